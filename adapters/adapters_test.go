@@ -86,13 +86,13 @@ func testAdapter(t *testing.T, api huma.API) {
 	}
 }
 
-func TestAdapters(t *testing.T) {
-	config := huma.DefaultConfig("Test", "1.0.0")
+type adapterCase struct {
+	name string
+	new  func() huma.API
+}
 
-	for _, adapter := range []struct {
-		name string
-		new  func() huma.API
-	}{
+func adapterCases(config huma.Config) []adapterCase {
+	return []adapterCase{
 		{"chi", func() huma.API { return humachi.New(chi.NewMux(), config) }},
 		{"chi4", func() huma.API { return humachi.NewV4(chi4.NewMux(), config) }},
 		{"echo", func() huma.API { return humaecho.New(echo.New(), config) }},
@@ -102,9 +102,75 @@ func TestAdapters(t *testing.T) {
 		{"mux", func() huma.API { return humamux.New(mux.NewRouter(), config) }},
 		{"bunrouter", func() huma.API { return humabunrouter.New(bunrouter.New(), config) }},
 		{"bunroutercompat", func() huma.API { return humabunrouter.NewCompat(bunrouter.New().Compat(), config) }},
-	} {
+	}
+}
+
+func TestAdapters(t *testing.T) {
+	for _, adapter := range adapterCases(huma.DefaultConfig("Test", "1.0.0")) {
 		t.Run(adapter.name, func(t *testing.T) {
 			testAdapter(t, adapter.new())
 		})
 	}
 }
+
+func TestAdaptersCORS(t *testing.T) {
+	config := huma.DefaultConfig("Test", "1.0.0")
+	config.CORS = &huma.CORSConfig{AllowOrigins: []string{"https://example.com"}}
+
+	for _, adapter := range adapterCases(config) {
+		t.Run(adapter.name, func(t *testing.T) {
+			api := adapter.new()
+			for _, method := range []string{http.MethodPut, http.MethodPost} {
+				huma.Register(api, huma.Operation{
+					OperationID: method + "-test",
+					Method:      method,
+					Path:        "/{group}",
+				}, testHandler)
+			}
+
+			testAPI := humatest.Wrap(t, api)
+			resp := testAPI.Put("/foo",
+				"Origin: https://example.com",
+				strings.NewReader(`{"name": "Daniel", "email": "daniel@example.com"}`),
+			)
+			assert.Equal(t, http.StatusOK, resp.Code)
+			assert.Equal(t, "https://example.com", resp.Header().Get("Access-Control-Allow-Origin"))
+
+			// Preflight requests are handled for all routers.
+			resp = testAPI.Do(http.MethodOptions, "/foo",
+				"Origin: https://example.com",
+				"Access-Control-Request-Method: PUT",
+			)
+			assert.Equal(t, http.StatusNoContent, resp.Code)
+			assert.Equal(t, "https://example.com", resp.Header().Get("Access-Control-Allow-Origin"))
+			assert.Equal(t, "PUT, POST", resp.Header().Get("Access-Control-Allow-Methods"))
+		})
+	}
+}
+
+func TestAdaptersWildcard(t *testing.T) {
+	for _, adapter := range adapterCases(huma.DefaultConfig("Test", "1.0.0")) {
+		t.Run(adapter.name, func(t *testing.T) {
+			api := adapter.new()
+			huma.Register(api, huma.Operation{
+				OperationID: "get-file",
+				Method:      http.MethodGet,
+				Path:        "/static/{bucket}/{file...}",
+			}, func(ctx context.Context, input *struct {
+				Bucket string `path:"bucket"`
+				File   string `path:"file"`
+			}) (*struct{ Body string }, error) {
+				return &struct{ Body string }{Body: input.Bucket + ":" + input.File}, nil
+			})
+
+			testAPI := humatest.Wrap(t, api)
+			resp := testAPI.Get("/static/assets/css/site.css")
+			assert.Equal(t, http.StatusOK, resp.Code)
+			assert.Equal(t, `"assets:css/site.css"`, strings.TrimSpace(resp.Body.String()))
+
+			resp = testAPI.Get("/static/assets/index.html")
+			assert.Equal(t, http.StatusOK, resp.Code)
+			assert.Equal(t, `"assets:index.html"`, strings.TrimSpace(resp.Body.String()))
+		})
+	}
+}
@@ -41,18 +41,27 @@ func (c *bunContext) Host() string {
 	return c.r.Host
 }
 
+func (c *bunContext) RemoteAddr() string {
+	return c.r.RemoteAddr
+}
+
 func (c *bunContext) URL() url.URL {
 	return *c.r.URL
 }
 
 func (c *bunContext) Param(name string) string {
-	return c.r.Param(name)
+	// Catch-all parameters may include the leading slash.
+	return strings.TrimPrefix(c.r.Param(name), "/")
 }
 
 func (c *bunContext) Query(name string) string {
 	return queryparam.Get(c.r.URL.RawQuery, name)
 }
 
+func (c *bunContext) GetMultiQuery(name string) []string {
+	return queryparam.GetAll(c.r.URL.RawQuery, name)
+}
+
 func (c *bunContext) Header(name string) string {
 	return c.r.Header.Get(name)
 }
@@ -78,6 +87,14 @@ func (c *bunContext) SetReadDeadline(deadline time.Time) error {
 	return huma.SetReadDeadline(c.w, deadline)
 }
 
+func (c *bunContext) EnableFullDuplex() error {
+	return huma.EnableFullDuplex(c.w)
+}
+
+func (c *bunContext) WriteEarlyHints(links []string) error {
+	return huma.WriteEarlyHints(c.w, links)
+}
+
 func (c *bunContext) SetStatus(code int) {
 	c.w.WriteHeader(code)
 }
@@ -121,19 +138,27 @@ func (c *bunCompatContext) Host() string {
 	return c.r.Host
 }
 
+func (c *bunCompatContext) RemoteAddr() string {
+	return c.r.RemoteAddr
+}
+
 func (c *bunCompatContext) URL() url.URL {
 	return *c.r.URL
 }
 
 func (c *bunCompatContext) Param(name string) string {
 	params := bunrouter.ParamsFromContext(c.r.Context())
-	return params.ByName(name)
+	return strings.TrimPrefix(params.ByName(name), "/")
 }
 
 func (c *bunCompatContext) Query(name string) string {
 	return queryparam.Get(c.r.URL.RawQuery, name)
 }
 
+func (c *bunCompatContext) GetMultiQuery(name string) []string {
+	return queryparam.GetAll(c.r.URL.RawQuery, name)
+}
+
 func (c *bunCompatContext) Header(name string) string {
 	return c.r.Header.Get(name)
 }
@@ -159,6 +184,14 @@ func (c *bunCompatContext) SetReadDeadline(deadline time.Time) error {
 	return huma.SetReadDeadline(c.w, deadline)
 }
 
+func (c *bunCompatContext) EnableFullDuplex() error {
+	return huma.EnableFullDuplex(c.w)
+}
+
+func (c *bunCompatContext) WriteEarlyHints(links []string) error {
+	return huma.WriteEarlyHints(c.w, links)
+}
+
 func (c *bunCompatContext) SetStatus(code int) {
 	c.w.WriteHeader(code)
 }
@@ -180,14 +213,23 @@ func NewCompatContext(op *huma.Operation, r *http.Request, w http.ResponseWriter
 	return &bunCompatContext{op: op, r: r, w: w}
 }
 
+// bunPath converts {param} to :param and {param...} to *param.
+func bunPath(op string) string {
+	path, name, wildcard := huma.PathWildcard(op)
+	path = strings.ReplaceAll(path, "{", ":")
+	path = strings.ReplaceAll(path, "}", "")
+	if wildcard {
+		path += "*" + name
+	}
+	return path
+}
+
 type bunCompatAdapter struct {
 	router *bunrouter.CompatRouter
 }
 
 func (a *bunCompatAdapter) Handle(op *huma.Operation, handler func(huma.Context)) {
-	path := op.Path
-	path = strings.ReplaceAll(path, "{", ":")
-	path = strings.ReplaceAll(path, "}", "")
+	path := bunPath(op.Path)
 	a.router.Handle(op.Method, path, func(w http.ResponseWriter, r *http.Request) {
 		handler(NewCompatContext(op, r, w))
 	})
@@ -207,9 +249,7 @@ type bunAdapter struct {
 }
 
 func (a *bunAdapter) Handle(op *huma.Operation, handler func(huma.Context)) {
-	path := op.Path
-	path = strings.ReplaceAll(path, "{", ":")
-	path = strings.ReplaceAll(path, "}", "")
+	path := bunPath(op.Path)
 	a.router.Handle(op.Method, path, func(w http.ResponseWriter, r bunrouter.Request) error {
 		var err error
 		defer func() {
@@ -6,6 +6,7 @@ import (
 	"mime/multipart"
 	"net/http"
 	"net/url"
+	"sync"
 	"time"
 
 	"github.com/danielgtaylor/huma/v2"
@@ -25,6 +26,13 @@ type chiContext struct {
 	v4 bool
 }
 
+// chiContextPool reuses contexts across requests to reduce allocations.
+var chiContextPool = sync.Pool{
+	New: func() any {
+		return &chiContext{}
+	},
+}
+
 func (c *chiContext) Operation() *huma.Operation {
 	return c.op
 }
@@ -41,11 +49,19 @@ func (c *chiContext) Host() string {
 	return c.r.Host
 }
 
+func (c *chiContext) RemoteAddr() string {
+	return c.r.RemoteAddr
+}
+
 func (c *chiContext) URL() url.URL {
 	return *c.r.URL
 }
 
 func (c *chiContext) Param(name string) string {
+	if _, wildcard, ok := huma.PathWildcard(c.op.Path); ok && name == wildcard {
+		// Chi names catch-all parameters `*`.
+		name = "*"
+	}
 	if !c.v4 {
 		return chi.URLParam(c.r, name)
 	}
@@ -57,6 +73,10 @@ func (c *chiContext) Query(name string) string {
 	return queryparam.Get(c.r.URL.RawQuery, name)
 }
 
+func (c *chiContext) GetMultiQuery(name string) []string {
+	return queryparam.GetAll(c.r.URL.RawQuery, name)
+}
+
 func (c *chiContext) Header(name string) string {
 	return c.r.Header.Get(name)
 }
@@ -82,6 +102,14 @@ func (c *chiContext) SetReadDeadline(deadline time.Time) error {
 	return huma.SetReadDeadline(c.w, deadline)
 }
 
+func (c *chiContext) EnableFullDuplex() error {
+	return huma.EnableFullDuplex(c.w)
+}
+
+func (c *chiContext) WriteEarlyHints(links []string) error {
+	return huma.WriteEarlyHints(c.w, links)
+}
+
 func (c *chiContext) SetStatus(code int) {
 	c.w.WriteHeader(code)
 }
@@ -103,13 +131,27 @@ func NewContext(op *huma.Operation, r *http.Request, w http.ResponseWriter) huma
 	return &chiContext{op: op, r: r, w: w}
 }
 
+// chiPath converts a catch-all parameter like `{file...}` to `*`.
+func chiPath(path string) string {
+	if prefix, _, ok := huma.PathWildcard(path); ok {
+		return prefix + "*"
+	}
+	return path
+}
+
 type chiAdapter struct {
 	router chi.Router
 }
 
 func (a *chiAdapter) Handle(op *huma.Operation, handler func(huma.Context)) {
-	a.router.MethodFunc(op.Method, op.Path, func(w http.ResponseWriter, r *http.Request) {
-		handler(&chiContext{op: op, r: r, w: w})
+	a.router.MethodFunc(op.Method, chiPath(op.Path), func(w http.ResponseWriter, r *http.Request) {
+		ctx := chiContextPool.Get().(*chiContext)
+		ctx.op, ctx.r, ctx.w = op, r, w
+		defer func() {
+			*ctx = chiContext{}
+			chiContextPool.Put(ctx)
+		}()
+		handler(ctx)
 	})
 }
 
@@ -132,8 +174,14 @@ type chiAdapterV4 struct {
 }
 
 func (a *chiAdapterV4) Handle(op *huma.Operation, handler func(huma.Context)) {
-	a.router.MethodFunc(op.Method, op.Path, func(w http.ResponseWriter, r *http.Request) {
-		handler(&chiContext{op: op, r: r, w: w, v4: true})
+	a.router.MethodFunc(op.Method, chiPath(op.Path), func(w http.ResponseWriter, r *http.Request) {
+		ctx := chiContextPool.Get().(*chiContext)
+		ctx.op, ctx.r, ctx.w, ctx.v4 = op, r, w, true
+		defer func() {
+			*ctx = chiContext{}
+			chiContextPool.Put(ctx)
+		}()
+		handler(ctx)
 	})
 }
 
@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"net/url"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/danielgtaylor/huma/v2"
@@ -22,6 +23,13 @@ type echoCtx struct {
 	orig echo.Context
 }
 
+// echoCtxPool reuses contexts across requests to reduce allocations.
+var echoCtxPool = sync.Pool{
+	New: func() any {
+		return &echoCtx{}
+	},
+}
+
 func (c *echoCtx) Operation() *huma.Operation {
 	return c.op
 }
@@ -38,11 +46,19 @@ func (c *echoCtx) Host() string {
 	return c.orig.Request().Host
 }
 
+func (c *echoCtx) RemoteAddr() string {
+	return c.orig.Request().RemoteAddr
+}
+
 func (c *echoCtx) URL() url.URL {
 	return *c.orig.Request().URL
 }
 
 func (c *echoCtx) Param(name string) string {
+	if _, wildcard, ok := huma.PathWildcard(c.op.Path); ok && name == wildcard {
+		// Echo names catch-all parameters `*`.
+		name = "*"
+	}
 	return c.orig.Param(name)
 }
 
@@ -50,6 +66,10 @@ func (c *echoCtx) Query(name string) string {
 	return c.orig.QueryParam(name)
 }
 
+func (c *echoCtx) GetMultiQuery(name string) []string {
+	return c.orig.QueryParams()[name]
+}
+
 func (c *echoCtx) Header(name string) string {
 	return c.orig.Request().Header.Get(name)
 }
@@ -75,6 +95,14 @@ func (c *echoCtx) SetReadDeadline(deadline time.Time) error {
 	return huma.SetReadDeadline(c.orig.Response(), deadline)
 }
 
+func (c *echoCtx) EnableFullDuplex() error {
+	return huma.EnableFullDuplex(c.orig.Response().Writer)
+}
+
+func (c *echoCtx) WriteEarlyHints(links []string) error {
+	return huma.WriteEarlyHints(c.orig.Response().Writer, links)
+}
+
 func (c *echoCtx) SetStatus(code int) {
 	c.orig.Response().WriteHeader(code)
 }
@@ -96,12 +124,20 @@ type echoAdapter struct {
 }
 
 func (a *echoAdapter) Handle(op *huma.Operation, handler func(huma.Context)) {
-	// Convert {param} to :param
-	path := op.Path
+	// Convert {param} to :param and {param...} to *
+	path, _, wildcard := huma.PathWildcard(op.Path)
 	path = strings.ReplaceAll(path, "{", ":")
 	path = strings.ReplaceAll(path, "}", "")
+	if wildcard {
+		path += "*"
+	}
 	a.router.Add(op.Method, path, func(c echo.Context) error {
-		ctx := &echoCtx{op: op, orig: c}
+		ctx := echoCtxPool.Get().(*echoCtx)
+		ctx.op, ctx.orig = op, c
+		defer func() {
+			*ctx = echoCtx{}
+			echoCtxPool.Put(ctx)
+		}()
 		handler(ctx)
 		return nil
 	})
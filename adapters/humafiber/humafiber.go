@@ -1,6 +1,7 @@
 package humafiber
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"io"
@@ -8,6 +9,7 @@ import (
 	"net/http"
 	"net/url"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/danielgtaylor/huma/v2"
@@ -19,6 +21,13 @@ type fiberCtx struct {
 	orig *fiber.Ctx
 }
 
+// fiberCtxPool reuses contexts across requests to reduce allocations.
+var fiberCtxPool = sync.Pool{
+	New: func() any {
+		return &fiberCtx{}
+	},
+}
+
 func (c *fiberCtx) Operation() *huma.Operation {
 	return c.op
 }
@@ -39,12 +48,20 @@ func (c *fiberCtx) Host() string {
 	return c.orig.Hostname()
 }
 
+func (c *fiberCtx) RemoteAddr() string {
+	return c.orig.Context().RemoteAddr().String()
+}
+
 func (c *fiberCtx) URL() url.URL {
 	u, _ := url.Parse(string(c.orig.Request().RequestURI()))
 	return *u
 }
 
 func (c *fiberCtx) Param(name string) string {
+	if _, wildcard, ok := huma.PathWildcard(c.op.Path); ok && name == wildcard {
+		// Fiber names catch-all parameters `*`.
+		name = "*"
+	}
 	return c.orig.Params(name)
 }
 
@@ -52,6 +69,14 @@ func (c *fiberCtx) Query(name string) string {
 	return c.orig.Query(name)
 }
 
+func (c *fiberCtx) GetMultiQuery(name string) []string {
+	var values []string
+	for _, v := range c.orig.Context().QueryArgs().PeekMulti(name) {
+		values = append(values, string(v))
+	}
+	return values
+}
+
 func (c *fiberCtx) Header(name string) string {
 	return c.orig.Get(name)
 }
@@ -99,17 +124,33 @@ func (c *fiberCtx) BodyWriter() io.Writer {
 	return c.orig
 }
 
+func (c *fiberCtx) StreamBody(cb func(w io.Writer, flush func() error)) {
+	// Fasthttp buffers the response body unless a stream writer is used, which
+	// gets called after the handler returns when the response is being sent.
+	c.orig.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		cb(w, w.Flush)
+	})
+}
+
 type fiberAdapter struct {
 	router *fiber.App
 }
 
 func (a *fiberAdapter) Handle(op *huma.Operation, handler func(huma.Context)) {
-	// Convert {param} to :param
-	path := op.Path
+	// Convert {param} to :param and {param...} to *
+	path, _, wildcard := huma.PathWildcard(op.Path)
 	path = strings.ReplaceAll(path, "{", ":")
 	path = strings.ReplaceAll(path, "}", "")
+	if wildcard {
+		path += "*"
+	}
 	a.router.Add(op.Method, path, func(c *fiber.Ctx) error {
-		ctx := &fiberCtx{op: op, orig: c}
+		ctx := fiberCtxPool.Get().(*fiberCtx)
+		ctx.op, ctx.orig = op, c
+		defer func() {
+			*ctx = fiberCtx{}
+			fiberCtxPool.Put(ctx)
+		}()
 		handler(ctx)
 		return nil
 	})
@@ -3,6 +3,7 @@ package humafiber
 import (
 	"context"
 	"net/http"
+	"strings"
 	"testing"
 
 	"github.com/danielgtaylor/huma/v2"
@@ -59,3 +60,52 @@ func BenchmarkNotHuma(b *testing.B) {
 		r.Test(req)
 	}
 }
+
+// BenchmarkHumaFiberParallel measures steady-state request handling across
+// many goroutines, where adapter contexts and validation state are reused
+// from pools instead of being allocated per request.
+func BenchmarkHumaFiberParallel(b *testing.B) {
+	type GreetingInput struct {
+		ID   string `path:"id"`
+		Body struct {
+			Suffix string `json:"suffix" maxLength:"5"`
+		}
+	}
+
+	type GreetingOutput struct {
+		Body struct {
+			Greeting string `json:"greeting"`
+		}
+	}
+
+	r := fiber.New()
+	api := New(r, huma.DefaultConfig("Test API", "1.0.0"))
+
+	huma.Register(api, huma.Operation{
+		OperationID: "greet",
+		Method:      http.MethodPost,
+		Path:        "/foo/{id}",
+	}, func(ctx context.Context, input *GreetingInput) (*GreetingOutput, error) {
+		resp := &GreetingOutput{}
+		resp.Body.Greeting = "Hello, " + input.ID + input.Body.Suffix
+		return resp, nil
+	})
+
+	b.ResetTimer()
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			req, _ := http.NewRequest(http.MethodPost, "/foo/123", strings.NewReader(`{"suffix": "!"}`))
+			req.Header.Set("Content-Type", "application/json")
+			resp, err := r.Test(req)
+			if err != nil {
+				b.Error(err)
+				return
+			}
+			if resp.StatusCode != http.StatusOK {
+				b.Errorf("unexpected status %d", resp.StatusCode)
+				return
+			}
+		}
+	})
+}
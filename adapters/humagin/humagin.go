@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"net/url"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/danielgtaylor/huma/v2"
@@ -22,6 +23,13 @@ type ginCtx struct {
 	orig *gin.Context
 }
 
+// ginCtxPool reuses contexts across requests to reduce allocations.
+var ginCtxPool = sync.Pool{
+	New: func() any {
+		return &ginCtx{}
+	},
+}
+
 func (c *ginCtx) Operation() *huma.Operation {
 	return c.op
 }
@@ -38,18 +46,27 @@ func (c *ginCtx) Host() string {
 	return c.orig.Request.Host
 }
 
+func (c *ginCtx) RemoteAddr() string {
+	return c.orig.Request.RemoteAddr
+}
+
 func (c *ginCtx) URL() url.URL {
 	return *c.orig.Request.URL
 }
 
 func (c *ginCtx) Param(name string) string {
-	return c.orig.Param(name)
+	// Catch-all parameters include the leading slash.
+	return strings.TrimPrefix(c.orig.Param(name), "/")
 }
 
 func (c *ginCtx) Query(name string) string {
 	return c.orig.Query(name)
 }
 
+func (c *ginCtx) GetMultiQuery(name string) []string {
+	return c.orig.QueryArray(name)
+}
+
 func (c *ginCtx) Header(name string) string {
 	return c.orig.GetHeader(name)
 }
@@ -75,6 +92,10 @@ func (c *ginCtx) SetReadDeadline(deadline time.Time) error {
 	return huma.SetReadDeadline(c.orig.Writer, deadline)
 }
 
+func (c *ginCtx) EnableFullDuplex() error {
+	return huma.EnableFullDuplex(c.orig.Writer)
+}
+
 func (c *ginCtx) SetStatus(code int) {
 	c.orig.Status(code)
 }
@@ -96,12 +117,20 @@ type ginAdapter struct {
 }
 
 func (a *ginAdapter) Handle(op *huma.Operation, handler func(huma.Context)) {
-	// Convert {param} to :param
-	path := op.Path
+	// Convert {param} to :param and {param...} to *param
+	path, name, wildcard := huma.PathWildcard(op.Path)
 	path = strings.ReplaceAll(path, "{", ":")
 	path = strings.ReplaceAll(path, "}", "")
+	if wildcard {
+		path += "*" + name
+	}
 	a.router.Handle(op.Method, path, func(c *gin.Context) {
-		ctx := &ginCtx{op: op, orig: c}
+		ctx := ginCtxPool.Get().(*ginCtx)
+		ctx.op, ctx.orig = op, c
+		defer func() {
+			*ctx = ginCtx{}
+			ginCtxPool.Put(ctx)
+		}()
 		handler(ctx)
 	})
 }
@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"net/url"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/danielgtaylor/huma/v2"
@@ -23,6 +24,13 @@ type goContext struct {
 	w  http.ResponseWriter
 }
 
+// goContextPool reuses contexts across requests to reduce allocations.
+var goContextPool = sync.Pool{
+	New: func() any {
+		return &goContext{}
+	},
+}
+
 func (c *goContext) Operation() *huma.Operation {
 	return c.op
 }
@@ -39,6 +47,10 @@ func (c *goContext) Host() string {
 	return c.r.Host
 }
 
+func (c *goContext) RemoteAddr() string {
+	return c.r.RemoteAddr
+}
+
 func (c *goContext) URL() url.URL {
 	return *c.r.URL
 }
@@ -58,6 +70,10 @@ func (c *goContext) Query(name string) string {
 	return queryparam.Get(c.r.URL.RawQuery, name)
 }
 
+func (c *goContext) GetMultiQuery(name string) []string {
+	return queryparam.GetAll(c.r.URL.RawQuery, name)
+}
+
 func (c *goContext) Header(name string) string {
 	return c.r.Header.Get(name)
 }
@@ -83,6 +99,14 @@ func (c *goContext) SetReadDeadline(deadline time.Time) error {
 	return huma.SetReadDeadline(c.w, deadline)
 }
 
+func (c *goContext) EnableFullDuplex() error {
+	return huma.EnableFullDuplex(c.w)
+}
+
+func (c *goContext) WriteEarlyHints(links []string) error {
+	return huma.WriteEarlyHints(c.w, links)
+}
+
 func (c *goContext) SetStatus(code int) {
 	c.w.WriteHeader(code)
 }
@@ -110,7 +134,13 @@ type goAdapter struct {
 
 func (a *goAdapter) Handle(op *huma.Operation, handler func(huma.Context)) {
 	a.router.HandleFunc(strings.ToUpper(op.Method)+" "+op.Path, func(w http.ResponseWriter, r *http.Request) {
-		handler(&goContext{op: op, r: r, w: w})
+		ctx := goContextPool.Get().(*goContext)
+		ctx.op, ctx.r, ctx.w = op, r, w
+		defer func() {
+			*ctx = goContext{}
+			goContextPool.Put(ctx)
+		}()
+		handler(ctx)
 	})
 }
 
@@ -74,6 +74,55 @@ func BenchmarkHumaV2Go(b *testing.B) {
 	}
 }
 
+// BenchmarkHumaV2GoParallel measures steady-state request handling across
+// many goroutines, where adapter contexts and validation state are reused
+// from pools instead of being allocated per request.
+func BenchmarkHumaV2GoParallel(b *testing.B) {
+	type GreetingInput struct {
+		ID   string `path:"id"`
+		Body struct {
+			Suffix string `json:"suffix" maxLength:"5"`
+		}
+	}
+
+	type GreetingOutput struct {
+		Body struct {
+			Greeting string `json:"greeting"`
+		}
+	}
+
+	r := http.NewServeMux()
+	app := New(r, huma.DefaultConfig("Test", "1.0.0"))
+
+	huma.Register(app, huma.Operation{
+		OperationID: "greet",
+		Method:      http.MethodPost,
+		Path:        "/foo/{id}",
+	}, func(ctx context.Context, input *GreetingInput) (*GreetingOutput, error) {
+		resp := &GreetingOutput{}
+		resp.Body.Greeting = "Hello, " + input.ID + input.Body.Suffix
+		return resp, nil
+	})
+
+	b.ResetTimer()
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		reqBody := strings.NewReader(`{"suffix": "!"}`)
+		req, _ := http.NewRequest(http.MethodPost, "/foo/123", reqBody)
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		for pb.Next() {
+			reqBody.Seek(0, 0)
+			w.Body.Reset()
+			r.ServeHTTP(w, req)
+			if w.Code != http.StatusOK {
+				b.Error(w.Body.String())
+				return
+			}
+		}
+	})
+}
+
 func BenchmarkRawGo(b *testing.B) {
 	type GreetingInput struct {
 		Suffix string `json:"suffix" maxLength:"5"`
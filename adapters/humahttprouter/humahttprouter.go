@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"net/url"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/danielgtaylor/huma/v2"
@@ -25,6 +26,13 @@ type httprouterContext struct {
 	ps httprouter.Params
 }
 
+// httprouterContextPool reuses contexts across requests to reduce allocations.
+var httprouterContextPool = sync.Pool{
+	New: func() any {
+		return &httprouterContext{}
+	},
+}
+
 func (c *httprouterContext) Operation() *huma.Operation {
 	return c.op
 }
@@ -41,18 +49,27 @@ func (c *httprouterContext) Host() string {
 	return c.r.Host
 }
 
+func (c *httprouterContext) RemoteAddr() string {
+	return c.r.RemoteAddr
+}
+
 func (c *httprouterContext) URL() url.URL {
 	return *c.r.URL
 }
 
 func (c *httprouterContext) Param(name string) string {
-	return c.ps.ByName(name)
+	// Catch-all parameters include the leading slash.
+	return strings.TrimPrefix(c.ps.ByName(name), "/")
 }
 
 func (c *httprouterContext) Query(name string) string {
 	return queryparam.Get(c.r.URL.RawQuery, name)
 }
 
+func (c *httprouterContext) GetMultiQuery(name string) []string {
+	return queryparam.GetAll(c.r.URL.RawQuery, name)
+}
+
 func (c *httprouterContext) Header(name string) string {
 	return c.r.Header.Get(name)
 }
@@ -78,6 +95,14 @@ func (c *httprouterContext) SetReadDeadline(deadline time.Time) error {
 	return huma.SetReadDeadline(c.w, deadline)
 }
 
+func (c *httprouterContext) EnableFullDuplex() error {
+	return huma.EnableFullDuplex(c.w)
+}
+
+func (c *httprouterContext) WriteEarlyHints(links []string) error {
+	return huma.WriteEarlyHints(c.w, links)
+}
+
 func (c *httprouterContext) SetStatus(code int) {
 	c.w.WriteHeader(code)
 }
@@ -99,12 +124,21 @@ type httprouterAdapter struct {
 }
 
 func (a *httprouterAdapter) Handle(op *huma.Operation, handler func(huma.Context)) {
-	// Convert {param} to :param
-	path := op.Path
+	// Convert {param} to :param and {param...} to *param
+	path, name, wildcard := huma.PathWildcard(op.Path)
 	path = strings.ReplaceAll(path, "{", ":")
 	path = strings.ReplaceAll(path, "}", "")
+	if wildcard {
+		path += "*" + name
+	}
 	a.router.Handle(op.Method, path, func(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
-		handler(&httprouterContext{op: op, r: r, w: w, ps: ps})
+		ctx := httprouterContextPool.Get().(*httprouterContext)
+		ctx.op, ctx.r, ctx.w, ctx.ps = op, r, w, ps
+		defer func() {
+			*ctx = httprouterContext{}
+			httprouterContextPool.Put(ctx)
+		}()
+		handler(ctx)
 	})
 }
 
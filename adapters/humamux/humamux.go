@@ -6,6 +6,7 @@ import (
 	"mime/multipart"
 	"net/http"
 	"net/url"
+	"sync"
 	"time"
 
 	"github.com/danielgtaylor/huma/v2"
@@ -23,6 +24,13 @@ type gmuxContext struct {
 	w  http.ResponseWriter
 }
 
+// gmuxContextPool reuses contexts across requests to reduce allocations.
+var gmuxContextPool = sync.Pool{
+	New: func() any {
+		return &gmuxContext{}
+	},
+}
+
 func (c *gmuxContext) Operation() *huma.Operation {
 	return c.op
 }
@@ -39,6 +47,10 @@ func (c *gmuxContext) Host() string {
 	return c.r.Host
 }
 
+func (c *gmuxContext) RemoteAddr() string {
+	return c.r.RemoteAddr
+}
+
 func (c *gmuxContext) URL() url.URL {
 	return *c.r.URL
 }
@@ -51,6 +63,10 @@ func (c *gmuxContext) Query(name string) string {
 	return queryparam.Get(c.r.URL.RawQuery, name)
 }
 
+func (c *gmuxContext) GetMultiQuery(name string) []string {
+	return queryparam.GetAll(c.r.URL.RawQuery, name)
+}
+
 func (c *gmuxContext) Header(name string) string {
 	return c.r.Header.Get(name)
 }
@@ -76,6 +92,14 @@ func (c *gmuxContext) SetReadDeadline(deadline time.Time) error {
 	return huma.SetReadDeadline(c.w, deadline)
 }
 
+func (c *gmuxContext) EnableFullDuplex() error {
+	return huma.EnableFullDuplex(c.w)
+}
+
+func (c *gmuxContext) WriteEarlyHints(links []string) error {
+	return huma.WriteEarlyHints(c.w, links)
+}
+
 func (c *gmuxContext) SetStatus(code int) {
 	c.w.WriteHeader(code)
 }
@@ -92,6 +116,14 @@ func (c *gmuxContext) BodyWriter() io.Writer {
 	return c.w
 }
 
+// muxPath converts a catch-all parameter like `{file...}` to `{file:.*}`.
+func muxPath(path string) string {
+	if prefix, name, ok := huma.PathWildcard(path); ok {
+		return prefix + "{" + name + ":.*}"
+	}
+	return path
+}
+
 type gMux struct {
 	router *mux.Router
 }
@@ -99,10 +131,16 @@ type gMux struct {
 func (a *gMux) Handle(op *huma.Operation, handler func(huma.Context)) {
 	a.router.
 		NewRoute().
-		Path(op.Path).
+		Path(muxPath(op.Path)).
 		Methods(op.Method).
 		HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			handler(&gmuxContext{op: op, r: r, w: w})
+			ctx := gmuxContextPool.Get().(*gmuxContext)
+			ctx.op, ctx.r, ctx.w = op, r, w
+			defer func() {
+				*ctx = gmuxContext{}
+				gmuxContextPool.Put(ctx)
+			}()
+			handler(ctx)
 		})
 }
 
@@ -1,6 +1,7 @@
 package huma
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
@@ -12,13 +13,13 @@ import (
 	"reflect"
 	"regexp"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/danielgtaylor/huma/v2/negotiation"
+	"github.com/danielgtaylor/huma/v2/yaml"
 )
 
-var rxSchema = regexp.MustCompile(`#/components/schemas/([^"]+)`)
-
 var ErrUnknownContentType = errors.New("unknown content type")
 
 // Resolver runs a `Resolve` function after a request has been parsed, enabling
@@ -78,6 +79,10 @@ type Context interface {
 	// Query returns the value for the given query parameter.
 	Query(name string) string
 
+	// GetMultiQuery returns all values for the given query parameter when it
+	// is repeated, e.g. `?tag=a&tag=b`.
+	GetMultiQuery(name string) []string
+
 	// Header returns the value for the given header.
 	Header(name string) string
 
@@ -108,12 +113,142 @@ type Context interface {
 	BodyWriter() io.Writer
 }
 
+// ContextUnwrapper is implemented by contexts which wrap another context,
+// such as those created by middleware. Optional interfaces like
+// `EarlyHintsContext` are then still found on the wrapped adapter context.
+//
+// Contexts which embed `ContextWrapper` implement it automatically.
+//
+//	func (c *myContext) Unwrap() huma.Context {
+//		return c.ctx
+//	}
+type ContextUnwrapper interface {
+	Unwrap() Context
+}
+
+// ContextWrapper can be embedded by middleware contexts which wrap another
+// context to override some of its methods. All other methods are passed
+// through to the wrapped context, which `Unwrap` returns so that optional
+// interfaces like `EarlyHintsContext` are still found.
+//
+//	type statusContext struct {
+//		huma.ContextWrapper
+//		status int
+//	}
+//
+//	func (c *statusContext) SetStatus(code int) {
+//		c.status = code
+//		c.ContextWrapper.SetStatus(code)
+//	}
+//
+//	next(&statusContext{ContextWrapper: huma.WrapContext(ctx)})
+type ContextWrapper struct {
+	humaContext
+}
+
+// humaContext allows embedding `Context`, whose name would otherwise clash
+// with its `Context()` method.
+type humaContext = Context
+
+// WrapContext returns a `ContextWrapper` passing calls through to `ctx`.
+func WrapContext(ctx Context) ContextWrapper {
+	return ContextWrapper{ctx}
+}
+
+// Unwrap returns the wrapped context.
+func (c ContextWrapper) Unwrap() Context {
+	return c.humaContext
+}
+
+// RemoteAddrContext may be implemented by adapter contexts which know the
+// network address of the client.
+type RemoteAddrContext interface {
+	// RemoteAddr returns the network address of the client, typically as
+	// `host:port`.
+	RemoteAddr() string
+}
+
+// RemoteAddr returns the network address of the client, typically as
+// `host:port`, or an empty string if the adapter does not provide it.
+// Contexts wrapped by middleware are searched via `ContextUnwrapper`.
+func RemoteAddr(ctx Context) string {
+	if rc, ok := findContext[RemoteAddrContext](ctx); ok {
+		return rc.RemoteAddr()
+	}
+	return ""
+}
+
+// findContext returns the first context implementing `T`, starting with
+// `ctx` and then each context it wraps in turn.
+func findContext[T any](ctx Context) (T, bool) {
+	for {
+		if t, ok := ctx.(T); ok {
+			return t, true
+		}
+		u, ok := ctx.(ContextUnwrapper)
+		if !ok {
+			var zero T
+			return zero, false
+		}
+		ctx = u.Unwrap()
+	}
+}
+
 // Transformer is a function that can modify a response body before it is
 // serialized. The `status` is the HTTP status code for the response and `v` is
 // the value to be serialized. The return value is the new value to be
 // serialized or an error.
 type Transformer func(ctx Context, status string, v any) (any, error)
 
+// RequestTransformer is a function that can modify a decoded request body
+// before it is validated. The value `v` is the generic parsed body, e.g. a
+// `map[string]any` for JSON objects. The return value is the new body to be
+// validated and passed to the handler or an error, which results in an
+// HTTP 400 Bad Request response.
+type RequestTransformer func(ctx Context, v any) (any, error)
+
+// ResponseStrategy controls how response bodies are written. See
+// `Config.ResponseStrategy` and `Operation.ResponseStrategy`.
+type ResponseStrategy int
+
+const (
+	// ResponseDefault uses the API-wide strategy from the config, falling
+	// back to `ResponseStreamed`.
+	ResponseDefault ResponseStrategy = iota
+
+	// ResponseStreamed marshals response bodies directly to the client as they
+	// are encoded. This uses less memory for huge payloads, but the status
+	// code is sent before marshaling so failures result in a partial response.
+	ResponseStreamed
+
+	// ResponseBuffered marshals response bodies into memory before sending
+	// anything, which enables setting the `Content-Length` header and sending
+	// a proper error response if transforming or marshaling fails.
+	ResponseBuffered
+)
+
+// ValidationMode controls what happens when a request fails validation. See
+// `Config.ValidationMode` and `Operation.ValidationMode`.
+type ValidationMode int
+
+const (
+	// ValidationDefault uses the API-wide mode from the config, falling back
+	// to `ValidationEnforce`.
+	ValidationDefault ValidationMode = iota
+
+	// ValidationEnforce rejects requests which fail validation with an error
+	// response.
+	ValidationEnforce
+
+	// ValidationAudit reports schema validation failures to
+	// `Config.OnValidationAudit` and then calls the handler anyway. This is
+	// useful for rolling out stricter schemas against existing traffic
+	// without breaking clients. Requests which cannot be parsed into the
+	// input struct at all, e.g. malformed JSON or an invalid integer
+	// parameter, and errors from resolvers are still rejected.
+	ValidationAudit
+)
+
 // Config represents a configuration for a new API. See `huma.DefaultConfig()`
 // as a starting point.
 type Config struct {
@@ -137,17 +272,181 @@ type Config struct {
 	// or for use in editors like VSCode to provide autocomplete & validation.
 	SchemasPath string
 
+	// CompactSchemas merges structurally identical schemas generated from
+	// anonymous structs into a single shared schema in the served OpenAPI,
+	// which can shrink large specs substantially. See `huma.CompactSchemas`.
+	CompactSchemas bool
+
+	// ErrorsPath is the path to the problem type documentation. If set to
+	// `/errors` it will allow clients to get `/errors/{name}` for each of the
+	// `ProblemTypes`, and is used to generate their `type` URIs.
+	ErrorsPath string
+
+	// OnSchema is called with each schema generated from a Go type, and the
+	// type itself, so that schemas can be customized centrally, e.g. to add
+	// descriptions or extensions, without implementing `SchemaProvider` on
+	// every type. The schema registry must implement `SchemaHookRegistry`.
+	OnSchema func(t reflect.Type, s *Schema)
+
+	// ProblemTypes is a catalog of the kinds of errors returned by the API,
+	// each with a stable `type` URI. See `huma.ProblemType`.
+	ProblemTypes []*ProblemType
+
 	// Formats defines the supported request/response formats by content type or
 	// extension (e.g. `json` for `application/my-format+json`).
 	Formats map[string]Format
 
+	// Compression enables compressing response bodies, negotiated using the
+	// client's `Accept-Encoding` header, so it behaves the same with every
+	// adapter. Do not combine it with router-specific compression middleware.
+	// See `huma.DefaultCompression`.
+	Compression *Compression
+
+	// ContentDecoders decompress request bodies sent with a `Content-Encoding`,
+	// keyed by content coding like `gzip` or `br`. Bodies using any other
+	// coding are rejected with `415 Unsupported Media Type`. The body size
+	// limit applies to the decompressed body. If empty, request bodies are
+	// never decompressed.
+	ContentDecoders map[string]ContentDecoder
+
 	// DefaultFormat specifies the default content type to use when the client
 	// does not specify one. If unset, the default type will be randomly
 	// chosen from the keys of `Formats`.
 	DefaultFormat string
 
+	// ErrorFormats defines additional formats used only for error responses,
+	// keyed by content type, e.g. `application/problem+xml` or `text/plain`.
+	// They are used when explicitly listed in the client's `Accept` header,
+	// otherwise errors use the regular `Formats`. Only `Marshal` is required.
+	ErrorFormats map[string]Format
+
 	// Transformers are a way to modify a response body before it is serialized.
 	Transformers []Transformer
+
+	// ConditionalTransformers are transformers with a priority and conditions
+	// controlling when they run. They are combined with `Transformers` and
+	// run in priority order.
+	ConditionalTransformers []ConditionalTransformer
+
+	// RequestTransformers are a way to modify a request body after it is
+	// decoded but before it is validated, e.g. to rename legacy fields. They
+	// run before any `Operation.RequestTransformers`.
+	RequestTransformers []RequestTransformer
+
+	// DefaultStatus infers the default success status for operations which do
+	// not set `Operation.DefaultStatus`. `hasBody` is whether the operation's
+	// output has a body. Return zero to fall back to the built-in rules of
+	// 200 OK with a body and 204 No Content without. See
+	// `huma.RESTDefaultStatus` for an example.
+	DefaultStatus func(op *Operation, hasBody bool) int
+
+	// ValidationErrorStatus is the HTTP status code returned when a request
+	// fails validation for operations which do not set
+	// `Operation.ValidationErrorStatus`. If not specified, the default is
+	// 422 Unprocessable Entity. Some style guides prefer 400 Bad Request.
+	ValidationErrorStatus int
+
+	// ValidationMode controls whether requests which fail validation are
+	// rejected or only reported, for operations which do not set
+	// `Operation.ValidationMode`. If not specified, they are rejected.
+	ValidationMode ValidationMode
+
+	// OnValidationAudit is called with the validation errors for requests
+	// to operations using `ValidationAudit` before the handler is called,
+	// e.g. to log or count them. The operation is available via
+	// `ctx.Operation()`.
+	OnValidationAudit func(ctx Context, errs []error)
+
+	// TrustedProxies are the addresses or CIDR ranges of reverse proxies and
+	// load balancers in front of the API, e.g. `10.0.0.0/8`. For requests
+	// coming from them, the client's address, scheme, and host are taken from
+	// the `X-Forwarded-For`, `X-Forwarded-Proto`, and `X-Forwarded-Host`
+	// headers. See `huma.ClientInfoFromContext`.
+	TrustedProxies []string
+
+	// Locales are the language tags supported by the API, e.g. `en-US`, used
+	// to negotiate `huma.Locale` inputs. The first is used when none of the
+	// client's preferred languages are supported. If empty, the client's
+	// most preferred language is used as-is.
+	Locales []string
+
+	// OnDeprecatedUsage is called when a request sets a parameter or body
+	// field marked with `deprecated:"true"`, with its location like
+	// `query.sort` or `body.items[0].legacy_id`, e.g. to count remaining usage
+	// before removing it. Such requests also get a `Warning` response header.
+	// The operation is available via `ctx.Operation()`.
+	OnDeprecatedUsage func(ctx Context, location string)
+
+	// ResponseStrategy controls whether response bodies are buffered or
+	// streamed for operations which do not set `Operation.ResponseStrategy`.
+	// If not specified, responses are streamed.
+	ResponseStrategy ResponseStrategy
+
+	// MaxErrorDetails limits the number of error details included in error
+	// responses. Any extra details are replaced by a single summary detail,
+	// e.g. `and 25 more errors`. If not specified, there is no limit.
+	MaxErrorDetails int
+
+	// Dependencies holds values which handlers depend on. See `huma.Provide`
+	// and `huma.Inject`. If unset, an empty container is created.
+	Dependencies *Dependencies
+
+	// MaxBodyBytes is the default maximum number of bytes to read from request
+	// bodies for operations which do not set `Operation.MaxBodyBytes`. If not
+	// specified, the default is 1MB. Use -1 for unlimited.
+	MaxBodyBytes int64
+
+	// RecoverPanics converts panics in operation handlers and middleware into
+	// `500 Internal Server Error` problem responses, so that router-specific
+	// recovery middleware is not needed.
+	RecoverPanics bool
+
+	// OnPanic is called with the recovered value and stack trace when a panic
+	// is recovered, e.g. for logging. Only used when `RecoverPanics` is set.
+	OnPanic func(ctx Context, recovered any, stack []byte)
+
+	// RequestIDHeader enables request IDs when set, e.g. to `X-Request-ID`.
+	// The ID is read from this request header or generated if missing, and is
+	// available to handlers via `huma.RequestID`. It is echoed back in the
+	// same response header and included in error response bodies.
+	RequestIDHeader string
+
+	// NewRequestID generates a request ID when the client does not send one.
+	// Defaults to a random 32 character hex string.
+	NewRequestID func() string
+
+	// ServerTiming enables the `Server-Timing` response header, which lets
+	// browser developer tools show where time was spent handling a request.
+	// Timings for validation, the handler, and serialization are recorded
+	// automatically, and handlers or middleware can add their own via
+	// `huma.AddServerTiming`.
+	ServerTiming bool
+
+	// ValidateResponses validates every response status, header, and JSON
+	// body against the operation's declared responses in the OpenAPI, so you
+	// can catch handlers which drift from the spec. This adds overhead to
+	// each request and is intended as a debug flag for development and
+	// testing. See also `humatest.ValidateResponses`.
+	ValidateResponses bool
+
+	// OnResponseViolation is called with the errors for responses which do
+	// not match the OpenAPI when `ValidateResponses` is enabled. The response
+	// has already been sent at that point. If not specified, the errors are
+	// logged.
+	OnResponseViolation func(ctx Context, status int, errs []error)
+
+	// AutoETag adds an `ETag` header to successful `GET` responses with a
+	// body, and responds with `304 Not Modified` instead when it matches the
+	// client's `If-None-Match` header. The entity tag comes from an `ETag`
+	// output header, an output or body implementing `ETagger`, or otherwise a
+	// hash of the serialized body. Known entity tags let unchanged resources
+	// skip serialization entirely.
+	AutoETag bool
+
+	// CORS enables Cross-Origin Resource Sharing when set. Preflight requests
+	// are handled automatically using the methods registered for each path.
+	// See `huma.CORSConfig` for details.
+	CORS *CORSConfig
 }
 
 // API represents a Huma API wrapping a specific router.
@@ -190,6 +489,51 @@ type API interface {
 	Middlewares() Middlewares
 }
 
+// ConfigProvider is implemented by APIs which expose the configuration they
+// were created with, including any defaults filled in by `huma.NewAPI`. It is
+// separate from `API` so that custom `API` implementations keep working. The
+// returned config is shared and must not be modified.
+type ConfigProvider interface {
+	Config() *Config
+}
+
+// configOf returns the API's config, or an empty config if the API does not
+// implement `ConfigProvider`.
+func configOf(api API) *Config {
+	if p, ok := api.(ConfigProvider); ok {
+		if c := p.Config(); c != nil {
+			return c
+		}
+	}
+	return &Config{}
+}
+
+// LimitBody returns the request body limited to the API's default maximum
+// body size from `Config.MaxBodyBytes`, or 1MB if unset. It is meant for
+// handlers registered directly on the adapter which read request bodies
+// themselves. Reading past the limit returns an `*http.MaxBytesError`.
+//
+//	body, err := io.ReadAll(huma.LimitBody(api, ctx))
+//	var tooLarge *http.MaxBytesError
+//	if errors.As(err, &tooLarge) {
+//		// Respond with `413 Request Entity Too Large`.
+//	}
+func LimitBody(api API, ctx Context) io.Reader {
+	limit := maxBodyBytes(api)
+	if limit <= 0 {
+		return ctx.BodyReader()
+	}
+	return http.MaxBytesReader(nil, io.NopCloser(ctx.BodyReader()), limit)
+}
+
+// maxBodyBytes returns the API-wide default maximum request body size.
+func maxBodyBytes(api API) int64 {
+	if limit := configOf(api).MaxBodyBytes; limit != 0 {
+		return limit
+	}
+	return 1024 * 1024
+}
+
 // Format represents a request / response format. It is used to marshal and
 // unmarshal data.
 type Format struct {
@@ -205,8 +549,78 @@ type api struct {
 	adapter      Adapter
 	formats      map[string]Format
 	formatKeys   []string
-	transformers []Transformer
+	transformers []ConditionalTransformer
 	middlewares  Middlewares
+	spec         specCache
+}
+
+// specCache memoizes the rendered OpenAPI document so it is only marshaled
+// when first requested, rather than on every request. It is invalidated
+// whenever an operation is added to the OpenAPI.
+type specCache struct {
+	mu       sync.RWMutex
+	specJSON []byte
+	specYAML []byte
+
+	// compact merges identical anonymous schemas, see `CompactSchemas`.
+	compact bool
+}
+
+// invalidate the cached document. It is an `AddOpFunc` so it can be called
+// whenever an operation is added.
+func (c *specCache) invalidate(oapi *OpenAPI, op *Operation) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.specJSON = nil
+	c.specYAML = nil
+}
+
+// JSON returns the cached JSON document, rendering it if needed.
+func (c *specCache) JSON(oapi *OpenAPI) []byte {
+	c.mu.RLock()
+	b := c.specJSON
+	c.mu.RUnlock()
+	if b != nil {
+		return b
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.render(oapi)
+	return c.specJSON
+}
+
+// YAML returns the cached YAML document, rendering it if needed.
+func (c *specCache) YAML(oapi *OpenAPI) []byte {
+	c.mu.RLock()
+	b := c.specYAML
+	c.mu.RUnlock()
+	if b != nil {
+		return b
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.render(oapi)
+	if c.specYAML == nil {
+		// Convert the already rendered JSON rather than marshaling again.
+		buf := bytes.NewBuffer([]byte{})
+		yaml.Convert(buf, bytes.NewReader(c.specJSON))
+		c.specYAML = buf.Bytes()
+	}
+	return c.specYAML
+}
+
+// render the JSON document if it is not yet cached. The caller must hold the
+// write lock.
+func (c *specCache) render(oapi *OpenAPI) {
+	if c.specJSON == nil {
+		if c.compact {
+			c.specJSON, _ = CompactSchemas(oapi)
+		} else {
+			c.specJSON, _ = json.Marshal(oapi)
+		}
+	}
 }
 
 func (a *api) Adapter() Adapter {
@@ -217,6 +631,10 @@ func (a *api) OpenAPI() *OpenAPI {
 	return a.config.OpenAPI
 }
 
+func (a *api) Config() *Config {
+	return &a.config
+}
+
 func (a *api) Unmarshal(contentType string, data []byte, v any) error {
 	// Handle e.g. `application/json; charset=utf-8` or `my/format+json`
 	start := strings.IndexRune(contentType, '+') + 1
@@ -249,8 +667,12 @@ func (a *api) Negotiate(accept string) (string, error) {
 
 func (a *api) Transform(ctx Context, status string, v any) (any, error) {
 	var err error
-	for _, t := range a.transformers {
-		v, err = t(ctx, status, v)
+	for i := range a.transformers {
+		t := &a.transformers[i]
+		if !t.matches(a, ctx, status) {
+			continue
+		}
+		v, err = t.Transformer(ctx, status, v)
 		if err != nil {
 			return nil, err
 		}
@@ -265,8 +687,18 @@ func (a *api) Marshal(w io.Writer, ct string, v any) error {
 		f, ok = a.formats[ct[start:]]
 	}
 	if !ok {
+		// Custom error formats are given the error model as-is.
+		if f, ok = a.config.ErrorFormats[ct]; ok {
+			return f.Marshal(w, v)
+		}
 		return fmt.Errorf("unknown content type: %s", ct)
 	}
+	switch e := v.(type) {
+	case *ErrorModel:
+		v = (*extendedError)(e)
+	case *ErrorDetail:
+		v = (*extendedDetail)(e)
+	}
 	return f.Marshal(w, v)
 }
 
@@ -293,15 +725,29 @@ func (a *api) Middlewares() Middlewares {
 //	config := huma.DefaultConfig("Example API", "1.0.0")
 //	api := huma.NewAPI(config, adapter)
 func NewAPI(config Config, a Adapter) API {
+	if config.OpenAPI == nil {
+		config.OpenAPI = &OpenAPI{}
+	}
+
+	if config.Dependencies == nil {
+		config.Dependencies = NewDependencies()
+	}
+
+	var cors *corsAdapter
+	if config.CORS != nil {
+		// The built-in routes below are simple `GET` requests which never need
+		// a preflight, and registering one could conflict with user routes in
+		// some routers.
+		cors = newCORSAdapter(a, config.CORS)
+		cors.skipPreflight = true
+		a = cors
+	}
+
 	newAPI := &api{
 		config:       config,
 		adapter:      a,
 		formats:      map[string]Format{},
-		transformers: config.Transformers,
-	}
-
-	if config.OpenAPI == nil {
-		config.OpenAPI = &OpenAPI{}
+		transformers: sortTransformers(config.Transformers, config.ConditionalTransformers),
 	}
 
 	if config.OpenAPI.OpenAPI == "" {
@@ -316,9 +762,20 @@ func NewAPI(config Config, a Adapter) API {
 		config.OpenAPI.Components.Schemas = NewMapRegistry("#/components/schemas/", DefaultSchemaNamer)
 	}
 
+	if config.OnSchema != nil {
+		hooks, ok := config.OpenAPI.Components.Schemas.(SchemaHookRegistry)
+		if !ok {
+			panic("schema registry does not support OnSchema hooks")
+		}
+		hooks.OnSchema(config.OnSchema)
+	}
+
 	if config.DefaultFormat == "" && config.Formats["application/json"].Marshal != nil {
 		config.DefaultFormat = "application/json"
 	}
+	newAPI.config = config
+	newAPI.spec.compact = config.CompactSchemas
+
 	if config.DefaultFormat != "" {
 		newAPI.formatKeys = append(newAPI.formatKeys, config.DefaultFormat)
 	}
@@ -328,27 +785,22 @@ func NewAPI(config Config, a Adapter) API {
 	}
 
 	if config.OpenAPIPath != "" {
-		var specJSON []byte
+		// The spec is rendered lazily on first request and cached until another
+		// operation is registered.
+		config.OpenAPI.OnAddOperation = append(config.OpenAPI.OnAddOperation, newAPI.spec.invalidate)
 		a.Handle(&Operation{
 			Method: http.MethodGet,
 			Path:   config.OpenAPIPath + ".json",
 		}, func(ctx Context) {
 			ctx.SetHeader("Content-Type", "application/vnd.oai.openapi+json")
-			if specJSON == nil {
-				specJSON, _ = json.Marshal(newAPI.OpenAPI())
-			}
-			ctx.BodyWriter().Write(specJSON)
+			ctx.BodyWriter().Write(newAPI.spec.JSON(newAPI.OpenAPI()))
 		})
-		var specYAML []byte
 		a.Handle(&Operation{
 			Method: http.MethodGet,
 			Path:   config.OpenAPIPath + ".yaml",
 		}, func(ctx Context) {
 			ctx.SetHeader("Content-Type", "application/vnd.oai.openapi+yaml")
-			if specYAML == nil {
-				specYAML, _ = newAPI.OpenAPI().YAML()
-			}
-			ctx.BodyWriter().Write(specYAML)
+			ctx.BodyWriter().Write(newAPI.spec.YAML(newAPI.OpenAPI()))
 		})
 	}
 
@@ -384,7 +836,10 @@ func NewAPI(config Config, a Adapter) API {
 		})
 	}
 
+	setupProblemTypes(config, a)
+
 	if config.SchemasPath != "" {
+		rxSchema := regexp.MustCompile(regexp.QuoteMeta(registryPrefix(config.OpenAPI.Components.Schemas)) + `([^"]+)`)
 		a.Handle(&Operation{
 			Method: http.MethodGet,
 			Path:   config.SchemasPath + "/{schema}",
@@ -398,5 +853,35 @@ func NewAPI(config Config, a Adapter) API {
 		})
 	}
 
+	if cors != nil {
+		cors.skipPreflight = false
+	}
+
 	return newAPI
 }
+
+// DocsOnly returns an `http.Handler` which serves only the API's OpenAPI
+// document, documentation, and schemas at the paths set in its config, using
+// the built-in router. None of the API's operations are served, making it
+// useful for publishing documentation without running the service itself.
+// Call it once all operations have been registered.
+//
+//	http.ListenAndServe(":8888", huma.DocsOnly(api))
+func DocsOnly(api API) http.Handler {
+	config := *configOf(api)
+	// Hooks have already been added to the shared registry.
+	config.OnSchema = nil
+
+	// Setting up the docs API adds hooks & extensions to its OpenAPI, so it
+	// uses a copy to leave the live API's document untouched.
+	oapi := *api.OpenAPI()
+	oapi.OnAddOperation = nil
+	if oapi.Extensions != nil {
+		oapi.Extensions = make(map[string]any, len(oapi.Extensions))
+		for k, v := range api.OpenAPI().Extensions {
+			oapi.Extensions[k] = v
+		}
+	}
+	config.OpenAPI = &oapi
+	return NewAPI(config, NewRouter()).Adapter()
+}
@@ -1,7 +1,11 @@
 package huma_test
 
 import (
+	"context"
+	"io"
 	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/danielgtaylor/huma/v2"
@@ -67,3 +71,195 @@ func ExampleAdapter_handle() {
 		ctx.BodyWriter().Write([]byte("Hello, " + name))
 	})
 }
+
+func TestCustomAPI(t *testing.T) {
+	// APIs are not required to expose their config.
+	_, api := humatest.New(t, huma.DefaultConfig("Test API", "1.0.0"))
+	custom := struct{ huma.API }{api}
+	_, ok := huma.API(custom).(huma.ConfigProvider)
+	assert.False(t, ok)
+
+	huma.Register(custom, huma.Operation{
+		Method: http.MethodGet,
+		Path:   "/test",
+	}, func(ctx context.Context, input *struct{}) (*struct{ Body string }, error) {
+		return &struct{ Body string }{Body: "hello"}, nil
+	})
+
+	resp := api.Get("/test")
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, `"hello"`+"\n", resp.Body.String())
+
+	assert.Panics(t, func() {
+		huma.Provide(custom, "value")
+	})
+}
+
+func TestConditionalTransformers(t *testing.T) {
+	appendTransformer := func(suffix string) huma.Transformer {
+		return func(ctx huma.Context, status string, v any) (any, error) {
+			if m, ok := v.(map[string]any); ok {
+				m["order"] = m["order"].(string) + suffix
+			}
+			return v, nil
+		}
+	}
+
+	config := huma.DefaultConfig("Test API", "1.0.0")
+	config.Transformers = append(config.Transformers, appendTransformer("b"))
+	config.ConditionalTransformers = []huma.ConditionalTransformer{
+		{Transformer: appendTransformer("c"), Priority: 10},
+		{Transformer: appendTransformer("a"), Priority: -10},
+		{Transformer: appendTransformer("x"), Operations: []string{"other"}},
+		{Transformer: appendTransformer("y"), ContentTypes: []string{"application/cbor"}},
+	}
+	_, api := humatest.New(t, config)
+
+	type Output struct {
+		Body map[string]any
+	}
+
+	huma.Register(api, huma.Operation{
+		OperationID: "test",
+		Method:      http.MethodGet,
+		Path:        "/test",
+	}, func(ctx context.Context, input *struct{}) (*Output, error) {
+		return &Output{Body: map[string]any{"order": ""}}, nil
+	})
+
+	resp := api.Get("/test")
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, `{"order": "abc"}`, resp.Body.String())
+
+	// Conditional transformers skip errors unless enabled.
+	called := false
+	config.ConditionalTransformers = []huma.ConditionalTransformer{
+		{Transformer: func(ctx huma.Context, status string, v any) (any, error) {
+			called = true
+			return v, nil
+		}},
+	}
+	_, api = humatest.New(t, config)
+	huma.Register(api, huma.Operation{
+		Method: http.MethodGet,
+		Path:   "/error",
+	}, func(ctx context.Context, input *struct{}) (*struct{}, error) {
+		return nil, huma.Error400BadRequest("bad")
+	})
+
+	resp = api.Get("/error")
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+	assert.False(t, called)
+}
+
+func TestOpenAPICache(t *testing.T) {
+	_, api := humatest.New(t, huma.DefaultConfig("Test API", "1.0.0"))
+
+	huma.Register(api, huma.Operation{
+		OperationID: "get-first",
+		Method:      http.MethodGet,
+		Path:        "/first",
+	}, func(ctx context.Context, input *struct{}) (*struct{}, error) {
+		return nil, nil
+	})
+
+	resp := api.Get("/openapi.json")
+	assert.Contains(t, resp.Body.String(), "/first")
+
+	// Cached responses are identical.
+	assert.Equal(t, resp.Body.String(), api.Get("/openapi.json").Body.String())
+
+	// Registering another operation invalidates the cache.
+	huma.Register(api, huma.Operation{
+		OperationID: "get-second",
+		Method:      http.MethodGet,
+		Path:        "/second",
+	}, func(ctx context.Context, input *struct{}) (*struct{}, error) {
+		return nil, nil
+	})
+
+	assert.Contains(t, api.Get("/openapi.json").Body.String(), "/second")
+	assert.Contains(t, api.Get("/openapi.yaml").Body.String(), "/second")
+}
+
+type DocsThing struct {
+	ID string `json:"id"`
+}
+
+func TestDocsOnly(t *testing.T) {
+	_, api := humatest.New(t, huma.DefaultConfig("Test API", "1.0.0"))
+
+	huma.Register(api, huma.Operation{
+		OperationID: "get-thing",
+		Method:      http.MethodGet,
+		Path:        "/things/{id}",
+	}, func(ctx context.Context, input *struct {
+		ID string `path:"id"`
+	}) (*struct{ Body DocsThing }, error) {
+		return nil, nil
+	})
+
+	hooks := len(api.OpenAPI().OnAddOperation)
+	docs := huma.DocsOnly(api)
+
+	// The live API's OpenAPI is not modified.
+	assert.Len(t, api.OpenAPI().OnAddOperation, hooks)
+
+	for _, path := range []string{"/openapi.json", "/openapi.yaml", "/docs", "/schemas/DocsThing.json"} {
+		w := httptest.NewRecorder()
+		docs.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		assert.Equal(t, http.StatusOK, w.Code, path)
+	}
+
+	w := httptest.NewRecorder()
+	docs.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+	assert.Contains(t, w.Body.String(), "/things/{id}")
+
+	// Operations are documented but not served.
+	w = httptest.NewRecorder()
+	docs.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/things/123", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+type remoteAddrContext struct {
+	huma.ContextWrapper
+}
+
+func (c *remoteAddrContext) RemoteAddr() string {
+	return "192.0.2.1:1234"
+}
+
+func TestRemoteAddr(t *testing.T) {
+	assert.Equal(t, "192.0.2.1:1234", huma.RemoteAddr(huma.WrapContext(&remoteAddrContext{})))
+
+	// Adapters don't have to provide the address.
+	assert.Equal(t, "", huma.RemoteAddr(huma.WrapContext(nil)))
+}
+
+func TestLimitBody(t *testing.T) {
+	config := huma.DefaultConfig("Test API", "1.0.0")
+	config.MaxBodyBytes = 5
+	_, api := humatest.New(t, config)
+
+	read := func(body string) ([]byte, error) {
+		r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+		ctx := humatest.NewContext(&huma.Operation{}, r, httptest.NewRecorder())
+		return io.ReadAll(huma.LimitBody(api, ctx))
+	}
+
+	b, err := read("hello")
+	assert.NoError(t, err)
+	assert.Equal(t, "hello", string(b))
+
+	_, err = read("hello world")
+	var tooLarge *http.MaxBytesError
+	assert.ErrorAs(t, err, &tooLarge)
+	assert.EqualValues(t, 5, tooLarge.Limit)
+
+	// Unlimited bodies are read in full.
+	config.MaxBodyBytes = -1
+	_, api = humatest.New(t, config)
+	b, err = read("hello world")
+	assert.NoError(t, err)
+	assert.Equal(t, "hello world", string(b))
+}
@@ -0,0 +1,201 @@
+// Package apikey provides API key authentication for Huma APIs. Keys are read
+// from the header, query parameter, or cookie declared by an `apiKey`
+// security scheme in the OpenAPI, and checked using a lookup function you
+// provide, e.g. one which queries your database.
+//
+//	config := huma.DefaultConfig("My API", "1.0.0")
+//	config.Components.SecuritySchemes = map[string]*huma.SecurityScheme{
+//		"apiKey": {Type: "apiKey", In: "header", Name: "X-API-Key"},
+//	}
+//	api := humachi.New(router, config)
+//	apikey.Use(api, apikey.Config{
+//		SchemeName: "apiKey",
+//		Lookup: func(ctx context.Context, key string) (any, error) {
+//			account, ok := accounts[key]
+//			if !ok {
+//				return nil, apikey.ErrInvalidKey
+//			}
+//			return account, nil
+//		},
+//	})
+package apikey
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// ErrInvalidKey should be returned by `Config.Lookup` when the key is not
+// valid, resulting in a `401 Unauthorized` response.
+var ErrInvalidKey = errors.New("invalid API key")
+
+var errMissingKey = errors.New("missing API key")
+
+// Config configures API key authentication.
+type Config struct {
+	// SchemeName is the name of the `apiKey` security scheme in
+	// `OpenAPI.Components.SecuritySchemes` which describes where to find the
+	// key. Operations whose security requirements include this scheme are
+	// authenticated.
+	SchemeName string
+
+	// Lookup checks the key and returns a value describing who it belongs to,
+	// which handlers can get via `apikey.GetPrincipal`. Return an error
+	// wrapping `ErrInvalidKey` for unknown keys. Any other error results in a
+	// `500 Internal Server Error`.
+	Lookup func(ctx context.Context, key string) (any, error)
+}
+
+type principalKey struct{}
+
+// GetPrincipal returns the value returned by `Config.Lookup` for the current
+// request, or nil if the request was not authenticated with an API key.
+//
+//	func handler(ctx context.Context, input *MyInput) (*MyOutput, error) {
+//		account := apikey.GetPrincipal(ctx).(*Account)
+//		// ...
+//	}
+func GetPrincipal(ctx context.Context) any {
+	return ctx.Value(principalKey{})
+}
+
+// principalContext overrides the request context to include the principal.
+type principalContext struct {
+	huma.ContextWrapper
+	ctx context.Context
+}
+
+func (c *principalContext) Context() context.Context {
+	return c.ctx
+}
+
+// requirement returns whether the operation uses the scheme and whether it is
+// required, i.e. there is no other security alternative without it.
+func (c *Config) requirement(oapi *huma.OpenAPI, op *huma.Operation) (used, required bool) {
+	security := op.Security
+	if security == nil {
+		security = oapi.Security
+	}
+
+	required = len(security) > 0
+	for _, alternative := range security {
+		if _, ok := alternative[c.SchemeName]; ok {
+			used = true
+		} else {
+			required = false
+		}
+	}
+	return used, used && required
+}
+
+// readKey returns the API key from the request, or an empty string if it was
+// not sent.
+func readKey(ctx huma.Context, scheme *huma.SecurityScheme) string {
+	switch scheme.In {
+	case "header":
+		return ctx.Header(scheme.Name)
+	case "query":
+		return ctx.Query(scheme.Name)
+	case "cookie":
+		var headers []string
+		ctx.EachHeader(func(name, value string) {
+			if strings.EqualFold(name, "Cookie") {
+				headers = append(headers, value)
+			}
+		})
+		req := http.Request{Header: http.Header{"Cookie": headers}}
+		if c, err := req.Cookie(scheme.Name); err == nil {
+			return c.Value
+		}
+	}
+	return ""
+}
+
+// Use adds API key authentication middleware to the API and documents the
+// `401` response for authenticated operations in the OpenAPI. Like other
+// middleware, it must be called before registering operations with
+// `huma.Register` in order to apply to them. It panics if the security scheme
+// is not declared as an `apiKey` scheme.
+//
+// Operations are authenticated when their `Security` requirements (or the
+// top-level `OpenAPI.Security` if unset) include the configured scheme. When
+// every alternative requires the scheme, requests without a valid key get a
+// `401 Unauthorized`. When another alternative could be used instead, e.g. a
+// bearer token or `{}` for anonymous access, keys are checked if present but
+// not required.
+func Use(api huma.API, config Config) {
+	oapi := api.OpenAPI()
+	var scheme *huma.SecurityScheme
+	if oapi.Components != nil {
+		scheme = oapi.Components.SecuritySchemes[config.SchemeName]
+	}
+	if scheme == nil || scheme.Type != "apiKey" || scheme.Name == "" {
+		panic(fmt.Errorf("apikey: security scheme %q must be declared with type apiKey and a name", config.SchemeName))
+	}
+	if scheme.In != "header" && scheme.In != "query" && scheme.In != "cookie" {
+		panic(fmt.Errorf("apikey: security scheme %q has unsupported location %q", config.SchemeName, scheme.In))
+	}
+	if config.Lookup == nil {
+		panic(errors.New("apikey: Lookup must be set"))
+	}
+
+	oapi.OnAddOperation = append(oapi.OnAddOperation, config.document)
+
+	api.UseMiddleware(func(ctx huma.Context, next func(huma.Context)) {
+		used, required := config.requirement(oapi, ctx.Operation())
+		if !used {
+			next(ctx)
+			return
+		}
+
+		key := readKey(ctx, scheme)
+		if key == "" {
+			if required {
+				huma.WriteErr(api, ctx, http.StatusUnauthorized, "unauthorized", errMissingKey)
+				return
+			}
+			next(ctx)
+			return
+		}
+
+		principal, err := config.Lookup(ctx.Context(), key)
+		if err != nil {
+			if errors.Is(err, ErrInvalidKey) {
+				huma.WriteErr(api, ctx, http.StatusUnauthorized, "unauthorized", err)
+				return
+			}
+			huma.WriteErr(api, ctx, http.StatusInternalServerError, "unable to check API key")
+			return
+		}
+
+		next(&principalContext{
+			ContextWrapper: huma.WrapContext(ctx),
+			ctx:            context.WithValue(ctx.Context(), principalKey{}, principal),
+		})
+	})
+}
+
+// document adds the `401` response to the OpenAPI for operations which use
+// the security scheme.
+func (c *Config) document(oapi *huma.OpenAPI, op *huma.Operation) {
+	if used, _ := c.requirement(oapi, op); !used {
+		return
+	}
+
+	code := strconv.Itoa(http.StatusUnauthorized)
+	if op.Responses == nil {
+		op.Responses = map[string]*huma.Response{}
+	}
+	if op.Responses[code] == nil {
+		op.Responses[code] = &huma.Response{
+			Description: http.StatusText(http.StatusUnauthorized),
+			Content:     huma.ErrorContent(op),
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package apikey
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/humatest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type account struct {
+	Name string
+}
+
+func lookup(ctx context.Context, key string) (any, error) {
+	switch key {
+	case "secret":
+		return &account{Name: "alice"}, nil
+	case "broken":
+		return nil, errors.New("database unavailable")
+	}
+	return nil, fmt.Errorf("unknown key: %w", ErrInvalidKey)
+}
+
+func newTestAPI(t *testing.T, in, name string) humatest.TestAPI {
+	config := huma.DefaultConfig("Test API", "1.0.0")
+	config.Components.SecuritySchemes = map[string]*huma.SecurityScheme{
+		"apiKey": {Type: "apiKey", In: in, Name: name},
+	}
+	_, api := humatest.New(t, config)
+	Use(api, Config{SchemeName: "apiKey", Lookup: lookup})
+
+	for _, op := range []huma.Operation{
+		{OperationID: "public", Path: "/public"},
+		{OperationID: "private", Path: "/private", Security: []map[string][]string{{"apiKey": {}}}},
+		{OperationID: "optional", Path: "/optional", Security: []map[string][]string{{"apiKey": {}}, {}}},
+	} {
+		op.Method = http.MethodGet
+		huma.Register(api, op, func(ctx context.Context, input *struct{}) (*struct {
+			Body string
+		}, error) {
+			name := "anonymous"
+			if a, ok := GetPrincipal(ctx).(*account); ok {
+				name = a.Name
+			}
+			return &struct{ Body string }{Body: name}, nil
+		})
+	}
+	return api
+}
+
+func TestLocations(t *testing.T) {
+	for _, tc := range []struct {
+		in   string
+		name string
+		path string
+		args []any
+	}{
+		{"header", "X-API-Key", "/private", []any{"X-API-Key: secret"}},
+		{"query", "api_key", "/private?api_key=secret", nil},
+		{"cookie", "session", "/private", []any{"Cookie: other=1; session=secret"}},
+	} {
+		t.Run(tc.in, func(t *testing.T) {
+			api := newTestAPI(t, tc.in, tc.name)
+			resp := api.Get(tc.path, tc.args...)
+			assert.Equal(t, http.StatusOK, resp.Code)
+			assert.JSONEq(t, `"alice"`, resp.Body.String())
+		})
+	}
+}
+
+func TestUse(t *testing.T) {
+	api := newTestAPI(t, "header", "X-API-Key")
+
+	// Operations without the scheme are not authenticated.
+	assert.Equal(t, http.StatusOK, api.Get("/public").Code)
+
+	// A key is required.
+	resp := api.Get("/private")
+	assert.Equal(t, http.StatusUnauthorized, resp.Code)
+	assert.Contains(t, resp.Body.String(), "missing API key")
+
+	resp = api.Get("/private", "X-API-Key: wrong")
+	assert.Equal(t, http.StatusUnauthorized, resp.Code)
+	assert.Contains(t, resp.Body.String(), "unknown key: invalid API key")
+
+	// Lookup failures are server errors.
+	resp = api.Get("/private", "X-API-Key: broken")
+	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+	assert.NotContains(t, resp.Body.String(), "database")
+
+	// Keys are optional when another alternative is allowed.
+	resp = api.Get("/optional")
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, `"anonymous"`, resp.Body.String())
+	assert.Equal(t, http.StatusUnauthorized, api.Get("/optional", "X-API-Key: wrong").Code)
+	assert.JSONEq(t, `"alice"`, api.Get("/optional", "X-API-Key: secret").Body.String())
+
+	// The 401 response is documented.
+	paths := api.OpenAPI().Paths
+	assert.Nil(t, paths["/public"].Get.Responses["401"])
+	for _, path := range []string{"/private", "/optional"} {
+		resp := paths[path].Get.Responses["401"]
+		require.NotNil(t, resp, path)
+		assert.NotEmpty(t, resp.Content)
+	}
+}
+
+func TestGlobalSecurity(t *testing.T) {
+	config := huma.DefaultConfig("Test API", "1.0.0")
+	config.Components.SecuritySchemes = map[string]*huma.SecurityScheme{
+		"apiKey": {Type: "apiKey", In: "header", Name: "X-API-Key"},
+	}
+	config.Security = []map[string][]string{{"apiKey": {}}}
+	_, api := humatest.New(t, config)
+	Use(api, Config{SchemeName: "apiKey", Lookup: lookup})
+
+	huma.Register(api, huma.Operation{
+		OperationID: "test",
+		Method:      http.MethodGet,
+		Path:        "/test",
+	}, func(ctx context.Context, input *struct{}) (*struct{}, error) {
+		return nil, nil
+	})
+
+	// An explicitly empty security list opts out of the global requirement.
+	huma.Register(api, huma.Operation{
+		OperationID: "health",
+		Method:      http.MethodGet,
+		Path:        "/health",
+		Security:    []map[string][]string{},
+	}, func(ctx context.Context, input *struct{}) (*struct{}, error) {
+		return nil, nil
+	})
+
+	assert.Equal(t, http.StatusUnauthorized, api.Get("/test").Code)
+	assert.Equal(t, http.StatusNoContent, api.Get("/test", "X-API-Key: secret").Code)
+	assert.Equal(t, http.StatusNoContent, api.Get("/health").Code)
+}
+
+func TestInvalidScheme(t *testing.T) {
+	config := huma.DefaultConfig("Test API", "1.0.0")
+	config.Components.SecuritySchemes = map[string]*huma.SecurityScheme{
+		"bearer": {Type: "http", Scheme: "bearer"},
+	}
+	_, api := humatest.New(t, config)
+
+	assert.Panics(t, func() {
+		Use(api, Config{SchemeName: "missing", Lookup: lookup})
+	})
+	assert.Panics(t, func() {
+		Use(api, Config{SchemeName: "bearer", Lookup: lookup})
+	})
+}
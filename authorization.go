@@ -0,0 +1,110 @@
+package huma
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+)
+
+var (
+	errAuthorizationFormat = errors.New("expected scheme and credentials like `Bearer abc123`")
+	errBasicEncoding       = errors.New("expected base64 encoded basic credentials")
+	errBasicFormat         = errors.New("expected basic credentials like `username:password`")
+	errBearerFormat        = errors.New("expected bearer token made of letters, digits, and `-._~+/` followed by optional `=` padding")
+)
+
+// Authorization is an input parameter which parses the `Authorization`
+// header into its scheme and credentials. Basic credentials are decoded into
+// `Username` and `Password`, and bearer tokens are extracted into `Token`.
+// Malformed headers are rejected with a validation error, without echoing
+// the credentials back to the client.
+//
+//	type MyInput struct {
+//		Auth huma.Authorization `header:"Authorization" required:"true"`
+//	}
+//
+//	func handler(ctx context.Context, input *MyInput) (*MyOutput, error) {
+//		if !input.Auth.IsScheme("Bearer") {
+//			return nil, huma.Error401Unauthorized("bearer token required")
+//		}
+//		claims, err := verify(input.Auth.Token)
+//		// ...
+//	}
+type Authorization struct {
+	// Scheme is the authentication scheme as sent by the client, e.g.
+	// `Basic` or `Bearer`. Schemes are case-insensitive, see `IsScheme`.
+	Scheme string
+
+	// Credentials are the raw credentials following the scheme.
+	Credentials string
+
+	// Username and Password are set for the `Basic` scheme.
+	Username string
+	Password string
+
+	// Token is set for the `Bearer` scheme.
+	Token string
+}
+
+// IsScheme returns whether the authentication scheme matches, ignoring case.
+func (a Authorization) IsScheme(scheme string) bool {
+	return strings.EqualFold(a.Scheme, scheme)
+}
+
+func (a *Authorization) UnmarshalText(text []byte) error {
+	*a = Authorization{}
+	scheme, credentials, _ := strings.Cut(strings.TrimSpace(string(text)), " ")
+	credentials = strings.TrimSpace(credentials)
+	if scheme == "" || credentials == "" {
+		return errAuthorizationFormat
+	}
+	a.Scheme = scheme
+	a.Credentials = credentials
+
+	switch {
+	case a.IsScheme("Basic"):
+		decoded, err := base64.StdEncoding.DecodeString(credentials)
+		if err != nil {
+			return errBasicEncoding
+		}
+		username, password, ok := strings.Cut(string(decoded), ":")
+		if !ok {
+			return errBasicFormat
+		}
+		a.Username = username
+		a.Password = password
+	case a.IsScheme("Bearer"):
+		if !isToken68(credentials) {
+			return errBearerFormat
+		}
+		a.Token = credentials
+	}
+	return nil
+}
+
+func (a *Authorization) Schema(r Registry) *Schema {
+	return &Schema{
+		Type:        TypeString,
+		Description: "Credentials as the scheme followed by a space and the credentials, e.g. `Bearer abc123`.",
+		Extensions:  map[string]any{"x-sensitive": true},
+	}
+}
+
+// isToken68 returns whether `s` matches the RFC 9110 `token68` syntax used by
+// bearer tokens.
+func isToken68(s string) bool {
+	end := strings.TrimRight(s, "=")
+	if end == "" {
+		return false
+	}
+	for i := 0; i < len(end); i++ {
+		c := end[i]
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '-' || c == '.' || c == '_' || c == '~' || c == '+' || c == '/':
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,111 @@
+package huma_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/humatest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthorizationUnmarshal(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		value    string
+		expected huma.Authorization
+		err      string
+	}{
+		{
+			name:  "basic",
+			value: "Basic dXNlcjpwYXNzOndvcmQ=",
+			expected: huma.Authorization{
+				Scheme:      "Basic",
+				Credentials: "dXNlcjpwYXNzOndvcmQ=",
+				Username:    "user",
+				Password:    "pass:word",
+			},
+		},
+		{
+			name:  "bearer",
+			value: "bearer abc.DEF-123_~+/==",
+			expected: huma.Authorization{
+				Scheme:      "bearer",
+				Credentials: "abc.DEF-123_~+/==",
+				Token:       "abc.DEF-123_~+/==",
+			},
+		},
+		{
+			name:  "other",
+			value: `Digest username="user", realm="api"`,
+			expected: huma.Authorization{
+				Scheme:      "Digest",
+				Credentials: `username="user", realm="api"`,
+			},
+		},
+		{name: "missing credentials", value: "Bearer", err: "expected scheme and credentials"},
+		{name: "bad base64", value: "Basic !!!", err: "expected base64"},
+		{name: "missing colon", value: "Basic dXNlcg==", err: "username:password"},
+		{name: "bad token", value: "Bearer a b", err: "expected bearer token"},
+		{name: "only padding", value: "Bearer ==", err: "expected bearer token"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			a := huma.Authorization{}
+			err := a.UnmarshalText([]byte(tc.value))
+			if tc.err != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, a)
+		})
+	}
+}
+
+func TestAuthorizationInput(t *testing.T) {
+	_, api := humatest.New(t, huma.DefaultConfig("Test API", "1.0.0"))
+
+	huma.Register(api, huma.Operation{
+		OperationID: "me",
+		Method:      http.MethodGet,
+		Path:        "/me",
+	}, func(ctx context.Context, input *struct {
+		Auth huma.Authorization `header:"Authorization" required:"true"`
+	}) (*struct {
+		Body struct {
+			User string `json:"user"`
+		}
+	}, error) {
+		if !input.Auth.IsScheme("Basic") {
+			return nil, huma.Error401Unauthorized("basic credentials required")
+		}
+		out := &struct {
+			Body struct {
+				User string `json:"user"`
+			}
+		}{}
+		out.Body.User = input.Auth.Username
+		return out, nil
+	})
+
+	resp := api.Get("/me", "Authorization: Basic dXNlcjpwYXNz")
+	assert.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+	assert.Contains(t, resp.Body.String(), `"user":"user"`)
+
+	resp = api.Get("/me", "Authorization: Bearer abc")
+	assert.Equal(t, http.StatusUnauthorized, resp.Code)
+
+	resp = api.Get("/me")
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+	assert.Contains(t, resp.Body.String(), "header.Authorization")
+
+	// Malformed credentials are not echoed back.
+	resp = api.Get("/me", "Authorization: Basic c2VjcmV0")
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+	assert.Contains(t, resp.Body.String(), "username:password")
+	assert.Contains(t, resp.Body.String(), huma.Redacted)
+	assert.NotContains(t, resp.Body.String(), "c2VjcmV0")
+}
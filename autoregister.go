@@ -0,0 +1,240 @@
+package huma
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/danielgtaylor/casing"
+)
+
+var contextType = reflect.TypeOf((*context.Context)(nil)).Elem()
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+var operationPtrType = reflect.TypeOf(&Operation{})
+
+// autoRegisterMethods maps method name prefixes to HTTP methods for use by
+// `AutoRegister`.
+var autoRegisterMethods = []struct {
+	prefix string
+	method string
+}{
+	{"Get", http.MethodGet},
+	{"Post", http.MethodPost},
+	{"Put", http.MethodPut},
+	{"Patch", http.MethodPatch},
+	{"Delete", http.MethodDelete},
+	{"Head", http.MethodHead},
+}
+
+// AutoRegister auto-detects operation registration methods and registers them
+// with the given API. Since registration happens at service startup, no errors
+// are returned and methods should panic on error. Two kinds of methods are
+// detected:
+//
+// Any method named `Register...` will be called and passed the API as the
+// only argument.
+//
+// When enabled by a blank field on the server struct tagged with
+// `infer:"true"`, any method with a handler signature like
+// `func(context.Context, *Input) (*Output, error)` whose name starts with an
+// HTTP method (`Get`, `Post`, `Put`, `Patch`, `Delete`, or `Head`) is also
+// registered as an operation. The method, path, and operation ID are
+// inferred from the name, e.g. `GetGreeting` becomes `GET /greeting` with an
+// operation ID of `get-greeting`. Any path parameters in the input struct
+// that are not already in the path are appended, e.g. `/greeting/{name}`.
+// Inference is opt-in so that handlers registered by a `Register...` method
+// are not exposed a second time.
+//
+// The blank field may also provide a path prefix and tags for all inferred
+// operations:
+//
+//	type GreetingService struct {
+//		_ struct{} `infer:"true" path:"/v1" tags:"Greetings"`
+//	}
+//
+// The inferred operation can be customized by providing a method with the
+// same name plus an `Operation` suffix, which is passed the operation before
+// it is registered:
+//
+//	func (s *GreetingService) GetGreetingOperation(op *huma.Operation) {
+//		op.Summary = "Get a greeting"
+//	}
+//
+// Example:
+//
+//	type ItemsHandler struct {
+//		_ struct{} `infer:"true"`
+//	}
+//
+//	func (s *ItemsHandler) RegisterListItems(api API) {
+//		huma.Register(api, huma.Operation{
+//			OperationID: "ListItems",
+//			Method: http.MethodGet,
+//			Path: "/items",
+//		}, s.ListItems)
+//	}
+//
+//	func (s *ItemsHandler) GetItem(ctx context.Context, input *GetItemInput) (*GetItemOutput, error) {
+//		// ...
+//	}
+//
+//	func main() {
+//		router := chi.NewMux()
+//		config := huma.DefaultConfig("My Service", "1.0.0")
+//		api := huma.NewExampleAPI(router, config)
+//
+//		itemsHandler := &ItemsHandler{}
+//		huma.AutoRegister(api, itemsHandler)
+//	}
+func AutoRegister(api API, server any) {
+	args := []reflect.Value{reflect.ValueOf(server), reflect.ValueOf(api)}
+
+	infer, prefix, tags := autoRegisterSettings(reflect.TypeOf(server))
+
+	t := reflect.TypeOf(server)
+	for i := 0; i < t.NumMethod(); i++ {
+		m := t.Method(i)
+		if strings.HasPrefix(m.Name, "Register") && len(m.Name) > 8 {
+			m.Func.Call(args)
+			continue
+		}
+
+		if !infer {
+			continue
+		}
+
+		method := autoRegisterMethod(m)
+		if method == "" {
+			continue
+		}
+
+		inputType := m.Type.In(2).Elem()
+		outputType := m.Type.Out(0).Elem()
+		if inputType.Kind() != reflect.Struct || outputType.Kind() != reflect.Struct {
+			continue
+		}
+
+		path := prefix
+		if resource := casing.Kebab(m.Name[len(autoRegisterPrefix(m.Name)):]); resource != "" {
+			path += "/" + resource
+		}
+		for _, name := range pathParamNames(inputType) {
+			if !strings.Contains(path, "{"+name+"}") {
+				path += "/{" + name + "}"
+			}
+		}
+		if path == "" {
+			path = "/"
+		}
+
+		op := Operation{
+			OperationID: casing.Kebab(m.Name),
+			Method:      method,
+			Path:        path,
+			Tags:        append([]string(nil), tags...),
+		}
+
+		if hook, ok := t.MethodByName(m.Name + "Operation"); ok {
+			if hook.Type.NumIn() == 2 && hook.Type.In(1) == operationPtrType && hook.Type.NumOut() == 0 {
+				hook.Func.Call([]reflect.Value{args[0], reflect.ValueOf(&op)})
+			}
+		}
+
+		f := m.Func
+		register(api, op, inputType, outputType, func(ctx context.Context, input any) (any, error) {
+			out := f.Call([]reflect.Value{args[0], reflect.ValueOf(ctx), reflect.ValueOf(input)})
+			err, _ := out[1].Interface().(error)
+			return out[0].Interface(), err
+		})
+	}
+}
+
+// autoRegisterPrefix returns the HTTP method prefix of a method name, or an
+// empty string if there isn't one. The prefix must be followed by an
+// uppercase letter or the end of the name, so e.g. `Getter` does not match.
+func autoRegisterPrefix(name string) string {
+	for _, entry := range autoRegisterMethods {
+		if !strings.HasPrefix(name, entry.prefix) {
+			continue
+		}
+		rest := name[len(entry.prefix):]
+		if rest == "" || (rest[0] >= 'A' && rest[0] <= 'Z') {
+			return entry.prefix
+		}
+	}
+	return ""
+}
+
+// autoRegisterMethod returns the HTTP method for a server method if it is
+// named and shaped like an operation handler, otherwise an empty string.
+func autoRegisterMethod(m reflect.Method) string {
+	prefix := autoRegisterPrefix(m.Name)
+	if prefix == "" {
+		return ""
+	}
+
+	mt := m.Type
+	if mt.NumIn() != 3 || mt.NumOut() != 2 {
+		return ""
+	}
+	if mt.In(1) != contextType || mt.In(2).Kind() != reflect.Pointer {
+		return ""
+	}
+	if mt.Out(0).Kind() != reflect.Pointer || mt.Out(1) != errorType {
+		return ""
+	}
+
+	for _, entry := range autoRegisterMethods {
+		if entry.prefix == prefix {
+			return entry.method
+		}
+	}
+	return ""
+}
+
+// autoRegisterSettings reads whether to infer operations, the path prefix,
+// and tags from blank fields on the server struct, if any.
+func autoRegisterSettings(t reflect.Type) (bool, string, []string) {
+	t = deref(t)
+	if t.Kind() != reflect.Struct {
+		return false, "", nil
+	}
+
+	infer := false
+	prefix := ""
+	var tags []string
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Name != "_" {
+			continue
+		}
+		if f.Tag.Get("infer") == "true" {
+			infer = true
+		}
+		if p := f.Tag.Get("path"); p != "" {
+			prefix = strings.TrimSuffix(p, "/")
+		}
+		if t := f.Tag.Get("tags"); t != "" {
+			for _, tag := range strings.Split(t, ",") {
+				tags = append(tags, strings.TrimSpace(tag))
+			}
+		}
+	}
+	return infer, prefix, tags
+}
+
+// pathParamNames returns the names of all path parameters in the input
+// struct, in field order.
+func pathParamNames(t reflect.Type) []string {
+	names := []string{}
+	for _, f := range reflect.VisibleFields(t) {
+		if f.Anonymous || !f.IsExported() {
+			continue
+		}
+		if name := f.Tag.Get("path"); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
@@ -4,9 +4,13 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"testing"
 
 	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/humatest"
 	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // Item represents a single item with a unique ID.
@@ -51,3 +55,105 @@ func ExampleAutoRegister() {
 	fmt.Println(api.OpenAPI().Paths["/items"].Get.OperationID)
 	// Output: list-items
 }
+
+// GreetingService has operation handlers which are registered by name.
+type GreetingService struct {
+	_ struct{} `infer:"true" path:"/v1" tags:"Greetings"`
+}
+
+type AutoGreetingInput struct {
+	Name string `path:"name"`
+}
+
+type AutoGreetingOutput struct {
+	Body struct {
+		Message string `json:"message"`
+	}
+}
+
+// GetGreeting is registered as `GET /v1/greeting/{name}`.
+func (s *GreetingService) GetGreeting(ctx context.Context, input *AutoGreetingInput) (*AutoGreetingOutput, error) {
+	resp := &AutoGreetingOutput{}
+	resp.Body.Message = "Hello, " + input.Name + "!"
+	return resp, nil
+}
+
+// GetGreetingOperation customizes the inferred `get-greeting` operation.
+func (s *GreetingService) GetGreetingOperation(op *huma.Operation) {
+	op.Summary = "Get a greeting"
+}
+
+// DeleteGreeting is registered as `DELETE /v1/greeting/{name}`.
+func (s *GreetingService) DeleteGreeting(ctx context.Context, input *AutoGreetingInput) (*struct{}, error) {
+	if input.Name == "bob" {
+		return nil, huma.Error403Forbidden("cannot delete bob")
+	}
+	return nil, nil
+}
+
+// Getter is not an operation handler since it has the wrong signature.
+func (s *GreetingService) Getter() string {
+	return ""
+}
+
+func ExampleAutoRegister_conventions() {
+	router := chi.NewMux()
+	api := NewExampleAPI(router, huma.DefaultConfig("My Service", "1.0.0"))
+
+	huma.AutoRegister(api, &GreetingService{})
+
+	op := api.OpenAPI().Paths["/v1/greeting/{name}"].Get
+	fmt.Println(op.OperationID, op.Summary, op.Tags)
+	// Output: get-greeting Get a greeting [Greetings]
+}
+
+func TestAutoRegisterConventions(t *testing.T) {
+	_, api := humatest.New(t)
+	huma.AutoRegister(api, &GreetingService{})
+
+	item := api.OpenAPI().Paths["/v1/greeting/{name}"]
+	require.NotNil(t, item)
+	require.NotNil(t, item.Delete)
+	assert.Equal(t, "delete-greeting", item.Delete.OperationID)
+
+	resp := api.Get("/v1/greeting/alice")
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Contains(t, resp.Body.String(), "Hello, alice!")
+
+	resp = api.Delete("/v1/greeting/alice")
+	assert.Equal(t, http.StatusNoContent, resp.Code)
+
+	resp = api.Delete("/v1/greeting/bob")
+	assert.Equal(t, http.StatusForbidden, resp.Code)
+}
+
+// ExplicitService registers its handler itself, so it must not also be
+// inferred from its name.
+type ExplicitService struct{}
+
+func (s *ExplicitService) RegisterGetGreeting(api huma.API) {
+	huma.Register(api, huma.Operation{
+		OperationID: "greet",
+		Method:      http.MethodGet,
+		Path:        "/greetings/{name}",
+	}, s.GetGreeting)
+}
+
+func (s *ExplicitService) GetGreeting(ctx context.Context, input *AutoGreetingInput) (*AutoGreetingOutput, error) {
+	resp := &AutoGreetingOutput{}
+	resp.Body.Message = "Hello, " + input.Name + "!"
+	return resp, nil
+}
+
+func TestAutoRegisterExplicit(t *testing.T) {
+	_, api := humatest.New(t)
+	huma.AutoRegister(api, &ExplicitService{})
+
+	paths := api.OpenAPI().Paths
+	assert.Len(t, paths, 1)
+	require.NotNil(t, paths["/greetings/{name}"])
+	assert.Equal(t, "greet", paths["/greetings/{name}"].Get.OperationID)
+
+	resp := api.Get("/greeting/alice")
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+}
@@ -0,0 +1,387 @@
+// Package batch adds an endpoint which accepts several requests at once,
+// like the batch endpoints of OData and Google APIs, so clients can save
+// round trips. Each request in the batch is handled by calling the matching
+// operation's `huma.OperationHandler` internally, so API middleware,
+// validation, and error handling all behave the same as for individual
+// requests.
+//
+// Batches are either `multipart/mixed` bodies where each part is an
+// `application/http` request, or JSON arrays of requests:
+//
+//	batch.Expose(api, batch.Config{})
+//
+//	// --> POST /batch
+//	// [{"id": "1", "method": "GET", "url": "/things/a"}]
+//	// <-- [{"id": "1", "status": 200, "headers": {...}, "body": {"name": "Thing A"}}]
+//
+// The requests are handled in order, and the response has one part or item
+// per request in the same order.
+package batch
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"strings"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// Config configures the batch endpoint.
+type Config struct {
+	// Path is the URL path of the batch endpoint. Defaults to `/batch`.
+	Path string
+
+	// MaxRequests is the maximum number of requests in a batch. Defaults to
+	// 50.
+	MaxRequests int
+
+	// Filter decides which operations can be called. If nil, all operations
+	// with an operation ID can be called.
+	Filter func(op *huma.Operation) bool
+
+	// ForwardHeaders are the headers of the batch request which are copied to
+	// each request in the batch that does not set them itself. Defaults to
+	// `Authorization` and `Cookie`.
+	ForwardHeaders []string
+}
+
+// Request is a request in a JSON batch.
+type Request struct {
+	// ID is returned with the response, to help clients match them up.
+	ID string `json:"id,omitempty"`
+
+	// Method is the HTTP method, e.g. `GET`.
+	Method string `json:"method"`
+
+	// URL is the path and query of the request, e.g. `/things?limit=5`.
+	URL string `json:"url"`
+
+	// Headers to send with the request.
+	Headers map[string]string `json:"headers,omitempty"`
+
+	// Body is the JSON request body, if any.
+	Body json.RawMessage `json:"body,omitempty"`
+}
+
+// Response is a response in a JSON batch.
+type Response struct {
+	// ID of the request, if it had one.
+	ID string `json:"id,omitempty"`
+
+	// Status is the HTTP status code of the response.
+	Status int `json:"status"`
+
+	// Headers of the response.
+	Headers map[string]string `json:"headers,omitempty"`
+
+	// Body is the response body. Non-JSON bodies are returned as strings.
+	Body json.RawMessage `json:"body,omitempty"`
+}
+
+type endpoint struct {
+	api     huma.API
+	config  Config
+	forward map[string]bool
+}
+
+// Expose adds a batch endpoint to the API at `config.Path` for `POST`
+// requests. Operations registered after calling Expose can also be called.
+func Expose(api huma.API, config Config) {
+	if config.Path == "" {
+		config.Path = "/batch"
+	}
+	if config.MaxRequests == 0 {
+		config.MaxRequests = 50
+	}
+	if config.ForwardHeaders == nil {
+		config.ForwardHeaders = []string{"Authorization", "Cookie"}
+	}
+	e := &endpoint{api: api, config: config, forward: map[string]bool{}}
+	for _, name := range config.ForwardHeaders {
+		e.forward[http.CanonicalHeaderKey(name)] = true
+	}
+	api.Adapter().Handle(&huma.Operation{Method: http.MethodPost, Path: config.Path}, e.handle)
+}
+
+func (e *endpoint) handle(ctx huma.Context) {
+	mediaType, params, _ := mime.ParseMediaType(ctx.Header("Content-Type"))
+	switch {
+	case mediaType == "multipart/mixed":
+		e.handleMultipart(ctx, params["boundary"])
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		e.handleJSON(ctx)
+	default:
+		huma.WriteErr(e.api, ctx, http.StatusUnsupportedMediaType, "batch must be multipart/mixed or a JSON array")
+	}
+}
+
+func (e *endpoint) tooMany(ctx huma.Context, count int) bool {
+	if count > e.config.MaxRequests {
+		huma.WriteErr(e.api, ctx, http.StatusRequestEntityTooLarge, fmt.Sprintf("batch has %d requests, but at most %d are allowed", count, e.config.MaxRequests))
+		return true
+	}
+	return false
+}
+
+// tooLarge writes an error if reading the batch failed because it is over the
+// API's maximum body size.
+func (e *endpoint) tooLarge(ctx huma.Context, err error) bool {
+	var mbe *http.MaxBytesError
+	if errors.As(err, &mbe) {
+		huma.WriteErr(e.api, ctx, http.StatusRequestEntityTooLarge, fmt.Sprintf("request body is too large limit=%d bytes", mbe.Limit))
+		return true
+	}
+	return false
+}
+
+func (e *endpoint) handleJSON(ctx huma.Context) {
+	var requests []Request
+	if err := json.NewDecoder(huma.LimitBody(e.api, ctx)).Decode(&requests); err != nil {
+		if e.tooLarge(ctx, err) {
+			return
+		}
+		huma.WriteErr(e.api, ctx, http.StatusBadRequest, "batch must be a JSON array of requests", err)
+		return
+	}
+	if e.tooMany(ctx, len(requests)) {
+		return
+	}
+
+	responses := make([]Response, len(requests))
+	for i, item := range requests {
+		var body io.Reader
+		if len(item.Body) > 0 {
+			body = bytes.NewReader(item.Body)
+		}
+		r, err := http.NewRequestWithContext(ctx.Context(), item.Method, item.URL, body)
+		if err != nil {
+			responses[i] = jsonResponse(item.ID, errorResponse(http.StatusBadRequest, "invalid request: "+err.Error()))
+			continue
+		}
+		for name, value := range item.Headers {
+			r.Header.Set(name, value)
+		}
+		if body != nil && r.Header.Get("Content-Type") == "" {
+			r.Header.Set("Content-Type", "application/json")
+		}
+		if r.Header.Get("Accept") == "" {
+			r.Header.Set("Accept", "application/json")
+		}
+		responses[i] = jsonResponse(item.ID, e.call(ctx, r))
+	}
+
+	ctx.SetHeader("Content-Type", "application/json")
+	ctx.SetStatus(http.StatusOK)
+	json.NewEncoder(ctx.BodyWriter()).Encode(responses)
+}
+
+// jsonResponse converts a recorded response to a JSON batch response.
+func jsonResponse(id string, rec *httptest.ResponseRecorder) Response {
+	resp := Response{ID: id, Status: rec.Code, Headers: map[string]string{}}
+	for name, values := range rec.Header() {
+		resp.Headers[name] = strings.Join(values, ", ")
+	}
+	if rec.Body.Len() > 0 {
+		if json.Valid(rec.Body.Bytes()) {
+			resp.Body = rec.Body.Bytes()
+		} else {
+			resp.Body, _ = json.Marshal(rec.Body.String())
+		}
+	}
+	return resp
+}
+
+// part is a request read from a multipart batch.
+type part struct {
+	contentID string
+	req       *http.Request
+	err       error
+}
+
+func (e *endpoint) handleMultipart(ctx huma.Context, boundary string) {
+	if boundary == "" {
+		huma.WriteErr(e.api, ctx, http.StatusBadRequest, "multipart batch is missing a boundary")
+		return
+	}
+
+	// Read all parts first, so that no requests are made for invalid or
+	// oversized batches.
+	parts := []part{}
+	reader := multipart.NewReader(huma.LimitBody(e.api, ctx), boundary)
+	for {
+		p, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			if !e.tooLarge(ctx, err) {
+				huma.WriteErr(e.api, ctx, http.StatusBadRequest, "invalid multipart batch", err)
+			}
+			return
+		}
+		if e.tooMany(ctx, len(parts)+1) {
+			return
+		}
+		item := part{contentID: p.Header.Get("Content-ID")}
+		if ct, _, _ := mime.ParseMediaType(p.Header.Get("Content-Type")); ct != "application/http" {
+			item.err = fmt.Errorf("part content type must be application/http")
+		} else {
+			item.req, item.err = readRequest(ctx, p)
+			if e.tooLarge(ctx, item.err) {
+				return
+			}
+		}
+		parts = append(parts, item)
+	}
+
+	out := &bytes.Buffer{}
+	writer := multipart.NewWriter(out)
+	for _, item := range parts {
+		var rec *httptest.ResponseRecorder
+		if item.err != nil {
+			rec = errorResponse(http.StatusBadRequest, "invalid request: "+item.err.Error())
+		} else {
+			rec = e.call(ctx, item.req)
+		}
+
+		header := textproto.MIMEHeader{}
+		header.Set("Content-Type", "application/http")
+		if item.contentID != "" {
+			header.Set("Content-ID", responseContentID(item.contentID))
+		}
+		w, _ := writer.CreatePart(header)
+		rec.Result().Write(w)
+	}
+	writer.Close()
+
+	ctx.SetHeader("Content-Type", "multipart/mixed; boundary="+writer.Boundary())
+	ctx.SetStatus(http.StatusOK)
+	ctx.BodyWriter().Write(out.Bytes())
+}
+
+// readRequest reads an HTTP request from a multipart batch part, buffering
+// its body so the next part can be read. Parts often omit the
+// `Content-Length`, in which case the rest of the part is the body.
+func readRequest(ctx huma.Context, p *multipart.Part) (*http.Request, error) {
+	br := bufio.NewReader(p)
+	r, err := http.ReadRequest(br)
+	if err != nil {
+		return nil, err
+	}
+	var body []byte
+	if r.ContentLength == 0 && len(r.TransferEncoding) == 0 {
+		body, err = io.ReadAll(br)
+	} else {
+		body, err = io.ReadAll(r.Body)
+	}
+	if err != nil {
+		return nil, err
+	}
+	r.ContentLength = int64(len(body))
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	r.RequestURI = ""
+	return r.WithContext(ctx.Context()), nil
+}
+
+// responseContentID returns the `Content-ID` of the response to a part,
+// following the convention of prefixing the request's ID with `response-`.
+func responseContentID(id string) string {
+	if strings.HasPrefix(id, "<") && strings.HasSuffix(id, ">") {
+		return "<response-" + id[1:]
+	}
+	return "response-" + id
+}
+
+// errorResponse records a problem details error response for a request in the
+// batch which couldn't be handled by an operation.
+func errorResponse(status int, msg string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	rec.Header().Set("Content-Type", "application/problem+json")
+	rec.WriteHeader(status)
+	json.NewEncoder(rec).Encode(huma.NewError(status, msg))
+	return rec
+}
+
+// call handles a single request from the batch, forwarding the configured
+// headers of the batch request not set on it, e.g. for authentication.
+func (e *endpoint) call(ctx huma.Context, r *http.Request) *httptest.ResponseRecorder {
+	op := e.findOperation(r.Method, r.URL.Path)
+	if op == nil {
+		return errorResponse(http.StatusNotFound, fmt.Sprintf("no operation found for %s %s", r.Method, r.URL.Path))
+	}
+
+	set := map[string]bool{}
+	for name := range r.Header {
+		set[name] = true
+	}
+	ctx.EachHeader(func(name, value string) {
+		name = http.CanonicalHeaderKey(name)
+		if e.forward[name] && !set[name] {
+			r.Header.Add(name, value)
+		}
+	})
+	r.Host = ctx.Host()
+	r.RemoteAddr = huma.RemoteAddr(ctx)
+
+	rec := httptest.NewRecorder()
+	huma.OperationHandler(e.api, op.OperationID).ServeHTTP(rec, r)
+	return rec
+}
+
+// findOperation returns the callable operation for the method and path. When
+// several operations match, the one with the most literal path segments wins,
+// e.g. `/things/new` over `/things/{id}`.
+func (e *endpoint) findOperation(method, path string) *huma.Operation {
+	var found *huma.Operation
+	best := -1
+	for _, item := range e.api.OpenAPI().Paths {
+		for _, op := range []*huma.Operation{item.Get, item.Put, item.Post, item.Delete, item.Options, item.Head, item.Patch, item.Trace} {
+			if op == nil || op.Method != method || op.OperationID == "" || (e.config.Filter != nil && !e.config.Filter(op)) {
+				continue
+			}
+			// The operation's own path is used as the OpenAPI has no syntax
+			// for catch-all parameters.
+			if literals, ok := matchPath(op.Path, path); ok && literals > best {
+				found, best = op, literals
+			}
+		}
+	}
+	return found
+}
+
+// matchPath returns whether the path matches the path template, and how many
+// of its segments matched literally. Parameters must be whole path segments,
+// and a trailing `{name...}` parameter matches the rest of the path.
+func matchPath(template, path string) (int, bool) {
+	tsegs := strings.Split(strings.Trim(template, "/"), "/")
+	psegs := strings.Split(strings.Trim(path, "/"), "/")
+	literals := 0
+	for i, s := range tsegs {
+		if i >= len(psegs) {
+			return 0, false
+		}
+		if strings.HasPrefix(s, "{") && strings.HasSuffix(s, "}") {
+			if strings.HasSuffix(s, "...}") && i == len(tsegs)-1 {
+				return literals, psegs[i] != ""
+			}
+			if psegs[i] == "" {
+				return 0, false
+			}
+			continue
+		}
+		if s != psegs[i] {
+			return 0, false
+		}
+		literals++
+	}
+	return literals, len(tsegs) == len(psegs)
+}
@@ -0,0 +1,251 @@
+package batch
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/humatest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type ThingOutput struct {
+	Body struct {
+		ID   string `json:"id"`
+		Auth string `json:"auth,omitempty"`
+	}
+}
+
+func setup(t *testing.T, config Config) humatest.TestAPI {
+	_, api := humatest.New(t)
+
+	var order []string
+
+	huma.Register(api, huma.Operation{
+		OperationID: "get-thing",
+		Method:      http.MethodGet,
+		Path:        "/things/{thing-id}",
+	}, func(ctx context.Context, input *struct {
+		ThingID string `path:"thing-id"`
+		Auth    string `header:"Authorization"`
+	}) (*ThingOutput, error) {
+		order = append(order, input.ThingID)
+		if input.ThingID == "missing" {
+			return nil, huma.Error404NotFound("thing not found")
+		}
+		resp := &ThingOutput{}
+		resp.Body.ID = input.ThingID
+		resp.Body.Auth = input.Auth
+		return resp, nil
+	})
+
+	huma.Register(api, huma.Operation{
+		OperationID: "get-new-thing",
+		Method:      http.MethodGet,
+		Path:        "/things/new",
+	}, func(ctx context.Context, input *struct{}) (*ThingOutput, error) {
+		resp := &ThingOutput{}
+		resp.Body.ID = "new"
+		return resp, nil
+	})
+
+	huma.Register(api, huma.Operation{
+		OperationID: "create-thing",
+		Method:      http.MethodPost,
+		Path:        "/things",
+	}, func(ctx context.Context, input *struct {
+		Body struct {
+			ID string `json:"id" minLength:"2"`
+		}
+	}) (*ThingOutput, error) {
+		order = append(order, input.Body.ID)
+		resp := &ThingOutput{}
+		resp.Body.ID = input.Body.ID
+		return resp, nil
+	})
+
+	huma.Register(api, huma.Operation{
+		OperationID: "get-file",
+		Method:      http.MethodGet,
+		Path:        "/files/{path...}",
+	}, func(ctx context.Context, input *struct {
+		Path string `path:"path"`
+	}) (*ThingOutput, error) {
+		resp := &ThingOutput{}
+		resp.Body.ID = input.Path
+		return resp, nil
+	})
+
+	Expose(api, config)
+	return api
+}
+
+func TestJSONBatch(t *testing.T) {
+	api := setup(t, Config{})
+
+	resp := api.Post("/batch", "Authorization: Bearer abc", []any{
+		map[string]any{"id": "1", "method": "POST", "url": "/things", "body": map[string]any{"id": "t1"}},
+		map[string]any{"id": "2", "method": "GET", "url": "/things/t1"},
+		map[string]any{"id": "3", "method": "GET", "url": "/things/new"},
+		map[string]any{"id": "4", "method": "GET", "url": "/files/a/b.txt"},
+		map[string]any{"id": "5", "method": "GET", "url": "/things/missing"},
+		map[string]any{"id": "6", "method": "POST", "url": "/things", "body": map[string]any{"id": "x"}},
+		map[string]any{"id": "7", "method": "DELETE", "url": "/things/t1"},
+		map[string]any{"id": "8", "method": "GET", "url": "/things/t2", "headers": map[string]string{"Authorization": "Bearer def"}},
+	})
+	require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+
+	var responses []Response
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &responses))
+	require.Len(t, responses, 8)
+
+	statuses := []int{}
+	for i, r := range responses {
+		assert.Equal(t, string(rune('1'+i)), r.ID)
+		statuses = append(statuses, r.Status)
+	}
+	assert.Equal(t, []int{200, 200, 200, 200, 404, 422, 404, 200}, statuses)
+
+	// Headers of the batch request are forwarded unless overridden.
+	assert.JSONEq(t, `{"id": "t1", "auth": "Bearer abc"}`, string(responses[1].Body))
+	assert.JSONEq(t, `{"id": "new"}`, string(responses[2].Body))
+	assert.JSONEq(t, `{"id": "a/b.txt"}`, string(responses[3].Body))
+	assert.Contains(t, string(responses[4].Body), "thing not found")
+	assert.Contains(t, responses[5].Headers["Content-Type"], "problem+json")
+	assert.Contains(t, string(responses[6].Body), "no operation found for DELETE /things/t1")
+	assert.JSONEq(t, `{"id": "t2", "auth": "Bearer def"}`, string(responses[7].Body))
+}
+
+func TestMultipartBatch(t *testing.T) {
+	api := setup(t, Config{})
+
+	body := strings.Join([]string{
+		"--b",
+		"Content-Type: application/http",
+		"Content-ID: <item1@example.com>",
+		"",
+		"POST /things HTTP/1.1",
+		"Content-Type: application/json",
+		"",
+		`{"id": "t1"}`,
+		"--b",
+		"Content-Type: application/http",
+		"Content-ID: 2",
+		"",
+		"GET /things/t1 HTTP/1.1",
+		"Accept: application/json",
+		"",
+		"",
+		"--b",
+		"Content-Type: text/plain",
+		"",
+		"hello",
+		"--b--",
+		"",
+	}, "\r\n")
+
+	resp := api.Post("/batch", "Content-Type: multipart/mixed; boundary=b", strings.NewReader(body))
+	require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+
+	mediaType, params, err := mime.ParseMediaType(resp.Header().Get("Content-Type"))
+	require.NoError(t, err)
+	assert.Equal(t, "multipart/mixed", mediaType)
+
+	reader := multipart.NewReader(resp.Body, params["boundary"])
+	ids := []string{}
+	statuses := []int{}
+	bodies := []string{}
+	for {
+		p, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		assert.Equal(t, "application/http", p.Header.Get("Content-Type"))
+		ids = append(ids, p.Header.Get("Content-ID"))
+
+		r, err := http.ReadResponse(bufio.NewReader(p), nil)
+		require.NoError(t, err)
+		b, _ := io.ReadAll(r.Body)
+		statuses = append(statuses, r.StatusCode)
+		bodies = append(bodies, string(b))
+	}
+
+	assert.Equal(t, []string{"<response-item1@example.com>", "response-2", ""}, ids)
+	assert.Equal(t, []int{200, 200, 400}, statuses)
+	assert.JSONEq(t, `{"id": "t1"}`, bodies[1])
+	assert.Contains(t, bodies[2], "application/http")
+}
+
+func TestBatchErrors(t *testing.T) {
+	api := setup(t, Config{Path: "/api/batch", MaxRequests: 1, Filter: func(op *huma.Operation) bool {
+		return op.OperationID != "create-thing"
+	}})
+
+	resp := api.Post("/api/batch", "Content-Type: text/plain", strings.NewReader("hello"))
+	assert.Equal(t, http.StatusUnsupportedMediaType, resp.Code)
+
+	resp = api.Post("/api/batch", map[string]any{"not": "an array"})
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+
+	resp = api.Post("/api/batch", "Content-Type: multipart/mixed", strings.NewReader(""))
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+
+	resp = api.Post("/api/batch", []any{
+		map[string]any{"method": "GET", "url": "/things/a"},
+		map[string]any{"method": "GET", "url": "/things/b"},
+	})
+	assert.Equal(t, http.StatusRequestEntityTooLarge, resp.Code)
+
+	// Filtered operations can't be called.
+	resp = api.Post("/api/batch", []any{
+		map[string]any{"method": "POST", "url": "/things", "body": map[string]any{"id": "t1"}},
+	})
+	require.Equal(t, http.StatusOK, resp.Code)
+	assert.Contains(t, resp.Body.String(), `"status":404`)
+}
+
+func TestBatchForwardHeaders(t *testing.T) {
+	api := setup(t, Config{ForwardHeaders: []string{}})
+
+	resp := api.Post("/batch", "Authorization: Bearer abc", []any{
+		map[string]any{"method": "GET", "url": "/things/t1"},
+	})
+	require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+
+	var responses []Response
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &responses))
+	require.Len(t, responses, 1)
+	assert.JSONEq(t, `{"id": "t1"}`, string(responses[0].Body))
+}
+
+func TestBatchTooLarge(t *testing.T) {
+	config := huma.DefaultConfig("Test API", "1.0.0")
+	config.MaxBodyBytes = 64
+	_, api := humatest.New(t, config)
+	Expose(api, Config{})
+
+	resp := api.Post("/batch", []any{
+		map[string]any{"method": "GET", "url": "/things/" + strings.Repeat("a", 100)},
+	})
+	assert.Equal(t, http.StatusRequestEntityTooLarge, resp.Code, resp.Body.String())
+
+	body := strings.Join([]string{
+		"--b",
+		"Content-Type: application/http",
+		"",
+		"GET /things/" + strings.Repeat("a", 100) + " HTTP/1.1",
+		"",
+		"--b--",
+	}, "\r\n")
+	resp = api.Post("/batch", "Content-Type: multipart/mixed; boundary=b", strings.NewReader(body))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, resp.Code, resp.Body.String())
+}
@@ -0,0 +1,169 @@
+package huma
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CachePolicy describes how successful responses of an operation may be
+// cached, and is sent as the `Cache-Control`, `Expires`, and `Vary` response
+// headers. Set it via `Operation.Cache`, e.g. using one of the helpers:
+//
+//	huma.Register(api, huma.Operation{
+//		OperationID: "get-item",
+//		Method:      http.MethodGet,
+//		Path:        "/items/{id}",
+//		Cache:       huma.CacheMaxAge(5 * time.Minute),
+//	}, handler)
+//
+// Headers set by the handler's output take precedence over the policy.
+type CachePolicy struct {
+	// NoStore prevents any cache from storing the response, e.g. for
+	// sensitive data. Other directives are ignored.
+	NoStore bool
+
+	// NoCache allows caches to store the response, but they must revalidate
+	// it with the server before each use.
+	NoCache bool
+
+	// Public allows shared caches like CDNs to store the response even if
+	// the request was authenticated.
+	Public bool
+
+	// Private prevents shared caches like CDNs from storing the response, so
+	// it is only cached by the client.
+	Private bool
+
+	// MaxAge is how long the response is fresh for.
+	MaxAge time.Duration
+
+	// SharedMaxAge overrides `MaxAge` for shared caches like CDNs.
+	SharedMaxAge time.Duration
+
+	// StaleWhileRevalidate allows caches to use a stale response for this
+	// long while they revalidate it in the background.
+	StaleWhileRevalidate time.Duration
+
+	// StaleIfError allows caches to use a stale response for this long if
+	// revalidating it fails with an error.
+	StaleIfError time.Duration
+
+	// MustRevalidate prevents caches from using a stale response, even if
+	// the server cannot be reached.
+	MustRevalidate bool
+
+	// Immutable tells clients the response will never change while it is
+	// fresh, e.g. for versioned assets.
+	Immutable bool
+
+	// Expires also sends an `Expires` header based on `MaxAge`, for caches
+	// which do not understand `Cache-Control`.
+	Expires bool
+
+	// Vary lists request headers which select between different responses,
+	// e.g. `Accept-Language`, so caches store each variant separately.
+	Vary []string
+}
+
+// CacheNoStore returns a policy which prevents any cache from storing the
+// response.
+func CacheNoStore() *CachePolicy {
+	return &CachePolicy{NoStore: true}
+}
+
+// CacheMaxAge returns a policy which allows the response to be cached for
+// the given duration.
+func CacheMaxAge(maxAge time.Duration) *CachePolicy {
+	return &CachePolicy{MaxAge: maxAge}
+}
+
+// CacheStaleWhileRevalidate returns a policy which allows the response to be
+// cached for `maxAge`, after which a stale response may be used for up to
+// `stale` while it is revalidated in the background.
+func CacheStaleWhileRevalidate(maxAge, stale time.Duration) *CachePolicy {
+	return &CachePolicy{MaxAge: maxAge, StaleWhileRevalidate: stale}
+}
+
+// seconds formats a duration in whole seconds, rounded down.
+func seconds(d time.Duration) string {
+	return strconv.FormatInt(int64(d/time.Second), 10)
+}
+
+// CacheControl returns the `Cache-Control` header value for the policy.
+func (p *CachePolicy) CacheControl() string {
+	if p.NoStore {
+		return "no-store"
+	}
+
+	directives := []string{}
+	if p.Public {
+		directives = append(directives, "public")
+	}
+	if p.Private {
+		directives = append(directives, "private")
+	}
+	if p.NoCache {
+		directives = append(directives, "no-cache")
+	}
+	if p.MaxAge > 0 {
+		directives = append(directives, "max-age="+seconds(p.MaxAge))
+	}
+	if p.SharedMaxAge > 0 {
+		directives = append(directives, "s-maxage="+seconds(p.SharedMaxAge))
+	}
+	if p.StaleWhileRevalidate > 0 {
+		directives = append(directives, "stale-while-revalidate="+seconds(p.StaleWhileRevalidate))
+	}
+	if p.StaleIfError > 0 {
+		directives = append(directives, "stale-if-error="+seconds(p.StaleIfError))
+	}
+	if p.MustRevalidate {
+		directives = append(directives, "must-revalidate")
+	}
+	if p.Immutable {
+		directives = append(directives, "immutable")
+	}
+	return strings.Join(directives, ", ")
+}
+
+// writeHeaders sets the policy's response headers.
+func (p *CachePolicy) writeHeaders(ctx Context) {
+	if cc := p.CacheControl(); cc != "" {
+		ctx.SetHeader("Cache-Control", cc)
+	}
+	if p.Expires && !p.NoStore {
+		ctx.SetHeader("Expires", time.Now().Add(p.MaxAge).UTC().Format(http.TimeFormat))
+	}
+	if len(p.Vary) > 0 {
+		// Append rather than set, to keep values from middleware like CORS.
+		ctx.AppendHeader("Vary", strings.Join(p.Vary, ", "))
+	}
+}
+
+// document adds the policy's headers to a response in the OpenAPI, unless
+// they are already documented.
+func (p *CachePolicy) document(resp *Response) {
+	if resp.Headers == nil {
+		resp.Headers = map[string]*Param{}
+	}
+	add := func(name, description string, example any) {
+		if resp.Headers[name] == nil {
+			resp.Headers[name] = &Header{
+				Description: description,
+				Schema:      &Schema{Type: TypeString},
+				Example:     example,
+			}
+		}
+	}
+	if cc := p.CacheControl(); cc != "" {
+		add("Cache-Control", "How the response may be cached.", cc)
+	}
+	if p.Expires && !p.NoStore {
+		add("Expires", "When the response becomes stale.", nil)
+	}
+	if len(p.Vary) > 0 {
+		add("Vary", "Request headers which select between response variants.", strings.Join(p.Vary, ", "))
+	}
+}
@@ -0,0 +1,92 @@
+package huma_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/humatest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCachePolicy(t *testing.T) {
+	for _, tc := range []struct {
+		policy   *huma.CachePolicy
+		expected string
+	}{
+		{huma.CacheNoStore(), "no-store"},
+		{&huma.CachePolicy{NoStore: true, MaxAge: time.Minute}, "no-store"},
+		{huma.CacheMaxAge(90 * time.Second), "max-age=90"},
+		{huma.CacheStaleWhileRevalidate(time.Minute, time.Hour), "max-age=60, stale-while-revalidate=3600"},
+		{&huma.CachePolicy{Private: true, NoCache: true}, "private, no-cache"},
+		{&huma.CachePolicy{
+			Public:         true,
+			MaxAge:         time.Minute,
+			SharedMaxAge:   time.Hour,
+			StaleIfError:   time.Hour,
+			MustRevalidate: true,
+			Immutable:      true,
+		}, "public, max-age=60, s-maxage=3600, stale-if-error=3600, must-revalidate, immutable"},
+	} {
+		assert.Equal(t, tc.expected, tc.policy.CacheControl())
+	}
+}
+
+func TestOperationCache(t *testing.T) {
+	_, api := humatest.New(t)
+
+	type Output struct {
+		CacheControl string `header:"Cache-Control"`
+		Body         struct {
+			Name string `json:"name"`
+		}
+	}
+
+	huma.Register(api, huma.Operation{
+		OperationID: "get-item",
+		Method:      http.MethodGet,
+		Path:        "/items/{id}",
+		Cache: &huma.CachePolicy{
+			MaxAge:  time.Minute,
+			Expires: true,
+			Vary:    []string{"Accept-Language"},
+		},
+	}, func(ctx context.Context, input *struct {
+		ID string `path:"id"`
+	}) (*Output, error) {
+		if input.ID == "missing" {
+			return nil, huma.Error404NotFound("not found")
+		}
+		resp := &Output{}
+		if input.ID == "secret" {
+			resp.CacheControl = "no-store"
+		}
+		resp.Body.Name = input.ID
+		return resp, nil
+	})
+
+	resp := api.Get("/items/abc")
+	require.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, "max-age=60", resp.Header().Get("Cache-Control"))
+	assert.Equal(t, "Accept-Language", resp.Header().Get("Vary"))
+	expires, err := http.ParseTime(resp.Header().Get("Expires"))
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(time.Minute), expires, 5*time.Second)
+
+	// Output headers take precedence over the policy.
+	resp = api.Get("/items/secret")
+	assert.Equal(t, "no-store", resp.Header().Get("Cache-Control"))
+
+	// Errors are not cached.
+	resp = api.Get("/items/missing")
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+	assert.Empty(t, resp.Header().Get("Cache-Control"))
+
+	headers := api.OpenAPI().Paths["/items/{id}"].Get.Responses["200"].Headers
+	require.NotNil(t, headers["Cache-Control"])
+	assert.NotNil(t, headers["Expires"])
+	assert.Equal(t, "Accept-Language", headers["Vary"].Example)
+}
@@ -2,13 +2,16 @@ package huma
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/http"
 	"os"
 	"os/signal"
 	"reflect"
 	"strconv"
 	"strings"
 	"syscall"
+	"time"
 
 	"github.com/danielgtaylor/casing"
 	"github.com/spf13/cobra"
@@ -270,3 +273,54 @@ func NewCLI[O any](onParsed func(Hooks, *O)) CLI {
 	}
 	return c
 }
+
+// DocsCommand returns a command which serves only the API's OpenAPI document,
+// documentation, and schemas, without any of its operations. This is useful
+// for publishing API documentation, e.g. from CI. The API is created by the
+// CLI's `onParsed` callback, so `getAPI` is called once the command runs.
+// Avoid connecting to external services in the callback, as it still runs,
+// and do so in `OnStart` instead.
+//
+//	var api huma.API
+//	cli := huma.NewCLI(func(hooks huma.Hooks, opts *Options) {
+//		api = humachi.New(chi.NewMux(), huma.DefaultConfig("My API", "1.0.0"))
+//		// ...
+//	})
+//	cli.Root().AddCommand(huma.DocsCommand(func() huma.API { return api }))
+//
+// Then run e.g. `myapp docs --docs-addr :8080`.
+func DocsCommand(getAPI func() API) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "docs",
+		Short: "Serve only the API documentation",
+		Run: func(cmd *cobra.Command, args []string) {
+			addr, _ := cmd.Flags().GetString("docs-addr")
+			srv := &http.Server{
+				Addr:              addr,
+				Handler:           DocsOnly(getAPI()),
+				ReadHeaderTimeout: 10 * time.Second,
+			}
+
+			done := make(chan error, 1)
+			go func() {
+				done <- srv.ListenAndServe()
+			}()
+			cmd.Printf("Serving API documentation on %s\n", addr)
+
+			quit := make(chan os.Signal, 1)
+			signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+			defer signal.Stop(quit)
+
+			select {
+			case err := <-done:
+				if !errors.Is(err, http.ErrServerClosed) {
+					cmd.PrintErrln(err)
+				}
+			case <-quit:
+				srv.Shutdown(context.Background())
+			}
+		},
+	}
+	cmd.Flags().String("docs-addr", ":8888", "Address to serve the documentation on")
+	return cmd
+}
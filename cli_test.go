@@ -220,6 +220,30 @@ func TestCLIShutdown(t *testing.T) {
 	assert.True(t, started)
 }
 
+func TestCLIDocsCommand(t *testing.T) {
+	type Options struct{}
+
+	var api huma.API
+	cli := huma.NewCLI(func(hooks huma.Hooks, options *Options) {
+		api = humachi.New(chi.NewMux(), huma.DefaultConfig("My API", "1.0.0"))
+		hooks.OnStart(func() {
+			t.Fatal("server should not start")
+		})
+	})
+	cli.Root().AddCommand(huma.DocsCommand(func() huma.API { return api }))
+
+	// An invalid address makes the server exit immediately.
+	buf := bytes.NewBuffer(nil)
+	cli.Root().SetOut(buf)
+	cli.Root().SetErr(buf)
+	cli.Root().SetArgs([]string{"docs", "--docs-addr", "invalid"})
+	cli.Run()
+
+	assert.NotNil(t, api)
+	assert.Contains(t, buf.String(), "Serving API documentation on invalid")
+	assert.Contains(t, buf.String(), "missing port")
+}
+
 func TestCLIBadType(t *testing.T) {
 	type Options struct {
 		Debug []struct{}
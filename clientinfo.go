@@ -0,0 +1,246 @@
+package huma
+
+import (
+	"context"
+	"net"
+	"net/netip"
+	"strings"
+	"sync"
+)
+
+type clientInfoKey struct{}
+
+// ClientInfo describes the client which made a request. When the request
+// came through one of the API's `Config.TrustedProxies`, it is taken from the
+// `Forwarded` header (or `X-Forwarded-*` headers if absent) set by the
+// proxies, otherwise from the connection itself.
+type ClientInfo struct {
+	// IP is the client's address. It is invalid if unknown, e.g. for
+	// requests over a unix socket.
+	IP netip.Addr
+
+	// ID is the `unknown` or obfuscated identifier such as `_hidden` used by
+	// a trusted proxy in the `Forwarded` header instead of the client's
+	// address, in which case `IP` is invalid.
+	ID string
+
+	// Scheme is the scheme used by the client, e.g. `https`, or empty if
+	// unknown. It is only known when set by a trusted proxy or the router.
+	Scheme string
+
+	// Host is the host requested by the client.
+	Host string
+
+	// Proxied is whether the request came through a trusted proxy, and the
+	// information above was taken from its headers.
+	Proxied bool
+
+	// Forwarded is the parsed `Forwarded` header when the request came from a
+	// trusted proxy, with the nearest proxy's element last. Elements before
+	// the client's may have been forged by the client.
+	Forwarded []Forwarded
+}
+
+// ClientInfoFromContext returns information about the client which made the
+// current request, taking trusted proxies into account. It is computed once
+// per request on first use. Middleware can call it with `ctx.Context()`.
+//
+//	func handler(ctx context.Context, input *MyInput) (*MyOutput, error) {
+//		client := huma.ClientInfoFromContext(ctx)
+//		log.Printf("request from %s", client.IP)
+//		// ...
+//	}
+func ClientInfoFromContext(ctx context.Context) ClientInfo {
+	if r, ok := ctx.Value(clientInfoKey{}).(*clientInfoResolver); ok {
+		return r.get()
+	}
+	return ClientInfo{}
+}
+
+// parseTrustedProxies parses addresses and CIDR ranges, panicking if any are
+// invalid so that misconfiguration is caught on startup.
+func parseTrustedProxies(proxies []string) []netip.Prefix {
+	prefixes := make([]netip.Prefix, 0, len(proxies))
+	for _, p := range proxies {
+		if strings.Contains(p, "/") {
+			prefix, err := netip.ParsePrefix(p)
+			if err != nil {
+				panic("invalid trusted proxy " + p + ": " + err.Error())
+			}
+			prefixes = append(prefixes, prefix.Masked())
+			continue
+		}
+		addr, err := netip.ParseAddr(p)
+		if err != nil {
+			panic("invalid trusted proxy " + p + ": " + err.Error())
+		}
+		prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+	}
+	return prefixes
+}
+
+// parseIP parses an address which may include a port or brackets.
+func parseIP(s string) netip.Addr {
+	s = strings.TrimSpace(s)
+	if host, _, err := net.SplitHostPort(s); err == nil {
+		s = host
+	}
+	s = strings.TrimSuffix(strings.TrimPrefix(s, "["), "]")
+	addr, err := netip.ParseAddr(s)
+	if err != nil {
+		return netip.Addr{}
+	}
+	return addr.Unmap()
+}
+
+// clientInfoResolver lazily resolves the client info for a request.
+type clientInfoResolver struct {
+	ctx     Context
+	trusted []netip.Prefix
+	once    sync.Once
+	info    ClientInfo
+}
+
+func (r *clientInfoResolver) isTrusted(addr netip.Addr) bool {
+	if !addr.IsValid() {
+		return false
+	}
+	for _, p := range r.trusted {
+		if p.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *clientInfoResolver) get() ClientInfo {
+	r.once.Do(func() {
+		r.info = r.resolve()
+	})
+	return r.info
+}
+
+func (r *clientInfoResolver) resolve() ClientInfo {
+	ctx := r.ctx
+	u := ctx.URL()
+	info := ClientInfo{
+		IP:     parseIP(RemoteAddr(ctx)),
+		Scheme: u.Scheme,
+		Host:   ctx.Host(),
+	}
+	if !r.isTrusted(info.IP) {
+		return info
+	}
+
+	if forwarded := joinedHeader(ctx, "Forwarded"); forwarded != "" {
+		// The standard header supersedes the `X-Forwarded-*` headers. If a
+		// trusted proxy sent an invalid one, fall back to the connection.
+		if elements, err := ParseForwarded(forwarded); err == nil {
+			r.resolveForwarded(&info, elements)
+		}
+		return info
+	}
+
+	if xff := joinedHeader(ctx, "X-Forwarded-For"); xff != "" {
+		// Each proxy appends the address it received the request from, so walk
+		// backwards until reaching an address which is not a trusted proxy.
+		// Anything before it could have been forged by the client, including
+		// whole header lines since proxies may add their own line.
+		hops := strings.Split(xff, ",")
+		for i := len(hops) - 1; i >= 0; i-- {
+			ip := parseIP(hops[i])
+			if !ip.IsValid() {
+				break
+			}
+			info.IP = ip
+			info.Proxied = true
+			if !r.isTrusted(ip) {
+				break
+			}
+		}
+	}
+	if proto := firstValue(ctx.Header("X-Forwarded-Proto")); proto != "" {
+		info.Scheme = strings.ToLower(proto)
+		info.Proxied = true
+	}
+	if host := firstValue(ctx.Header("X-Forwarded-Host")); host != "" {
+		info.Host = host
+		info.Proxied = true
+	}
+	return info
+}
+
+// resolveForwarded walks the `Forwarded` elements backwards like
+// `X-Forwarded-For`, stopping at the first node which is not a trusted
+// proxy. The scheme and host come from the element recorded by the
+// client-facing proxy, since later proxies only saw the internal request.
+func (r *clientInfoResolver) resolveForwarded(info *ClientInfo, elements []Forwarded) {
+	info.Forwarded = elements
+	for i := len(elements) - 1; i >= 0; i-- {
+		f := elements[i]
+		if !f.For.IP.IsValid() && f.For.Name == "" {
+			// The proxy did not record the `for` node, so the client is unknown.
+			break
+		}
+		info.IP = f.For.IP
+		info.ID = f.For.Name
+		info.Proxied = true
+		if f.Proto != "" {
+			info.Scheme = f.Proto
+		}
+		if f.Host != "" {
+			info.Host = f.Host
+		}
+		if !r.isTrusted(f.For.IP) {
+			break
+		}
+	}
+}
+
+// joinedHeader returns all values of a comma-separated list header, joining
+// multiple header lines like RFC 9110 allows.
+func joinedHeader(ctx Context, name string) string {
+	joined := ""
+	ctx.EachHeader(func(n, value string) {
+		if strings.EqualFold(n, name) {
+			if joined != "" {
+				joined += ","
+			}
+			joined += value
+		}
+	})
+	return joined
+}
+
+// firstValue returns the first of a comma-separated list of header values.
+func firstValue(v string) string {
+	first, _, _ := strings.Cut(v, ",")
+	return strings.TrimSpace(first)
+}
+
+// clientInfoContext overrides the request context to include the client info.
+type clientInfoContext struct {
+	humaContext
+	ctx context.Context
+}
+
+func (c *clientInfoContext) Context() context.Context {
+	return c.ctx
+}
+
+func (c *clientInfoContext) Unwrap() Context {
+	return c.humaContext
+}
+
+// clientInfo wraps a handler to make the client info available via
+// `ClientInfoFromContext`.
+func clientInfo(api API, handler func(ctx Context)) func(ctx Context) {
+	trusted := parseTrustedProxies(configOf(api).TrustedProxies)
+	return func(ctx Context) {
+		r := &clientInfoResolver{ctx: ctx, trusted: trusted}
+		handler(&clientInfoContext{
+			humaContext: ctx,
+			ctx:         context.WithValue(ctx.Context(), clientInfoKey{}, r),
+		})
+	}
+}
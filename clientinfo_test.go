@@ -0,0 +1,230 @@
+package huma_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/humatest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClientInfo(t *testing.T) {
+	config := huma.DefaultConfig("Test API", "1.0.0")
+	config.TrustedProxies = []string{"10.0.0.0/8", "fd00::1"}
+	_, api := humatest.New(t, config)
+
+	var info huma.ClientInfo
+	huma.Register(api, huma.Operation{
+		OperationID: "info",
+		Method:      http.MethodGet,
+		Path:        "/info",
+	}, func(ctx context.Context, input *struct{}) (*struct{}, error) {
+		info = huma.ClientInfoFromContext(ctx)
+		return nil, nil
+	})
+
+	for _, tc := range []struct {
+		name     string
+		remote   string
+		headers  map[string]string
+		expected huma.ClientInfo
+	}{
+		{
+			name:     "direct",
+			remote:   "203.0.113.5:1234",
+			expected: huma.ClientInfo{IP: netip.MustParseAddr("203.0.113.5"), Host: "example.com"},
+		},
+		{
+			name:   "untrusted proxy headers are ignored",
+			remote: "203.0.113.5:1234",
+			headers: map[string]string{
+				"X-Forwarded-For":   "198.51.100.1",
+				"X-Forwarded-Proto": "https",
+			},
+			expected: huma.ClientInfo{IP: netip.MustParseAddr("203.0.113.5"), Host: "example.com"},
+		},
+		{
+			name:   "trusted proxy",
+			remote: "10.0.0.2:1234",
+			headers: map[string]string{
+				"X-Forwarded-For":   "198.51.100.1",
+				"X-Forwarded-Proto": "HTTPS",
+				"X-Forwarded-Host":  "api.example.com",
+			},
+			expected: huma.ClientInfo{IP: netip.MustParseAddr("198.51.100.1"), Scheme: "https", Host: "api.example.com", Proxied: true},
+		},
+		{
+			name:   "spoofed hops are skipped",
+			remote: "10.0.0.2:1234",
+			headers: map[string]string{
+				"X-Forwarded-For": "1.2.3.4, 198.51.100.1, 10.1.1.1",
+			},
+			expected: huma.ClientInfo{IP: netip.MustParseAddr("198.51.100.1"), Host: "example.com", Proxied: true},
+		},
+		{
+			name:   "ipv6 proxy",
+			remote: "[fd00::1]:1234",
+			headers: map[string]string{
+				"X-Forwarded-For": "[2001:db8::5]:4567",
+			},
+			expected: huma.ClientInfo{IP: netip.MustParseAddr("2001:db8::5"), Host: "example.com", Proxied: true},
+		},
+		{
+			name:   "all trusted",
+			remote: "10.0.0.2:1234",
+			headers: map[string]string{
+				"X-Forwarded-For": "10.0.0.3",
+			},
+			expected: huma.ClientInfo{IP: netip.MustParseAddr("10.0.0.3"), Host: "example.com", Proxied: true},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/info", nil)
+			req.RemoteAddr = tc.remote
+			for k, v := range tc.headers {
+				req.Header.Set(k, v)
+			}
+			w := httptest.NewRecorder()
+			api.Adapter().ServeHTTP(w, req)
+			assert.Equal(t, http.StatusNoContent, w.Code)
+			assert.Equal(t, tc.expected, info)
+		})
+	}
+}
+
+func TestClientInfoInvalidProxy(t *testing.T) {
+	config := huma.DefaultConfig("Test API", "1.0.0")
+	config.TrustedProxies = []string{"not-an-ip"}
+	_, api := humatest.New(t, config)
+
+	assert.Panics(t, func() {
+		huma.Register(api, huma.Operation{
+			OperationID: "info",
+			Method:      http.MethodGet,
+			Path:        "/info",
+		}, func(ctx context.Context, input *struct{}) (*struct{}, error) {
+			return nil, nil
+		})
+	})
+}
+
+func TestClientInfoMissing(t *testing.T) {
+	assert.Equal(t, huma.ClientInfo{}, huma.ClientInfoFromContext(context.Background()))
+}
+
+func TestClientInfoForwarded(t *testing.T) {
+	config := huma.DefaultConfig("Test API", "1.0.0")
+	config.TrustedProxies = []string{"10.0.0.0/8"}
+	_, api := humatest.New(t, config)
+
+	var info huma.ClientInfo
+	huma.Register(api, huma.Operation{
+		OperationID: "info",
+		Method:      http.MethodGet,
+		Path:        "/info",
+	}, func(ctx context.Context, input *struct{}) (*struct{}, error) {
+		info = huma.ClientInfoFromContext(ctx)
+		return nil, nil
+	})
+
+	for _, tc := range []struct {
+		name      string
+		remote    string
+		forwarded []string
+		xff       string
+		ip        string
+		id        string
+		scheme    string
+		host      string
+		proxied   bool
+	}{
+		{
+			name:      "untrusted",
+			remote:    "203.0.113.5:1234",
+			forwarded: []string{"for=198.51.100.1;proto=https"},
+			ip:        "203.0.113.5",
+			host:      "example.com",
+		},
+		{
+			name:      "trusted",
+			remote:    "10.0.0.2:1234",
+			forwarded: []string{`for=1.2.3.4, for="198.51.100.1:5678";proto=https;host=api.example.com;by=10.0.0.1`, "for=10.1.1.1;proto=http"},
+			xff:       "9.9.9.9",
+			ip:        "198.51.100.1",
+			scheme:    "https",
+			host:      "api.example.com",
+			proxied:   true,
+		},
+		{
+			name:      "obfuscated",
+			remote:    "10.0.0.2:1234",
+			forwarded: []string{"for=_client1"},
+			id:        "_client1",
+			host:      "example.com",
+			proxied:   true,
+		},
+		{
+			name:      "invalid",
+			remote:    "10.0.0.2:1234",
+			forwarded: []string{"for=bad value"},
+			xff:       "198.51.100.1",
+			ip:        "10.0.0.2",
+			host:      "example.com",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/info", nil)
+			req.RemoteAddr = tc.remote
+			for _, v := range tc.forwarded {
+				req.Header.Add("Forwarded", v)
+			}
+			if tc.xff != "" {
+				req.Header.Set("X-Forwarded-For", tc.xff)
+			}
+			w := httptest.NewRecorder()
+			api.Adapter().ServeHTTP(w, req)
+			assert.Equal(t, http.StatusNoContent, w.Code)
+			if tc.ip != "" {
+				assert.Equal(t, netip.MustParseAddr(tc.ip), info.IP)
+			} else {
+				assert.False(t, info.IP.IsValid())
+			}
+			assert.Equal(t, tc.id, info.ID)
+			assert.Equal(t, tc.scheme, info.Scheme)
+			assert.Equal(t, tc.host, info.Host)
+			assert.Equal(t, tc.proxied, info.Proxied)
+		})
+	}
+}
+
+func TestClientInfoForwardedForLines(t *testing.T) {
+	config := huma.DefaultConfig("Test API", "1.0.0")
+	config.TrustedProxies = []string{"10.0.0.0/8"}
+	_, api := humatest.New(t, config)
+
+	var info huma.ClientInfo
+	huma.Register(api, huma.Operation{
+		OperationID: "info",
+		Method:      http.MethodGet,
+		Path:        "/info",
+	}, func(ctx context.Context, input *struct{}) (*struct{}, error) {
+		info = huma.ClientInfoFromContext(ctx)
+		return nil, nil
+	})
+
+	// The client sends its own forged line and the proxy adds another rather
+	// than appending to the existing one.
+	req := httptest.NewRequest(http.MethodGet, "/info", nil)
+	req.RemoteAddr = "10.0.0.2:1234"
+	req.Header.Add("X-Forwarded-For", "1.2.3.4")
+	req.Header.Add("X-Forwarded-For", "198.51.100.1, 10.1.1.1")
+	w := httptest.NewRecorder()
+	api.Adapter().ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, netip.MustParseAddr("198.51.100.1"), info.IP)
+	assert.True(t, info.Proxied)
+}
@@ -0,0 +1,446 @@
+// Package cloudevents provides helpers for receiving and emitting
+// [CloudEvents] over HTTP in both binary and structured content modes.
+// Event data is validated against its schema from the API's registry, and
+// the event attributes and data are documented in the OpenAPI.
+//
+//	cloudevents.Register(api, huma.Operation{
+//		OperationID: "order-created",
+//		Method:      http.MethodPost,
+//		Path:        "/events/orders",
+//	}, func(ctx context.Context, event *cloudevents.Event[Order]) error {
+//		fmt.Println("Received", event.Type, "for order", event.Data.ID)
+//		return nil
+//	})
+//
+// [CloudEvents]: https://cloudevents.io/
+package cloudevents
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// SpecVersion is the CloudEvents specification version supported by this
+// package.
+const SpecVersion = "1.0"
+
+// ContentType is the content type of events in structured content mode.
+const ContentType = "application/cloudevents+json"
+
+// metadataKey is the operation metadata key used to pass the data schema to
+// the input resolver.
+const metadataKey = "cloudevents"
+
+// Mode is an HTTP content mode for CloudEvents.
+type Mode int
+
+const (
+	// Binary mode sends the event attributes as `ce-` prefixed headers and
+	// the event data as the body.
+	Binary Mode = iota
+
+	// Structured mode sends the whole event, including its data, as a JSON
+	// body with the `application/cloudevents+json` content type.
+	Structured
+)
+
+// Event is a CloudEvent with data of type `T`.
+type Event[T any] struct {
+	// ID identifies the event. Required.
+	ID string
+
+	// Source identifies the context in which the event happened, as a URI
+	// reference. Required.
+	Source string
+
+	// Type describes the type of event, e.g. `com.example.order.created`.
+	// Required.
+	Type string
+
+	// Subject describes the subject of the event in the context of the
+	// source, e.g. an order ID.
+	Subject string
+
+	// Time is when the event happened.
+	Time time.Time
+
+	// DataSchema is a URI identifying the schema of the data.
+	DataSchema string
+
+	// DataContentType is the content type of the data. Defaults to
+	// `application/json`.
+	DataContentType string
+
+	// Extensions are extension attributes, keyed by their lowercase names.
+	Extensions map[string]string
+
+	// Data is the event payload.
+	Data T
+}
+
+// info is stored in the operation metadata for the input resolver.
+type info struct {
+	registry huma.Registry
+	schema   *huma.Schema
+}
+
+// input is the operation input for receiving events. It parses and validates
+// the event in either content mode.
+type input[T any] struct {
+	RawBody []byte
+
+	event Event[T]
+	mode  Mode
+}
+
+func (i *input[T]) Resolve(ctx huma.Context) []error {
+	inf := ctx.Operation().Metadata[metadataKey].(*info)
+
+	contentType := ctx.Header("Content-Type")
+	if mt, _, ok := strings.Cut(contentType, ";"); ok {
+		contentType = mt
+	}
+	contentType = strings.TrimSpace(strings.ToLower(contentType))
+
+	attrs := map[string]any{}
+	var data any
+	location := "body"
+	if contentType == ContentType {
+		i.mode = Structured
+		location = "body.data"
+		if err := json.Unmarshal(i.RawBody, &attrs); err != nil {
+			return []error{&huma.ErrorDetail{Location: "body", Message: "invalid event: " + err.Error()}}
+		}
+		if _, ok := attrs["data_base64"]; ok {
+			return []error{&huma.ErrorDetail{Location: "body.data_base64", Message: "binary data is not supported"}}
+		}
+		data = attrs["data"]
+		delete(attrs, "data")
+	} else {
+		i.mode = Binary
+		ctx.EachHeader(func(name, value string) {
+			name = strings.ToLower(name)
+			if strings.HasPrefix(name, "ce-") {
+				attrs[name[3:]] = value
+			}
+		})
+		if contentType != "" {
+			attrs["datacontenttype"] = contentType
+		}
+		if len(bytes.TrimSpace(i.RawBody)) > 0 {
+			if !isJSON(contentType) {
+				return []error{huma.Error415UnsupportedMediaType("event data must be JSON")}
+			}
+			if err := json.Unmarshal(i.RawBody, &data); err != nil {
+				return []error{&huma.ErrorDetail{Location: "body", Message: "invalid data: " + err.Error()}}
+			}
+		}
+	}
+
+	errs := i.setAttributes(attrs)
+	if len(errs) > 0 {
+		return errs
+	}
+
+	if inf.schema != nil {
+		pb := huma.NewPathBuffer([]byte(location), len(location))
+		res := &huma.ValidateResult{}
+		huma.Validate(inf.registry, inf.schema, pb, huma.ModeWriteToServer, data, res)
+		if len(res.Errors) > 0 {
+			return res.Errors
+		}
+	}
+
+	if data != nil {
+		b, _ := json.Marshal(data)
+		if err := json.Unmarshal(b, &i.event.Data); err != nil {
+			return []error{&huma.ErrorDetail{Location: location, Message: err.Error()}}
+		}
+	}
+	return nil
+}
+
+// setAttributes sets and checks the event attributes.
+func (i *input[T]) setAttributes(attrs map[string]any) []error {
+	loc := func(name string) string {
+		if i.mode == Binary {
+			return "header.ce-" + name
+		}
+		return "body." + name
+	}
+
+	errs := []error{}
+	str := func(name string) string {
+		v, ok := attrs[name]
+		delete(attrs, name)
+		if !ok || v == nil {
+			return ""
+		}
+		s, ok := v.(string)
+		if !ok {
+			errs = append(errs, &huma.ErrorDetail{Location: loc(name), Message: "expected string", Value: v})
+		}
+		return s
+	}
+
+	e := &i.event
+	version := str("specversion")
+	e.ID = str("id")
+	e.Source = str("source")
+	e.Type = str("type")
+	e.Subject = str("subject")
+	e.DataSchema = str("dataschema")
+	e.DataContentType = str("datacontenttype")
+	if t := str("time"); t != "" {
+		var err error
+		if e.Time, err = time.Parse(time.RFC3339Nano, t); err != nil {
+			errs = append(errs, &huma.ErrorDetail{Location: loc("time"), Message: "expected RFC 3339 date-time", Value: t})
+		}
+	}
+
+	for name, value := range map[string]string{"specversion": version, "id": e.ID, "source": e.Source, "type": e.Type} {
+		if value == "" {
+			errs = append(errs, &huma.ErrorDetail{Location: loc(name), Message: "required attribute is missing"})
+		}
+	}
+	if version != "" && version != SpecVersion {
+		errs = append(errs, &huma.ErrorDetail{Location: loc("specversion"), Message: "unsupported spec version, expected " + SpecVersion, Value: version})
+	}
+
+	for name, value := range attrs {
+		if e.Extensions == nil {
+			e.Extensions = map[string]string{}
+		}
+		e.Extensions[name] = fmt.Sprint(value)
+	}
+
+	// Sort for a stable error order.
+	sort.Slice(errs, func(a, b int) bool {
+		return errs[a].(*huma.ErrorDetail).Location < errs[b].(*huma.ErrorDetail).Location
+	})
+	return errs
+}
+
+func isJSON(contentType string) bool {
+	return contentType == "" || contentType == "application/json" || strings.HasSuffix(contentType, "+json")
+}
+
+// Register an operation which receives events with data of type `T` in
+// either content mode. The event data is validated against its schema before
+// the handler is called. On success, the operation responds with
+// `204 No Content`.
+func Register[T any](api huma.API, op huma.Operation, handler func(ctx context.Context, event *Event[T]) error) {
+	setup[T](api, &op)
+	huma.Register(api, op, func(ctx context.Context, in *input[T]) (*struct{}, error) {
+		return nil, handler(ctx, &in.event)
+	})
+}
+
+// RegisterWithReply registers an operation which receives events with data
+// of type `T` and may reply with an event with data of type `R`, e.g. for
+// event routers which support reply events. The reply is sent in the same
+// content mode as the received event. If the reply is nil, the operation
+// responds with `204 No Content`.
+func RegisterWithReply[T, R any](api huma.API, op huma.Operation, handler func(ctx context.Context, event *Event[T]) (*Event[R], error)) {
+	setup[T](api, &op)
+
+	registry := api.OpenAPI().Components.Schemas
+	dataSchema := registry.Schema(reflect.TypeOf((*R)(nil)).Elem(), true, op.OperationID+"ReplyData")
+	if op.Responses == nil {
+		op.Responses = map[string]*huma.Response{}
+	}
+	op.Responses["200"] = &huma.Response{
+		Description: "Reply event",
+		Headers:     attributeParams(),
+		Content: map[string]*huma.MediaType{
+			"application/json": {Schema: dataSchema},
+			ContentType:        {Schema: envelopeSchema(dataSchema)},
+		},
+	}
+	op.Responses["204"] = &huma.Response{Description: "No reply"}
+
+	huma.Register(api, op, func(ctx context.Context, in *input[T]) (*huma.StreamResponse, error) {
+		reply, err := handler(ctx, &in.event)
+		if err != nil {
+			return nil, err
+		}
+		return &huma.StreamResponse{
+			Body: func(ctx huma.Context) {
+				if reply == nil {
+					ctx.SetStatus(http.StatusNoContent)
+					return
+				}
+				headers, body, err := Encode(reply, in.mode)
+				if err != nil {
+					huma.WriteErr(api, ctx, http.StatusInternalServerError, "unable to encode reply", err)
+					return
+				}
+				for name, values := range headers {
+					for _, v := range values {
+						ctx.AppendHeader(name, v)
+					}
+				}
+				ctx.SetStatus(http.StatusOK)
+				ctx.BodyWriter().Write(body)
+			},
+		}, nil
+	})
+}
+
+// setup documents the event request body & attribute headers and stores
+// the data schema for validation.
+func setup[T any](api huma.API, op *huma.Operation) {
+	registry := api.OpenAPI().Components.Schemas
+	dataSchema := registry.Schema(reflect.TypeOf((*T)(nil)).Elem(), true, op.OperationID+"Data")
+
+	if op.Metadata == nil {
+		op.Metadata = map[string]any{}
+	}
+	op.Metadata[metadataKey] = &info{registry: registry, schema: dataSchema}
+
+	if op.RequestBody == nil {
+		op.RequestBody = &huma.RequestBody{
+			Description: "A CloudEvent in binary content mode, with the attributes in `ce-` headers and the data as the body, or in structured content mode.",
+			Content: map[string]*huma.MediaType{
+				"application/json": {Schema: dataSchema},
+				ContentType:        {Schema: envelopeSchema(dataSchema)},
+			},
+		}
+	}
+	for _, a := range attributes {
+		op.Parameters = append(op.Parameters, &huma.Param{
+			Name:        "ce-" + a.name,
+			In:          "header",
+			Description: a.description + " Binary content mode only.",
+			Schema:      &huma.Schema{Type: huma.TypeString, Format: a.format},
+		})
+	}
+}
+
+var attributes = []struct {
+	name, description, format string
+}{
+	{"id", "Identifies the event.", ""},
+	{"source", "Identifies the context in which the event happened.", "uri-reference"},
+	{"specversion", "The CloudEvents specification version.", ""},
+	{"type", "The type of event.", ""},
+	{"subject", "The subject of the event in the context of the source.", ""},
+	{"time", "When the event happened.", "date-time"},
+	{"dataschema", "Identifies the schema of the data.", "uri"},
+}
+
+// attributeParams returns the documentation for the attribute headers used
+// in binary content mode.
+func attributeParams() map[string]*huma.Param {
+	params := map[string]*huma.Param{}
+	for _, a := range attributes {
+		params["ce-"+a.name] = &huma.Param{
+			Description: a.description,
+			Schema:      &huma.Schema{Type: huma.TypeString, Format: a.format},
+		}
+	}
+	return params
+}
+
+// envelopeSchema returns the schema of a structured mode event.
+func envelopeSchema(data *huma.Schema) *huma.Schema {
+	s := &huma.Schema{
+		Type:       huma.TypeObject,
+		Properties: map[string]*huma.Schema{"data": data},
+		Required:   []string{"id", "source", "specversion", "type"},
+	}
+	for _, a := range attributes {
+		s.Properties[a.name] = &huma.Schema{Type: huma.TypeString, Format: a.format, Description: a.description}
+	}
+	s.Properties["specversion"].Enum = []any{SpecVersion}
+	s.Properties["datacontenttype"] = &huma.Schema{Type: huma.TypeString, Description: "The content type of the data."}
+	return s
+}
+
+// Encode an event in the given content mode, returning the headers and
+// body to send.
+func Encode[T any](event *Event[T], mode Mode) (http.Header, []byte, error) {
+	data, err := json.Marshal(event.Data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	attrs := map[string]string{
+		"specversion": SpecVersion,
+		"id":          event.ID,
+		"source":      event.Source,
+		"type":        event.Type,
+		"subject":     event.Subject,
+		"dataschema":  event.DataSchema,
+	}
+	if !event.Time.IsZero() {
+		attrs["time"] = event.Time.Format(time.RFC3339Nano)
+	}
+	for name, value := range event.Extensions {
+		attrs[strings.ToLower(name)] = value
+	}
+	contentType := event.DataContentType
+	if contentType == "" {
+		contentType = "application/json"
+	}
+
+	headers := http.Header{}
+	if mode == Binary {
+		for name, value := range attrs {
+			if value != "" {
+				headers.Set("ce-"+name, value)
+			}
+		}
+		headers.Set("Content-Type", contentType)
+		return headers, data, nil
+	}
+
+	envelope := map[string]any{"datacontenttype": contentType, "data": json.RawMessage(data)}
+	for name, value := range attrs {
+		if value != "" {
+			envelope[name] = value
+		}
+	}
+	body, err := json.Marshal(envelope)
+	if err != nil {
+		return nil, nil, err
+	}
+	headers.Set("Content-Type", ContentType)
+	return headers, body, nil
+}
+
+// NewRequest creates a request to emit an event to a sink URL using the
+// given content mode.
+//
+//	req, err := cloudevents.NewRequest(ctx, "https://example.com/events", &cloudevents.Event[Order]{
+//		ID:     "abc123",
+//		Source: "/orders",
+//		Type:   "com.example.order.created",
+//		Data:   order,
+//	}, cloudevents.Binary)
+//	resp, err := http.DefaultClient.Do(req)
+func NewRequest[T any](ctx context.Context, url string, event *Event[T], mode Mode) (*http.Request, error) {
+	headers, body, err := Encode(event, mode)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, io.NopCloser(bytes.NewReader(body)))
+	if err != nil {
+		return nil, err
+	}
+	req.ContentLength = int64(len(body))
+	for name, values := range headers {
+		req.Header[name] = values
+	}
+	return req, nil
+}
@@ -0,0 +1,266 @@
+package cloudevents
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/humatest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type Order struct {
+	ID    string `json:"id" minLength:"3"`
+	Total int    `json:"total" minimum:"0"`
+}
+
+type Receipt struct {
+	OrderID string `json:"orderId"`
+}
+
+func TestReceiveBinary(t *testing.T) {
+	_, api := humatest.New(t)
+
+	var received *Event[Order]
+	Register(api, huma.Operation{
+		OperationID: "order-created",
+		Method:      http.MethodPost,
+		Path:        "/events",
+	}, func(ctx context.Context, event *Event[Order]) error {
+		received = event
+		return nil
+	})
+
+	resp := api.Post("/events",
+		"Content-Type: application/json",
+		"ce-specversion: 1.0",
+		"ce-id: abc",
+		"ce-source: /orders",
+		"ce-type: com.example.order.created",
+		"ce-time: 2024-01-02T03:04:05Z",
+		"ce-traceparent: 00-abc-def-01",
+		strings.NewReader(`{"id": "o123", "total": 5}`))
+	require.Equal(t, http.StatusNoContent, resp.Code, resp.Body.String())
+	require.NotNil(t, received)
+	assert.Equal(t, "abc", received.ID)
+	assert.Equal(t, "/orders", received.Source)
+	assert.Equal(t, "com.example.order.created", received.Type)
+	assert.Equal(t, "application/json", received.DataContentType)
+	assert.Equal(t, time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), received.Time)
+	assert.Equal(t, map[string]string{"traceparent": "00-abc-def-01"}, received.Extensions)
+	assert.Equal(t, Order{ID: "o123", Total: 5}, received.Data)
+}
+
+func TestReceiveStructured(t *testing.T) {
+	_, api := humatest.New(t)
+
+	var received *Event[Order]
+	Register(api, huma.Operation{
+		OperationID: "order-created",
+		Method:      http.MethodPost,
+		Path:        "/events",
+	}, func(ctx context.Context, event *Event[Order]) error {
+		received = event
+		return nil
+	})
+
+	resp := api.Post("/events",
+		"Content-Type: application/cloudevents+json; charset=utf-8",
+		strings.NewReader(`{
+			"specversion": "1.0",
+			"id": "abc",
+			"source": "/orders",
+			"type": "com.example.order.created",
+			"subject": "o123",
+			"data": {"id": "o123", "total": 5}
+		}`))
+	require.Equal(t, http.StatusNoContent, resp.Code, resp.Body.String())
+	assert.Equal(t, "o123", received.Subject)
+	assert.Equal(t, Order{ID: "o123", Total: 5}, received.Data)
+}
+
+func TestReceiveErrors(t *testing.T) {
+	_, api := humatest.New(t)
+
+	Register(api, huma.Operation{
+		OperationID: "order-created",
+		Method:      http.MethodPost,
+		Path:        "/events",
+	}, func(ctx context.Context, event *Event[Order]) error {
+		if event.Data.ID == "bad" {
+			return huma.Error409Conflict("duplicate order")
+		}
+		return nil
+	})
+
+	headers := []any{"ce-specversion: 1.0", "ce-id: abc", "ce-source: /orders", "ce-type: t"}
+
+	// Missing attributes.
+	resp := api.Post("/events", "ce-specversion: 0.3", strings.NewReader(`{"id": "o123"}`))
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+	assert.Contains(t, resp.Body.String(), `"location":"header.ce-id"`)
+	assert.Contains(t, resp.Body.String(), "unsupported spec version")
+
+	// Data validation against the schema.
+	resp = api.Post("/events", append(headers, strings.NewReader(`{"id": "o", "total": -1}`))...)
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+	assert.Contains(t, resp.Body.String(), `"location":"body.id"`)
+	assert.Contains(t, resp.Body.String(), `"location":"body.total"`)
+
+	resp = api.Post("/events", "Content-Type: application/cloudevents+json", strings.NewReader(`{
+		"specversion": "1.0", "id": "abc", "source": "/orders", "type": "t",
+		"data": {"total": 1}
+	}`))
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+	assert.Contains(t, resp.Body.String(), `"location":"body.data"`)
+
+	// Non-JSON data.
+	resp = api.Post("/events", append(headers, "Content-Type: text/plain", strings.NewReader(`hello`))...)
+	assert.Equal(t, http.StatusUnsupportedMediaType, resp.Code)
+
+	// Handler errors.
+	resp = api.Post("/events", append(headers, strings.NewReader(`{"id": "bad", "total": 1}`))...)
+	assert.Equal(t, http.StatusConflict, resp.Code)
+}
+
+func TestReply(t *testing.T) {
+	_, api := humatest.New(t)
+
+	RegisterWithReply(api, huma.Operation{
+		OperationID: "order-created",
+		Method:      http.MethodPost,
+		Path:        "/events",
+	}, func(ctx context.Context, event *Event[Order]) (*Event[Receipt], error) {
+		if event.Data.Total == 0 {
+			return nil, nil
+		}
+		return &Event[Receipt]{
+			ID:     "r-" + event.ID,
+			Source: "/receipts",
+			Type:   "com.example.receipt.created",
+			Data:   Receipt{OrderID: event.Data.ID},
+		}, nil
+	})
+
+	resp := api.Post("/events",
+		"ce-specversion: 1.0", "ce-id: abc", "ce-source: /orders", "ce-type: t",
+		strings.NewReader(`{"id": "o123", "total": 5}`))
+	require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+	assert.Equal(t, "r-abc", resp.Header().Get("ce-id"))
+	assert.Equal(t, "com.example.receipt.created", resp.Header().Get("ce-type"))
+	assert.Equal(t, "1.0", resp.Header().Get("ce-specversion"))
+	assert.Equal(t, "application/json", resp.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{"orderId": "o123"}`, resp.Body.String())
+
+	// Replies use the same content mode as the received event.
+	resp = api.Post("/events", "Content-Type: application/cloudevents+json", strings.NewReader(`{
+		"specversion": "1.0", "id": "abc", "source": "/orders", "type": "t",
+		"data": {"id": "o123", "total": 5}
+	}`))
+	require.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, ContentType, resp.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{
+		"specversion": "1.0",
+		"id": "r-abc",
+		"source": "/receipts",
+		"type": "com.example.receipt.created",
+		"datacontenttype": "application/json",
+		"data": {"orderId": "o123"}
+	}`, resp.Body.String())
+
+	resp = api.Post("/events",
+		"ce-specversion: 1.0", "ce-id: abc", "ce-source: /orders", "ce-type: t",
+		strings.NewReader(`{"id": "o123", "total": 0}`))
+	assert.Equal(t, http.StatusNoContent, resp.Code)
+}
+
+func TestOpenAPI(t *testing.T) {
+	_, api := humatest.New(t)
+
+	RegisterWithReply(api, huma.Operation{
+		OperationID: "order-created",
+		Method:      http.MethodPost,
+		Path:        "/events",
+	}, func(ctx context.Context, event *Event[Order]) (*Event[Receipt], error) {
+		return nil, nil
+	})
+
+	op := api.OpenAPI().Paths["/events"].Post
+	assert.Equal(t, "#/components/schemas/Order", op.RequestBody.Content["application/json"].Schema.Ref)
+	envelope := op.RequestBody.Content[ContentType].Schema
+	assert.Equal(t, "#/components/schemas/Order", envelope.Properties["data"].Ref)
+	assert.Contains(t, envelope.Required, "specversion")
+
+	names := []string{}
+	for _, p := range op.Parameters {
+		names = append(names, p.Name)
+	}
+	assert.Contains(t, names, "ce-id")
+	assert.Contains(t, names, "ce-type")
+
+	assert.Equal(t, "#/components/schemas/Receipt", op.Responses["200"].Content["application/json"].Schema.Ref)
+	assert.NotNil(t, op.Responses["200"].Headers["ce-id"])
+	assert.NotNil(t, op.Responses["204"])
+}
+
+func TestNewRequest(t *testing.T) {
+	event := &Event[Receipt]{
+		ID:         "abc",
+		Source:     "/receipts",
+		Type:       "com.example.receipt.created",
+		Time:       time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Extensions: map[string]string{"Partition": "p1"},
+		Data:       Receipt{OrderID: "o123"},
+	}
+
+	req, err := NewRequest(context.Background(), "https://example.com/events", event, Binary)
+	require.NoError(t, err)
+	assert.Equal(t, http.MethodPost, req.Method)
+	assert.Equal(t, "abc", req.Header.Get("ce-id"))
+	assert.Equal(t, "2024-01-02T03:04:05Z", req.Header.Get("ce-time"))
+	assert.Equal(t, "p1", req.Header.Get("ce-partition"))
+	assert.Empty(t, req.Header.Get("ce-subject"))
+	body, _ := io.ReadAll(req.Body)
+	assert.JSONEq(t, `{"orderId": "o123"}`, string(body))
+
+	req, err = NewRequest(context.Background(), "https://example.com/events", event, Structured)
+	require.NoError(t, err)
+	assert.Equal(t, ContentType, req.Header.Get("Content-Type"))
+	body, _ = io.ReadAll(req.Body)
+	var envelope map[string]any
+	require.NoError(t, json.Unmarshal(body, &envelope))
+	assert.Equal(t, "p1", envelope["partition"])
+	assert.Equal(t, map[string]any{"orderId": "o123"}, envelope["data"])
+
+	// Emitted events can be received.
+	_, api := humatest.New(t)
+	var received *Event[Receipt]
+	Register(api, huma.Operation{
+		OperationID: "receipt-created",
+		Method:      http.MethodPost,
+		Path:        "/events",
+	}, func(ctx context.Context, e *Event[Receipt]) error {
+		received = e
+		return nil
+	})
+	for _, mode := range []Mode{Binary, Structured} {
+		req, _ = NewRequest(context.Background(), "/events", event, mode)
+		args := []any{}
+		for name := range req.Header {
+			args = append(args, name+": "+req.Header.Get(name))
+		}
+		body, _ = io.ReadAll(req.Body)
+		resp := api.Post("/events", append(args, bytes.NewReader(body))...)
+		require.Equal(t, http.StatusNoContent, resp.Code, resp.Body.String())
+		assert.Equal(t, event.Data, received.Data)
+		assert.Equal(t, event.Time, received.Time)
+		assert.Equal(t, map[string]string{"partition": "p1"}, received.Extensions)
+	}
+}
@@ -0,0 +1,101 @@
+package huma
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+)
+
+// CompactSchemas returns the OpenAPI as JSON, with structurally identical
+// schemas generated from anonymous structs merged into a single shared
+// schema. Anonymous structs get a schema for each place they are used, so
+// large APIs which repeat them inline can shrink substantially. Schemas for
+// named types are left as-is, as they may differ in meaning even if they
+// have the same structure. The OpenAPI itself is not modified, so request
+// validation is unaffected. See also `Config.CompactSchemas`.
+func CompactSchemas(oapi *OpenAPI) ([]byte, error) {
+	b, err := json.Marshal(oapi)
+	if err != nil || oapi.Components == nil || oapi.Components.Schemas == nil {
+		return b, err
+	}
+
+	var doc map[string]any
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	if err := dec.Decode(&doc); err != nil {
+		return nil, err
+	}
+
+	components, _ := doc["components"].(map[string]any)
+	schemas, _ := components["schemas"].(map[string]any)
+	if len(schemas) == 0 {
+		return b, nil
+	}
+
+	registry := oapi.Components.Schemas
+	prefix := registryPrefix(registry)
+	candidates := []string{}
+	for name := range schemas {
+		if t := registry.TypeFromRef(prefix + name); t != nil && deref(t).Name() == "" {
+			candidates = append(candidates, name)
+		}
+	}
+	// Prefer keeping the shortest names, which are usually the most generic.
+	sort.Slice(candidates, func(i, j int) bool {
+		if len(candidates[i]) != len(candidates[j]) {
+			return len(candidates[i]) < len(candidates[j])
+		}
+		return candidates[i] < candidates[j]
+	})
+
+	for {
+		// Group identical schemas, keeping the first name of each group. Maps
+		// are marshaled with sorted keys, so the JSON is a canonical form.
+		kept := map[string]string{}
+		renames := map[string]string{}
+		remaining := candidates[:0]
+		for _, name := range candidates {
+			key, err := json.Marshal(schemas[name])
+			if err != nil {
+				return nil, err
+			}
+			if existing, ok := kept[string(key)]; ok {
+				renames[prefix+name] = prefix + existing
+				delete(schemas, name)
+				continue
+			}
+			kept[string(key)] = name
+			remaining = append(remaining, name)
+		}
+		candidates = remaining
+
+		if len(renames) == 0 {
+			break
+		}
+
+		// Merging may make schemas which referenced them identical too, so
+		// keep going until nothing changes.
+		rewriteRefs(doc, renames)
+	}
+
+	return json.Marshal(doc)
+}
+
+// rewriteRefs replaces each `$ref` found in `renames` with its new value.
+func rewriteRefs(v any, renames map[string]string) {
+	switch v := v.(type) {
+	case map[string]any:
+		if ref, ok := v["$ref"].(string); ok {
+			if renamed, ok := renames[ref]; ok {
+				v["$ref"] = renamed
+			}
+		}
+		for _, item := range v {
+			rewriteRefs(item, renames)
+		}
+	case []any:
+		for _, item := range v {
+			rewriteRefs(item, renames)
+		}
+	}
+}
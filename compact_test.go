@@ -0,0 +1,111 @@
+package huma_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/humatest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type CompactA struct {
+	Sub struct {
+		Name  string `json:"name"`
+		Inner struct {
+			Value int `json:"value"`
+		} `json:"inner"`
+	} `json:"sub"`
+}
+
+type CompactB struct {
+	Sub struct {
+		Name  string `json:"name"`
+		Inner struct {
+			Value int `json:"value"`
+		} `json:"inner"`
+	} `json:"sub"`
+	Other struct {
+		Value int `json:"value"`
+	} `json:"other"`
+}
+
+// CompactNamed has the same structure as another named type, but is never
+// merged with it.
+type CompactNamed struct {
+	Value int `json:"value"`
+}
+
+type CompactNamedCopy struct {
+	Value int `json:"value"`
+}
+
+func TestCompactSchemas(t *testing.T) {
+	config := huma.DefaultConfig("Test API", "1.0.0")
+	config.CompactSchemas = true
+	_, api := humatest.New(t, config)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "put-a",
+		Method:      http.MethodPut,
+		Path:        "/a",
+	}, func(ctx context.Context, input *struct {
+		Body CompactA
+	}) (*struct{ Body CompactNamed }, error) {
+		return nil, nil
+	})
+
+	huma.Register(api, huma.Operation{
+		OperationID: "put-b",
+		Method:      http.MethodPut,
+		Path:        "/b",
+	}, func(ctx context.Context, input *struct {
+		Body CompactB
+	}) (*struct{ Body CompactNamedCopy }, error) {
+		return nil, nil
+	})
+
+	schemas := api.OpenAPI().Components.Schemas.Map()
+	require.Contains(t, schemas, "CompactASubStruct")
+	require.Contains(t, schemas, "CompactBSubStruct")
+
+	b, err := huma.CompactSchemas(api.OpenAPI())
+	require.NoError(t, err)
+
+	var doc struct {
+		Components struct {
+			Schemas map[string]map[string]any `json:"schemas"`
+		} `json:"components"`
+	}
+	require.NoError(t, json.Unmarshal(b, &doc))
+	compacted := doc.Components.Schemas
+
+	// Identical anonymous structs are merged, keeping the shortest name.
+	assert.Contains(t, compacted, "CompactASubStruct")
+	assert.NotContains(t, compacted, "CompactBSubStruct")
+	assert.Contains(t, compacted, "InnerStruct")
+	assert.NotContains(t, compacted, "CompactBOtherStruct")
+	assert.Contains(t, string(b), `"$ref":"#/components/schemas/CompactASubStruct"`)
+	assert.NotContains(t, string(b), "CompactBSubStruct")
+	assert.NotContains(t, string(b), "CompactBOtherStruct")
+
+	// Named types are kept.
+	assert.Contains(t, compacted, "CompactA")
+	assert.Contains(t, compacted, "CompactB")
+	assert.Contains(t, compacted, "CompactNamed")
+	assert.Contains(t, compacted, "CompactNamedCopy")
+
+	// The registry is unchanged, so validation still works.
+	assert.Contains(t, api.OpenAPI().Components.Schemas.Map(), "CompactBSubStruct")
+	resp := api.Put("/b", map[string]any{"sub": map[string]any{"name": 1}})
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+	assert.Contains(t, resp.Body.String(), "body.sub.name")
+
+	// The served OpenAPI is compacted.
+	resp = api.Get("/openapi.json")
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.NotContains(t, resp.Body.String(), "CompactBSubStruct")
+}
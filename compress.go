@@ -0,0 +1,307 @@
+package huma
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/danielgtaylor/huma/v2/negotiation"
+)
+
+// ContentEncoding compresses response bodies using a content coding like
+// `gzip`. See `Compression`.
+type ContentEncoding struct {
+	// Name of the content coding, sent in the `Content-Encoding` header.
+	Name string
+
+	// NewWriter returns a writer which compresses data written to it and
+	// writes the result to `w`. Closing the writer must flush any remaining
+	// data but not close `w`. If the writer has a `Flush() error` method, it
+	// is used to support streaming responses.
+	NewWriter func(w io.Writer) io.WriteCloser
+}
+
+var gzipWriterPool = sync.Pool{
+	New: func() any {
+		return gzip.NewWriter(nil)
+	},
+}
+
+// pooledGzipWriter returns its writer to the pool once closed.
+type pooledGzipWriter struct {
+	*gzip.Writer
+}
+
+func (w pooledGzipWriter) Close() error {
+	err := w.Writer.Close()
+	gzipWriterPool.Put(w.Writer)
+	return err
+}
+
+// GzipEncoding compresses response bodies with `gzip`.
+var GzipEncoding = ContentEncoding{
+	Name: "gzip",
+	NewWriter: func(w io.Writer) io.WriteCloser {
+		gz := gzipWriterPool.Get().(*gzip.Writer)
+		gz.Reset(w)
+		return pooledGzipWriter{gz}
+	},
+}
+
+// DeflateEncoding compresses response bodies with `deflate`, which is the
+// zlib format.
+var DeflateEncoding = ContentEncoding{
+	Name: "deflate",
+	NewWriter: func(w io.Writer) io.WriteCloser {
+		return zlib.NewWriter(w)
+	},
+}
+
+// Compression configures response compression for all operations, so it
+// behaves the same with every adapter. See `Config.Compression`.
+//
+//	config := huma.DefaultConfig("My API", "1.0.0")
+//	config.Compression = huma.DefaultCompression()
+type Compression struct {
+	// Encodings are the supported content codings, negotiated using the
+	// client's `Accept-Encoding` header. The first is preferred when the
+	// client accepts several equally.
+	Encodings []ContentEncoding
+
+	// MinSize is the minimum response body size in bytes to compress, as
+	// small bodies may get larger when compressed.
+	MinSize int
+
+	// ContentTypes are the media types to compress, e.g. `application/json`.
+	// Use `type/*` to match any subtype or `type/*+suffix` to match any
+	// subtype with a structured syntax suffix, like `application/*+json`.
+	ContentTypes []string
+}
+
+// DefaultCompression returns a compression config using `gzip` and `deflate`
+// for common text-based responses of at least 1KiB. Other codings like `br`
+// or `zstd` can be added using third-party packages.
+func DefaultCompression() *Compression {
+	return &Compression{
+		Encodings: []ContentEncoding{GzipEncoding, DeflateEncoding},
+		MinSize:   1024,
+		ContentTypes: []string{
+			"text/*",
+			"application/json",
+			"application/*+json",
+			"application/xml",
+			"application/*+xml",
+			"application/javascript",
+			"application/cbor",
+			"image/svg+xml",
+		},
+	}
+}
+
+// compressible returns whether responses with the given content type should
+// be compressed.
+func (c *Compression) compressible(contentType string) bool {
+	mt, _, _ := strings.Cut(contentType, ";")
+	mt = strings.ToLower(strings.TrimSpace(mt))
+	typ, sub, ok := strings.Cut(mt, "/")
+	if !ok {
+		return false
+	}
+	for _, pattern := range c.ContentTypes {
+		ptyp, psub, _ := strings.Cut(pattern, "/")
+		if ptyp != typ {
+			continue
+		}
+		if psub == sub || psub == "*" {
+			return true
+		}
+		if suffix, ok := strings.CutPrefix(psub, "*"); ok && strings.HasSuffix(sub, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// compressContext defers sending the response status until enough of the
+// body has been written to decide whether to compress it.
+type compressContext struct {
+	humaContext
+	config   *Compression
+	encoding *ContentEncoding
+
+	status        int
+	contentType   string
+	contentLength string
+	encoded       bool // The handler set its own `Content-Encoding`.
+
+	// decided is set once the status has been sent, after which writes go
+	// to `enc` if compressing, or directly to `w` otherwise.
+	decided bool
+	w       io.Writer
+	enc     io.WriteCloser
+	buf     bytes.Buffer
+}
+
+func (c *compressContext) SetStatus(code int) {
+	c.status = code
+	if code < http.StatusOK || code == http.StatusNoContent || code == http.StatusPartialContent || code == http.StatusNotModified {
+		// There is no body to compress, or it must be sent as-is.
+		c.decide(false)
+	}
+}
+
+func (c *compressContext) SetHeader(name, value string) {
+	switch {
+	case strings.EqualFold(name, "Content-Type"):
+		c.contentType = value
+	case strings.EqualFold(name, "Content-Length") && !c.decided:
+		// Only valid if the body is sent uncompressed.
+		c.contentLength = value
+		return
+	case strings.EqualFold(name, "Content-Encoding"):
+		c.encoded = true
+	}
+	c.humaContext.SetHeader(name, value)
+}
+
+func (c *compressContext) AppendHeader(name, value string) {
+	if strings.EqualFold(name, "Content-Encoding") {
+		c.encoded = true
+	}
+	c.humaContext.AppendHeader(name, value)
+}
+
+func (c *compressContext) BodyWriter() io.Writer {
+	return (*compressWriter)(c)
+}
+
+func (c *compressContext) StreamBody(cb func(w io.Writer, flush func() error)) {
+	// Adapter-managed streams may be written after the handler returns, so
+	// they are never compressed.
+	c.decide(false)
+	streamBody(c.humaContext, cb)
+}
+
+func (c *compressContext) Unwrap() Context {
+	return c.humaContext
+}
+
+// decide sends the status and headers, compressing the rest of the body if
+// `compress` is set and the response is eligible.
+func (c *compressContext) decide(compress bool) {
+	if c.decided {
+		return
+	}
+	c.decided = true
+
+	eligible := !c.encoded && c.config.compressible(c.contentType)
+	if eligible {
+		c.humaContext.AppendHeader("Vary", "Accept-Encoding")
+	}
+	if compress && eligible {
+		c.humaContext.SetHeader("Content-Encoding", c.encoding.Name)
+	} else if c.contentLength != "" {
+		c.humaContext.SetHeader("Content-Length", c.contentLength)
+	}
+	if c.status != 0 {
+		c.humaContext.SetStatus(c.status)
+	}
+	c.w = c.humaContext.BodyWriter()
+	if compress && eligible {
+		c.enc = c.encoding.NewWriter(c.w)
+	}
+
+	if c.buf.Len() > 0 {
+		c.write(c.buf.Bytes())
+		c.buf.Reset()
+	}
+}
+
+func (c *compressContext) write(p []byte) (int, error) {
+	if c.enc != nil {
+		return c.enc.Write(p)
+	}
+	return c.w.Write(p)
+}
+
+// finish sends anything still buffered once the handler has returned.
+func (c *compressContext) finish() {
+	c.decide(c.buf.Len() >= c.config.MinSize)
+	if c.enc != nil {
+		c.enc.Close()
+	}
+}
+
+// compressWriter is the body writer of a `compressContext`.
+type compressWriter compressContext
+
+func (w *compressWriter) Write(p []byte) (int, error) {
+	c := (*compressContext)(w)
+	if c.decided {
+		return c.write(p)
+	}
+	c.buf.Write(p)
+	if c.buf.Len() >= c.config.MinSize {
+		c.decide(true)
+	}
+	return len(p), nil
+}
+
+// FlushError sends any buffered data to the client. Streamed responses are
+// compressed if eligible, whatever their size.
+func (w *compressWriter) FlushError() error {
+	c := (*compressContext)(w)
+	c.decide(true)
+	if f, ok := c.enc.(interface{ Flush() error }); ok {
+		if err := f.Flush(); err != nil {
+			return err
+		}
+	}
+	return flushFunc(c.w)()
+}
+
+// Flush implements `http.Flusher`.
+func (w *compressWriter) Flush() {
+	w.FlushError()
+}
+
+// compressResponses wraps a handler to compress response bodies when enabled
+// in the API's config.
+func compressResponses(api API, op *Operation, handler func(ctx Context)) func(ctx Context) {
+	config := configOf(api).Compression
+	if config == nil || len(config.Encodings) == 0 || op.SkipCompression {
+		return handler
+	}
+
+	names := make([]string, len(config.Encodings))
+	for i, e := range config.Encodings {
+		names[i] = e.Name
+	}
+
+	return func(ctx Context) {
+		accept := ctx.Header("Accept-Encoding")
+		if accept == "" || ctx.Method() == http.MethodHead {
+			handler(ctx)
+			return
+		}
+		name := negotiation.SelectQValue(accept, names)
+		if name == "" {
+			handler(ctx)
+			return
+		}
+
+		cctx := &compressContext{humaContext: ctx, config: config}
+		for i := range config.Encodings {
+			if config.Encodings[i].Name == name {
+				cctx.encoding = &config.Encodings[i]
+				break
+			}
+		}
+		defer cctx.finish()
+		handler(cctx)
+	}
+}
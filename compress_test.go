@@ -0,0 +1,137 @@
+package huma_test
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/humatest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type CompressOutput struct {
+	ContentType string `header:"Content-Type"`
+	Body        []byte
+}
+
+func TestCompression(t *testing.T) {
+	config := huma.DefaultConfig("Test API", "1.0.0")
+	config.Compression = huma.DefaultCompression()
+	_, api := humatest.New(t, config)
+
+	large := strings.Repeat("compress me ", 200)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "get-text",
+		Method:      http.MethodGet,
+		Path:        "/text/{size}",
+	}, func(ctx context.Context, input *struct {
+		Size string `path:"size" enum:"small,large"`
+		Type string `query:"type" default:"text/plain"`
+	}) (*CompressOutput, error) {
+		body := "small"
+		if input.Size == "large" {
+			body = large
+		}
+		return &CompressOutput{ContentType: input.Type, Body: []byte(body)}, nil
+	})
+
+	huma.Register(api, huma.Operation{
+		OperationID:     "get-raw",
+		Method:          http.MethodGet,
+		Path:            "/raw",
+		SkipCompression: true,
+	}, func(ctx context.Context, input *struct{}) (*CompressOutput, error) {
+		return &CompressOutput{ContentType: "text/plain", Body: []byte(large)}, nil
+	})
+
+	resp := api.Get("/text/large", "Accept-Encoding: gzip")
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, "gzip", resp.Header().Get("Content-Encoding"))
+	assert.Contains(t, resp.Header().Values("Vary"), "Accept-Encoding")
+	gz, err := gzip.NewReader(resp.Body)
+	require.NoError(t, err)
+	b, err := io.ReadAll(gz)
+	require.NoError(t, err)
+	assert.Equal(t, large, string(b))
+
+	resp = api.Get("/text/large", "Accept-Encoding: gzip;q=0.5, deflate")
+	assert.Equal(t, "deflate", resp.Header().Get("Content-Encoding"))
+	zr, err := zlib.NewReader(resp.Body)
+	require.NoError(t, err)
+	b, err = io.ReadAll(zr)
+	require.NoError(t, err)
+	assert.Equal(t, large, string(b))
+
+	// Small bodies are sent as-is.
+	resp = api.Get("/text/small", "Accept-Encoding: gzip")
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Empty(t, resp.Header().Get("Content-Encoding"))
+	assert.Equal(t, "small", resp.Body.String())
+
+	// Unsupported or missing encodings and content types are sent as-is.
+	for _, headers := range [][]any{
+		{"Accept-Encoding: br"},
+		{},
+	} {
+		resp = api.Get("/text/large", headers...)
+		assert.Empty(t, resp.Header().Get("Content-Encoding"))
+		assert.Equal(t, large, resp.Body.String())
+	}
+
+	resp = api.Get("/text/large?type=image/png", "Accept-Encoding: gzip")
+	assert.Empty(t, resp.Header().Get("Content-Encoding"))
+	assert.NotContains(t, resp.Header().Values("Vary"), "Accept-Encoding")
+	assert.Equal(t, large, resp.Body.String())
+
+	resp = api.Get("/text/large?type=application/problem%2Bjson", "Accept-Encoding: gzip")
+	assert.Equal(t, "gzip", resp.Header().Get("Content-Encoding"))
+
+	resp = api.Get("/raw", "Accept-Encoding: gzip")
+	assert.Empty(t, resp.Header().Get("Content-Encoding"))
+	assert.Equal(t, large, resp.Body.String())
+
+	// Small error responses are sent as-is.
+	resp = api.Get("/text/medium", "Accept-Encoding: gzip")
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+	assert.Empty(t, resp.Header().Get("Content-Encoding"))
+	assert.Contains(t, resp.Body.String(), "path.size")
+}
+
+func TestCompressionStream(t *testing.T) {
+	config := huma.DefaultConfig("Test API", "1.0.0")
+	config.Compression = huma.DefaultCompression()
+	_, api := humatest.New(t, config)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "stream",
+		Method:      http.MethodGet,
+		Path:        "/stream",
+	}, func(ctx context.Context, input *struct{}) (*huma.StreamResponse, error) {
+		return &huma.StreamResponse{
+			Body: func(ctx huma.Context) {
+				ctx.SetHeader("Content-Type", "text/plain")
+				w := ctx.BodyWriter()
+				w.Write([]byte("first"))
+				// Flushing sends the data so far, compressed, whatever its size.
+				w.(http.Flusher).Flush()
+				w.Write([]byte(" second"))
+			},
+		}, nil
+	})
+
+	resp := api.Get("/stream", "Accept-Encoding: gzip")
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, "gzip", resp.Header().Get("Content-Encoding"))
+	gz, err := gzip.NewReader(resp.Body)
+	require.NoError(t, err)
+	b, err := io.ReadAll(gz)
+	require.NoError(t, err)
+	assert.Equal(t, "first second", string(b))
+}
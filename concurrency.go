@@ -0,0 +1,63 @@
+package huma
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+// concurrencyLimiter is a semaphore with a bounded number of waiters, used to
+// enforce `Operation.MaxConcurrent`.
+type concurrencyLimiter struct {
+	slots    chan struct{}
+	maxQueue int32
+	timeout  time.Duration
+	waiting  atomic.Int32
+}
+
+func newConcurrencyLimiter(op *Operation) *concurrencyLimiter {
+	if op.MaxConcurrent <= 0 {
+		return nil
+	}
+	return &concurrencyLimiter{
+		slots:    make(chan struct{}, op.MaxConcurrent),
+		maxQueue: int32(op.MaxQueue),
+		timeout:  op.QueueTimeout,
+	}
+}
+
+// acquire takes a slot, waiting in the queue if there is room. It returns
+// false if the request should be rejected.
+func (l *concurrencyLimiter) acquire(ctx context.Context) bool {
+	select {
+	case l.slots <- struct{}{}:
+		return true
+	default:
+	}
+
+	if l.waiting.Add(1) > l.maxQueue {
+		l.waiting.Add(-1)
+		return false
+	}
+	defer l.waiting.Add(-1)
+
+	var timeout <-chan time.Time
+	if l.timeout > 0 {
+		t := time.NewTimer(l.timeout)
+		defer t.Stop()
+		timeout = t.C
+	}
+
+	select {
+	case l.slots <- struct{}{}:
+		return true
+	case <-ctx.Done():
+		return false
+	case <-timeout:
+		return false
+	}
+}
+
+func (l *concurrencyLimiter) release() {
+	<-l.slots
+}
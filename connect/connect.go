@@ -0,0 +1,479 @@
+// Package connect exposes selected operations of a Huma API over the
+// [Connect protocol] and optionally gRPC, so internal callers can use binary
+// RPC while external callers keep using REST. Protobuf messages are generated
+// from the operation schemas and each call is handled by calling the
+// operation's `huma.OperationHandler` internally, so API middleware,
+// validation, and error handling all behave the same as for REST requests.
+//
+//	connect.Expose(api, connect.Config{
+//		Service: "things.v1.ThingService",
+//		Filter: func(op *huma.Operation) bool {
+//			return op.Metadata["rpc"] == true
+//		},
+//		GRPC: true,
+//	})
+//
+// Each operation becomes a unary method named after its operation ID in
+// upper camel case. The request message has a field for each parameter and a
+// `body` field for the request body. The response message is the response
+// body's schema. The generated `.proto` file is served at `{ProtoPath}`.
+//
+// Expose should be called after registering operations.
+//
+// [Connect protocol]: https://connectrpc.com/docs/protocol
+package connect
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// Config configures the RPC bridge.
+type Config struct {
+	// Service is the fully qualified name of the protobuf service, e.g.
+	// `things.v1.ThingService`. Defaults to `api.v1.APIService`.
+	Service string
+
+	// Filter selects which operations are exposed. If nil, all operations
+	// with an operation ID are exposed.
+	Filter func(op *huma.Operation) bool
+
+	// GRPC enables the gRPC protocol in addition to Connect. gRPC requires
+	// HTTP/2 with support for trailers, so make sure your server and router
+	// support them.
+	GRPC bool
+
+	// ProtoPath is the URL path to serve the generated `.proto` file at.
+	// Defaults to `/{Service}.proto`.
+	ProtoPath string
+}
+
+// Error codes shared by the Connect and gRPC protocols.
+var codes = map[string]int{
+	"canceled":            1,
+	"unknown":             2,
+	"invalid_argument":    3,
+	"deadline_exceeded":   4,
+	"not_found":           5,
+	"already_exists":      6,
+	"permission_denied":   7,
+	"resource_exhausted":  8,
+	"failed_precondition": 9,
+	"aborted":             10,
+	"unimplemented":       12,
+	"internal":            13,
+	"unavailable":         14,
+	"unauthenticated":     16,
+}
+
+// statusCodes maps operation HTTP status codes to RPC error codes.
+var statusCodes = map[int]string{
+	http.StatusBadRequest:          "invalid_argument",
+	http.StatusUnauthorized:        "unauthenticated",
+	http.StatusForbidden:           "permission_denied",
+	http.StatusNotFound:            "not_found",
+	http.StatusConflict:            "aborted",
+	http.StatusPreconditionFailed:  "failed_precondition",
+	http.StatusUnprocessableEntity: "invalid_argument",
+	http.StatusTooManyRequests:     "resource_exhausted",
+	499:                            "canceled",
+	http.StatusNotImplemented:      "unimplemented",
+	http.StatusServiceUnavailable:  "unavailable",
+	http.StatusGatewayTimeout:      "deadline_exceeded",
+}
+
+// connectStatus maps RPC error codes to the HTTP status codes used by the
+// Connect protocol.
+var connectStatus = map[string]int{
+	"canceled":            499,
+	"unknown":             http.StatusInternalServerError,
+	"invalid_argument":    http.StatusBadRequest,
+	"deadline_exceeded":   http.StatusGatewayTimeout,
+	"not_found":           http.StatusNotFound,
+	"already_exists":      http.StatusConflict,
+	"permission_denied":   http.StatusForbidden,
+	"resource_exhausted":  http.StatusTooManyRequests,
+	"failed_precondition": http.StatusBadRequest,
+	"aborted":             http.StatusConflict,
+	"unimplemented":       http.StatusNotImplemented,
+	"internal":            http.StatusInternalServerError,
+	"unavailable":         http.StatusServiceUnavailable,
+	"unauthenticated":     http.StatusUnauthorized,
+}
+
+// rpcError is an error returned by a method.
+type rpcError struct {
+	Code    string `json:"code"`
+	Message string `json:"message,omitempty"`
+}
+
+func newError(code, format string, args ...any) *rpcError {
+	return &rpcError{Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+type bridge struct {
+	api    huma.API
+	config Config
+}
+
+// Expose adds Connect (and optionally gRPC) endpoints for the selected
+// operations at `/{Service}/{Method}` and serves the generated `.proto` file.
+func Expose(api huma.API, config Config) {
+	if config.Service == "" {
+		config.Service = "api.v1.APIService"
+	}
+	if config.ProtoPath == "" {
+		config.ProtoPath = "/" + config.Service + ".proto"
+	}
+	b := &bridge{api: api, config: config}
+
+	builder := newProtoBuilder(api.OpenAPI().Components.Schemas)
+	methods := []*protoMethod{}
+	for _, path := range sortedPaths(api.OpenAPI().Paths) {
+		item := api.OpenAPI().Paths[path]
+		for _, op := range []*huma.Operation{item.Get, item.Put, item.Post, item.Delete, item.Patch} {
+			if op == nil || op.OperationID == "" || (config.Filter != nil && !config.Filter(op)) {
+				continue
+			}
+			m := builder.method(op)
+			methods = append(methods, m)
+			api.Adapter().Handle(&huma.Operation{
+				Method: http.MethodPost,
+				Path:   "/" + config.Service + "/" + m.name,
+			}, func(ctx huma.Context) {
+				b.handle(ctx, m)
+			})
+		}
+	}
+
+	pkg, service := "", config.Service
+	if i := strings.LastIndex(service, "."); i != -1 {
+		pkg, service = service[:i], service[i+1:]
+	}
+	proto := protoFile(pkg, service, methods, builder.order)
+	api.Adapter().Handle(&huma.Operation{Method: http.MethodGet, Path: config.ProtoPath}, func(ctx huma.Context) {
+		ctx.SetHeader("Content-Type", "text/plain; charset=utf-8")
+		ctx.BodyWriter().Write([]byte(proto))
+	})
+}
+
+func sortedPaths(paths map[string]*huma.PathItem) []string {
+	keys := make([]string, 0, len(paths))
+	for k := range paths {
+		keys = append(keys, k)
+	}
+	// Sort for a stable order of methods in the generated file.
+	sort.Strings(keys)
+	return keys
+}
+
+// handle handles a call to a method using the protocol given by the request
+// content type.
+func (b *bridge) handle(ctx huma.Context, m *protoMethod) {
+	contentType := ctx.Header("Content-Type")
+	if i := strings.IndexByte(contentType, ';'); i != -1 {
+		contentType = contentType[:i]
+	}
+	contentType = strings.TrimSpace(strings.ToLower(contentType))
+
+	switch contentType {
+	case "application/proto", "application/json":
+		b.handleConnect(ctx, m, contentType == "application/json")
+	case "application/grpc", "application/grpc+proto":
+		if b.config.GRPC {
+			b.handleGRPC(ctx, m)
+			return
+		}
+		fallthrough
+	default:
+		ctx.SetStatus(http.StatusUnsupportedMediaType)
+	}
+}
+
+func (b *bridge) handleConnect(ctx huma.Context, m *protoMethod, isJSON bool) {
+	writeError := func(err *rpcError) {
+		ctx.SetHeader("Content-Type", "application/json")
+		ctx.SetStatus(connectStatus[err.Code])
+		json.NewEncoder(ctx.BodyWriter()).Encode(err)
+	}
+
+	if enc := ctx.Header("Content-Encoding"); enc != "" && enc != "identity" {
+		writeError(newError("unimplemented", "unsupported compression %s", enc))
+		return
+	}
+
+	body, rerr := b.readBody(ctx)
+	if rerr != nil {
+		writeError(rerr)
+		return
+	}
+
+	input, rerr := decodeMessage(m.request, body, isJSON)
+	if rerr != nil {
+		writeError(rerr)
+		return
+	}
+
+	output, rerr := b.call(ctx, m, input)
+	if rerr != nil {
+		writeError(rerr)
+		return
+	}
+
+	encoded, rerr := encodeMessage(m.response, output, isJSON)
+	if rerr != nil {
+		writeError(rerr)
+		return
+	}
+	if isJSON {
+		ctx.SetHeader("Content-Type", "application/json")
+	} else {
+		ctx.SetHeader("Content-Type", "application/proto")
+	}
+	ctx.SetStatus(http.StatusOK)
+	ctx.BodyWriter().Write(encoded)
+}
+
+func (b *bridge) handleGRPC(ctx huma.Context, m *protoMethod) {
+	ctx.SetHeader("Content-Type", "application/grpc+proto")
+	ctx.SetHeader("Trailer", "Grpc-Status, Grpc-Message")
+	ctx.SetStatus(http.StatusOK)
+
+	writeStatus := func(err *rpcError) {
+		if err == nil {
+			ctx.SetHeader("Grpc-Status", "0")
+			return
+		}
+		ctx.SetHeader("Grpc-Status", fmt.Sprint(codes[err.Code]))
+		ctx.SetHeader("Grpc-Message", url.PathEscape(err.Message))
+	}
+
+	body, rerr := b.readBody(ctx)
+	if rerr != nil {
+		writeStatus(rerr)
+		return
+	}
+	if len(body) < 5 || int(binary.BigEndian.Uint32(body[1:5])) != len(body)-5 {
+		writeStatus(newError("invalid_argument", "request must be a single length-prefixed message"))
+		return
+	}
+	if body[0] != 0 {
+		writeStatus(newError("unimplemented", "compressed messages are not supported"))
+		return
+	}
+
+	input, rerr := decodeMessage(m.request, body[5:], false)
+	if rerr != nil {
+		writeStatus(rerr)
+		return
+	}
+
+	output, rerr := b.call(ctx, m, input)
+	if rerr != nil {
+		writeStatus(rerr)
+		return
+	}
+
+	encoded, rerr := encodeMessage(m.response, output, false)
+	if rerr != nil {
+		writeStatus(rerr)
+		return
+	}
+	prefix := make([]byte, 5)
+	binary.BigEndian.PutUint32(prefix[1:], uint32(len(encoded)))
+	w := ctx.BodyWriter()
+	w.Write(prefix)
+	w.Write(encoded)
+	writeStatus(nil)
+}
+
+// readBody reads the request body, limited to the API's maximum body size.
+func (b *bridge) readBody(ctx huma.Context) ([]byte, *rpcError) {
+	body, err := io.ReadAll(huma.LimitBody(b.api, ctx))
+	if err != nil {
+		var mbe *http.MaxBytesError
+		if errors.As(err, &mbe) {
+			return nil, newError("resource_exhausted", "request body is too large limit=%d bytes", mbe.Limit)
+		}
+		return nil, newError("invalid_argument", "unable to read request: %v", err)
+	}
+	return body, nil
+}
+
+// decodeMessage decodes a request message into a JSON value keyed by
+// property names.
+func decodeMessage(m *protoMessage, body []byte, isJSON bool) (map[string]any, *rpcError) {
+	if !isJSON {
+		v, err := unmarshal(m, body)
+		if err != nil {
+			return nil, newError("invalid_argument", "invalid message: %v", err)
+		}
+		return v, nil
+	}
+
+	var v map[string]any
+	if len(bytes.TrimSpace(body)) > 0 {
+		dec := json.NewDecoder(bytes.NewReader(body))
+		dec.UseNumber()
+		if err := dec.Decode(&v); err != nil {
+			return nil, newError("invalid_argument", "invalid message: %v", err)
+		}
+	}
+	converted, err := fromJSON(m, v)
+	if err != nil {
+		return nil, newError("invalid_argument", "invalid message: %v", err)
+	}
+	return converted, nil
+}
+
+// encodeMessage encodes a response body as a message.
+func encodeMessage(m *protoMessage, body any, isJSON bool) ([]byte, *rpcError) {
+	v, ok := body.(map[string]any)
+	if m.wrapper {
+		v, ok = map[string]any{"value": body}, true
+	}
+	if !ok {
+		v = map[string]any{}
+	}
+
+	var encoded []byte
+	var err error
+	if isJSON {
+		var converted map[string]any
+		if converted, err = toJSON(m, v); err == nil {
+			encoded, err = json.Marshal(converted)
+		}
+	} else {
+		encoded, err = marshal(m, v)
+	}
+	if err != nil {
+		return nil, newError("internal", "unable to encode response: %v", err)
+	}
+	return encoded, nil
+}
+
+// call calls the operation for a method, returning the decoded response
+// body or an error converted from the operation's error response.
+func (b *bridge) call(ctx huma.Context, m *protoMethod, input map[string]any) (any, *rpcError) {
+	req, err := newRequest(ctx, m.op, input)
+	if err != nil {
+		return nil, newError("invalid_argument", "%v", err)
+	}
+	rec := httptest.NewRecorder()
+	huma.OperationHandler(b.api, m.op.OperationID).ServeHTTP(rec, req)
+
+	var result any
+	if rec.Body.Len() > 0 {
+		dec := json.NewDecoder(rec.Body)
+		dec.UseNumber()
+		if err := dec.Decode(&result); err != nil {
+			return nil, newError("internal", "invalid response: %v", err)
+		}
+	}
+
+	if rec.Code >= http.StatusBadRequest {
+		code := statusCodes[rec.Code]
+		if code == "" {
+			code = "unknown"
+			if rec.Code >= http.StatusInternalServerError {
+				code = "internal"
+			}
+		}
+		message := http.StatusText(rec.Code)
+		if m, ok := result.(map[string]any); ok {
+			if detail, ok := m["detail"].(string); ok && detail != "" {
+				message = detail
+			}
+		}
+		return nil, &rpcError{Code: code, Message: message}
+	}
+	return result, nil
+}
+
+// newRequest creates an internal request for an operation from the decoded
+// request message, forwarding the headers of the RPC request, e.g. for
+// authentication.
+func newRequest(ctx huma.Context, op *huma.Operation, input map[string]any) (*http.Request, error) {
+	path := op.Path
+	query := url.Values{}
+	headers := http.Header{}
+	for _, p := range op.Parameters {
+		v, ok := input[p.Name]
+		if !ok || v == nil {
+			continue
+		}
+		value := formatParam(v)
+		switch p.In {
+		case "path":
+			path = strings.ReplaceAll(path, "{"+p.Name+"}", url.PathEscape(value))
+		case "query":
+			query.Set(p.Name, value)
+		case "header":
+			headers.Set(p.Name, value)
+		}
+	}
+	if strings.Contains(path, "{") {
+		return nil, fmt.Errorf("missing required path params")
+	}
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+
+	var body io.Reader
+	if v, ok := input["body"]; ok && op.RequestBody != nil {
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		body = bytes.NewReader(encoded)
+	}
+
+	req, err := http.NewRequestWithContext(ctx.Context(), op.Method, path, body)
+	if err != nil {
+		return nil, err
+	}
+	ctx.EachHeader(func(name, value string) {
+		switch http.CanonicalHeaderKey(name) {
+		case "Accept", "Accept-Encoding", "Content-Encoding", "Content-Length", "Content-Type", "Te", "Trailer":
+			return
+		}
+		if strings.HasPrefix(strings.ToLower(name), "grpc-") || strings.HasPrefix(strings.ToLower(name), "connect-") {
+			return
+		}
+		req.Header.Add(name, value)
+	})
+	for name, values := range headers {
+		req.Header[name] = values
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Host = ctx.Host()
+	req.RemoteAddr = huma.RemoteAddr(ctx)
+	return req, nil
+}
+
+// formatParam formats a decoded field value as a parameter string, joining
+// lists with commas as Huma expects.
+func formatParam(v any) string {
+	if list, ok := v.([]any); ok {
+		parts := make([]string, len(list))
+		for i, item := range list {
+			parts[i] = formatParam(item)
+		}
+		return strings.Join(parts, ",")
+	}
+	return fmt.Sprint(v)
+}
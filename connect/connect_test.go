@@ -0,0 +1,356 @@
+package connect
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/humatest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type Thing struct {
+	ID     string            `json:"id"`
+	Count  int               `json:"count"`
+	Ratio  float64           `json:"ratio,omitempty"`
+	Active bool              `json:"active,omitempty"`
+	Tags   []string          `json:"tags,omitempty"`
+	Labels map[string]string `json:"labels,omitempty"`
+	Extra  any               `json:"extra,omitempty"`
+}
+
+func setup(t *testing.T) (humatest.TestAPI, *protoMethod) {
+	_, api := humatest.New(t)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "get-thing",
+		Method:      http.MethodGet,
+		Path:        "/things/{thing-id}",
+		Summary:     "Get a thing",
+	}, func(ctx context.Context, input *struct {
+		ThingID string `path:"thing-id"`
+		Auth    string `header:"Authorization"`
+	}) (*struct{ Body *Thing }, error) {
+		if input.ThingID == "missing" {
+			return nil, huma.Error404NotFound("thing not found")
+		}
+		return &struct{ Body *Thing }{&Thing{
+			ID:     input.ThingID,
+			Count:  -5,
+			Ratio:  0.5,
+			Active: true,
+			Tags:   []string{"a", "b"},
+			Labels: map[string]string{"auth": input.Auth},
+			Extra:  []any{1.0, "two"},
+		}}, nil
+	})
+
+	huma.Register(api, huma.Operation{
+		OperationID: "create-thing",
+		Method:      http.MethodPost,
+		Path:        "/things",
+	}, func(ctx context.Context, input *struct {
+		DryRun bool `query:"dry-run"`
+		Body   struct {
+			ID    string `json:"id" minLength:"2"`
+			Count int    `json:"count"`
+		}
+	}) (*struct{ Body []string }, error) {
+		return &struct{ Body []string }{[]string{input.Body.ID, strings.Repeat("x", input.Body.Count)}}, nil
+	})
+
+	huma.Register(api, huma.Operation{
+		OperationID: "rest-only",
+		Method:      http.MethodGet,
+		Path:        "/rest-only",
+		Metadata:    map[string]any{"rest": true},
+	}, func(ctx context.Context, input *struct{}) (*struct{}, error) {
+		return nil, nil
+	})
+
+	huma.Register(api, huma.Operation{
+		OperationID: "get-new-thing",
+		Method:      http.MethodGet,
+		Path:        "/things/new",
+		Metadata:    map[string]any{"rest": true},
+	}, func(ctx context.Context, input *struct{}) (*struct{}, error) {
+		return nil, huma.Error409Conflict("not via the router")
+	})
+
+	Expose(api, Config{
+		Service: "things.v1.ThingService",
+		Filter: func(op *huma.Operation) bool {
+			return op.Metadata["rest"] == nil
+		},
+		GRPC: true,
+	})
+
+	b := newProtoBuilder(api.OpenAPI().Components.Schemas)
+	return api, b.method(api.OpenAPI().Paths["/things/{thing-id}"].Get)
+}
+
+func TestProto(t *testing.T) {
+	api, _ := setup(t)
+
+	resp := api.Get("/things.v1.ThingService.proto")
+	require.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, `syntax = "proto3";
+
+package things.v1;
+
+service ThingService {
+  rpc CreateThing(CreateThingRequest) returns (CreateThingResponse);
+  // Get a thing
+  rpc GetThing(GetThingRequest) returns (Thing);
+}
+
+message CreateThingRequest {
+  bool dry_run = 1;
+  CreateThingRequestBody body = 2;
+}
+
+message CreateThingRequestBody {
+  int64 count = 1;
+  string id = 2;
+}
+
+message CreateThingResponse {
+  repeated string value = 1;
+}
+
+message GetThingRequest {
+  string authorization = 1;
+  string thing_id = 2;
+}
+
+message Thing {
+  bool active = 1;
+  int64 count = 2;
+  // JSON encoded.
+  string extra = 3;
+  string id = 4;
+  map<string, string> labels = 5;
+  double ratio = 6;
+  repeated string tags = 7;
+}
+`, resp.Body.String())
+}
+
+func TestConnectJSON(t *testing.T) {
+	api, _ := setup(t)
+
+	resp := api.Post("/things.v1.ThingService/GetThing",
+		"Content-Type: application/json",
+		"Authorization: abc",
+		strings.NewReader(`{"thingId": "t1"}`))
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, "application/json", resp.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{
+		"id": "t1",
+		"count": -5,
+		"ratio": 0.5,
+		"active": true,
+		"tags": ["a", "b"],
+		"labels": {"auth": "abc"},
+		"extra": "[1,\"two\"]"
+	}`, resp.Body.String())
+
+	resp = api.Post("/things.v1.ThingService/CreateThing",
+		"Content-Type: application/json",
+		strings.NewReader(`{"dry_run": true, "body": {"id": "t2", "count": "3"}}`))
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, `{"value": ["t2", "xxx"]}`, resp.Body.String())
+
+	// Methods call their own operation, even when the router would match the
+	// request path to a different one.
+	resp = api.Post("/things.v1.ThingService/GetThing",
+		"Content-Type: application/json",
+		strings.NewReader(`{"thingId": "new"}`))
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Contains(t, resp.Body.String(), `"id":"new"`)
+
+	// Operations which are filtered out are not exposed.
+	resp = api.Post("/things.v1.ThingService/RestOnly", "Content-Type: application/json", strings.NewReader(`{}`))
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+}
+
+func TestConnectProto(t *testing.T) {
+	api, m := setup(t)
+
+	req, err := marshal(m.request, map[string]any{"thing-id": "t1"})
+	require.NoError(t, err)
+
+	resp := api.Post("/things.v1.ThingService/GetThing",
+		"Content-Type: application/proto",
+		bytes.NewReader(req))
+	require.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, "application/proto", resp.Header().Get("Content-Type"))
+
+	thing, err := unmarshal(m.response, resp.Body.Bytes())
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"id":     "t1",
+		"count":  int64(-5),
+		"ratio":  0.5,
+		"active": true,
+		"tags":   []any{"a", "b"},
+		"labels": map[string]any{"auth": ""},
+		"extra":  []any{1.0, "two"},
+	}, thing)
+}
+
+func TestConnectErrors(t *testing.T) {
+	api, _ := setup(t)
+
+	for _, tc := range []struct {
+		name    string
+		headers []any
+		body    string
+		status  int
+		code    string
+	}{
+		{"not found", nil, `{"thingId": "missing"}`, http.StatusNotFound, "not_found"},
+		{"invalid json", nil, `{"thingId": `, http.StatusBadRequest, "invalid_argument"},
+		{"invalid type", nil, `{"thingId": 5}`, http.StatusBadRequest, "invalid_argument"},
+		{"missing path", nil, `{}`, http.StatusBadRequest, "invalid_argument"},
+		{"compressed", []any{"Content-Encoding: gzip"}, `{}`, http.StatusNotImplemented, "unimplemented"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			args := append([]any{"Content-Type: application/json"}, tc.headers...)
+			args = append(args, strings.NewReader(tc.body))
+			resp := api.Post("/things.v1.ThingService/GetThing", args...)
+			assert.Equal(t, tc.status, resp.Code)
+			assert.Contains(t, resp.Body.String(), `"code":"`+tc.code+`"`)
+		})
+	}
+
+	// Validation errors from the operation.
+	resp := api.Post("/things.v1.ThingService/CreateThing",
+		"Content-Type: application/json",
+		strings.NewReader(`{"body": {"id": "a"}}`))
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+	assert.Contains(t, resp.Body.String(), `"code":"invalid_argument"`)
+
+	resp = api.Post("/things.v1.ThingService/GetThing", "Content-Type: text/plain", strings.NewReader(`{}`))
+	assert.Equal(t, http.StatusUnsupportedMediaType, resp.Code)
+}
+
+func grpcFrame(msg []byte) []byte {
+	frame := make([]byte, 5, 5+len(msg))
+	binary.BigEndian.PutUint32(frame[1:], uint32(len(msg)))
+	return append(frame, msg...)
+}
+
+func TestGRPC(t *testing.T) {
+	api, m := setup(t)
+
+	req, err := marshal(m.request, map[string]any{"thing-id": "t1"})
+	require.NoError(t, err)
+
+	resp := api.Post("/things.v1.ThingService/GetThing",
+		"Content-Type: application/grpc",
+		bytes.NewReader(grpcFrame(req)))
+	require.Equal(t, http.StatusOK, resp.Code)
+	result := resp.Result()
+	assert.Equal(t, "0", result.Trailer.Get("Grpc-Status"))
+
+	body := resp.Body.Bytes()
+	require.Greater(t, len(body), 5)
+	assert.Equal(t, len(body)-5, int(binary.BigEndian.Uint32(body[1:5])))
+	thing, err := unmarshal(m.response, body[5:])
+	require.NoError(t, err)
+	assert.Equal(t, "t1", thing["id"])
+
+	req, _ = marshal(m.request, map[string]any{"thing-id": "missing"})
+	resp = api.Post("/things.v1.ThingService/GetThing",
+		"Content-Type: application/grpc+proto",
+		bytes.NewReader(grpcFrame(req)))
+	result = resp.Result()
+	assert.Equal(t, "5", result.Trailer.Get("Grpc-Status"))
+	assert.Equal(t, "thing%20not%20found", result.Trailer.Get("Grpc-Message"))
+
+	resp = api.Post("/things.v1.ThingService/GetThing",
+		"Content-Type: application/grpc",
+		bytes.NewReader([]byte{0, 0, 0}))
+	assert.Equal(t, "3", resp.Result().Trailer.Get("Grpc-Status"))
+}
+
+func TestWire(t *testing.T) {
+	inner := &protoMessage{name: "Inner", fields: []*protoField{
+		{name: "name", prop: "name", number: 1, kind: kindString},
+	}}
+	m := &protoMessage{name: "Test", fields: []*protoField{
+		{name: "i32", prop: "i32", number: 1, kind: kindInt32},
+		{name: "f", prop: "f", number: 2, kind: kindFloat},
+		{name: "data", prop: "data", number: 3, kind: kindBytes},
+		{name: "nums", prop: "nums", number: 4, kind: kindInt64, repeated: true},
+		{name: "inner", prop: "inner", number: 5, kind: kindMessage, message: inner},
+		{name: "inners", prop: "inners", number: 6, kind: kindMessage, message: inner, repeated: true},
+		{name: "counts", prop: "counts", number: 7, kind: kindMap, value: &protoField{prop: "value", number: 2, kind: kindInt64}},
+	}}
+
+	value := map[string]any{
+		"i32":    int64(-1),
+		"f":      1.5,
+		"data":   "aGVsbG8=",
+		"nums":   []any{int64(1), int64(300)},
+		"inner":  map[string]any{"name": "a"},
+		"inners": []any{map[string]any{"name": "b"}, map[string]any{"name": "c"}},
+		"counts": map[string]any{"x": int64(1)},
+	}
+	b, err := marshal(m, value)
+	require.NoError(t, err)
+
+	// Packed encoding of `nums`: tag, length, 1, 300.
+	assert.True(t, bytes.Contains(b, []byte{4<<3 | wireLen, 3, 1, 0xac, 0x02}))
+
+	decoded, err := unmarshal(m, b)
+	require.NoError(t, err)
+	assert.Equal(t, value, decoded)
+
+	// Unpacked repeated values are also accepted.
+	decoded, err = unmarshal(m, []byte{4 << 3, 1, 4 << 3, 2})
+	require.NoError(t, err)
+	assert.Equal(t, []any{int64(1), int64(2)}, decoded["nums"])
+
+	_, err = unmarshal(m, []byte{3<<3 | wireLen, 10, 1})
+	assert.Error(t, err)
+
+	_, err = marshal(m, map[string]any{"i32": "nope"})
+	assert.Error(t, err)
+}
+
+func TestTooLarge(t *testing.T) {
+	config := huma.DefaultConfig("Test API", "1.0.0")
+	config.MaxBodyBytes = 16
+	_, api := humatest.New(t, config)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "echo",
+		Method:      http.MethodPost,
+		Path:        "/echo",
+	}, func(ctx context.Context, input *struct {
+		Body struct {
+			Message string `json:"message"`
+		}
+	}) (*struct{}, error) {
+		return nil, nil
+	})
+	Expose(api, Config{GRPC: true})
+
+	resp := api.Post("/api.v1.APIService/Echo",
+		"Content-Type: application/json",
+		strings.NewReader(`{"body": {"message": "`+strings.Repeat("a", 32)+`"}}`))
+	assert.Equal(t, http.StatusTooManyRequests, resp.Code)
+	assert.Contains(t, resp.Body.String(), `"code":"resource_exhausted"`)
+
+	resp = api.Post("/api.v1.APIService/Echo",
+		"Content-Type: application/grpc",
+		bytes.NewReader(grpcFrame(bytes.Repeat([]byte{0}, 32))))
+	assert.Equal(t, "8", resp.Result().Trailer.Get("Grpc-Status"))
+}
@@ -0,0 +1,346 @@
+package connect
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/danielgtaylor/casing"
+	"github.com/danielgtaylor/huma/v2"
+)
+
+var validIdent = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Scalar kinds of protobuf fields. Values which cannot be represented in
+// protobuf, like free-form objects or nested arrays, use `kindJSON`, which is
+// a string field holding JSON.
+const (
+	kindString  = "string"
+	kindBytes   = "bytes"
+	kindBool    = "bool"
+	kindInt32   = "int32"
+	kindInt64   = "int64"
+	kindFloat   = "float"
+	kindDouble  = "double"
+	kindMessage = "message"
+	kindMap     = "map"
+	kindJSON    = "json"
+)
+
+// protoField describes a field of a protobuf message.
+type protoField struct {
+	// name is the proto field name and jsonName the name used by the
+	// protobuf JSON mapping. prop is the name of the JSON property or
+	// parameter the field is converted to for the operation.
+	name     string
+	jsonName string
+	prop     string
+	number   int
+	kind     string
+	repeated bool
+	message  *protoMessage
+	value    *protoField // value of map fields, keys are always strings
+}
+
+// typeName returns the field type as written in a `.proto` file.
+func (f *protoField) typeName() string {
+	switch f.kind {
+	case kindMessage:
+		return f.message.name
+	case kindMap:
+		return "map<string, " + f.value.typeName() + ">"
+	case kindJSON:
+		return "string"
+	}
+	return f.kind
+}
+
+// protoMessage describes a protobuf message.
+type protoMessage struct {
+	name   string
+	fields []*protoField
+
+	// wrapper is set for response messages wrapping a body which is not an
+	// object, with the body in a single `value` field.
+	wrapper bool
+}
+
+func (m *protoMessage) field(number int) *protoField {
+	for _, f := range m.fields {
+		if f.number == number {
+			return f
+		}
+	}
+	return nil
+}
+
+// protoMethod is an RPC method backed by an operation.
+type protoMethod struct {
+	name     string
+	op       *huma.Operation
+	request  *protoMessage
+	response *protoMessage
+}
+
+// protoBuilder generates protobuf messages from the OpenAPI schemas.
+type protoBuilder struct {
+	registry huma.Registry
+	messages map[string]*protoMessage
+	order    []*protoMessage
+}
+
+func newProtoBuilder(registry huma.Registry) *protoBuilder {
+	return &protoBuilder{registry: registry, messages: map[string]*protoMessage{}}
+}
+
+func (b *protoBuilder) add(m *protoMessage) *protoMessage {
+	b.messages[m.name] = m
+	b.order = append(b.order, m)
+	return m
+}
+
+// method creates the method for an operation. The request message has a
+// field for each parameter and a `body` field for the request body. The
+// response message is the response body's named schema, or a wrapper.
+func (b *protoBuilder) method(op *huma.Operation) *protoMethod {
+	name := casing.Camel(op.OperationID)
+	m := &protoMethod{name: name, op: op}
+
+	m.request = b.add(&protoMessage{name: b.uniqueName(name, "Request")})
+	params := append([]*huma.Param{}, op.Parameters...)
+	sort.Slice(params, func(i, j int) bool { return params[i].Name < params[j].Name })
+	for _, p := range params {
+		if p.In == "cookie" {
+			continue
+		}
+		if f := b.field(m.request, p.Name, p.Schema); f != nil {
+			m.request.fields = append(m.request.fields, f)
+		}
+	}
+	if op.RequestBody != nil {
+		for ct, mt := range op.RequestBody.Content {
+			if strings.Contains(ct, "json") && mt.Schema != nil {
+				if f := b.field(m.request, "body", mt.Schema); f != nil {
+					m.request.fields = append(m.request.fields, f)
+				}
+				break
+			}
+		}
+	}
+	numberFields(m.request)
+
+	s := responseSchema(op)
+	if s != nil && s.Ref != "" {
+		if msg := b.message(s.Ref); msg != nil {
+			m.response = msg
+			return m
+		}
+	}
+	m.response = b.add(&protoMessage{name: b.uniqueName(name, "Response"), wrapper: true})
+	if s != nil {
+		if f := b.field(m.response, "value", s); f != nil {
+			m.response.fields = append(m.response.fields, f)
+		}
+	}
+	numberFields(m.response)
+	return m
+}
+
+// uniqueName returns a message name which doesn't conflict with the name of
+// a registered schema, e.g. a request body named after the operation.
+func (b *protoBuilder) uniqueName(method, suffix string) string {
+	if _, ok := b.registry.Map()[method+suffix]; ok {
+		return method + "RPC" + suffix
+	}
+	return method + suffix
+}
+
+// numberFields assigns field numbers in order.
+func numberFields(m *protoMessage) {
+	for i, f := range m.fields {
+		f.number = i + 1
+	}
+}
+
+// message returns the message for a named object schema, or nil if the
+// schema is not an object.
+func (b *protoBuilder) message(ref string) *protoMessage {
+	s := b.registry.SchemaFromRef(ref)
+	if s == nil || s.Type != huma.TypeObject || len(s.Properties) == 0 {
+		return nil
+	}
+	name := ref[strings.LastIndex(ref, "/")+1:]
+	if !validIdent.MatchString(name) {
+		return nil
+	}
+	if m := b.messages[name]; m != nil {
+		return m
+	}
+	m := b.add(&protoMessage{name: name})
+	b.properties(m, s)
+	return m
+}
+
+// properties adds a field for each property of an object schema, sorted by
+// name so field numbers are stable.
+func (b *protoBuilder) properties(m *protoMessage, s *huma.Schema) {
+	props := make([]string, 0, len(s.Properties))
+	for prop := range s.Properties {
+		props = append(props, prop)
+	}
+	sort.Strings(props)
+	for _, prop := range props {
+		if f := b.field(m, prop, s.Properties[prop]); f != nil {
+			m.fields = append(m.fields, f)
+		}
+	}
+	numberFields(m)
+}
+
+// field creates a field of the parent message for a property schema. It
+// returns nil if the property name is not a valid protobuf identifier.
+func (b *protoBuilder) field(parent *protoMessage, prop string, s *huma.Schema) *protoField {
+	name := casing.Snake(prop)
+	if !validIdent.MatchString(name) {
+		return nil
+	}
+	f := &protoField{name: name, jsonName: casing.LowerCamel(name), prop: prop}
+	if s != nil && s.Type == huma.TypeArray {
+		f.repeated = true
+		s = s.Items
+		if s != nil && (s.Type == huma.TypeArray || b.resolve(s).Type == huma.TypeArray) {
+			// Nested lists can't be represented.
+			f.repeated = false
+			f.kind = kindJSON
+			return f
+		}
+	}
+	b.setType(parent, f, s)
+	if f.repeated && f.kind == kindMap {
+		// Lists of maps can't be represented.
+		f.repeated = false
+		f.kind = kindJSON
+		f.value = nil
+	}
+	return f
+}
+
+func (b *protoBuilder) resolve(s *huma.Schema) *huma.Schema {
+	if s != nil && s.Ref != "" {
+		if r := b.registry.SchemaFromRef(s.Ref); r != nil {
+			return r
+		}
+	}
+	if s == nil {
+		return &huma.Schema{}
+	}
+	return s
+}
+
+// setType sets the kind of a field from its schema.
+func (b *protoBuilder) setType(parent *protoMessage, f *protoField, s *huma.Schema) {
+	f.kind = kindJSON
+	if s == nil {
+		return
+	}
+	if s.Ref != "" {
+		if m := b.message(s.Ref); m != nil {
+			f.kind = kindMessage
+			f.message = m
+			return
+		}
+		s = b.resolve(s)
+	}
+	switch s.Type {
+	case huma.TypeString:
+		f.kind = kindString
+		if s.ContentEncoding == "base64" {
+			f.kind = kindBytes
+		}
+	case huma.TypeBoolean:
+		f.kind = kindBool
+	case huma.TypeInteger:
+		f.kind = kindInt64
+		if s.Format == "int32" {
+			f.kind = kindInt32
+		}
+	case huma.TypeNumber:
+		f.kind = kindDouble
+		if s.Format == "float" {
+			f.kind = kindFloat
+		}
+	case huma.TypeObject:
+		if len(s.Properties) > 0 {
+			m := b.add(&protoMessage{name: parent.name + casing.Camel(f.name)})
+			b.properties(m, s)
+			f.kind = kindMessage
+			f.message = m
+		} else if ap, ok := s.AdditionalProperties.(*huma.Schema); ok && ap != nil {
+			value := &protoField{name: "value", jsonName: "value", prop: "value", number: 2}
+			b.setType(parent, value, ap)
+			if value.kind != kindJSON {
+				f.kind = kindMap
+				f.value = value
+			}
+		}
+	}
+}
+
+// responseSchema returns the JSON schema of the operation's first successful
+// response, if any.
+func responseSchema(op *huma.Operation) *huma.Schema {
+	codes := make([]string, 0, len(op.Responses))
+	for code := range op.Responses {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	for _, code := range codes {
+		if !strings.HasPrefix(code, "2") {
+			continue
+		}
+		for ct, mt := range op.Responses[code].Content {
+			if strings.Contains(ct, "json") && mt.Schema != nil {
+				return mt.Schema
+			}
+		}
+	}
+	return nil
+}
+
+// protoFile returns the `.proto` file describing the service.
+func protoFile(pkg, service string, methods []*protoMethod, messages []*protoMessage) string {
+	var sb strings.Builder
+	sb.WriteString("syntax = \"proto3\";\n\n")
+	if pkg != "" {
+		sb.WriteString("package " + pkg + ";\n\n")
+	}
+	sb.WriteString("service " + service + " {\n")
+	for _, m := range methods {
+		if m.op.Summary != "" {
+			sb.WriteString("  // " + m.op.Summary + "\n")
+		}
+		if m.op.Deprecated {
+			sb.WriteString(fmt.Sprintf("  rpc %s(%s) returns (%s) {\n    option deprecated = true;\n  }\n", m.name, m.request.name, m.response.name))
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("  rpc %s(%s) returns (%s);\n", m.name, m.request.name, m.response.name))
+	}
+	sb.WriteString("}\n")
+
+	for _, m := range messages {
+		sb.WriteString("\nmessage " + m.name + " {\n")
+		for _, f := range m.fields {
+			if f.kind == kindJSON {
+				sb.WriteString("  // JSON encoded.\n")
+			}
+			label := ""
+			if f.repeated {
+				label = "repeated "
+			}
+			sb.WriteString(fmt.Sprintf("  %s%s %s = %d;\n", label, f.typeName(), f.name, f.number))
+		}
+		sb.WriteString("}\n")
+	}
+	return sb.String()
+}
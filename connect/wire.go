@@ -0,0 +1,481 @@
+package connect
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// Protobuf wire types.
+const (
+	wireVarint = 0
+	wireI64    = 1
+	wireLen    = 2
+	wireI32    = 5
+)
+
+var errTruncated = errors.New("truncated message")
+
+func appendVarint(b []byte, v uint64) []byte {
+	return binary.AppendUvarint(b, v)
+}
+
+func appendTag(b []byte, number, wireType int) []byte {
+	return appendVarint(b, uint64(number)<<3|uint64(wireType))
+}
+
+// marshal encodes a JSON value, keyed by property names, as a protobuf
+// message.
+func marshal(m *protoMessage, v map[string]any) ([]byte, error) {
+	b := []byte{}
+	for _, f := range m.fields {
+		value, ok := v[f.prop]
+		if !ok || value == nil {
+			continue
+		}
+		var err error
+		if b, err = appendField(b, f, value); err != nil {
+			return nil, fmt.Errorf("%s: %w", f.prop, err)
+		}
+	}
+	return b, nil
+}
+
+func appendField(b []byte, f *protoField, v any) ([]byte, error) {
+	if f.repeated {
+		list, ok := v.([]any)
+		if !ok {
+			return nil, fmt.Errorf("expected array but got %T", v)
+		}
+		if isPackable(f.kind) {
+			packed := []byte{}
+			for _, item := range list {
+				var err error
+				if packed, _, err = appendScalar(packed, f.kind, item); err != nil {
+					return nil, err
+				}
+			}
+			b = appendTag(b, f.number, wireLen)
+			b = appendVarint(b, uint64(len(packed)))
+			return append(b, packed...), nil
+		}
+		for _, item := range list {
+			var err error
+			if b, err = appendValue(b, f, item); err != nil {
+				return nil, err
+			}
+		}
+		return b, nil
+	}
+
+	if f.kind == kindMap {
+		obj, ok := v.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("expected object but got %T", v)
+		}
+		for key, value := range obj {
+			entry := appendTag(nil, 1, wireLen)
+			entry = appendVarint(entry, uint64(len(key)))
+			entry = append(entry, key...)
+			if value != nil {
+				var err error
+				if entry, err = appendValue(entry, f.value, value); err != nil {
+					return nil, err
+				}
+			}
+			b = appendTag(b, f.number, wireLen)
+			b = appendVarint(b, uint64(len(entry)))
+			b = append(b, entry...)
+		}
+		return b, nil
+	}
+
+	return appendValue(b, f, v)
+}
+
+// appendValue appends a single tagged value of a field.
+func appendValue(b []byte, f *protoField, v any) ([]byte, error) {
+	switch f.kind {
+	case kindMessage:
+		obj, ok := v.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("expected object but got %T", v)
+		}
+		encoded, err := marshal(f.message, obj)
+		if err != nil {
+			return nil, err
+		}
+		b = appendTag(b, f.number, wireLen)
+		b = appendVarint(b, uint64(len(encoded)))
+		return append(b, encoded...), nil
+	case kindJSON:
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		b = appendTag(b, f.number, wireLen)
+		b = appendVarint(b, uint64(len(encoded)))
+		return append(b, encoded...), nil
+	}
+
+	value, wireType, err := appendScalar(nil, f.kind, v)
+	if err != nil {
+		return nil, err
+	}
+	b = appendTag(b, f.number, wireType)
+	return append(b, value...), nil
+}
+
+func isPackable(kind string) bool {
+	switch kind {
+	case kindBool, kindInt32, kindInt64, kindFloat, kindDouble:
+		return true
+	}
+	return false
+}
+
+// appendScalar appends a scalar value without its tag and returns its wire
+// type. Strings and bytes include their length prefix.
+func appendScalar(b []byte, kind string, v any) ([]byte, int, error) {
+	switch kind {
+	case kindString, kindBytes:
+		s, ok := v.(string)
+		if !ok {
+			return nil, 0, fmt.Errorf("expected string but got %T", v)
+		}
+		data := []byte(s)
+		if kind == kindBytes {
+			var err error
+			if data, err = base64.StdEncoding.DecodeString(s); err != nil {
+				return nil, 0, err
+			}
+		}
+		b = appendVarint(b, uint64(len(data)))
+		return append(b, data...), wireLen, nil
+	case kindBool:
+		bv, ok := v.(bool)
+		if !ok {
+			return nil, 0, fmt.Errorf("expected boolean but got %T", v)
+		}
+		if bv {
+			return append(b, 1), wireVarint, nil
+		}
+		return append(b, 0), wireVarint, nil
+	case kindInt32, kindInt64:
+		i, err := toInt(v)
+		if err != nil {
+			return nil, 0, err
+		}
+		return appendVarint(b, uint64(i)), wireVarint, nil
+	case kindFloat:
+		n, err := toFloat(v)
+		if err != nil {
+			return nil, 0, err
+		}
+		return binary.LittleEndian.AppendUint32(b, math.Float32bits(float32(n))), wireI32, nil
+	case kindDouble:
+		n, err := toFloat(v)
+		if err != nil {
+			return nil, 0, err
+		}
+		return binary.LittleEndian.AppendUint64(b, math.Float64bits(n)), wireI64, nil
+	}
+	return nil, 0, fmt.Errorf("unsupported type %s", kind)
+}
+
+func toInt(v any) (int64, error) {
+	switch n := v.(type) {
+	case int64:
+		return n, nil
+	case json.Number:
+		return n.Int64()
+	case string:
+		return strconv.ParseInt(n, 10, 64)
+	}
+	f, err := toFloat(v)
+	return int64(f), err
+}
+
+func toFloat(v any) (float64, error) {
+	switch n := v.(type) {
+	case float64:
+		return n, nil
+	case int64:
+		return float64(n), nil
+	case json.Number:
+		return n.Float64()
+	case string:
+		// The protobuf JSON mapping allows numbers as strings.
+		return strconv.ParseFloat(n, 64)
+	}
+	return 0, fmt.Errorf("expected number but got %T", v)
+}
+
+// unmarshal decodes a protobuf message into a JSON value keyed by property
+// names. Unknown fields are ignored.
+func unmarshal(m *protoMessage, b []byte) (map[string]any, error) {
+	out := map[string]any{}
+	for len(b) > 0 {
+		tag, n := binary.Uvarint(b)
+		if n <= 0 {
+			return nil, errTruncated
+		}
+		b = b[n:]
+		number, wireType := int(tag>>3), int(tag&7)
+
+		var raw []byte
+		var varint uint64
+		switch wireType {
+		case wireVarint:
+			varint, n = binary.Uvarint(b)
+			if n <= 0 {
+				return nil, errTruncated
+			}
+			b = b[n:]
+		case wireI64:
+			if len(b) < 8 {
+				return nil, errTruncated
+			}
+			raw, b = b[:8], b[8:]
+		case wireI32:
+			if len(b) < 4 {
+				return nil, errTruncated
+			}
+			raw, b = b[:4], b[4:]
+		case wireLen:
+			length, n := binary.Uvarint(b)
+			if n <= 0 || uint64(len(b)-n) < length {
+				return nil, errTruncated
+			}
+			raw, b = b[n:n+int(length)], b[n+int(length):]
+		default:
+			return nil, fmt.Errorf("unsupported wire type %d", wireType)
+		}
+
+		f := m.field(number)
+		if f == nil {
+			continue
+		}
+
+		if f.repeated && wireType == wireLen && isPackable(f.kind) {
+			list, _ := out[f.prop].([]any)
+			for len(raw) > 0 {
+				var value any
+				var err error
+				if value, raw, err = readPacked(f.kind, raw); err != nil {
+					return nil, fmt.Errorf("%s: %w", f.prop, err)
+				}
+				list = append(list, value)
+			}
+			out[f.prop] = list
+			continue
+		}
+
+		if f.kind == kindMap {
+			entry, err := unmarshal(&protoMessage{fields: []*protoField{
+				{prop: "key", number: 1, kind: kindString},
+				f.value,
+			}}, raw)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", f.prop, err)
+			}
+			obj, _ := out[f.prop].(map[string]any)
+			if obj == nil {
+				obj = map[string]any{}
+			}
+			key, _ := entry["key"].(string)
+			obj[key] = entry[f.value.prop]
+			out[f.prop] = obj
+			continue
+		}
+
+		value, err := readValue(f, varint, raw)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", f.prop, err)
+		}
+		if f.repeated {
+			list, _ := out[f.prop].([]any)
+			out[f.prop] = append(list, value)
+		} else {
+			out[f.prop] = value
+		}
+	}
+	return out, nil
+}
+
+// readPacked reads a single value from packed repeated field data.
+func readPacked(kind string, b []byte) (any, []byte, error) {
+	switch kind {
+	case kindFloat:
+		if len(b) < 4 {
+			return nil, nil, errTruncated
+		}
+		return float64(math.Float32frombits(binary.LittleEndian.Uint32(b))), b[4:], nil
+	case kindDouble:
+		if len(b) < 8 {
+			return nil, nil, errTruncated
+		}
+		return math.Float64frombits(binary.LittleEndian.Uint64(b)), b[8:], nil
+	}
+	v, n := binary.Uvarint(b)
+	if n <= 0 {
+		return nil, nil, errTruncated
+	}
+	value, err := readValue(&protoField{kind: kind}, v, nil)
+	return value, b[n:], err
+}
+
+// readValue converts a single decoded wire value to a JSON value.
+func readValue(f *protoField, varint uint64, raw []byte) (any, error) {
+	switch f.kind {
+	case kindString:
+		return string(raw), nil
+	case kindBytes:
+		return base64.StdEncoding.EncodeToString(raw), nil
+	case kindBool:
+		return varint != 0, nil
+	case kindInt32:
+		return int64(int32(varint)), nil
+	case kindInt64:
+		return int64(varint), nil
+	case kindFloat:
+		if len(raw) != 4 {
+			return nil, errTruncated
+		}
+		return float64(math.Float32frombits(binary.LittleEndian.Uint32(raw))), nil
+	case kindDouble:
+		if len(raw) != 8 {
+			return nil, errTruncated
+		}
+		return math.Float64frombits(binary.LittleEndian.Uint64(raw)), nil
+	case kindMessage:
+		return unmarshal(f.message, raw)
+	case kindJSON:
+		var v any
+		if err := json.Unmarshal(raw, &v); err != nil {
+			return nil, err
+		}
+		return v, nil
+	}
+	return nil, fmt.Errorf("unsupported type %s", f.kind)
+}
+
+// fromJSON converts a message in the protobuf JSON mapping, which uses
+// lower camel case field names, to a JSON value keyed by property names.
+func fromJSON(m *protoMessage, v map[string]any) (map[string]any, error) {
+	out := map[string]any{}
+	for _, f := range m.fields {
+		value, ok := v[f.jsonName]
+		if !ok {
+			value, ok = v[f.name]
+		}
+		if !ok || value == nil {
+			continue
+		}
+		converted, err := convertJSON(f, value, true)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", f.jsonName, err)
+		}
+		out[f.prop] = converted
+	}
+	return out, nil
+}
+
+// toJSON converts a JSON value keyed by property names to the protobuf JSON
+// mapping of a message.
+func toJSON(m *protoMessage, v map[string]any) (map[string]any, error) {
+	out := map[string]any{}
+	for _, f := range m.fields {
+		value, ok := v[f.prop]
+		if !ok || value == nil {
+			continue
+		}
+		converted, err := convertJSON(f, value, false)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", f.prop, err)
+		}
+		out[f.jsonName] = converted
+	}
+	return out, nil
+}
+
+// convertJSON converts a field value to or from the protobuf JSON mapping.
+// Only messages, JSON encoded fields, and numbers sent as strings differ,
+// but scalar types are checked.
+func convertJSON(f *protoField, v any, from bool) (any, error) {
+	if f.repeated {
+		list, ok := v.([]any)
+		if !ok {
+			return nil, fmt.Errorf("expected array but got %T", v)
+		}
+		out := make([]any, len(list))
+		single := *f
+		single.repeated = false
+		for i, item := range list {
+			converted, err := convertJSON(&single, item, from)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = converted
+		}
+		return out, nil
+	}
+
+	switch f.kind {
+	case kindMessage:
+		obj, ok := v.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("expected object but got %T", v)
+		}
+		if from {
+			return fromJSON(f.message, obj)
+		}
+		return toJSON(f.message, obj)
+	case kindMap:
+		obj, ok := v.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("expected object but got %T", v)
+		}
+		out := make(map[string]any, len(obj))
+		for key, value := range obj {
+			converted, err := convertJSON(f.value, value, from)
+			if err != nil {
+				return nil, err
+			}
+			out[key] = converted
+		}
+		return out, nil
+	case kindJSON:
+		if !from {
+			b, err := json.Marshal(v)
+			return string(b), err
+		}
+		s, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected JSON string but got %T", v)
+		}
+		var out any
+		err := json.Unmarshal([]byte(s), &out)
+		return out, err
+	case kindString, kindBytes:
+		if _, ok := v.(string); !ok {
+			return nil, fmt.Errorf("expected string but got %T", v)
+		}
+	case kindBool:
+		if _, ok := v.(bool); !ok {
+			return nil, fmt.Errorf("expected boolean but got %T", v)
+		}
+	case kindInt32, kindInt64, kindFloat, kindDouble:
+		if s, ok := v.(string); ok && from {
+			return json.Number(s), nil
+		}
+		if _, err := toFloat(v); err != nil {
+			return nil, err
+		}
+	}
+	return v, nil
+}
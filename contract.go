@@ -0,0 +1,201 @@
+package huma
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// responseValidationContext records the response status, headers, and body
+// as they are written so they can be validated once the handler returns.
+type responseValidationContext struct {
+	humaContext
+	status   int
+	headers  http.Header
+	body     bytes.Buffer
+	streamed bool
+}
+
+func (c *responseValidationContext) SetStatus(code int) {
+	c.status = code
+	c.humaContext.SetStatus(code)
+}
+
+func (c *responseValidationContext) SetHeader(name, value string) {
+	c.headers.Set(name, value)
+	c.humaContext.SetHeader(name, value)
+}
+
+func (c *responseValidationContext) AppendHeader(name, value string) {
+	c.headers.Add(name, value)
+	c.humaContext.AppendHeader(name, value)
+}
+
+func (c *responseValidationContext) BodyWriter() io.Writer {
+	if c.status == 0 {
+		c.status = http.StatusOK
+	}
+	return io.MultiWriter(c.humaContext.BodyWriter(), &c.body)
+}
+
+func (c *responseValidationContext) StreamBody(cb func(w io.Writer, flush func() error)) {
+	// Streamed bodies may be unbounded, so only their headers are validated.
+	c.streamed = true
+	if c.status == 0 {
+		c.status = http.StatusOK
+	}
+	streamBody(c.humaContext, cb)
+}
+
+func (c *responseValidationContext) Unwrap() Context {
+	return c.humaContext
+}
+
+// validateResponses wraps a handler to validate each response against the
+// operation's declared responses when enabled in the API's config.
+func validateResponses(api API, op *Operation, handler func(ctx Context)) func(ctx Context) {
+	config := configOf(api)
+	if !config.ValidateResponses {
+		return handler
+	}
+
+	onViolation := config.OnResponseViolation
+	if onViolation == nil {
+		onViolation = func(ctx Context, status int, errs []error) {
+			log.Printf("huma: %s %s response %d does not match the OpenAPI: %v", ctx.Method(), ctx.Operation().Path, status, errs)
+		}
+	}
+
+	return func(ctx Context) {
+		vctx := &responseValidationContext{humaContext: ctx, headers: http.Header{}}
+		handler(vctx)
+		if vctx.status == 0 {
+			// Nothing was written, e.g. the client went away.
+			return
+		}
+		if errs := checkResponse(api.OpenAPI(), op, vctx); len(errs) > 0 {
+			onViolation(ctx, vctx.status, errs)
+		}
+	}
+}
+
+// checkResponse validates a recorded response against the operation's
+// declared response for its status code.
+func checkResponse(oapi *OpenAPI, op *Operation, c *responseValidationContext) []error {
+	resp := op.Responses[strconv.Itoa(c.status)]
+	if resp == nil {
+		resp = op.Responses["default"]
+	}
+	if resp == nil {
+		return []error{&ErrorDetail{
+			Message: "undeclared response status code",
+			Value:   c.status,
+		}}
+	}
+
+	registry := oapi.Components.Schemas
+	pb := NewPathBuffer([]byte{}, 0)
+	res := &ValidateResult{}
+
+	for name, param := range resp.Headers {
+		values := c.headers.Values(name)
+		if len(values) == 0 {
+			if param.Required {
+				pb.Reset()
+				pb.Push("header")
+				pb.Push(name)
+				res.Add(pb, nil, "required header is missing")
+			}
+			continue
+		}
+		if param.Schema != nil {
+			pb.Reset()
+			pb.Push("header")
+			pb.Push(name)
+			validateHeader(registry, param.Schema, pb, values, res)
+		}
+	}
+
+	if c.streamed || (c.body.Len() == 0 && len(resp.Content) == 0) {
+		return res.Errors
+	}
+
+	ct := c.headers.Get("Content-Type")
+	mt, _, _ := mime.ParseMediaType(ct)
+	media := resp.Content[mt]
+	if media == nil {
+		pb.Reset()
+		pb.Push("header")
+		pb.Push("Content-Type")
+		res.Add(pb, ct, "undeclared response content type")
+		return res.Errors
+	}
+	if media.Schema == nil || !strings.HasSuffix(mt, "json") {
+		return res.Errors
+	}
+
+	pb.Reset()
+	pb.Push("body")
+	var body any
+	if err := json.Unmarshal(c.body.Bytes(), &body); err != nil {
+		res.Add(pb, nil, "invalid JSON: "+err.Error())
+		return res.Errors
+	}
+	Validate(registry, media.Schema, pb, ModeReadFromServer, body, res)
+	return res.Errors
+}
+
+// validateHeader validates response header values, converting them to the
+// type expected by the schema first.
+func validateHeader(registry Registry, s *Schema, pb *PathBuffer, values []string, res *ValidateResult) {
+	for s.Ref != "" {
+		s = registry.SchemaFromRef(s.Ref)
+	}
+
+	if s.Type == TypeArray && s.Items != nil {
+		items := make([]any, 0, len(values))
+		for i, value := range values {
+			pb.PushIndex(i)
+			if v, ok := parseHeaderValue(s.Items, value); ok {
+				items = append(items, v)
+			} else {
+				res.Add(pb, value, "expected "+s.Items.Type)
+			}
+			pb.Pop()
+		}
+		Validate(registry, s, pb, ModeReadFromServer, items, res)
+		return
+	}
+
+	if s.Format == "date-time" {
+		if _, err := http.ParseTime(values[0]); err == nil {
+			// Time headers are sent in the HTTP date format rather than RFC 3339.
+			return
+		}
+	}
+
+	if v, ok := parseHeaderValue(s, values[0]); ok {
+		Validate(registry, s, pb, ModeReadFromServer, v, res)
+	} else {
+		res.Add(pb, values[0], "expected "+s.Type)
+	}
+}
+
+// parseHeaderValue converts a header value to the type expected by the
+// schema.
+func parseHeaderValue(s *Schema, value string) (any, bool) {
+	switch s.Type {
+	case TypeBoolean:
+		b, err := strconv.ParseBool(value)
+		return b, err == nil
+	case TypeInteger, TypeNumber:
+		f, err := strconv.ParseFloat(value, 64)
+		return f, err == nil
+	}
+	return value, true
+}
@@ -0,0 +1,196 @@
+package huma
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CORSConfig configures Cross-Origin Resource Sharing (CORS), which allows
+// browsers to call the API from web pages served from other origins. Allowed
+// methods are derived from the registered operations for each path, and
+// preflight `OPTIONS` requests are handled automatically.
+type CORSConfig struct {
+	// AllowOrigins lists the origins which may make cross-origin requests,
+	// e.g. `https://example.com`. A single `*` wildcard may be used within an
+	// origin to match subdomains like `https://*.example.com`, or on its own
+	// to allow any origin.
+	AllowOrigins []string
+
+	// AllowHeaders lists the request headers which clients may send. If empty,
+	// any headers requested in the preflight are allowed.
+	AllowHeaders []string
+
+	// ExposeHeaders lists the response headers which client scripts may read
+	// beyond the CORS-safelisted response headers.
+	ExposeHeaders []string
+
+	// AllowCredentials allows requests to include credentials like cookies.
+	// The request's origin is echoed back rather than `*` when enabled, as
+	// required by the CORS spec. It cannot be combined with allowing any
+	// origin via `*`, as that would let any site make credentialed requests.
+	AllowCredentials bool
+
+	// MaxAge is how long browsers may cache preflight responses. If zero, the
+	// browser default is used.
+	MaxAge time.Duration
+}
+
+// allowOrigin returns whether the given request origin is allowed.
+func (c *CORSConfig) allowOrigin(origin string) bool {
+	for _, allowed := range c.AllowOrigins {
+		if allowed == "*" || strings.EqualFold(allowed, origin) {
+			return true
+		}
+		if prefix, suffix, ok := strings.Cut(allowed, "*"); ok {
+			if len(origin) > len(prefix)+len(suffix) && strings.HasPrefix(origin, prefix) && strings.HasSuffix(origin, suffix) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// anyOrigin returns whether a literal `*` can be sent as the allowed origin.
+func (c *CORSConfig) anyOrigin() bool {
+	for _, allowed := range c.AllowOrigins {
+		if allowed == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// corsPath tracks the methods registered for a path, which are sent in
+// response to preflight requests.
+type corsPath struct {
+	methods   []string
+	allow     string
+	preflight bool
+}
+
+// corsAdapter wraps an adapter to add CORS headers to responses and register
+// preflight handlers for each path as operations are added. Wrapping the
+// adapter means CORS works identically for every router and also applies to
+// the built-in OpenAPI & docs routes.
+type corsAdapter struct {
+	Adapter
+	config        *CORSConfig
+	anyOrigin     bool
+	allowHeaders  string
+	exposeHeaders string
+	maxAge        string
+	paths         map[string]*corsPath
+
+	// skipPreflight disables registering preflight handlers, e.g. for the
+	// built-in routes.
+	skipPreflight bool
+}
+
+func newCORSAdapter(a Adapter, config *CORSConfig) *corsAdapter {
+	if config.AllowCredentials && config.anyOrigin() {
+		panic("CORS cannot allow credentials from any origin, list the allowed origins instead of using `*`")
+	}
+	c := &corsAdapter{
+		Adapter:       a,
+		config:        config,
+		anyOrigin:     config.anyOrigin(),
+		allowHeaders:  strings.Join(config.AllowHeaders, ", "),
+		exposeHeaders: strings.Join(config.ExposeHeaders, ", "),
+		paths:         map[string]*corsPath{},
+	}
+	if config.MaxAge > 0 {
+		c.maxAge = strconv.Itoa(int(config.MaxAge.Seconds()))
+	}
+	return c
+}
+
+func (a *corsAdapter) Handle(op *Operation, handler func(ctx Context)) {
+	if a.skipPreflight {
+		a.Adapter.Handle(op, a.wrap(handler))
+		return
+	}
+
+	p := a.paths[op.Path]
+	if p == nil {
+		p = &corsPath{}
+		a.paths[op.Path] = p
+	}
+
+	if op.Method == http.MethodOptions {
+		if p.preflight {
+			panic(fmt.Errorf("cannot register OPTIONS %s as CORS preflight requests are already handled", op.Path))
+		}
+		// Custom `OPTIONS` handlers take over preflight handling for the path.
+		p.preflight = true
+		a.Adapter.Handle(op, a.wrap(handler))
+		return
+	}
+
+	p.methods = append(p.methods, op.Method)
+	p.allow = strings.Join(p.methods, ", ")
+	if !p.preflight {
+		p.preflight = true
+		a.Adapter.Handle(&Operation{Method: http.MethodOptions, Path: op.Path}, func(ctx Context) {
+			a.preflight(ctx, p)
+		})
+	}
+
+	a.Adapter.Handle(op, a.wrap(handler))
+}
+
+// setOrigin sets the allowed origin headers for an allowed origin.
+func (a *corsAdapter) setOrigin(ctx Context, origin string) {
+	if a.anyOrigin {
+		ctx.SetHeader("Access-Control-Allow-Origin", "*")
+		return
+	}
+	ctx.SetHeader("Access-Control-Allow-Origin", origin)
+	if a.config.AllowCredentials {
+		ctx.SetHeader("Access-Control-Allow-Credentials", "true")
+	}
+}
+
+// wrap adds CORS headers to responses for allowed origins.
+func (a *corsAdapter) wrap(handler func(ctx Context)) func(ctx Context) {
+	return func(ctx Context) {
+		if !a.anyOrigin {
+			// Responses differ by origin so must not be cached across them.
+			ctx.AppendHeader("Vary", "Origin")
+		}
+		if origin := ctx.Header("Origin"); origin != "" && a.config.allowOrigin(origin) {
+			a.setOrigin(ctx, origin)
+			if a.exposeHeaders != "" {
+				ctx.SetHeader("Access-Control-Expose-Headers", a.exposeHeaders)
+			}
+		}
+		handler(ctx)
+	}
+}
+
+// preflight responds to an `OPTIONS` request for a path with the allowed
+// methods and, for allowed origins, the CORS preflight headers.
+func (a *corsAdapter) preflight(ctx Context, p *corsPath) {
+	ctx.SetHeader("Allow", p.allow+", OPTIONS")
+	if !a.anyOrigin {
+		ctx.AppendHeader("Vary", "Origin")
+	}
+
+	origin := ctx.Header("Origin")
+	if origin != "" && ctx.Header("Access-Control-Request-Method") != "" && a.config.allowOrigin(origin) {
+		a.setOrigin(ctx, origin)
+		ctx.SetHeader("Access-Control-Allow-Methods", p.allow)
+		if a.allowHeaders != "" {
+			ctx.SetHeader("Access-Control-Allow-Headers", a.allowHeaders)
+		} else if requested := ctx.Header("Access-Control-Request-Headers"); requested != "" {
+			ctx.SetHeader("Access-Control-Allow-Headers", requested)
+		}
+		if a.maxAge != "" {
+			ctx.SetHeader("Access-Control-Max-Age", a.maxAge)
+		}
+	}
+
+	ctx.SetStatus(http.StatusNoContent)
+}
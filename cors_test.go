@@ -0,0 +1,126 @@
+package huma_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/humatest"
+	"github.com/stretchr/testify/assert"
+)
+
+func registerCORSTest(api huma.API) {
+	for _, method := range []string{http.MethodGet, http.MethodPut} {
+		huma.Register(api, huma.Operation{
+			OperationID: method + "-item",
+			Method:      method,
+			Path:        "/items/{id}",
+		}, func(ctx context.Context, input *struct {
+			ID string `path:"id"`
+		}) (*struct{}, error) {
+			return nil, nil
+		})
+	}
+}
+
+func TestCORS(t *testing.T) {
+	config := huma.DefaultConfig("Test API", "1.0.0")
+	config.CORS = &huma.CORSConfig{
+		AllowOrigins:     []string{"https://example.com", "https://*.example.org"},
+		AllowHeaders:     []string{"Content-Type", "Authorization"},
+		ExposeHeaders:    []string{"ETag"},
+		AllowCredentials: true,
+		MaxAge:           10 * time.Minute,
+	}
+	_, api := humatest.New(t, config)
+	registerCORSTest(api)
+
+	// Preflight requests are handled automatically.
+	resp := api.Do(http.MethodOptions, "/items/123",
+		"Origin: https://example.com",
+		"Access-Control-Request-Method: PUT",
+	)
+	assert.Equal(t, http.StatusNoContent, resp.Code)
+	assert.Equal(t, "GET, PUT, OPTIONS", resp.Header().Get("Allow"))
+	assert.Equal(t, "https://example.com", resp.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "GET, PUT", resp.Header().Get("Access-Control-Allow-Methods"))
+	assert.Equal(t, "Content-Type, Authorization", resp.Header().Get("Access-Control-Allow-Headers"))
+	assert.Equal(t, "true", resp.Header().Get("Access-Control-Allow-Credentials"))
+	assert.Equal(t, "600", resp.Header().Get("Access-Control-Max-Age"))
+	assert.Equal(t, "Origin", resp.Header().Get("Vary"))
+
+	// Actual requests from allowed origins get CORS headers.
+	resp = api.Get("/items/123", "Origin: https://api.example.org")
+	assert.Equal(t, http.StatusNoContent, resp.Code)
+	assert.Equal(t, "https://api.example.org", resp.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "ETag", resp.Header().Get("Access-Control-Expose-Headers"))
+	assert.Equal(t, "Origin", resp.Header().Get("Vary"))
+
+	// Other origins do not.
+	for _, origin := range []string{"https://evil.com", "https://example.org", "https://example.com.evil.com"} {
+		resp = api.Do(http.MethodOptions, "/items/123",
+			"Origin: "+origin,
+			"Access-Control-Request-Method: PUT",
+		)
+		assert.Equal(t, http.StatusNoContent, resp.Code)
+		assert.Empty(t, resp.Header().Get("Access-Control-Allow-Origin"), origin)
+
+		resp = api.Get("/items/123", "Origin: "+origin)
+		assert.Equal(t, http.StatusNoContent, resp.Code)
+		assert.Empty(t, resp.Header().Get("Access-Control-Allow-Origin"), origin)
+	}
+
+	// The OpenAPI is available cross-origin too.
+	resp = api.Get("/openapi.json", "Origin: https://example.com")
+	assert.Equal(t, "https://example.com", resp.Header().Get("Access-Control-Allow-Origin"))
+}
+
+func TestCORSAnyOrigin(t *testing.T) {
+	config := huma.DefaultConfig("Test API", "1.0.0")
+	config.CORS = &huma.CORSConfig{AllowOrigins: []string{"*"}}
+	_, api := humatest.New(t, config)
+	registerCORSTest(api)
+
+	// Requested headers are allowed by default.
+	resp := api.Do(http.MethodOptions, "/items/123",
+		"Origin: https://example.com",
+		"Access-Control-Request-Method: PUT",
+		"Access-Control-Request-Headers: content-type, x-custom",
+	)
+	assert.Equal(t, "*", resp.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "content-type, x-custom", resp.Header().Get("Access-Control-Allow-Headers"))
+	assert.Empty(t, resp.Header().Get("Access-Control-Allow-Credentials"))
+	assert.Empty(t, resp.Header().Get("Vary"))
+
+	resp = api.Get("/items/123", "Origin: https://example.com")
+	assert.Equal(t, "*", resp.Header().Get("Access-Control-Allow-Origin"))
+}
+
+func TestCORSAnyOriginCredentials(t *testing.T) {
+	config := huma.DefaultConfig("Test API", "1.0.0")
+	config.CORS = &huma.CORSConfig{
+		AllowOrigins:     []string{"https://example.com", "*"},
+		AllowCredentials: true,
+	}
+	assert.Panics(t, func() {
+		humatest.New(t, config)
+	})
+}
+
+func TestCORSCustomOptions(t *testing.T) {
+	config := huma.DefaultConfig("Test API", "1.0.0")
+	config.CORS = &huma.CORSConfig{AllowOrigins: []string{"*"}}
+	_, api := humatest.New(t, config)
+
+	registerCORSTest(api)
+	assert.Panics(t, func() {
+		huma.Register(api, huma.Operation{
+			Method: http.MethodOptions,
+			Path:   "/items/{id}",
+		}, func(ctx context.Context, input *struct{}) (*struct{}, error) {
+			return nil, nil
+		})
+	})
+}
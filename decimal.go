@@ -0,0 +1,178 @@
+package huma
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"math/big"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// DecimalFormat is the schema format used for arbitrary-precision numbers,
+// which are sent as JSON strings because many clients parse JSON numbers as
+// 64-bit floats, corrupting values like money amounts. Set it before
+// registering operations to use a different format name.
+var DecimalFormat = "decimal"
+
+// DecimalTypes lists additional types which are documented and validated as
+// decimal strings, e.g. from third-party decimal packages which marshal to
+// JSON strings:
+//
+//	huma.DecimalTypes = append(huma.DecimalTypes, reflect.TypeOf(decimal.Decimal{}))
+var DecimalTypes []reflect.Type
+
+var rxDecimal = regexp.MustCompile(`^-?([0-9]+(?:\.[0-9]+)?)(?:[eE]([-+]?[0-9]+))?$`)
+
+// Decimal numbers are limited in size, as otherwise a short string like
+// `1e1000000` expands to a huge number when it is used or serialized.
+const (
+	maxDecimalDigits   = 1000
+	maxDecimalExponent = 1000
+)
+
+var errInvalidDecimal = errors.New("invalid decimal number")
+
+// isDecimal returns whether `s` is a decimal number within the size limits.
+func isDecimal(s string) bool {
+	m := rxDecimal.FindStringSubmatch(s)
+	if m == nil {
+		return false
+	}
+	if len(m[1])-strings.Count(m[1], ".") > maxDecimalDigits {
+		return false
+	}
+	if m[2] != "" {
+		exp, err := strconv.Atoi(m[2])
+		if err != nil || exp > maxDecimalExponent || exp < -maxDecimalExponent {
+			return false
+		}
+	}
+	return true
+}
+
+// isDecimalType returns whether `t` is one of the `DecimalTypes`.
+func isDecimalType(t reflect.Type) bool {
+	for _, dt := range DecimalTypes {
+		if t == dt {
+			return true
+		}
+	}
+	return false
+}
+
+// unquoteNumber returns the contents of a JSON string or number.
+func unquoteNumber(data []byte) (string, error) {
+	if len(data) > 0 && data[0] == '"' {
+		return strconv.Unquote(string(data))
+	}
+	return string(data), nil
+}
+
+// BigInt is an arbitrary-precision integer which is sent as a JSON string,
+// e.g. `"12345678901234567890"`.
+//
+//	type Account struct {
+//		Balance huma.BigInt `json:"balance"`
+//	}
+//
+//	account.Balance.SetInt64(100)
+type BigInt struct {
+	big.Int
+}
+
+// NewBigInt returns a `BigInt` with the value of `x`.
+func NewBigInt(x *big.Int) BigInt {
+	b := BigInt{}
+	b.Int.Set(x)
+	return b
+}
+
+func (b BigInt) MarshalText() ([]byte, error) {
+	return b.Int.MarshalText()
+}
+
+func (b *BigInt) UnmarshalText(text []byte) error {
+	if _, ok := b.Int.SetString(string(text), 10); !ok {
+		return errInvalidDecimal
+	}
+	return nil
+}
+
+func (b BigInt) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + b.Int.String() + `"`), nil
+}
+
+func (b *BigInt) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+	s, err := unquoteNumber(data)
+	if err != nil {
+		return err
+	}
+	return b.UnmarshalText([]byte(s))
+}
+
+func (b *BigInt) Schema(r Registry) *Schema {
+	return &Schema{Type: TypeString, Format: DecimalFormat, Pattern: "^-?[0-9]+$"}
+}
+
+// BigFloat is an arbitrary-precision decimal number which is sent as a JSON
+// string, e.g. `"1234.56"`. When decoded, the precision is set high enough
+// to keep all of the given digits.
+//
+//	type Price struct {
+//		Amount huma.BigFloat `json:"amount"`
+//	}
+type BigFloat struct {
+	big.Float
+}
+
+// NewBigFloat returns a `BigFloat` with the value and precision of `x`.
+func NewBigFloat(x *big.Float) BigFloat {
+	b := BigFloat{}
+	b.Float.SetPrec(x.Prec()).Set(x)
+	return b
+}
+
+func (b BigFloat) MarshalText() ([]byte, error) {
+	return []byte(b.Float.Text('f', -1)), nil
+}
+
+func (b *BigFloat) UnmarshalText(text []byte) error {
+	s := string(text)
+	if !isDecimal(s) {
+		return errInvalidDecimal
+	}
+	// Each decimal digit needs log2(10) bits of mantissa, plus some headroom
+	// so the shortest representation round-trips to the same digits.
+	prec := uint(math.Ceil(float64(len(s))*math.Log2(10))) + 64
+	f, _, err := big.ParseFloat(s, 10, prec, big.ToNearestEven)
+	if err != nil {
+		return errInvalidDecimal
+	}
+	b.Float = *f
+	return nil
+}
+
+func (b BigFloat) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + b.Float.Text('f', -1) + `"`), nil
+}
+
+func (b *BigFloat) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+	s, err := unquoteNumber(data)
+	if err != nil {
+		return err
+	}
+	return b.UnmarshalText([]byte(s))
+}
+
+func (b *BigFloat) Schema(r Registry) *Schema {
+	return &Schema{Type: TypeString, Format: DecimalFormat}
+}
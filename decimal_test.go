@@ -0,0 +1,146 @@
+package huma_test
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/humatest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBigIntJSON(t *testing.T) {
+	var v struct {
+		Value huma.BigInt `json:"value"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(`{"value": "123456789012345678901234567890"}`), &v))
+	assert.Equal(t, "123456789012345678901234567890", v.Value.String())
+
+	b, err := json.Marshal(v)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"value": "123456789012345678901234567890"}`, string(b))
+
+	// Numbers are accepted too.
+	require.NoError(t, json.Unmarshal([]byte(`{"value": 42}`), &v))
+	assert.Equal(t, int64(42), v.Value.Int64())
+
+	assert.Error(t, json.Unmarshal([]byte(`{"value": "1.5"}`), &v))
+
+	n := huma.NewBigInt(big.NewInt(7))
+	assert.Equal(t, "7", n.String())
+}
+
+func TestBigFloatJSON(t *testing.T) {
+	var v struct {
+		Value huma.BigFloat `json:"value"`
+	}
+
+	// Digits are preserved even beyond what a float64 can represent.
+	require.NoError(t, json.Unmarshal([]byte(`{"value": "12345678901234567890.123456789"}`), &v))
+	b, err := json.Marshal(v)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"value": "12345678901234567890.123456789"}`, string(b))
+
+	require.NoError(t, json.Unmarshal([]byte(`{"value": "0.1"}`), &v))
+	b, err = json.Marshal(v)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"value": "0.1"}`, string(b))
+
+	assert.Error(t, json.Unmarshal([]byte(`{"value": "abc"}`), &v))
+
+	// Huge numbers are rejected, as they would be slow to use and serialize.
+	require.NoError(t, json.Unmarshal([]byte(`{"value": "1e1000"}`), &v))
+	require.NoError(t, json.Unmarshal([]byte(`{"value": "1e-1000"}`), &v))
+	assert.Error(t, json.Unmarshal([]byte(`{"value": "1e1000000"}`), &v))
+	assert.Error(t, json.Unmarshal([]byte(`{"value": "1e-1001"}`), &v))
+	assert.Error(t, json.Unmarshal([]byte(`{"value": "1e99999999999999999999"}`), &v))
+	assert.Error(t, json.Unmarshal([]byte(`{"value": "0.`+strings.Repeat("1", 1000)+`"}`), &v))
+
+	f := huma.NewBigFloat(big.NewFloat(1.5))
+	assert.Equal(t, "1.5", f.Text('g', -1))
+}
+
+func TestDecimalTypes(t *testing.T) {
+	type Money struct {
+		Amount string
+	}
+
+	huma.DecimalTypes = append(huma.DecimalTypes, reflect.TypeOf(Money{}))
+	defer func() {
+		huma.DecimalTypes = huma.DecimalTypes[:len(huma.DecimalTypes)-1]
+	}()
+
+	r := huma.NewMapRegistry("#/components/schemas/", huma.DefaultSchemaNamer)
+	s := r.Schema(reflect.TypeOf(Money{}), true, "")
+	assert.Equal(t, huma.TypeString, s.Type)
+	assert.Equal(t, "decimal", s.Format)
+	assert.Empty(t, r.Map())
+}
+
+func TestDecimalRequests(t *testing.T) {
+	_, api := humatest.New(t, huma.DefaultConfig("Test API", "1.0.0"))
+
+	type Body struct {
+		Price    huma.BigFloat `json:"price"`
+		Quantity huma.BigInt   `json:"quantity"`
+	}
+
+	huma.Register(api, huma.Operation{
+		OperationID: "echo",
+		Method:      http.MethodPost,
+		Path:        "/echo",
+	}, func(ctx context.Context, input *struct {
+		Discount huma.BigFloat `query:"discount"`
+		Body     Body
+	}) (*struct{ Body Body }, error) {
+		out := &struct{ Body Body }{}
+		out.Body.Price = huma.NewBigFloat(new(big.Float).Sub(&input.Body.Price.Float, &input.Discount.Float))
+		out.Body.Quantity = input.Body.Quantity
+		return out, nil
+	})
+
+	schema := api.OpenAPI().Components.Schemas.Map()["Body"]
+	require.NotNil(t, schema)
+	assert.Equal(t, "decimal", schema.Properties["price"].Format)
+	assert.Equal(t, "^-?[0-9]+$", schema.Properties["quantity"].Pattern)
+	assert.Equal(t, "decimal", api.OpenAPI().Paths["/echo"].Post.Parameters[0].Schema.Format)
+
+	resp := api.Post("/echo?discount=0", map[string]any{
+		"price":    "1234567890123456789.99",
+		"quantity": "98765432109876543210",
+	})
+	assert.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+	var out map[string]any
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &out))
+	assert.Equal(t, "1234567890123456789.99", out["price"])
+	assert.Equal(t, "98765432109876543210", out["quantity"])
+
+	resp = api.Post("/echo", map[string]any{
+		"price":    "abc",
+		"quantity": "1.5",
+	})
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+	assert.Contains(t, resp.Body.String(), "body.price")
+	assert.Contains(t, resp.Body.String(), "body.quantity")
+
+	resp = api.Post("/echo", map[string]any{
+		"price":    "1e1000000",
+		"quantity": strings.Repeat("9", 1001),
+	})
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+	assert.Contains(t, resp.Body.String(), "body.price")
+	assert.Contains(t, resp.Body.String(), "body.quantity")
+
+	resp = api.Post("/echo?discount=abc", map[string]any{
+		"price":    "1",
+		"quantity": "1",
+	})
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+	assert.Contains(t, resp.Body.String(), "query.discount")
+}
@@ -0,0 +1,746 @@
+package huma
+
+import (
+	"encoding"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+var (
+	jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// isJSONContentType returns whether a request body with the given content type
+// would be unmarshaled using the JSON format, mirroring the content type
+// handling of `API.Unmarshal`.
+func isJSONContentType(contentType string) bool {
+	start := strings.IndexRune(contentType, '+') + 1
+	end := strings.IndexRune(contentType, ';')
+	if end == -1 {
+		end = len(contentType)
+	}
+	ct := contentType[start:end]
+	return ct == "" || ct == "application/json"
+}
+
+// structField describes how to reach a JSON-visible field of a struct.
+type structField struct {
+	name  string
+	index []int
+}
+
+// structFields describes the JSON-visible fields of a struct type. If `ok` is
+// false then the struct uses features (like embedded pointers or the `string`
+// tag option) which must be handled by `encoding/json` directly.
+type structFields struct {
+	ok     bool
+	fields []structField
+}
+
+// lookup finds a field by its JSON name, preferring an exact match and falling
+// back to a case-insensitive match like `encoding/json`.
+func (sf *structFields) lookup(name string) []int {
+	for i := range sf.fields {
+		if sf.fields[i].name == name {
+			return sf.fields[i].index
+		}
+	}
+	for i := range sf.fields {
+		if strings.EqualFold(sf.fields[i].name, name) {
+			return sf.fields[i].index
+		}
+	}
+	return nil
+}
+
+var structFieldsCache sync.Map
+
+func cachedStructFields(t reflect.Type) *structFields {
+	if sf, ok := structFieldsCache.Load(t); ok {
+		return sf.(*structFields)
+	}
+	sf := &structFields{ok: true}
+	collectStructFields(t, nil, sf)
+	structFieldsCache.Store(t, sf)
+	return sf
+}
+
+func collectStructFields(t reflect.Type, index []int, sf *structFields) {
+	var embedded []reflect.StructField
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if strings.Contains(","+opts+",", ",string,") {
+			sf.ok = false
+		}
+		if f.Anonymous && name == "" {
+			if f.Type.Kind() == reflect.Pointer {
+				sf.ok = false
+				continue
+			}
+			if f.Type.Kind() == reflect.Struct {
+				embedded = append(embedded, f)
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		if sf.lookupExact(name) {
+			continue
+		}
+		sf.fields = append(sf.fields, structField{
+			name:  name,
+			index: append(append([]int{}, index...), i),
+		})
+	}
+
+	// Fields of embedded structs are promoted, but shallower fields win.
+	for _, f := range embedded {
+		collectStructFields(f.Type, append(append([]int{}, index...), f.Index...), sf)
+	}
+}
+
+func (sf *structFields) lookupExact(name string) bool {
+	for i := range sf.fields {
+		if sf.fields[i].name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// errInvalidBody is returned by the body decoder as soon as the body is found
+// not to be valid JSON or not to match its schema.
+var errInvalidBody = errors.New("invalid body")
+
+// maxBodyDepth limits the nesting of objects and arrays, like `encoding/json`.
+const maxBodyDepth = 10000
+
+// bodyDecoder decodes a JSON request body directly into the input body value
+// while validating it against the body schema in the same pass, avoiding the
+// need to parse the body twice and build an intermediate `map[string]any`.
+// Tokens are read straight from the body rather than via `json.Decoder`, so
+// they are not boxed into interfaces along the way. Values which cannot be
+// handled on the fly (custom unmarshalers, interfaces, schema combinators,
+// etc.) fall back to parsing, validating, and then unmarshaling just that
+// part of the body.
+//
+// The decoder only handles valid bodies. It stops at the first problem, at
+// which point the caller discards the partially decoded value along with
+// anything added to the validation result, and instead parses and validates
+// the body separately so errors are reported in the usual order and format.
+type bodyDecoder struct {
+	r     Registry
+	data  []byte
+	pos   int
+	depth int
+	pb    *PathBuffer
+	res   *ValidateResult
+}
+
+// decodeBody decodes the JSON `data` into `v` while validating it against the
+// schema `s`. An error is returned if the body is not valid JSON or does not
+// match the schema, in which case `v` and `res` are left in an undefined state.
+func decodeBody(r Registry, s *Schema, pb *PathBuffer, data []byte, v reflect.Value, res *ValidateResult) error {
+	d := bodyDecoder{r: r, data: data, pb: pb, res: res}
+	if _, err := d.value(s, v, false); err != nil {
+		return err
+	}
+	if d.skipSpace(); d.pos != len(d.data) {
+		return errInvalidBody
+	}
+	return nil
+}
+
+func (d *bodyDecoder) skipSpace() {
+	for d.pos < len(d.data) {
+		switch d.data[d.pos] {
+		case ' ', '\t', '\r', '\n':
+			d.pos++
+		default:
+			return
+		}
+	}
+}
+
+// peek returns the next non-space byte, or zero at the end of the body.
+func (d *bodyDecoder) peek() byte {
+	d.skipSpace()
+	if d.pos < len(d.data) {
+		return d.data[d.pos]
+	}
+	return 0
+}
+
+// consume skips the next non-space byte if it is `c`.
+func (d *bodyDecoder) consume(c byte) bool {
+	if d.peek() == c {
+		d.pos++
+		return true
+	}
+	return false
+}
+
+// literal skips `lit`, e.g. `true`, if it is next.
+func (d *bodyDecoder) literal(lit string) bool {
+	if len(d.data)-d.pos >= len(lit) && string(d.data[d.pos:d.pos+len(lit)]) == lit {
+		d.pos += len(lit)
+		return true
+	}
+	return false
+}
+
+// rawString reads the string at the current position, returning its contents
+// without the quotes and whether it has any escapes.
+func (d *bodyDecoder) rawString() ([]byte, bool, bool) {
+	escaped := false
+	start := d.pos + 1
+	for i := start; i < len(d.data); i++ {
+		switch c := d.data[i]; {
+		case c == '"':
+			d.pos = i + 1
+			return d.data[start:i], escaped, true
+		case c == '\\':
+			escaped = true
+			i++
+		case c < ' ':
+			return nil, false, false
+		}
+	}
+	return nil, false, false
+}
+
+// str reads the string at the current position.
+func (d *bodyDecoder) str() (string, bool) {
+	start := d.pos
+	raw, escaped, ok := d.rawString()
+	if !ok {
+		return "", false
+	}
+	return d.unquote(start, raw, escaped)
+}
+
+// unquote returns the string read by `rawString` from `start`. Strings with
+// escapes or invalid UTF-8 are left to `encoding/json`.
+func (d *bodyDecoder) unquote(start int, raw []byte, escaped bool) (string, bool) {
+	if !escaped && utf8.Valid(raw) {
+		return string(raw), true
+	}
+	var s string
+	return s, json.Unmarshal(d.data[start:d.pos], &s) == nil
+}
+
+// key reads an object key and the colon after it. Keys which match the name
+// of one of the struct `fields` reuse the name instead of allocating.
+func (d *bodyDecoder) key(fields *structFields) (string, bool) {
+	if d.peek() != '"' {
+		return "", false
+	}
+	start := d.pos
+	raw, escaped, ok := d.rawString()
+	if !ok {
+		return "", false
+	}
+	k, found := "", false
+	if fields != nil && !escaped {
+		for i := range fields.fields {
+			if fields.fields[i].name == string(raw) {
+				k, found = fields.fields[i].name, true
+				break
+			}
+		}
+	}
+	if !found {
+		if k, ok = d.unquote(start, raw, escaped); !ok {
+			return "", false
+		}
+	}
+	return k, d.consume(':')
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// number reads the number at the current position, returning its text.
+func (d *bodyDecoder) number() ([]byte, bool) {
+	data, i := d.data, d.pos
+	digits := func() bool {
+		start := i
+		for i < len(data) && isDigit(data[i]) {
+			i++
+		}
+		return i > start
+	}
+	if i < len(data) && data[i] == '-' {
+		i++
+	}
+	if i < len(data) && data[i] == '0' {
+		i++
+	} else if !digits() {
+		return nil, false
+	}
+	if i < len(data) && data[i] == '.' {
+		i++
+		if !digits() {
+			return nil, false
+		}
+	}
+	if i < len(data) && (data[i] == 'e' || data[i] == 'E') {
+		i++
+		if i < len(data) && (data[i] == '+' || data[i] == '-') {
+			i++
+		}
+		if !digits() {
+			return nil, false
+		}
+	}
+	n := data[d.pos:i]
+	d.pos = i
+	return n, true
+}
+
+// rawValue returns the next value without decoding it. The value is only
+// checked enough to find where it ends, so it must still be parsed.
+func (d *bodyDecoder) rawValue() ([]byte, bool) {
+	d.skipSpace()
+	start, depth := d.pos, 0
+	for d.pos < len(d.data) {
+		c := d.data[d.pos]
+		if depth == 0 && (c == ',' || c == '}' || c == ']' || c == ' ' || c == '\t' || c == '\r' || c == '\n') {
+			break
+		}
+		switch c {
+		case '"':
+			if _, _, ok := d.rawString(); !ok {
+				return nil, false
+			}
+			continue
+		case '{', '[':
+			depth++
+		case '}', ']':
+			depth--
+		}
+		d.pos++
+	}
+	if depth != 0 || d.pos == start {
+		return nil, false
+	}
+	return d.data[start:d.pos], true
+}
+
+// direct returns whether a value of type `t` described by schema `s` can be
+// decoded and validated on the fly.
+func (d *bodyDecoder) direct(s *Schema, t reflect.Type) bool {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Implements(jsonUnmarshalerType) || reflect.PointerTo(t).Implements(jsonUnmarshalerType) || reflect.PointerTo(t).Implements(textUnmarshalerType) {
+		return false
+	}
+
+	switch s.Type {
+	case TypeBoolean:
+		return t.Kind() == reflect.Bool
+	case TypeString:
+		return t.Kind() == reflect.String
+	case TypeInteger, TypeNumber:
+		switch t.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+			reflect.Float32, reflect.Float64:
+			return true
+		}
+		return false
+	}
+
+	// Composite values are only validated once complete when using these.
+	if s.OneOf != nil || s.AnyOf != nil || s.AllOf != nil || s.Not != nil || len(s.Enum) > 0 {
+		return false
+	}
+
+	switch s.Type {
+	case TypeArray:
+		return t.Kind() == reflect.Slice && t.Elem().Kind() != reflect.Uint8 && s.Items != nil && !s.UniqueItems
+	case TypeObject:
+		switch t.Kind() {
+		case reflect.Struct:
+			return cachedStructFields(t).ok
+		case reflect.Map:
+			_, ok := s.AdditionalProperties.(*Schema)
+			return ok && len(s.Properties) == 0 && t.Key().Kind() == reflect.String && !reflect.PointerTo(t.Key()).Implements(textUnmarshalerType)
+		}
+	}
+	return false
+}
+
+// value decodes and validates the next value into `v`. When `optional` is set
+// a `null` is treated like a missing value and is not validated. Returns
+// whether the value was `null`.
+func (d *bodyDecoder) value(s *Schema, v reflect.Value, optional bool) (bool, error) {
+	for s.Ref != "" {
+		s = d.r.SchemaFromRef(s.Ref)
+	}
+
+	if !d.direct(s, v.Type()) {
+		return d.fallback(s, v, optional)
+	}
+
+	if d.peek() == 'n' {
+		if !d.literal("null") {
+			return false, errInvalidBody
+		}
+		if !optional {
+			if err := d.validate(s, nil); err != nil {
+				return false, err
+			}
+		}
+		switch v.Kind() {
+		case reflect.Pointer, reflect.Map, reflect.Slice:
+			v.Set(reflect.Zero(v.Type()))
+		}
+		return true, nil
+	}
+
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		v = v.Elem()
+	}
+
+	switch s.Type {
+	case TypeObject, TypeArray:
+		open := byte('[')
+		if s.Type == TypeObject {
+			open = '{'
+		}
+		if !d.consume(open) || d.depth >= maxBodyDepth {
+			return false, errInvalidBody
+		}
+		d.depth++
+		var err error
+		switch {
+		case s.Type == TypeArray:
+			err = d.array(s, v)
+		case v.Kind() == reflect.Struct:
+			err = d.object(s, v)
+		default:
+			err = d.mapValue(s, v)
+		}
+		d.depth--
+		return false, err
+	}
+	return false, d.scalar(s, v)
+}
+
+// fallback parses the next value, validates it, and then unmarshals it into
+// `v` using `encoding/json`.
+func (d *bodyDecoder) fallback(s *Schema, v reflect.Value, optional bool) (bool, error) {
+	raw, ok := d.rawValue()
+	if !ok {
+		return false, errInvalidBody
+	}
+	var parsed any
+	if err := json.Unmarshal(raw, &parsed); err != nil {
+		return false, err
+	}
+	if parsed == nil && optional {
+		return true, json.Unmarshal(raw, v.Addr().Interface())
+	}
+
+	if err := d.validate(s, parsed); err != nil {
+		return false, err
+	}
+	if err := json.Unmarshal(raw, v.Addr().Interface()); err != nil {
+		return false, errInvalidBody
+	}
+	return parsed == nil, nil
+}
+
+// validate validates a generic value against `s`.
+func (d *bodyDecoder) validate(s *Schema, v any) error {
+	count := len(d.res.Errors)
+	Validate(d.r, s, d.pb, ModeWriteToServer, v, d.res)
+	if len(d.res.Errors) > count {
+		return errInvalidBody
+	}
+	return nil
+}
+
+// skip parses the next value, validating it against `s` if set.
+func (d *bodyDecoder) skip(s *Schema) error {
+	raw, ok := d.rawValue()
+	if !ok {
+		return errInvalidBody
+	}
+	if s == nil {
+		if !json.Valid(raw) {
+			return errInvalidBody
+		}
+		return nil
+	}
+	var parsed any
+	if err := json.Unmarshal(raw, &parsed); err != nil {
+		return err
+	}
+	return d.validate(s, parsed)
+}
+
+func (d *bodyDecoder) scalar(s *Schema, v reflect.Value) error {
+	switch c := d.peek(); {
+	case c == '"':
+		if v.Kind() != reflect.String {
+			return errInvalidBody
+		}
+		str, ok := d.str()
+		if !ok {
+			return errInvalidBody
+		}
+		if err := d.validate(s, str); err != nil {
+			return err
+		}
+		v.SetString(str)
+		return nil
+	case c == 't' || c == 'f':
+		b := c == 't'
+		lit := "false"
+		if b {
+			lit = "true"
+		}
+		if v.Kind() != reflect.Bool || !d.literal(lit) {
+			return errInvalidBody
+		}
+		if err := d.validate(s, b); err != nil {
+			return err
+		}
+		v.SetBool(b)
+		return nil
+	case c == '-' || isDigit(c):
+		n, ok := d.number()
+		if !ok {
+			return errInvalidBody
+		}
+		text := string(n)
+		f, _ := strconv.ParseFloat(text, 64)
+		if err := d.validate(s, f); err != nil {
+			return err
+		}
+		switch v.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			if i, err := strconv.ParseInt(text, 10, 64); err == nil && !v.OverflowInt(i) {
+				v.SetInt(i)
+				return nil
+			}
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			if i, err := strconv.ParseUint(text, 10, 64); err == nil && !v.OverflowUint(i) {
+				v.SetUint(i)
+				return nil
+			}
+		case reflect.Float32, reflect.Float64:
+			if f, err := strconv.ParseFloat(text, v.Type().Bits()); err == nil {
+				v.SetFloat(f)
+				return nil
+			}
+		}
+	}
+
+	return errInvalidBody
+}
+
+type seenProperty struct {
+	name string
+	null bool
+}
+
+func (d *bodyDecoder) object(s *Schema, v reflect.Value) error {
+	fields := cachedStructFields(v.Type())
+	var seen []seenProperty
+
+	for more := !d.consume('}'); more; {
+		k, ok := d.key(fields)
+		if !ok {
+			return errInvalidBody
+		}
+
+		var f reflect.Value
+		if index := fields.lookup(k); index != nil {
+			f = v.FieldByIndex(index)
+		}
+
+		d.pb.Push(k)
+		null := false
+		var err error
+		if prop := s.Properties[k]; prop != nil {
+			if f.IsValid() {
+				null, err = d.value(prop, f, true)
+			} else {
+				err = d.skip(prop)
+			}
+		} else {
+			// No additional properties allowed.
+			if addl, ok := s.AdditionalProperties.(bool); ok && !addl {
+				d.pb.Pop()
+				return errInvalidBody
+			}
+			addl, _ := s.AdditionalProperties.(*Schema)
+			if f.IsValid() {
+				// Field matched via a case-insensitive name.
+				if addl != nil {
+					_, err = d.value(addl, f, false)
+				} else if raw, ok := d.rawValue(); !ok || json.Unmarshal(raw, f.Addr().Interface()) != nil {
+					err = errInvalidBody
+				}
+			} else {
+				err = d.skip(addl)
+			}
+		}
+		d.pb.Pop()
+		if err != nil {
+			return err
+		}
+
+		found := false
+		for i := range seen {
+			if seen[i].name == k {
+				seen[i].null = null
+				found = true
+				break
+			}
+		}
+		if !found {
+			seen = append(seen, seenProperty{name: k, null: null})
+		}
+
+		if more, ok = d.next('}'); !ok {
+			return errInvalidBody
+		}
+	}
+
+	if s.MinProperties != nil && len(seen) < *s.MinProperties {
+		return errInvalidBody
+	}
+	if s.MaxProperties != nil && len(seen) > *s.MaxProperties {
+		return errInvalidBody
+	}
+
+	for _, k := range s.propertyNames {
+		if !s.requiredMap[k] {
+			continue
+		}
+		present := false
+		for i := range seen {
+			if seen[i].name == k {
+				present = !seen[i].null
+				break
+			}
+		}
+		if present {
+			continue
+		}
+		prop := s.Properties[k]
+		for prop.Ref != "" {
+			prop = d.r.SchemaFromRef(prop.Ref)
+		}
+		if prop.ReadOnly {
+			// Read-only properties are not required when writing to the server.
+			continue
+		}
+		return errInvalidBody
+	}
+	return nil
+}
+
+// next consumes the comma before the next member or item, or the `end` of the
+// object or array. Returns whether there are more members or items.
+func (d *bodyDecoder) next(end byte) (bool, bool) {
+	if d.consume(',') {
+		return true, true
+	}
+	return false, d.consume(end)
+}
+
+func (d *bodyDecoder) mapValue(s *Schema, v reflect.Value) error {
+	t := v.Type()
+	addl := s.AdditionalProperties.(*Schema)
+	if v.IsNil() {
+		v.Set(reflect.MakeMap(t))
+	}
+
+	count := 0
+	for more := !d.consume('}'); more; {
+		k, ok := d.key(nil)
+		if !ok {
+			return errInvalidBody
+		}
+		count++
+
+		item := reflect.New(t.Elem()).Elem()
+		d.pb.Push(k)
+		_, err := d.value(addl, item, false)
+		d.pb.Pop()
+		if err != nil {
+			return err
+		}
+		v.SetMapIndex(reflect.ValueOf(k).Convert(t.Key()), item)
+
+		if more, ok = d.next('}'); !ok {
+			return errInvalidBody
+		}
+	}
+
+	if s.MinProperties != nil && count < *s.MinProperties {
+		return errInvalidBody
+	}
+	if s.MaxProperties != nil && count > *s.MaxProperties {
+		return errInvalidBody
+	}
+	return nil
+}
+
+func (d *bodyDecoder) array(s *Schema, v reflect.Value) error {
+	t := v.Type()
+	if v.IsNil() {
+		v.Set(reflect.MakeSlice(t, 0, 0))
+	} else {
+		v.SetLen(0)
+	}
+
+	zero := reflect.Zero(t.Elem())
+	for i, more := 0, !d.consume(']'); more; i++ {
+		v.Set(reflect.Append(v, zero))
+		d.pb.PushIndex(i)
+		_, err := d.value(s.Items, v.Index(i), false)
+		d.pb.Pop()
+		if err != nil {
+			return err
+		}
+
+		var ok bool
+		if more, ok = d.next(']'); !ok {
+			return errInvalidBody
+		}
+	}
+
+	if s.MinItems != nil && v.Len() < *s.MinItems {
+		return errInvalidBody
+	}
+	if s.MaxItems != nil && v.Len() > *s.MaxItems {
+		return errInvalidBody
+	}
+	return nil
+}
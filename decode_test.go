@@ -0,0 +1,212 @@
+package huma_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/humatest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type decodeTestItem struct {
+	ID    int     `json:"id"`
+	Score float64 `json:"score,omitempty"`
+}
+
+type decodeTestBody struct {
+	Name    string           `json:"name" maxLength:"5"`
+	Count   int              `json:"count,omitempty" minimum:"1"`
+	Small   int8             `json:"small,omitempty"`
+	Tags    []string         `json:"tags,omitempty" minItems:"1" maxItems:"2"`
+	Labels  map[string]int   `json:"labels,omitempty" maxProperties:"1"`
+	Items   []decodeTestItem `json:"items,omitempty"`
+	Created time.Time        `json:"created,omitempty"`
+}
+
+// Invalid bodies must be reported exactly like they were before bodies were
+// decoded and validated in a single pass, so the expected errors here were
+// taken from that implementation.
+func TestDecodeBodyErrors(t *testing.T) {
+	for _, item := range []struct {
+		name   string
+		body   string
+		status int
+		errors string
+	}{
+		{
+			name:   "valid",
+			body:   `{"name": "Huma", "count": 2, "tags": ["a"], "labels": {"a": 1}, "items": [{"id": 1, "score": 0.5}], "created": "2024-01-02T03:04:05Z"}`,
+			status: http.StatusNoContent,
+		},
+		{
+			name:   "multiple",
+			body:   `{"name": null, "items": [{"id": "one"}, {"id": 1.5}], "extra": true}`,
+			status: http.StatusUnprocessableEntity,
+			errors: `[
+				{"message": "expected required property name to be present", "location": "body", "value": {"extra": true, "items": [{"id": "one"}, {"id": 1.5}], "name": null}},
+				{"message": "expected number", "location": "body.items[0].id", "value": "one"},
+				{"message": "unexpected property", "location": "body.extra", "value": {"extra": true, "items": [{"id": "one"}, {"id": 1.5}], "name": null}}
+			]`,
+		},
+		{
+			name:   "constraints",
+			body:   `{"tags": [], "name": "Too long", "labels": {"a": 1, "b": "two"}, "count": 0}`,
+			status: http.StatusUnprocessableEntity,
+			errors: `[
+				{"message": "expected length <= 5", "location": "body.name", "value": "Too long"},
+				{"message": "expected number >= 1", "location": "body.count", "value": 0},
+				{"message": "expected array length >= 1", "location": "body.tags", "value": []},
+				{"message": "expected object with at most 1 properties", "location": "body.labels", "value": {"a": 1, "b": "two"}},
+				{"message": "expected number", "location": "body.labels.b", "value": "two"}
+			]`,
+		},
+		{
+			name:   "too-many-items",
+			body:   `{"name": "a", "tags": ["a", "b", "c"]}`,
+			status: http.StatusUnprocessableEntity,
+			errors: `[{"message": "expected array length <= 2", "location": "body.tags", "value": ["a", "b", "c"]}]`,
+		},
+		{
+			name:   "wrong-type",
+			body:   `[{"name": "a"}]`,
+			status: http.StatusUnprocessableEntity,
+			errors: `[{"message": "expected object", "location": "body", "value": [{"name": "a"}]}]`,
+		},
+		{
+			name:   "bad-json",
+			body:   `{"name": "a", "count": }`,
+			status: http.StatusBadRequest,
+			errors: `[{"message": "invalid character '}' looking for beginning of value", "location": "body", "value": "eyJuYW1lIjogImEiLCAiY291bnQiOiB9"}]`,
+		},
+		{
+			name:   "truncated",
+			body:   `{"name": "a", "tags": ["a"`,
+			status: http.StatusBadRequest,
+			errors: `[{"message": "unexpected end of JSON input", "location": "body", "value": "eyJuYW1lIjogImEiLCAidGFncyI6IFsiYSI="}]`,
+		},
+		{
+			name:   "trailing-data",
+			body:   `{"name": "a"} {}`,
+			status: http.StatusBadRequest,
+			errors: `[{"message": "invalid character '{' after top-level value", "location": "body", "value": "eyJuYW1lIjogImEifSB7fQ=="}]`,
+		},
+		{
+			name:   "int-overflow",
+			body:   `{"name": "a", "count": 1e20}`,
+			status: http.StatusUnprocessableEntity,
+			errors: `[{"message": "json: cannot unmarshal number 1e20 into Go struct field decodeTestBody.count of type int", "location": "body", "value": "{\"name\": \"a\", \"count\": 1e20}"}]`,
+		},
+		{
+			name:   "int8-overflow",
+			body:   `{"name": "a", "small": 300}`,
+			status: http.StatusUnprocessableEntity,
+			errors: `[{"message": "json: cannot unmarshal number 300 into Go struct field decodeTestBody.small of type int8", "location": "body", "value": "{\"name\": \"a\", \"small\": 300}"}]`,
+		},
+		{
+			name:   "bad-time",
+			body:   `{"name": "a", "created": "yesterday"}`,
+			status: http.StatusUnprocessableEntity,
+			errors: `[{"message": "expected string to be RFC 3339 date-time", "location": "body.created", "value": "yesterday"}]`,
+		},
+	} {
+		t.Run(item.name, func(t *testing.T) {
+			_, api := humatest.New(t)
+			huma.Register(api, huma.Operation{
+				Method: http.MethodPut,
+				Path:   "/body",
+			}, func(ctx context.Context, input *struct {
+				Body decodeTestBody
+			}) (*struct{}, error) {
+				return nil, nil
+			})
+
+			resp := api.Put("/body", strings.NewReader(item.body))
+			assert.Equal(t, item.status, resp.Code, resp.Body.String())
+			if item.errors == "" {
+				return
+			}
+			var model struct {
+				Errors json.RawMessage `json:"errors"`
+			}
+			require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &model))
+			assert.JSONEq(t, item.errors, string(model.Errors))
+		})
+	}
+}
+
+type decodeBenchBody struct {
+	ID     int      `json:"id"`
+	Name   string   `json:"name" maxLength:"50"`
+	Tags   []string `json:"tags"`
+	Rating float64  `json:"rating" minimum:"0" maximum:"5"`
+	Owner  struct {
+		ID    int    `json:"id"`
+		Name  string `json:"name"`
+		Email string `json:"email" format:"email"`
+	} `json:"owner"`
+	Categories []struct {
+		Name    string   `json:"name"`
+		Order   int      `json:"order"`
+		Visible bool     `json:"visible"`
+		Aliases []string `json:"aliases,omitempty"`
+	} `json:"categories"`
+}
+
+// BenchmarkDecodeBody compares decoding and validating a medium-sized body in
+// a single pass with parsing, validating, and unmarshaling it separately,
+// which is forced here by using a request transformer.
+func BenchmarkDecodeBody(b *testing.B) {
+	_, api := humatest.New(b)
+
+	handler := func(ctx context.Context, input *struct {
+		Body decodeBenchBody
+	}) (*struct{}, error) {
+		return nil, nil
+	}
+	huma.Register(api, huma.Operation{
+		Method: http.MethodPut,
+		Path:   "/single",
+	}, handler)
+	huma.Register(api, huma.Operation{
+		Method: http.MethodPut,
+		Path:   "/two",
+		RequestTransformers: []huma.RequestTransformer{func(ctx huma.Context, v any) (any, error) {
+			return v, nil
+		}},
+	}, handler)
+
+	body := []byte(`{
+		"id": 123,
+		"name": "Test",
+		"tags": ["one", "two", "three"],
+		"rating": 4.5,
+		"owner": {"id": 4, "name": "Alice", "email": "alice@example.com"},
+		"categories": [
+			{"name": "First", "order": 1, "visible": true},
+			{"name": "Second", "order": 2, "visible": false, "aliases": ["foo", "bar"]}
+		]
+	}`)
+
+	for _, path := range []string{"/single", "/two"} {
+		b.Run(path[1:], func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				req, _ := http.NewRequest(http.MethodPut, path, bytes.NewReader(body))
+				req.Header.Set("Content-Type", "application/json")
+				w := httptest.NewRecorder()
+				api.Adapter().ServeHTTP(w, req)
+				if w.Code != http.StatusNoContent {
+					b.Fatal(w.Body.String())
+				}
+			}
+		})
+	}
+}
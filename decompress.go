@@ -0,0 +1,91 @@
+package huma
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ContentDecoder returns a reader which decompresses a request body sent with
+// a `Content-Encoding` like `gzip`. See `Config.ContentDecoders`.
+type ContentDecoder func(r io.Reader) (io.ReadCloser, error)
+
+// GzipDecoder decompresses request bodies sent with `Content-Encoding: gzip`.
+func GzipDecoder(r io.Reader) (io.ReadCloser, error) {
+	return gzip.NewReader(r)
+}
+
+// DeflateDecoder decompresses request bodies sent with
+// `Content-Encoding: deflate`, which is the zlib format.
+func DeflateDecoder(r io.Reader) (io.ReadCloser, error) {
+	return zlib.NewReader(r)
+}
+
+// errUnsupportedEncoding is returned for content codings without a decoder.
+type errUnsupportedEncoding string
+
+func (e errUnsupportedEncoding) Error() string {
+	return fmt.Sprintf("unsupported content encoding %q", string(e))
+}
+
+// contentDecodeError wraps errors from decompressing the request body, so
+// they can be told apart from errors reading it.
+type contentDecodeError struct {
+	err error
+}
+
+func (e *contentDecodeError) Error() string {
+	return "cannot decompress request body: " + e.err.Error()
+}
+
+func (e *contentDecodeError) Unwrap() error {
+	return e.err
+}
+
+// decodingReader marks errors from a content decoder.
+type decodingReader struct {
+	io.ReadCloser
+}
+
+func (r decodingReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	if err != nil && err != io.EOF {
+		err = &contentDecodeError{err}
+	}
+	return n, err
+}
+
+// decodeContent wraps the request body reader to undo each of the codings in
+// the `Content-Encoding` header, which are listed in the order they were
+// applied. The returned closers must be closed once the body has been read.
+func decodeContent(decoders map[string]ContentDecoder, encoding string, r io.Reader) (io.Reader, []io.Closer, error) {
+	codings := strings.Split(encoding, ",")
+	closers := make([]io.Closer, 0, len(codings))
+	for i := len(codings) - 1; i >= 0; i-- {
+		coding := strings.ToLower(strings.TrimSpace(codings[i]))
+		if coding == "" || coding == "identity" {
+			continue
+		}
+		decoder := decoders[coding]
+		if decoder == nil {
+			closeAll(closers)
+			return nil, nil, errUnsupportedEncoding(coding)
+		}
+		rc, err := decoder(r)
+		if err != nil {
+			closeAll(closers)
+			return nil, nil, &contentDecodeError{err}
+		}
+		closers = append(closers, rc)
+		r = decodingReader{rc}
+	}
+	return r, closers, nil
+}
+
+func closeAll(closers []io.Closer) {
+	for _, c := range closers {
+		c.Close()
+	}
+}
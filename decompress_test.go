@@ -0,0 +1,85 @@
+package huma_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/humatest"
+	"github.com/stretchr/testify/assert"
+)
+
+func gzipBytes(t *testing.T, data string) []byte {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	_, err := w.Write([]byte(data))
+	assert.NoError(t, err)
+	assert.NoError(t, w.Close())
+	return buf.Bytes()
+}
+
+func TestRequestDecompression(t *testing.T) {
+	config := huma.DefaultConfig("Test API", "1.0.0")
+	// Custom codings like `br` or `zstd` can be added with their decoders.
+	config.ContentDecoders["x-upper"] = func(r io.Reader) (io.ReadCloser, error) {
+		b, err := io.ReadAll(r)
+		return io.NopCloser(strings.NewReader(strings.ToUpper(string(b)))), err
+	}
+	_, api := humatest.New(t, config)
+
+	huma.Register(api, huma.Operation{
+		OperationID:  "create",
+		Method:       http.MethodPost,
+		Path:         "/items",
+		MaxBodyBytes: 100,
+	}, func(ctx context.Context, input *struct {
+		Body struct {
+			Name string `json:"name"`
+		}
+	}) (*struct{ Body string }, error) {
+		return &struct{ Body string }{Body: input.Body.Name}, nil
+	})
+
+	resp := api.Post("/items", "Content-Encoding: gzip", "Content-Type: application/json", bytes.NewReader(gzipBytes(t, `{"name": "gzipped"}`)))
+	assert.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+	assert.Contains(t, resp.Body.String(), "gzipped")
+
+	var deflated bytes.Buffer
+	zw := zlib.NewWriter(&deflated)
+	zw.Write([]byte(`{"name": "deflated"}`))
+	zw.Close()
+	resp = api.Post("/items", "Content-Encoding: deflate", "Content-Type: application/json", &deflated)
+	assert.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+	assert.Contains(t, resp.Body.String(), "deflated")
+
+	// Multiple codings are undone in reverse order.
+	resp = api.Post("/items", "Content-Encoding: x-upper, gzip", "Content-Type: application/json", bytes.NewReader(gzipBytes(t, `{"name": "both"}`)))
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code, resp.Body.String())
+	assert.Contains(t, resp.Body.String(), "NAME")
+
+	resp = api.Post("/items", "Content-Encoding: br", "Content-Type: application/json", strings.NewReader(`{}`))
+	assert.Equal(t, http.StatusUnsupportedMediaType, resp.Code)
+	assert.Contains(t, resp.Body.String(), `unsupported content encoding \"br\"`)
+
+	resp = api.Post("/items", "Content-Encoding: gzip", "Content-Type: application/json", strings.NewReader(`not gzip`))
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+	assert.Contains(t, resp.Body.String(), "cannot decompress request body")
+
+	corrupt := gzipBytes(t, `{"name": "corrupt"}`)
+	corrupt = corrupt[:len(corrupt)-6]
+	resp = api.Post("/items", "Content-Encoding: gzip", "Content-Type: application/json", bytes.NewReader(corrupt))
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+	assert.Contains(t, resp.Body.String(), "cannot decompress request body")
+
+	// The size limit applies to the decompressed body.
+	large := gzipBytes(t, `{"name": "`+strings.Repeat("a", 1000)+`"}`)
+	assert.Less(t, len(large), 100)
+	resp = api.Post("/items", "Content-Encoding: gzip", "Content-Type: application/json", bytes.NewReader(large))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, resp.Code)
+}
@@ -51,10 +51,11 @@ var DefaultCBORFormat = Format{
 
 // DefaultConfig returns a default configuration for a new API. It is a good
 // starting point for creating your own configuration. It supports JSON and
-// CBOR formats out of the box. The registry uses references for structs and
-// a link transformer is included to add `$schema` fields and links into
-// responses. The `/openapi.[json|yaml]`, `/docs`, and `/schemas` paths are
-// set up to serve the OpenAPI spec, docs UI, and schemas respectively.
+// CBOR formats out of the box, and decompresses `gzip` and `deflate` request
+// bodies. The registry uses references for structs and a link transformer is
+// included to add `$schema` fields and links into responses. The
+// `/openapi.[json|yaml]`, `/docs`, and `/schemas` paths are set up to serve
+// the OpenAPI spec, docs UI, and schemas respectively.
 //
 //	// Create and customize the config (if desired).
 //	config := huma.DefaultConfig("My API", "1.0.0")
@@ -94,8 +95,13 @@ func DefaultConfig(title, version string) Config {
 			"cbor":             DefaultCBORFormat,
 		},
 		DefaultFormat: "application/json",
+		ContentDecoders: map[string]ContentDecoder{
+			"gzip":    GzipDecoder,
+			"deflate": DeflateDecoder,
+		},
 		Transformers: []Transformer{
 			linkTransformer.Transform,
 		},
+		RecoverPanics: true,
 	}
 }
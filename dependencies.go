@@ -0,0 +1,127 @@
+package huma
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// Dependencies is a simple type-keyed container of values which operation
+// handlers depend on, like database connections, clients, or loggers. Each
+// API has its own container which is created automatically if not set in the
+// config. Use `huma.Provide` to add values and `huma.Inject` to build
+// handlers which receive them.
+type Dependencies struct {
+	mu      sync.RWMutex
+	values  map[reflect.Type]any
+	version uint64
+}
+
+// NewDependencies creates a new empty dependency container.
+func NewDependencies() *Dependencies {
+	return &Dependencies{values: map[reflect.Type]any{}}
+}
+
+func (d *Dependencies) set(t reflect.Type, v any) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.values[t] = v
+	d.version++
+}
+
+func (d *Dependencies) get(t reflect.Type) (any, uint64, bool) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	v, ok := d.values[t]
+	return v, d.version, ok
+}
+
+// dependenciesOf returns the API's dependencies. It panics if the API does
+// not have any, i.e. it was not created via `huma.NewAPI` and does not
+// implement `ConfigProvider`.
+func dependenciesOf(api API) *Dependencies {
+	deps := configOf(api).Dependencies
+	if deps == nil {
+		panic("API does not support dependencies")
+	}
+	return deps
+}
+
+// Provide registers a dependency value of type `T` with the API, replacing
+// any value of the same type that was previously provided. Handlers built
+// via `huma.Inject` pick up the new value on their next request, which makes
+// it easy to swap in fakes during tests.
+//
+//	huma.Provide(api, db)
+//	huma.Provide[Store](api, &MemoryStore{})
+func Provide[T any](api API, value T) {
+	dependenciesOf(api).set(reflect.TypeOf((*T)(nil)).Elem(), value)
+}
+
+// Dependency returns the dependency value of type `T` registered with the
+// API, and whether it was found.
+//
+//	if db, ok := huma.Dependency[*sql.DB](api); ok {
+//		// ...
+//	}
+func Dependency[T any](api API) (T, bool) {
+	v, _, ok := dependenciesOf(api).get(reflect.TypeOf((*T)(nil)).Elem())
+	if !ok {
+		var zero T
+		return zero, false
+	}
+	value, _ := v.(T)
+	return value, true
+}
+
+// Inject creates an operation handler from a factory function which receives
+// the API's dependency of type `D`. The factory is called lazily on the first
+// request and again whenever the API's dependencies change, so functions
+// registering routes do not need to close over global variables. If the
+// dependency was never provided, the handler returns an error.
+//
+//	type ItemsStore interface {
+//		Get(ctx context.Context, id string) (*Item, error)
+//	}
+//
+//	func RegisterRoutes(api huma.API) {
+//		huma.Register(api, huma.Operation{
+//			OperationID: "get-item",
+//			Method:      http.MethodGet,
+//			Path:        "/items/{id}",
+//		}, huma.Inject(api, func(store ItemsStore) func(context.Context, *GetItemInput) (*GetItemOutput, error) {
+//			return func(ctx context.Context, input *GetItemInput) (*GetItemOutput, error) {
+//				item, err := store.Get(ctx, input.ID)
+//				// ...
+//			}
+//		}))
+//	}
+//
+//	huma.Provide[ItemsStore](api, NewDBStore(db))
+func Inject[D, I, O any](api API, factory func(D) func(context.Context, *I) (*O, error)) func(context.Context, *I) (*O, error) {
+	deps := dependenciesOf(api)
+	t := reflect.TypeOf((*D)(nil)).Elem()
+
+	var mu sync.Mutex
+	var built func(context.Context, *I) (*O, error)
+	var builtVersion uint64
+
+	return func(ctx context.Context, input *I) (*O, error) {
+		v, version, ok := deps.get(t)
+		if !ok {
+			return nil, fmt.Errorf("no dependency of type %s provided", t)
+		}
+
+		mu.Lock()
+		if built == nil || builtVersion != version {
+			d, _ := v.(D)
+			built = factory(d)
+			builtVersion = version
+		}
+		h := built
+		mu.Unlock()
+
+		return h(ctx, input)
+	}
+}
@@ -0,0 +1,88 @@
+package huma_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/humatest"
+	"github.com/stretchr/testify/assert"
+)
+
+type GreetingStore interface {
+	Greeting(name string) string
+}
+
+type staticGreetingStore string
+
+func (s staticGreetingStore) Greeting(name string) string {
+	return string(s) + ", " + name + "!"
+}
+
+type DIGreetingOutput struct {
+	Body struct {
+		Message string `json:"message"`
+	}
+}
+
+func registerGreeting(api huma.API) {
+	huma.Register(api, huma.Operation{
+		OperationID: "get-greeting",
+		Method:      http.MethodGet,
+		Path:        "/greeting/{name}",
+	}, huma.Inject(api, func(store GreetingStore) func(context.Context, *struct {
+		Name string `path:"name"`
+	}) (*DIGreetingOutput, error) {
+		return func(ctx context.Context, input *struct {
+			Name string `path:"name"`
+		}) (*DIGreetingOutput, error) {
+			resp := &DIGreetingOutput{}
+			resp.Body.Message = store.Greeting(input.Name)
+			return resp, nil
+		}
+	}))
+}
+
+func TestInject(t *testing.T) {
+	_, api := humatest.New(t)
+	registerGreeting(api)
+
+	// Missing dependency should result in a server error.
+	resp := api.Get("/greeting/bob")
+	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+
+	huma.Provide[GreetingStore](api, staticGreetingStore("Hello"))
+	resp = api.Get("/greeting/bob")
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Contains(t, resp.Body.String(), "Hello, bob!")
+
+	// Swap the dependency, e.g. for a test fake.
+	huma.Provide[GreetingStore](api, staticGreetingStore("Hi"))
+	resp = api.Get("/greeting/bob")
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Contains(t, resp.Body.String(), "Hi, bob!")
+}
+
+func TestDependency(t *testing.T) {
+	_, api := humatest.New(t)
+
+	_, ok := huma.Dependency[GreetingStore](api)
+	assert.False(t, ok)
+
+	huma.Provide[GreetingStore](api, staticGreetingStore("Hello"))
+	store, ok := huma.Dependency[GreetingStore](api)
+	assert.True(t, ok)
+	assert.Equal(t, "Hello, alice!", store.Greeting("alice"))
+
+	// Dependencies are shared by all APIs using the same container.
+	deps := huma.NewDependencies()
+	config := huma.DefaultConfig("Test", "1.0.0")
+	config.Dependencies = deps
+	_, api1 := humatest.New(t, config)
+	_, api2 := humatest.New(t, config)
+	huma.Provide(api1, 42)
+	v, ok := huma.Dependency[int](api2)
+	assert.True(t, ok)
+	assert.Equal(t, 42, v)
+}
@@ -0,0 +1,364 @@
+// Package digest implements the `Content-Digest` header (RFC 9530) for Huma
+// APIs. Request bodies sent with a `Content-Digest` header are checked
+// against it, and responses can include a digest of their body so that
+// clients can detect corruption or, combined with message signatures,
+// tampering.
+//
+//	digest.Use(api, digest.Config{Response: true})
+//
+// Operations may override the API's configuration via their metadata:
+//
+//	huma.Register(api, huma.Operation{
+//		OperationID: "upload-file",
+//		Method:      http.MethodPut,
+//		Path:        "/files/{name}",
+//		Metadata: map[string]any{
+//			digest.MetadataKey: digest.Config{Required: true},
+//		},
+//	}, handler)
+//
+// See https://www.rfc-editor.org/rfc/rfc9530
+package digest
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"hash"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// MetadataKey is the operation metadata key used to override the API's
+// `Config` for an operation. Set it to a `Config`, or to `false` to disable
+// digests for the operation.
+const MetadataKey = "digest"
+
+// Header names.
+const (
+	Header     = "Content-Digest"
+	WantHeader = "Want-Content-Digest"
+)
+
+// Supported digest algorithms.
+const (
+	SHA256 = "sha-256"
+	SHA512 = "sha-512"
+)
+
+var algorithms = map[string]func() hash.Hash{
+	SHA256: sha256.New,
+	SHA512: sha512.New,
+}
+
+// Config configures digests for an API or operation.
+type Config struct {
+	// Required rejects requests with a body but no `Content-Digest` header
+	// with a `400 Bad Request` error. Digests are always checked when sent.
+	Required bool
+
+	// Response adds a `Content-Digest` header to response bodies. Clients may
+	// also ask for one via the `Want-Content-Digest` header.
+	Response bool
+
+	// Algorithm is the algorithm used for response digests when the client
+	// does not ask for one. Defaults to `sha-256`.
+	Algorithm string
+}
+
+// forOperation returns the config for an operation, or false if digests are
+// disabled for it.
+func (c Config) forOperation(op *huma.Operation) (Config, bool) {
+	switch v := op.Metadata[MetadataKey].(type) {
+	case Config:
+		if v.Algorithm == "" {
+			v.Algorithm = c.Algorithm
+		}
+		return v, true
+	case bool:
+		return c, v
+	}
+	return c, true
+}
+
+// entry is a member of a `Content-Digest` or `Want-Content-Digest` dictionary.
+type entry struct {
+	key   string
+	value string
+}
+
+// parseDictionary splits a structured field dictionary into its members,
+// ignoring any parameters. Values are returned unparsed.
+func parseDictionary(s string) []entry {
+	var entries []entry
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if i := strings.IndexByte(part, ';'); i != -1 {
+			part = part[:i]
+		}
+		if part == "" {
+			continue
+		}
+		key, value, _ := strings.Cut(part, "=")
+		entries = append(entries, entry{strings.ToLower(strings.TrimSpace(key)), strings.TrimSpace(value)})
+	}
+	return entries
+}
+
+// sum returns the `Content-Digest` header value for a body.
+func sum(alg string, body []byte) string {
+	h := algorithms[alg]()
+	h.Write(body)
+	return alg + "=:" + base64.StdEncoding.EncodeToString(h.Sum(nil)) + ":"
+}
+
+// check verifies a body against a `Content-Digest` header value. Digests
+// using unsupported algorithms are ignored, but at least one must be
+// supported.
+func check(header string, body []byte) error {
+	checked := false
+	for _, e := range parseDictionary(header) {
+		newHash := algorithms[e.key]
+		if newHash == nil {
+			continue
+		}
+		if len(e.value) < 2 || e.value[0] != ':' || e.value[len(e.value)-1] != ':' {
+			return &huma.ErrorDetail{Message: "invalid " + e.key + " digest", Location: "header." + Header, Value: header}
+		}
+		expected, err := base64.StdEncoding.DecodeString(e.value[1 : len(e.value)-1])
+		if err != nil {
+			return &huma.ErrorDetail{Message: "invalid " + e.key + " digest", Location: "header." + Header, Value: header}
+		}
+		h := newHash()
+		h.Write(body)
+		if !bytes.Equal(h.Sum(nil), expected) {
+			return &huma.ErrorDetail{Message: e.key + " digest does not match the body", Location: "header." + Header, Value: header}
+		}
+		checked = true
+	}
+	if !checked {
+		return &huma.ErrorDetail{Message: "expected a sha-256 or sha-512 digest", Location: "header." + Header, Value: header}
+	}
+	return nil
+}
+
+// wanted returns the algorithm the client prefers for the response digest
+// from a `Want-Content-Digest` header, or an empty string if none of the
+// supported algorithms were requested.
+func wanted(header string) string {
+	best, bestPref := "", 0
+	for _, e := range parseDictionary(header) {
+		pref, err := strconv.Atoi(e.value)
+		if err != nil || pref <= 0 || algorithms[e.key] == nil {
+			continue
+		}
+		if pref > bestPref {
+			best, bestPref = e.key, pref
+		}
+	}
+	return best
+}
+
+// digestContext replays the verified request body, and buffers the response
+// body so its digest can be sent before it.
+type digestContext struct {
+	huma.ContextWrapper
+	body     io.Reader
+	alg      string
+	status   int
+	buf      bytes.Buffer
+	streamed bool
+}
+
+func (c *digestContext) BodyReader() io.Reader {
+	if c.body != nil {
+		return c.body
+	}
+	return c.ContextWrapper.BodyReader()
+}
+
+// SetStatus is delayed until the body has been written, unless the response
+// is not being digested.
+func (c *digestContext) SetStatus(code int) {
+	if c.alg == "" {
+		c.ContextWrapper.SetStatus(code)
+		return
+	}
+	c.status = code
+}
+
+func (c *digestContext) BodyWriter() io.Writer {
+	if c.alg == "" || c.streamed {
+		return c.ContextWrapper.BodyWriter()
+	}
+	return &c.buf
+}
+
+// StreamBody passes streamed responses through without a digest, as they
+// may be arbitrarily large or long-lived.
+func (c *digestContext) StreamBody(cb func(w io.Writer, flush func() error)) {
+	c.flush()
+	c.streamed = true
+	if sc, ok := c.Unwrap().(huma.StreamingContext); ok {
+		sc.StreamBody(cb)
+		return
+	}
+	w := c.ContextWrapper.BodyWriter()
+	cb(w, func() error {
+		if f, ok := w.(http.Flusher); ok {
+			f.Flush()
+			return nil
+		}
+		return http.ErrNotSupported
+	})
+}
+
+// flush writes the delayed status and any buffered body, adding the digest
+// header if the response has a body.
+func (c *digestContext) flush() {
+	if c.alg == "" || c.streamed {
+		return
+	}
+	alg := c.alg
+	c.alg = ""
+	if c.status != 0 {
+		if c.status != http.StatusNoContent && c.status != http.StatusNotModified && c.Method() != http.MethodHead {
+			c.ContextWrapper.SetHeader(Header, sum(alg, c.buf.Bytes()))
+		}
+		c.ContextWrapper.SetStatus(c.status)
+	}
+	if c.buf.Len() > 0 {
+		c.ContextWrapper.BodyWriter().Write(c.buf.Bytes())
+	}
+}
+
+// Use adds `Content-Digest` middleware to the API and documents the header
+// in the OpenAPI. Like other middleware, it must be called before
+// registering operations with `huma.Register` in order to apply to them.
+//
+// Requests with a `Content-Digest` header which does not match the body
+// receive a `400 Bad Request` error. To include the digest in response
+// signatures, call `Use` after adding the `httpsig` middleware.
+func Use(api huma.API, config Config) {
+	if config.Algorithm == "" {
+		config.Algorithm = SHA256
+	}
+	if algorithms[config.Algorithm] == nil {
+		panic("digest: unsupported algorithm " + config.Algorithm)
+	}
+
+	oapi := api.OpenAPI()
+	oapi.OnAddOperation = append(oapi.OnAddOperation, config.document)
+
+	api.UseMiddleware(func(ctx huma.Context, next func(huma.Context)) {
+		op := ctx.Operation()
+		c, ok := config.forOperation(op)
+		if !ok {
+			next(ctx)
+			return
+		}
+
+		dc := &digestContext{ContextWrapper: huma.WrapContext(ctx)}
+
+		header := ctx.Header(Header)
+		if header != "" || c.Required {
+			// Buffer the body, limited in the same way as the operation will
+			// limit it. Bodies over the limit are rejected by the operation.
+			limit := op.MaxBodyBytes
+			if limit == 0 {
+				limit = 1024 * 1024
+			}
+			var body []byte
+			if limit > 0 {
+				body, _ = io.ReadAll(io.LimitReader(ctx.BodyReader(), limit+1))
+			} else {
+				body, _ = io.ReadAll(ctx.BodyReader())
+			}
+			dc.body = bytes.NewReader(body)
+
+			if header == "" && len(body) > 0 {
+				huma.WriteErr(api, ctx, http.StatusBadRequest, "the "+Header+" header is required", &huma.ErrorDetail{
+					Message:  "required header parameter is missing",
+					Location: "header." + Header,
+				})
+				return
+			}
+			if header != "" && (limit <= 0 || int64(len(body)) <= limit) {
+				if err := check(header, body); err != nil {
+					huma.WriteErr(api, ctx, http.StatusBadRequest, "invalid "+Header+" header", err)
+					return
+				}
+			}
+		}
+
+		if alg := wanted(ctx.Header(WantHeader)); alg != "" {
+			dc.alg = alg
+		} else if c.Response {
+			dc.alg = c.Algorithm
+		}
+
+		next(dc)
+		dc.flush()
+	})
+}
+
+// document adds the `Content-Digest` header to the OpenAPI for operations
+// with a request body or a response digest.
+func (c Config) document(oapi *huma.OpenAPI, op *huma.Operation) {
+	config, ok := c.forOperation(op)
+	if !ok {
+		return
+	}
+	if algorithms[config.Algorithm] == nil {
+		panic("digest: unsupported algorithm " + config.Algorithm)
+	}
+
+	if op.RequestBody != nil {
+		documented := false
+		for _, p := range op.Parameters {
+			if p.In == "header" && strings.EqualFold(p.Name, Header) {
+				documented = true
+			}
+		}
+		if !documented {
+			op.Parameters = append(op.Parameters, &huma.Param{
+				Name:        Header,
+				In:          "header",
+				Description: "Digest of the request body, e.g. `sha-256=:base64:`. Requests whose body does not match are rejected.",
+				Required:    config.Required,
+				Schema:      &huma.Schema{Type: huma.TypeString},
+			})
+		}
+		if op.Responses == nil {
+			op.Responses = map[string]*huma.Response{}
+		}
+		code := strconv.Itoa(http.StatusBadRequest)
+		if op.Responses[code] == nil {
+			op.Responses[code] = &huma.Response{
+				Description: http.StatusText(http.StatusBadRequest),
+				Content:     huma.ErrorContent(op),
+			}
+		}
+	}
+
+	if !config.Response {
+		return
+	}
+	for code, resp := range op.Responses {
+		if resp.Ref != "" || resp.Content == nil || strings.HasPrefix(code, "4") || strings.HasPrefix(code, "5") || code == "default" {
+			continue
+		}
+		if resp.Headers == nil {
+			resp.Headers = map[string]*huma.Param{}
+		}
+		resp.Headers[Header] = &huma.Param{
+			Description: "Digest of the response body.",
+			Schema:      &huma.Schema{Type: huma.TypeString},
+		}
+	}
+}
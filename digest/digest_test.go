@@ -0,0 +1,141 @@
+package digest
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/humatest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type ItemInput struct {
+	Body struct {
+		Name string `json:"name"`
+	}
+}
+
+type ItemOutput struct {
+	Body struct {
+		Name string `json:"name"`
+	}
+}
+
+func setup(t *testing.T, config Config, metadata any) humatest.TestAPI {
+	_, api := humatest.New(t)
+	Use(api, config)
+
+	op := huma.Operation{
+		OperationID: "create-item",
+		Method:      http.MethodPost,
+		Path:        "/items",
+	}
+	if metadata != nil {
+		op.Metadata = map[string]any{MetadataKey: metadata}
+	}
+	huma.Register(api, op, func(ctx context.Context, input *ItemInput) (*ItemOutput, error) {
+		resp := &ItemOutput{}
+		resp.Body.Name = input.Body.Name
+		return resp, nil
+	})
+
+	return api
+}
+
+const body = `{"name": "apple"}`
+
+func sha256Header(b string) string {
+	sum := sha256.Sum256([]byte(b))
+	return "sha-256=:" + base64.StdEncoding.EncodeToString(sum[:]) + ":"
+}
+
+func TestRequestDigest(t *testing.T) {
+	api := setup(t, Config{}, nil)
+
+	for name, tc := range map[string]struct {
+		header string
+		status int
+	}{
+		"none":        {"", http.StatusOK},
+		"valid":       {sha256Header(body), http.StatusOK},
+		"multiple":    {"md5=:abc:, " + sha256Header(body), http.StatusOK},
+		"mismatch":    {sha256Header(`{"name": "pear"}`), http.StatusBadRequest},
+		"unsupported": {"md5=:abc:", http.StatusBadRequest},
+		"malformed":   {"sha-256=abc", http.StatusBadRequest},
+	} {
+		t.Run(name, func(t *testing.T) {
+			args := []any{"Content-Type: application/json", strings.NewReader(body)}
+			if tc.header != "" {
+				args = append(args, Header+": "+tc.header)
+			}
+			resp := api.Post("/items", args...)
+			assert.Equal(t, tc.status, resp.Code, resp.Body.String())
+			if tc.status == http.StatusOK {
+				assert.JSONEq(t, `{"name": "apple"}`, resp.Body.String())
+			}
+		})
+	}
+}
+
+func TestRequired(t *testing.T) {
+	api := setup(t, Config{}, Config{Required: true})
+
+	resp := api.Post("/items", "Content-Type: application/json", strings.NewReader(body))
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+	assert.Contains(t, resp.Body.String(), "header."+Header)
+
+	resp = api.Post("/items", "Content-Type: application/json", Header+": "+sha256Header(body), strings.NewReader(body))
+	assert.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+}
+
+func TestResponseDigest(t *testing.T) {
+	api := setup(t, Config{Response: true}, nil)
+
+	resp := api.Post("/items", map[string]any{"name": "apple"})
+	require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+	assert.Equal(t, sha256Header(resp.Body.String()), resp.Header().Get(Header))
+
+	// Clients can ask for a specific algorithm.
+	resp = api.Post("/items", WantHeader+": sha-256=1, sha-512=3", map[string]any{"name": "apple"})
+	sum := sha512.Sum512(resp.Body.Bytes())
+	assert.Equal(t, "sha-512=:"+base64.StdEncoding.EncodeToString(sum[:])+":", resp.Header().Get(Header))
+
+	// Error responses are digested too.
+	resp = api.Post("/items", "Content-Type: application/json", strings.NewReader("{"))
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+	assert.Equal(t, sha256Header(resp.Body.String()), resp.Header().Get(Header))
+
+	// Operations can opt out.
+	api = setup(t, Config{Response: true}, false)
+	resp = api.Post("/items", WantHeader+": sha-256=1", map[string]any{"name": "apple"})
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Empty(t, resp.Header().Get(Header))
+
+	// Responses are only digested when configured or asked for.
+	api = setup(t, Config{}, nil)
+	resp = api.Post("/items", map[string]any{"name": "apple"})
+	assert.Empty(t, resp.Header().Get(Header))
+	resp = api.Post("/items", WantHeader+": sha-256=1", map[string]any{"name": "apple"})
+	assert.Equal(t, sha256Header(resp.Body.String()), resp.Header().Get(Header))
+}
+
+func TestOpenAPI(t *testing.T) {
+	api := setup(t, Config{}, Config{Required: true, Response: true})
+
+	op := api.OpenAPI().Paths["/items"].Post
+	require.Len(t, op.Parameters, 1)
+	assert.Equal(t, Header, op.Parameters[0].Name)
+	assert.True(t, op.Parameters[0].Required)
+	assert.NotNil(t, op.Responses["400"])
+	assert.NotNil(t, op.Responses["200"].Headers[Header])
+
+	assert.Panics(t, func() {
+		setup(t, Config{}, Config{Algorithm: "md5"})
+	})
+}
@@ -0,0 +1,120 @@
+package huma
+
+import (
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+var fileDownloadType = reflect.TypeOf(FileDownload{})
+
+// FileDownload is an output body which streams a file to the client from a
+// reader, setting the `Content-Type`, `Content-Length`, and
+// `Content-Disposition` headers from its fields. It is documented as a
+// binary response, using the body field's `contentType` tag as the media
+// type if set. The reader is closed after it has been sent if it implements
+// `io.Closer`.
+//
+//	type ReportOutput struct {
+//		Body huma.FileDownload `contentType:"application/pdf"`
+//	}
+//
+//	func handler(ctx context.Context, input *struct{}) (*ReportOutput, error) {
+//		f, err := os.Open("report.pdf")
+//		if err != nil {
+//			return nil, err
+//		}
+//		info, _ := f.Stat()
+//		return &ReportOutput{Body: huma.FileDownload{
+//			Filename:    "Quarterly report.pdf",
+//			ContentType: "application/pdf",
+//			Size:        info.Size(),
+//			Reader:      f,
+//		}}, nil
+//	}
+type FileDownload struct {
+	// Filename is suggested to the client when saving the file. Non-ASCII
+	// names are sent using RFC 5987 encoding along with an ASCII fallback.
+	// If empty, no `Content-Disposition` header is sent.
+	Filename string
+
+	// Inline asks the client to display the file, e.g. a PDF in the browser,
+	// rather than downloading it.
+	Inline bool
+
+	// ContentType of the file. Defaults to `application/octet-stream` unless
+	// the output sets a `Content-Type` header.
+	ContentType string
+
+	// Size of the file in bytes, sent as the `Content-Length` if positive.
+	Size int64
+
+	// Reader provides the file contents.
+	Reader io.Reader
+}
+
+// write sends the file with the given status. The content type is any
+// already set by the output headers.
+func (d *FileDownload) write(ctx Context, status int, ct string) {
+	if c, ok := d.Reader.(io.Closer); ok {
+		defer c.Close()
+	}
+
+	if d.ContentType != "" {
+		ctx.SetHeader("Content-Type", d.ContentType)
+	} else if ct == "" {
+		ctx.SetHeader("Content-Type", "application/octet-stream")
+	}
+	if d.Filename != "" {
+		disposition := "attachment"
+		if d.Inline {
+			disposition = "inline"
+		}
+		ctx.SetHeader("Content-Disposition", contentDisposition(disposition, d.Filename))
+	}
+	if d.Size > 0 {
+		ctx.SetHeader("Content-Length", strconv.FormatInt(d.Size, 10))
+	}
+	ctx.SetStatus(status)
+
+	if d.Reader != nil {
+		io.Copy(ctx.BodyWriter(), d.Reader)
+	}
+}
+
+// contentDisposition returns a `Content-Disposition` header value for the
+// filename. Names which aren't plain ASCII get an ASCII fallback for older
+// clients, and the full name in the RFC 5987 `filename*` parameter.
+func contentDisposition(disposition, filename string) string {
+	fallback := strings.Map(func(r rune) rune {
+		if r < 0x20 || r > 0x7e || r == '"' || r == '\\' || r == '%' {
+			return '_'
+		}
+		return r
+	}, filename)
+
+	value := disposition + `; filename="` + fallback + `"`
+	if fallback != filename {
+		value += "; filename*=UTF-8''" + rfc5987Escape(filename)
+	}
+	return value
+}
+
+// rfc5987Escape percent-encodes all bytes of `s` except the RFC 5987
+// `attr-char` set.
+func rfc5987Escape(s string) string {
+	const hex = "0123456789ABCDEF"
+	sb := strings.Builder{}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || ('0' <= c && c <= '9') || strings.IndexByte("!#$&+-.^_`|~", c) >= 0 {
+			sb.WriteByte(c)
+			continue
+		}
+		sb.WriteByte('%')
+		sb.WriteByte(hex[c>>4])
+		sb.WriteByte(hex[c&15])
+	}
+	return sb.String()
+}
@@ -0,0 +1,74 @@
+package huma_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/humatest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// closingReader records whether it was closed.
+type closingReader struct {
+	io.Reader
+	closed bool
+}
+
+func (r *closingReader) Close() error {
+	r.closed = true
+	return nil
+}
+
+func TestFileDownload(t *testing.T) {
+	_, api := humatest.New(t, huma.DefaultConfig("Test API", "1.0.0"))
+
+	var reader *closingReader
+	huma.Register(api, huma.Operation{
+		OperationID: "download",
+		Method:      http.MethodGet,
+		Path:        "/files/{name}",
+	}, func(ctx context.Context, input *struct {
+		Name   string `path:"name"`
+		Inline bool   `query:"inline"`
+	}) (*struct {
+		Body huma.FileDownload `contentType:"text/plain"`
+	}, error) {
+		reader = &closingReader{Reader: strings.NewReader("hello")}
+		out := &struct {
+			Body huma.FileDownload `contentType:"text/plain"`
+		}{}
+		out.Body = huma.FileDownload{
+			Filename: input.Name,
+			Inline:   input.Inline,
+			Size:     5,
+			Reader:   reader,
+		}
+		if strings.HasSuffix(input.Name, ".txt") {
+			out.Body.ContentType = "text/plain"
+		}
+		return out, nil
+	})
+
+	resp := api.Get("/files/report.txt")
+	require.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, "hello", resp.Body.String())
+	assert.Equal(t, "text/plain", resp.Header().Get("Content-Type"))
+	assert.Equal(t, "5", resp.Header().Get("Content-Length"))
+	assert.Equal(t, `attachment; filename="report.txt"`, resp.Header().Get("Content-Disposition"))
+	assert.True(t, reader.closed)
+
+	resp = api.Get("/files/r%C3%A9sum%C3%A9%20%221%22?inline=true")
+	assert.Equal(t, "application/octet-stream", resp.Header().Get("Content-Type"))
+	assert.Equal(t, `inline; filename="r_sum_ _1_"; filename*=UTF-8''r%C3%A9sum%C3%A9%20%221%22`, resp.Header().Get("Content-Disposition"))
+
+	// The response is documented as a binary file.
+	get := api.OpenAPI().Paths["/files/{name}"].Get
+	schema := get.Responses["200"].Content["text/plain"].Schema
+	assert.Equal(t, "binary", schema.Format)
+	assert.Contains(t, get.Responses["200"].Headers, "Content-Disposition")
+}
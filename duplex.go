@@ -0,0 +1,41 @@
+package huma
+
+import "net/http"
+
+// FullDuplexContext may be implemented by adapter contexts which can keep
+// reading the request body after the response has started.
+type FullDuplexContext interface {
+	EnableFullDuplex() error
+}
+
+// FullDuplex allows the request body to be read after the response has
+// started being written, for streaming endpoints which interleave the two.
+// Go's HTTP/1.x server otherwise stops reading the request body once the
+// response is written to, while HTTP/2 is always full-duplex. If the adapter
+// does not support it then `http.ErrNotSupported` is returned. Contexts
+// wrapped by middleware are searched via `ContextUnwrapper`. See also
+// `Operation.FullDuplex`.
+func FullDuplex(ctx Context) error {
+	if fc, ok := findContext[FullDuplexContext](ctx); ok {
+		return fc.EnableFullDuplex()
+	}
+	return http.ErrNotSupported
+}
+
+// EnableFullDuplex is a utility to enable full-duplex mode on a response
+// writer, if possible, like `http.ResponseController` but without its
+// allocation. This is mostly a convenience function for adapters.
+//
+//	huma.EnableFullDuplex(w)
+func EnableFullDuplex(w http.ResponseWriter) error {
+	for {
+		switch t := w.(type) {
+		case interface{ EnableFullDuplex() error }:
+			return t.EnableFullDuplex()
+		case interface{ Unwrap() http.ResponseWriter }:
+			w = t.Unwrap()
+		default:
+			return http.ErrNotSupported
+		}
+	}
+}
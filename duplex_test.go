@@ -0,0 +1,131 @@
+package huma_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/humatest"
+	"github.com/stretchr/testify/assert"
+)
+
+// trackingReader records whether the request body was read.
+type trackingReader struct {
+	io.Reader
+	read bool
+}
+
+func (r *trackingReader) Read(p []byte) (int, error) {
+	r.read = true
+	return r.Reader.Read(p)
+}
+
+func TestExpectContinue(t *testing.T) {
+	_, api := humatest.New(t, huma.DefaultConfig("Test API", "1.0.0"))
+
+	huma.Register(api, huma.Operation{
+		OperationID: "upload",
+		Method:      http.MethodPut,
+		Path:        "/upload",
+	}, func(ctx context.Context, input *struct {
+		Name    string `query:"name" minLength:"3"`
+		RawBody []byte
+	}) (*struct{}, error) {
+		return nil, nil
+	})
+
+	// Requests are sent to the adapter directly, since `humatest` reads the
+	// body to log the request.
+	put := func(url string, body io.Reader) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPut, url, body)
+		req.Header.Set("Expect", "100-continue")
+		w := httptest.NewRecorder()
+		api.Adapter().ServeHTTP(w, req)
+		return w
+	}
+
+	// Invalid params reject the request without reading the body, so the
+	// client is never told to continue.
+	body := &trackingReader{Reader: strings.NewReader("data")}
+	resp := put("/upload?name=a", body)
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+	assert.Contains(t, resp.Body.String(), "query.name")
+	assert.False(t, body.read)
+
+	body = &trackingReader{Reader: strings.NewReader("data")}
+	resp = put("/upload?name=abc", body)
+	assert.Equal(t, http.StatusNoContent, resp.Code, resp.Body.String())
+	assert.True(t, body.read)
+}
+
+func TestFullDuplexOperation(t *testing.T) {
+	_, api := humatest.New(t, huma.DefaultConfig("Test API", "1.0.0"))
+
+	huma.Register(api, huma.Operation{
+		OperationID: "echo",
+		Method:      http.MethodPost,
+		Path:        "/echo",
+		FullDuplex:  true,
+	}, func(ctx context.Context, input *struct{}) (*huma.StreamResponse, error) {
+		return &huma.StreamResponse{
+			Body: func(ctx huma.Context) {
+				ctx.SetHeader("Content-Type", "text/plain")
+				ctx.SetStatus(http.StatusOK)
+				w := ctx.BodyWriter()
+				w.Write([]byte("echo: "))
+				io.Copy(w, ctx.BodyReader())
+			},
+		}, nil
+	})
+
+	resp := api.Post("/echo", strings.NewReader("hello"))
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, "echo: hello", resp.Body.String())
+}
+
+type duplexWriter struct {
+	http.ResponseWriter
+	enabled bool
+}
+
+func (w *duplexWriter) EnableFullDuplex() error {
+	w.enabled = true
+	return nil
+}
+
+type wrappedWriter struct {
+	http.ResponseWriter
+}
+
+func (w *wrappedWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func TestEnableFullDuplex(t *testing.T) {
+	w := &duplexWriter{ResponseWriter: httptest.NewRecorder()}
+	assert.NoError(t, huma.EnableFullDuplex(&wrappedWriter{w}))
+	assert.True(t, w.enabled)
+
+	assert.ErrorIs(t, huma.EnableFullDuplex(httptest.NewRecorder()), http.ErrNotSupported)
+	assert.ErrorIs(t, huma.FullDuplex(huma.WrapContext(nil)), http.ErrNotSupported)
+}
+
+type duplexContext struct {
+	huma.ContextWrapper
+	enabled bool
+}
+
+func (c *duplexContext) EnableFullDuplex() error {
+	c.enabled = true
+	return nil
+}
+
+func TestFullDuplexUnwrap(t *testing.T) {
+	dc := &duplexContext{}
+	assert.NoError(t, huma.FullDuplex(huma.WrapContext(dc)))
+	assert.True(t, dc.enabled)
+}
@@ -0,0 +1,54 @@
+package huma
+
+import "net/http"
+
+// EarlyHintsContext may be implemented by adapter contexts which can send
+// `103 Early Hints` informational responses before the final response.
+type EarlyHintsContext interface {
+	WriteEarlyHints(links []string) error
+}
+
+// EarlyHints sends a `103 Early Hints` informational response with the given
+// `Link` header values, letting browsers start loading resources like
+// stylesheets or fonts while the final response is still being prepared. It
+// must be called before the response status is set. If the adapter does not
+// support informational responses then `http.ErrNotSupported` is returned
+// and the request can continue as normal. Contexts wrapped by middleware are
+// searched via `ContextUnwrapper`.
+//
+//	huma.EarlyHints(ctx,
+//		huma.Preload("/static/site.css", "style"),
+//		huma.Preload("/static/app.js", "script"),
+//	)
+func EarlyHints(ctx Context, links ...string) error {
+	if ec, ok := findContext[EarlyHintsContext](ctx); ok {
+		return ec.WriteEarlyHints(links)
+	}
+	return http.ErrNotSupported
+}
+
+// WriteEarlyHints writes a `103 Early Hints` informational response with the
+// given `Link` header values to a response writer. The links are also kept
+// for the final response, as recommended by RFC 8297. This is mostly a
+// convenience function for adapters based on `net/http`.
+func WriteEarlyHints(w http.ResponseWriter, links []string) error {
+	if len(links) == 0 {
+		return nil
+	}
+	h := w.Header()
+	for _, link := range links {
+		h.Add("Link", link)
+	}
+	w.WriteHeader(http.StatusEarlyHints)
+	return nil
+}
+
+// Preload returns a `Link` header value asking the client to preload the
+// resource at `url`, where `as` is the kind of resource, e.g. `style`,
+// `script`, `font`, or `image`.
+//
+//	huma.Preload("/static/site.css", "style")
+//	// </static/site.css>; rel=preload; as=style
+func Preload(url, as string) string {
+	return "<" + url + ">; rel=preload; as=" + as
+}
@@ -0,0 +1,84 @@
+package huma_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httptrace"
+	"net/textproto"
+	"testing"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/humatest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEarlyHints(t *testing.T) {
+	config := huma.DefaultConfig("Test API", "1.0.0")
+	// Wrapped contexts must pass early hints through to the adapter.
+	config.RecoverPanics = true
+	config.ServerTiming = true
+	config.RequestIDHeader = "X-Request-ID"
+	router, api := humatest.New(t, config)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "page",
+		Method:      http.MethodGet,
+		Path:        "/page",
+	}, func(ctx context.Context, input *struct{}) (*huma.StreamResponse, error) {
+		return &huma.StreamResponse{
+			Body: func(ctx huma.Context) {
+				assert.NoError(t, huma.EarlyHints(ctx, huma.Preload("/site.css", "style")))
+				ctx.SetHeader("Content-Type", "text/plain")
+				ctx.SetStatus(http.StatusOK)
+				ctx.BodyWriter().Write([]byte("hello"))
+			},
+		}, nil
+	})
+
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	var hints []http.Header
+	trace := &httptrace.ClientTrace{
+		Got1xxResponse: func(code int, header textproto.MIMEHeader) error {
+			if code == http.StatusEarlyHints {
+				hints = append(hints, http.Header(header))
+			}
+			return nil
+		},
+	}
+	req, err := http.NewRequestWithContext(httptrace.WithClientTrace(context.Background(), trace), http.MethodGet, server.URL+"/page", nil)
+	require.NoError(t, err)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Len(t, hints, 1)
+	assert.Equal(t, "</site.css>; rel=preload; as=style", hints[0].Get("Link"))
+	assert.Equal(t, "</site.css>; rel=preload; as=style", resp.Header.Get("Link"))
+}
+
+func TestEarlyHintsUnsupported(t *testing.T) {
+	var ctx huma.Context = huma.WrapContext(nil)
+	assert.ErrorIs(t, huma.EarlyHints(ctx, huma.Preload("/site.css", "style")), http.ErrNotSupported)
+}
+
+type hintsContext struct {
+	huma.ContextWrapper
+	links []string
+}
+
+func (c *hintsContext) WriteEarlyHints(links []string) error {
+	c.links = append(c.links, links...)
+	return nil
+}
+
+func TestEarlyHintsUnwrap(t *testing.T) {
+	hc := &hintsContext{}
+	var ctx huma.Context = huma.WrapContext(huma.WrapContext(hc))
+	assert.NoError(t, huma.EarlyHints(ctx, huma.Preload("/site.css", "style")))
+	assert.Equal(t, []string{"</site.css>; rel=preload; as=style"}, hc.links)
+}
@@ -0,0 +1,100 @@
+package huma
+
+import "reflect"
+
+// fieldAt returns the struct type containing the field at `path` within `t`,
+// and the field itself. Slices and maps along the path are looked through, as their
+// items don't add to the path.
+func fieldAt(t reflect.Type, path []int) (reflect.Type, reflect.StructField) {
+	parent := deref(t)
+	for _, i := range path[:len(path)-1] {
+		parent = deref(parent.Field(i).Type)
+		for parent.Kind() == reflect.Slice || parent.Kind() == reflect.Map {
+			parent = deref(parent.Elem())
+		}
+	}
+	return parent, parent.Field(path[len(path)-1])
+}
+
+// shadowed returns whether the field at `path` within `t` is promoted from an
+// embedded struct but hidden by a field of the same name at a shallower
+// depth, following Go's rules for selectors. For example, an input may embed
+// a shared group of params and replace one of them with its own field.
+func shadowed(t reflect.Type, path []int) bool {
+	// Find the struct the field is promoted into, i.e. the last struct along
+	// the path which isn't embedded in its parent.
+	owner, start := deref(t), 0
+	current := owner
+	for i, index := range path[:len(path)-1] {
+		f := current.Field(index)
+		current = deref(f.Type)
+		if current.Kind() != reflect.Struct {
+			return false
+		}
+		if !f.Anonymous {
+			owner, start = current, i+1
+		}
+	}
+	if start == len(path)-1 {
+		// Not promoted from an embedded struct.
+		return false
+	}
+
+	// Ambiguous fields at the same depth are not found by name, but are all
+	// kept as they can still be set by index.
+	f, ok := owner.FieldByName(current.Field(path[len(path)-1]).Name)
+	if !ok {
+		return false
+	}
+	if len(f.Index) != len(path)-start {
+		return true
+	}
+	for i, index := range f.Index {
+		if index != path[start+i] {
+			return true
+		}
+	}
+	return false
+}
+
+// findEmbeddedPointers returns the paths to the exported struct pointers
+// embedded within `t`, including those embedded within other embedded
+// structs. Parents come before the structs embedded within them.
+func findEmbeddedPointers(t reflect.Type) [][]int {
+	var paths [][]int
+	var find func(t reflect.Type, path []int)
+	find = func(t reflect.Type, path []int) {
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if !f.Anonymous || deref(f.Type).Kind() != reflect.Struct {
+				continue
+			}
+			if f.Type.Kind() == reflect.Pointer && !f.IsExported() {
+				// Unexported pointers cannot be set.
+				continue
+			}
+			fp := append(append([]int{}, path...), i)
+			if f.Type.Kind() == reflect.Pointer {
+				paths = append(paths, fp)
+			}
+			find(deref(f.Type), fp)
+		}
+	}
+	find(deref(t), nil)
+	return paths
+}
+
+// allocEmbeddedPointers sets any nil embedded struct pointers found by
+// `findEmbeddedPointers`, so the params and resolvers within them can be
+// used like those of structs embedded by value.
+func allocEmbeddedPointers(v reflect.Value, paths [][]int) {
+	for _, path := range paths {
+		f := v
+		for _, i := range path {
+			f = reflect.Indirect(f).Field(i)
+		}
+		if f.IsNil() {
+			f.Set(reflect.New(f.Type().Elem()))
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package huma
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// EnumValue documents one of the allowed values of an enum type.
+type EnumValue struct {
+	// Value is the allowed value as it is serialized, e.g. `"active"`.
+	Value any
+
+	// Name is a code-friendly name for the value, e.g. `StatusActive`, which
+	// SDK generators use for constants via `x-enum-varnames`.
+	Name string
+
+	// Description of the value, documented via `x-enum-descriptions`.
+	Description string
+}
+
+// EnumValuer is an interface that can be implemented by types to document
+// their allowed values, along with optional names and descriptions for each.
+// The values are used as the schema's `enum`.
+//
+//	type Status string
+//
+//	func (Status) EnumValues() []huma.EnumValue {
+//		return []huma.EnumValue{
+//			{Value: "active", Name: "StatusActive", Description: "In use"},
+//			{Value: "archived", Name: "StatusArchived", Description: "Read-only"},
+//		}
+//	}
+type EnumValuer interface {
+	EnumValues() []EnumValue
+}
+
+// setEnumValues sets the schema's enum from the values, along with the
+// `x-enum-varnames` and `x-enum-descriptions` extensions if any value has a
+// name or description.
+func setEnumValues(s *Schema, values []EnumValue) {
+	s.Enum = make([]any, len(values))
+	names := make([]string, len(values))
+	descriptions := make([]string, len(values))
+	for i, v := range values {
+		s.Enum[i] = v.Value
+		names[i] = v.Name
+		descriptions[i] = v.Description
+	}
+	setEnumExtension(s, "x-enum-varnames", names)
+	setEnumExtension(s, "x-enum-descriptions", descriptions)
+}
+
+// setEnumExtension sets an extension documenting each enum value, unless none
+// of the values are documented.
+func setEnumExtension(s *Schema, name string, values []string) {
+	for _, v := range values {
+		if v != "" {
+			if s.Extensions == nil {
+				s.Extensions = map[string]any{}
+			}
+			s.Extensions[name] = values
+			return
+		}
+	}
+}
+
+// enumTag returns the per-value strings from an `enumNames` or
+// `enumDescriptions` field tag, which must have one item per enum value. Tags
+// are comma-separated, or JSON arrays for values containing commas.
+func enumTag(f reflect.StructField, tag string, count int) []string {
+	value := f.Tag.Get(tag)
+	if value == "" {
+		return nil
+	}
+	var values []string
+	if value[0] == '[' {
+		if err := json.Unmarshal([]byte(value), &values); err != nil {
+			panic(fmt.Errorf("invalid %s tag for field '%s': %w", tag, f.Name, err))
+		}
+	} else {
+		for _, v := range strings.Split(value, ",") {
+			values = append(values, strings.TrimSpace(v))
+		}
+	}
+	if len(values) != count {
+		panic(fmt.Errorf("%s tag for field '%s' has %d values but the enum has %d: %w", tag, f.Name, len(values), count, ErrSchemaInvalid))
+	}
+	return values
+}
@@ -1,9 +1,18 @@
 package huma
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"reflect"
+	"sort"
 	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/danielgtaylor/huma/v2/negotiation"
+	"github.com/fxamacker/cbor/v2"
 )
 
 // ErrorDetailer returns error details for responses & debugging. This enables
@@ -27,6 +36,14 @@ type ErrorDetail struct {
 	// the client didn't send extra whitespace or help when the client
 	// did not log an outgoing request.
 	Value any `json:"value,omitempty" doc:"The value at the given location"`
+
+	// Extensions are additional members which are serialized alongside the
+	// fields above, e.g. a documentation link for this specific error.
+	// Extensions never override the standard members.
+	Extensions map[string]any `json:"-"`
+
+	// keyword is the schema keyword which failed validation, if any.
+	keyword string
 }
 
 // Error returns the error message / satisfies the `error` interface. If a
@@ -44,6 +61,27 @@ func (e *ErrorDetail) ErrorDetail() *ErrorDetail {
 	return e
 }
 
+// extendedDetail serializes an `ErrorDetail` including its extension members.
+// Like `extendedError`, the error detail has no marshalers of its own so that
+// structs which embed it keep their additional fields.
+type extendedDetail ErrorDetail
+
+func (e *extendedDetail) MarshalJSON() ([]byte, error) {
+	type detail ErrorDetail
+	return marshalWithExtensions[json.RawMessage]((*detail)(e), e.Extensions, json.Marshal, json.Unmarshal)
+}
+
+func (e *extendedDetail) MarshalCBOR() ([]byte, error) {
+	type detail ErrorDetail
+	return marshalWithExtensions[cbor.RawMessage]((*detail)(e), e.Extensions, cborEncMode.Marshal, cbor.Unmarshal)
+}
+
+// TransformSchema allows extension members in the generated schema.
+func (e *ErrorDetail) TransformSchema(r Registry, s *Schema) *Schema {
+	s.AdditionalProperties = true
+	return s
+}
+
 // ErrorModel defines a basic error message model based on RFC 7807 Problem
 // Details for HTTP APIs (https://datatracker.ietf.org/doc/html/rfc7807). It
 // is augmented with an `errors` field of `huma.ErrorDetail` objects that
@@ -88,6 +126,15 @@ type ErrorModel struct {
 	// Errors provides an optional mechanism of passing additional error details
 	// as a list.
 	Errors []*ErrorDetail `json:"errors,omitempty" doc:"Optional list of individual error details"`
+
+	// RequestID identifies the request which caused the error, if request IDs
+	// are enabled. See `huma.Config.RequestIDHeader`.
+	RequestID string `json:"requestId,omitempty" doc:"Identifier of the request which caused the error, for support purposes"`
+
+	// Extensions are additional top-level members, as allowed by RFC 9457,
+	// e.g. trace IDs or retryability flags. Extensions never override the
+	// standard members, and are included when Huma writes the error response.
+	Extensions map[string]any `json:"-"`
 }
 
 // Error satisfies the `error` interface. It returns the error's detail field.
@@ -120,6 +167,60 @@ func (e *ErrorModel) GetStatus() int {
 	return e.Status
 }
 
+// extendedError serializes an `ErrorModel` including its extension members.
+// The error model has no marshalers of its own, as they would be promoted to
+// custom error structs which embed it and hide their additional fields.
+type extendedError ErrorModel
+
+// members returns the error with its details wrapped so their extension
+// members are serialized too.
+func (e *extendedError) members() any {
+	type model ErrorModel
+	m := struct {
+		*model
+		Errors []*extendedDetail `json:"errors,omitempty"`
+	}{model: (*model)(e)}
+	if len(e.Errors) > 0 {
+		m.Errors = make([]*extendedDetail, len(e.Errors))
+		for i, d := range e.Errors {
+			m.Errors[i] = (*extendedDetail)(d)
+		}
+	}
+	return m
+}
+
+func (e *extendedError) MarshalJSON() ([]byte, error) {
+	return marshalWithExtensions[json.RawMessage](e.members(), e.Extensions, json.Marshal, json.Unmarshal)
+}
+
+func (e *extendedError) MarshalCBOR() ([]byte, error) {
+	return marshalWithExtensions[cbor.RawMessage](e.members(), e.Extensions, cborEncMode.Marshal, cbor.Unmarshal)
+}
+
+// TransformSchema allows extension members in the generated schema.
+func (e *ErrorModel) TransformSchema(r Registry, s *Schema) *Schema {
+	s.AdditionalProperties = true
+	return s
+}
+
+// withExtension returns a shallow copy of the error with the given extension
+// member added. This is used by transformers which add members like `$schema`
+// to preserve the custom serialization of extensions.
+func (e *ErrorModel) withExtension(name string, value any) any {
+	tmp := *e
+	tmp.Extensions = make(map[string]any, len(e.Extensions)+1)
+	for k, v := range e.Extensions {
+		tmp.Extensions[k] = v
+	}
+	tmp.Extensions[name] = value
+	return &tmp
+}
+
+// SetRequestID sets the ID of the request which caused the error.
+func (e *ErrorModel) SetRequestID(id string) {
+	e.RequestID = id
+}
+
 // ContentType provides a filter to adjust response content types. This is
 // used to ensure e.g. `application/problem+json` content types defined in
 // RFC 7807 Problem Details for HTTP APIs are used in responses to clients.
@@ -133,6 +234,56 @@ func (e *ErrorModel) ContentType(ct string) string {
 	return ct
 }
 
+// marshalWithExtensions marshals `v` and merges in the extension members,
+// where `R` is the raw message type of the format. Members of `v` take
+// precedence over extensions with the same name.
+func marshalWithExtensions[R ~[]byte](v any, extensions map[string]any, marshal func(any) ([]byte, error), unmarshal func([]byte, any) error) ([]byte, error) {
+	b, err := marshal(v)
+	if err != nil || len(extensions) == 0 {
+		return b, err
+	}
+
+	members := map[string]R{}
+	if err := unmarshal(b, &members); err != nil {
+		return nil, err
+	}
+	for k, ext := range extensions {
+		if _, ok := members[k]; ok {
+			continue
+		}
+		eb, err := marshal(ext)
+		if err != nil {
+			return nil, err
+		}
+		members[k] = R(eb)
+	}
+	return marshal(members)
+}
+
+// ErrorContent returns the content of an existing error response of the
+// operation, preferring the `default` response followed by the lowest `4xx`
+// or `5xx` response. Middleware which documents additional error responses,
+// like `429 Too Many Requests`, can use it so those responses have the same
+// error model as the rest of the operation. Returns nil if there is none.
+func ErrorContent(op *Operation) map[string]*MediaType {
+	if resp := op.Responses["default"]; resp != nil && resp.Content != nil {
+		return resp.Content
+	}
+	codes := make([]string, 0, len(op.Responses))
+	for code := range op.Responses {
+		if strings.HasPrefix(code, "4") || strings.HasPrefix(code, "5") {
+			codes = append(codes, code)
+		}
+	}
+	sort.Strings(codes)
+	for _, code := range codes {
+		if resp := op.Responses[code]; resp.Content != nil {
+			return resp.Content
+		}
+	}
+	return nil
+}
+
 // ContentTypeFilter allows you to override the content type for responses,
 // allowing you to return a different content type like
 // `application/problem+json` after using the `application/json` marshaller.
@@ -149,6 +300,160 @@ type StatusError interface {
 	Error() string
 }
 
+// errorStatusMapping maps errors matching a type to an HTTP status code.
+type errorStatusMapping struct {
+	match  func(err error) bool
+	status int
+}
+
+var errorStatuses = struct {
+	mu       sync.RWMutex
+	mappings []errorStatusMapping
+}{}
+
+// MapErrorStatus registers an HTTP status code for errors of type `E`. When a
+// handler returns an error which does not satisfy `huma.StatusError`, the
+// error chain is checked against the registered types via `errors.As` and
+// the first match determines the response status. Unmatched errors result in
+// a 500 Internal Server Error. Mappings should be registered at startup.
+//
+//	type NotFoundError struct{ ID string }
+//
+//	func (e *NotFoundError) Error() string {
+//		return "thing " + e.ID + " not found"
+//	}
+//
+//	huma.MapErrorStatus[*NotFoundError](http.StatusNotFound)
+func MapErrorStatus[E error](status int) {
+	errorStatuses.mu.Lock()
+	defer errorStatuses.mu.Unlock()
+	errorStatuses.mappings = append(errorStatuses.mappings, errorStatusMapping{
+		match: func(err error) bool {
+			var target E
+			return errors.As(err, &target)
+		},
+		status: status,
+	})
+}
+
+// mappedErrorStatus returns the HTTP status code registered for the error's
+// type via `MapErrorStatus`, or zero if there is no mapping.
+func mappedErrorStatus(err error) int {
+	errorStatuses.mu.RLock()
+	defer errorStatuses.mu.RUnlock()
+	for _, m := range errorStatuses.mappings {
+		if m.match(err) {
+			return m.status
+		}
+	}
+	return 0
+}
+
+// HeadersError is an error which contributes headers to the error response,
+// e.g. a `Retry-After` header on a `429 Too Many Requests`. When returned
+// from an operation handler, the headers are sent along with the error.
+type HeadersError interface {
+	GetHeaders() http.Header
+}
+
+// ErrorResponseHeaders lists the headers which are documented in the OpenAPI
+// for declared error responses with the given status code. Modify this at
+// startup to document additional headers sent via `huma.HeadersError`.
+var ErrorResponseHeaders = map[int][]string{
+	http.StatusUnauthorized:       {"WWW-Authenticate"},
+	http.StatusMethodNotAllowed:   {"Allow"},
+	http.StatusTooManyRequests:    {"Retry-After"},
+	http.StatusServiceUnavailable: {"Retry-After"},
+}
+
+// errorWithHeaders wraps an error with response headers.
+type errorWithHeaders struct {
+	err     error
+	headers http.Header
+}
+
+func (e *errorWithHeaders) Error() string {
+	return e.err.Error()
+}
+
+func (e *errorWithHeaders) Unwrap() error {
+	return e.err
+}
+
+func (e *errorWithHeaders) GetHeaders() http.Header {
+	return e.headers
+}
+
+// ErrorWithHeaders wraps an error with headers to send in the error response.
+// The wrapped error's status and body are used as normal.
+//
+//	return nil, huma.ErrorWithHeaders(
+//		huma.Error429TooManyRequests("slow down"),
+//		http.Header{"Retry-After": {"30"}},
+//	)
+func ErrorWithHeaders(err error, headers http.Header) error {
+	return &errorWithHeaders{err: err, headers: headers}
+}
+
+// writeErrorHeaders sets any headers contributed by the error on the
+// response. It must be called before the response status is written.
+func writeErrorHeaders(ctx Context, err error) {
+	var he HeadersError
+	if errors.As(err, &he) {
+		for name, values := range he.GetHeaders() {
+			for _, value := range values {
+				ctx.AppendHeader(name, value)
+			}
+		}
+	}
+}
+
+// errorResponse returns the OpenAPI response for a declared error status.
+func errorResponse(status int, contentType string, schema *Schema) *Response {
+	resp := &Response{
+		Description: http.StatusText(status),
+		Content: map[string]*MediaType{
+			contentType: {
+				Schema: schema,
+			},
+		},
+	}
+	for _, name := range ErrorResponseHeaders[status] {
+		if resp.Headers == nil {
+			resp.Headers = map[string]*Param{}
+		}
+		resp.Headers[name] = &Header{
+			Schema: &Schema{Type: TypeString},
+		}
+	}
+	return resp
+}
+
+// addErrorTypeCodes adds the status codes from `op.ErrorTypes` to
+// `op.Errors` in sorted order if they are not already present.
+func addErrorTypeCodes(op *Operation) {
+	codes := make([]int, 0, len(op.ErrorTypes))
+	for code := range op.ErrorTypes {
+		if !slicesContains(op.Errors, code) {
+			codes = append(codes, code)
+		}
+	}
+	sort.Ints(codes)
+	op.Errors = append(op.Errors, codes...)
+}
+
+// errorTypeResponse returns the response for a status code using the schema
+// for the given example error value from `Operation.ErrorTypes`.
+func errorTypeResponse(registry Registry, op *Operation, status int, v any) *Response {
+	contentType := "application/json"
+	if ctf, ok := v.(ContentTypeFilter); ok {
+		contentType = ctf.ContentType(contentType)
+	}
+	typ := reflect.TypeOf(v)
+	schema := registry.Schema(typ, true, getHint(deref(typ), "", op.OperationID+strconv.Itoa(status)+"Error"))
+	return errorResponse(status, contentType, schema)
+}
+
 // NewError creates a new instance of an error model with the given status code,
 // message, and optional error details. If the error details implement the
 // `ErrorDetailer` interface, the error details will be used. Otherwise, the
@@ -203,21 +508,62 @@ var NewError = func(status int, msg string, errs ...error) StatusError {
 	}
 }
 
+// negotiateErrorContentType selects the content type for an error response.
+// Any of the API's `Config.ErrorFormats` explicitly accepted by the client
+// are preferred. Otherwise, the usual content negotiation is used and the
+// result is passed through the error's `ContentTypeFilter` if it has one.
+func negotiateErrorContentType(api API, ctx Context, err any) (string, error) {
+	accept := ctx.Header("Accept")
+	if formats := configOf(api).ErrorFormats; len(formats) > 0 && accept != "" {
+		keys := make([]string, 0, len(formats))
+		for k := range formats {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		if ct := negotiation.SelectQValueFast(accept, keys); ct != "" {
+			return ct, nil
+		}
+	}
+
+	ct, negotiateErr := api.Negotiate(accept)
+	if negotiateErr != nil {
+		return ct, negotiateErr
+	}
+	if ctf, ok := err.(ContentTypeFilter); ok {
+		ct = ctf.ContentType(ct)
+	}
+	return ct, nil
+}
+
+// limitErrors caps the number of errors at `max` (if positive), replacing any
+// extra errors with a summary so error responses stay small even for
+// pathological inputs.
+func limitErrors(errs []error, max int) []error {
+	if max <= 0 || len(errs) <= max {
+		return errs
+	}
+	limited := make([]error, max, max+1)
+	copy(limited, errs)
+	return append(limited, &ErrorDetail{
+		Message: fmt.Sprintf("and %d more errors", len(errs)-max),
+	})
+}
+
 // WriteErr writes an error response with the given context, using the
 // configured error type and with the given status code and message. It is
 // marshaled using the API's content negotiation methods.
 func WriteErr(api API, ctx Context, status int, msg string, errs ...error) error {
+	errs = limitErrors(errs, configOf(api).MaxErrorDetails)
 	var err any = NewError(status, msg, errs...)
 
-	ct, negotiateErr := api.Negotiate(ctx.Header("Accept"))
+	ct, negotiateErr := negotiateErrorContentType(api, ctx, err)
 	if negotiateErr != nil {
 		return negotiateErr
 	}
 
-	if ctf, ok := err.(ContentTypeFilter); ok {
-		ct = ctf.ContentType(ct)
-	}
-
+	writeErrorHeaders(ctx, err.(error))
+	setErrorRequestID(ctx, err)
+	setErrorLocale(ctx, err)
 	ctx.SetHeader("Content-Type", ct)
 	ctx.SetStatus(status)
 	tval, terr := api.Transform(ctx, strconv.Itoa(status), err)
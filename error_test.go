@@ -1,13 +1,18 @@
 package huma_test
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
 	"github.com/danielgtaylor/huma/v2"
 	"github.com/danielgtaylor/huma/v2/humatest"
+	"github.com/fxamacker/cbor/v2"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -100,3 +105,292 @@ func TestTransformError(t *testing.T) {
 
 	require.Error(t, huma.WriteErr(api, ctx, 400, "bad request"))
 }
+
+type mappedNotFoundError struct {
+	id string
+}
+
+func (e *mappedNotFoundError) Error() string {
+	return "thing " + e.id + " not found"
+}
+
+func TestMapErrorStatus(t *testing.T) {
+	huma.MapErrorStatus[*mappedNotFoundError](http.StatusNotFound)
+
+	_, api := humatest.New(t, huma.DefaultConfig("Test API", "1.0.0"))
+	huma.Register(api, huma.Operation{
+		Method: http.MethodGet,
+		Path:   "/things/{id}",
+	}, func(ctx context.Context, input *struct {
+		ID string `path:"id"`
+	}) (*struct{}, error) {
+		if input.ID == "plain" {
+			return nil, errors.New("plain error")
+		}
+		return nil, fmt.Errorf("wrapped: %w", &mappedNotFoundError{id: input.ID})
+	})
+
+	resp := api.Get("/things/abc")
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+	assert.Contains(t, resp.Body.String(), "wrapped: thing abc not found")
+	assert.Contains(t, resp.Body.String(), `"title":"Not Found"`)
+
+	resp = api.Get("/things/plain")
+	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+}
+
+func TestErrorWithHeaders(t *testing.T) {
+	_, api := humatest.New(t, huma.DefaultConfig("Test API", "1.0.0"))
+	huma.Register(api, huma.Operation{
+		Method: http.MethodGet,
+		Path:   "/limited",
+		Errors: []int{http.StatusTooManyRequests},
+	}, func(ctx context.Context, input *struct{}) (*struct{}, error) {
+		return nil, huma.ErrorWithHeaders(
+			huma.Error429TooManyRequests("slow down"),
+			http.Header{"Retry-After": {"30"}},
+		)
+	})
+
+	resp := api.Get("/limited")
+	assert.Equal(t, http.StatusTooManyRequests, resp.Code)
+	assert.Equal(t, "30", resp.Header().Get("Retry-After"))
+	assert.Contains(t, resp.Body.String(), `"detail":"slow down"`)
+
+	// The header is documented on the declared error response.
+	r := api.OpenAPI().Paths["/limited"].Get.Responses["429"]
+	require.NotNil(t, r.Headers["Retry-After"])
+	assert.Equal(t, "string", r.Headers["Retry-After"].Schema.Type)
+}
+
+type ConflictError struct {
+	Message  string `json:"message"`
+	Existing string `json:"existing"`
+}
+
+func (e *ConflictError) Error() string  { return e.Message }
+func (e *ConflictError) GetStatus() int { return http.StatusConflict }
+
+func TestErrorTypes(t *testing.T) {
+	_, api := humatest.New(t, huma.DefaultConfig("Test API", "1.0.0"))
+
+	huma.Register(api, huma.Operation{
+		OperationID: "create-thing",
+		Method:      http.MethodPost,
+		Path:        "/things",
+		Errors:      []int{http.StatusNotFound},
+		ErrorTypes: map[int]any{
+			http.StatusConflict: &ConflictError{},
+		},
+	}, func(ctx context.Context, input *struct{}) (*struct{}, error) {
+		return nil, &ConflictError{Message: "already exists", Existing: "abc"}
+	})
+
+	responses := api.OpenAPI().Paths["/things"].Post.Responses
+	assert.Equal(t, "#/components/schemas/ErrorModel", responses["404"].Content["application/problem+json"].Schema.Ref)
+	assert.Equal(t, "#/components/schemas/ConflictError", responses["409"].Content["application/json"].Schema.Ref)
+	assert.NotNil(t, responses["500"])
+	assert.Nil(t, responses["default"])
+
+	resp := api.Post("/things")
+	assert.Equal(t, http.StatusConflict, resp.Code)
+	assert.Contains(t, resp.Body.String(), `"existing":"abc"`)
+}
+
+func TestMaxErrorDetails(t *testing.T) {
+	config := huma.DefaultConfig("Test API", "1.0.0")
+	config.MaxErrorDetails = 2
+	_, api := humatest.New(t, config)
+
+	huma.Register(api, huma.Operation{
+		Method: http.MethodGet,
+		Path:   "/things",
+	}, func(ctx context.Context, input *struct {
+		A int `query:"a" minimum:"1"`
+		B int `query:"b" minimum:"1"`
+		C int `query:"c" minimum:"1"`
+		D int `query:"d" minimum:"1"`
+	}) (*struct{}, error) {
+		return nil, nil
+	})
+
+	resp := api.Get("/things?a=0&b=0&c=0&d=0")
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+
+	var model huma.ErrorModel
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &model))
+	require.Len(t, model.Errors, 3)
+	assert.Equal(t, "query.a", model.Errors[0].Location)
+	assert.Equal(t, "and 2 more errors", model.Errors[2].Message)
+}
+
+func TestProblemTypes(t *testing.T) {
+	outOfStock := &huma.ProblemType{
+		Name:        "out-of-stock",
+		Title:       "Out of stock",
+		Status:      http.StatusConflict,
+		Description: "The requested item is no longer available.",
+	}
+
+	config := huma.DefaultConfig("Test API", "1.0.0")
+	config.ErrorsPath = "/errors"
+	config.ProblemTypes = []*huma.ProblemType{outOfStock}
+	_, api := humatest.New(t, config)
+
+	assert.Equal(t, "/errors/out-of-stock", outOfStock.Type)
+
+	huma.Register(api, huma.Operation{
+		Method: http.MethodPost,
+		Path:   "/orders",
+	}, func(ctx context.Context, input *struct{}) (*struct{}, error) {
+		return nil, outOfStock.New("item 123 is out of stock")
+	})
+
+	resp := api.Post("/orders")
+	assert.Equal(t, http.StatusConflict, resp.Code)
+	assert.Contains(t, resp.Body.String(), `"type":"/errors/out-of-stock"`)
+	assert.Contains(t, resp.Body.String(), `"title":"Out of stock"`)
+	assert.Contains(t, resp.Body.String(), `"detail":"item 123 is out of stock"`)
+
+	resp = api.Get("/errors/out-of-stock")
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, `{
+		"name": "out-of-stock",
+		"type": "/errors/out-of-stock",
+		"title": "Out of stock",
+		"status": 409,
+		"description": "The requested item is no longer available."
+	}`, resp.Body.String())
+
+	resp = api.Get("/errors/unknown")
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+
+	spec, err := json.Marshal(api.OpenAPI())
+	require.NoError(t, err)
+	assert.Contains(t, string(spec), `"x-problem-types":[{"name":"out-of-stock"`)
+}
+
+func TestErrorFormats(t *testing.T) {
+	config := huma.DefaultConfig("Test API", "1.0.0")
+	config.ErrorFormats = map[string]huma.Format{
+		"text/plain": {
+			Marshal: func(w io.Writer, v any) error {
+				if m, ok := v.(*huma.ErrorModel); ok {
+					_, err := fmt.Fprintf(w, "%d %s: %s", m.Status, m.Title, m.Detail)
+					return err
+				}
+				return fmt.Errorf("unsupported error type %T", v)
+			},
+		},
+	}
+	// Disable the schema link transformer, which wraps the error model.
+	config.Transformers = nil
+	_, api := humatest.New(t, config)
+
+	huma.Register(api, huma.Operation{
+		Method: http.MethodGet,
+		Path:   "/error",
+	}, func(ctx context.Context, input *struct {
+		Count int `query:"count" minimum:"1"`
+	}) (*struct{}, error) {
+		return nil, huma.Error404NotFound("no such thing")
+	})
+
+	resp := api.Get("/error", "Accept: text/plain")
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+	assert.Equal(t, "text/plain", resp.Header().Get("Content-Type"))
+	assert.Equal(t, "404 Not Found: no such thing", resp.Body.String())
+
+	// Errors written before the handler runs also use the error format.
+	resp = api.Get("/error?count=0", "Accept: text/plain")
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+	assert.Equal(t, "422 Unprocessable Entity: validation failed", resp.Body.String())
+
+	// Other clients get the default error format.
+	resp = api.Get("/error", "Accept: */*")
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+	assert.Equal(t, "application/problem+json", resp.Header().Get("Content-Type"))
+}
+
+func TestErrorExtensions(t *testing.T) {
+	_, api := humatest.New(t, huma.DefaultConfig("Test API", "1.0.0"))
+
+	huma.Register(api, huma.Operation{
+		Method: http.MethodGet,
+		Path:   "/error",
+	}, func(ctx context.Context, input *struct{}) (*struct{}, error) {
+		return nil, &huma.ErrorModel{
+			Status: http.StatusServiceUnavailable,
+			Detail: "try again later",
+			Errors: []*huma.ErrorDetail{
+				{
+					Message:    "database unavailable",
+					Extensions: map[string]any{"docs": "https://example.com/db"},
+				},
+			},
+			Extensions: map[string]any{
+				"traceId":   "abc123",
+				"retryable": true,
+				"status":    "ignored",
+			},
+		}
+	})
+
+	resp := api.Get("/error")
+	assert.Equal(t, http.StatusServiceUnavailable, resp.Code)
+	assert.JSONEq(t, `{
+		"$schema": "https:///schemas/ErrorModel.json",
+		"status": 503,
+		"detail": "try again later",
+		"errors": [{"message": "database unavailable", "docs": "https://example.com/db"}],
+		"traceId": "abc123",
+		"retryable": true
+	}`, resp.Body.String())
+
+	// CBOR also includes the extensions.
+	resp = api.Get("/error", "Accept: application/cbor")
+	var decoded map[string]any
+	require.NoError(t, cbor.Unmarshal(resp.Body.Bytes(), &decoded))
+	assert.Equal(t, "abc123", decoded["traceId"])
+	assert.Equal(t, "https://example.com/db", decoded["errors"].([]any)[0].(map[any]any)["docs"])
+
+	// The schema allows extension members.
+	schema := api.OpenAPI().Components.Schemas.Map()["ErrorModel"]
+	assert.Equal(t, true, schema.AdditionalProperties)
+}
+
+func TestErrorEmbedding(t *testing.T) {
+	// Custom error types which embed the built-in models must keep their own
+	// fields when serialized.
+	detail, err := json.Marshal(&struct {
+		huma.ErrorDetail
+		Code string `json:"code"`
+	}{ErrorDetail: huma.ErrorDetail{Message: "bad value"}, Code: "E123"})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"message": "bad value", "code": "E123"}`, string(detail))
+
+	model, err := json.Marshal(&struct {
+		huma.ErrorModel
+		Code string `json:"code"`
+	}{ErrorModel: huma.ErrorModel{Status: http.StatusBadRequest}, Code: "E123"})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"status": 400, "code": "E123"}`, string(model))
+}
+
+func TestErrorContent(t *testing.T) {
+	defaultContent := map[string]*huma.MediaType{"application/problem+json": {}}
+	notFoundContent := map[string]*huma.MediaType{"application/json": {}}
+
+	op := &huma.Operation{Responses: map[string]*huma.Response{
+		"200": {Content: map[string]*huma.MediaType{"text/plain": {}}},
+		"500": {Content: map[string]*huma.MediaType{"text/html": {}}},
+		"404": {Content: notFoundContent},
+		"400": {Description: "no content"},
+	}}
+	assert.Equal(t, notFoundContent, huma.ErrorContent(op))
+
+	op.Responses["default"] = &huma.Response{Content: defaultContent}
+	assert.Equal(t, defaultContent, huma.ErrorContent(op))
+
+	assert.Nil(t, huma.ErrorContent(&huma.Operation{}))
+}
@@ -0,0 +1,150 @@
+package huma
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// ETagger is implemented by response outputs or bodies which can cheaply
+// provide a version token for the resource, e.g. a revision number or an
+// update timestamp. When `Config.AutoETag` is enabled, the token is used as
+// the `ETag` so that unchanged resources are not serialized at all. Return
+// an empty string to fall back to hashing the serialized body.
+//
+//	func (o *GetItemOutput) ETag() string {
+//		return strconv.Itoa(o.Body.Revision)
+//	}
+type ETagger interface {
+	ETag() string
+}
+
+// formatETag quotes an entity tag if needed, keeping any weak `W/` prefix.
+func formatETag(etag string) string {
+	if strings.HasPrefix(etag, `"`) || strings.HasPrefix(etag, `W/"`) {
+		return etag
+	}
+	return `"` + etag + `"`
+}
+
+// hashETag returns a strong entity tag for a serialized body.
+func hashETag(b []byte) string {
+	sum := sha256.Sum256(b)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// etagMatches returns whether an `If-None-Match` header value matches the
+// entity tag, using the weak comparison required for `If-None-Match`.
+func etagMatches(ifNoneMatch, etag string) bool {
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// autoETagApplies returns whether a response should get an automatic `ETag`
+// when they are enabled, which is only the case for successful reads.
+func autoETagApplies(ctx Context, status int) bool {
+	if status != http.StatusOK {
+		return false
+	}
+	m := ctx.Method()
+	return m == http.MethodGet || m == http.MethodHead
+}
+
+// notModified sets the `ETag` header and, if the client already has the
+// current representation, responds with `304 Not Modified`.
+func notModified(ctx Context, etag string) bool {
+	ctx.SetHeader("ETag", etag)
+	if inm := ctx.Header("If-None-Match"); inm != "" && etagMatches(inm, etag) {
+		ctx.SetStatus(http.StatusNotModified)
+		return true
+	}
+	return false
+}
+
+// writeWithETag writes a response body with an `ETag`, or a `304 Not
+// Modified` without serializing the body if the client's `If-None-Match`
+// matches. Without a known entity tag, the body is serialized and hashed
+// before anything is written.
+func writeWithETag(api API, ctx Context, etag string, status int, ct string, body any) {
+	b, raw := body.([]byte)
+	if etag != "" {
+		etag = formatETag(etag)
+	} else {
+		if !raw {
+			tval, terr := api.Transform(ctx, strconv.Itoa(status), body)
+			if terr != nil {
+				panic(fmt.Sprintf("error transforming response %+v for %s %s %d: %s\n", tval, ctx.Operation().Method, ctx.Operation().Path, status, terr.Error()))
+			}
+			buf := bufPool.Get().(*bytes.Buffer)
+			defer releaseBuf(buf)
+			if merr := api.Marshal(buf, ct, tval); merr != nil {
+				panic(fmt.Sprintf("error marshaling response %+v for %s %s %d: %s\n", tval, ctx.Operation().Method, ctx.Operation().Path, status, merr.Error()))
+			}
+			b, raw = buf.Bytes(), true
+		}
+		etag = hashETag(b)
+	}
+
+	if notModified(ctx, etag) {
+		return
+	}
+	if !raw {
+		transformAndWrite(api, ctx, status, ct, body)
+		return
+	}
+	ctx.SetHeader("Content-Length", strconv.Itoa(len(b)))
+	ctx.SetStatus(status)
+	ctx.BodyWriter().Write(b)
+}
+
+// documentAutoETag adds the `If-None-Match` parameter, `ETag` response
+// header, and `304 Not Modified` response to a read operation.
+func documentAutoETag(op *Operation) {
+	if op.Method != http.MethodGet {
+		return
+	}
+	resp := op.Responses[strconv.Itoa(http.StatusOK)]
+	if resp == nil || resp.Content == nil {
+		return
+	}
+
+	documented := false
+	for _, p := range op.Parameters {
+		if p.In == "header" && strings.EqualFold(p.Name, "If-None-Match") {
+			documented = true
+		}
+	}
+	if !documented {
+		op.Parameters = append(op.Parameters, &Param{
+			Name:        "If-None-Match",
+			In:          "header",
+			Description: "Entity tags of representations the client already has. If the current representation matches, a `304 Not Modified` response without a body is returned.",
+			Schema:      &Schema{Type: TypeString},
+		})
+	}
+
+	if resp.Headers == nil {
+		resp.Headers = map[string]*Param{}
+	}
+	if resp.Headers["ETag"] == nil {
+		resp.Headers["ETag"] = &Header{
+			Description: "Entity tag of the current representation.",
+			Schema:      &Schema{Type: TypeString},
+		}
+	}
+	if op.Responses["304"] == nil {
+		op.Responses["304"] = &Response{
+			Description: http.StatusText(http.StatusNotModified),
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package huma_test
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/humatest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type VersionedItem struct {
+	Revision int    `json:"revision"`
+	Name     string `json:"name"`
+}
+
+type VersionedOutput struct {
+	Body *VersionedItem
+}
+
+func (o *VersionedOutput) ETag() string {
+	return "rev-" + strconv.Itoa(o.Body.Revision)
+}
+
+func TestAutoETag(t *testing.T) {
+	config := huma.DefaultConfig("Test API", "1.0.0")
+	config.AutoETag = true
+	transformed := 0
+	config.Transformers = append(config.Transformers, func(ctx huma.Context, status string, v any) (any, error) {
+		transformed++
+		return v, nil
+	})
+	_, api := humatest.New(t, config)
+
+	name := "apple"
+	huma.Register(api, huma.Operation{
+		OperationID: "get-item",
+		Method:      http.MethodGet,
+		Path:        "/items",
+	}, func(ctx context.Context, input *struct{}) (*struct{ Body VersionedItem }, error) {
+		return &struct{ Body VersionedItem }{Body: VersionedItem{Name: name}}, nil
+	})
+
+	huma.Register(api, huma.Operation{
+		OperationID: "get-versioned",
+		Method:      http.MethodGet,
+		Path:        "/versioned",
+	}, func(ctx context.Context, input *struct{}) (*VersionedOutput, error) {
+		return &VersionedOutput{Body: &VersionedItem{Revision: 3, Name: name}}, nil
+	})
+
+	huma.Register(api, huma.Operation{
+		OperationID: "get-raw",
+		Method:      http.MethodGet,
+		Path:        "/raw",
+	}, func(ctx context.Context, input *struct{}) (*struct{ Body []byte }, error) {
+		return &struct{ Body []byte }{Body: []byte(name)}, nil
+	})
+
+	huma.Register(api, huma.Operation{
+		OperationID: "create-item",
+		Method:      http.MethodPost,
+		Path:        "/items",
+	}, func(ctx context.Context, input *struct{}) (*struct{ Body VersionedItem }, error) {
+		return &struct{ Body VersionedItem }{}, nil
+	})
+
+	// Hashed from the serialized body.
+	resp := api.Get("/items")
+	require.Equal(t, http.StatusOK, resp.Code)
+	etag := resp.Header().Get("ETag")
+	require.NotEmpty(t, etag)
+	assert.Contains(t, resp.Body.String(), "apple")
+
+	resp = api.Get("/items", "If-None-Match: "+etag)
+	assert.Equal(t, http.StatusNotModified, resp.Code)
+	assert.Empty(t, resp.Body.String())
+	assert.Equal(t, etag, resp.Header().Get("ETag"))
+
+	resp = api.Get("/items", `If-None-Match: "other", W/`+etag)
+	assert.Equal(t, http.StatusNotModified, resp.Code)
+
+	// Changes produce a new ETag.
+	name = "banana"
+	resp = api.Get("/items", "If-None-Match: "+etag)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.NotEqual(t, etag, resp.Header().Get("ETag"))
+
+	// Version tokens from the handler skip serialization.
+	before := transformed
+	resp = api.Get("/versioned", `If-None-Match: "rev-3"`)
+	assert.Equal(t, http.StatusNotModified, resp.Code)
+	assert.Equal(t, `"rev-3"`, resp.Header().Get("ETag"))
+	assert.Equal(t, before, transformed)
+
+	resp = api.Get("/versioned", `If-None-Match: "rev-2"`)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Contains(t, resp.Body.String(), "banana")
+
+	resp = api.Get("/raw")
+	assert.Equal(t, "banana", resp.Body.String())
+	resp = api.Get("/raw", "If-None-Match: "+resp.Header().Get("ETag"))
+	assert.Equal(t, http.StatusNotModified, resp.Code)
+
+	// Writes are not affected.
+	resp = api.Post("/items")
+	assert.Empty(t, resp.Header().Get("ETag"))
+
+	op := api.OpenAPI().Paths["/items"].Get
+	assert.NotNil(t, op.Responses["304"])
+	assert.NotNil(t, op.Responses["200"].Headers["ETag"])
+	require.Len(t, op.Parameters, 1)
+	assert.Equal(t, "If-None-Match", op.Parameters[0].Name)
+	assert.Nil(t, api.OpenAPI().Paths["/items"].Post.Responses["304"])
+}
@@ -0,0 +1,272 @@
+package huma
+
+import (
+	"encoding"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// projection is a tree of selected fields. A nil projection selects the
+// whole value.
+type projection map[string]projection
+
+// add selects the given path, where selecting a field also selects all of
+// its children.
+func (p projection) add(path []string) {
+	if len(path) == 0 {
+		return
+	}
+	child, ok := p[path[0]]
+	if ok && child == nil {
+		// Already fully selected.
+		return
+	}
+	if len(path) == 1 {
+		p[path[0]] = nil
+		return
+	}
+	if child == nil {
+		child = projection{}
+		p[path[0]] = child
+	}
+	child.add(path[1:])
+}
+
+// parseProjection parses a comma-separated list of dotted paths like
+// `items.id`, JMESPath-style list projections like `items[*].id`, or JSON
+// Pointers like `/items/id`. Lists are projected implicitly, so `items.id`
+// selects the `id` of every item. Empty segments are ignored.
+func parseProjection(fields string) projection {
+	p := projection{}
+	for _, field := range strings.Split(fields, ",") {
+		field = strings.TrimSpace(field)
+		var path []string
+		if strings.HasPrefix(field, "/") {
+			for _, segment := range strings.Split(field[1:], "/") {
+				segment = strings.ReplaceAll(strings.ReplaceAll(segment, "~1", "/"), "~0", "~")
+				if segment != "" {
+					path = append(path, segment)
+				}
+			}
+		} else {
+			for _, segment := range strings.Split(field, ".") {
+				segment = strings.TrimSuffix(strings.TrimSuffix(segment, "[*]"), "[]")
+				if segment != "" {
+					path = append(path, segment)
+				}
+			}
+		}
+		p.add(path)
+	}
+	return p
+}
+
+// jsonField is a struct field serialized as JSON.
+type jsonField struct {
+	index     []int
+	omitEmpty bool
+}
+
+var jsonFieldsCache sync.Map
+
+// jsonFields returns the JSON fields of a struct type by name, including
+// those promoted from embedded structs.
+func jsonFields(t reflect.Type) map[string]jsonField {
+	if cached, ok := jsonFieldsCache.Load(t); ok {
+		return cached.(map[string]jsonField)
+	}
+	fields := map[string]jsonField{}
+	addJSONFields(fields, t, nil)
+	jsonFieldsCache.Store(t, fields)
+	return fields
+}
+
+func addJSONFields(fields map[string]jsonField, t reflect.Type, index []int) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		fi := append(append([]int{}, index...), i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if f.Anonymous && name == "" && deref(f.Type).Kind() == reflect.Struct {
+			addJSONFields(fields, deref(f.Type), fi)
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		if _, ok := fields[name]; ok && len(index) > 0 {
+			// Fields of the outer struct take precedence over promoted ones.
+			continue
+		}
+		fields[name] = jsonField{index: fi, omitEmpty: strings.Contains(","+opts+",", ",omitempty,")}
+	}
+}
+
+var (
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// project returns the selected parts of `v`. Selected values are returned
+// as-is so that only they get serialized, while objects containing selected
+// fields become maps. Values with custom marshalers are serialized first
+// since their structure is not known.
+func project(v reflect.Value, p projection) any {
+	if !v.IsValid() {
+		return nil
+	}
+	if p == nil {
+		return v.Interface()
+	}
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		if v.Type().Implements(jsonMarshalerType) {
+			break
+		}
+		v = v.Elem()
+	}
+
+	if v.Type().Implements(jsonMarshalerType) || v.Type().Implements(textMarshalerType) ||
+		(v.CanAddr() && v.Addr().Type().Implements(jsonMarshalerType)) {
+		iv := v.Interface()
+		if v.CanAddr() {
+			iv = v.Addr().Interface()
+		}
+		var tmp any
+		if b, err := json.Marshal(iv); err == nil && json.Unmarshal(b, &tmp) == nil {
+			return project(reflect.ValueOf(tmp), p)
+		}
+		return v.Interface()
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		fields := jsonFields(v.Type())
+		result := make(map[string]any, len(p))
+		for name, child := range p {
+			f, ok := fields[name]
+			if !ok {
+				continue
+			}
+			fv, err := v.FieldByIndexErr(f.index)
+			if err != nil || (f.omitEmpty && isEmptyValue(fv)) {
+				continue
+			}
+			result[name] = project(fv, child)
+		}
+		return result
+	case reflect.Map:
+		if v.IsNil() || v.Type().Key().Kind() != reflect.String {
+			return v.Interface()
+		}
+		result := make(map[string]any, len(p))
+		for name, child := range p {
+			item := v.MapIndex(reflect.ValueOf(name).Convert(v.Type().Key()))
+			if item.IsValid() {
+				result[name] = project(item, child)
+			}
+		}
+		return result
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return v.Interface()
+		}
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			// Bytes are serialized as a string.
+			return v.Interface()
+		}
+		result := make([]any, v.Len())
+		for i := range result {
+			result[i] = project(v.Index(i), p)
+		}
+		return result
+	}
+	return v.Interface()
+}
+
+// FieldSelectTransformer is an opt-in transform that lets clients select the
+// response fields they want via a query parameter, e.g.
+// `?fields=items.id,items.name,meta`, providing a GraphQL-like way to send
+// only the needed fields over the wire. Nested fields are selected with dots,
+// JMESPath-style list projections like `items[*].id`, or JSON Pointers like
+// `/items/id`, and lists are projected implicitly.
+//
+// Fields are selected from the response value directly, so discarded fields
+// are never serialized. Error responses and raw `[]byte` bodies are not
+// modified. Use `OnAddOperation` to document the query parameter.
+//
+//	fields := huma.NewFieldSelectTransformer("fields")
+//	config.OnAddOperation = append(config.OnAddOperation, fields.OnAddOperation)
+//	config.Transformers = append(config.Transformers, fields.Transform)
+type FieldSelectTransformer struct {
+	param string
+}
+
+// NewFieldSelectTransformer creates a new transformer which selects response
+// fields using the given query parameter, defaulting to `fields`.
+func NewFieldSelectTransformer(param string) *FieldSelectTransformer {
+	if param == "" {
+		param = "fields"
+	}
+	return &FieldSelectTransformer{param: param}
+}
+
+// OnAddOperation is triggered whenever a new operation is added to the API,
+// enabling this transformer to document the query parameter on operations
+// with a response body.
+func (t *FieldSelectTransformer) OnAddOperation(oapi *OpenAPI, op *Operation) {
+	hasBody := false
+	for status, resp := range op.Responses {
+		if code, err := strconv.Atoi(status); err == nil && code < http.StatusBadRequest && len(resp.Content) > 0 {
+			hasBody = true
+		}
+	}
+	if !hasBody {
+		return
+	}
+	for _, p := range op.Parameters {
+		if p.In == "query" && p.Name == t.param {
+			return
+		}
+	}
+	op.Parameters = append(op.Parameters, &Param{
+		Name:        t.param,
+		In:          "query",
+		Description: "Comma-separated response fields to include, like `items.id,items.name,meta`. Nested fields use dots or JSON Pointers.",
+		Schema:      &Schema{Type: TypeString},
+	})
+}
+
+// Transform is called for every response to select the requested fields.
+func (t *FieldSelectTransformer) Transform(ctx Context, status string, v any) (any, error) {
+	if v == nil {
+		return v, nil
+	}
+	if _, ok := v.([]byte); ok {
+		return v, nil
+	}
+	if code, err := strconv.Atoi(status); err != nil || code >= http.StatusBadRequest {
+		return v, nil
+	}
+	fields := ctx.Query(t.param)
+	if fields == "" {
+		return v, nil
+	}
+	p := parseProjection(fields)
+	if len(p) == 0 {
+		return v, nil
+	}
+	return project(reflect.ValueOf(v), p), nil
+}
@@ -0,0 +1,274 @@
+// Package filter provides filtering & sorting query parameters for list
+// operations. Filters use a small expression language like
+// `status eq active and age gt 30`, and sorting uses a comma-separated list
+// of fields like `-created_at,name`, where a `-` prefix sorts descending.
+//
+// The filterable & sortable fields are declared on a struct using `filter`
+// and `sort` tags, and both the allowed fields and operators are documented
+// in the generated OpenAPI.
+//
+//	type ThingFields struct {
+//		Status    string    `json:"status" filter:"eq,ne,in"`
+//		Age       int       `json:"age" filter:"eq,gt,lt" sort:"true"`
+//		CreatedAt time.Time `json:"created_at" sort:"true"`
+//	}
+//
+//	huma.Register(api, huma.Operation{
+//		OperationID: "list-things",
+//		Method:      http.MethodGet,
+//		Path:        "/things",
+//	}, func(ctx context.Context, input *struct {
+//		filter.Params[ThingFields]
+//	}) (*ListThingsOutput, error) {
+//		things := db.ListThings(input.Where, input.OrderBy)
+//		...
+//	})
+package filter
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// Op is a comparison operator.
+type Op string
+
+// Supported comparison operators.
+const (
+	Eq         Op = "eq"
+	Ne         Op = "ne"
+	Gt         Op = "gt"
+	Ge         Op = "ge"
+	Lt         Op = "lt"
+	Le         Op = "le"
+	In         Op = "in"
+	Contains   Op = "contains"
+	StartsWith Op = "startswith"
+)
+
+var ops = map[Op]bool{Eq: true, Ne: true, Gt: true, Ge: true, Lt: true, Le: true, In: true, Contains: true, StartsWith: true}
+
+// field describes a filterable or sortable field.
+type field struct {
+	name     string
+	typ      reflect.Type
+	ops      []Op
+	sortable bool
+}
+
+func (f *field) allows(op Op) bool {
+	for _, o := range f.ops {
+		if o == op {
+			return true
+		}
+	}
+	return false
+}
+
+// fields describes the filterable & sortable fields of a struct.
+type fields struct {
+	byName map[string]*field
+	order  []*field
+}
+
+var fieldsCache sync.Map
+
+// fieldsOf returns the fields declared by the tags of struct type `t`,
+// panicking on invalid declarations.
+func fieldsOf(t reflect.Type) *fields {
+	if cached, ok := fieldsCache.Load(t); ok {
+		return cached.(*fields)
+	}
+	if t.Kind() != reflect.Struct {
+		panic(fmt.Errorf("filter fields must be a struct, got %s", t))
+	}
+
+	fs := &fields{byName: map[string]*field{}}
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		filterTag := sf.Tag.Get("filter")
+		sortTag := sf.Tag.Get("sort")
+		if filterTag == "" && sortTag == "" {
+			continue
+		}
+
+		name := sf.Name
+		if j := strings.Split(sf.Tag.Get("json"), ",")[0]; j != "" && j != "-" {
+			name = j
+		}
+		f := &field{name: name, typ: sf.Type, sortable: sortTag == "true"}
+		if filterTag != "" {
+			if !supported(f.typ) {
+				panic(fmt.Errorf("unsupported filter field type %s for %s", f.typ, name))
+			}
+			for _, o := range strings.Split(filterTag, ",") {
+				op := Op(strings.TrimSpace(o))
+				if !ops[op] {
+					panic(fmt.Errorf("unknown filter operator %q for %s", op, name))
+				}
+				if (op == Contains || op == StartsWith) && f.typ.Kind() != reflect.String {
+					panic(fmt.Errorf("filter operator %s requires a string field for %s", op, name))
+				}
+				f.ops = append(f.ops, op)
+			}
+		}
+		fs.byName[name] = f
+		fs.order = append(fs.order, f)
+	}
+
+	cached, _ := fieldsCache.LoadOrStore(t, fs)
+	return cached.(*fields)
+}
+
+// Expression is the `filter` query parameter. Its schema describes the
+// fields and operators declared by `T`.
+type Expression[T any] string
+
+func (e *Expression[T]) Schema(r huma.Registry) *huma.Schema {
+	fs := fieldsOf(reflect.TypeOf((*T)(nil)).Elem())
+	var sb strings.Builder
+	sb.WriteString("Filter expression, like `status eq active and age gt 30`. Comparisons can be combined with `and`, `or`, `not`, and parentheses. Quote values containing spaces. Allowed fields and operators:\n")
+	for _, f := range fs.order {
+		if len(f.ops) == 0 {
+			continue
+		}
+		names := make([]string, len(f.ops))
+		for i, op := range f.ops {
+			names[i] = string(op)
+		}
+		sb.WriteString("\n- `" + f.name + "`: " + strings.Join(names, ", "))
+	}
+	return &huma.Schema{Type: huma.TypeString, Description: sb.String()}
+}
+
+// SortOrder is the `sort` query parameter. Its schema describes the sortable
+// fields declared by `T`.
+type SortOrder[T any] string
+
+func (s *SortOrder[T]) Schema(r huma.Registry) *huma.Schema {
+	fs := fieldsOf(reflect.TypeOf((*T)(nil)).Elem())
+	names := []string{}
+	for _, f := range fs.order {
+		if f.sortable {
+			names = append(names, "`"+f.name+"`")
+		}
+	}
+	return &huma.Schema{
+		Type:        huma.TypeString,
+		Description: "Comma-separated fields to sort by, prefixed with `-` for descending order. Sortable fields: " + strings.Join(names, ", "),
+	}
+}
+
+// SortField is a field to sort by.
+type SortField struct {
+	Field string
+	Desc  bool
+}
+
+// Params are the filter & sort query parameters for the fields declared by
+// `T`. After the request is parsed, the `Where` and `OrderBy` fields contain
+// the validated filter expression and sort order.
+type Params[T any] struct {
+	Filter Expression[T] `query:"filter"`
+	Sort   SortOrder[T]  `query:"sort"`
+
+	// Where is the filter expression, or nil if no filter was given.
+	Where Expr
+
+	// OrderBy are the fields to sort by, in order.
+	OrderBy []SortField
+}
+
+func (p *Params[T]) Resolve(ctx huma.Context) []error {
+	fs := fieldsOf(reflect.TypeOf((*T)(nil)).Elem())
+
+	// Start over in case the params are resolved more than once.
+	p.Where = nil
+	p.OrderBy = nil
+
+	var errs []error
+	if p.Filter != "" {
+		expr, err := Parse(string(p.Filter))
+		if err == nil {
+			err = check(fs, expr)
+		}
+		if err != nil {
+			errs = append(errs, &huma.ErrorDetail{
+				Message:  err.Error(),
+				Location: "query.filter",
+				Value:    string(p.Filter),
+			})
+		} else {
+			p.Where = expr
+		}
+	}
+
+	if p.Sort != "" {
+		for _, part := range strings.Split(string(p.Sort), ",") {
+			part = strings.TrimSpace(part)
+			sf := SortField{Field: part}
+			if strings.HasPrefix(part, "-") || strings.HasPrefix(part, "+") {
+				sf.Field = part[1:]
+				sf.Desc = part[0] == '-'
+			}
+			if f := fs.byName[sf.Field]; f == nil || !f.sortable {
+				errs = append(errs, &huma.ErrorDetail{
+					Message:  "cannot sort by " + sf.Field,
+					Location: "query.sort",
+					Value:    string(p.Sort),
+				})
+				continue
+			}
+			p.OrderBy = append(p.OrderBy, sf)
+		}
+	}
+	return errs
+}
+
+// check validates the fields & operators of an expression and converts the
+// comparison values to the field types.
+func check(fs *fields, expr Expr) error {
+	switch e := expr.(type) {
+	case *And:
+		if err := check(fs, e.Left); err != nil {
+			return err
+		}
+		return check(fs, e.Right)
+	case *Or:
+		if err := check(fs, e.Left); err != nil {
+			return err
+		}
+		return check(fs, e.Right)
+	case *Not:
+		return check(fs, e.Expr)
+	case *Comparison:
+		f := fs.byName[e.Field]
+		if f == nil || len(f.ops) == 0 {
+			return fmt.Errorf("cannot filter by %s", e.Field)
+		}
+		if !f.allows(e.Op) {
+			return fmt.Errorf("operator %s not allowed for %s", e.Op, e.Field)
+		}
+		if e.Op == In {
+			values := e.Value.([]any)
+			for i, v := range values {
+				parsed, err := parseValue(f.typ, v.(string))
+				if err != nil {
+					return fmt.Errorf("invalid value for %s: %w", e.Field, err)
+				}
+				values[i] = parsed
+			}
+			return nil
+		}
+		parsed, err := parseValue(f.typ, e.Value.(string))
+		if err != nil {
+			return fmt.Errorf("invalid value for %s: %w", e.Field, err)
+		}
+		e.Value = parsed
+	}
+	return nil
+}
@@ -0,0 +1,184 @@
+package filter
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/humatest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type ThingFields struct {
+	Status    string    `json:"status" filter:"eq,ne,in,startswith"`
+	Age       int       `json:"age" filter:"eq,gt,ge,lt,le" sort:"true"`
+	CreatedAt time.Time `json:"created_at" filter:"gt,lt" sort:"true"`
+	Name      string    `json:"name" sort:"true"`
+	Ignored   string
+}
+
+func TestParse(t *testing.T) {
+	for _, tc := range []struct {
+		input    string
+		expected string
+	}{
+		{`status eq active`, `status eq 'active'`},
+		{`status eq 'it''s here' and age gt 30`, `(status eq 'it''s here' and age gt '30')`},
+		{`a eq 1 or b eq 2 and c eq 3`, `(a eq '1' or (b eq '2' and c eq '3'))`},
+		{`(a eq 1 or b eq 2) AND NOT c eq "3"`, `((a eq '1' or b eq '2') and not c eq '3')`},
+		{`status in (a, "b c")`, `status in ('a', 'b c')`},
+	} {
+		t.Run(tc.input, func(t *testing.T) {
+			expr, err := Parse(tc.input)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, expr.String())
+		})
+	}
+
+	for _, input := range []string{
+		``,
+		`status`,
+		`status is active`,
+		`status eq`,
+		`status eq 'active`,
+		`(status eq active`,
+		`status eq active extra`,
+		`status in active`,
+		`status in (a b)`,
+		`status eq (`,
+	} {
+		_, err := Parse(input)
+		assert.Error(t, err, input)
+	}
+
+	deep := ""
+	for i := 0; i < 100; i++ {
+		deep += "not "
+	}
+	_, err := Parse(deep + "a eq 1")
+	assert.ErrorContains(t, err, "nested too deeply")
+}
+
+func TestParams(t *testing.T) {
+	_, api := humatest.New(t)
+
+	var params Params[ThingFields]
+	huma.Register(api, huma.Operation{
+		OperationID: "list-things",
+		Method:      http.MethodGet,
+		Path:        "/things",
+	}, func(ctx context.Context, input *struct {
+		Params[ThingFields]
+	}) (*struct{}, error) {
+		params = input.Params
+		return nil, nil
+	})
+
+	query := url.Values{
+		"filter": {"status in (active, pending) and (age gt 30 or created_at lt 2024-01-02T03:04:05Z)"},
+		"sort":   {"-created_at,name,+age"},
+	}
+	resp := api.Get("/things?" + query.Encode())
+	require.Equal(t, http.StatusNoContent, resp.Code, resp.Body.String())
+
+	assert.Equal(t, &And{
+		&Comparison{Field: "status", Op: In, Value: []any{"active", "pending"}},
+		&Or{
+			&Comparison{Field: "age", Op: Gt, Value: 30},
+			&Comparison{Field: "created_at", Op: Lt, Value: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)},
+		},
+	}, params.Where)
+	assert.Equal(t, []SortField{
+		{Field: "created_at", Desc: true},
+		{Field: "name"},
+		{Field: "age"},
+	}, params.OrderBy)
+
+	resp = api.Get("/things")
+	require.Equal(t, http.StatusNoContent, resp.Code, resp.Body.String())
+	assert.Nil(t, params.Where)
+	assert.Nil(t, params.OrderBy)
+}
+
+func TestParamsErrors(t *testing.T) {
+	_, api := humatest.New(t)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "list-things",
+		Method:      http.MethodGet,
+		Path:        "/things",
+	}, func(ctx context.Context, input *struct {
+		Params[ThingFields]
+	}) (*struct{}, error) {
+		return nil, nil
+	})
+
+	for _, tc := range []struct {
+		query    url.Values
+		location string
+		message  string
+	}{
+		{url.Values{"filter": {"status eq"}}, "query.filter", "expected value"},
+		{url.Values{"filter": {"name eq foo"}}, "query.filter", "cannot filter by name"},
+		{url.Values{"filter": {"Ignored eq foo"}}, "query.filter", "cannot filter by Ignored"},
+		{url.Values{"filter": {"status gt foo"}}, "query.filter", "operator gt not allowed for status"},
+		{url.Values{"filter": {"age eq old"}}, "query.filter", "invalid value for age: expected integer"},
+		{url.Values{"filter": {"created_at gt yesterday"}}, "query.filter", "expected RFC 3339 date-time"},
+		{url.Values{"sort": {"status"}}, "query.sort", "cannot sort by status"},
+	} {
+		t.Run(tc.query.Encode(), func(t *testing.T) {
+			resp := api.Get("/things?" + tc.query.Encode())
+			assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+			assert.Contains(t, resp.Body.String(), `"location":"`+tc.location+`"`)
+			assert.Contains(t, resp.Body.String(), tc.message)
+		})
+	}
+}
+
+func TestOpenAPI(t *testing.T) {
+	_, api := humatest.New(t)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "list-things",
+		Method:      http.MethodGet,
+		Path:        "/things",
+	}, func(ctx context.Context, input *struct {
+		Params[ThingFields]
+	}) (*struct{}, error) {
+		return nil, nil
+	})
+
+	params := map[string]*huma.Param{}
+	for _, p := range api.OpenAPI().Paths["/things"].Get.Parameters {
+		params[p.Name] = p
+	}
+	require.Len(t, params, 2)
+	assert.Equal(t, "query", params["filter"].In)
+	assert.Contains(t, params["filter"].Schema.Description, "- `status`: eq, ne, in, startswith")
+	assert.Contains(t, params["filter"].Schema.Description, "- `created_at`: gt, lt")
+	assert.NotContains(t, params["filter"].Schema.Description, "`name`")
+	assert.Contains(t, params["sort"].Schema.Description, "Sortable fields: `age`, `created_at`, `name`")
+}
+
+func TestInvalidFields(t *testing.T) {
+	assert.Panics(t, func() {
+		fieldsOf(reflect.TypeOf(struct {
+			Tags []string `filter:"eq"`
+		}{}))
+	})
+	assert.Panics(t, func() {
+		fieldsOf(reflect.TypeOf(struct {
+			Age int `filter:"like"`
+		}{}))
+	})
+	assert.Panics(t, func() {
+		fieldsOf(reflect.TypeOf(struct {
+			Age int `filter:"contains"`
+		}{}))
+	})
+}
@@ -0,0 +1,361 @@
+package filter
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxDepth limits the nesting of expressions to prevent deep recursion on
+// malicious input.
+const maxDepth = 32
+
+// Expr is a node in a filter expression: one of `*And`, `*Or`, `*Not`, or
+// `*Comparison`.
+type Expr interface {
+	fmt.Stringer
+	expr()
+}
+
+// And matches when both sides match.
+type And struct {
+	Left, Right Expr
+}
+
+// Or matches when either side matches.
+type Or struct {
+	Left, Right Expr
+}
+
+// Not matches when the expression does not match.
+type Not struct {
+	Expr Expr
+}
+
+// Comparison compares a field to a value. After validation against the
+// declared fields, the value has the field's type, e.g. `int` or
+// `time.Time`, or is a `[]any` of such values for the `in` operator.
+type Comparison struct {
+	Field string
+	Op    Op
+	Value any
+}
+
+func (*And) expr()        {}
+func (*Or) expr()         {}
+func (*Not) expr()        {}
+func (*Comparison) expr() {}
+
+func (e *And) String() string { return "(" + e.Left.String() + " and " + e.Right.String() + ")" }
+func (e *Or) String() string  { return "(" + e.Left.String() + " or " + e.Right.String() + ")" }
+func (e *Not) String() string { return "not " + e.Expr.String() }
+
+func (e *Comparison) String() string {
+	if values, ok := e.Value.([]any); ok {
+		parts := make([]string, len(values))
+		for i, v := range values {
+			parts[i] = formatValue(v)
+		}
+		return e.Field + " " + string(e.Op) + " (" + strings.Join(parts, ", ") + ")"
+	}
+	return e.Field + " " + string(e.Op) + " " + formatValue(e.Value)
+}
+
+func formatValue(v any) string {
+	switch v := v.(type) {
+	case string:
+		return "'" + strings.ReplaceAll(v, "'", "''") + "'"
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	}
+	return fmt.Sprint(v)
+}
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokWord
+	tokString
+	tokLParen
+	tokRParen
+	tokComma
+)
+
+type token struct {
+	kind  tokenKind
+	value string
+	pos   int
+}
+
+// lex splits an expression into tokens. Words are runs of characters other
+// than whitespace, parentheses, commas, and quotes. Strings are quoted with
+// single or double quotes, where a doubled quote is an escaped quote.
+func lex(s string) ([]token, error) {
+	tokens := []token{}
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '(':
+			tokens = append(tokens, token{tokLParen, "(", i})
+			i++
+		case c == ')':
+			tokens = append(tokens, token{tokRParen, ")", i})
+			i++
+		case c == ',':
+			tokens = append(tokens, token{tokComma, ",", i})
+			i++
+		case c == '\'' || c == '"':
+			start := i
+			var sb strings.Builder
+			i++
+			for {
+				if i >= len(s) {
+					return nil, fmt.Errorf("unterminated string at position %d", start)
+				}
+				if s[i] == c {
+					if i+1 < len(s) && s[i+1] == c {
+						sb.WriteByte(c)
+						i += 2
+						continue
+					}
+					i++
+					break
+				}
+				sb.WriteByte(s[i])
+				i++
+			}
+			tokens = append(tokens, token{tokString, sb.String(), start})
+		default:
+			start := i
+			for i < len(s) && !strings.ContainsRune(" \t\n\r(),'\"", rune(s[i])) {
+				i++
+			}
+			tokens = append(tokens, token{tokWord, s[start:i], start})
+		}
+	}
+	return append(tokens, token{tokEOF, "", len(s)}), nil
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+	depth  int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+// keyword returns whether the next token is the given case-insensitive
+// keyword, consuming it if so.
+func (p *parser) keyword(k string) bool {
+	if t := p.peek(); t.kind == tokWord && strings.EqualFold(t.value, k) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+// Parse parses a filter expression. Comparison values are strings until the
+// expression is validated against declared fields by `Params`. The grammar
+// is:
+//
+//	expr       = and ("or" and)*
+//	and        = unary ("and" unary)*
+//	unary      = "not" unary | "(" expr ")" | comparison
+//	comparison = field op value | field "in" "(" value ("," value)* ")"
+func Parse(s string) (Expr, error) {
+	tokens, err := lex(s)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	expr, err := p.or()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokEOF {
+		return nil, fmt.Errorf("unexpected %q at position %d", t.value, t.pos)
+	}
+	return expr, nil
+}
+
+func (p *parser) or() (Expr, error) {
+	left, err := p.and()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("or") {
+		right, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+		left = &Or{left, right}
+	}
+	return left, nil
+}
+
+func (p *parser) and() (Expr, error) {
+	left, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("and") {
+		right, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		left = &And{left, right}
+	}
+	return left, nil
+}
+
+func (p *parser) unary() (Expr, error) {
+	p.depth++
+	defer func() { p.depth-- }()
+	if p.depth > maxDepth {
+		return nil, fmt.Errorf("expression nested too deeply at position %d", p.peek().pos)
+	}
+
+	if p.keyword("not") {
+		e, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return &Not{e}, nil
+	}
+	if p.peek().kind == tokLParen {
+		p.next()
+		e, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		if t := p.next(); t.kind != tokRParen {
+			return nil, fmt.Errorf("expected ) at position %d", t.pos)
+		}
+		return e, nil
+	}
+	return p.comparison()
+}
+
+func (p *parser) comparison() (Expr, error) {
+	f := p.next()
+	if f.kind != tokWord {
+		return nil, fmt.Errorf("expected field at position %d", f.pos)
+	}
+	o := p.next()
+	op := Op(strings.ToLower(o.value))
+	if o.kind != tokWord || !ops[op] {
+		return nil, fmt.Errorf("expected operator at position %d", o.pos)
+	}
+
+	if op != In {
+		v, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		return &Comparison{Field: f.value, Op: op, Value: v}, nil
+	}
+
+	if t := p.next(); t.kind != tokLParen {
+		return nil, fmt.Errorf("expected ( at position %d", t.pos)
+	}
+	values := []any{}
+	for {
+		v, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+		t := p.next()
+		if t.kind == tokRParen {
+			break
+		}
+		if t.kind != tokComma {
+			return nil, fmt.Errorf("expected , or ) at position %d", t.pos)
+		}
+	}
+	return &Comparison{Field: f.value, Op: op, Value: values}, nil
+}
+
+func (p *parser) value() (string, error) {
+	t := p.next()
+	if t.kind != tokWord && t.kind != tokString {
+		return "", fmt.Errorf("expected value at position %d", t.pos)
+	}
+	return t.value, nil
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// supported returns whether values of a field type can be parsed.
+func supported(t reflect.Type) bool {
+	if t == timeType {
+		return true
+	}
+	switch t.Kind() {
+	case reflect.String, reflect.Bool, reflect.Float32, reflect.Float64,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+	return false
+}
+
+// parseValue converts a string value to the given field type.
+func parseValue(t reflect.Type, value string) (any, error) {
+	if t == timeType {
+		v, err := time.Parse(time.RFC3339Nano, value)
+		if err != nil {
+			return nil, errors.New("expected RFC 3339 date-time")
+		}
+		return v, nil
+	}
+
+	var v reflect.Value
+	switch t.Kind() {
+	case reflect.String:
+		v = reflect.ValueOf(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, errors.New("expected boolean")
+		}
+		v = reflect.ValueOf(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(value, 10, t.Bits())
+		if err != nil {
+			return nil, errors.New("expected integer")
+		}
+		v = reflect.ValueOf(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(value, 10, t.Bits())
+		if err != nil {
+			return nil, errors.New("expected integer")
+		}
+		v = reflect.ValueOf(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(value, t.Bits())
+		if err != nil {
+			return nil, errors.New("expected number")
+		}
+		v = reflect.ValueOf(f)
+	default:
+		return nil, errors.New("unsupported type")
+	}
+	return v.Convert(t).Interface(), nil
+}
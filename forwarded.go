@@ -0,0 +1,273 @@
+package huma
+
+import (
+	"errors"
+	"net/netip"
+	"strings"
+)
+
+var errForwardedFormat = errors.New("invalid Forwarded header")
+
+// ForwardedNode identifies a client or proxy in a `Forwarded` header as
+// described in RFC 7239 section 6. It is either an IP address, the literal
+// `unknown`, or an obfuscated identifier starting with an underscore, each
+// with an optional port.
+type ForwardedNode struct {
+	// IP is the node's address, which is invalid for unknown and obfuscated
+	// nodes.
+	IP netip.Addr
+
+	// Name is the `unknown` or obfuscated identifier such as `_hidden`, and
+	// empty when the address is known.
+	Name string
+
+	// Port is the port or obfuscated port such as `_1234`, if any.
+	Port string
+}
+
+// IsUnknown returns whether the proxy did not know the node's identity.
+func (n ForwardedNode) IsUnknown() bool {
+	return n.Name == "unknown"
+}
+
+// IsObfuscated returns whether the proxy hid the node's identity behind an
+// obfuscated identifier.
+func (n ForwardedNode) IsObfuscated() bool {
+	return strings.HasPrefix(n.Name, "_")
+}
+
+// String returns the node in its `Forwarded` header format, e.g.
+// `[2001:db8::1]:8080` or `_hidden`.
+func (n ForwardedNode) String() string {
+	s := n.Name
+	if n.IP.IsValid() {
+		s = n.IP.String()
+		if n.IP.Is6() && n.Port != "" {
+			s = "[" + s + "]"
+		}
+	}
+	if n.Port != "" {
+		s += ":" + n.Port
+	}
+	return s
+}
+
+// Forwarded is one element of a `Forwarded` header as described in RFC 7239,
+// added by a single proxy along the way.
+type Forwarded struct {
+	// For is the node which made the request to the proxy.
+	For ForwardedNode
+
+	// By is the proxy's interface which received the request.
+	By ForwardedNode
+
+	// Host is the `Host` header received by the proxy.
+	Host string
+
+	// Proto is the lowercase scheme used to make the request to the proxy,
+	// e.g. `https`.
+	Proto string
+}
+
+// ParseForwarded parses the value of one or more `Forwarded` headers, joined
+// with commas, into its elements in the order they were added by proxies, so
+// the last element is from the nearest proxy. Unrecognized parameters are
+// ignored.
+//
+//	elements, err := huma.ParseForwarded(`for=192.0.2.60;proto=http;by=203.0.113.43, for="[2001:db8:cafe::17]:4711"`)
+func ParseForwarded(value string) ([]Forwarded, error) {
+	elements := []Forwarded{}
+	p := forwardedParser{s: value}
+	for {
+		p.skipSpace()
+		if p.done() {
+			break
+		}
+		f := Forwarded{}
+		for {
+			p.skipSpace()
+			name := strings.ToLower(p.token())
+			if name == "" || !p.consume('=') {
+				return nil, errForwardedFormat
+			}
+			v, ok := p.value()
+			if !ok {
+				return nil, errForwardedFormat
+			}
+			var err error
+			switch name {
+			case "for":
+				f.For, err = parseForwardedNode(v)
+			case "by":
+				f.By, err = parseForwardedNode(v)
+			case "host":
+				f.Host = v
+			case "proto":
+				f.Proto = strings.ToLower(v)
+			}
+			if err != nil {
+				return nil, err
+			}
+			p.skipSpace()
+			if !p.consume(';') {
+				break
+			}
+		}
+		elements = append(elements, f)
+		if !p.consume(',') {
+			p.skipSpace()
+			if !p.done() {
+				return nil, errForwardedFormat
+			}
+		}
+	}
+	return elements, nil
+}
+
+// parseForwardedNode parses a `for` or `by` node like `192.0.2.43:47011`,
+// `[2001:db8:cafe::17]`, `unknown`, or `_hidden:_port`.
+func parseForwardedNode(v string) (ForwardedNode, error) {
+	n := ForwardedNode{}
+	host := v
+	if strings.HasPrefix(v, "[") {
+		end := strings.IndexByte(v, ']')
+		if end < 0 {
+			return n, errForwardedFormat
+		}
+		host = v[1:end]
+		if rest := v[end+1:]; rest != "" {
+			if rest[0] != ':' {
+				return n, errForwardedFormat
+			}
+			n.Port = rest[1:]
+		}
+		addr, err := netip.ParseAddr(host)
+		if err != nil || !addr.Is6() {
+			return n, errForwardedFormat
+		}
+		n.IP = addr
+	} else {
+		if i := strings.IndexByte(v, ':'); i >= 0 {
+			host, n.Port = v[:i], v[i+1:]
+		}
+		if host == "unknown" || strings.HasPrefix(host, "_") {
+			n.Name = host
+		} else {
+			addr, err := netip.ParseAddr(host)
+			if err != nil || !addr.Is4() {
+				return n, errForwardedFormat
+			}
+			n.IP = addr
+		}
+	}
+	if n.Port == "" && strings.HasSuffix(v, ":") {
+		return n, errForwardedFormat
+	}
+	if n.Name != "" && !isObfuscatedNode(n.Name) {
+		return n, errForwardedFormat
+	}
+	if n.Port != "" && !isForwardedPort(n.Port) {
+		return n, errForwardedFormat
+	}
+	return n, nil
+}
+
+// isObfuscatedNode returns whether the name is `unknown` or a valid
+// obfuscated identifier.
+func isObfuscatedNode(name string) bool {
+	if name == "unknown" {
+		return true
+	}
+	if len(name) < 2 {
+		return false
+	}
+	for i := 1; i < len(name); i++ {
+		c := name[i]
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '.' || c == '_' || c == '-') {
+			return false
+		}
+	}
+	return true
+}
+
+// isForwardedPort returns whether the port is numeric or obfuscated.
+func isForwardedPort(port string) bool {
+	if strings.HasPrefix(port, "_") {
+		return isObfuscatedNode(port)
+	}
+	if len(port) > 5 {
+		return false
+	}
+	for i := 0; i < len(port); i++ {
+		if port[i] < '0' || port[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// forwardedParser scans `token=value` pairs where values may be quoted.
+type forwardedParser struct {
+	s   string
+	pos int
+}
+
+func (p *forwardedParser) done() bool {
+	return p.pos >= len(p.s)
+}
+
+func (p *forwardedParser) skipSpace() {
+	for !p.done() && (p.s[p.pos] == ' ' || p.s[p.pos] == '\t') {
+		p.pos++
+	}
+}
+
+func (p *forwardedParser) consume(c byte) bool {
+	if !p.done() && p.s[p.pos] == c {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *forwardedParser) token() string {
+	start := p.pos
+	for !p.done() && isTokenChar(p.s[p.pos]) {
+		p.pos++
+	}
+	return p.s[start:p.pos]
+}
+
+func (p *forwardedParser) value() (string, bool) {
+	if !p.consume('"') {
+		t := p.token()
+		return t, t != ""
+	}
+	sb := strings.Builder{}
+	for !p.done() {
+		c := p.s[p.pos]
+		p.pos++
+		switch c {
+		case '"':
+			return sb.String(), true
+		case '\\':
+			if p.done() {
+				return "", false
+			}
+			sb.WriteByte(p.s[p.pos])
+			p.pos++
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	return "", false
+}
+
+// isTokenChar returns whether `c` is allowed in an RFC 9110 token.
+func isTokenChar(c byte) bool {
+	switch {
+	case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		return true
+	}
+	return strings.IndexByte("!#$%&'*+-.^_`|~", c) >= 0
+}
@@ -0,0 +1,62 @@
+package huma_test
+
+import (
+	"net/netip"
+	"testing"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseForwarded(t *testing.T) {
+	elements, err := huma.ParseForwarded(`For="[2001:db8:cafe::17]:4711";proto=HTTPS;host="example.com", for=192.0.2.60;by=203.0.113.43 ,for=unknown;by="_hidden:_port", for="_abc-1.x:80";ext="a\"b,c;d"`)
+	require.NoError(t, err)
+	assert.Equal(t, []huma.Forwarded{
+		{
+			For:   huma.ForwardedNode{IP: netip.MustParseAddr("2001:db8:cafe::17"), Port: "4711"},
+			Proto: "https",
+			Host:  "example.com",
+		},
+		{
+			For: huma.ForwardedNode{IP: netip.MustParseAddr("192.0.2.60")},
+			By:  huma.ForwardedNode{IP: netip.MustParseAddr("203.0.113.43")},
+		},
+		{
+			For: huma.ForwardedNode{Name: "unknown"},
+			By:  huma.ForwardedNode{Name: "_hidden", Port: "_port"},
+		},
+		{
+			For: huma.ForwardedNode{Name: "_abc-1.x", Port: "80"},
+		},
+	}, elements)
+
+	assert.True(t, elements[2].For.IsUnknown())
+	assert.False(t, elements[2].For.IsObfuscated())
+	assert.True(t, elements[2].By.IsObfuscated())
+	assert.Equal(t, "[2001:db8:cafe::17]:4711", elements[0].For.String())
+	assert.Equal(t, "192.0.2.60", elements[1].For.String())
+	assert.Equal(t, "_hidden:_port", elements[2].By.String())
+}
+
+func TestParseForwardedInvalid(t *testing.T) {
+	for _, value := range []string{
+		`for`,
+		`for=`,
+		`for=192.0.2.60;`,
+		`for=192.0.2.60 for=192.0.2.61`,
+		`for="unterminated`,
+		`for=2001:db8::1`,
+		`for="[192.0.2.60]"`,
+		`for="[2001:db8::1"`,
+		`for=192.0.2.60:http`,
+		`for=192.0.2.60:`,
+		`for=_`,
+		`for=example.com`,
+	} {
+		t.Run(value, func(t *testing.T) {
+			_, err := huma.ParseForwarded(value)
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,499 @@
+// Package graphql exposes the operations registered with a Huma API as a
+// GraphQL schema, so clients can fetch several resources in a single request
+// and select only the fields they need. Each GraphQL field is resolved by
+// calling the operation's `huma.OperationHandler` internally, so API
+// middleware, validation, and error handling all behave the same as for REST
+// requests.
+//
+// `GET` operations become fields of the `Query` type, and other operations
+// become fields of the `Mutation` type. Field names are the lower camel case
+// operation IDs, path and query parameters become arguments, and request
+// bodies are passed via a `body` argument:
+//
+//	graphql.Expose(api, graphql.Config{})
+//
+//	// query {
+//	//   a: getThing(thingId: "a") { name }
+//	//   b: getThing(thingId: "b") { name }
+//	// }
+//
+// Expose should be called after registering operations. The generated schema
+// in GraphQL SDL is served at `{Path}/schema`.
+package graphql
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/danielgtaylor/casing"
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// Config configures the GraphQL endpoint.
+type Config struct {
+	// Path is the URL path of the GraphQL endpoint. Defaults to `/graphql`.
+	Path string
+
+	// Filter decides which operations are exposed. If nil, all operations
+	// with an operation ID are exposed.
+	Filter func(op *huma.Operation) bool
+}
+
+// field is a GraphQL field backed by an operation.
+type field struct {
+	name string
+	op   *huma.Operation
+
+	// args maps argument names to the parameters they set.
+	args map[string]*huma.Param
+}
+
+type gateway struct {
+	api    huma.API
+	config Config
+
+	mu        sync.Mutex
+	queries   map[string]*field
+	mutations map[string]*field
+	sdl       string
+}
+
+// Expose adds a GraphQL endpoint to the API at `config.Path` for `GET` and
+// `POST` requests, using the standard GraphQL over HTTP request format with
+// `query`, `variables`, and `operationName`.
+func Expose(api huma.API, config Config) {
+	if config.Path == "" {
+		config.Path = "/graphql"
+	}
+	g := &gateway{api: api, config: config}
+
+	oapi := api.OpenAPI()
+	oapi.OnAddOperation = append(oapi.OnAddOperation, func(oapi *huma.OpenAPI, op *huma.Operation) {
+		g.mu.Lock()
+		defer g.mu.Unlock()
+		g.queries = nil
+	})
+
+	adapter := api.Adapter()
+	adapter.Handle(&huma.Operation{Method: http.MethodGet, Path: config.Path}, g.handle)
+	adapter.Handle(&huma.Operation{Method: http.MethodPost, Path: config.Path}, g.handle)
+	adapter.Handle(&huma.Operation{Method: http.MethodGet, Path: config.Path + "/schema"}, func(ctx huma.Context) {
+		_, _, sdl := g.fields()
+		ctx.SetHeader("Content-Type", "text/plain; charset=utf-8")
+		ctx.BodyWriter().Write([]byte(sdl))
+	})
+}
+
+// fields returns the query and mutation fields and the SDL for the schema,
+// building them from the OpenAPI if any operations have been added.
+func (g *gateway) fields() (map[string]*field, map[string]*field, string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.queries != nil {
+		return g.queries, g.mutations, g.sdl
+	}
+
+	g.queries = map[string]*field{}
+	g.mutations = map[string]*field{}
+	for _, item := range g.api.OpenAPI().Paths {
+		for _, op := range []*huma.Operation{item.Get, item.Put, item.Post, item.Delete, item.Patch} {
+			if op == nil || op.OperationID == "" || (g.config.Filter != nil && !g.config.Filter(op)) {
+				continue
+			}
+			f := &field{name: casing.LowerCamel(op.OperationID), op: op, args: map[string]*huma.Param{}}
+			for _, p := range op.Parameters {
+				if p.In == "path" || p.In == "query" {
+					f.args[casing.LowerCamel(p.Name)] = p
+				}
+			}
+			if op.Method == http.MethodGet {
+				g.queries[f.name] = f
+			} else {
+				g.mutations[f.name] = f
+			}
+		}
+	}
+	g.sdl = newSchemaBuilder(g.api.OpenAPI().Components.Schemas).build(g.queries, g.mutations)
+	return g.queries, g.mutations, g.sdl
+}
+
+// request is a GraphQL over HTTP request.
+type request struct {
+	Query         string         `json:"query"`
+	Variables     map[string]any `json:"variables,omitempty"`
+	OperationName string         `json:"operationName,omitempty"`
+}
+
+// gqlError is an error in a GraphQL response.
+type gqlError struct {
+	Message string `json:"message"`
+	Path    []any  `json:"path,omitempty"`
+}
+
+// response is a GraphQL over HTTP response.
+type response struct {
+	Data   any         `json:"data"`
+	Errors []*gqlError `json:"errors,omitempty"`
+}
+
+func (g *gateway) handle(ctx huma.Context) {
+	var req request
+	if ctx.Method() == http.MethodGet {
+		u := ctx.URL()
+		q := u.Query()
+		req.Query = q.Get("query")
+		req.OperationName = q.Get("operationName")
+		if v := q.Get("variables"); v != "" {
+			if err := json.Unmarshal([]byte(v), &req.Variables); err != nil {
+				writeResponse(ctx, http.StatusBadRequest, &response{Errors: []*gqlError{{Message: "invalid variables: " + err.Error()}}})
+				return
+			}
+		}
+	} else if err := json.NewDecoder(huma.LimitBody(g.api, ctx)).Decode(&req); err != nil {
+		var mbe *http.MaxBytesError
+		if errors.As(err, &mbe) {
+			writeResponse(ctx, http.StatusRequestEntityTooLarge, &response{Errors: []*gqlError{{Message: fmt.Sprintf("request body is too large limit=%d bytes", mbe.Limit)}}})
+			return
+		}
+		writeResponse(ctx, http.StatusBadRequest, &response{Errors: []*gqlError{{Message: "invalid request: " + err.Error()}}})
+		return
+	}
+
+	resp, status := g.execute(ctx, &req)
+	writeResponse(ctx, status, resp)
+}
+
+func writeResponse(ctx huma.Context, status int, resp *response) {
+	ctx.SetHeader("Content-Type", "application/json")
+	ctx.SetStatus(status)
+	json.NewEncoder(ctx.BodyWriter()).Encode(resp)
+}
+
+// execute runs a GraphQL request and returns the response along with the
+// HTTP status code to send.
+func (g *gateway) execute(ctx huma.Context, req *request) (*response, int) {
+	doc, err := parse(req.Query)
+	if err != nil {
+		return &response{Errors: []*gqlError{{Message: err.Error()}}}, http.StatusBadRequest
+	}
+
+	var op *operation
+	for _, o := range doc.operations {
+		if req.OperationName == "" || o.name == req.OperationName {
+			if op != nil {
+				return &response{Errors: []*gqlError{{Message: "operationName is required for documents with multiple operations"}}}, http.StatusBadRequest
+			}
+			op = o
+		}
+	}
+	if op == nil {
+		return &response{Errors: []*gqlError{{Message: "unknown operation " + req.OperationName}}}, http.StatusBadRequest
+	}
+
+	queries, mutations, _ := g.fields()
+	fields := queries
+	typeName := "Query"
+	switch op.kind {
+	case "mutation":
+		if ctx.Method() != http.MethodPost {
+			return &response{Errors: []*gqlError{{Message: "mutations require a POST request"}}}, http.StatusMethodNotAllowed
+		}
+		fields = mutations
+		typeName = "Mutation"
+	case "subscription":
+		return &response{Errors: []*gqlError{{Message: "subscriptions are not supported"}}}, http.StatusBadRequest
+	}
+
+	vars := map[string]any{}
+	for k, v := range op.variables {
+		vars[k] = resolveValue(v, nil)
+	}
+	for k, v := range req.Variables {
+		vars[k] = v
+	}
+
+	ex := &executor{gateway: g, ctx: ctx, fragments: doc.fragments, vars: vars}
+	sels, err := ex.collect(op.selections)
+	if err != nil {
+		return &response{Errors: []*gqlError{{Message: err.Error()}}}, http.StatusBadRequest
+	}
+
+	for _, sel := range sels {
+		if sel.name != "__typename" && fields[sel.name] == nil {
+			return &response{Errors: []*gqlError{{Message: fmt.Sprintf("cannot query field %q on type %q", sel.name, typeName)}}}, http.StatusBadRequest
+		}
+	}
+
+	data := make(object, len(sels))
+	var wg sync.WaitGroup
+	for i, sel := range sels {
+		data[i].key = sel.key()
+		if sel.name == "__typename" {
+			data[i].value = typeName
+			continue
+		}
+		if op.kind == "mutation" {
+			// Mutations run one after another in order.
+			data[i].value = ex.resolve(fields[sel.name], sel)
+			continue
+		}
+		wg.Add(1)
+		go func(i int, sel *selection) {
+			defer wg.Done()
+			data[i].value = ex.resolve(fields[sel.name], sel)
+		}(i, sel)
+	}
+	wg.Wait()
+
+	return &response{Data: data, Errors: ex.errors}, http.StatusOK
+}
+
+// object is a JSON object which keeps its keys in the order they were
+// selected, as required by GraphQL.
+type object []struct {
+	key   string
+	value any
+}
+
+func (o object) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, f := range o {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		k, _ := json.Marshal(f.key)
+		buf.Write(k)
+		buf.WriteByte(':')
+		v, err := json.Marshal(f.value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(v)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// executor resolves the fields of a single GraphQL operation.
+type executor struct {
+	gateway   *gateway
+	ctx       huma.Context
+	fragments map[string]*fragment
+	vars      map[string]any
+
+	mu     sync.Mutex
+	errors []*gqlError
+}
+
+func (ex *executor) addError(msg string, path ...any) {
+	ex.mu.Lock()
+	defer ex.mu.Unlock()
+	ex.errors = append(ex.errors, &gqlError{Message: msg, Path: path})
+}
+
+// collect flattens fragment spreads and inline fragments into a list of
+// fields, dropping those excluded by `@skip` or `@include`.
+func (ex *executor) collect(sels []*selection) ([]*selection, error) {
+	out := []*selection{}
+	for _, sel := range sels {
+		if skip, _ := resolveValue(sel.directives["skip"]["if"], ex.vars).(bool); skip {
+			continue
+		}
+		if inc, ok := sel.directives["include"]; ok {
+			if include, _ := resolveValue(inc["if"], ex.vars).(bool); !include {
+				continue
+			}
+		}
+		switch {
+		case sel.spread != "":
+			f := ex.fragments[sel.spread]
+			if f == nil {
+				return nil, fmt.Errorf("unknown fragment %q", sel.spread)
+			}
+			nested, err := ex.collect(f.selections)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, nested...)
+		case sel.inline:
+			nested, err := ex.collect(sel.selections)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, nested...)
+		default:
+			out = append(out, sel)
+		}
+	}
+	return out, nil
+}
+
+// resolve calls the operation for a field and projects its response body
+// onto the field's selection set.
+func (ex *executor) resolve(f *field, sel *selection) any {
+	path := f.op.Path
+	query := url.Values{}
+	var body io.Reader
+	for name, v := range sel.args {
+		value := resolveValue(v, ex.vars)
+		if name == "body" && f.op.RequestBody != nil {
+			b, err := json.Marshal(value)
+			if err != nil {
+				ex.addError(err.Error(), sel.key())
+				return nil
+			}
+			body = bytes.NewReader(b)
+			continue
+		}
+		p := f.args[name]
+		if p == nil {
+			ex.addError(fmt.Sprintf("unknown argument %q on field %q", name, f.name), sel.key())
+			return nil
+		}
+		if value == nil {
+			continue
+		}
+		if p.In == "path" {
+			path = strings.ReplaceAll(path, "{"+p.Name+"}", url.PathEscape(formatArg(value)))
+		} else {
+			query.Set(p.Name, formatArg(value))
+		}
+	}
+	if strings.Contains(path, "{") {
+		ex.addError(fmt.Sprintf("missing required path argument on field %q", f.name), sel.key())
+		return nil
+	}
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+
+	req, err := newRequest(ex.ctx, f.op.Method, path, body)
+	if err != nil {
+		ex.addError(err.Error(), sel.key())
+		return nil
+	}
+	rec := httptest.NewRecorder()
+	huma.OperationHandler(ex.gateway.api, f.op.OperationID).ServeHTTP(rec, req)
+
+	var result any
+	if rec.Body.Len() > 0 {
+		if err := json.Unmarshal(rec.Body.Bytes(), &result); err != nil {
+			ex.addError("invalid response: "+err.Error(), sel.key())
+			return nil
+		}
+	}
+	if rec.Code >= http.StatusBadRequest {
+		msg := http.StatusText(rec.Code)
+		if m, ok := result.(map[string]any); ok {
+			if detail, ok := m["detail"].(string); ok && detail != "" {
+				msg = detail
+			} else if title, ok := m["title"].(string); ok && title != "" {
+				msg = title
+			}
+		}
+		ex.addError(msg, sel.key())
+		return nil
+	}
+
+	value, err := ex.project(result, sel.selections)
+	if err != nil {
+		ex.addError(err.Error(), sel.key())
+		return nil
+	}
+	return value
+}
+
+// newRequest creates an internal request for an operation, forwarding the
+// headers of the GraphQL request, e.g. for authentication.
+func newRequest(ctx huma.Context, method, path string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx.Context(), method, path, body)
+	if err != nil {
+		return nil, err
+	}
+	ctx.EachHeader(func(name, value string) {
+		switch http.CanonicalHeaderKey(name) {
+		case "Accept", "Accept-Encoding", "Content-Length", "Content-Type":
+			return
+		}
+		req.Header.Add(name, value)
+	})
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Host = ctx.Host()
+	req.RemoteAddr = huma.RemoteAddr(ctx)
+	return req, nil
+}
+
+// formatArg formats an argument value as a parameter, joining lists with
+// commas as Huma expects.
+func formatArg(v any) string {
+	if list, ok := v.([]any); ok {
+		parts := make([]string, len(list))
+		for i, item := range list {
+			parts[i] = formatArg(item)
+		}
+		return strings.Join(parts, ",")
+	}
+	if f, ok := v.(float64); ok && f == float64(int64(f)) {
+		return fmt.Sprint(int64(f))
+	}
+	return fmt.Sprint(v)
+}
+
+// project returns only the selected fields of a value. Values without a
+// selection set are returned as-is.
+func (ex *executor) project(v any, sels []*selection) (any, error) {
+	if len(sels) == 0 {
+		return v, nil
+	}
+	switch v := v.(type) {
+	case []any:
+		out := make([]any, len(v))
+		for i, item := range v {
+			projected, err := ex.project(item, sels)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = projected
+		}
+		return out, nil
+	case map[string]any:
+		fields, err := ex.collect(sels)
+		if err != nil {
+			return nil, err
+		}
+		out := make(object, len(fields))
+		for i, sel := range fields {
+			out[i].key = sel.key()
+			projected, err := ex.project(v[sel.name], sel.selections)
+			if err != nil {
+				return nil, err
+			}
+			out[i].value = projected
+		}
+		return out, nil
+	}
+	return v, nil
+}
+
+// sortedKeys returns the keys of a field map in order.
+func sortedKeys(fields map[string]*field) []string {
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,288 @@
+package graphql
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/humatest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type Thing struct {
+	ID    string   `json:"id"`
+	Name  string   `json:"name"`
+	Tags  []string `json:"tags,omitempty"`
+	Owner *Owner   `json:"owner,omitempty"`
+}
+
+type Owner struct {
+	Email string `json:"email"`
+}
+
+func setup(t *testing.T) humatest.TestAPI {
+	_, api := humatest.New(t)
+
+	things := map[string]*Thing{
+		"a": {ID: "a", Name: "Thing A", Tags: []string{"x"}, Owner: &Owner{Email: "a@example.com"}},
+		"b": {ID: "b", Name: "Thing B"},
+	}
+
+	huma.Register(api, huma.Operation{
+		OperationID: "get-thing",
+		Method:      http.MethodGet,
+		Path:        "/things/{thing-id}",
+		Summary:     "Get a thing",
+	}, func(ctx context.Context, input *struct {
+		ThingID string `path:"thing-id"`
+		Auth    string `header:"Authorization"`
+	}) (*struct{ Body *Thing }, error) {
+		thing := things[input.ThingID]
+		if thing == nil {
+			return nil, huma.Error404NotFound("thing not found")
+		}
+		if input.Auth != "" {
+			thing.Name += " (" + input.Auth + ")"
+		}
+		return &struct{ Body *Thing }{thing}, nil
+	})
+
+	huma.Register(api, huma.Operation{
+		OperationID: "get-new-thing",
+		Method:      http.MethodGet,
+		Path:        "/things/new",
+	}, func(ctx context.Context, input *struct{}) (*struct{ Body *Thing }, error) {
+		return &struct{ Body *Thing }{&Thing{ID: "template"}}, nil
+	})
+
+	huma.Register(api, huma.Operation{
+		OperationID: "list-things",
+		Method:      http.MethodGet,
+		Path:        "/things",
+	}, func(ctx context.Context, input *struct {
+		Limit int `query:"limit"`
+	}) (*struct{ Body []*Thing }, error) {
+		list := []*Thing{things["a"], things["b"]}
+		if input.Limit > 0 && input.Limit < len(list) {
+			list = list[:input.Limit]
+		}
+		return &struct{ Body []*Thing }{list}, nil
+	})
+
+	huma.Register(api, huma.Operation{
+		OperationID: "put-thing",
+		Method:      http.MethodPut,
+		Path:        "/things/{thing-id}",
+	}, func(ctx context.Context, input *struct {
+		ThingID string `path:"thing-id"`
+		Body    struct {
+			Name string `json:"name"`
+		}
+	}) (*struct{ Body *Thing }, error) {
+		thing := &Thing{ID: input.ThingID, Name: input.Body.Name}
+		things[input.ThingID] = thing
+		return &struct{ Body *Thing }{thing}, nil
+	})
+
+	Expose(api, Config{})
+	return api
+}
+
+func TestQuery(t *testing.T) {
+	api := setup(t)
+
+	resp := api.Post("/graphql", map[string]any{
+		"query": `query Things($id: String!) {
+			a: getThing(thingId: $id) { name ...OwnerFields }
+			b: getThing(thingId: "b") { id, name }
+			listThings(limit: 1) { id tags @include(if: false) }
+			__typename
+		}
+		fragment OwnerFields on Thing { owner { email } }`,
+		"variables": map[string]any{"id": "a"},
+	})
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, `{
+		"data": {
+			"a": {"name": "Thing A", "owner": {"email": "a@example.com"}},
+			"b": {"id": "b", "name": "Thing B"},
+			"listThings": [{"id": "a"}],
+			"__typename": "Query"
+		}
+	}`, resp.Body.String())
+
+	// Fields keep the order they were selected in.
+	assert.True(t, strings.HasPrefix(resp.Body.String(), `{"data":{"a":{"name"`))
+}
+
+func TestQueryGet(t *testing.T) {
+	api := setup(t)
+
+	resp := api.Get("/graphql?query=" + url.QueryEscape(`{ getThing(thingId: "b") { name } }`))
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, `{"data": {"getThing": {"name": "Thing B"}}}`, resp.Body.String())
+
+	// Mutations are not allowed via GET.
+	resp = api.Get("/graphql?query=" + url.QueryEscape(`mutation { putThing(thingId: "c", body: {name: "C"}) { id } }`))
+	assert.Equal(t, http.StatusMethodNotAllowed, resp.Code)
+}
+
+func TestForwardHeaders(t *testing.T) {
+	api := setup(t)
+
+	resp := api.Post("/graphql", "Authorization: abc", map[string]any{
+		"query": `{ getThing(thingId: "b") { name } }`,
+	})
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, `{"data": {"getThing": {"name": "Thing B (abc)"}}}`, resp.Body.String())
+}
+
+func TestMutation(t *testing.T) {
+	api := setup(t)
+
+	resp := api.Post("/graphql", map[string]any{
+		"query": `mutation {
+			putThing(thingId: "c", body: {name: "Thing C"}) { id name }
+		}
+		query { getThing(thingId: "c") { name } }`,
+	})
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+	assert.Contains(t, resp.Body.String(), "operationName is required")
+
+	resp = api.Post("/graphql", map[string]any{
+		"query":         `mutation Create($name: String) { putThing(thingId: "c", body: {name: $name}) { id name } }`,
+		"variables":     map[string]any{"name": "Thing C"},
+		"operationName": "Create",
+	})
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, `{"data": {"putThing": {"id": "c", "name": "Thing C"}}}`, resp.Body.String())
+
+	resp = api.Post("/graphql", map[string]any{
+		"query": `{ getThing(thingId: "c") { name } }`,
+	})
+	assert.JSONEq(t, `{"data": {"getThing": {"name": "Thing C"}}}`, resp.Body.String())
+}
+
+func TestErrors(t *testing.T) {
+	api := setup(t)
+
+	resp := api.Post("/graphql", map[string]any{
+		"query": `{ found: getThing(thingId: "a") { id } missing: getThing(thingId: "z") { id } }`,
+	})
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, `{
+		"data": {"found": {"id": "a"}, "missing": null},
+		"errors": [{"message": "thing not found", "path": ["missing"]}]
+	}`, resp.Body.String())
+
+	for _, query := range []string{
+		`{ getThing(thingId: "a") { id }`,
+		`{ unknown { id } }`,
+		`subscription { getThing { id } }`,
+		`query { a } fragment F on Thing { ...G }`,
+	} {
+		t.Run(query, func(t *testing.T) {
+			resp := api.Post("/graphql", map[string]any{"query": query})
+			assert.Equal(t, http.StatusBadRequest, resp.Code)
+			assert.Contains(t, resp.Body.String(), `"errors"`)
+		})
+	}
+}
+
+func TestResolveOperation(t *testing.T) {
+	api := setup(t)
+
+	// Fields call their own operation, even when the router would match the
+	// request path to a different one.
+	resp := api.Post("/graphql", map[string]any{
+		"query": `{ getThing(thingId: "new") { id } getNewThing { id } }`,
+	})
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, `{
+		"data": {"getThing": null, "getNewThing": {"id": "template"}},
+		"errors": [{"message": "thing not found", "path": ["getThing"]}]
+	}`, resp.Body.String())
+}
+
+func TestTooLarge(t *testing.T) {
+	config := huma.DefaultConfig("Test API", "1.0.0")
+	config.MaxBodyBytes = 32
+	_, api := humatest.New(t, config)
+	Expose(api, Config{})
+
+	resp := api.Post("/graphql", map[string]any{
+		"query":     `{ __typename }`,
+		"variables": map[string]any{"padding": strings.Repeat("a", 64)},
+	})
+	assert.Equal(t, http.StatusRequestEntityTooLarge, resp.Code)
+	assert.JSONEq(t, `{"data": null, "errors": [{"message": "request body is too large limit=32 bytes"}]}`, resp.Body.String())
+}
+
+func TestSchema(t *testing.T) {
+	api := setup(t)
+
+	resp := api.Get("/graphql/schema")
+	require.Equal(t, http.StatusOK, resp.Code)
+	sdl := resp.Body.String()
+
+	assert.Contains(t, sdl, "scalar JSON")
+	assert.Contains(t, sdl, `"""Get a thing"""`)
+	assert.Contains(t, sdl, "getThing(thingId: String!): Thing")
+	assert.Contains(t, sdl, "listThings(limit: Int): [Thing]")
+	assert.Contains(t, sdl, "type Mutation {\n  putThing(thingId: String!, body: JSON!): Thing\n}")
+	assert.Contains(t, sdl, "type Thing {\n  id: String!\n  name: String!\n  owner: Owner\n  tags: [String]\n}")
+	assert.Contains(t, sdl, "type Owner {\n  email: String!\n}")
+
+	// Operations added later are included.
+	huma.Register(api, huma.Operation{
+		OperationID: "get-late",
+		Method:      http.MethodGet,
+		Path:        "/late",
+	}, func(ctx context.Context, input *struct{}) (*struct{ Body string }, error) {
+		return &struct{ Body string }{"late"}, nil
+	})
+	resp = api.Get("/graphql/schema")
+	assert.Contains(t, resp.Body.String(), "getLate: String")
+}
+
+func TestParse(t *testing.T) {
+	doc, err := parse(`
+		# A comment
+		query Q($a: [Int!]! = [1, 2], $b: Boolean) {
+			alias: field(arg: "x\/y", num: -1.5e2, list: [1, $a], obj: {k: ENUM}) @skip(if: $b) {
+				... on Thing { id }
+				nested { deep }
+			}
+			block: other(text: """
+				multi
+				line
+			""")
+		}`)
+	require.NoError(t, err)
+	require.Len(t, doc.operations, 1)
+
+	op := doc.operations[0]
+	assert.Equal(t, "query", op.kind)
+	assert.Equal(t, "Q", op.name)
+	assert.Equal(t, []any{int64(1), int64(2)}, resolveValue(op.variables["a"], nil))
+
+	sel := op.selections[0]
+	assert.Equal(t, "alias", sel.key())
+	assert.Equal(t, "field", sel.name)
+	assert.Equal(t, "x/y", sel.args["arg"])
+	assert.Equal(t, -150.0, sel.args["num"])
+	assert.Equal(t, []any{int64(1), []any{int64(1), int64(2)}}, resolveValue(sel.args["list"], map[string]any{"a": []any{int64(1), int64(2)}}))
+	assert.Equal(t, map[string]any{"k": "ENUM"}, resolveValue(sel.args["obj"], nil))
+	assert.Equal(t, variable("b"), sel.directives["skip"]["if"])
+	assert.True(t, sel.selections[0].inline)
+	assert.Equal(t, "nested", sel.selections[1].name)
+
+	assert.Equal(t, "multi\nline", op.selections[1].args["text"])
+
+	_, err = parse(`{ field(arg: "unterminated) }`)
+	assert.Error(t, err)
+}
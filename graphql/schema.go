@@ -0,0 +1,191 @@
+package graphql
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+var validName = regexp.MustCompile(`^[_A-Za-z][_0-9A-Za-z]*$`)
+
+// schemaBuilder generates GraphQL SDL from the OpenAPI schemas of the exposed
+// operations. Named schemas become object types, and anything which cannot be
+// represented as a GraphQL type, like maps or unions, uses the `JSON` scalar.
+type schemaBuilder struct {
+	registry huma.Registry
+	types    map[string]bool
+	order    []string
+}
+
+func newSchemaBuilder(registry huma.Registry) *schemaBuilder {
+	return &schemaBuilder{registry: registry, types: map[string]bool{}}
+}
+
+func (b *schemaBuilder) build(queries, mutations map[string]*field) string {
+	var sb strings.Builder
+	sb.WriteString("scalar JSON\n")
+	b.writeRoot(&sb, "Query", queries)
+	b.writeRoot(&sb, "Mutation", mutations)
+
+	// Types referenced by other types are added to the queue while writing.
+	for i := 0; i < len(b.order); i++ {
+		name := b.order[i]
+		s := b.registry.Map()[name]
+		sb.WriteString("\n")
+		writeDescription(&sb, "", s.Description)
+		sb.WriteString("type " + name + " {\n")
+		written := false
+		for _, prop := range sortedProps(s.Properties) {
+			if !validName.MatchString(prop) {
+				continue
+			}
+			ps := s.Properties[prop]
+			writeDescription(&sb, "  ", ps.Description)
+			sb.WriteString("  " + prop + ": " + b.typeOf(ps, contains(s.Required, prop)) + deprecated(ps.Deprecated) + "\n")
+			written = true
+		}
+		if !written {
+			// GraphQL object types must have at least one field.
+			sb.WriteString("  _: JSON\n")
+		}
+		sb.WriteString("}\n")
+	}
+	return sb.String()
+}
+
+func (b *schemaBuilder) writeRoot(sb *strings.Builder, typeName string, fields map[string]*field) {
+	if len(fields) == 0 {
+		if typeName == "Query" {
+			// A schema must always have a query type.
+			sb.WriteString("\ntype Query {\n  _: JSON\n}\n")
+		}
+		return
+	}
+	sb.WriteString("\ntype " + typeName + " {\n")
+	for _, name := range sortedKeys(fields) {
+		f := fields[name]
+		if !validName.MatchString(name) {
+			continue
+		}
+		writeDescription(sb, "  ", f.op.Summary)
+
+		args := []string{}
+		argNames := make([]string, 0, len(f.args))
+		for arg := range f.args {
+			argNames = append(argNames, arg)
+		}
+		sort.Strings(argNames)
+		for _, arg := range argNames {
+			p := f.args[arg]
+			args = append(args, arg+": "+b.typeOf(p.Schema, p.Required))
+		}
+		if f.op.RequestBody != nil {
+			args = append(args, "body: JSON"+nonNull(f.op.RequestBody.Required))
+		}
+
+		sb.WriteString("  " + name)
+		if len(args) > 0 {
+			sb.WriteString("(" + strings.Join(args, ", ") + ")")
+		}
+		sb.WriteString(": " + b.typeOf(responseSchema(f.op), false) + deprecated(f.op.Deprecated) + "\n")
+	}
+	sb.WriteString("}\n")
+}
+
+// responseSchema returns the JSON schema of the operation's first successful
+// response, if any.
+func responseSchema(op *huma.Operation) *huma.Schema {
+	codes := make([]string, 0, len(op.Responses))
+	for code := range op.Responses {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	for _, code := range codes {
+		if !strings.HasPrefix(code, "2") {
+			continue
+		}
+		for ct, mt := range op.Responses[code].Content {
+			if strings.Contains(ct, "json") && mt.Schema != nil {
+				return mt.Schema
+			}
+		}
+	}
+	return nil
+}
+
+// typeOf returns the GraphQL type reference for a schema.
+func (b *schemaBuilder) typeOf(s *huma.Schema, required bool) string {
+	t := "JSON"
+	if s != nil {
+		if s.Ref != "" {
+			if ref := b.registry.SchemaFromRef(s.Ref); ref != nil && ref.Type == huma.TypeObject && len(ref.Properties) > 0 {
+				name := s.Ref[strings.LastIndex(s.Ref, "/")+1:]
+				if validName.MatchString(name) {
+					if !b.types[name] {
+						b.types[name] = true
+						b.order = append(b.order, name)
+					}
+					t = name
+				}
+			} else if ref != nil {
+				return b.typeOf(ref, required)
+			}
+		} else {
+			switch s.Type {
+			case huma.TypeString:
+				t = "String"
+			case huma.TypeInteger:
+				t = "Int"
+			case huma.TypeNumber:
+				t = "Float"
+			case huma.TypeBoolean:
+				t = "Boolean"
+			case huma.TypeArray:
+				t = fmt.Sprintf("[%s]", b.typeOf(s.Items, false))
+			}
+		}
+	}
+	return t + nonNull(required)
+}
+
+func writeDescription(sb *strings.Builder, indent, desc string) {
+	if desc == "" {
+		return
+	}
+	sb.WriteString(indent + `"""` + strings.ReplaceAll(desc, `"""`, `\"""`) + `"""` + "\n")
+}
+
+func deprecated(d bool) string {
+	if d {
+		return " @deprecated"
+	}
+	return ""
+}
+
+func nonNull(required bool) string {
+	if required {
+		return "!"
+	}
+	return ""
+}
+
+func sortedProps(props map[string]*huma.Schema) []string {
+	keys := make([]string, 0, len(props))
+	for k := range props {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func contains(values []string, v string) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,169 @@
+package httpsig
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// Algorithms from the HTTP Signature Algorithms registry.
+const (
+	AlgorithmEd25519         = "ed25519"
+	AlgorithmECDSAP256SHA256 = "ecdsa-p256-sha256"
+	AlgorithmECDSAP384SHA384 = "ecdsa-p384-sha384"
+	AlgorithmRSAPSSSHA512    = "rsa-pss-sha512"
+	AlgorithmRSAv15SHA256    = "rsa-v1_5-sha256"
+	AlgorithmHMACSHA256      = "hmac-sha256"
+)
+
+var errInvalidSignature = errors.New("invalid signature")
+
+// pssOptions are the RSASSA-PSS parameters required by RFC 9421.
+var pssOptions = &rsa.PSSOptions{SaltLength: 64, Hash: crypto.SHA512}
+
+// algorithm returns the key's algorithm, inferring it from the key type if
+// not set.
+func (k *Key) algorithm() (string, error) {
+	if k.Algorithm != "" {
+		return k.Algorithm, nil
+	}
+	switch key := k.Key.(type) {
+	case ed25519.PublicKey, ed25519.PrivateKey:
+		return AlgorithmEd25519, nil
+	case *ecdsa.PublicKey:
+		return ecdsaAlgorithm(key.Curve)
+	case *ecdsa.PrivateKey:
+		return ecdsaAlgorithm(key.Curve)
+	case *rsa.PublicKey, *rsa.PrivateKey:
+		return AlgorithmRSAPSSSHA512, nil
+	case []byte:
+		return AlgorithmHMACSHA256, nil
+	}
+	return "", fmt.Errorf("unsupported key type %T", k.Key)
+}
+
+func ecdsaAlgorithm(curve elliptic.Curve) (string, error) {
+	switch curve {
+	case elliptic.P256():
+		return AlgorithmECDSAP256SHA256, nil
+	case elliptic.P384():
+		return AlgorithmECDSAP384SHA384, nil
+	}
+	return "", errors.New("unsupported ECDSA curve")
+}
+
+// public returns the public key for verifying, which may be the private key
+// itself for HMAC.
+func (k *Key) public() crypto.PublicKey {
+	if s, ok := k.Key.(crypto.Signer); ok {
+		return s.Public()
+	}
+	return k.Key
+}
+
+// sign creates a signature over the signature base.
+func sign(alg string, key any, base []byte) ([]byte, error) {
+	switch alg {
+	case AlgorithmEd25519:
+		if k, ok := key.(ed25519.PrivateKey); ok {
+			return ed25519.Sign(k, base), nil
+		}
+	case AlgorithmECDSAP256SHA256, AlgorithmECDSAP384SHA384:
+		if k, ok := key.(*ecdsa.PrivateKey); ok {
+			if a, _ := ecdsaAlgorithm(k.Curve); a != alg {
+				break
+			}
+			digest := hashFor(alg, base)
+			r, s, err := ecdsa.Sign(rand.Reader, k, digest)
+			if err != nil {
+				return nil, err
+			}
+			// Signatures are the fixed-size concatenation of r and s.
+			size := (k.Curve.Params().BitSize + 7) / 8
+			sig := make([]byte, 2*size)
+			r.FillBytes(sig[:size])
+			s.FillBytes(sig[size:])
+			return sig, nil
+		}
+	case AlgorithmRSAPSSSHA512:
+		if k, ok := key.(*rsa.PrivateKey); ok {
+			return rsa.SignPSS(rand.Reader, k, crypto.SHA512, hashFor(alg, base), pssOptions)
+		}
+	case AlgorithmRSAv15SHA256:
+		if k, ok := key.(*rsa.PrivateKey); ok {
+			return rsa.SignPKCS1v15(rand.Reader, k, crypto.SHA256, hashFor(alg, base))
+		}
+	case AlgorithmHMACSHA256:
+		if k, ok := key.([]byte); ok {
+			m := hmac.New(sha256.New, k)
+			m.Write(base)
+			return m.Sum(nil), nil
+		}
+	default:
+		return nil, fmt.Errorf("unsupported algorithm %q", alg)
+	}
+	return nil, fmt.Errorf("key type %T cannot sign with %q", key, alg)
+}
+
+// verify checks a signature over the signature base, ensuring that the key
+// type matches the algorithm.
+func verify(alg string, key any, base, sig []byte) error {
+	switch alg {
+	case AlgorithmEd25519:
+		if k, ok := key.(ed25519.PublicKey); ok && ed25519.Verify(k, base, sig) {
+			return nil
+		}
+	case AlgorithmECDSAP256SHA256, AlgorithmECDSAP384SHA384:
+		if k, ok := key.(*ecdsa.PublicKey); ok {
+			size := (k.Curve.Params().BitSize + 7) / 8
+			if a, _ := ecdsaAlgorithm(k.Curve); a == alg && len(sig) == 2*size {
+				r := new(big.Int).SetBytes(sig[:size])
+				s := new(big.Int).SetBytes(sig[size:])
+				if ecdsa.Verify(k, hashFor(alg, base), r, s) {
+					return nil
+				}
+			}
+		}
+	case AlgorithmRSAPSSSHA512:
+		if k, ok := key.(*rsa.PublicKey); ok && rsa.VerifyPSS(k, crypto.SHA512, hashFor(alg, base), sig, pssOptions) == nil {
+			return nil
+		}
+	case AlgorithmRSAv15SHA256:
+		if k, ok := key.(*rsa.PublicKey); ok && rsa.VerifyPKCS1v15(k, crypto.SHA256, hashFor(alg, base), sig) == nil {
+			return nil
+		}
+	case AlgorithmHMACSHA256:
+		if k, ok := key.([]byte); ok {
+			m := hmac.New(sha256.New, k)
+			m.Write(base)
+			if hmac.Equal(m.Sum(nil), sig) {
+				return nil
+			}
+		}
+	default:
+		return fmt.Errorf("unsupported algorithm %q", alg)
+	}
+	return errInvalidSignature
+}
+
+func hashFor(alg string, base []byte) []byte {
+	switch alg {
+	case AlgorithmECDSAP384SHA384:
+		sum := sha512.Sum384(base)
+		return sum[:]
+	case AlgorithmRSAPSSSHA512:
+		sum := sha512.Sum512(base)
+		return sum[:]
+	}
+	sum := sha256.Sum256(base)
+	return sum[:]
+}
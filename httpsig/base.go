@@ -0,0 +1,208 @@
+package httpsig
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// message holds the parts of a request or response which may be covered by
+// a signature. Responses have a status and no method.
+type message struct {
+	method    string
+	scheme    string
+	authority string
+	path      string
+	rawQuery  string
+	status    int
+	header    http.Header
+}
+
+// normalizeAuthority lowercases the host and removes the default port for
+// the scheme.
+func normalizeAuthority(scheme, host string) string {
+	host = strings.ToLower(host)
+	if (scheme == "https" && strings.HasSuffix(host, ":443")) || (scheme == "http" && strings.HasSuffix(host, ":80")) {
+		host = host[:strings.LastIndexByte(host, ':')]
+	}
+	return host
+}
+
+func newMessage(method, scheme, host string, u *url.URL, header http.Header) *message {
+	path := u.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	return &message{
+		method:    strings.ToUpper(method),
+		scheme:    scheme,
+		authority: normalizeAuthority(scheme, host),
+		path:      path,
+		rawQuery:  u.RawQuery,
+		header:    header,
+	}
+}
+
+// requestMessage returns the message for an incoming request.
+func requestMessage(ctx huma.Context, scheme string) *message {
+	u := ctx.URL()
+	header := http.Header{}
+	ctx.EachHeader(func(name, value string) {
+		header.Add(name, value)
+	})
+	return newMessage(ctx.Method(), scheme, ctx.Host(), &u, header)
+}
+
+// componentItem parses a component identifier like `@method`, `date`, or
+// `"@query-param";name="id"`.
+func componentItem(component string) (item, error) {
+	if !strings.HasPrefix(component, `"`) {
+		return item{value: component}, nil
+	}
+	p := &sfParser{s: component}
+	it, err := p.item()
+	if err == nil && !p.eof() {
+		err = errMalformed
+	}
+	if _, ok := it.value.(string); !ok && err == nil {
+		err = errMalformed
+	}
+	if err != nil {
+		return item{}, fmt.Errorf("invalid component %q", component)
+	}
+	return it, nil
+}
+
+// serializeComponent returns the canonical form of a component identifier,
+// used to compare components.
+func serializeComponent(it item) string {
+	var sb strings.Builder
+	serializeBareItem(&sb, it.value)
+	serializeParams(&sb, it.params)
+	return sb.String()
+}
+
+var errMissingComponent = errors.New("missing component")
+
+// componentValue returns the value of a component in the message. Header
+// components which are not present return `errMissingComponent`.
+func (m *message) componentValue(it item) (string, error) {
+	name, _ := it.value.(string)
+	if name == "" || name != strings.ToLower(name) {
+		return "", fmt.Errorf("invalid component %s", serializeComponent(it))
+	}
+
+	allowed := ""
+	if name == "@query-param" {
+		allowed = "name"
+	}
+	for _, p := range it.params {
+		if p.key != allowed {
+			return "", fmt.Errorf("unsupported component parameter %q", p.key)
+		}
+	}
+
+	isResponse := m.status != 0
+	if strings.HasPrefix(name, "@") && (name == "@status") != isResponse {
+		return "", fmt.Errorf("component %s is not valid for this message", name)
+	}
+
+	switch name {
+	case "@method":
+		return m.method, nil
+	case "@target-uri":
+		return m.scheme + "://" + m.authority + m.requestTarget(), nil
+	case "@authority":
+		return m.authority, nil
+	case "@scheme":
+		return m.scheme, nil
+	case "@request-target":
+		return m.requestTarget(), nil
+	case "@path":
+		return m.path, nil
+	case "@query":
+		return "?" + m.rawQuery, nil
+	case "@query-param":
+		return m.queryParam(it.params)
+	case "@status":
+		return fmt.Sprintf("%03d", m.status), nil
+	}
+	if strings.HasPrefix(name, "@") {
+		return "", fmt.Errorf("unsupported component %s", name)
+	}
+
+	values := m.header.Values(name)
+	if len(values) == 0 {
+		return "", errMissingComponent
+	}
+	trimmed := make([]string, len(values))
+	for i, v := range values {
+		trimmed[i] = strings.TrimSpace(v)
+	}
+	return strings.Join(trimmed, ", "), nil
+}
+
+func (m *message) requestTarget() string {
+	if m.rawQuery != "" {
+		return m.path + "?" + m.rawQuery
+	}
+	return m.path
+}
+
+// queryParam returns the value of a single query parameter, re-encoded so
+// that equivalent encodings produce the same value.
+func (m *message) queryParam(ps params) (string, error) {
+	v, _ := ps.get("name")
+	name, ok := v.(string)
+	if !ok {
+		return "", errors.New("@query-param requires a name parameter")
+	}
+	var found []string
+	for _, pair := range strings.Split(m.rawQuery, "&") {
+		k, value, _ := strings.Cut(pair, "=")
+		if k, err := url.QueryUnescape(k); err != nil || k != name {
+			continue
+		}
+		value, err := url.QueryUnescape(value)
+		if err != nil {
+			return "", fmt.Errorf("invalid query parameter %q", name)
+		}
+		found = append(found, value)
+	}
+	switch len(found) {
+	case 0:
+		return "", errMissingComponent
+	case 1:
+		return strings.ReplaceAll(url.QueryEscape(found[0]), "+", "%20"), nil
+	}
+	return "", fmt.Errorf("query parameter %q is repeated", name)
+}
+
+// signatureBase creates the signature base for the covered components, ending
+// with the `@signature-params` line.
+func (m *message) signatureBase(components []item, sigParams params) ([]byte, error) {
+	var sb strings.Builder
+	seen := map[string]bool{}
+	for _, it := range components {
+		id := serializeComponent(it)
+		if seen[id] {
+			return nil, fmt.Errorf("component %s is repeated", id)
+		}
+		seen[id] = true
+
+		value, err := m.componentValue(it)
+		if err != nil {
+			if err == errMissingComponent {
+				return nil, fmt.Errorf("missing component %s", id)
+			}
+			return nil, err
+		}
+		sb.WriteString(id + ": " + value + "\n")
+	}
+	sb.WriteString(`"@signature-params": ` + serializeInnerList(components, sigParams))
+	return []byte(sb.String()), nil
+}
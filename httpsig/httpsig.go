@@ -0,0 +1,525 @@
+// Package httpsig verifies HTTP Message Signatures (RFC 9421) on requests to
+// Huma APIs, and can optionally sign responses. It is useful for partner
+// integrations which require every request to be signed, e.g. with a key pair
+// exchanged ahead of time, independent of any bearer token.
+//
+//	httpsig.Use(api, httpsig.Config{
+//		KeyResolver: func(ctx context.Context, keyID string) (*httpsig.Key, error) {
+//			return partnerKeys[keyID], nil
+//		},
+//		Components: []string{"@method", "@authority", "@path", "@query", "content-digest"},
+//	})
+//
+//	huma.Register(api, huma.Operation{
+//		OperationID: "create-order",
+//		Method:      http.MethodPost,
+//		Path:        "/orders",
+//		Metadata: map[string]any{
+//			httpsig.MetadataKey: true,
+//		},
+//	}, handler)
+//
+// The ID of the key which verified the request is available to handlers via
+// `httpsig.KeyID`. Clients may use a `Signer` to sign requests.
+//
+// See https://www.rfc-editor.org/rfc/rfc9421
+package httpsig
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// MetadataKey is the operation metadata key used to opt an operation in to
+// (or out of) signature verification, e.g.
+// `Metadata: map[string]any{"httpsig": true}`.
+const MetadataKey = "httpsig"
+
+// Header names.
+const (
+	SignatureInputHeader  = "Signature-Input"
+	SignatureHeader       = "Signature"
+	AcceptSignatureHeader = "Accept-Signature"
+)
+
+var (
+	errMissingSignature = errors.New("missing signature")
+	errUnknownKey       = errors.New("unknown key")
+)
+
+// defaultComponents are covered by signatures when no components are
+// configured.
+var defaultComponents = []string{"@method", "@authority", "@path"}
+
+// now returns the current time and can be replaced for testing.
+var now = time.Now
+
+// Key is a key used to sign or verify signatures.
+type Key struct {
+	// ID is the key identifier, sent as the `keyid` signature parameter.
+	ID string
+
+	// Algorithm is the signature algorithm, e.g. `ed25519`. Defaults to the
+	// algorithm for the key type, which is `rsa-pss-sha512` for RSA keys.
+	// When verifying, signatures declaring a different `alg` are rejected.
+	Algorithm string
+
+	// Key is the key material: an `ed25519`, `*ecdsa` (P-256 or P-384), or
+	// `*rsa` public key to verify signatures, or the matching private key to
+	// sign them. HMAC uses a shared `[]byte` secret for both.
+	Key any
+}
+
+// Config configures signature verification.
+type Config struct {
+	// KeyResolver returns the key for the `keyid` signature parameter.
+	// Returning a nil key rejects the request. Required.
+	KeyResolver func(ctx context.Context, keyID string) (*Key, error)
+
+	// Components which signatures must cover, e.g. `@method`, `content-type`,
+	// or `"@query-param";name="id"`. Defaults to `@method`, `@authority`, and
+	// `@path`. Covering `content-digest` only protects the body if the digest
+	// is also checked against it, e.g. using the `digest` package.
+	Components []string
+
+	// MaxAge rejects signatures whose `created` parameter is older than this.
+	// Defaults to five minutes. Set to a negative value to accept signatures
+	// without a `created` parameter.
+	MaxAge time.Duration
+
+	// Leeway allows for clock skew when checking the `created` and `expires`
+	// parameters.
+	Leeway time.Duration
+
+	// Tag, if set, ignores signatures without a matching `tag` parameter, so
+	// that signatures made for other purposes are not used.
+	Tag string
+
+	// Scheme is the URL scheme used for the `@scheme` and `@target-uri`
+	// components, as it cannot be reliably detected behind a proxy which
+	// terminates TLS. Defaults to `https`.
+	Scheme string
+
+	// AllOperations verifies signatures for every operation, unless opted out
+	// via `MetadataKey`, rather than only opted-in operations.
+	AllOperations bool
+
+	// ResponseSigner, if set, signs responses from operations which verify
+	// signatures, including error responses.
+	ResponseSigner *Signer
+}
+
+// enabled returns whether an operation verifies signatures.
+func (c *Config) enabled(op *huma.Operation) bool {
+	if on, ok := op.Metadata[MetadataKey].(bool); ok {
+		return on
+	}
+	return c.AllOperations
+}
+
+type keyIDKey struct{}
+
+// KeyID returns the ID of the key which verified the current request's
+// signature, or an empty string if it was not verified.
+//
+//	func handler(ctx context.Context, input *MyInput) (*MyOutput, error) {
+//		partner := partners[httpsig.KeyID(ctx)]
+//		// ...
+//	}
+func KeyID(ctx context.Context) string {
+	id, _ := ctx.Value(keyIDKey{}).(string)
+	return id
+}
+
+// sigContext overrides the request context to include the key ID, and signs
+// the response if configured.
+type sigContext struct {
+	huma.ContextWrapper
+	ctx    context.Context
+	signer *Signer
+	header http.Header
+}
+
+func (c *sigContext) Context() context.Context {
+	return c.ctx
+}
+
+func (c *sigContext) SetHeader(name, value string) {
+	c.header.Set(name, value)
+	c.ContextWrapper.SetHeader(name, value)
+}
+
+func (c *sigContext) AppendHeader(name, value string) {
+	c.header.Add(name, value)
+	c.ContextWrapper.AppendHeader(name, value)
+}
+
+// SetStatus signs the response before its headers are written. Huma sets all
+// response headers before the status.
+func (c *sigContext) SetStatus(code int) {
+	if c.signer != nil {
+		m := &message{status: code, header: c.header}
+		if input, sig, err := c.signer.sign(m, true); err == nil {
+			c.ContextWrapper.SetHeader(SignatureInputHeader, input)
+			c.ContextWrapper.SetHeader(SignatureHeader, sig)
+		}
+	}
+	c.ContextWrapper.SetStatus(code)
+}
+
+// verifier verifies request signatures.
+type verifier struct {
+	config   Config
+	required []string
+}
+
+// verify checks the request's signature and returns the ID of the key which
+// verified it.
+func (v *verifier) verify(ctx huma.Context) (string, error) {
+	inputHeader := ctx.Header(SignatureInputHeader)
+	sigHeader := ctx.Header(SignatureHeader)
+	if inputHeader == "" || sigHeader == "" {
+		return "", errMissingSignature
+	}
+	inputs, err := parseDictionary(inputHeader)
+	if err != nil {
+		return "", fmt.Errorf("invalid %s header", SignatureInputHeader)
+	}
+	sigs, err := parseDictionary(sigHeader)
+	if err != nil {
+		return "", fmt.Errorf("invalid %s header", SignatureHeader)
+	}
+
+	// Use the first signature with the configured tag.
+	var input *member
+	var sig []byte
+	for i := range inputs {
+		if !inputs[i].isList {
+			continue
+		}
+		if v.config.Tag != "" {
+			if tag, _ := inputs[i].item.params.get("tag"); tag != v.config.Tag {
+				continue
+			}
+		}
+		for _, s := range sigs {
+			if b, ok := s.item.value.([]byte); ok && s.key == inputs[i].key {
+				input, sig = &inputs[i], b
+			}
+		}
+		if input != nil {
+			break
+		}
+	}
+	if input == nil {
+		return "", errMissingSignature
+	}
+	sigParams := input.item.params
+
+	covered := map[string]bool{}
+	for _, it := range input.list {
+		covered[serializeComponent(it)] = true
+	}
+	for _, c := range v.required {
+		if !covered[c] {
+			return "", fmt.Errorf("signature must cover %s", c)
+		}
+	}
+
+	t := now()
+	if created, ok := sigParams.get("created"); ok {
+		c, ok := created.(int64)
+		if !ok {
+			return "", errors.New("invalid created parameter")
+		}
+		createdAt := time.Unix(c, 0)
+		if createdAt.After(t.Add(v.config.Leeway)) {
+			return "", errors.New("signature created in the future")
+		}
+		if v.config.MaxAge > 0 && t.Sub(createdAt) > v.config.MaxAge+v.config.Leeway {
+			return "", errors.New("signature is too old")
+		}
+	} else if v.config.MaxAge > 0 {
+		return "", errors.New("signature must have a created parameter")
+	}
+	if expires, ok := sigParams.get("expires"); ok {
+		e, ok := expires.(int64)
+		if !ok {
+			return "", errors.New("invalid expires parameter")
+		}
+		if t.After(time.Unix(e, 0).Add(v.config.Leeway)) {
+			return "", errors.New("signature is expired")
+		}
+	}
+
+	keyID, ok := sigParams.get("keyid")
+	id, _ := keyID.(string)
+	if !ok || id == "" {
+		return "", errors.New("signature must have a keyid parameter")
+	}
+	key, err := v.config.KeyResolver(ctx.Context(), id)
+	if err != nil {
+		return "", err
+	}
+	if key == nil {
+		return "", errUnknownKey
+	}
+	alg, err := key.algorithm()
+	if err != nil {
+		return "", err
+	}
+	if a, ok := sigParams.get("alg"); ok && a != alg {
+		return "", fmt.Errorf("key does not support algorithm %v", a)
+	}
+
+	base, err := requestMessage(ctx, v.config.Scheme).signatureBase(input.list, sigParams)
+	if err != nil {
+		return "", err
+	}
+	if err := verify(alg, key.public(), base, sig); err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+// acceptSignature returns the `Accept-Signature` header value describing the
+// signatures the API accepts.
+func (v *verifier) acceptSignature() string {
+	items := make([]item, 0, len(v.config.Components))
+	for _, c := range v.config.Components {
+		it, _ := componentItem(c)
+		items = append(items, it)
+	}
+	var ps params
+	if v.config.MaxAge > 0 {
+		ps = append(ps, param{"created", true})
+	}
+	ps = append(ps, param{"keyid", true})
+	if v.config.Tag != "" {
+		ps = append(ps, param{"tag", v.config.Tag})
+	}
+	return "sig1=" + serializeInnerList(items, ps)
+}
+
+// Use adds signature verification middleware to the API and documents the
+// signature headers and `401` response on operations which verify
+// signatures. Like other middleware, it must be called before registering
+// operations with `huma.Register` in order to apply to them.
+//
+// Requests to opted-in operations without a valid signature covering the
+// required components receive a `401 Unauthorized` error with an
+// `Accept-Signature` header describing the expected signature.
+func Use(api huma.API, config Config) {
+	if config.KeyResolver == nil {
+		panic("httpsig: KeyResolver must be set")
+	}
+	if config.Components == nil {
+		config.Components = defaultComponents
+	}
+	if config.MaxAge == 0 {
+		config.MaxAge = 5 * time.Minute
+	}
+	if config.Scheme == "" {
+		config.Scheme = "https"
+	}
+
+	v := &verifier{config: config}
+	for _, c := range config.Components {
+		it, err := componentItem(c)
+		if err != nil {
+			panic("httpsig: " + err.Error())
+		}
+		v.required = append(v.required, serializeComponent(it))
+	}
+	accept := v.acceptSignature()
+
+	oapi := api.OpenAPI()
+	oapi.OnAddOperation = append(oapi.OnAddOperation, config.document)
+
+	api.UseMiddleware(func(ctx huma.Context, next func(huma.Context)) {
+		if !config.enabled(ctx.Operation()) {
+			next(ctx)
+			return
+		}
+
+		sc := &sigContext{
+			ContextWrapper: huma.WrapContext(ctx),
+			ctx:            ctx.Context(),
+			signer:         config.ResponseSigner,
+			header:         http.Header{},
+		}
+
+		keyID, err := v.verify(ctx)
+		if err != nil {
+			sc.SetHeader(AcceptSignatureHeader, accept)
+			huma.WriteErr(api, sc, http.StatusUnauthorized, "invalid signature", err)
+			return
+		}
+
+		sc.ctx = context.WithValue(sc.ctx, keyIDKey{}, keyID)
+		next(sc)
+	})
+}
+
+// Signer signs HTTP messages. Clients may use it to sign requests, and APIs
+// to sign responses via `Config.ResponseSigner`.
+type Signer struct {
+	// Key is the private key or HMAC secret to sign with. Its ID is sent as
+	// the `keyid` signature parameter. Required.
+	Key *Key
+
+	// Components to cover. For requests, defaults to `@method`, `@authority`,
+	// and `@path`. For responses, defaults to `@status`, `content-type`, and
+	// `content-digest`, omitting headers which are not present.
+	Components []string
+
+	// Label is the signature's label in the headers. Defaults to `sig1`.
+	Label string
+
+	// Tag is sent as the `tag` signature parameter, if set.
+	Tag string
+}
+
+// SignRequest signs an outgoing request, setting its `Signature-Input` and
+// `Signature` headers. The request's URL must be absolute, and any headers to
+// be covered must already be set.
+func (s *Signer) SignRequest(req *http.Request) error {
+	scheme := req.URL.Scheme
+	if scheme == "" {
+		scheme = "https"
+	}
+	host := req.Host
+	if host == "" {
+		host = req.URL.Host
+	}
+	input, sig, err := s.sign(newMessage(req.Method, scheme, host, req.URL, req.Header), false)
+	if err != nil {
+		return err
+	}
+	req.Header.Set(SignatureInputHeader, input)
+	req.Header.Set(SignatureHeader, sig)
+	return nil
+}
+
+// sign returns the `Signature-Input` and `Signature` header values for a
+// message. If optional is true, header components missing from the message
+// are omitted rather than returning an error.
+func (s *Signer) sign(m *message, optional bool) (string, string, error) {
+	alg, err := s.Key.algorithm()
+	if err != nil {
+		return "", "", err
+	}
+	label := s.Label
+	if label == "" {
+		label = "sig1"
+	}
+	components := s.Components
+	if components == nil {
+		components = defaultComponents
+		if m.status != 0 {
+			components = []string{"@status", "content-type", "content-digest"}
+		}
+	}
+
+	items := make([]item, 0, len(components))
+	for _, c := range components {
+		it, err := componentItem(c)
+		if err != nil {
+			return "", "", err
+		}
+		if _, err := m.componentValue(it); err == errMissingComponent && optional {
+			continue
+		}
+		items = append(items, it)
+	}
+
+	ps := params{
+		{"created", now().Unix()},
+		{"keyid", s.Key.ID},
+		{"alg", alg},
+	}
+	if s.Tag != "" {
+		ps = append(ps, param{"tag", s.Tag})
+	}
+
+	base, err := m.signatureBase(items, ps)
+	if err != nil {
+		return "", "", err
+	}
+	sig, err := sign(alg, s.Key.Key, base)
+	if err != nil {
+		return "", "", err
+	}
+	var sb strings.Builder
+	serializeBareItem(&sb, sig)
+	return label + "=" + serializeInnerList(items, ps), label + "=" + sb.String(), nil
+}
+
+// document adds the signature headers and `401` response to the OpenAPI for
+// operations which verify signatures.
+func (c *Config) document(oapi *huma.OpenAPI, op *huma.Operation) {
+	if !c.enabled(op) {
+		return
+	}
+
+	for _, name := range []string{SignatureInputHeader, SignatureHeader} {
+		documented := false
+		for _, p := range op.Parameters {
+			if p.In == "header" && strings.EqualFold(p.Name, name) {
+				documented = true
+			}
+		}
+		if documented {
+			continue
+		}
+		op.Parameters = append(op.Parameters, &huma.Param{
+			Name:        name,
+			In:          "header",
+			Description: "HTTP message signature (RFC 9421) covering " + strings.Join(c.Components, ", ") + ".",
+			Required:    true,
+			Schema:      &huma.Schema{Type: huma.TypeString},
+		})
+	}
+
+	if op.Responses == nil {
+		op.Responses = map[string]*huma.Response{}
+	}
+	code := strconv.Itoa(http.StatusUnauthorized)
+	if op.Responses[code] == nil {
+		op.Responses[code] = &huma.Response{
+			Description: http.StatusText(http.StatusUnauthorized),
+			Content:     huma.ErrorContent(op),
+			Headers: map[string]*huma.Param{
+				AcceptSignatureHeader: {
+					Description: "The signature the API expects.",
+					Schema:      &huma.Schema{Type: huma.TypeString},
+				},
+			},
+		}
+	}
+
+	if c.ResponseSigner == nil {
+		return
+	}
+	for _, resp := range op.Responses {
+		if resp.Ref != "" {
+			continue
+		}
+		if resp.Headers == nil {
+			resp.Headers = map[string]*huma.Param{}
+		}
+		for _, name := range []string{SignatureInputHeader, SignatureHeader} {
+			resp.Headers[name] = &huma.Param{
+				Description: "HTTP message signature (RFC 9421) of the response.",
+				Schema:      &huma.Schema{Type: huma.TypeString},
+			}
+		}
+	}
+}
@@ -0,0 +1,319 @@
+package httpsig
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/humatest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignatureBase(t *testing.T) {
+	// From RFC 9421 appendix B.2.6.
+	u, _ := url.Parse("/foo?param=Value&Pet=dog")
+	m := newMessage("POST", "https", "example.com", u, http.Header{
+		"Date":           {"Tue, 20 Apr 2021 02:07:55 GMT"},
+		"Content-Type":   {"application/json"},
+		"Content-Length": {"18"},
+	})
+	input, err := parseDictionary(`sig-b26=("date" "@method" "@path" "@authority" "content-type" "content-length");created=1618884473;keyid="test-key-ed25519"`)
+	require.NoError(t, err)
+	require.Len(t, input, 1)
+
+	base, err := m.signatureBase(input[0].list, input[0].item.params)
+	require.NoError(t, err)
+	assert.Equal(t, `"date": Tue, 20 Apr 2021 02:07:55 GMT
+"@method": POST
+"@path": /foo
+"@authority": example.com
+"content-type": application/json
+"content-length": 18
+"@signature-params": ("date" "@method" "@path" "@authority" "content-type" "content-length");created=1618884473;keyid="test-key-ed25519"`, string(base))
+
+	for component, expected := range map[string]string{
+		"@target-uri":                 "https://example.com/foo?param=Value&Pet=dog",
+		"@request-target":             "/foo?param=Value&Pet=dog",
+		"@query":                      "?param=Value&Pet=dog",
+		"@scheme":                     "https",
+		`"@query-param";name="param"`: "Value",
+	} {
+		it, err := componentItem(component)
+		require.NoError(t, err)
+		value, err := m.componentValue(it)
+		require.NoError(t, err, component)
+		assert.Equal(t, expected, value, component)
+	}
+
+	_, err = m.componentValue(item{value: "@status"})
+	assert.Error(t, err)
+	_, err = m.componentValue(item{value: "x-missing"})
+	assert.ErrorIs(t, err, errMissingComponent)
+	assert.Equal(t, "example.com", normalizeAuthority("https", "Example.com:443"))
+	assert.Equal(t, "example.com:8443", normalizeAuthority("https", "example.com:8443"))
+}
+
+func TestParseDictionary(t *testing.T) {
+	members, err := parseDictionary(`sig1=("@method" "@query-param";name="a\"b");created=1;tag=tok;flag, sig2=:AQID:`)
+	require.NoError(t, err)
+	require.Len(t, members, 2)
+	assert.True(t, members[0].isList)
+	assert.Equal(t, `("@method" "@query-param";name="a\"b");created=1;tag=tok;flag`, serializeInnerList(members[0].list, members[0].item.params))
+	assert.Equal(t, []byte{1, 2, 3}, members[1].item.value)
+
+	for _, bad := range []string{`Sig=1`, `sig1=("a"`, `sig1=:!:`, `sig1=1,`, `sig1="unterminated`, `sig1=1.5`} {
+		_, err := parseDictionary(bad)
+		assert.Error(t, err, bad)
+	}
+}
+
+type testKey struct {
+	name    string
+	private *Key
+	public  *Key
+}
+
+func newTestKeys(t *testing.T) []testKey {
+	_, ed, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	ec, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	ec384, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.NoError(t, err)
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	secret := []byte("shared-secret")
+
+	return []testKey{
+		{"ed25519", &Key{ID: "ed", Key: ed}, &Key{Key: ed.Public()}},
+		{"ecdsa-p256", &Key{ID: "ec", Key: ec}, &Key{Key: &ec.PublicKey}},
+		{"ecdsa-p384", &Key{ID: "ec384", Key: ec384}, &Key{Key: &ec384.PublicKey}},
+		{"rsa-pss", &Key{ID: "rsa", Key: rsaKey}, &Key{Key: &rsaKey.PublicKey}},
+		{"rsa-v1_5", &Key{ID: "rsa15", Algorithm: AlgorithmRSAv15SHA256, Key: rsaKey}, &Key{Algorithm: AlgorithmRSAv15SHA256, Key: &rsaKey.PublicKey}},
+		{"hmac", &Key{ID: "hmac", Key: secret}, &Key{Key: secret}},
+	}
+}
+
+func setup(t *testing.T, keys []testKey, config Config) humatest.TestAPI {
+	_, api := humatest.New(t)
+	config.KeyResolver = func(ctx context.Context, keyID string) (*Key, error) {
+		for _, k := range keys {
+			if k.private.ID == keyID {
+				return k.public, nil
+			}
+		}
+		return nil, nil
+	}
+	Use(api, config)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "create-order",
+		Method:      http.MethodPost,
+		Path:        "/orders",
+		Metadata:    map[string]any{MetadataKey: true},
+	}, func(ctx context.Context, input *struct {
+		Body struct {
+			Item string `json:"item"`
+		}
+	}) (*struct {
+		Body struct {
+			KeyID string `json:"keyId"`
+		}
+	}, error) {
+		resp := &struct {
+			Body struct {
+				KeyID string `json:"keyId"`
+			}
+		}{}
+		resp.Body.KeyID = KeyID(ctx)
+		return resp, nil
+	})
+
+	huma.Register(api, huma.Operation{
+		OperationID: "list-orders",
+		Method:      http.MethodGet,
+		Path:        "/orders",
+	}, func(ctx context.Context, input *struct{}) (*struct{}, error) {
+		return nil, nil
+	})
+
+	return api
+}
+
+func do(api humatest.TestAPI, req *http.Request) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	api.Adapter().ServeHTTP(w, req)
+	return w
+}
+
+func newRequest(path string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "https://example.com"+path, strings.NewReader(`{"item": "apple"}`))
+	req.Header.Set("Content-Type", "application/json")
+	return req
+}
+
+func TestVerify(t *testing.T) {
+	keys := newTestKeys(t)
+	api := setup(t, keys, Config{Components: []string{"@method", "@authority", "@path", "content-type"}})
+
+	for _, k := range keys {
+		t.Run(k.name, func(t *testing.T) {
+			req := newRequest("/orders")
+			signer := &Signer{Key: k.private, Components: []string{"@method", "@authority", "@path", "@query", "content-type"}}
+			require.NoError(t, signer.SignRequest(req))
+
+			resp := do(api, req)
+			require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+			assert.JSONEq(t, `{"keyId": "`+k.private.ID+`"}`, resp.Body.String())
+		})
+	}
+
+	// Unsigned requests are rejected with the expected signature.
+	resp := do(api, newRequest("/orders"))
+	assert.Equal(t, http.StatusUnauthorized, resp.Code)
+	assert.Equal(t, `sig1=("@method" "@authority" "@path" "content-type");created;keyid`, resp.Header().Get(AcceptSignatureHeader))
+
+	// Operations which have not opted in are not verified.
+	resp = do(api, httptest.NewRequest(http.MethodGet, "https://example.com/orders", nil))
+	assert.Equal(t, http.StatusNoContent, resp.Code)
+
+	key := keys[0].private
+	for name, tc := range map[string]struct {
+		signer *Signer
+		modify func(req *http.Request)
+		err    string
+	}{
+		"tampered": {
+			signer: &Signer{Key: key, Components: []string{"@method", "@authority", "@path", "content-type"}},
+			modify: func(req *http.Request) { req.Header.Set("Content-Type", "text/plain") },
+			err:    "invalid signature",
+		},
+		"missing component": {
+			signer: &Signer{Key: key},
+			err:    "signature must cover \\\"content-type\\\"",
+		},
+		"unknown key": {
+			signer: &Signer{Key: &Key{ID: "other", Key: key.Key}, Components: []string{"@method", "@authority", "@path", "content-type"}},
+			err:    "unknown key",
+		},
+		"wrong algorithm": {
+			signer: &Signer{Key: &Key{ID: "hmac", Key: []byte("shared-secret"), Algorithm: AlgorithmHMACSHA256}, Components: []string{"@method", "@authority", "@path", "content-type"}},
+			modify: func(req *http.Request) {
+				req.Header.Set(SignatureInputHeader, strings.Replace(req.Header.Get(SignatureInputHeader), "hmac-sha256", "ed25519", 1))
+			},
+			err: "does not support algorithm",
+		},
+		"malformed": {
+			signer: &Signer{Key: key, Components: []string{"@method", "@authority", "@path", "content-type"}},
+			modify: func(req *http.Request) { req.Header.Set(SignatureHeader, "sig1=:nope") },
+			err:    "invalid Signature header",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			req := newRequest("/orders")
+			require.NoError(t, tc.signer.SignRequest(req))
+			if tc.modify != nil {
+				tc.modify(req)
+			}
+			resp := do(api, req)
+			assert.Equal(t, http.StatusUnauthorized, resp.Code)
+			assert.Contains(t, resp.Body.String(), tc.err)
+		})
+	}
+}
+
+func TestExpiry(t *testing.T) {
+	keys := newTestKeys(t)
+	api := setup(t, keys, Config{Tag: "partner"})
+	defer func() { now = time.Now }()
+
+	signer := &Signer{Key: keys[0].private, Tag: "partner"}
+	now = func() time.Time { return time.Now().Add(-10 * time.Minute) }
+	req := newRequest("/orders")
+	require.NoError(t, signer.SignRequest(req))
+	now = time.Now
+
+	resp := do(api, req)
+	assert.Equal(t, http.StatusUnauthorized, resp.Code)
+	assert.Contains(t, resp.Body.String(), "too old")
+
+	// Signatures with a different tag are ignored.
+	req = newRequest("/orders")
+	require.NoError(t, (&Signer{Key: keys[0].private, Tag: "other"}).SignRequest(req))
+	resp = do(api, req)
+	assert.Equal(t, http.StatusUnauthorized, resp.Code)
+	assert.Contains(t, resp.Body.String(), "missing signature")
+
+	req = newRequest("/orders")
+	require.NoError(t, signer.SignRequest(req))
+	resp = do(api, req)
+	assert.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+}
+
+func TestResponseSigning(t *testing.T) {
+	keys := newTestKeys(t)
+	serverKey := keys[0]
+	api := setup(t, keys[1:], Config{ResponseSigner: &Signer{Key: serverKey.private}})
+
+	req := newRequest("/orders")
+	require.NoError(t, (&Signer{Key: keys[1].private}).SignRequest(req))
+
+	for _, resp := range []*httptest.ResponseRecorder{do(api, req), do(api, newRequest("/orders"))} {
+		inputs, err := parseDictionary(resp.Header().Get(SignatureInputHeader))
+		require.NoError(t, err)
+		require.Len(t, inputs, 1)
+		assert.Equal(t, `("@status" "content-type")`, serializeInnerList(inputs[0].list, nil))
+
+		sigs, err := parseDictionary(resp.Header().Get(SignatureHeader))
+		require.NoError(t, err)
+		require.Len(t, sigs, 1)
+
+		m := &message{status: resp.Code, header: resp.Header()}
+		base, err := m.signatureBase(inputs[0].list, inputs[0].item.params)
+		require.NoError(t, err)
+		assert.NoError(t, verify(AlgorithmEd25519, serverKey.public.Key, base, sigs[0].item.value.([]byte)))
+	}
+}
+
+func TestOpenAPI(t *testing.T) {
+	api := setup(t, nil, Config{ResponseSigner: &Signer{Key: &Key{Key: []byte("secret")}}})
+
+	op := api.OpenAPI().Paths["/orders"].Post
+	names := []string{}
+	for _, p := range op.Parameters {
+		if p.In == "header" {
+			names = append(names, p.Name)
+			assert.True(t, p.Required)
+		}
+	}
+	assert.ElementsMatch(t, []string{SignatureInputHeader, SignatureHeader}, names)
+	require.NotNil(t, op.Responses["401"])
+	assert.NotNil(t, op.Responses["401"].Headers[AcceptSignatureHeader])
+	assert.NotNil(t, op.Responses["200"].Headers[SignatureHeader])
+
+	assert.Empty(t, api.OpenAPI().Paths["/orders"].Get.Parameters)
+}
+
+func TestConfigRequired(t *testing.T) {
+	_, api := humatest.New(t)
+	assert.Panics(t, func() {
+		Use(api, Config{})
+	})
+	assert.Panics(t, func() {
+		Use(api, Config{
+			KeyResolver: func(ctx context.Context, keyID string) (*Key, error) { return nil, nil },
+			Components:  []string{`"@query-param`},
+		})
+	})
+}
@@ -0,0 +1,336 @@
+package httpsig
+
+import (
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// This file implements the subset of RFC 8941 Structured Field Values needed
+// for the `Signature-Input` and `Signature` dictionaries.
+
+var errMalformed = errors.New("malformed structured field")
+
+// token is a structured field token, which is serialized without quotes.
+type token string
+
+// param is a structured field parameter. Values are `int64`, `string`,
+// `token`, `bool`, or `[]byte`.
+type param struct {
+	key   string
+	value any
+}
+
+type params []param
+
+func (ps params) get(key string) (any, bool) {
+	for _, p := range ps {
+		if p.key == key {
+			return p.value, true
+		}
+	}
+	return nil, false
+}
+
+// item is a bare item with parameters.
+type item struct {
+	value  any
+	params params
+}
+
+// member is a dictionary member, which is either an item or an inner list.
+type member struct {
+	key    string
+	item   item
+	list   []item
+	isList bool
+}
+
+type sfParser struct {
+	s   string
+	pos int
+}
+
+func (p *sfParser) eof() bool {
+	return p.pos >= len(p.s)
+}
+
+func (p *sfParser) peek() byte {
+	if p.eof() {
+		return 0
+	}
+	return p.s[p.pos]
+}
+
+func (p *sfParser) skipSP() {
+	for !p.eof() && p.s[p.pos] == ' ' {
+		p.pos++
+	}
+}
+
+func (p *sfParser) skipOWS() {
+	for !p.eof() && (p.s[p.pos] == ' ' || p.s[p.pos] == '\t') {
+		p.pos++
+	}
+}
+
+// parseDictionary parses a structured field dictionary, preserving order.
+// Later members with the same key replace earlier ones.
+func parseDictionary(s string) ([]member, error) {
+	p := &sfParser{s: s}
+	p.skipSP()
+	members := []member{}
+	for !p.eof() {
+		key, err := p.key()
+		if err != nil {
+			return nil, err
+		}
+		m := member{key: key}
+		if p.peek() == '=' {
+			p.pos++
+			if p.peek() == '(' {
+				m.isList = true
+				if m.list, m.item.params, err = p.innerList(); err != nil {
+					return nil, err
+				}
+			} else if m.item, err = p.item(); err != nil {
+				return nil, err
+			}
+		} else {
+			m.item.value = true
+			if m.item.params, err = p.params(); err != nil {
+				return nil, err
+			}
+		}
+
+		replaced := false
+		for i := range members {
+			if members[i].key == key {
+				members[i] = m
+				replaced = true
+			}
+		}
+		if !replaced {
+			members = append(members, m)
+		}
+
+		p.skipOWS()
+		if p.eof() {
+			break
+		}
+		if p.peek() != ',' {
+			return nil, errMalformed
+		}
+		p.pos++
+		p.skipOWS()
+		if p.eof() {
+			// Trailing comma.
+			return nil, errMalformed
+		}
+	}
+	return members, nil
+}
+
+func isLCAlpha(c byte) bool { return c >= 'a' && c <= 'z' }
+func isDigit(c byte) bool   { return c >= '0' && c <= '9' }
+
+func (p *sfParser) key() (string, error) {
+	c := p.peek()
+	if !isLCAlpha(c) && c != '*' {
+		return "", errMalformed
+	}
+	start := p.pos
+	for !p.eof() {
+		c := p.peek()
+		if !isLCAlpha(c) && !isDigit(c) && c != '_' && c != '-' && c != '.' && c != '*' {
+			break
+		}
+		p.pos++
+	}
+	return p.s[start:p.pos], nil
+}
+
+func (p *sfParser) innerList() ([]item, params, error) {
+	p.pos++ // (
+	items := []item{}
+	for !p.eof() {
+		p.skipSP()
+		if p.peek() == ')' {
+			p.pos++
+			ps, err := p.params()
+			return items, ps, err
+		}
+		it, err := p.item()
+		if err != nil {
+			return nil, nil, err
+		}
+		items = append(items, it)
+		if c := p.peek(); c != ' ' && c != ')' {
+			return nil, nil, errMalformed
+		}
+	}
+	return nil, nil, errMalformed
+}
+
+func (p *sfParser) item() (item, error) {
+	v, err := p.bareItem()
+	if err != nil {
+		return item{}, err
+	}
+	ps, err := p.params()
+	return item{value: v, params: ps}, err
+}
+
+func (p *sfParser) params() (params, error) {
+	var ps params
+	for p.peek() == ';' {
+		p.pos++
+		p.skipSP()
+		key, err := p.key()
+		if err != nil {
+			return nil, err
+		}
+		var value any = true
+		if p.peek() == '=' {
+			p.pos++
+			if value, err = p.bareItem(); err != nil {
+				return nil, err
+			}
+		}
+		ps = append(ps, param{key, value})
+	}
+	return ps, nil
+}
+
+func (p *sfParser) bareItem() (any, error) {
+	c := p.peek()
+	switch {
+	case c == '-' || isDigit(c):
+		start := p.pos
+		p.pos++
+		for !p.eof() && isDigit(p.peek()) {
+			p.pos++
+		}
+		if p.peek() == '.' {
+			// Decimals are not used by signatures.
+			return nil, errMalformed
+		}
+		i, err := strconv.ParseInt(p.s[start:p.pos], 10, 64)
+		if err != nil || p.pos-start > 16 {
+			return nil, errMalformed
+		}
+		return i, nil
+	case c == '"':
+		p.pos++
+		var sb strings.Builder
+		for !p.eof() {
+			c := p.s[p.pos]
+			p.pos++
+			switch {
+			case c == '\\':
+				if p.eof() || (p.s[p.pos] != '"' && p.s[p.pos] != '\\') {
+					return nil, errMalformed
+				}
+				sb.WriteByte(p.s[p.pos])
+				p.pos++
+			case c == '"':
+				return sb.String(), nil
+			case c < 0x20 || c > 0x7e:
+				return nil, errMalformed
+			default:
+				sb.WriteByte(c)
+			}
+		}
+		return nil, errMalformed
+	case c == ':':
+		p.pos++
+		end := strings.IndexByte(p.s[p.pos:], ':')
+		if end == -1 {
+			return nil, errMalformed
+		}
+		b, err := base64.StdEncoding.DecodeString(p.s[p.pos : p.pos+end])
+		if err != nil {
+			return nil, errMalformed
+		}
+		p.pos += end + 1
+		return b, nil
+	case c == '?':
+		p.pos++
+		switch p.peek() {
+		case '0':
+			p.pos++
+			return false, nil
+		case '1':
+			p.pos++
+			return true, nil
+		}
+		return nil, errMalformed
+	case c == '*' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z'):
+		start := p.pos
+		for !p.eof() {
+			c := p.peek()
+			if c <= ' ' || c >= 0x7f || strings.IndexByte(`"(),;<=>?@[\]{}`, c) != -1 {
+				break
+			}
+			p.pos++
+		}
+		return token(p.s[start:p.pos]), nil
+	}
+	return nil, errMalformed
+}
+
+// serializeBareItem serializes a bare item value.
+func serializeBareItem(sb *strings.Builder, v any) {
+	switch v := v.(type) {
+	case int64:
+		sb.WriteString(strconv.FormatInt(v, 10))
+	case string:
+		sb.WriteByte('"')
+		for i := 0; i < len(v); i++ {
+			if v[i] == '"' || v[i] == '\\' {
+				sb.WriteByte('\\')
+			}
+			sb.WriteByte(v[i])
+		}
+		sb.WriteByte('"')
+	case token:
+		sb.WriteString(string(v))
+	case bool:
+		if v {
+			sb.WriteString("?1")
+		} else {
+			sb.WriteString("?0")
+		}
+	case []byte:
+		sb.WriteString(":" + base64.StdEncoding.EncodeToString(v) + ":")
+	}
+}
+
+func serializeParams(sb *strings.Builder, ps params) {
+	for _, p := range ps {
+		sb.WriteString(";" + p.key)
+		if b, ok := p.value.(bool); ok && b {
+			continue
+		}
+		sb.WriteByte('=')
+		serializeBareItem(sb, p.value)
+	}
+}
+
+// serializeInnerList serializes an inner list with its parameters, as used
+// for the `@signature-params` component and the `Signature-Input` header.
+func serializeInnerList(items []item, ps params) string {
+	var sb strings.Builder
+	sb.WriteByte('(')
+	for i, it := range items {
+		if i > 0 {
+			sb.WriteByte(' ')
+		}
+		serializeBareItem(&sb, it.value)
+		serializeParams(&sb, it.params)
+	}
+	sb.WriteByte(')')
+	serializeParams(&sb, ps)
+	return sb.String()
+}
@@ -10,12 +10,14 @@ package huma
 import (
 	"bytes"
 	"context"
+	"encoding"
 	"errors"
 	"fmt"
 	"io"
 	"net"
 	"net/http"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -25,6 +27,7 @@ import (
 var errDeadlineUnsupported = fmt.Errorf("%w", http.ErrNotSupported)
 
 var bodyCallbackType = reflect.TypeOf(func(Context) {})
+var cookieType = reflect.TypeOf(http.Cookie{})
 
 // slicesIndex returns the index of the first occurrence of v in s,
 // or -1 if not present.
@@ -88,6 +91,20 @@ type StreamResponse struct {
 	Body func(ctx Context)
 }
 
+// StatusCoder can be implemented by output structs to set the response
+// status code at runtime, for example to return a 201 Created instead of a
+// 200 OK. If zero is returned, the operation's default status is used.
+// Alternatively, output structs may have a `Status int` field. To document
+// each possible status, use an `enum` tag on the field:
+//
+//	type MyOutput struct {
+//		Status int `enum:"200,201"`
+//		Body   MyBody
+//	}
+type StatusCoder interface {
+	GetStatus() int
+}
+
 type paramFieldInfo struct {
 	Type       reflect.Type
 	Name       string
@@ -96,14 +113,257 @@ type paramFieldInfo struct {
 	Default    string
 	TimeFormat string
 	Schema     *Schema
+	Multi      bool
+	Deprecated bool
+	locale     bool
+	parse      paramParser
+
+	// style is the `matrix` or `label` style of a path param, if set.
+	// Exploded arrays repeat the name (matrix) or prefix (label) for each
+	// item rather than separating items with commas.
+	style       string
+	explode     bool
+	stylePrefix string
+	msgStyle    string
+
+	// msgRequired is precomputed to avoid allocating on bad requests.
+	msgRequired string
+
+	// location and warning are precomputed for reporting deprecated usage.
+	location string
+	warning  string
+}
+
+// unstyle strips the `matrix` or `label` style prefix from a path param's
+// value, returning the plain value with array items separated by commas, or
+// an error message if the value is not in the expected style.
+func (p *paramFieldInfo) unstyle(value string) (string, string) {
+	if !strings.HasPrefix(value, p.stylePrefix) {
+		if p.style == "matrix" && value == p.stylePrefix[:len(p.stylePrefix)-1] {
+			// Empty values are sent as just `;name`.
+			return "", ""
+		}
+		return "", p.msgStyle
+	}
+	value = value[len(p.stylePrefix):]
+	if !p.explode || p.Type.Kind() != reflect.Slice {
+		return value, ""
+	}
+	if p.style == "label" {
+		return strings.ReplaceAll(value, ".", ","), ""
+	}
+	items := strings.Split(value, ";")
+	for i := 1; i < len(items); i++ {
+		if !strings.HasPrefix(items[i], p.Name+"=") {
+			return "", p.msgStyle
+		}
+		items[i] = items[i][len(p.Name)+1:]
+	}
+	return strings.Join(items, ","), ""
+}
+
+// paramParser parses a param's string value and sets it on the field `f`,
+// returning the parsed value for validation or an error message. They are
+// created once per field at registration time so that the request hot path
+// does not need to inspect the field's type.
+type paramParser func(f reflect.Value, value string, cookie *http.Cookie) (any, string)
+
+// newParamParser returns the parser for the given param field, panicking if
+// the field's type is not supported.
+func newParamParser(p *paramFieldInfo) paramParser {
+	switch p.Type.Kind() {
+	case reflect.String:
+		return func(f reflect.Value, value string, _ *http.Cookie) (any, string) {
+			f.SetString(value)
+			return value, ""
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return func(f reflect.Value, value string, _ *http.Cookie) (any, string) {
+			v, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return nil, "invalid integer"
+			}
+			f.SetInt(v)
+			return v, ""
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return func(f reflect.Value, value string, _ *http.Cookie) (any, string) {
+			v, err := strconv.ParseUint(value, 10, 64)
+			if err != nil {
+				return nil, "invalid integer"
+			}
+			f.SetUint(v)
+			return v, ""
+		}
+	case reflect.Float32, reflect.Float64:
+		return func(f reflect.Value, value string, _ *http.Cookie) (any, string) {
+			v, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return nil, "invalid float"
+			}
+			f.SetFloat(v)
+			return v, ""
+		}
+	case reflect.Bool:
+		return func(f reflect.Value, value string, _ *http.Cookie) (any, string) {
+			v, err := strconv.ParseBool(value)
+			if err != nil {
+				return nil, "invalid boolean"
+			}
+			f.SetBool(v)
+			return v, ""
+		}
+	case reflect.Slice:
+		switch p.Type.Elem().Kind() {
+		case reflect.String:
+			return func(f reflect.Value, value string, _ *http.Cookie) (any, string) {
+				values := strings.Split(value, ",")
+				f.Set(reflect.ValueOf(values))
+				return values, ""
+			}
+		case reflect.Int:
+			return sliceParamParser("invalid integer", func(s string) (int, error) {
+				val, err := strconv.ParseInt(s, 10, strconv.IntSize)
+				return int(val), err
+			})
+		case reflect.Int8:
+			return sliceParamParser("invalid integer", func(s string) (int8, error) {
+				val, err := strconv.ParseInt(s, 10, 8)
+				return int8(val), err
+			})
+		case reflect.Int16:
+			return sliceParamParser("invalid integer", func(s string) (int16, error) {
+				val, err := strconv.ParseInt(s, 10, 16)
+				return int16(val), err
+			})
+		case reflect.Int32:
+			return sliceParamParser("invalid integer", func(s string) (int32, error) {
+				val, err := strconv.ParseInt(s, 10, 32)
+				return int32(val), err
+			})
+		case reflect.Int64:
+			return sliceParamParser("invalid integer", func(s string) (int64, error) {
+				return strconv.ParseInt(s, 10, 64)
+			})
+		case reflect.Uint:
+			return sliceParamParser("invalid integer", func(s string) (uint, error) {
+				val, err := strconv.ParseUint(s, 10, strconv.IntSize)
+				return uint(val), err
+			})
+		case reflect.Uint16:
+			return sliceParamParser("invalid integer", func(s string) (uint16, error) {
+				val, err := strconv.ParseUint(s, 10, 16)
+				return uint16(val), err
+			})
+		case reflect.Uint32:
+			return sliceParamParser("invalid integer", func(s string) (uint32, error) {
+				val, err := strconv.ParseUint(s, 10, 32)
+				return uint32(val), err
+			})
+		case reflect.Uint64:
+			return sliceParamParser("invalid integer", func(s string) (uint64, error) {
+				return strconv.ParseUint(s, 10, 64)
+			})
+		case reflect.Float32:
+			return sliceParamParser("invalid floating value", func(s string) (float32, error) {
+				val, err := strconv.ParseFloat(s, 32)
+				return float32(val), err
+			})
+		case reflect.Float64:
+			return sliceParamParser("invalid floating value", func(s string) (float64, error) {
+				return strconv.ParseFloat(s, 64)
+			})
+		}
+		if reflect.PointerTo(p.Type.Elem()).Implements(textUnmarshalerType) {
+			return func(f reflect.Value, value string, _ *http.Cookie) (any, string) {
+				parts := strings.Split(value, ",")
+				vs := reflect.MakeSlice(p.Type, len(parts), len(parts))
+				for i, part := range parts {
+					if err := vs.Index(i).Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(part)); err != nil {
+						return nil, "invalid value: " + err.Error()
+					}
+				}
+				f.Set(vs)
+				// Validate the original strings so formats like `ip` apply.
+				values := make([]any, len(parts))
+				for i, part := range parts {
+					values[i] = part
+				}
+				return values, ""
+			}
+		}
+		// Unsupported slices are left unset and fail validation.
+		return func(f reflect.Value, value string, _ *http.Cookie) (any, string) {
+			return nil, ""
+		}
+	}
+
+	// Special case: http.Cookie
+	if p.Type == cookieType {
+		return func(f reflect.Value, value string, cookie *http.Cookie) (any, string) {
+			if cookie == nil {
+				cookie = &http.Cookie{Name: p.Name, Value: value}
+			}
+			f.Set(reflect.ValueOf(*cookie))
+			return value, ""
+		}
+	}
+
+	// Special case: time.Time
+	if p.Type == timeType {
+		msgInvalid := "invalid date/time for format " + p.TimeFormat
+		return func(f reflect.Value, value string, _ *http.Cookie) (any, string) {
+			t, err := time.Parse(p.TimeFormat, value)
+			if err != nil {
+				return nil, msgInvalid
+			}
+			f.Set(reflect.ValueOf(t))
+			return value, ""
+		}
+	}
+
+	// Special case: types like `netip.Addr` which parse themselves.
+	if reflect.PointerTo(p.Type).Implements(textUnmarshalerType) {
+		return func(f reflect.Value, value string, _ *http.Cookie) (any, string) {
+			if err := f.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(value)); err != nil {
+				return nil, "invalid value: " + err.Error()
+			}
+			return value, ""
+		}
+	}
+
+	panic("unsupported param type " + p.Type.String())
+}
+
+// sliceParamParser returns a parser for comma-separated slice params.
+func sliceParamParser[T any](msg string, parse func(string) (T, error)) paramParser {
+	return func(f reflect.Value, value string, _ *http.Cookie) (any, string) {
+		vs, err := parseArrElement(strings.Split(value, ","), parse)
+		if err != nil {
+			return nil, msg
+		}
+		f.Set(reflect.ValueOf(vs))
+		return vs, ""
+	}
 }
 
 func findParams(registry Registry, op *Operation, t reflect.Type) *findResult[*paramFieldInfo] {
+	seen := map[string]bool{}
 	return findInType(t, nil, func(f reflect.StructField, path []int) *paramFieldInfo {
 		if f.Anonymous {
 			return nil
 		}
 
+		if shadowed(t, path) {
+			// The input replaces a param promoted from an embedded struct.
+			return nil
+		}
+
+		if f.Type == cookieType && f.Tag.Get("cookie") == "" {
+			// Cookie fields are only supported as cookie params.
+			return nil
+		}
+
 		if f.Type.Kind() == reflect.Pointer {
 			// TODO: support pointers? The problem is that when we dynamically
 			// create an instance of the input struct the `params.Every(...)`
@@ -113,9 +373,20 @@ func findParams(registry Registry, op *Operation, t reflect.Type) *findResult[*p
 			panic("pointers are not supported for path/query/header parameters")
 		}
 
+		if f.Tag.Get("queryPrefix") != "" {
+			if f.Type.Kind() != reflect.Struct {
+				panic(fmt.Sprintf("queryPrefix requires a struct field but %s is %s", f.Name, f.Type))
+			}
+			// Members of the group are found as the struct is traversed.
+			return nil
+		}
+
 		pfi := &paramFieldInfo{
 			Type:   f.Type,
-			Schema: SchemaFromField(registry, f, ""),
+			locale: f.Type == localeType,
+		}
+		if f.Type != cookieType {
+			pfi.Schema = SchemaFromField(registry, f, "")
 		}
 
 		var example any
@@ -133,16 +404,46 @@ func findParams(registry Registry, op *Operation, t reflect.Type) *findResult[*p
 			pfi.Loc = "path"
 			name = p
 			pfi.Required = true
+			switch style := f.Tag.Get("style"); style {
+			case "", "simple":
+			case "matrix", "label":
+				pfi.style = style
+				pfi.explode = boolTag(f, "explode")
+				if style == "matrix" {
+					pfi.stylePrefix = ";" + name + "="
+					pfi.msgStyle = "expected matrix style value like ;" + name + "=value"
+				} else {
+					pfi.stylePrefix = "."
+					pfi.msgStyle = "expected label style value like .value"
+				}
+				if pfi.explode {
+					explode = &pfi.explode
+				}
+			default:
+				panic(fmt.Sprintf("unsupported style %q for path param %s", style, name))
+			}
 		} else if q := f.Tag.Get("query"); q != "" {
 			pfi.Loc = "query"
-			name = q
+			name = queryPrefix(t, path) + q
 			// If `in` is `query` then `explode` defaults to true. Parsing is *much*
 			// easier if we use comma-separated values, so we disable explode.
 			nope := false
 			explode = &nope
+			// Slices also accept repeated params like `?tag=a&tag=b`.
+			pfi.Multi = f.Type.Kind() == reflect.Slice
 		} else if h := f.Tag.Get("header"); h != "" {
 			pfi.Loc = "header"
 			name = h
+			// Slices get the items from all of the header's values.
+			pfi.Multi = f.Type.Kind() == reflect.Slice
+		} else if c := f.Tag.Get("cookie"); c != "" {
+			pfi.Loc = "cookie"
+			name = c
+
+			if f.Type == cookieType {
+				// Special case: `http.Cookie` is sent as a simple string value.
+				pfi.Schema = &Schema{Type: TypeString}
+			}
 		} else {
 			return nil
 		}
@@ -154,6 +455,15 @@ func findParams(registry Registry, op *Operation, t reflect.Type) *findResult[*p
 
 		pfi.Name = name
 
+		key := pfi.Loc + "." + name
+		if pfi.Loc == "header" {
+			key = strings.ToLower(key)
+		}
+		if seen[key] {
+			panic(fmt.Sprintf("duplicate %s parameter %q in %s", pfi.Loc, name, t))
+		}
+		seen[key] = true
+
 		if f.Type == timeType {
 			timeFormat := time.RFC3339Nano
 			if pfi.Loc == "header" {
@@ -164,32 +474,145 @@ func findParams(registry Registry, op *Operation, t reflect.Type) *findResult[*p
 			}
 			pfi.TimeFormat = timeFormat
 		}
+		pfi.parse = newParamParser(pfi)
+		pfi.msgRequired = "required " + pfi.Loc + " parameter is missing"
+		if pfi.Schema != nil && pfi.Schema.Deprecated {
+			pfi.Deprecated = true
+			pfi.location = pfi.Loc + "." + name
+			pfi.warning = deprecatedWarning(pfi.location)
+		}
 
 		if f.Tag.Get("hidden") == "" {
 			// Document the parameter if not hidden.
+			var description string
+			if _, wildcard, ok := PathWildcard(op.Path); ok && pfi.Loc == "path" && name == wildcard {
+				description = wildcardDescription
+			}
 			op.Parameters = append(op.Parameters, &Param{
-				Name:     name,
-				In:       pfi.Loc,
-				Explode:  explode,
-				Required: pfi.Required,
-				Schema:   pfi.Schema,
-				Example:  example,
+				Name:        name,
+				Description: description,
+				In:          pfi.Loc,
+				Style:       pfi.style,
+				Explode:     explode,
+				Required:    pfi.Required,
+				Deprecated:  pfi.Deprecated,
+				Schema:      pfi.Schema,
+				Example:     example,
 			})
 		}
 		return pfi
 	}, "Body")
 }
 
-func findResolvers(resolverType, t reflect.Type) *findResult[bool] {
-	return findInType(t, func(t reflect.Type, path []int) bool {
-		if reflect.PtrTo(t).Implements(resolverType) {
-			return true
-		}
-		if reflect.PtrTo(t).Implements(resolverWithPathType) {
-			return true
+// bodyErrorValue returns the request body to include in a parse error, or
+// `Redacted` if it may contain sensitive fields.
+func bodyErrorValue(body any, sensitive bool) any {
+	if sensitive {
+		return Redacted
+	}
+	return body
+}
+
+// queryPrefix returns the combined `queryPrefix` tags of the struct fields
+// containing the field at `path`, so that reusable groups of query params
+// can be bound with different names, e.g. `page_size` and `page_token`.
+func queryPrefix(t reflect.Type, path []int) string {
+	prefix := ""
+	for _, i := range path[:len(path)-1] {
+		t = deref(t)
+		if t.Kind() != reflect.Struct {
+			break
 		}
+		f := t.Field(i)
+		prefix += f.Tag.Get("queryPrefix")
+		t = f.Type
+	}
+	return prefix
+}
+
+// deprecatedField is a body field marked as deprecated. Sending its default
+// value is not considered usage, as it cannot be told apart from omitting it.
+type deprecatedField struct {
+	def any
+}
+
+// used returns whether a request set the field.
+func (d *deprecatedField) used(item reflect.Value) bool {
+	if item.IsZero() {
+		return false
+	}
+	if d.def != nil && reflect.DeepEqual(item.Interface(), reflect.Indirect(reflect.ValueOf(d.def)).Interface()) {
 		return false
+	}
+	return true
+}
+
+func findDeprecated(t reflect.Type) *findResult[*deprecatedField] {
+	return findInType(t, nil, func(sf reflect.StructField, i []int) *deprecatedField {
+		if !boolTag(sf, "deprecated") {
+			return nil
+		}
+		for _, tag := range []string{"path", "query", "header", "cookie"} {
+			if sf.Tag.Get(tag) != "" {
+				// Params are handled while parsing them.
+				return nil
+			}
+		}
+		d := &deprecatedField{}
+		if def := sf.Tag.Get("default"); def != "" {
+			d.def = jsonTagValue(sf, sf.Type, def)
+		}
+		return d
+	})
+}
+
+// deprecatedWarning returns the `Warning` header value sent when a request
+// uses a deprecated parameter or body field at the given location.
+func deprecatedWarning(location string) string {
+	// Locations may contain quoted map keys, which must be escaped within the
+	// quoted warning text.
+	return `299 - "` + warningEscaper.Replace(location) + ` is deprecated"`
+}
+
+var warningEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+
+func findResolvers(resolverType, t reflect.Type) *findResult[*resolverInfo] {
+	root := t
+	result := findInType(t, func(t reflect.Type, path []int) *resolverInfo {
+		if t.Kind() == reflect.Interface {
+			// The value's dynamic type is checked for resolvers at runtime.
+			return &resolverInfo{}
+		}
+		pt := reflect.PtrTo(t)
+		if !pt.Implements(resolverType) && !pt.Implements(resolverWithPathType) {
+			return nil
+		}
+		if len(path) > 0 {
+			if parent, f := fieldAt(root, path); f.Anonymous && deref(f.Type) == t {
+				ppt := reflect.PtrTo(parent)
+				if ppt.Implements(resolverType) || ppt.Implements(resolverWithPathType) {
+					// The resolver is promoted to the parent, or replaced by its own,
+					// so it must not run a second time.
+					return nil
+				}
+				if !f.IsExported() {
+					panic(fmt.Sprintf("resolver of unexported embedded struct %s in %s cannot be run", t, parent))
+				}
+			}
+		}
+		info := &resolverInfo{}
+		if pt.Implements(resolverOrderType) {
+			info.order = reflect.New(t).Interface().(ResolverOrder).ResolverOrder()
+		}
+		return info
 	}, nil)
+
+	// Paths are found parents first, then fields in declaration order. Keep
+	// that order for resolvers with the same order value.
+	sort.SliceStable(result.Paths, func(i, j int) bool {
+		return result.Paths[i].Value.order < result.Paths[j].Value.order
+	})
+	return result
 }
 
 func findDefaults(t reflect.Type) *findResult[any] {
@@ -212,12 +635,26 @@ type headerInfo struct {
 
 func findHeaders(t reflect.Type) *findResult[*headerInfo] {
 	return findInType(t, nil, func(sf reflect.StructField, i []int) *headerInfo {
+		for j := 1; j < len(i); j++ {
+			if !deref(t).FieldByIndex(i[:j]).Anonymous {
+				// Only top-level and embedded fields are headers, e.g. the fields
+				// of an `http.Cookie` are not.
+				return nil
+			}
+		}
+		if sf.Anonymous && deref(sf.Type).Kind() == reflect.Struct && sf.Tag.Get("header") == "" {
+			// Headers are promoted from embedded structs.
+			return nil
+		}
+		if shadowed(t, i) {
+			return nil
+		}
 		header := sf.Tag.Get("header")
 		if header == "" {
 			header = sf.Name
 		}
 		timeFormat := ""
-		if sf.Type == timeType {
+		if sf.Type == timeType || (sf.Type.Kind() == reflect.Slice && sf.Type.Elem() == timeType) {
 			timeFormat = http.TimeFormat
 			if f := sf.Tag.Get("timeFormat"); f != "" {
 				timeFormat = f
@@ -227,6 +664,77 @@ func findHeaders(t reflect.Type) *findResult[*headerInfo] {
 	}, "Status", "Body")
 }
 
+// headerList combines the items from all values of a repeated header into a
+// single comma-separated list. Whitespace around items is trimmed and empty
+// items are skipped, as described by RFC 9110 section 5.6.1.
+func headerList(ctx Context, name string) string {
+	sb := strings.Builder{}
+	ctx.EachHeader(func(n, v string) {
+		if !strings.EqualFold(n, name) {
+			return
+		}
+		for _, item := range strings.Split(v, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				if sb.Len() > 0 {
+					sb.WriteByte(',')
+				}
+				sb.WriteString(item)
+			}
+		}
+	})
+	return sb.String()
+}
+
+// readCookies parses all cookies sent in the request's `Cookie` headers.
+func readCookies(ctx Context) map[string]*http.Cookie {
+	var headers []string
+	ctx.EachHeader(func(name, value string) {
+		if strings.EqualFold(name, "Cookie") {
+			headers = append(headers, value)
+		}
+	})
+	req := http.Request{Header: http.Header{"Cookie": headers}}
+	cookies := map[string]*http.Cookie{}
+	for _, c := range req.Cookies() {
+		if _, ok := cookies[c.Name]; !ok {
+			cookies[c.Name] = c
+		}
+	}
+	return cookies
+}
+
+// formatHeader converts an output header field value into a string. Empty
+// strings and zero times return false as they should not be sent.
+func formatHeader(f reflect.Value, timeFormat string) (string, bool) {
+	switch f.Kind() {
+	case reflect.String:
+		return f.String(), f.String() != ""
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(f.Int(), 10), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(f.Uint(), 10), true
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(f.Float(), 'f', -1, 64), true
+	case reflect.Bool:
+		return strconv.FormatBool(f.Bool()), true
+	}
+
+	if f.Type() == cookieType {
+		c := f.Interface().(http.Cookie)
+		return c.String(), c.Name != ""
+	}
+
+	if f.Type() == timeType {
+		t := f.Interface().(time.Time)
+		if t.IsZero() {
+			return "", false
+		}
+		return t.Format(timeFormat), true
+	}
+
+	return fmt.Sprintf("%v", f.Interface()), true
+}
+
 type findResultPath[T comparable] struct {
 	Path  []int
 	Value T
@@ -309,6 +817,10 @@ func (r *findResult[T]) everyPB(current reflect.Value, path []int, pb *PathBuffe
 				pb.Push("header")
 				pb.Push(header)
 				pops++
+			} else if cookie := field.Tag.Get("cookie"); cookie != "" && pb.Len() == 0 {
+				pb.Push("cookie")
+				pb.Push(cookie)
+				pops++
 			} else {
 				// The body is _always_ in a field called "Body", which turns into
 				// `body` in the path buffer, so we don't need to push it separately
@@ -327,13 +839,23 @@ func (r *findResult[T]) everyPB(current reflect.Value, path []int, pb *PathBuffe
 			pb.Pop()
 		}
 	case reflect.Map:
-		for _, k := range current.MapKeys() {
+		for _, k := range sortedMapKeys(current) {
 			if k.Kind() == reflect.String {
-				pb.Push(k.String())
+				pb.PushKey(k.String())
 			} else {
-				pb.Push(fmt.Sprintf("%v", k.Interface()))
+				pb.PushKey(fmt.Sprintf("%v", k.Interface()))
+			}
+			item := current.MapIndex(k)
+			if item.Kind() == reflect.Pointer {
+				r.everyPB(item.Elem(), path, pb, v, f)
+			} else {
+				// Map values are not addressable, so walk a copy and store it back
+				// in case it was modified, e.g. by a resolver.
+				tmp := reflect.New(item.Type()).Elem()
+				tmp.Set(item)
+				r.everyPB(tmp, path, pb, v, f)
+				current.SetMapIndex(k, tmp)
 			}
-			r.everyPB(reflect.Indirect(current.MapIndex(k)), path, pb, v, f)
 			pb.Pop()
 		}
 	default:
@@ -352,6 +874,39 @@ func (r *findResult[T]) EveryPB(pb *PathBuffer, v reflect.Value, f func(reflect.
 	}
 }
 
+// validationStatus returns the status code for a request which failed
+// validation. If any of the errors provide a status then the last one is
+// used, since they are added in order, otherwise `status` is used.
+func validationStatus(status int, errs []error) int {
+	for i := len(errs) - 1; i >= 0; i-- {
+		if s, ok := errs[i].(StatusError); ok {
+			return s.GetStatus()
+		}
+	}
+	return status
+}
+
+// sortedMapKeys returns the keys of a map in a deterministic order: numbers
+// by value and anything else by its formatted string.
+func sortedMapKeys(m reflect.Value) []reflect.Value {
+	keys := m.MapKeys()
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := keys[i], keys[j]
+		switch a.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return a.Int() < b.Int()
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			return a.Uint() < b.Uint()
+		case reflect.Float32, reflect.Float64:
+			return a.Float() < b.Float()
+		case reflect.String:
+			return a.String() < b.String()
+		}
+		return fmt.Sprintf("%v", a.Interface()) < fmt.Sprintf("%v", b.Interface())
+	})
+	return keys
+}
+
 func findInType[T comparable](t reflect.Type, onType func(reflect.Type, []int) T, onField func(reflect.StructField, []int) T, ignore ...string) *findResult[T] {
 	result := &findResult[T]{}
 	_findInType(t, []int{}, result, onType, onField, ignore...)
@@ -372,7 +927,9 @@ func _findInType[T comparable](t reflect.Type, path []int, result *findResult[T]
 	case reflect.Struct:
 		for i := 0; i < t.NumField(); i++ {
 			f := t.Field(i)
-			if !f.IsExported() {
+			if !f.IsExported() && !(f.Anonymous && f.Type.Kind() == reflect.Struct) {
+				// The exported fields of unexported embedded structs are still
+				// promoted and settable, but not those behind pointers.
 				continue
 			}
 			if slicesContains(ignore, f.Name) {
@@ -405,13 +962,14 @@ func getHint(parent reflect.Type, name string, other string) string {
 type validateDeps struct {
 	pb  *PathBuffer
 	res *ValidateResult
+	lr  io.LimitedReader
 }
 
 var validatePool = sync.Pool{
 	New: func() any {
 		return &validateDeps{
 			pb:  &PathBuffer{buf: make([]byte, 0, 128)},
-			res: &ValidateResult{},
+			res: &ValidateResult{pooled: true},
 		}
 	},
 }
@@ -422,17 +980,71 @@ var bufPool = sync.Pool{
 	},
 }
 
+// maxPooledBufSize is the largest buffer capacity that is returned to the
+// buffer pool. Larger buffers from occasional big bodies are left to the
+// garbage collector rather than being held on to.
+const maxPooledBufSize = 1024 * 1024
+
+// releaseBuf returns a buffer to the buffer pool.
+func releaseBuf(buf *bytes.Buffer) {
+	if buf.Cap() > maxPooledBufSize {
+		return
+	}
+	buf.Reset()
+	bufPool.Put(buf)
+}
+
+// writeBodyTooLarge writes a 413 error for a request body over the limit,
+// including the received `Content-Length` if known (non-negative).
+func writeBodyTooLarge(api API, ctx Context, limit, length int64, errs []error) {
+	detail := &ErrorDetail{
+		Location: "body",
+		Message:  fmt.Sprintf("expected at most %d bytes", limit),
+	}
+	if length >= 0 {
+		detail.Location = "header.Content-Length"
+		detail.Value = length
+	}
+	WriteErr(api, ctx, http.StatusRequestEntityTooLarge, fmt.Sprintf("request body is too large limit=%d bytes", limit), append(errs, detail)...)
+}
+
+// copyErrors copies validation errors out of the pooled validation result so
+// they remain valid after the request has been handled.
+func copyErrors(errs []error) []error {
+	copied := make([]error, len(errs))
+	for i, err := range errs {
+		if d, ok := err.(*ErrorDetail); ok {
+			c := *d
+			err = &c
+		}
+		copied[i] = err
+	}
+	return copied
+}
+
 // transformAndWrite is a utility function to transform and write a response.
-// It is best-effort as the status code and headers may have already been sent.
+// It is best-effort as the status code and headers may have already been sent,
+// unless the operation uses the `ResponseBuffered` strategy.
 func transformAndWrite(api API, ctx Context, status int, ct string, body any) {
+	if op := ctx.Operation(); op != nil && op.ResponseStrategy == ResponseBuffered {
+		transformAndWriteBuffered(api, ctx, status, ct, body)
+		return
+	}
+
 	// Try to transform and then marshal/write the response.
 	// Status code was already sent, so just log the error if something fails,
 	// and do our best to stuff it into the body of the response.
+	timings := getServerTimings(ctx.Context())
+	var start time.Time
+	if timings != nil {
+		start = time.Now()
+	}
 	tval, terr := api.Transform(ctx, strconv.Itoa(status), body)
 	if terr != nil {
 		ctx.BodyWriter().Write([]byte("error transforming response"))
 		panic(fmt.Sprintf("error transforming response %+v for %s %s %d: %s\n", tval, ctx.Operation().Method, ctx.Operation().Path, status, terr.Error()))
 	}
+	timings.since(ServerTimingSerialization, start)
 	ctx.SetStatus(status)
 	if merr := api.Marshal(ctx.BodyWriter(), ct, tval); merr != nil {
 		ctx.BodyWriter().Write([]byte("error marshaling response"))
@@ -440,6 +1052,33 @@ func transformAndWrite(api API, ctx Context, status int, ct string, body any) {
 	}
 }
 
+// transformAndWriteBuffered transforms and marshals the response into memory
+// before writing anything, so that failures can still result in a proper
+// error response (e.g. via panic recovery) and `Content-Length` can be set.
+func transformAndWriteBuffered(api API, ctx Context, status int, ct string, body any) {
+	timings := getServerTimings(ctx.Context())
+	var start time.Time
+	if timings != nil {
+		start = time.Now()
+	}
+
+	tval, terr := api.Transform(ctx, strconv.Itoa(status), body)
+	if terr != nil {
+		panic(fmt.Sprintf("error transforming response %+v for %s %s %d: %s\n", tval, ctx.Operation().Method, ctx.Operation().Path, status, terr.Error()))
+	}
+
+	buf := bufPool.Get().(*bytes.Buffer)
+	defer releaseBuf(buf)
+	if merr := api.Marshal(buf, ct, tval); merr != nil {
+		panic(fmt.Sprintf("error marshaling response %+v for %s %s %d: %s\n", tval, ctx.Operation().Method, ctx.Operation().Path, status, merr.Error()))
+	}
+
+	timings.since(ServerTimingSerialization, start)
+	ctx.SetHeader("Content-Length", strconv.Itoa(buf.Len()))
+	ctx.SetStatus(status)
+	ctx.BodyWriter().Write(buf.Bytes())
+}
+
 func parseArrElement[T any](values []string, parse func(string) (T, error)) ([]T, error) {
 	result := make([]T, 0, len(values))
 
@@ -454,6 +1093,40 @@ func parseArrElement[T any](values []string, parse func(string) (T, error)) ([]T
 	return result, nil
 }
 
+// RESTDefaultStatus is a `Config.DefaultStatus` function which infers
+// 201 Created for `POST` operations with a response body and 204 No Content
+// for any operation without a response body, e.g. most `DELETE` operations.
+// Otherwise, 200 OK is used.
+//
+//	config := huma.DefaultConfig("My API", "1.0.0")
+//	config.DefaultStatus = huma.RESTDefaultStatus
+func RESTDefaultStatus(op *Operation, hasBody bool) int {
+	if !hasBody {
+		return http.StatusNoContent
+	}
+	if op.Method == http.MethodPost {
+		return http.StatusCreated
+	}
+	return http.StatusOK
+}
+
+type operationKey struct{}
+
+// OperationFromContext returns the operation being handled for the current
+// request, or nil if there is none. This lets logging, metrics, and
+// authorization code make per-operation decisions, e.g. using the operation
+// ID, tags, or `Metadata`.
+//
+//	func handler(ctx context.Context, input *MyInput) (*MyOutput, error) {
+//		op := huma.OperationFromContext(ctx)
+//		log.Printf("handling %s", op.OperationID)
+//		// ...
+//	}
+func OperationFromContext(ctx context.Context) *Operation {
+	op, _ := ctx.Value(operationKey{}).(*Operation)
+	return op
+}
+
 // Register an operation handler for an API. The handler must be a function that
 // takes a context and a pointer to the input struct and returns a pointer to the
 // output struct and an error. The input struct must be a struct with fields
@@ -476,14 +1149,26 @@ func parseArrElement[T any](values []string, parse func(string) (T, error)) ([]T
 //		return resp, nil
 //	})
 func Register[I, O any](api API, op Operation, handler func(context.Context, *I) (*O, error)) {
+	inputType := reflect.TypeOf((*I)(nil)).Elem()
+	outputType := reflect.TypeOf((*O)(nil)).Elem()
+	register(api, op, inputType, outputType, func(ctx context.Context, input any) (any, error) {
+		return handler(ctx, input.(*I))
+	})
+}
+
+// register is the non-generic implementation of `Register`. The handler is
+// passed a pointer to a new instance of `inputType` and must return a pointer
+// to an instance of `outputType` (or a nil pointer) and an error.
+func register(api API, op Operation, inputType, outputType reflect.Type, handler func(context.Context, any) (any, error)) {
 	oapi := api.OpenAPI()
 	registry := oapi.Components.Schemas
 
 	if op.Method == "" || op.Path == "" {
 		panic("method and path must be specified in operation")
 	}
+	checkPathWildcard(op.Path)
+	applyTagDefaults(oapi, &op)
 
-	inputType := reflect.TypeOf((*I)(nil)).Elem()
 	if inputType.Kind() != reflect.Struct {
 		panic("input must be a struct")
 	}
@@ -514,15 +1199,6 @@ func Register[I, O any](api API, op Operation, handler func(context.Context, *I)
 			}
 		}
 
-		if op.BodyReadTimeout == 0 {
-			// 5 second default
-			op.BodyReadTimeout = 5 * time.Second
-		}
-
-		if op.MaxBodyBytes == 0 {
-			// 1 MB default
-			op.MaxBodyBytes = 1024 * 1024
-		}
 	}
 	rawBodyIndex := -1
 	if f, ok := inputType.FieldByName("RawBody"); ok {
@@ -547,99 +1223,212 @@ func Register[I, O any](api API, op Operation, handler func(context.Context, *I)
 		}
 	}
 
+	if inputBodyIndex != -1 || rawBodyIndex != -1 {
+		if op.BodyReadTimeout == 0 {
+			// 5 second default
+			op.BodyReadTimeout = 5 * time.Second
+		}
+
+		if op.MaxBodyBytes == 0 {
+			// Use the API-wide default, falling back to 1 MB.
+			op.MaxBodyBytes = maxBodyBytes(api)
+		}
+	}
+
+	var requestTransformers []RequestTransformer
+	if inputBodyIndex != -1 {
+		requestTransformers = append(requestTransformers, configOf(api).RequestTransformers...)
+		requestTransformers = append(requestTransformers, op.RequestTransformers...)
+	}
+
 	var inSchema *Schema
 	if op.RequestBody != nil && op.RequestBody.Content != nil && op.RequestBody.Content["application/json"] != nil && op.RequestBody.Content["application/json"].Schema != nil {
 		inSchema = op.RequestBody.Content["application/json"].Schema
 	}
 
+	// Bodies which may contain sensitive fields are never echoed back in
+	// parse errors, since they cannot be parsed to redact those fields.
+	sensitiveBody := false
+	if op.RequestBody != nil {
+		for _, mt := range op.RequestBody.Content {
+			if mt != nil && containsSensitive(registry, mt.Schema, map[*Schema]bool{}) {
+				sensitiveBody = true
+			}
+		}
+	}
+
 	resolvers := findResolvers(resolverType, inputType)
+	embeddedPointers := findEmbeddedPointers(inputType)
 	defaults := findDefaults(inputType)
+	deprecated := findDeprecated(inputType)
+	onDeprecated := configOf(api).OnDeprecatedUsage
+	locales := configOf(api).Locales
+	decoders := configOf(api).ContentDecoders
 
 	if op.Responses == nil {
 		op.Responses = map[string]*Response{}
 	}
-	outputType := reflect.TypeOf((*O)(nil)).Elem()
 	if outputType.Kind() != reflect.Struct {
 		panic("output must be a struct")
 	}
 
 	outStatusIndex := -1
+	var outStatuses []int
 	if f, ok := outputType.FieldByName("Status"); ok {
 		outStatusIndex = f.Index[0]
 		if f.Type.Kind() != reflect.Int {
 			panic("status field must be an int")
 		}
-		// TODO: enum tag?
-		// TODO: register each of the possible responses with the right model
-		//       and headers down below.
+		if e := f.Tag.Get("enum"); e != "" {
+			// Document each of the possible responses using the same model and
+			// headers as the default response.
+			for _, v := range strings.Split(e, ",") {
+				code, err := strconv.Atoi(strings.TrimSpace(v))
+				if err != nil {
+					panic("status enum values must be integers")
+				}
+				outStatuses = append(outStatuses, code)
+			}
+		}
 	}
 	outHeaders := findHeaders(outputType)
 	outBodyIndex := -1
 	outBodyFunc := false
+	outBodyDownload := false
+	outBodyContentType := ""
+	var outSchema *Schema
 	if f, ok := outputType.FieldByName("Body"); ok {
 		outBodyIndex = f.Index[0]
-		if f.Type.Kind() == reflect.Func {
+		if deref(f.Type) == fileDownloadType {
+			outBodyDownload = true
+			outBodyContentType = "application/octet-stream"
+			if c := f.Tag.Get("contentType"); c != "" {
+				outBodyContentType = c
+			}
+		} else if f.Type.Kind() == reflect.Func {
 			outBodyFunc = true
 
-			if f.Type != bodyCallbackType {
-				panic("body field must be a function with signature func(huma.Context)")
+			if f.Type != bodyCallbackType && f.Type != streamBodyType {
+				panic("body field must be a function with signature func(huma.Context) or a huma.StreamBody")
 			}
+		} else {
+			outSchema = SchemaFromField(registry, f, getHint(outputType, f.Name, op.OperationID+"Response"))
 		}
-		status := op.DefaultStatus
-		if status == 0 {
-			status = http.StatusOK
+	}
+	if op.DefaultStatus == 0 {
+		if f := configOf(api).DefaultStatus; f != nil {
+			op.DefaultStatus = f(&op, outBodyIndex != -1)
+		}
+	}
+	if op.DefaultStatus == 0 {
+		if outBodyIndex != -1 {
+			op.DefaultStatus = http.StatusOK
+		} else {
+			op.DefaultStatus = http.StatusNoContent
+		}
+	}
+	if !slicesContains(outStatuses, op.DefaultStatus) {
+		outStatuses = append([]int{op.DefaultStatus}, outStatuses...)
+	}
+	outHeaderSchemas := make([]*Schema, len(outHeaders.Paths))
+	defaultStatusStr := strconv.Itoa(op.DefaultStatus)
+	for i, entry := range outHeaders.Paths {
+		// We need to generate the schema from the field to get validation info
+		// like min/max and enums. Useful to let the client know possible values.
+		v := entry.Value
+		if ft := deref(v.Field.Type); ft == cookieType || (ft.Kind() == reflect.Slice && deref(ft.Elem()) == cookieType) {
+			// Cookies are sent as strings, one header per cookie.
+			outHeaderSchemas[i] = &Schema{Type: TypeString}
+			continue
 		}
+		outHeaderSchemas[i] = SchemaFromField(registry, v.Field, getHint(outputType, v.Field.Name, op.OperationID+defaultStatusStr+v.Name))
+	}
+	for _, status := range outStatuses {
 		statusStr := strconv.Itoa(status)
 		if op.Responses[statusStr] == nil {
 			op.Responses[statusStr] = &Response{}
 		}
-		if op.Responses[statusStr].Description == "" {
-			op.Responses[statusStr].Description = http.StatusText(status)
+		resp := op.Responses[statusStr]
+		if resp.Description == "" {
+			resp.Description = http.StatusText(status)
 		}
-		if op.Responses[statusStr].Headers == nil {
-			op.Responses[statusStr].Headers = map[string]*Param{}
+		if outBodyIndex != -1 && resp.Headers == nil {
+			resp.Headers = map[string]*Param{}
 		}
-		if !outBodyFunc {
-			outSchema := SchemaFromField(registry, f, getHint(outputType, f.Name, op.OperationID+"Response"))
-			if op.Responses[statusStr].Content == nil {
-				op.Responses[statusStr].Content = map[string]*MediaType{}
+		if outSchema != nil {
+			if resp.Content == nil {
+				resp.Content = map[string]*MediaType{}
 			}
-			if _, ok := op.Responses[statusStr].Content["application/json"]; !ok {
-				op.Responses[statusStr].Content["application/json"] = &MediaType{}
+			if _, ok := resp.Content["application/json"]; !ok {
+				resp.Content["application/json"] = &MediaType{}
 			}
-			if op.Responses[statusStr].Content["application/json"].Schema == nil {
-				op.Responses[statusStr].Content["application/json"].Schema = outSchema
+			if resp.Content["application/json"].Schema == nil {
+				resp.Content["application/json"].Schema = outSchema
 			}
 		}
-	}
-	if op.DefaultStatus == 0 {
-		if outBodyIndex != -1 {
-			op.DefaultStatus = http.StatusOK
-		} else {
-			op.DefaultStatus = http.StatusNoContent
+		if outBodyDownload && status < 300 {
+			if resp.Content == nil {
+				resp.Content = map[string]*MediaType{
+					outBodyContentType: {Schema: &Schema{Type: TypeString, Format: "binary"}},
+				}
+			}
+			if resp.Headers["Content-Disposition"] == nil {
+				resp.Headers["Content-Disposition"] = &Header{
+					Description: "Suggested filename for saving the file.",
+					Schema:      &Schema{Type: TypeString},
+				}
+			}
 		}
-	}
-	defaultStatusStr := strconv.Itoa(op.DefaultStatus)
-	if op.Responses[defaultStatusStr] == nil {
-		op.Responses[defaultStatusStr] = &Response{
-			Description: http.StatusText(op.DefaultStatus),
+		for i, entry := range outHeaders.Paths {
+			// Document the header's name and type.
+			if resp.Headers == nil {
+				resp.Headers = map[string]*Param{}
+			}
+			resp.Headers[entry.Value.Name] = &Header{
+				Schema: outHeaderSchemas[i],
+			}
+		}
+		if op.Cache != nil && status < 400 {
+			op.Cache.document(resp)
 		}
 	}
-	for _, entry := range outHeaders.Paths {
-		// Document the header's name and type.
-		if op.Responses[defaultStatusStr].Headers == nil {
-			op.Responses[defaultStatusStr].Headers = map[string]*Param{}
+
+	if op.ResponseStrategy == ResponseDefault {
+		op.ResponseStrategy = configOf(api).ResponseStrategy
+	}
+
+	if op.ValidationErrorStatus == 0 {
+		// Use the API-wide default, falling back to 422.
+		op.ValidationErrorStatus = configOf(api).ValidationErrorStatus
+		if op.ValidationErrorStatus == 0 {
+			op.ValidationErrorStatus = http.StatusUnprocessableEntity
 		}
-		v := entry.Value
-		op.Responses[defaultStatusStr].Headers[v.Name] = &Header{
-			// We need to generate the schema from the field to get validation info
-			// like min/max and enums. Useful to let the client know possible values.
-			Schema: SchemaFromField(registry, v.Field, getHint(outputType, v.Field.Name, op.OperationID+defaultStatusStr+v.Name)),
+	}
+
+	if op.ValidationMode == ValidationDefault {
+		op.ValidationMode = configOf(api).ValidationMode
+	}
+	audit := op.ValidationMode == ValidationAudit
+	onAudit := configOf(api).OnValidationAudit
+
+	var lenient map[string]bool
+	if len(op.LenientValidation) > 0 {
+		lenient = make(map[string]bool, len(op.LenientValidation))
+		for _, keyword := range op.LenientValidation {
+			lenient[keyword] = true
 		}
 	}
 
+	addErrorTypeCodes(&op)
+
 	if len(op.Errors) > 0 && (len(inputParams.Paths) > 0 || inputBodyIndex >= -1) {
-		op.Errors = append(op.Errors, http.StatusUnprocessableEntity)
+		op.Errors = append(op.Errors, op.ValidationErrorStatus)
+	}
+	if len(op.Errors) > 0 && op.Timeout > 0 {
+		op.Errors = append(op.Errors, http.StatusGatewayTimeout)
+	}
+	if len(op.Errors) > 0 && op.MaxConcurrent > 0 {
+		op.Errors = append(op.Errors, http.StatusTooManyRequests)
 	}
 	if len(op.Errors) > 0 {
 		op.Errors = append(op.Errors, http.StatusInternalServerError)
@@ -653,14 +1442,11 @@ func Register[I, O any](api API, op Operation, handler func(context.Context, *I)
 	errType := reflect.TypeOf(exampleErr)
 	errSchema := registry.Schema(errType, true, getHint(errType, "", "Error"))
 	for _, code := range op.Errors {
-		op.Responses[strconv.Itoa(code)] = &Response{
-			Description: http.StatusText(code),
-			Content: map[string]*MediaType{
-				errContentType: {
-					Schema: errSchema,
-				},
-			},
+		if v, ok := op.ErrorTypes[code]; ok {
+			op.Responses[strconv.Itoa(code)] = errorTypeResponse(registry, &op, code, v)
+			continue
 		}
+		op.Responses[strconv.Itoa(code)] = errorResponse(code, errContentType, errSchema)
 	}
 	if len(op.Responses) <= 1 && len(op.Errors) == 0 {
 		// No errors are defined, so set a default response.
@@ -674,293 +1460,158 @@ func Register[I, O any](api API, op Operation, handler func(context.Context, *I)
 		}
 	}
 
+	autoETagEnabled := configOf(api).AutoETag
+	if autoETagEnabled {
+		documentAutoETag(&op)
+	}
+
 	if !op.Hidden {
 		oapi.AddOperation(&op)
 	}
 
-	a := api.Adapter()
+	limiter := newConcurrencyLimiter(&op)
+	strict := newStrictHeaders(api, &op, inputParams)
+
+	handle(api, &op, func(ctx Context) {
+		var held handlerResources
+		defer held.release()
 
-	a.Handle(&op, api.Middlewares().Handler(func(ctx Context) {
-		var input I
+		if limiter != nil {
+			if !limiter.acquire(ctx.Context()) {
+				WriteErr(api, ctx, http.StatusTooManyRequests, "too many concurrent requests")
+				return
+			}
+			held.limiter = limiter
+		}
+
+		input := reflect.New(inputType)
+		if embeddedPointers != nil {
+			allocEmbeddedPointers(input, embeddedPointers)
+		}
 
 		// Get the validation dependencies from the shared pool.
 		deps := validatePool.Get().(*validateDeps)
 		defer func() {
 			deps.pb.Reset()
 			deps.res.Reset()
+			deps.lr = io.LimitedReader{}
 			validatePool.Put(deps)
 		}()
 		pb := deps.pb
 		res := deps.res
 
-		errStatus := http.StatusUnprocessableEntity
+		timings := getServerTimings(ctx.Context())
+		var start time.Time
+		if timings != nil {
+			start = time.Now()
+		}
+
+		errStatus := op.ValidationErrorStatus
 
-		v := reflect.ValueOf(&input).Elem()
+		// fatal tracks errors which leave the input unusable, so the request
+		// must be rejected even when only auditing validation failures.
+		fatal := false
+
+		// warnings are errors for lenient schema keywords, which are sent to
+		// the client rather than rejecting the request.
+		var warnings []error
+
+		v := input.Elem()
+		var cookies map[string]*http.Cookie
+		var locale string
 		inputParams.Every(v, func(f reflect.Value, p *paramFieldInfo) {
 			var value string
+			var cookie *http.Cookie
 			switch p.Loc {
 			case "path":
 				value = ctx.Param(p.Name)
 			case "query":
-				value = ctx.Query(p.Name)
+				if p.Multi {
+					// Repeated params are combined into the comma-separated form.
+					if values := ctx.GetMultiQuery(p.Name); len(values) == 1 {
+						value = values[0]
+					} else if len(values) > 1 {
+						value = strings.Join(values, ",")
+					}
+				} else {
+					value = ctx.Query(p.Name)
+				}
 			case "header":
-				value = ctx.Header(p.Name)
+				if p.Multi {
+					value = headerList(ctx, p.Name)
+				} else {
+					value = ctx.Header(p.Name)
+				}
+			case "cookie":
+				if cookies == nil {
+					cookies = readCookies(ctx)
+				}
+				if c, ok := cookies[p.Name]; ok {
+					cookie = c
+					value = c.Value
+				}
+			}
+
+			if p.locale {
+				// Locales are negotiated rather than validated, so they always
+				// get a value even if the client sent none.
+				l := f.Addr().Interface().(*Locale)
+				l.UnmarshalText([]byte(value))
+				l.negotiate(locales)
+				if locale == "" {
+					locale = l.Tag
+				}
+				if len(locales) > 0 && p.Loc == "header" {
+					ctx.AppendHeader("Vary", p.Name)
+				}
+				return
 			}
 
 			pb.Reset()
 			pb.Push(p.Loc)
 			pb.Push(p.Name)
 
+			if p.style != "" && value != "" {
+				unstyled, msg := p.unstyle(value)
+				if msg != "" {
+					if p.Schema.IsSensitive() {
+						res.Add(pb, Redacted, msg)
+					} else {
+						res.Add(pb, value, msg)
+					}
+					fatal = true
+					return
+				}
+				value = unstyled
+			}
+
+			if p.Deprecated && value != "" {
+				ctx.AppendHeader("Warning", p.warning)
+				if onDeprecated != nil {
+					onDeprecated(ctx, p.location)
+				}
+			}
+
 			if value == "" && p.Default != "" {
 				value = p.Default
 			}
 
 			if p.Required && value == "" {
 				// Path params are always required.
-				res.Add(pb, "", "required "+p.Loc+" parameter is missing")
+				res.addKeyword(pb, "", "required", p.msgRequired)
 				return
 			}
 
 			if value != "" {
-				var pv any
-
-				switch p.Type.Kind() {
-				case reflect.String:
-					f.SetString(value)
-					pv = value
-				case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-					v, err := strconv.ParseInt(value, 10, 64)
-					if err != nil {
-						res.Add(pb, value, "invalid integer")
-						return
-					}
-					f.SetInt(v)
-					pv = v
-				case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-					v, err := strconv.ParseUint(value, 10, 64)
-					if err != nil {
-						res.Add(pb, value, "invalid integer")
-						return
-					}
-					f.SetUint(v)
-					pv = v
-				case reflect.Float32, reflect.Float64:
-					v, err := strconv.ParseFloat(value, 64)
-					if err != nil {
-						res.Add(pb, value, "invalid float")
-						return
-					}
-					f.SetFloat(v)
-					pv = v
-				case reflect.Bool:
-					v, err := strconv.ParseBool(value)
-					if err != nil {
-						res.Add(pb, value, "invalid boolean")
-						return
-					}
-					f.SetBool(v)
-					pv = v
-				default:
-					if f.Type().Kind() == reflect.Slice {
-						switch f.Type().Elem().Kind() {
-
-						case reflect.String:
-							values := strings.Split(value, ",")
-							f.Set(reflect.ValueOf(values))
-							pv = values
-
-						case reflect.Int:
-							values := strings.Split(value, ",")
-							vs, err := parseArrElement(values, func(s string) (int, error) {
-								val, err := strconv.ParseInt(s, 10, strconv.IntSize)
-								if err != nil {
-									return 0, err
-								}
-								return int(val), nil
-							})
-							if err != nil {
-								res.Add(pb, value, "invalid integer")
-								return
-							}
-							f.Set(reflect.ValueOf(vs))
-							pv = vs
-
-						case reflect.Int8:
-							values := strings.Split(value, ",")
-							vs, err := parseArrElement(values, func(s string) (int8, error) {
-								val, err := strconv.ParseInt(s, 10, 8)
-								if err != nil {
-									return 0, err
-								}
-								return int8(val), nil
-							})
-							if err != nil {
-								res.Add(pb, value, "invalid integer")
-								return
-							}
-							f.Set(reflect.ValueOf(vs))
-							pv = vs
-
-						case reflect.Int16:
-							values := strings.Split(value, ",")
-							vs, err := parseArrElement(values, func(s string) (int16, error) {
-								val, err := strconv.ParseInt(s, 10, 16)
-								if err != nil {
-									return 0, err
-								}
-								return int16(val), nil
-							})
-							if err != nil {
-								res.Add(pb, value, "invalid integer")
-								return
-							}
-							f.Set(reflect.ValueOf(vs))
-							pv = vs
-
-						case reflect.Int32:
-							values := strings.Split(value, ",")
-							vs, err := parseArrElement(values, func(s string) (int32, error) {
-								val, err := strconv.ParseInt(s, 10, 32)
-								if err != nil {
-									return 0, err
-								}
-								return int32(val), nil
-							})
-							if err != nil {
-								res.Add(pb, value, "invalid integer")
-								return
-							}
-							f.Set(reflect.ValueOf(vs))
-							pv = vs
-
-						case reflect.Int64:
-							values := strings.Split(value, ",")
-							vs, err := parseArrElement(values, func(s string) (int64, error) {
-								val, err := strconv.ParseInt(s, 10, 64)
-								if err != nil {
-									return 0, err
-								}
-								return int64(val), nil
-							})
-							if err != nil {
-								res.Add(pb, value, "invalid integer")
-								return
-							}
-							f.Set(reflect.ValueOf(vs))
-							pv = vs
-
-						case reflect.Uint:
-							values := strings.Split(value, ",")
-							vs, err := parseArrElement(values, func(s string) (uint, error) {
-								val, err := strconv.ParseUint(s, 10, strconv.IntSize)
-								if err != nil {
-									return 0, err
-								}
-								return uint(val), nil
-							})
-							if err != nil {
-								res.Add(pb, value, "invalid integer")
-								return
-							}
-							f.Set(reflect.ValueOf(vs))
-							pv = vs
-
-						case reflect.Uint16:
-							values := strings.Split(value, ",")
-							vs, err := parseArrElement(values, func(s string) (uint16, error) {
-								val, err := strconv.ParseUint(s, 10, 16)
-								if err != nil {
-									return 0, err
-								}
-								return uint16(val), nil
-							})
-							if err != nil {
-								res.Add(pb, value, "invalid integer")
-								return
-							}
-							f.Set(reflect.ValueOf(vs))
-							pv = vs
-
-						case reflect.Uint32:
-							values := strings.Split(value, ",")
-							vs, err := parseArrElement(values, func(s string) (uint32, error) {
-								val, err := strconv.ParseUint(s, 10, 32)
-								if err != nil {
-									return 0, err
-								}
-								return uint32(val), nil
-							})
-							if err != nil {
-								res.Add(pb, value, "invalid integer")
-								return
-							}
-							f.Set(reflect.ValueOf(vs))
-							pv = vs
-
-						case reflect.Uint64:
-							values := strings.Split(value, ",")
-							vs, err := parseArrElement(values, func(s string) (uint64, error) {
-								val, err := strconv.ParseUint(s, 10, 64)
-								if err != nil {
-									return 0, err
-								}
-								return uint64(val), nil
-							})
-							if err != nil {
-								res.Add(pb, value, "invalid integer")
-								return
-							}
-							f.Set(reflect.ValueOf(vs))
-							pv = vs
-
-						case reflect.Float32:
-							values := strings.Split(value, ",")
-							vs, err := parseArrElement(values, func(s string) (float32, error) {
-								val, err := strconv.ParseFloat(s, 32)
-								if err != nil {
-									return 0, err
-								}
-								return float32(val), nil
-							})
-							if err != nil {
-								res.Add(pb, value, "invalid floating value")
-								return
-							}
-							f.Set(reflect.ValueOf(vs))
-							pv = vs
-
-						case reflect.Float64:
-							values := strings.Split(value, ",")
-							vs, err := parseArrElement(values, func(s string) (float64, error) {
-								val, err := strconv.ParseFloat(s, 64)
-								if err != nil {
-									return 0, err
-								}
-								return float64(val), nil
-							})
-							if err != nil {
-								res.Add(pb, value, "invalid floating value")
-								return
-							}
-							f.Set(reflect.ValueOf(vs))
-							pv = vs
-						}
-						break
-					}
-
-					// Special case: time.Time
-					if f.Type() == timeType {
-						t, err := time.Parse(p.TimeFormat, value)
-						if err != nil {
-							res.Add(pb, value, "invalid date/time for format "+p.TimeFormat)
-							return
-						}
-						f.Set(reflect.ValueOf(t))
-						pv = value
-						break
+				pv, msg := p.parse(f, value, cookie)
+				if msg != "" {
+					if p.Schema.IsSensitive() {
+						res.Add(pb, Redacted, msg)
+					} else {
+						res.Add(pb, value, msg)
 					}
-
-					panic("unsupported param type " + p.Type.String())
+					fatal = true
+					return
 				}
 
 				if !op.SkipValidateParams {
@@ -969,6 +1620,29 @@ func Register[I, O any](api API, op Operation, handler func(context.Context, *I)
 			}
 		})
 
+		if strict != nil {
+			strict.validate(oapi.Components.Schemas, ctx, pb, res)
+		}
+
+		if locale != "" {
+			if len(locales) > 0 {
+				ctx.SetHeader("Content-Language", locale)
+			}
+			ctx = &localeContext{
+				humaContext: ctx,
+				ctx:         context.WithValue(ctx.Context(), localeKey{}, locale),
+			}
+		}
+
+		warnings = takeLenient(res, lenient, warnings)
+		if len(res.Errors) > 0 && (!audit || fatal) && (inputBodyIndex != -1 || rawBodyIndex != -1) && strings.EqualFold(ctx.Header("Expect"), "100-continue") {
+			// The request will be rejected anyway, so respond before reading the
+			// body. The client is then never told to continue, and does not waste
+			// time sending a body which would be ignored.
+			WriteErr(api, ctx, validationStatus(errStatus, res.Errors), "validation failed", res.Errors...)
+			return
+		}
+
 		// Read input body if defined.
 		if inputBodyIndex != -1 || rawBodyIndex != -1 {
 			if op.BodyReadTimeout > 0 {
@@ -978,7 +1652,32 @@ func Register[I, O any](api API, op Operation, handler func(context.Context, *I)
 				ctx.SetReadDeadline(time.Time{})
 			}
 
+			var length int64 = -1
+			if cl := ctx.Header("Content-Length"); cl != "" {
+				if l, err := strconv.ParseInt(cl, 10, 64); err == nil {
+					length = l
+				}
+			}
+
+			if op.MaxBodyBytes > 0 && length > op.MaxBodyBytes {
+				// Reject bodies which are known to be too large before reading.
+				writeBodyTooLarge(api, ctx, op.MaxBodyBytes, length, res.Errors)
+				return
+			}
+
 			buf := bufPool.Get().(*bytes.Buffer)
+			if rawBodyIndex == -1 {
+				defer releaseBuf(buf)
+			} else {
+				// `RawBody` references the buffer without copying it, so the buffer
+				// is only released once the handler has returned.
+				held.buf = buf
+			}
+			if length > 0 && (op.MaxBodyBytes <= 0 || length <= op.MaxBodyBytes) {
+				// Size the buffer up front to avoid copies as it grows. Reading needs
+				// `bytes.MinRead` of spare room to detect the end of the body.
+				buf.Grow(int(length) + bytes.MinRead)
+			}
 			reader := ctx.BodyReader()
 			if reader == nil {
 				reader = bytes.NewReader(nil)
@@ -986,27 +1685,46 @@ func Register[I, O any](api API, op Operation, handler func(context.Context, *I)
 			if closer, ok := reader.(io.Closer); ok {
 				defer closer.Close()
 			}
+			if enc := ctx.Header("Content-Encoding"); enc != "" && len(decoders) > 0 {
+				decoded, closers, err := decodeContent(decoders, enc, reader)
+				if err != nil {
+					status := http.StatusBadRequest
+					if _, ok := err.(errUnsupportedEncoding); ok {
+						status = http.StatusUnsupportedMediaType
+					}
+					WriteErr(api, ctx, status, err.Error(), res.Errors...)
+					return
+				}
+				defer closeAll(closers)
+				// The body size limit below applies to the decompressed body, to
+				// guard against small bodies which decompress to huge ones.
+				reader = decoded
+			}
 			if op.MaxBodyBytes > 0 {
-				reader = io.LimitReader(reader, op.MaxBodyBytes)
+				// Read one extra byte to detect bodies over the limit.
+				deps.lr.R, deps.lr.N = reader, op.MaxBodyBytes+1
+				reader = &deps.lr
 			}
 			count, err := io.Copy(buf, reader)
 			if op.MaxBodyBytes > 0 {
-				if count == op.MaxBodyBytes {
-					buf.Reset()
-					bufPool.Put(buf)
-					WriteErr(api, ctx, http.StatusRequestEntityTooLarge, fmt.Sprintf("request body is too large limit=%d bytes", op.MaxBodyBytes), res.Errors...)
+				if count > op.MaxBodyBytes {
+					writeBodyTooLarge(api, ctx, op.MaxBodyBytes, -1, res.Errors)
 					return
 				}
 			}
 			if err != nil {
-				buf.Reset()
-				bufPool.Put(buf)
-
-				if e, ok := err.(net.Error); ok && e.Timeout() {
+				var ne net.Error
+				if errors.As(err, &ne) && ne.Timeout() {
 					WriteErr(api, ctx, http.StatusRequestTimeout, "request body read timeout", res.Errors...)
 					return
 				}
 
+				var de *contentDecodeError
+				if errors.As(err, &de) {
+					WriteErr(api, ctx, http.StatusBadRequest, de.Error(), res.Errors...)
+					return
+				}
+
 				WriteErr(api, ctx, http.StatusInternalServerError, "cannot read request body", err)
 				return
 			}
@@ -1019,14 +1737,35 @@ func Register[I, O any](api API, op Operation, handler func(context.Context, *I)
 
 			if len(body) == 0 {
 				if op.RequestBody != nil && op.RequestBody.Required {
-					buf.Reset()
-					bufPool.Put(buf)
 					WriteErr(api, ctx, http.StatusBadRequest, "request body is required", res.Errors...)
 					return
 				}
 			} else {
 				parseErrCount := 0
-				if inputBodyIndex != -1 && !op.SkipValidateBody {
+				singlePass := !audit && lenient == nil && inputBodyIndex != -1 && inSchema != nil && !op.SkipValidateBody && len(requestTransformers) == 0 && isJSONContentType(ctx.Header("Content-Type"))
+				if singlePass {
+					// Decode directly into the input struct, validating along the way
+					// to avoid parsing the body twice. Invalid bodies are handled below
+					// instead, so that errors are always reported the same way.
+					pb.Reset()
+					pb.Push("body")
+					count := len(res.Errors)
+					f := v.Field(inputBodyIndex)
+					if err := decodeBody(oapi.Components.Schemas, inSchema, pb, body, f, res); err != nil {
+						res.Errors = res.Errors[:count]
+						f.Set(reflect.Zero(f.Type()))
+						singlePass = false
+					} else {
+						// Set defaults for any fields that were not in the input.
+						defaults.Every(v, func(item reflect.Value, def any) {
+							if item.IsZero() {
+								item.Set(reflect.Indirect(reflect.ValueOf(def)))
+							}
+						})
+					}
+				}
+
+				if !singlePass && inputBodyIndex != -1 && (!op.SkipValidateBody || len(requestTransformers) > 0) {
 					// Validate the input. First, parse the body into []any or map[string]any
 					// or equivalent, which can be easily validated. Then, convert to the
 					// expected struct type to call the handler.
@@ -1039,22 +1778,37 @@ func Register[I, O any](api API, op Operation, handler func(context.Context, *I)
 						res.Errors = append(res.Errors, &ErrorDetail{
 							Location: "body",
 							Message:  err.Error(),
-							Value:    body,
+							Value:    bodyErrorValue(body, sensitiveBody),
 						})
 						parseErrCount++
-					} else {
+						fatal = true
+					} else if len(requestTransformers) > 0 {
+						// Rewrite the parsed body, then re-encode it so that validation
+						// and decoding into the input struct see the transformed value.
+						if parsed, body, err = transformRequestBody(api, ctx, requestTransformers, parsed); err != nil {
+							errStatus = http.StatusBadRequest
+							res.Errors = append(res.Errors, &ErrorDetail{
+								Location: "body",
+								Message:  err.Error(),
+							})
+							parseErrCount++
+							fatal = true
+						}
+					}
+
+					if parseErrCount == 0 && !op.SkipValidateBody {
 						pb.Reset()
 						pb.Push("body")
 						count := len(res.Errors)
 						Validate(oapi.Components.Schemas, inSchema, pb, ModeWriteToServer, parsed, res)
 						parseErrCount = len(res.Errors) - count
 						if parseErrCount > 0 {
-							errStatus = http.StatusUnprocessableEntity
+							errStatus = op.ValidationErrorStatus
 						}
 					}
 				}
 
-				if inputBodyIndex != -1 {
+				if !singlePass && inputBodyIndex != -1 {
 					// We need to get the body into the correct type now that it has been
 					// validated. Benchmarks on Go 1.20 show that using `json.Unmarshal` a
 					// second time is faster than `mapstructure.Decode` or any of the other
@@ -1062,12 +1816,13 @@ func Register[I, O any](api API, op Operation, handler func(context.Context, *I)
 					// JSON payloads with lots of strings.
 					f := v.Field(inputBodyIndex)
 					if err := api.Unmarshal(ctx.Header("Content-Type"), body, f.Addr().Interface()); err != nil {
+						fatal = true
 						if parseErrCount == 0 {
 							// Hmm, this should have worked... validator missed something?
 							res.Errors = append(res.Errors, &ErrorDetail{
 								Location: "body",
 								Message:  err.Error(),
-								Value:    string(body),
+								Value:    bodyErrorValue(string(body), sensitiveBody),
 							})
 						}
 					} else {
@@ -1079,106 +1834,194 @@ func Register[I, O any](api API, op Operation, handler func(context.Context, *I)
 						})
 					}
 				}
-
-				buf.Reset()
-				bufPool.Put(buf)
 			}
 		}
 
-		resolvers.EveryPB(pb, v, func(item reflect.Value, _ bool) {
-			if resolver, ok := item.Addr().Interface().(Resolver); ok {
-				if errs := resolver.Resolve(ctx); len(errs) > 0 {
-					res.Errors = append(res.Errors, errs...)
+		if len(deprecated.Paths) > 0 {
+			deprecated.EveryPB(pb, v, func(item reflect.Value, d *deprecatedField) {
+				if !d.used(item) {
+					return
 				}
-			} else if resolver, ok := item.Addr().Interface().(ResolverWithPath); ok {
-				if errs := resolver.Resolve(ctx, pb); len(errs) > 0 {
-					res.Errors = append(res.Errors, errs...)
+				location := pb.String()
+				ctx.AppendHeader("Warning", deprecatedWarning(location))
+				if onDeprecated != nil {
+					onDeprecated(ctx, location)
 				}
-			} else {
-				panic("matched resolver cannot be run, please file a bug")
+			})
+		}
+
+		if len(resolvers.Paths) > 0 {
+			run := resolverRun{ctx: ctx, pb: pb, res: res}
+			run.all(resolvers, v, true)
+			if run.failed {
+				fatal = true
 			}
-		})
+		}
 
-		if len(res.Errors) > 0 {
-			for i := len(res.Errors) - 1; i >= 0; i-- {
-				// If there are errors, and they provide a status, then update the
-				// response status code to match. Otherwise, use the default status
-				// code is used. Since these run in order, the last error code wins.
-				if s, ok := res.Errors[i].(StatusError); ok {
-					errStatus = s.GetStatus()
-					break
-				}
+		if timings != nil {
+			timings.since(ServerTimingValidation, start)
+			start = time.Now()
+		}
+
+		warnings = takeLenient(res, lenient, warnings)
+		if len(warnings) > 0 {
+			for _, w := range warnings {
+				ctx.AppendHeader("Warning", validationWarning(w))
+			}
+			ctx = &validationWarningsContext{
+				humaContext: ctx,
+				ctx:         context.WithValue(ctx.Context(), validationWarningsKey{}, warnings),
 			}
-			WriteErr(api, ctx, errStatus, "validation failed", res.Errors...)
+		}
+
+		if len(res.Errors) > 0 && audit && !fatal {
+			if onAudit != nil {
+				onAudit(ctx, copyErrors(res.Errors))
+			}
+		} else if len(res.Errors) > 0 {
+			WriteErr(api, ctx, validationStatus(errStatus, res.Errors), "validation failed", res.Errors...)
 			return
 		}
 
-		output, err := handler(ctx.Context(), &input)
+		if op.FullDuplex {
+			// Not all adapters support this, in which case the handler can still
+			// read the body, but possibly not once it has started to respond.
+			FullDuplex(ctx)
+		}
+
+		hctx := context.WithValue(ctx.Context(), operationKey{}, &op)
+		var output any
+		var err error
+		if op.Timeout > 0 {
+			// The handler may keep running after timing out, so it releases the
+			// resources it holds once it actually returns.
+			h := held
+			held = handlerResources{}
+			output, err = callWithTimeout(hctx, op.Timeout, input.Interface(), handler, h.release)
+		} else {
+			output, err = handler(hctx, input.Interface())
+		}
+		if timings != nil {
+			timings.since(ServerTimingHandler, start)
+		}
 		if err != nil {
 			status := http.StatusInternalServerError
-			if se, ok := err.(StatusError); ok {
+			writeErrorHeaders(ctx, err)
+
+			var se StatusError
+			if errors.As(err, &se) {
 				status = se.GetStatus()
+				err = se
 			} else {
-				err = NewError(http.StatusInternalServerError, err.Error())
+				if mapped := mappedErrorStatus(err); mapped != 0 {
+					status = mapped
+				}
+				err = NewError(status, err.Error())
 			}
 
-			ct, _ := api.Negotiate(ctx.Header("Accept"))
-			if ctf, ok := err.(ContentTypeFilter); ok {
-				ct = ctf.ContentType(ct)
-			}
+			setErrorRequestID(ctx, err)
+			setErrorLocale(ctx, err)
 
+			ct, _ := negotiateErrorContentType(api, ctx, err)
 			ctx.SetHeader("Content-Type", ct)
 			transformAndWrite(api, ctx, status, ct, err)
 			return
 		}
 
+		vo := reflect.ValueOf(output).Elem()
+		status := op.DefaultStatus
+		if outStatusIndex != -1 && vo.IsValid() {
+			if s := int(vo.Field(outStatusIndex).Int()); s != 0 {
+				status = s
+			}
+		} else if sc, ok := output.(StatusCoder); ok && vo.IsValid() {
+			if s := sc.GetStatus(); s != 0 {
+				status = s
+			}
+		}
+
+		// Set the caching policy first so output headers can override it.
+		if op.Cache != nil && status < 400 {
+			op.Cache.writeHeaders(ctx)
+		}
+
 		// Serialize output headers
 		ct := ""
-		vo := reflect.ValueOf(output).Elem()
+		etag := ""
 		outHeaders.Every(vo, func(f reflect.Value, info *headerInfo) {
-			switch f.Kind() {
-			case reflect.String:
-				if f.String() == "" {
-					// Don't set empty headers.
-					return
-				}
-				ctx.SetHeader(info.Name, f.String())
-				if info.Name == "Content-Type" {
-					ct = f.String()
-				}
-			case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-				ctx.SetHeader(info.Name, strconv.FormatInt(f.Int(), 10))
-			case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-				ctx.SetHeader(info.Name, strconv.FormatUint(f.Uint(), 10))
-			case reflect.Float32, reflect.Float64:
-				ctx.SetHeader(info.Name, strconv.FormatFloat(f.Float(), 'f', -1, 64))
-			case reflect.Bool:
-				ctx.SetHeader(info.Name, strconv.FormatBool(f.Bool()))
-			default:
-				if f.Type() == timeType && !f.Interface().(time.Time).IsZero() {
-					ctx.SetHeader(info.Name, f.Interface().(time.Time).Format(info.TimeFormat))
-					return
+			if f.Kind() == reflect.Slice {
+				// Multi-value headers like `Set-Cookie` or `Link` append each item
+				// rather than overwriting the previous value.
+				for i := 0; i < f.Len(); i++ {
+					item := reflect.Indirect(f.Index(i))
+					if !item.IsValid() {
+						continue
+					}
+					if value, ok := formatHeader(item, info.TimeFormat); ok {
+						ctx.AppendHeader(info.Name, value)
+					}
 				}
+				return
+			}
 
-				ctx.SetHeader(info.Name, fmt.Sprintf("%v", f.Interface()))
+			value, ok := formatHeader(f, info.TimeFormat)
+			if !ok {
+				// Don't set empty headers.
+				return
+			}
+			ctx.SetHeader(info.Name, value)
+			switch info.Name {
+			case "Content-Type":
+				ct = value
+			case "ETag":
+				etag = value
 			}
 		})
 
-		status := op.DefaultStatus
-		if outStatusIndex != -1 {
-			status = int(vo.Field(outStatusIndex).Int())
-		}
-
 		if outBodyIndex != -1 {
 			// Serialize output body
 			body := vo.Field(outBodyIndex).Interface()
 
+			if outBodyDownload {
+				switch d := body.(type) {
+				case FileDownload:
+					d.write(ctx, status, ct)
+				case *FileDownload:
+					if d != nil {
+						d.write(ctx, status, ct)
+					} else {
+						ctx.SetStatus(status)
+					}
+				}
+				return
+			}
+
 			if outBodyFunc {
+				if sb, ok := body.(StreamBody); ok {
+					ctx.SetStatus(status)
+					if sb != nil {
+						writeStream(ctx, sb)
+					}
+					return
+				}
 				body.(func(Context))(ctx)
 				return
 			}
 
+			autoETag := autoETagEnabled && autoETagApplies(ctx, status)
+			if autoETag && etag == "" {
+				if e, ok := output.(ETagger); ok {
+					etag = e.ETag()
+				} else if e, ok := body.(ETagger); ok {
+					etag = e.ETag()
+				}
+			}
+
 			if b, ok := body.([]byte); ok {
+				if autoETag {
+					writeWithETag(api, ctx, etag, status, ct, b)
+					return
+				}
 				ctx.SetStatus(status)
 				ctx.BodyWriter().Write(b)
 				return
@@ -1199,44 +2042,28 @@ func Register[I, O any](api API, op Operation, handler func(context.Context, *I)
 				ctx.SetHeader("Content-Type", ct)
 			}
 
+			if autoETag {
+				writeWithETag(api, ctx, etag, status, ct, body)
+				return
+			}
 			transformAndWrite(api, ctx, status, ct, body)
 		} else {
 			ctx.SetStatus(status)
 		}
-	}))
+	})
 }
 
-// AutoRegister auto-detects operation registration methods and registers them
-// with the given API. Any method named `Register...` will be called and
-// passed the API as the only argument. Since registration happens at
-// service startup, no errors are returned and methods should panic on error.
-//
-//	type ItemsHandler struct {}
-//
-//	func (s *ItemsHandler) RegisterListItems(api API) {
-//		huma.Register(api, huma.Operation{
-//			OperationID: "ListItems",
-//			Method: http.MethodGet,
-//			Path: "/items",
-//		}, s.ListItems)
-//	}
-//
-//	func main() {
-//		router := chi.NewMux()
-//		config := huma.DefaultConfig("My Service", "1.0.0")
-//		api := huma.NewExampleAPI(router, config)
-//
-//		itemsHandler := &ItemsHandler{}
-//		huma.AutoRegister(api, itemsHandler)
-//	}
-func AutoRegister(api API, server any) {
-	args := []reflect.Value{reflect.ValueOf(server), reflect.ValueOf(api)}
-
-	t := reflect.TypeOf(server)
-	for i := 0; i < t.NumMethod(); i++ {
-		m := t.Method(i)
-		if strings.HasPrefix(m.Name, "Register") && len(m.Name) > 8 {
-			m.Func.Call(args)
+// handle registers the operation handler with the API's adapter, wrapped by
+// the API's middleware and built-in request handling like panic recovery.
+func handle(api API, op *Operation, handler func(ctx Context)) {
+	handler = requestIDs(api, routes(op, clientInfo(api, compressResponses(api, op, serverTiming(api, validateResponses(api, op, recoverPanics(api, api.Middlewares().Handler(tagMiddlewares(api.OpenAPI(), op).Handler(handler)))))))))
+	if op.OperationID != "" {
+		standalone := handler
+		if cors, ok := api.Adapter().(*corsAdapter); ok {
+			// Standalone handlers bypass the adapter, so need its CORS headers.
+			standalone = cors.wrap(handler)
 		}
+		addOperationHandler(api.OpenAPI(), op, standalone)
 	}
+	api.Adapter().Handle(op, handler)
 }
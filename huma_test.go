@@ -7,6 +7,9 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"net/netip"
+	"reflect"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
@@ -141,6 +144,24 @@ func TestFeatures(t *testing.T) {
 				"date":   "Mon, 01 Jan 2023 12:00:00 GMT",
 			},
 		},
+		{
+			Name: "params-repeated",
+			Register: func(t *testing.T, api huma.API) {
+				huma.Register(api, huma.Operation{
+					Method: http.MethodGet,
+					Path:   "/test-params",
+				}, func(ctx context.Context, input *struct {
+					QueryStrings []string `query:"strings"`
+					QueryInts    []int    `query:"ints"`
+				}) (*struct{}, error) {
+					assert.Equal(t, []string{"foo", "bar", "baz"}, input.QueryStrings)
+					assert.Equal(t, []int{1, 2}, input.QueryInts)
+					return nil, nil
+				})
+			},
+			Method: http.MethodGet,
+			URL:    "/test-params?strings=foo&ints=1&strings=bar,baz&ints=2",
+		},
 		{
 			Name: "params-error",
 			Register: func(t *testing.T, api huma.API) {
@@ -219,6 +240,65 @@ func TestFeatures(t *testing.T) {
 			// Headers: map[string]string{"Content-Type": "application/json"},
 			Body: `{"name":"foo"}`,
 		},
+		{
+			Name: "request-body-transform",
+			Register: func(t *testing.T, api huma.API) {
+				huma.Register(api, huma.Operation{
+					Method: http.MethodPut,
+					Path:   "/body",
+					RequestTransformers: []huma.RequestTransformer{
+						func(ctx huma.Context, v any) (any, error) {
+							// Alias the legacy `title` field to `name`.
+							if m, ok := v.(map[string]any); ok {
+								if title, ok := m["title"]; ok {
+									m["name"] = title
+									delete(m, "title")
+								}
+							}
+							return v, nil
+						},
+					},
+				}, func(ctx context.Context, input *struct {
+					Body struct {
+						Name string `json:"name" minLength:"1"`
+					}
+				}) (*struct{}, error) {
+					assert.Equal(t, "foo", input.Body.Name)
+					return nil, nil
+				})
+			},
+			Method: http.MethodPut,
+			URL:    "/body",
+			Body:   `{"title":"foo"}`,
+		},
+		{
+			Name: "request-body-transform-error",
+			Register: func(t *testing.T, api huma.API) {
+				huma.Register(api, huma.Operation{
+					Method: http.MethodPut,
+					Path:   "/body",
+					RequestTransformers: []huma.RequestTransformer{
+						func(ctx huma.Context, v any) (any, error) {
+							return nil, fmt.Errorf("unsupported legacy body")
+						},
+					},
+				}, func(ctx context.Context, input *struct {
+					Body struct {
+						Name string `json:"name"`
+					}
+				}) (*struct{}, error) {
+					t.Fatal("handler should not be called")
+					return nil, nil
+				})
+			},
+			Method: http.MethodPut,
+			URL:    "/body",
+			Body:   `{"name":"foo"}`,
+			Assert: func(t *testing.T, resp *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, resp.Code)
+				assert.Contains(t, resp.Body.String(), "unsupported legacy body")
+			},
+		},
 		{
 			Name: "request-body-defaults",
 			Register: func(t *testing.T, api huma.API) {
@@ -313,6 +393,105 @@ func TestFeatures(t *testing.T) {
 			Body:   "foobarbaz",
 			Assert: func(t *testing.T, resp *httptest.ResponseRecorder) {
 				assert.Equal(t, http.StatusRequestEntityTooLarge, resp.Code)
+				assert.Contains(t, resp.Body.String(), "expected at most 1 bytes")
+			},
+		},
+		{
+			Name: "request-body-too-large-content-length",
+			Register: func(t *testing.T, api huma.API) {
+				huma.Register(api, huma.Operation{
+					Method:       http.MethodPut,
+					Path:         "/body",
+					MaxBodyBytes: 5,
+				}, func(ctx context.Context, input *struct {
+					RawBody []byte
+				}) (*struct{}, error) {
+					t.Fatal("handler should not be called")
+					return nil, nil
+				})
+			},
+			Method:  http.MethodPut,
+			URL:     "/body",
+			Headers: map[string]string{"Content-Length": "9"},
+			Body:    "foobarbaz",
+			Assert: func(t *testing.T, resp *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusRequestEntityTooLarge, resp.Code)
+				assert.Contains(t, resp.Body.String(), `"location":"header.Content-Length","value":9`)
+			},
+		},
+		{
+			Name: "request-body-at-limit",
+			Register: func(t *testing.T, api huma.API) {
+				huma.Register(api, huma.Operation{
+					Method:       http.MethodPut,
+					Path:         "/body",
+					MaxBodyBytes: 9,
+				}, func(ctx context.Context, input *struct {
+					RawBody []byte
+				}) (*struct{}, error) {
+					assert.Equal(t, "foobarbaz", string(input.RawBody))
+					return nil, nil
+				})
+			},
+			Method: http.MethodPut,
+			URL:    "/body",
+			Body:   "foobarbaz",
+		},
+		{
+			Name: "request-body-nested",
+			Register: func(t *testing.T, api huma.API) {
+				huma.Register(api, huma.Operation{
+					Method: http.MethodPut,
+					Path:   "/body",
+				}, func(ctx context.Context, input *struct {
+					Body struct {
+						Name   *string           `json:"name"`
+						Labels map[string]string `json:"labels"`
+						Items  []struct {
+							ID    int     `json:"id"`
+							Score float64 `json:"score"`
+						} `json:"items"`
+						Created time.Time `json:"created"`
+					}
+				}) (*struct{}, error) {
+					assert.Equal(t, "Huma", *input.Body.Name)
+					assert.Equal(t, map[string]string{"env": "test"}, input.Body.Labels)
+					assert.Len(t, input.Body.Items, 2)
+					assert.Equal(t, 9007199254740993, input.Body.Items[1].ID)
+					assert.Equal(t, 1.5, input.Body.Items[1].Score)
+					assert.Equal(t, 2024, input.Body.Created.Year())
+					return nil, nil
+				})
+			},
+			Method: http.MethodPut,
+			URL:    "/body",
+			Body:   `{"name": "Huma", "labels": {"env": "test"}, "items": [{"id": 1, "score": 0}, {"id": 9007199254740993, "score": 1.5}], "created": "2024-01-02T03:04:05Z"}`,
+		},
+		{
+			Name: "request-body-nested-invalid",
+			Register: func(t *testing.T, api huma.API) {
+				huma.Register(api, huma.Operation{
+					Method: http.MethodPut,
+					Path:   "/body",
+				}, func(ctx context.Context, input *struct {
+					Body struct {
+						Name  string `json:"name" maxLength:"5"`
+						Items []struct {
+							ID int `json:"id"`
+						} `json:"items"`
+					}
+				}) (*struct{}, error) {
+					return nil, nil
+				})
+			},
+			Method: http.MethodPut,
+			URL:    "/body",
+			Body:   `{"name": null, "items": [{"id": "one"}, {"id": 1.5}], "extra": true}`,
+			Assert: func(t *testing.T, resp *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+				assert.Contains(t, resp.Body.String(), `"message":"expected number","location":"body.items[0].id"`)
+				assert.Contains(t, resp.Body.String(), `"message":"unexpected property","location":"body.extra"`)
+				assert.Contains(t, resp.Body.String(), `"message":"expected required property name to be present","location":"body"`)
 			},
 		},
 		{
@@ -439,6 +618,232 @@ func TestFeatures(t *testing.T) {
 				assert.Empty(t, resp.Header().Values("Empty"))
 			},
 		},
+		{
+			Name: "response-dynamic-status",
+			Register: func(t *testing.T, api huma.API) {
+				type Resp struct {
+					Status int    `enum:"200,201"`
+					ETag   string `header:"ETag"`
+					Body   struct {
+						Greeting string `json:"greeting"`
+					}
+				}
+
+				huma.Register(api, huma.Operation{
+					Method: http.MethodPut,
+					Path:   "/response-dynamic-status",
+				}, func(ctx context.Context, input *struct{}) (*Resp, error) {
+					resp := &Resp{}
+					resp.Status = http.StatusCreated
+					resp.ETag = "abc"
+					resp.Body.Greeting = "Hello"
+					return resp, nil
+				})
+
+				// Each possible status is documented with the body and headers.
+				for _, status := range []string{"200", "201"} {
+					r := api.OpenAPI().Paths["/response-dynamic-status"].Put.Responses[status]
+					require.NotNil(t, r, status)
+					assert.NotNil(t, r.Content["application/json"].Schema, status)
+					assert.NotNil(t, r.Headers["ETag"], status)
+				}
+			},
+			Method: http.MethodPut,
+			URL:    "/response-dynamic-status",
+			Assert: func(t *testing.T, resp *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusCreated, resp.Code)
+				assert.Equal(t, "abc", resp.Header().Get("ETag"))
+			},
+		},
+		{
+			Name: "response-status-coder",
+			Register: func(t *testing.T, api huma.API) {
+				huma.Register(api, huma.Operation{
+					Method: http.MethodPost,
+					Path:   "/response-status-coder",
+				}, func(ctx context.Context, input *struct{}) (*StatusCoderOutput, error) {
+					return &StatusCoderOutput{status: http.StatusAccepted}, nil
+				})
+			},
+			Method: http.MethodPost,
+			URL:    "/response-status-coder",
+			Assert: func(t *testing.T, resp *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusAccepted, resp.Code)
+			},
+		},
+		{
+			Name: "response-status-coder-nil",
+			Register: func(t *testing.T, api huma.API) {
+				huma.Register(api, huma.Operation{
+					Method: http.MethodPost,
+					Path:   "/response-status-coder-nil",
+				}, func(ctx context.Context, input *struct{}) (*ValueStatusCoderOutput, error) {
+					// Nil outputs are not asked for their status, which would panic
+					// for value receivers.
+					return nil, nil
+				})
+			},
+			Method: http.MethodPost,
+			URL:    "/response-status-coder-nil",
+			Assert: func(t *testing.T, resp *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusNoContent, resp.Code)
+			},
+		},
+		{
+			Name: "response-status-zero",
+			Register: func(t *testing.T, api huma.API) {
+				type Resp struct {
+					Status int
+				}
+
+				huma.Register(api, huma.Operation{
+					Method: http.MethodPost,
+					Path:   "/response-status-zero",
+				}, func(ctx context.Context, input *struct{}) (*Resp, error) {
+					// No status set, so the default is used.
+					return &Resp{}, nil
+				})
+			},
+			Method: http.MethodPost,
+			URL:    "/response-status-zero",
+			Assert: func(t *testing.T, resp *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusNoContent, resp.Code)
+			},
+		},
+		{
+			Name: "response-headers-multi",
+			Register: func(t *testing.T, api huma.API) {
+				type Resp struct {
+					SetCookie []string    `header:"Set-Cookie"`
+					Link      []string    `header:"Link"`
+					Ints      []int       `header:"X-Ints"`
+					Dates     []time.Time `header:"X-Dates"`
+				}
+
+				huma.Register(api, huma.Operation{
+					Method: http.MethodGet,
+					Path:   "/response-headers-multi",
+				}, func(ctx context.Context, input *struct{}) (*Resp, error) {
+					resp := &Resp{}
+					resp.SetCookie = []string{"foo=bar", "baz=123"}
+					resp.Ints = []int{1, 2}
+					resp.Dates = []time.Time{time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC)}
+					return resp, nil
+				})
+			},
+			Method: http.MethodGet,
+			URL:    "/response-headers-multi",
+			Assert: func(t *testing.T, resp *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusNoContent, resp.Code)
+				assert.Equal(t, []string{"foo=bar", "baz=123"}, resp.Header().Values("Set-Cookie"))
+				assert.Equal(t, []string{"1", "2"}, resp.Header().Values("X-Ints"))
+				assert.Equal(t, []string{"Sun, 01 Jan 2023 12:00:00 GMT"}, resp.Header().Values("X-Dates"))
+				assert.Empty(t, resp.Header().Values("Link"))
+			},
+		},
+		{
+			Name: "cookies",
+			Register: func(t *testing.T, api huma.API) {
+				type Resp struct {
+					SetCookie []*http.Cookie `header:"Set-Cookie"`
+					Other     http.Cookie    `header:"Set-Cookie"`
+				}
+
+				huma.Register(api, huma.Operation{
+					Method: http.MethodGet,
+					Path:   "/cookies",
+				}, func(ctx context.Context, input *struct {
+					Session string      `cookie:"session"`
+					Count   int         `cookie:"count" minimum:"1"`
+					Default string      `cookie:"default" default:"def"`
+					CSRF    http.Cookie `cookie:"csrf"`
+				}) (*Resp, error) {
+					assert.Equal(t, "abc123", input.Session)
+					assert.Equal(t, 5, input.Count)
+					assert.Equal(t, "def", input.Default)
+					assert.Equal(t, "csrf", input.CSRF.Name)
+					assert.Equal(t, "token", input.CSRF.Value)
+					return &Resp{
+						SetCookie: []*http.Cookie{
+							{Name: "foo", Value: "bar"},
+							{Name: "baz", Value: "123", HttpOnly: true},
+						},
+					}, nil
+				})
+
+				// Cookie params are documented.
+				params := api.OpenAPI().Paths["/cookies"].Get.Parameters
+				require.Len(t, params, 4)
+				assert.Equal(t, "cookie", params[0].In)
+				assert.Equal(t, "session", params[0].Name)
+				assert.Equal(t, "string", params[3].Schema.Type)
+				assert.Equal(t, "string", api.OpenAPI().Paths["/cookies"].Get.Responses["204"].Headers["Set-Cookie"].Schema.Type)
+			},
+			Method: http.MethodGet,
+			URL:    "/cookies",
+			Headers: map[string]string{
+				"Cookie": "session=abc123; count=5; csrf=token",
+			},
+			Assert: func(t *testing.T, resp *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusNoContent, resp.Code)
+				assert.Equal(t, []string{"foo=bar", "baz=123; HttpOnly"}, resp.Header().Values("Set-Cookie"))
+			},
+		},
+		{
+			Name: "raw-handler",
+			Register: func(t *testing.T, api huma.API) {
+				huma.RegisterRaw(api, huma.Operation{
+					Method: http.MethodGet,
+					Path:   "/raw/{id}",
+					Parameters: []*huma.Param{
+						{Name: "id", In: "path", Required: true, Schema: &huma.Schema{Type: "string"}},
+					},
+					DefaultStatus: http.StatusAccepted,
+					Errors:        []int{http.StatusNotFound},
+				}, func(ctx huma.Context) {
+					assert.Equal(t, "abc", ctx.Param("id"))
+					ctx.SetHeader("Content-Type", "text/plain")
+					ctx.SetStatus(http.StatusAccepted)
+					ctx.BodyWriter().Write([]byte("raw " + ctx.Param("id")))
+				})
+
+				// The operation is still documented.
+				op := api.OpenAPI().Paths["/raw/{id}"].Get
+				require.NotNil(t, op)
+				assert.Len(t, op.Parameters, 1)
+				assert.Equal(t, "Accepted", op.Responses["202"].Description)
+				assert.NotNil(t, op.Responses["404"].Content["application/problem+json"])
+			},
+			Method: http.MethodGet,
+			URL:    "/raw/abc",
+			Assert: func(t *testing.T, resp *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusAccepted, resp.Code)
+				assert.Equal(t, "text/plain", resp.Header().Get("Content-Type"))
+				assert.Equal(t, "raw abc", resp.Body.String())
+			},
+		},
+		{
+			Name: "cookies-invalid",
+			Register: func(t *testing.T, api huma.API) {
+				huma.Register(api, huma.Operation{
+					Method: http.MethodGet,
+					Path:   "/cookies",
+				}, func(ctx context.Context, input *struct {
+					Count int `cookie:"count" minimum:"1"`
+				}) (*struct{}, error) {
+					return nil, nil
+				})
+			},
+			Method: http.MethodGet,
+			URL:    "/cookies",
+			Headers: map[string]string{
+				"Cookie": "count=0",
+			},
+			Assert: func(t *testing.T, resp *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+				assert.Contains(t, resp.Body.String(), `"location":"cookie.count"`)
+			},
+		},
 		{
 			Name: "response-custom-content-type",
 			Register: func(t *testing.T, api huma.API) {
@@ -582,41 +987,96 @@ func TestFeatures(t *testing.T) {
 			},
 		},
 		{
-			Name: "dynamic-status",
+			Name: "response-buffered",
 			Register: func(t *testing.T, api huma.API) {
 				type Resp struct {
-					Status int
+					Body struct {
+						Greeting string `json:"greeting"`
+					}
 				}
 
 				huma.Register(api, huma.Operation{
-					Method: http.MethodGet,
-					Path:   "/status",
+					Method:           http.MethodGet,
+					Path:             "/response",
+					ResponseStrategy: huma.ResponseBuffered,
 				}, func(ctx context.Context, input *struct{}) (*Resp, error) {
 					resp := &Resp{}
-					resp.Status = 256
+					resp.Body.Greeting = "Hello"
 					return resp, nil
 				})
 			},
 			Method: http.MethodGet,
-			URL:    "/status",
+			URL:    "/response",
 			Assert: func(t *testing.T, resp *httptest.ResponseRecorder) {
-				assert.Equal(t, 256, resp.Code)
+				assert.Equal(t, http.StatusOK, resp.Code)
+				assert.Equal(t, strconv.Itoa(resp.Body.Len()), resp.Header().Get("Content-Length"))
+				assert.Contains(t, resp.Body.String(), `"greeting":"Hello"`)
 			},
 		},
 		{
-			// Simulate a request with a body that came from another call, which
-			// includes the `$schema` field. It should be allowed to be passed
-			// to the new operation as input without modification.
-			Name: "round-trip-schema-field",
+			Name: "response-buffered-marshal-error",
 			Register: func(t *testing.T, api huma.API) {
-				huma.Register(api, huma.Operation{
-					Method: http.MethodPut,
-					Path:   "/round-trip",
-				}, func(ctx context.Context, input *struct {
+				type Resp struct {
 					Body struct {
-						Name string `json:"name"`
+						Greeting any `json:"greeting"`
 					}
-				}) (*struct{}, error) {
+				}
+
+				huma.Register(api, huma.Operation{
+					Method:           http.MethodGet,
+					Path:             "/response",
+					ResponseStrategy: huma.ResponseBuffered,
+				}, func(ctx context.Context, input *struct{}) (*Resp, error) {
+					resp := &Resp{}
+					resp.Body.Greeting = func() {}
+					return resp, nil
+				})
+			},
+			Method: http.MethodGet,
+			URL:    "/response",
+			Assert: func(t *testing.T, resp *httptest.ResponseRecorder) {
+				// Nothing was sent before marshaling failed, so the panic is
+				// recovered into a proper error response.
+				assert.Equal(t, http.StatusInternalServerError, resp.Code)
+				assert.NotContains(t, resp.Body.String(), "error marshaling response")
+			},
+		},
+		{
+			Name: "dynamic-status",
+			Register: func(t *testing.T, api huma.API) {
+				type Resp struct {
+					Status int
+				}
+
+				huma.Register(api, huma.Operation{
+					Method: http.MethodGet,
+					Path:   "/status",
+				}, func(ctx context.Context, input *struct{}) (*Resp, error) {
+					resp := &Resp{}
+					resp.Status = 256
+					return resp, nil
+				})
+			},
+			Method: http.MethodGet,
+			URL:    "/status",
+			Assert: func(t *testing.T, resp *httptest.ResponseRecorder) {
+				assert.Equal(t, 256, resp.Code)
+			},
+		},
+		{
+			// Simulate a request with a body that came from another call, which
+			// includes the `$schema` field. It should be allowed to be passed
+			// to the new operation as input without modification.
+			Name: "round-trip-schema-field",
+			Register: func(t *testing.T, api huma.API) {
+				huma.Register(api, huma.Operation{
+					Method: http.MethodPut,
+					Path:   "/round-trip",
+				}, func(ctx context.Context, input *struct {
+					Body struct {
+						Name string `json:"name"`
+					}
+				}) (*struct{}, error) {
 					return nil, nil
 				})
 			},
@@ -715,6 +1175,20 @@ func TestFeatures(t *testing.T) {
 	}
 }
 
+type StatusCoderOutput struct {
+	status int
+}
+
+func (o *StatusCoderOutput) GetStatus() int {
+	return o.status
+}
+
+type ValueStatusCoderOutput struct{}
+
+func (o ValueStatusCoderOutput) GetStatus() int {
+	return http.StatusAccepted
+}
+
 func TestOpenAPI(t *testing.T) {
 	r, api := humatest.New(t, huma.DefaultConfig("Features Test API", "1.0.0"))
 
@@ -930,7 +1404,157 @@ func TestNestedResolverWithPath(t *testing.T) {
 	w := httptest.NewRecorder()
 	r.ServeHTTP(w, req)
 	assert.Equal(t, http.StatusUnprocessableEntity, w.Code, w.Body.String())
-	assert.Contains(t, w.Body.String(), `"location":"body.field1.foo[0].field2"`)
+	assert.Contains(t, w.Body.String(), `"location":"body.field1[\"foo\"][0].field2"`)
+}
+
+type MapResolverItem struct {
+	Name     string `json:"name"`
+	resolved bool
+}
+
+func (i *MapResolverItem) Resolve(ctx huma.Context, prefix *huma.PathBuffer) []error {
+	i.resolved = true
+	if i.Name == "" {
+		return []error{&huma.ErrorDetail{
+			Location: prefix.With("name"),
+			Message:  "name is required",
+		}}
+	}
+	return nil
+}
+
+func TestResolverMapsAndInterfaces(t *testing.T) {
+	_, api := humatest.New(t, huma.DefaultConfig("Test API", "1.0.0"))
+
+	var resolved []bool
+	huma.Register(api, huma.Operation{
+		OperationID: "test",
+		Method:      http.MethodPut,
+		Path:        "/test",
+	}, func(ctx context.Context, input *struct {
+		Body struct {
+			Items    map[string]MapResolverItem  `json:"items"`
+			Pointers map[string]*MapResolverItem `json:"pointers,omitempty"`
+		}
+	}) (*struct{}, error) {
+		resolved = nil
+		for _, item := range input.Body.Items {
+			resolved = append(resolved, item.resolved)
+		}
+		return nil, nil
+	})
+
+	resp := api.Put("/test", map[string]any{
+		"items":    map[string]any{"a.b": map[string]any{"name": ""}, "ok": map[string]any{"name": "x"}},
+		"pointers": map[string]any{"p": map[string]any{"name": ""}},
+	})
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code, resp.Body.String())
+	assert.Contains(t, resp.Body.String(), `"location":"body.items[\"a.b\"].name"`)
+	assert.Contains(t, resp.Body.String(), `"location":"body.pointers[\"p\"].name"`)
+	assert.NotContains(t, resp.Body.String(), `ok`)
+
+	// Modifications made by resolvers to map values are kept.
+	resp = api.Put("/test", map[string]any{"items": map[string]any{"ok": map[string]any{"name": "x"}}})
+	assert.Equal(t, http.StatusNoContent, resp.Code, resp.Body.String())
+	assert.Equal(t, []bool{true}, resolved)
+}
+
+type InterfaceResolverInput struct {
+	Item any
+}
+
+func (i *InterfaceResolverInput) Resolve(ctx huma.Context) []error {
+	// Interface fields are typically populated by custom decoding or earlier
+	// resolvers, and are walked after their parent's resolver has run.
+	i.Item = struct {
+		Items []MapResolverItem `json:"items"`
+	}{Items: []MapResolverItem{{Name: "ok"}, {}}}
+	return nil
+}
+
+func TestResolverInterface(t *testing.T) {
+	_, api := humatest.New(t, huma.DefaultConfig("Test API", "1.0.0"))
+
+	huma.Register(api, huma.Operation{
+		OperationID: "test",
+		Method:      http.MethodGet,
+		Path:        "/test",
+	}, func(ctx context.Context, input *struct {
+		InterfaceResolverInput
+		Holder struct {
+			Value any
+		}
+	}) (*struct{}, error) {
+		return nil, nil
+	})
+
+	resp := api.Get("/test")
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code, resp.Body.String())
+	assert.Contains(t, resp.Body.String(), `"location":"item.items[1].name"`)
+}
+
+var resolveOrderLog []string
+
+type OrderedResolverItem struct {
+	Name string `json:"name"`
+}
+
+func (i *OrderedResolverItem) Resolve(ctx huma.Context) []error {
+	resolveOrderLog = append(resolveOrderLog, i.Name)
+	return nil
+}
+
+type OrderedResolverAuth struct{}
+
+func (a *OrderedResolverAuth) Resolve(ctx huma.Context) []error {
+	resolveOrderLog = append(resolveOrderLog, "auth")
+	if ctx.Header("Authorization") == "" {
+		return []error{huma.StopResolving(huma.Error401Unauthorized("missing token"))}
+	}
+	return nil
+}
+
+func (a *OrderedResolverAuth) ResolverOrder() int {
+	return -1
+}
+
+func TestResolverOrder(t *testing.T) {
+	_, api := humatest.New(t, huma.DefaultConfig("Test API", "1.0.0"))
+
+	huma.Register(api, huma.Operation{
+		OperationID: "test",
+		Method:      http.MethodPut,
+		Path:        "/test",
+	}, func(ctx context.Context, input *struct {
+		Body struct {
+			Items map[string]OrderedResolverItem `json:"items"`
+			List  []OrderedResolverItem          `json:"list"`
+		}
+		// Declared last, but runs first due to its order.
+		Auth OrderedResolverAuth
+	}) (*struct{}, error) {
+		return nil, nil
+	})
+
+	body := map[string]any{
+		"items": map[string]any{
+			"c": map[string]any{"name": "c"},
+			"a": map[string]any{"name": "a"},
+			"b": map[string]any{"name": "b"},
+		},
+		"list": []any{map[string]any{"name": "d"}, map[string]any{"name": "e"}},
+	}
+
+	resolveOrderLog = nil
+	resp := api.Put("/test", "Authorization: token", body)
+	assert.Equal(t, http.StatusNoContent, resp.Code, resp.Body.String())
+	assert.Equal(t, []string{"auth", "a", "b", "c", "d", "e"}, resolveOrderLog)
+
+	// Failing to authenticate skips all remaining resolvers.
+	resolveOrderLog = nil
+	resp = api.Put("/test", body)
+	assert.Equal(t, http.StatusUnauthorized, resp.Code, resp.Body.String())
+	assert.Equal(t, []string{"auth"}, resolveOrderLog)
 }
 
 type ResolverCustomStatus struct{}
@@ -957,6 +1581,260 @@ func TestResolverCustomStatus(t *testing.T) {
 	assert.Contains(t, w.Body.String(), "nope")
 }
 
+func TestValidationErrorsReused(t *testing.T) {
+	// Error details are reused between requests, so make sure a later request
+	// does not see stale details from an earlier one.
+	_, api := humatest.New(t, huma.DefaultConfig("Test API", "1.0.0"))
+
+	huma.Register(api, huma.Operation{
+		OperationID: "test",
+		Method:      http.MethodGet,
+		Path:        "/test",
+	}, func(ctx context.Context, input *struct {
+		Num  int    `query:"num" maximum:"5"`
+		Name string `query:"name" minLength:"3" required:"true"`
+	}) (*struct{}, error) {
+		return nil, nil
+	})
+
+	for i := 0; i < 20; i++ {
+		resp := api.Get("/test?num=10")
+		assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+		assert.Contains(t, resp.Body.String(), `"location":"query.num","value":10`)
+		assert.Contains(t, resp.Body.String(), `"message":"required query parameter is missing","location":"query.name"`)
+
+		resp = api.Get("/test?name=a")
+		assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+		assert.NotContains(t, resp.Body.String(), "query.num")
+		assert.Contains(t, resp.Body.String(), `"location":"query.name","value":"a"`)
+	}
+}
+
+func TestRawBodyNotReused(t *testing.T) {
+	// The raw body is not copied, so its buffer must not be reused by other
+	// requests while the handler is still running.
+	_, api := humatest.New(t, huma.DefaultConfig("Test API", "1.0.0"))
+
+	huma.Register(api, huma.Operation{
+		OperationID: "other",
+		Method:      http.MethodPut,
+		Path:        "/other",
+	}, func(ctx context.Context, input *struct {
+		RawBody []byte
+	}) (*struct{}, error) {
+		return nil, nil
+	})
+
+	huma.Register(api, huma.Operation{
+		OperationID: "raw",
+		Method:      http.MethodPut,
+		Path:        "/raw",
+	}, func(ctx context.Context, input *struct {
+		RawBody []byte
+	}) (*struct{}, error) {
+		for i := 0; i < 10; i++ {
+			api.Put("/other", strings.NewReader("overwritten"))
+		}
+		assert.Equal(t, "original", string(input.RawBody))
+		return nil, nil
+	})
+
+	resp := api.Put("/raw", strings.NewReader("original"))
+	assert.Equal(t, http.StatusNoContent, resp.Code)
+}
+
+func TestNetipParams(t *testing.T) {
+	_, api := humatest.New(t, huma.DefaultConfig("Test API", "1.0.0"))
+
+	huma.Register(api, huma.Operation{
+		OperationID: "check",
+		Method:      http.MethodPost,
+		Path:        "/check/{addr}",
+	}, func(ctx context.Context, input *struct {
+		Addr    netip.Addr     `path:"addr"`
+		V6      netip.Addr     `query:"v6" format:"ipv6"`
+		Allowed []netip.Prefix `query:"allowed"`
+		Body    struct {
+			Network netip.Prefix `json:"network"`
+		}
+	}) (*struct {
+		Body struct {
+			Contained bool       `json:"contained"`
+			Allowed   bool       `json:"allowed"`
+			V6        netip.Addr `json:"v6"`
+		}
+	}, error) {
+		out := &struct {
+			Body struct {
+				Contained bool       `json:"contained"`
+				Allowed   bool       `json:"allowed"`
+				V6        netip.Addr `json:"v6"`
+			}
+		}{}
+		out.Body.Contained = input.Body.Network.Contains(input.Addr)
+		for _, p := range input.Allowed {
+			out.Body.Allowed = out.Body.Allowed || p.Contains(input.Addr)
+		}
+		out.Body.V6 = input.V6
+		return out, nil
+	})
+
+	params := api.OpenAPI().Paths["/check/{addr}"].Post.Parameters
+	assert.Equal(t, "ip", params[0].Schema.Format)
+	assert.Equal(t, "ipv6", params[1].Schema.Format)
+	assert.Equal(t, "cidr", params[2].Schema.Items.Format)
+
+	resp := api.Post("/check/10.1.2.3?v6=::1&allowed=192.168.0.0/16,10.0.0.0/8", map[string]any{"network": "10.0.0.0/8"})
+	assert.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+	assert.JSONEq(t, `{"$schema": "https:///schemas/checkResponse.json", "contained": true, "allowed": true, "v6": "::1"}`, resp.Body.String())
+
+	resp = api.Post("/check/10.1.2.3?v6=127.0.0.1", map[string]any{"network": "10.0.0.0/8"})
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+	assert.Contains(t, resp.Body.String(), "query.v6")
+
+	resp = api.Post("/check/bad", map[string]any{"network": "10.0.0.0/8"})
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+	assert.Contains(t, resp.Body.String(), "path.addr")
+
+	resp = api.Post("/check/10.1.2.3?allowed=10.0.0.0/99", map[string]any{"network": "10.0.0.0/8"})
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+	assert.Contains(t, resp.Body.String(), "query.allowed")
+
+	resp = api.Post("/check/10.1.2.3", map[string]any{"network": "nope"})
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+	assert.Contains(t, resp.Body.String(), "body.network")
+}
+
+func TestRepeatedHeaderParams(t *testing.T) {
+	_, api := humatest.New(t, huma.DefaultConfig("Test API", "1.0.0"))
+
+	huma.Register(api, huma.Operation{
+		OperationID: "headers",
+		Method:      http.MethodGet,
+		Path:        "/headers",
+	}, func(ctx context.Context, input *struct {
+		Accept []string `header:"Accept"`
+		Tags   []string `header:"X-Tag" enum:"a,b,c"`
+		Ints   []int    `header:"X-Int"`
+		Single string   `header:"X-Single"`
+	}) (*struct {
+		Body struct {
+			Accept []string `json:"accept"`
+			Tags   []string `json:"tags"`
+			Ints   []int    `json:"ints"`
+			Single string   `json:"single"`
+		}
+	}, error) {
+		out := &struct {
+			Body struct {
+				Accept []string `json:"accept"`
+				Tags   []string `json:"tags"`
+				Ints   []int    `json:"ints"`
+				Single string   `json:"single"`
+			}
+		}{}
+		out.Body.Accept = input.Accept
+		out.Body.Tags = input.Tags
+		out.Body.Ints = input.Ints
+		out.Body.Single = input.Single
+		return out, nil
+	})
+
+	params := api.OpenAPI().Paths["/headers"].Get.Parameters
+	assert.Equal(t, "array", params[0].Schema.Type)
+	assert.Equal(t, "string", params[0].Schema.Items.Type)
+
+	do := func(headers http.Header) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(http.MethodGet, "/headers", nil)
+		req.Header = headers
+		resp := httptest.NewRecorder()
+		api.Adapter().ServeHTTP(resp, req)
+		return resp
+	}
+
+	// All values are bound, with whitespace and empty list items ignored.
+	resp := do(http.Header{
+		"Accept":   {"application/json", "text/plain;q=0.5, */*;q=0.1"},
+		"X-Tag":    {"a", "b,,c"},
+		"X-Int":    {"1, 2", "3"},
+		"X-Single": {"first", "second"},
+	})
+	assert.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+	assert.JSONEq(t, `{
+		"$schema": "https:///schemas/headersResponse.json",
+		"accept": ["application/json", "text/plain;q=0.5", "*/*;q=0.1"],
+		"tags": ["a", "b", "c"],
+		"ints": [1, 2, 3],
+		"single": "first"
+	}`, resp.Body.String())
+
+	// Each item is validated.
+	resp = do(http.Header{"X-Tag": {"a", "d"}})
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+	assert.Contains(t, resp.Body.String(), "header.X-Tag")
+
+	resp = do(http.Header{"X-Int": {"1", "two"}})
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+	assert.Contains(t, resp.Body.String(), "invalid integer")
+}
+
+func TestRawBodyTimeoutNotReused(t *testing.T) {
+	// Handlers which time out may outlive the request, so the raw body's buffer
+	// must not be reused until they return.
+	_, api := humatest.New(t, huma.DefaultConfig("Test API", "1.0.0"))
+
+	huma.Register(api, huma.Operation{
+		OperationID: "other",
+		Method:      http.MethodPut,
+		Path:        "/other",
+	}, func(ctx context.Context, input *struct {
+		RawBody []byte
+	}) (*struct{}, error) {
+		return nil, nil
+	})
+
+	release := make(chan struct{})
+	body := make(chan string, 1)
+	huma.Register(api, huma.Operation{
+		OperationID: "raw",
+		Method:      http.MethodPut,
+		Path:        "/raw",
+		Timeout:     10 * time.Millisecond,
+	}, func(ctx context.Context, input *struct {
+		RawBody []byte
+	}) (*struct{}, error) {
+		<-release
+		body <- string(input.RawBody)
+		return nil, nil
+	})
+
+	resp := api.Put("/raw", strings.NewReader("original"))
+	assert.Equal(t, http.StatusGatewayTimeout, resp.Code)
+	for i := 0; i < 10; i++ {
+		api.Put("/other", strings.NewReader("overwritten"))
+	}
+	close(release)
+	assert.Equal(t, "original", <-body)
+}
+
+func TestParamUnsupportedTypePanics(t *testing.T) {
+	// Param parsers are created at registration time, so unsupported types
+	// are caught immediately rather than on the first request.
+	_, app := humatest.New(t, huma.DefaultConfig("Test API", "1.0.0"))
+
+	assert.PanicsWithValue(t, "unsupported param type map[string]string", func() {
+		huma.Register(app, huma.Operation{
+			OperationID: "bug",
+			Method:      http.MethodGet,
+			Path:        "/bug",
+		}, func(ctx context.Context, input *struct {
+			Param map[string]string `query:"param"`
+		}) (*struct{}, error) {
+			return nil, nil
+		})
+	})
+}
+
 func TestParamPointerPanics(t *testing.T) {
 	// For now we don't support these, so we panic rather than have subtle
 	// bugs that are hard to track down.
@@ -1128,3 +2006,951 @@ func BenchmarkHandlerFunc(b *testing.B) {
 		}
 	})
 }
+
+func TestPanicRecovery(t *testing.T) {
+	var recovered any
+	var stack []byte
+	config := huma.DefaultConfig("Test API", "1.0.0")
+	config.OnPanic = func(ctx huma.Context, r any, s []byte) {
+		recovered = r
+		stack = s
+	}
+	_, api := humatest.New(t, config)
+
+	huma.Register(api, huma.Operation{
+		Method: http.MethodGet,
+		Path:   "/panic",
+	}, func(ctx context.Context, input *struct{}) (*struct{}, error) {
+		panic("whoops")
+	})
+
+	resp := api.Get("/panic")
+	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+	assert.Equal(t, "application/problem+json", resp.Header().Get("Content-Type"))
+	assert.NotContains(t, resp.Body.String(), "whoops")
+	assert.Equal(t, "whoops", recovered)
+	assert.NotEmpty(t, stack)
+}
+
+func TestPanicRecoveryDisabled(t *testing.T) {
+	config := huma.DefaultConfig("Test API", "1.0.0")
+	config.RecoverPanics = false
+	_, api := humatest.New(t, config)
+
+	huma.Register(api, huma.Operation{
+		Method: http.MethodGet,
+		Path:   "/panic",
+	}, func(ctx context.Context, input *struct{}) (*struct{}, error) {
+		panic("whoops")
+	})
+
+	assert.Panics(t, func() {
+		api.Get("/panic")
+	})
+}
+
+func TestRequestID(t *testing.T) {
+	config := huma.DefaultConfig("Test API", "1.0.0")
+	config.RequestIDHeader = "X-Request-ID"
+	config.NewRequestID = func() string {
+		return "generated"
+	}
+	_, api := humatest.New(t, config)
+
+	huma.Register(api, huma.Operation{
+		Method: http.MethodGet,
+		Path:   "/id",
+	}, func(ctx context.Context, input *struct{}) (*struct{}, error) {
+		return nil, huma.Error404NotFound("not found: " + huma.RequestID(ctx))
+	})
+
+	resp := api.Get("/id", "X-Request-ID: abc123")
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+	assert.Equal(t, "abc123", resp.Header().Get("X-Request-ID"))
+	assert.Contains(t, resp.Body.String(), `"detail":"not found: abc123"`)
+	assert.Contains(t, resp.Body.String(), `"requestId":"abc123"`)
+
+	// Missing or unsafe IDs are replaced with a generated one.
+	resp = api.Get("/id", "X-Request-ID: bad id")
+	assert.Equal(t, "generated", resp.Header().Get("X-Request-ID"))
+	assert.Contains(t, resp.Body.String(), `"requestId":"generated"`)
+}
+
+func TestConfigMaxBodyBytes(t *testing.T) {
+	config := huma.DefaultConfig("Test API", "1.0.0")
+	config.MaxBodyBytes = 3
+	_, api := humatest.New(t, config)
+
+	huma.Register(api, huma.Operation{
+		Method: http.MethodPut,
+		Path:   "/body",
+	}, func(ctx context.Context, input *struct {
+		RawBody []byte
+	}) (*struct{}, error) {
+		return nil, nil
+	})
+
+	assert.Equal(t, int64(3), api.OpenAPI().Paths["/body"].Put.MaxBodyBytes)
+	resp := api.Put("/body", strings.NewReader("abcd"))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, resp.Code)
+}
+
+func TestConfigRequestTransformers(t *testing.T) {
+	config := huma.DefaultConfig("Test API", "1.0.0")
+	config.RequestTransformers = []huma.RequestTransformer{
+		func(ctx huma.Context, v any) (any, error) {
+			if m, ok := v.(map[string]any); ok {
+				m["count"] = m["count"].(float64) + 1
+			}
+			return v, nil
+		},
+	}
+	_, api := humatest.New(t, config)
+
+	huma.Register(api, huma.Operation{
+		Method: http.MethodPut,
+		Path:   "/body",
+		RequestTransformers: []huma.RequestTransformer{
+			func(ctx huma.Context, v any) (any, error) {
+				if m, ok := v.(map[string]any); ok {
+					m["count"] = m["count"].(float64) * 2
+				}
+				return v, nil
+			},
+		},
+	}, func(ctx context.Context, input *struct {
+		Body struct {
+			Count int `json:"count" maximum:"4"`
+		}
+	}) (*struct{}, error) {
+		assert.Equal(t, 4, input.Body.Count)
+		return nil, nil
+	})
+
+	// Config transformers run first: (1 + 1) * 2 = 4.
+	resp := api.Put("/body", map[string]any{"count": 1})
+	assert.Equal(t, http.StatusNoContent, resp.Code)
+
+	// Validation runs against the transformed body: (2 + 1) * 2 = 6.
+	resp = api.Put("/body", map[string]any{"count": 2})
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+}
+
+func TestOperationFromContext(t *testing.T) {
+	_, api := humatest.New(t, huma.DefaultConfig("Test API", "1.0.0"))
+
+	huma.Register(api, huma.Operation{
+		OperationID: "get-thing",
+		Method:      http.MethodGet,
+		Path:        "/thing",
+		Tags:        []string{"Things"},
+		Metadata:    map[string]any{"scope": "read"},
+	}, func(ctx context.Context, input *struct{}) (*struct{}, error) {
+		op := huma.OperationFromContext(ctx)
+		require.NotNil(t, op)
+		assert.Equal(t, "get-thing", op.OperationID)
+		assert.Equal(t, []string{"Things"}, op.Tags)
+		assert.Equal(t, "read", op.Metadata["scope"])
+		return nil, nil
+	})
+
+	resp := api.Get("/thing")
+	assert.Equal(t, http.StatusNoContent, resp.Code)
+
+	assert.Nil(t, huma.OperationFromContext(context.Background()))
+}
+
+func TestRouteFromContext(t *testing.T) {
+	_, api := humatest.New(t, huma.DefaultConfig("Test API", "1.0.0"))
+
+	var fromMiddleware huma.Route
+	api.UseMiddleware(func(ctx huma.Context, next func(huma.Context)) {
+		next(ctx)
+		fromMiddleware, _ = huma.RouteFromContext(ctx.Context())
+	})
+
+	huma.Register(api, huma.Operation{
+		OperationID: "get-item",
+		Method:      http.MethodGet,
+		Path:        "/things/{thing-id}/items/{item-id}",
+	}, func(ctx context.Context, input *struct {
+		ThingID string `path:"thing-id"`
+		ItemID  string `path:"item-id"`
+	}) (*struct{}, error) {
+		route, ok := huma.RouteFromContext(ctx)
+		require.True(t, ok)
+		assert.Equal(t, map[string]string{"thing-id": "a", "item-id": "b"}, route.Params)
+		return nil, nil
+	})
+
+	resp := api.Get("/things/a/items/b")
+	assert.Equal(t, http.StatusNoContent, resp.Code)
+	assert.Equal(t, huma.Route{
+		Method:      http.MethodGet,
+		Template:    "/things/{thing-id}/items/{item-id}",
+		OperationID: "get-item",
+		Params:      map[string]string{"thing-id": "a", "item-id": "b"},
+	}, fromMiddleware)
+
+	_, ok := huma.RouteFromContext(context.Background())
+	assert.False(t, ok)
+}
+
+func TestConfigDefaultStatus(t *testing.T) {
+	config := huma.DefaultConfig("Test API", "1.0.0")
+	config.DefaultStatus = huma.RESTDefaultStatus
+	_, api := humatest.New(t, config)
+
+	type Output struct {
+		Body struct {
+			ID string `json:"id"`
+		}
+	}
+
+	huma.Register(api, huma.Operation{
+		Method: http.MethodPost,
+		Path:   "/things",
+	}, func(ctx context.Context, input *struct{}) (*Output, error) {
+		return &Output{}, nil
+	})
+
+	huma.Register(api, huma.Operation{
+		Method: http.MethodGet,
+		Path:   "/things",
+	}, func(ctx context.Context, input *struct{}) (*Output, error) {
+		return &Output{}, nil
+	})
+
+	huma.Register(api, huma.Operation{
+		Method: http.MethodDelete,
+		Path:   "/things",
+	}, func(ctx context.Context, input *struct{}) (*struct{}, error) {
+		return nil, nil
+	})
+
+	huma.Register(api, huma.Operation{
+		Method:        http.MethodPut,
+		Path:          "/things",
+		DefaultStatus: http.StatusAccepted,
+	}, func(ctx context.Context, input *struct{}) (*Output, error) {
+		return &Output{}, nil
+	})
+
+	assert.Equal(t, http.StatusCreated, api.Post("/things").Code)
+	assert.Equal(t, http.StatusOK, api.Get("/things").Code)
+	assert.Equal(t, http.StatusNoContent, api.Delete("/things").Code)
+	assert.Equal(t, http.StatusAccepted, api.Put("/things").Code)
+
+	assert.NotNil(t, api.OpenAPI().Paths["/things"].Post.Responses["201"])
+}
+
+func TestValidationErrorStatus(t *testing.T) {
+	config := huma.DefaultConfig("Test API", "1.0.0")
+	config.ValidationErrorStatus = http.StatusBadRequest
+	_, api := humatest.New(t, config)
+
+	type Input struct {
+		Count int `query:"count" minimum:"1"`
+	}
+
+	huma.Register(api, huma.Operation{
+		Method: http.MethodGet,
+		Path:   "/config",
+		Errors: []int{http.StatusNotFound},
+	}, func(ctx context.Context, input *Input) (*struct{}, error) {
+		return nil, nil
+	})
+
+	huma.Register(api, huma.Operation{
+		Method:                http.MethodGet,
+		Path:                  "/override",
+		ValidationErrorStatus: http.StatusUnprocessableEntity,
+	}, func(ctx context.Context, input *Input) (*struct{}, error) {
+		return nil, nil
+	})
+
+	assert.Equal(t, http.StatusBadRequest, api.Get("/config?count=0").Code)
+	assert.Equal(t, http.StatusUnprocessableEntity, api.Get("/override?count=0").Code)
+
+	responses := api.OpenAPI().Paths["/config"].Get.Responses
+	assert.NotNil(t, responses["400"])
+	assert.Nil(t, responses["422"])
+}
+
+func TestDeprecatedUsage(t *testing.T) {
+	var used []string
+	config := huma.DefaultConfig("Test API", "1.0.0")
+	config.OnDeprecatedUsage = func(ctx huma.Context, location string) {
+		assert.Equal(t, "deprecated", ctx.Operation().OperationID)
+		used = append(used, location)
+	}
+	_, api := humatest.New(t, config)
+
+	type Item struct {
+		ID       string `json:"id"`
+		LegacyID string `json:"legacy_id,omitempty" deprecated:"true"`
+	}
+
+	huma.Register(api, huma.Operation{
+		OperationID: "deprecated",
+		Method:      http.MethodPut,
+		Path:        "/deprecated",
+	}, func(ctx context.Context, input *struct {
+		Sort string `query:"sort" deprecated:"true"`
+		Body struct {
+			Mode  string `json:"mode,omitempty" default:"fast" deprecated:"true"`
+			Items []Item `json:"items"`
+		}
+	}) (*struct{}, error) {
+		return nil, nil
+	})
+
+	param := api.OpenAPI().Paths["/deprecated"].Put.Parameters[0]
+	assert.True(t, param.Deprecated)
+
+	// No deprecated values, including the default.
+	resp := api.Put("/deprecated", map[string]any{"mode": "fast", "items": []any{map[string]any{"id": "a"}}})
+	assert.Equal(t, http.StatusNoContent, resp.Code, resp.Body.String())
+	assert.Empty(t, resp.Header().Values("Warning"))
+	assert.Nil(t, used)
+
+	resp = api.Put("/deprecated?sort=name", map[string]any{
+		"mode":  "slow",
+		"items": []any{map[string]any{"id": "a"}, map[string]any{"id": "b", "legacy_id": "b1"}},
+	})
+	assert.Equal(t, http.StatusNoContent, resp.Code, resp.Body.String())
+	assert.Equal(t, []string{"query.sort", "body.mode", "body.items[1].legacy_id"}, used)
+	assert.Equal(t, []string{
+		`299 - "query.sort is deprecated"`,
+		`299 - "body.mode is deprecated"`,
+		`299 - "body.items[1].legacy_id is deprecated"`,
+	}, resp.Header().Values("Warning"))
+}
+
+func TestValidationAudit(t *testing.T) {
+	var audited []error
+	config := huma.DefaultConfig("Test API", "1.0.0")
+	config.ValidationMode = huma.ValidationAudit
+	config.OnValidationAudit = func(ctx huma.Context, errs []error) {
+		assert.Equal(t, "audit", ctx.Operation().OperationID)
+		audited = errs
+	}
+	_, api := humatest.New(t, config)
+
+	type Input struct {
+		Count int `query:"count" minimum:"1"`
+		Body  struct {
+			Name string `json:"name" maxLength:"3"`
+			Tag  string `json:"tag,omitempty" default:"none"`
+		}
+	}
+
+	type Output struct {
+		Body struct {
+			Count int    `json:"count"`
+			Name  string `json:"name"`
+			Tag   string `json:"tag"`
+		}
+	}
+
+	handler := func(ctx context.Context, input *Input) (*Output, error) {
+		out := &Output{}
+		out.Body.Count = input.Count
+		out.Body.Name = input.Body.Name
+		out.Body.Tag = input.Body.Tag
+		return out, nil
+	}
+
+	huma.Register(api, huma.Operation{
+		OperationID: "audit",
+		Method:      http.MethodPut,
+		Path:        "/audit",
+	}, handler)
+
+	huma.Register(api, huma.Operation{
+		OperationID:    "enforce",
+		Method:         http.MethodPut,
+		Path:           "/enforce",
+		ValidationMode: huma.ValidationEnforce,
+	}, handler)
+
+	// Schema validation failures are reported but the handler is still called.
+	resp := api.Put("/audit?count=0", map[string]any{"name": "abcdef"})
+	assert.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+	assert.Contains(t, resp.Body.String(), `"count":0,"name":"abcdef","tag":"none"`)
+	require.Len(t, audited, 2)
+	assert.Equal(t, "query.count", audited[0].(*huma.ErrorDetail).Location)
+	assert.Equal(t, "body.name", audited[1].(*huma.ErrorDetail).Location)
+
+	// Valid requests are not reported.
+	audited = nil
+	assert.Equal(t, http.StatusOK, api.Put("/audit?count=1", map[string]any{"name": "abc"}).Code)
+	assert.Nil(t, audited)
+
+	// Input which cannot be parsed is still rejected.
+	assert.Equal(t, http.StatusUnprocessableEntity, api.Put("/audit?count=abc", map[string]any{"name": "abc"}).Code)
+	assert.Equal(t, http.StatusUnprocessableEntity, api.Put("/audit", map[string]any{"name": 123}).Code)
+	assert.Equal(t, http.StatusBadRequest, api.Put("/audit", strings.NewReader("{")).Code)
+	assert.Nil(t, audited)
+
+	// Operations can override the API-wide mode.
+	assert.Equal(t, http.StatusUnprocessableEntity, api.Put("/enforce?count=0", map[string]any{"name": "abc"}).Code)
+}
+
+func TestLenientValidation(t *testing.T) {
+	config := huma.DefaultConfig("Test API", "1.0.0")
+	envelope := huma.NewEnvelopeTransformer(nil)
+	config.Transformers = append(config.Transformers, envelope.Transform)
+	_, api := humatest.New(t, config)
+
+	type Output struct {
+		Body struct {
+			Name     string `json:"name"`
+			Warnings int    `json:"warnings"`
+		}
+	}
+
+	huma.Register(api, huma.Operation{
+		OperationID:       "lenient",
+		Method:            http.MethodPut,
+		Path:              "/lenient",
+		LenientValidation: []string{"maxLength", "enum"},
+	}, func(ctx context.Context, input *struct {
+		Color string `query:"color" enum:"red,blue"`
+		Count int    `query:"count" minimum:"1"`
+		Body  struct {
+			Name string `json:"name" maxLength:"3"`
+			Tag  string `json:"tag,omitempty" default:"none"`
+		}
+	}) (*Output, error) {
+		out := &Output{}
+		out.Body.Name = input.Body.Name
+		out.Body.Warnings = len(huma.ValidationWarningsFromContext(ctx))
+		return out, nil
+	})
+
+	// Lenient keywords only produce warnings.
+	resp := api.Put("/lenient?color=green", map[string]any{"name": "abcdef"})
+	require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+	assert.Equal(t, []string{
+		`299 - "query.color: expected value to be one of \"red, blue\""`,
+		`299 - "body.name: expected length <= 3"`,
+	}, resp.Header().Values("Warning"))
+
+	var body struct {
+		Data struct {
+			Name     string `json:"name"`
+			Warnings int    `json:"warnings"`
+		} `json:"data"`
+		Meta struct {
+			Warnings []huma.ErrorDetail `json:"warnings"`
+		} `json:"meta"`
+	}
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &body))
+	assert.Equal(t, "abcdef", body.Data.Name)
+	assert.Equal(t, 2, body.Data.Warnings)
+	require.Len(t, body.Meta.Warnings, 2)
+	assert.Equal(t, "body.name", body.Meta.Warnings[1].Location)
+
+	// Other keywords are still enforced.
+	resp = api.Put("/lenient?count=0", map[string]any{"name": "abcdef"})
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+	assert.Len(t, resp.Header().Values("Warning"), 1)
+	assert.NotContains(t, resp.Body.String(), "expected length")
+
+	// Type errors are always rejected.
+	resp = api.Put("/lenient", map[string]any{"name": 123})
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+
+	// Valid requests get no warnings.
+	resp = api.Put("/lenient?color=red", map[string]any{"name": "abc"})
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Empty(t, resp.Header().Values("Warning"))
+	assert.NotContains(t, resp.Body.String(), "warnings\":[")
+}
+
+func TestOperationTimeout(t *testing.T) {
+	_, api := humatest.New(t, huma.DefaultConfig("Test API", "1.0.0"))
+
+	release := make(chan struct{})
+	defer close(release)
+
+	type Output struct {
+		Body string
+	}
+
+	huma.Register(api, huma.Operation{
+		Method:  http.MethodGet,
+		Path:    "/slow",
+		Timeout: 10 * time.Millisecond,
+		Errors:  []int{http.StatusNotFound},
+	}, func(ctx context.Context, input *struct{}) (*Output, error) {
+		// Ignore the context to ensure late results are discarded.
+		<-release
+		return &Output{Body: "late"}, nil
+	})
+
+	huma.Register(api, huma.Operation{
+		Method:  http.MethodGet,
+		Path:    "/ctx",
+		Timeout: 10 * time.Millisecond,
+	}, func(ctx context.Context, input *struct{}) (*Output, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+
+	huma.Register(api, huma.Operation{
+		Method:  http.MethodGet,
+		Path:    "/fast",
+		Timeout: time.Second,
+	}, func(ctx context.Context, input *struct{}) (*Output, error) {
+		_, ok := ctx.Deadline()
+		assert.True(t, ok)
+		return &Output{Body: "fast"}, nil
+	})
+
+	resp := api.Get("/slow")
+	assert.Equal(t, http.StatusGatewayTimeout, resp.Code)
+	assert.NotContains(t, resp.Body.String(), "late")
+
+	resp = api.Get("/ctx")
+	assert.Equal(t, http.StatusGatewayTimeout, resp.Code)
+
+	resp = api.Get("/fast")
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, `"fast"`+"\n", resp.Body.String())
+
+	assert.NotNil(t, api.OpenAPI().Paths["/slow"].Get.Responses["504"])
+}
+
+func TestOperationTimeoutPanic(t *testing.T) {
+	_, api := humatest.New(t, huma.DefaultConfig("Test API", "1.0.0"))
+
+	huma.Register(api, huma.Operation{
+		Method:  http.MethodGet,
+		Path:    "/panic",
+		Timeout: time.Second,
+	}, func(ctx context.Context, input *struct{}) (*struct{}, error) {
+		panic("boom")
+	})
+
+	resp := api.Get("/panic")
+	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+}
+
+func TestOperationMaxConcurrent(t *testing.T) {
+	_, api := humatest.New(t, huma.DefaultConfig("Test API", "1.0.0"))
+
+	started := make(chan struct{}, 10)
+	release := make(chan struct{})
+
+	for _, op := range []huma.Operation{
+		{Path: "/reject", MaxConcurrent: 1, Errors: []int{http.StatusNotFound}},
+		{Path: "/queue", MaxConcurrent: 1, MaxQueue: 1},
+		{Path: "/timeout", MaxConcurrent: 1, MaxQueue: 1, QueueTimeout: 10 * time.Millisecond},
+	} {
+		op.Method = http.MethodGet
+		huma.Register(api, op, func(ctx context.Context, input *struct{}) (*struct{}, error) {
+			started <- struct{}{}
+			<-release
+			return nil, nil
+		})
+	}
+
+	get := func(path string) <-chan int {
+		done := make(chan int, 1)
+		go func() {
+			done <- api.Get(path).Code
+		}()
+		return done
+	}
+
+	reject := get("/reject")
+	queue := get("/queue")
+	timeout := get("/timeout")
+	for i := 0; i < 3; i++ {
+		<-started
+	}
+
+	// Requests over the limit are rejected when there is no queue.
+	resp := api.Get("/reject")
+	assert.Equal(t, http.StatusTooManyRequests, resp.Code)
+	assert.Contains(t, resp.Body.String(), "too many concurrent requests")
+
+	// Queued requests give up after the queue timeout.
+	assert.Equal(t, http.StatusTooManyRequests, api.Get("/timeout").Code)
+
+	// Queued requests run once a slot is free.
+	queued := get("/queue")
+	close(release)
+	for _, done := range []<-chan int{reject, queue, timeout, queued} {
+		assert.Equal(t, http.StatusNoContent, <-done)
+	}
+
+	assert.NotNil(t, api.OpenAPI().Paths["/reject"].Get.Responses["429"])
+}
+
+func TestOperationMaxConcurrentTimeout(t *testing.T) {
+	_, api := humatest.New(t, huma.DefaultConfig("Test API", "1.0.0"))
+
+	release := make(chan struct{})
+	defer close(release)
+
+	huma.Register(api, huma.Operation{
+		Method:        http.MethodGet,
+		Path:          "/slow",
+		MaxConcurrent: 1,
+		Timeout:       10 * time.Millisecond,
+	}, func(ctx context.Context, input *struct{}) (*struct{}, error) {
+		// Ignore the context, so the handler outlives the request.
+		<-release
+		return nil, nil
+	})
+
+	assert.Equal(t, http.StatusGatewayTimeout, api.Get("/slow").Code)
+
+	// The timed out handler is still running, so still holds the only slot.
+	assert.Equal(t, http.StatusTooManyRequests, api.Get("/slow").Code)
+
+	// Once it returns, the slot is free again.
+	release <- struct{}{}
+	assert.Eventually(t, func() bool {
+		return api.Get("/slow").Code == http.StatusGatewayTimeout
+	}, time.Second, time.Millisecond)
+}
+
+func TestConfigResponseStrategy(t *testing.T) {
+	config := huma.DefaultConfig("Test API", "1.0.0")
+	config.ResponseStrategy = huma.ResponseBuffered
+	_, api := humatest.New(t, config)
+
+	type Output struct {
+		Body string
+	}
+
+	huma.Register(api, huma.Operation{
+		Method: http.MethodGet,
+		Path:   "/buffered",
+	}, func(ctx context.Context, input *struct{}) (*Output, error) {
+		return &Output{Body: "hello"}, nil
+	})
+
+	huma.Register(api, huma.Operation{
+		Method:           http.MethodGet,
+		Path:             "/streamed",
+		ResponseStrategy: huma.ResponseStreamed,
+	}, func(ctx context.Context, input *struct{}) (*Output, error) {
+		return &Output{Body: "hello"}, nil
+	})
+
+	assert.Equal(t, "8", api.Get("/buffered").Header().Get("Content-Length"))
+	assert.Empty(t, api.Get("/streamed").Header().Get("Content-Length"))
+}
+
+func TestPathParamStyles(t *testing.T) {
+	_, api := humatest.New(t, huma.DefaultConfig("Test API", "1.0.0"))
+
+	type Output struct {
+		Body struct {
+			ID     int      `json:"id"`
+			Tags   []string `json:"tags"`
+			Colors []string `json:"colors"`
+			Format string   `json:"format"`
+		}
+	}
+
+	huma.Register(api, huma.Operation{
+		OperationID: "styles",
+		Method:      http.MethodGet,
+		Path:        "/items/{id}/{tags}/{colors}/{format}",
+	}, func(ctx context.Context, input *struct {
+		ID     int      `path:"id" style:"matrix" minimum:"1"`
+		Tags   []string `path:"tags" style:"matrix" explode:"true"`
+		Colors []string `path:"colors" style:"label" explode:"true"`
+		Format string   `path:"format" style:"label"`
+	}) (*Output, error) {
+		out := &Output{}
+		out.Body.ID = input.ID
+		out.Body.Tags = input.Tags
+		out.Body.Colors = input.Colors
+		out.Body.Format = input.Format
+		return out, nil
+	})
+
+	params := api.OpenAPI().Paths["/items/{id}/{tags}/{colors}/{format}"].Get.Parameters
+	assert.Equal(t, "matrix", params[0].Style)
+	assert.Nil(t, params[0].Explode)
+	assert.Equal(t, "matrix", params[1].Style)
+	assert.True(t, *params[1].Explode)
+	assert.Equal(t, "label", params[2].Style)
+
+	resp := api.Get("/items/;id=5/;tags=a;tags=b/.red.blue/.json")
+	assert.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+	assert.JSONEq(t, `{"$schema": "https:///schemas/OutputBody.json", "id": 5, "tags": ["a", "b"], "colors": ["red", "blue"], "format": "json"}`, resp.Body.String())
+
+	resp = api.Get("/items/5/;tags=a/.red/.json")
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+	assert.Contains(t, resp.Body.String(), "path.id")
+	assert.Contains(t, resp.Body.String(), "matrix style")
+
+	resp = api.Get("/items/;id=5/;tags=a;other=b/.red/.json")
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+	assert.Contains(t, resp.Body.String(), "path.tags")
+
+	resp = api.Get("/items/;id=0/;tags=a/.red/json")
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+	assert.Contains(t, resp.Body.String(), "path.id")
+	assert.Contains(t, resp.Body.String(), "path.format")
+
+	assert.Panics(t, func() {
+		huma.Register(api, huma.Operation{
+			OperationID: "bad-style",
+			Method:      http.MethodGet,
+			Path:        "/bad/{id}",
+		}, func(ctx context.Context, input *struct {
+			ID string `path:"id" style:"form"`
+		}) (*struct{}, error) {
+			return nil, nil
+		})
+	})
+}
+
+type PageParams struct {
+	Size  int    `query:"size" default:"20" maximum:"100"`
+	Token string `query:"token"`
+}
+
+func TestQueryPrefix(t *testing.T) {
+	_, api := humatest.New(t, huma.DefaultConfig("Test API", "1.0.0"))
+
+	huma.Register(api, huma.Operation{
+		OperationID: "list",
+		Method:      http.MethodGet,
+		Path:        "/items",
+	}, func(ctx context.Context, input *struct {
+		Page   PageParams `queryPrefix:"page_"`
+		Parent struct {
+			Page PageParams `queryPrefix:"page_"`
+		} `queryPrefix:"parent_"`
+		Sort string `query:"sort"`
+	}) (*struct{ Body []any }, error) {
+		return &struct{ Body []any }{Body: []any{input.Page.Size, input.Page.Token, input.Parent.Page.Size, input.Sort}}, nil
+	})
+
+	names := []string{}
+	for _, p := range api.OpenAPI().Paths["/items"].Get.Parameters {
+		names = append(names, p.Name)
+	}
+	assert.Equal(t, []string{"page_size", "page_token", "parent_page_size", "parent_page_token", "sort"}, names)
+
+	resp := api.Get("/items?page_size=10&page_token=abc&parent_page_size=5&sort=name")
+	assert.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+	assert.JSONEq(t, `[10, "abc", 5, "name"]`, resp.Body.String())
+
+	resp = api.Get("/items?size=10")
+	assert.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+	assert.JSONEq(t, `[20, "", 20, ""]`, resp.Body.String())
+
+	resp = api.Get("/items?page_size=1000")
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+	assert.Contains(t, resp.Body.String(), "query.page_size")
+
+	assert.Panics(t, func() {
+		huma.Register(api, huma.Operation{
+			OperationID: "bad-prefix",
+			Method:      http.MethodGet,
+			Path:        "/bad",
+		}, func(ctx context.Context, input *struct {
+			Size int `query:"size" queryPrefix:"page_"`
+		}) (*struct{}, error) {
+			return nil, nil
+		})
+	})
+}
+
+type RawPayload struct {
+	Name  string `json:"name" minLength:"3"`
+	Count int    `json:"count" minimum:"1"`
+}
+
+// ProxiedPayload defers parsing like `json.RawMessage` while documenting the
+// payload's structure.
+type ProxiedPayload struct {
+	json.RawMessage
+}
+
+func (ProxiedPayload) Schema(r huma.Registry) *huma.Schema {
+	return r.Schema(reflect.TypeOf(RawPayload{}), true, "")
+}
+
+func TestRawMessageSchemaName(t *testing.T) {
+	_, api := humatest.New(t, huma.DefaultConfig("Test API", "1.0.0"))
+	api.OpenAPI().Components.Schemas.Schema(reflect.TypeOf(RawPayload{}), true, "")
+
+	var received []byte
+	huma.Register(api, huma.Operation{
+		OperationID: "proxy",
+		Method:      http.MethodPost,
+		Path:        "/proxy",
+	}, func(ctx context.Context, input *struct {
+		Body json.RawMessage `schemaName:"RawPayload"`
+	}) (*struct {
+		Body json.RawMessage `schemaName:"RawPayload"`
+	}, error) {
+		received = input.Body
+		return &struct {
+			Body json.RawMessage `schemaName:"RawPayload"`
+		}{Body: input.Body}, nil
+	})
+
+	huma.Register(api, huma.Operation{
+		OperationID: "proxy-provider",
+		Method:      http.MethodPost,
+		Path:        "/proxy-provider",
+	}, func(ctx context.Context, input *struct {
+		Body ProxiedPayload
+	}) (*struct{}, error) {
+		received = input.Body.RawMessage
+		return nil, nil
+	})
+
+	huma.Register(api, huma.Operation{
+		OperationID: "raw",
+		Method:      http.MethodPost,
+		Path:        "/raw",
+	}, func(ctx context.Context, input *struct {
+		Body json.RawMessage
+	}) (*struct{}, error) {
+		received = input.Body
+		return nil, nil
+	})
+
+	op := api.OpenAPI().Paths["/proxy"].Post
+	assert.Equal(t, "#/components/schemas/RawPayload", op.RequestBody.Content["application/json"].Schema.Ref)
+	assert.Equal(t, "#/components/schemas/RawPayload", op.Responses["200"].Content["application/json"].Schema.Ref)
+
+	// The handler gets the raw body, which is still validated.
+	resp := api.Post("/proxy", map[string]any{"name": "abc", "count": 2})
+	assert.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+	assert.JSONEq(t, `{"name": "abc", "count": 2}`, string(received))
+	assert.JSONEq(t, `{"name": "abc", "count": 2}`, resp.Body.String())
+
+	resp = api.Post("/proxy", map[string]any{"name": "a", "count": 0})
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+	assert.Contains(t, resp.Body.String(), "body.name")
+	assert.Contains(t, resp.Body.String(), "body.count")
+
+	// Schema providers work the same way.
+	resp = api.Post("/proxy-provider", map[string]any{"name": "abc", "count": 2})
+	assert.Equal(t, http.StatusNoContent, resp.Code, resp.Body.String())
+	assert.JSONEq(t, `{"name": "abc", "count": 2}`, string(received))
+
+	resp = api.Post("/proxy-provider", map[string]any{"name": "a", "count": 2})
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+
+	// Without a schema, any JSON is accepted.
+	resp = api.Post("/raw", []any{1, "two"})
+	assert.Equal(t, http.StatusNoContent, resp.Code, resp.Body.String())
+	assert.JSONEq(t, `[1, "two"]`, string(received))
+
+	assert.Panics(t, func() {
+		huma.Register(api, huma.Operation{
+			OperationID: "missing",
+			Method:      http.MethodPost,
+			Path:        "/missing",
+		}, func(ctx context.Context, input *struct {
+			Body json.RawMessage `schemaName:"Missing"`
+		}) (*struct{}, error) {
+			return nil, nil
+		})
+	})
+}
+
+type pageParams struct {
+	Cursor string `query:"cursor"`
+	Limit  int    `query:"limit" default:"10"`
+}
+
+// ListParams is a reusable group of params, which itself embeds an
+// unexported group.
+type ListParams struct {
+	pageParams
+	Sort string `query:"sort"`
+}
+
+type AuthHeaders struct {
+	Authorization string `header:"Authorization"`
+
+	user string
+	runs int
+}
+
+func (a *AuthHeaders) Resolve(ctx huma.Context) []error {
+	a.runs++
+	a.user = strings.TrimPrefix(a.Authorization, "Bearer ")
+	if a.user == "" {
+		return []error{&huma.ErrorDetail{Location: "header.Authorization", Message: "missing user"}}
+	}
+	return nil
+}
+
+func TestEmbeddedParamGroups(t *testing.T) {
+	_, api := humatest.New(t, huma.DefaultConfig("Test API", "1.0.0"))
+
+	huma.Register(api, huma.Operation{
+		OperationID: "list-things",
+		Method:      http.MethodGet,
+		Path:        "/things",
+	}, func(ctx context.Context, input *struct {
+		*AuthHeaders
+		ListParams
+
+		// Replaces the promoted param with different validation.
+		Limit int `query:"limit" maximum:"50"`
+	}) (*struct{ Body map[string]any }, error) {
+		return &struct{ Body map[string]any }{Body: map[string]any{
+			"user":   input.user,
+			"runs":   input.runs,
+			"cursor": input.Cursor,
+			"limit":  input.Limit,
+			"sort":   input.Sort,
+		}}, nil
+	})
+
+	names := []string{}
+	for _, p := range api.OpenAPI().Paths["/things"].Get.Parameters {
+		names = append(names, p.In+"."+p.Name)
+		if p.Name == "limit" {
+			assert.Equal(t, 50.0, *p.Schema.Maximum)
+		}
+	}
+	assert.Equal(t, []string{"header.Authorization", "query.cursor", "query.sort", "query.limit"}, names)
+
+	resp := api.Get("/things?cursor=abc&limit=20&sort=name", "Authorization: Bearer alice")
+	assert.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+	assert.JSONEq(t, `{"user": "alice", "runs": 1, "cursor": "abc", "limit": 20, "sort": "name"}`, resp.Body.String())
+
+	resp = api.Get("/things?limit=100", "Authorization: Bearer alice")
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+
+	resp = api.Get("/things")
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+	assert.Contains(t, resp.Body.String(), "missing user")
+
+	type Paging struct {
+		Next string `query:"cursor"`
+	}
+
+	assert.Panics(t, func() {
+		huma.Register(api, huma.Operation{
+			OperationID: "duplicate",
+			Method:      http.MethodGet,
+			Path:        "/duplicate",
+		}, func(ctx context.Context, input *struct {
+			ListParams
+			Paging
+		}) (*struct{}, error) {
+			return nil, nil
+		})
+	})
+}
@@ -0,0 +1,343 @@
+// Package humaclient provides a Go client for calling the operations of a
+// Huma API using the same input and output structs as the server handlers.
+// It is driven by the operations registered with the API, so no code
+// generation is needed. This is useful for internal service-to-service calls
+// and for tests.
+//
+//	client := humaclient.New(api, "https://api.example.com")
+//	out, err := humaclient.Call[GreetingInput, GreetingOutput](ctx, client, "get-greeting", &GreetingInput{
+//		Name: "world",
+//	})
+package humaclient
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// Doer sends an HTTP request and returns the response. It is implemented by
+// `*http.Client`.
+type Doer interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// Client calls operations registered with a Huma API over HTTP.
+type Client struct {
+	api     huma.API
+	baseURL string
+
+	// HTTPClient is used to send requests. Defaults to `http.DefaultClient`.
+	HTTPClient Doer
+
+	// ContentType is the content type used to send request bodies and the
+	// preferred response content type. It must be one of the API's formats.
+	// Defaults to `application/json`.
+	ContentType string
+
+	once sync.Once
+	ops  map[string]*huma.Operation
+}
+
+// New creates a new client for the given API which sends requests to the
+// server at `baseURL`, e.g. `https://api.example.com`. Operations must be
+// registered with the API before any calls are made.
+func New(api huma.API, baseURL string) *Client {
+	return &Client{
+		api:         api,
+		baseURL:     strings.TrimSuffix(baseURL, "/"),
+		HTTPClient:  http.DefaultClient,
+		ContentType: "application/json",
+	}
+}
+
+// Operation returns the registered operation with the given ID, or nil if
+// no such operation exists.
+func (c *Client) Operation(operationID string) *huma.Operation {
+	c.once.Do(func() {
+		c.ops = map[string]*huma.Operation{}
+		for _, item := range c.api.OpenAPI().Paths {
+			for _, op := range []*huma.Operation{item.Get, item.Put, item.Post, item.Delete, item.Options, item.Head, item.Patch, item.Trace} {
+				if op != nil && op.OperationID != "" {
+					c.ops[op.OperationID] = op
+				}
+			}
+		}
+	})
+	return c.ops[operationID]
+}
+
+// Call an operation by ID with the given input, returning the decoded
+// output. The input and output types should be the same as those used by the
+// operation handler on the server. Error responses are returned as a
+// `*huma.ErrorModel` which satisfies `huma.StatusError`.
+//
+//	out, err := humaclient.Call[GreetingInput, GreetingOutput](ctx, client, "get-greeting", &GreetingInput{
+//		Name: "world",
+//	})
+func Call[I, O any](ctx context.Context, c *Client, operationID string, input *I) (*O, error) {
+	op := c.Operation(operationID)
+	if op == nil {
+		return nil, fmt.Errorf("unknown operation %s", operationID)
+	}
+
+	req, err := c.newRequest(ctx, op, reflect.ValueOf(input))
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode >= 400 {
+		model := &huma.ErrorModel{}
+		if len(body) > 0 {
+			if err := c.api.Unmarshal(resp.Header.Get("Content-Type"), body, model); err != nil {
+				model.Detail = string(body)
+			}
+		}
+		model.Status = resp.StatusCode
+		if model.Title == "" {
+			model.Title = http.StatusText(resp.StatusCode)
+		}
+		return nil, model
+	}
+
+	var output O
+	if err := c.decodeResponse(resp, body, reflect.ValueOf(&output).Elem()); err != nil {
+		return nil, err
+	}
+	return &output, nil
+}
+
+// newRequest creates an HTTP request for the operation from the input struct.
+func (c *Client) newRequest(ctx context.Context, op *huma.Operation, input reflect.Value) (*http.Request, error) {
+	path := op.Path
+	query := url.Values{}
+	headers := http.Header{}
+	var cookies []*http.Cookie
+	var body io.Reader
+
+	v := reflect.Indirect(input)
+	if v.IsValid() {
+		if v.Kind() != reflect.Struct {
+			return nil, fmt.Errorf("input must be a struct")
+		}
+
+		for _, f := range reflect.VisibleFields(v.Type()) {
+			if f.Anonymous || !f.IsExported() {
+				continue
+			}
+			fv := v.FieldByIndex(f.Index)
+
+			if name := f.Tag.Get("path"); name != "" {
+				path = strings.ReplaceAll(path, "{"+name+"}", url.PathEscape(formatValue(fv, f.Tag.Get("timeFormat"), time.RFC3339Nano)))
+			} else if name := f.Tag.Get("query"); name != "" {
+				if !fv.IsZero() {
+					query.Set(name, formatValue(fv, f.Tag.Get("timeFormat"), time.RFC3339Nano))
+				}
+			} else if name := f.Tag.Get("header"); name != "" {
+				if !fv.IsZero() {
+					headers.Set(name, formatValue(fv, f.Tag.Get("timeFormat"), http.TimeFormat))
+				}
+			} else if name := f.Tag.Get("cookie"); name != "" {
+				if !fv.IsZero() {
+					if c, ok := fv.Interface().(http.Cookie); ok {
+						cookies = append(cookies, &http.Cookie{Name: name, Value: c.Value})
+					} else {
+						cookies = append(cookies, &http.Cookie{Name: name, Value: formatValue(fv, f.Tag.Get("timeFormat"), time.RFC3339Nano)})
+					}
+				}
+			} else if f.Name == "RawBody" && fv.Kind() == reflect.Slice {
+				ct := "application/octet-stream"
+				if t := f.Tag.Get("contentType"); t != "" {
+					ct = t
+				}
+				headers.Set("Content-Type", ct)
+				body = bytes.NewReader(fv.Bytes())
+			} else if f.Name == "Body" && len(f.Index) == 1 {
+				if (fv.Kind() == reflect.Pointer || fv.Kind() == reflect.Interface) && fv.IsNil() {
+					continue
+				}
+				buf := &bytes.Buffer{}
+				if err := c.api.Marshal(buf, c.ContentType, fv.Interface()); err != nil {
+					return nil, err
+				}
+				headers.Set("Content-Type", c.ContentType)
+				body = buf
+			}
+		}
+	}
+
+	u := c.baseURL + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, op.Method, u, body)
+	if err != nil {
+		return nil, err
+	}
+	for k, values := range headers {
+		req.Header[k] = values
+	}
+	for _, c := range cookies {
+		req.AddCookie(c)
+	}
+	if req.Header.Get("Accept") == "" {
+		req.Header.Set("Accept", c.ContentType)
+	}
+	return req, nil
+}
+
+// decodeResponse sets the output struct's status, headers, and body from the
+// HTTP response.
+func (c *Client) decodeResponse(resp *http.Response, body []byte, out reflect.Value) error {
+	if out.Kind() != reflect.Struct {
+		return fmt.Errorf("output must be a struct")
+	}
+
+	for _, f := range reflect.VisibleFields(out.Type()) {
+		if f.Anonymous || !f.IsExported() {
+			continue
+		}
+		fv := out.FieldByIndex(f.Index)
+
+		switch f.Name {
+		case "Status":
+			if fv.Kind() == reflect.Int {
+				fv.SetInt(int64(resp.StatusCode))
+			}
+			continue
+		case "Body":
+			if len(body) == 0 || fv.Kind() == reflect.Func {
+				continue
+			}
+			if fv.Kind() == reflect.Slice && fv.Type().Elem().Kind() == reflect.Uint8 {
+				fv.SetBytes(body)
+				continue
+			}
+			if err := c.api.Unmarshal(resp.Header.Get("Content-Type"), body, fv.Addr().Interface()); err != nil {
+				return err
+			}
+			continue
+		}
+
+		name := f.Tag.Get("header")
+		if name == "" {
+			name = f.Name
+		}
+		value := resp.Header.Get(name)
+		if value == "" {
+			continue
+		}
+		if err := parseValue(fv, value, f.Tag.Get("timeFormat"), http.TimeFormat); err != nil {
+			return fmt.Errorf("invalid header %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// formatValue converts a parameter value into its string representation.
+func formatValue(v reflect.Value, timeFormat, defaultTimeFormat string) string {
+	if v.Type() == timeType {
+		if timeFormat == "" {
+			timeFormat = defaultTimeFormat
+		}
+		return v.Interface().(time.Time).Format(timeFormat)
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return v.String()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10)
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 64)
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool())
+	case reflect.Slice:
+		parts := make([]string, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			parts[i] = formatValue(v.Index(i), timeFormat, defaultTimeFormat)
+		}
+		return strings.Join(parts, ",")
+	}
+	return fmt.Sprintf("%v", v.Interface())
+}
+
+// parseValue sets a field from its string representation.
+func parseValue(v reflect.Value, value, timeFormat, defaultTimeFormat string) error {
+	if v.Type() == timeType {
+		if timeFormat == "" {
+			timeFormat = defaultTimeFormat
+		}
+		t, err := time.Parse(timeFormat, value)
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(t))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(value)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return err
+		}
+		v.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		i, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return err
+		}
+		v.SetUint(i)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Slice:
+		parts := strings.Split(value, ",")
+		s := reflect.MakeSlice(v.Type(), len(parts), len(parts))
+		for i, part := range parts {
+			if err := parseValue(s.Index(i), strings.TrimSpace(part), timeFormat, defaultTimeFormat); err != nil {
+				return err
+			}
+		}
+		v.Set(s)
+	}
+	return nil
+}
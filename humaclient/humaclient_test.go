@@ -0,0 +1,112 @@
+package humaclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/humatest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type GreetingInput struct {
+	ID      string    `path:"id"`
+	Num     int       `query:"num"`
+	Tags    []string  `query:"tags"`
+	Since   time.Time `header:"If-Modified-Since"`
+	Request string    `header:"X-Request"`
+	Session string    `cookie:"session"`
+	Body    struct {
+		Suffix string `json:"suffix" maxLength:"5"`
+	}
+}
+
+type GreetingOutput struct {
+	Status       int
+	ETag         string    `header:"ETag"`
+	LastModified time.Time `header:"Last-Modified"`
+	Count        int       `header:"X-Count"`
+	Body         struct {
+		Greeting string   `json:"greeting"`
+		Tags     []string `json:"tags"`
+		Request  string   `json:"request"`
+	}
+}
+
+func TestCall(t *testing.T) {
+	lastModified := time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	_, api := humatest.New(t)
+	huma.Register(api, huma.Operation{
+		OperationID:   "greet",
+		Method:        http.MethodPost,
+		Path:          "/greet/{id}",
+		DefaultStatus: http.StatusCreated,
+	}, func(ctx context.Context, input *GreetingInput) (*GreetingOutput, error) {
+		if input.ID == "error" {
+			return nil, huma.Error409Conflict("already greeted")
+		}
+		assert.True(t, input.Since.Equal(lastModified))
+
+		resp := &GreetingOutput{}
+		resp.Status = http.StatusCreated
+		resp.ETag = "abc123"
+		resp.LastModified = lastModified
+		resp.Count = input.Num
+		resp.Body.Greeting = "Hello, " + input.ID + input.Body.Suffix
+		resp.Body.Tags = input.Tags
+		resp.Body.Request = input.Request + input.Session
+		return resp, nil
+	})
+
+	server := httptest.NewServer(api.Adapter())
+	defer server.Close()
+
+	client := New(api, server.URL+"/")
+
+	out, err := Call[GreetingInput, GreetingOutput](context.Background(), client, "greet", &GreetingInput{
+		ID:      "world",
+		Num:     5,
+		Tags:    []string{"a", "b"},
+		Since:   lastModified,
+		Request: "req-1",
+		Session: "-abc",
+		Body: struct {
+			Suffix string `json:"suffix" maxLength:"5"`
+		}{Suffix: "!"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, out.Status)
+	assert.Equal(t, "abc123", out.ETag)
+	assert.True(t, out.LastModified.Equal(lastModified))
+	assert.Equal(t, 5, out.Count)
+	assert.Equal(t, "Hello, world!", out.Body.Greeting)
+	assert.Equal(t, []string{"a", "b"}, out.Body.Tags)
+	assert.Equal(t, "req-1-abc", out.Body.Request)
+
+	// Errors are returned as status errors.
+	_, err = Call[GreetingInput, GreetingOutput](context.Background(), client, "greet", &GreetingInput{ID: "error"})
+	require.Error(t, err)
+	var se huma.StatusError
+	require.ErrorAs(t, err, &se)
+	assert.Equal(t, http.StatusConflict, se.GetStatus())
+	assert.Equal(t, "already greeted", se.Error())
+
+	// Validation errors from the server are passed through.
+	_, err = Call[GreetingInput, GreetingOutput](context.Background(), client, "greet", &GreetingInput{
+		ID: "world",
+		Body: struct {
+			Suffix string `json:"suffix" maxLength:"5"`
+		}{Suffix: "too long"},
+	})
+	require.Error(t, err)
+	require.ErrorAs(t, err, &se)
+	assert.Equal(t, http.StatusUnprocessableEntity, se.GetStatus())
+
+	_, err = Call[GreetingInput, GreetingOutput](context.Background(), client, "missing", &GreetingInput{})
+	assert.ErrorContains(t, err, "unknown operation")
+}
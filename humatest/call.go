@@ -0,0 +1,222 @@
+package humatest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+var (
+	timeType   = reflect.TypeOf(time.Time{})
+	cookieType = reflect.TypeOf(http.Cookie{})
+	bytesType  = reflect.TypeOf([]byte{})
+)
+
+// Call makes a request to the operation with the given ID and decodes the
+// response into its output type. The request is built from the input struct
+// the same way Huma reads it: `path`, `query`, `header`, and `cookie` fields
+// become parameters and the `Body` field is sent as JSON (or as-is if it is
+// a `[]byte`). Zero-valued query, header, and cookie parameters are omitted.
+//
+// The output's `Status` field, header fields, and `Body` are decoded from the
+// response. If the response is an error (status code 400 or above) then the
+// output is nil and the error can be read from the returned recorder.
+//
+//	out, resp := humatest.Call[GetThingInput, GetThingOutput](t, api, "get-thing", &GetThingInput{
+//		ID: "abc123",
+//	})
+//	assert.Equal(t, http.StatusOK, resp.Code)
+//	assert.Equal(t, "abc123", out.Body.ID)
+//
+// Call panics if the operation is not in the OpenAPI or the input or output
+// cannot be encoded or decoded.
+func Call[I, O any](tb TB, api TestAPI, operationID string, input *I) (*O, *httptest.ResponseRecorder) {
+	tb.Helper()
+
+	op := findOperation(api.OpenAPI(), operationID)
+	if op == nil {
+		panic("operation " + operationID + " not found")
+	}
+
+	path := op.Path
+	query := url.Values{}
+	args := []any{}
+	cookies := []string{}
+	if input != nil {
+		eachField(reflect.ValueOf(input).Elem(), func(f reflect.StructField, v reflect.Value) {
+			if name := f.Tag.Get("path"); name != "" {
+				path = strings.ReplaceAll(path, "{"+name+"}", url.PathEscape(formatParam(f, v, time.RFC3339Nano)))
+				return
+			}
+			if f.Name == "Body" || f.Name == "RawBody" {
+				if v.Kind() == reflect.Pointer && v.IsNil() {
+					return
+				}
+				if f.Type == bytesType {
+					args = append(args, bytes.NewReader(v.Bytes()))
+					return
+				}
+				encoded, err := json.Marshal(v.Interface())
+				if err != nil {
+					panic(err)
+				}
+				args = append(args, "Content-Type: application/json", bytes.NewReader(encoded))
+				return
+			}
+			if v.IsZero() {
+				return
+			}
+			if name := f.Tag.Get("query"); name != "" {
+				query.Set(name, formatParam(f, v, time.RFC3339Nano))
+			} else if name := f.Tag.Get("header"); name != "" {
+				args = append(args, name+": "+formatParam(f, v, http.TimeFormat))
+			} else if name := f.Tag.Get("cookie"); name != "" {
+				cookies = append(cookies, name+"="+formatParam(f, v, time.RFC3339Nano))
+			}
+		})
+	}
+	if len(cookies) > 0 {
+		args = append(args, "Cookie: "+strings.Join(cookies, "; "))
+	}
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+
+	resp := api.Do(op.Method, path, args...)
+	if resp.Code >= http.StatusBadRequest {
+		return nil, resp
+	}
+
+	var output O
+	eachField(reflect.ValueOf(&output).Elem(), func(f reflect.StructField, v reflect.Value) {
+		switch {
+		case f.Name == "Status" && v.Kind() == reflect.Int:
+			v.SetInt(int64(resp.Code))
+		case f.Name == "Body":
+			if f.Type == bytesType {
+				v.SetBytes(resp.Body.Bytes())
+			} else if resp.Body.Len() > 0 {
+				if err := json.Unmarshal(resp.Body.Bytes(), v.Addr().Interface()); err != nil {
+					panic(err)
+				}
+			}
+		default:
+			name := f.Tag.Get("header")
+			if name == "" {
+				name = f.Name
+			}
+			if values := resp.Header().Values(name); len(values) > 0 {
+				setHeader(f, v, values)
+			}
+		}
+	})
+
+	return &output, resp
+}
+
+// findOperation returns the operation with the given ID from the OpenAPI.
+func findOperation(oapi *huma.OpenAPI, operationID string) *huma.Operation {
+	for _, item := range oapi.Paths {
+		for _, op := range []*huma.Operation{item.Get, item.Put, item.Post, item.Delete, item.Options, item.Head, item.Patch, item.Trace} {
+			if op != nil && op.OperationID == operationID {
+				return op
+			}
+		}
+	}
+	return nil
+}
+
+// eachField calls `fn` for each exported field of a struct, including the
+// fields of embedded structs.
+func eachField(v reflect.Value, fn func(reflect.StructField, reflect.Value)) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Anonymous && f.Type.Kind() == reflect.Struct {
+			eachField(v.Field(i), fn)
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+		fn(f, v.Field(i))
+	}
+}
+
+// formatParam formats a parameter value as a string, joining slices with
+// commas as Huma expects.
+func formatParam(f reflect.StructField, v reflect.Value, timeFormat string) string {
+	switch {
+	case v.Type() == timeType:
+		if tf := f.Tag.Get("timeFormat"); tf != "" {
+			timeFormat = tf
+		}
+		return v.Interface().(time.Time).Format(timeFormat)
+	case v.Type() == cookieType:
+		return v.Interface().(http.Cookie).Value
+	case v.Kind() == reflect.Slice:
+		items := make([]string, v.Len())
+		for i := range items {
+			items[i] = formatParam(f, v.Index(i), timeFormat)
+		}
+		return strings.Join(items, ",")
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+// setHeader sets an output field from its response header values.
+func setHeader(f reflect.StructField, v reflect.Value, values []string) {
+	if v.Kind() == reflect.Slice && v.Type() != bytesType {
+		s := reflect.MakeSlice(v.Type(), len(values), len(values))
+		for i, value := range values {
+			setHeader(f, s.Index(i), []string{value})
+		}
+		v.Set(s)
+		return
+	}
+
+	value := values[0]
+	var err error
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(value)
+	case reflect.Bool:
+		var b bool
+		b, err = strconv.ParseBool(value)
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var i int64
+		i, err = strconv.ParseInt(value, 10, 64)
+		v.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		var u uint64
+		u, err = strconv.ParseUint(value, 10, 64)
+		v.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		var fl float64
+		fl, err = strconv.ParseFloat(value, 64)
+		v.SetFloat(fl)
+	default:
+		if v.Type() == timeType {
+			timeFormat := http.TimeFormat
+			if tf := f.Tag.Get("timeFormat"); tf != "" {
+				timeFormat = tf
+			}
+			var t time.Time
+			t, err = time.Parse(timeFormat, value)
+			v.Set(reflect.ValueOf(t))
+		}
+	}
+	if err != nil {
+		panic(fmt.Errorf("unable to decode header %s: %w", f.Name, err))
+	}
+}
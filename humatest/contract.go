@@ -0,0 +1,22 @@
+package humatest
+
+import (
+	"testing"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// ValidateResponses returns a copy of the config which validates every
+// response against the operation's declared responses in the OpenAPI and
+// fails the test when a handler drifts from the spec, e.g. by returning an
+// undocumented status code or a body which does not match its schema.
+//
+//	_, api := humatest.New(t, humatest.ValidateResponses(t, huma.DefaultConfig("My API", "1.0.0")))
+func ValidateResponses(tb testing.TB, config huma.Config) huma.Config {
+	config.ValidateResponses = true
+	config.OnResponseViolation = func(ctx huma.Context, status int, errs []error) {
+		tb.Helper()
+		tb.Errorf("%s %s response %d does not match the OpenAPI: %v", ctx.Method(), ctx.Operation().Path, status, errs)
+	}
+	return config
+}
@@ -0,0 +1,227 @@
+package humatest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// namedExample is a single example value from the OpenAPI.
+type namedExample struct {
+	name  string
+	value any
+}
+
+// mediaExamples returns the examples declared on a media type and its schema.
+func mediaExamples(registry huma.Registry, mt *huma.MediaType) []namedExample {
+	examples := []namedExample{}
+	if mt.Example != nil {
+		examples = append(examples, namedExample{"example", mt.Example})
+	}
+	names := make([]string, 0, len(mt.Examples))
+	for name := range mt.Examples {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if e := mt.Examples[name]; e != nil && e.Value != nil {
+			examples = append(examples, namedExample{name, e.Value})
+		}
+	}
+	if s := resolve(registry, mt.Schema); s != nil {
+		for i, v := range s.Examples {
+			examples = append(examples, namedExample{fmt.Sprintf("schema-%d", i), v})
+		}
+	}
+	return examples
+}
+
+func resolve(registry huma.Registry, s *huma.Schema) *huma.Schema {
+	for s != nil && s.Ref != "" {
+		s = registry.SchemaFromRef(s.Ref)
+	}
+	return s
+}
+
+// paramExample returns an example value for a parameter, falling back to the
+// schema's examples or default and then to a generated value for required
+// parameters.
+func paramExample(gen *Generator, registry huma.Registry, p *huma.Param) (any, bool) {
+	if p.Example != nil {
+		return p.Example, true
+	}
+	names := make([]string, 0, len(p.Examples))
+	for name := range p.Examples {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if e := p.Examples[name]; e != nil && e.Value != nil {
+			return e.Value, true
+		}
+	}
+	if s := resolve(registry, p.Schema); s != nil {
+		if len(s.Examples) > 0 {
+			return s.Examples[0], true
+		}
+		if s.Default != nil {
+			return s.Default, true
+		}
+		if p.Required {
+			return gen.Valid(p.Schema), true
+		}
+	}
+	return nil, false
+}
+
+// formatExample formats an example parameter value, joining arrays with
+// commas as Huma expects.
+func formatExample(v any) string {
+	if items, ok := v.([]any); ok {
+		parts := make([]string, len(items))
+		for i, item := range items {
+			parts[i] = fmt.Sprint(item)
+		}
+		return strings.Join(parts, ",")
+	}
+	return fmt.Sprint(v)
+}
+
+// CheckExamples keeps the examples in your API's documentation correct. It
+// runs a subtest for every operation in the OpenAPI which validates each
+// declared response example against its response schema and sends each
+// declared request body example to the operation, failing if the request is
+// rejected as invalid (a `400` or the operation's validation error status)
+// or results in a server error. Parameters use their declared examples,
+// defaults, or generated values if required.
+//
+//	func TestExamples(t *testing.T) {
+//		_, api := humatest.New(t)
+//		addRoutes(api)
+//		humatest.CheckExamples(t, api)
+//	}
+func CheckExamples(t *testing.T, api TestAPI) {
+	t.Helper()
+
+	oapi := api.OpenAPI()
+	registry := oapi.Components.Schemas
+	gen := NewGenerator(registry, 1)
+
+	paths := make([]string, 0, len(oapi.Paths))
+	for path := range oapi.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		item := oapi.Paths[path]
+		for _, op := range []*huma.Operation{item.Get, item.Put, item.Post, item.Delete, item.Options, item.Head, item.Patch, item.Trace} {
+			if op == nil {
+				continue
+			}
+			name := op.OperationID
+			if name == "" {
+				name = op.Method + " " + op.Path
+			}
+			t.Run(name, func(t *testing.T) {
+				checkResponseExamples(t, registry, op)
+				checkRequestExamples(t, api, gen, registry, op)
+			})
+		}
+	}
+}
+
+func checkResponseExamples(t *testing.T, registry huma.Registry, op *huma.Operation) {
+	t.Helper()
+	codes := make([]string, 0, len(op.Responses))
+	for code := range op.Responses {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+
+	for _, code := range codes {
+		resp := op.Responses[code]
+		for ct, mt := range resp.Content {
+			if mt == nil || mt.Schema == nil {
+				continue
+			}
+			for _, e := range mediaExamples(registry, mt) {
+				res := &huma.ValidateResult{}
+				pb := huma.NewPathBuffer([]byte{}, 0)
+				huma.Validate(registry, mt.Schema, pb, huma.ModeReadFromServer, roundTrip(e.value), res)
+				for _, err := range res.Errors {
+					t.Errorf("response %s %s example %s is invalid: %v", code, ct, e.name, err)
+				}
+			}
+		}
+	}
+}
+
+func checkRequestExamples(t *testing.T, api TestAPI, gen *Generator, registry huma.Registry, op *huma.Operation) {
+	t.Helper()
+	if op.RequestBody == nil {
+		return
+	}
+
+	path := op.Path
+	query := url.Values{}
+	args := []any{}
+	for _, p := range op.Parameters {
+		v, ok := paramExample(gen, registry, p)
+		if !ok {
+			continue
+		}
+		value := formatExample(v)
+		switch p.In {
+		case "path":
+			path = strings.ReplaceAll(path, "{"+p.Name+"}", url.PathEscape(value))
+		case "query":
+			query.Set(p.Name, value)
+		case "header":
+			args = append(args, p.Name+": "+value)
+		case "cookie":
+			args = append(args, "Cookie: "+p.Name+"="+value)
+		}
+	}
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+
+	for ct, mt := range op.RequestBody.Content {
+		if mt == nil || !strings.HasSuffix(ct, "json") {
+			continue
+		}
+		for _, e := range mediaExamples(registry, mt) {
+			body, err := json.Marshal(e.value)
+			if err != nil {
+				t.Errorf("request %s example %s cannot be marshaled: %v", ct, e.name, err)
+				continue
+			}
+			resp := Wrap(t, api).Do(op.Method, path, append([]any{"Content-Type: " + ct, bytes.NewReader(body)}, args...)...)
+			if resp.Code == http.StatusBadRequest || resp.Code == op.ValidationErrorStatus || resp.Code >= http.StatusInternalServerError {
+				t.Errorf("request %s example %s was rejected with %d: %s", ct, e.name, resp.Code, resp.Body.String())
+			}
+		}
+	}
+}
+
+// roundTrip converts an example to its JSON representation, e.g. structs to
+// maps, so it can be validated like a real response.
+func roundTrip(v any) any {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return v
+	}
+	var out any
+	if err := json.Unmarshal(b, &out); err != nil {
+		return v
+	}
+	return out
+}
@@ -0,0 +1,268 @@
+package humatest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// FixtureIgnoredHeaders are headers which vary between otherwise identical
+// requests, so they are not saved to fixtures or compared when replaying.
+var FixtureIgnoredHeaders = []string{
+	"Content-Length",
+	"Date",
+	"Server-Timing",
+	"Traceparent",
+	"X-Request-Id",
+}
+
+// FixtureRedactedHeaders are headers whose values are replaced with
+// `REDACTED` when recording fixtures, so credentials from real traffic are
+// not saved to disk.
+var FixtureRedactedHeaders = []string{
+	"Authorization",
+	"Cookie",
+	"Proxy-Authorization",
+	"Set-Cookie",
+	"X-Api-Key",
+}
+
+// Fixture is a recorded request and the response it received.
+type Fixture struct {
+	Request  FixtureRequest  `json:"request"`
+	Response FixtureResponse `json:"response"`
+}
+
+// FixtureRequest is a recorded request.
+type FixtureRequest struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header,omitempty"`
+	FixtureBody
+}
+
+// FixtureResponse is a recorded response.
+type FixtureResponse struct {
+	Status int         `json:"status"`
+	Header http.Header `json:"header,omitempty"`
+	FixtureBody
+}
+
+// FixtureBody is a recorded request or response body. JSON bodies are saved
+// as-is to keep fixtures readable and other bodies are saved as text.
+type FixtureBody struct {
+	Body     json.RawMessage `json:"body,omitempty"`
+	BodyText string          `json:"bodyText,omitempty"`
+}
+
+func newFixtureBody(b []byte) FixtureBody {
+	if len(b) == 0 {
+		return FixtureBody{}
+	}
+	if json.Valid(b) {
+		var buf bytes.Buffer
+		if json.Compact(&buf, b) == nil {
+			return FixtureBody{Body: buf.Bytes()}
+		}
+	}
+	return FixtureBody{BodyText: string(b)}
+}
+
+// Bytes returns the raw body.
+func (b FixtureBody) Bytes() []byte {
+	if len(b.Body) > 0 {
+		return b.Body
+	}
+	return []byte(b.BodyText)
+}
+
+// normalizeHeader returns a copy of the header without ignored headers and
+// with redacted header values replaced.
+func normalizeHeader(h http.Header) http.Header {
+	out := http.Header{}
+	for name, values := range h {
+		name = http.CanonicalHeaderKey(name)
+		if containsHeader(FixtureIgnoredHeaders, name) {
+			continue
+		}
+		if containsHeader(FixtureRedactedHeaders, name) {
+			values = []string{"REDACTED"}
+		}
+		out[name] = append([]string{}, values...)
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return out
+}
+
+func containsHeader(names []string, name string) bool {
+	for _, n := range names {
+		if strings.EqualFold(n, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// recordingWriter captures the response as it is written.
+type recordingWriter struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (w *recordingWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *recordingWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *recordingWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+var unsafeFileChars = regexp.MustCompile(`[^a-zA-Z0-9_-]+`)
+
+// Record wraps an HTTP handler, such as your router in a staging
+// environment, to save each request and its response as a JSON fixture file
+// in the given directory. The fixtures can then be replayed as a regression
+// suite with `Replay`. Headers in `FixtureIgnoredHeaders` are not saved and
+// headers in `FixtureRedactedHeaders` are redacted.
+//
+//	handler := humatest.Record("testdata/fixtures", router)
+//	http.ListenAndServe(":8888", handler)
+func Record(dir string, handler http.Handler) http.Handler {
+	var count atomic.Int64
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		rw := &recordingWriter{ResponseWriter: w}
+		handler.ServeHTTP(rw, r)
+
+		fixture := Fixture{
+			Request: FixtureRequest{
+				Method:      r.Method,
+				URL:         r.URL.RequestURI(),
+				Header:      normalizeHeader(r.Header),
+				FixtureBody: newFixtureBody(body),
+			},
+			Response: FixtureResponse{
+				Status:      rw.status,
+				Header:      normalizeHeader(rw.Header()),
+				FixtureBody: newFixtureBody(rw.body.Bytes()),
+			},
+		}
+
+		name := strings.Trim(unsafeFileChars.ReplaceAllString(r.URL.Path, "-"), "-")
+		filename := fmt.Sprintf("%04d-%s-%s.json", count.Add(1), strings.ToLower(r.Method), name)
+		if b, err := json.MarshalIndent(fixture, "", "  "); err == nil {
+			os.WriteFile(filepath.Join(dir, filename), b, 0o644)
+		}
+	})
+}
+
+// Replay sends the requests from the fixture files matching the glob pattern
+// to the API and checks that each response matches the recorded one. The
+// status code and body must match, with JSON bodies compared semantically,
+// and each recorded header must have the same values. Headers in
+// `FixtureIgnoredHeaders` or `FixtureRedactedHeaders` are not compared.
+//
+// Redacted request headers are not sent. Headers, given as strings like
+// `Authorization: Bearer abc123`, are sent with every request instead, e.g.
+// to authenticate with test credentials.
+//
+//	humatest.Replay(t, api, "testdata/fixtures/*.json", "Authorization: Bearer test")
+func Replay(t *testing.T, api TestAPI, pattern string, headers ...string) {
+	t.Helper()
+
+	files, err := filepath.Glob(pattern)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(files)
+
+	for _, file := range files {
+		t.Run(filepath.Base(file), func(t *testing.T) {
+			b, err := os.ReadFile(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var fixture Fixture
+			if err := json.Unmarshal(b, &fixture); err != nil {
+				t.Fatalf("invalid fixture: %v", err)
+			}
+			replayFixture(t, api, &fixture, headers)
+		})
+	}
+}
+
+func replayFixture(t *testing.T, api TestAPI, fixture *Fixture, headers []string) {
+	t.Helper()
+
+	args := []any{}
+	for name, values := range fixture.Request.Header {
+		if containsHeader(FixtureRedactedHeaders, name) || containsHeader(FixtureIgnoredHeaders, name) || len(values) == 0 {
+			continue
+		}
+		args = append(args, name+": "+values[0])
+	}
+	for _, h := range headers {
+		args = append(args, h)
+	}
+	if body := fixture.Request.Bytes(); len(body) > 0 {
+		args = append(args, bytes.NewReader(body))
+	}
+
+	resp := Wrap(t, api).Do(fixture.Request.Method, fixture.Request.URL, args...)
+
+	if resp.Code != fixture.Response.Status {
+		t.Errorf("expected status %d but got %d", fixture.Response.Status, resp.Code)
+	}
+
+	for name, expected := range fixture.Response.Header {
+		if containsHeader(FixtureRedactedHeaders, name) || containsHeader(FixtureIgnoredHeaders, name) {
+			continue
+		}
+		if actual := resp.Header().Values(name); !reflect.DeepEqual(expected, actual) {
+			t.Errorf("expected header %s to be %q but got %q", name, expected, actual)
+		}
+	}
+
+	expected := fixture.Response.Bytes()
+	actual := resp.Body.Bytes()
+	if len(fixture.Response.Body) > 0 {
+		var e, a any
+		if err := json.Unmarshal(expected, &e); err == nil {
+			if err := json.Unmarshal(actual, &a); err != nil || !reflect.DeepEqual(e, a) {
+				t.Errorf("expected body %s but got %s", expected, actual)
+			}
+			return
+		}
+	}
+	if !bytes.Equal(expected, actual) {
+		t.Errorf("expected body %q but got %q", expected, actual)
+	}
+}
@@ -0,0 +1,440 @@
+package humatest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand"
+	"net/http"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// maxGenerateDepth limits how deeply nested objects and arrays are generated,
+// which also keeps recursive schemas from generating forever.
+const maxGenerateDepth = 5
+
+// generateAttempts is how many random values are tried to find one which
+// passes validation, e.g. when a schema uses a `pattern`.
+const generateAttempts = 20
+
+// Generator produces request values from schemas for use with Go fuzzing and
+// property-based tests. Valid values satisfy the schema, while invalid values
+// each break a single constraint just past its boundary, e.g. a string one
+// character longer than `maxLength` or an object missing a required property.
+type Generator struct {
+	registry huma.Registry
+	rand     *rand.Rand
+}
+
+// NewGenerator creates a new generator which resolves schema references
+// using the given registry. The seed makes the generated values repeatable.
+func NewGenerator(registry huma.Registry, seed int64) *Generator {
+	return &Generator{
+		registry: registry,
+		rand:     rand.New(rand.NewSource(seed)),
+	}
+}
+
+func (g *Generator) resolve(s *huma.Schema) *huma.Schema {
+	return resolve(g.registry, s)
+}
+
+// validates returns whether the value passes validation against the schema.
+func (g *Generator) validates(s *huma.Schema, v any) bool {
+	res := &huma.ValidateResult{}
+	huma.Validate(g.registry, s, huma.NewPathBuffer([]byte{}, 0), huma.ModeWriteToServer, v, res)
+	return len(res.Errors) == 0
+}
+
+// Valid returns a random value which satisfies the schema. Schema examples
+// and defaults are used when no random value can be found, for example when
+// the schema has a `pattern`. Objects and arrays are returned as
+// `map[string]any` and `[]any` so they can be marshaled as JSON.
+func (g *Generator) Valid(s *huma.Schema) any {
+	var v any
+	for i := 0; i < generateAttempts; i++ {
+		v = g.valid(s, 0)
+		if g.validates(s, v) {
+			return v
+		}
+	}
+	if r := g.resolve(s); r != nil {
+		for _, example := range r.Examples {
+			if g.validates(s, example) {
+				return example
+			}
+		}
+		if r.Default != nil {
+			return r.Default
+		}
+	}
+	return v
+}
+
+func (g *Generator) valid(s *huma.Schema, depth int) any {
+	s = g.resolve(s)
+	if s == nil {
+		return nil
+	}
+
+	if len(s.Enum) > 0 {
+		return s.Enum[g.rand.Intn(len(s.Enum))]
+	}
+	if len(s.OneOf) > 0 {
+		return g.valid(s.OneOf[g.rand.Intn(len(s.OneOf))], depth)
+	}
+	if len(s.AnyOf) > 0 {
+		return g.valid(s.AnyOf[g.rand.Intn(len(s.AnyOf))], depth)
+	}
+	if len(s.AllOf) > 0 {
+		merged := map[string]any{}
+		for _, sub := range s.AllOf {
+			m, ok := g.valid(sub, depth).(map[string]any)
+			if !ok {
+				return g.valid(sub, depth)
+			}
+			for k, v := range m {
+				merged[k] = v
+			}
+		}
+		return merged
+	}
+
+	switch s.Type {
+	case huma.TypeBoolean:
+		return g.rand.Intn(2) == 0
+	case huma.TypeInteger, huma.TypeNumber:
+		return g.number(s)
+	case huma.TypeString:
+		return g.string(s)
+	case huma.TypeArray:
+		minItems, maxItems := bounds(s.MinItems, s.MaxItems, 3)
+		if depth >= maxGenerateDepth {
+			maxItems = minItems
+		}
+		items := make([]any, minItems+g.rand.Intn(maxItems-minItems+1))
+		for i := range items {
+			items[i] = g.valid(s.Items, depth+1)
+		}
+		return items
+	case huma.TypeObject:
+		obj := map[string]any{}
+		for _, name := range propertyNames(s) {
+			prop := s.Properties[name]
+			if p := g.resolve(prop); p != nil && p.ReadOnly {
+				continue
+			}
+			if !required(s, name) && (depth >= maxGenerateDepth || g.rand.Intn(2) == 0) {
+				continue
+			}
+			obj[name] = g.valid(prop, depth+1)
+		}
+		return obj
+	}
+	return nil
+}
+
+// bounds returns the minimum and maximum for a length or count, defaulting
+// the maximum to `spread` more than the minimum.
+func bounds(minimum, maximum *int, spread int) (int, int) {
+	lo, hi := 0, 0
+	if minimum != nil {
+		lo = *minimum
+	}
+	if maximum != nil {
+		hi = *maximum
+	} else {
+		hi = lo + spread
+	}
+	if hi < lo {
+		hi = lo
+	}
+	return lo, hi
+}
+
+// propertyNames returns the sorted property names of an object schema so
+// values are generated in a repeatable order.
+func propertyNames(s *huma.Schema) []string {
+	names := make([]string, 0, len(s.Properties))
+	for name := range s.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func required(s *huma.Schema, name string) bool {
+	for _, r := range s.Required {
+		if r == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (g *Generator) number(s *huma.Schema) float64 {
+	lo, hi := math.Inf(-1), math.Inf(1)
+	step := 0.0
+	if s.Type == huma.TypeInteger {
+		step = 1
+	}
+	if s.Minimum != nil {
+		lo = *s.Minimum
+	}
+	if s.ExclusiveMinimum != nil {
+		lo = math.Max(lo, *s.ExclusiveMinimum+math.Max(step, 0.001))
+	}
+	if s.Maximum != nil {
+		hi = *s.Maximum
+	}
+	if s.ExclusiveMaximum != nil {
+		hi = math.Min(hi, *s.ExclusiveMaximum-math.Max(step, 0.001))
+	}
+	switch {
+	case math.IsInf(lo, -1) && math.IsInf(hi, 1):
+		lo, hi = -1000, 1000
+	case math.IsInf(lo, -1):
+		lo = hi - 1000
+	case math.IsInf(hi, 1):
+		hi = lo + 1000
+	}
+
+	if s.MultipleOf != nil && *s.MultipleOf > 0 {
+		m := *s.MultipleOf
+		first, last := math.Ceil(lo/m), math.Floor(hi/m)
+		if last < first {
+			return first * m
+		}
+		return (first + float64(g.rand.Int63n(int64(last-first)+1))) * m
+	}
+
+	v := lo + g.rand.Float64()*(hi-lo)
+	if step > 0 {
+		first, last := math.Ceil(lo), math.Floor(hi)
+		if last < first {
+			return first
+		}
+		v = first + float64(g.rand.Int63n(int64(last-first)+1))
+	}
+	return v
+}
+
+const letters = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+func (g *Generator) letters(n int) string {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = letters[g.rand.Intn(len(letters))]
+	}
+	return string(b)
+}
+
+func (g *Generator) string(s *huma.Schema) string {
+	switch s.Format {
+	case "date-time":
+		return time.Unix(g.rand.Int63n(4e9), 0).UTC().Format(time.RFC3339)
+	case "date-time-http":
+		return time.Unix(g.rand.Int63n(4e9), 0).UTC().Format(time.RFC1123)
+	case "date":
+		return time.Unix(g.rand.Int63n(4e9), 0).UTC().Format("2006-01-02")
+	case "time":
+		return time.Unix(g.rand.Int63n(86400), 0).UTC().Format("15:04:05Z07:00")
+	case "email", "idn-email":
+		return strings.ToLower(g.letters(8)) + "@example.com"
+	case "hostname":
+		return strings.ToLower(g.letters(8)) + ".example.com"
+	case "ipv4":
+		return fmt.Sprintf("%d.%d.%d.%d", g.rand.Intn(256), g.rand.Intn(256), g.rand.Intn(256), g.rand.Intn(256))
+	case "ipv6":
+		return fmt.Sprintf("2001:db8::%x", g.rand.Intn(0x10000))
+	case "uri", "uri-reference", "iri", "iri-reference":
+		return "https://example.com/" + g.letters(8)
+	case "uuid":
+		b := make([]byte, 16)
+		g.rand.Read(b)
+		return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+	}
+
+	minLength, maxLength := bounds(s.MinLength, s.MaxLength, 16)
+	return g.letters(minLength + g.rand.Intn(maxLength-minLength+1))
+}
+
+// Invalid returns values which each fail validation against the schema by
+// breaking a single constraint just past its boundary, such as a number one
+// below `minimum`, a value of the wrong type, or an object with a required
+// property removed. Constraints of nested properties and array items are
+// included. Every returned value fails validation.
+func (g *Generator) Invalid(s *huma.Schema) []any {
+	candidates := g.invalid(s, 0)
+	values := make([]any, 0, len(candidates))
+	for _, v := range candidates {
+		if !g.validates(s, v) {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+func (g *Generator) invalid(s *huma.Schema, depth int) []any {
+	s = g.resolve(s)
+	if s == nil {
+		return nil
+	}
+
+	values := []any{}
+	if len(s.Enum) > 0 {
+		values = append(values, "not-"+fmt.Sprint(s.Enum[0]))
+	}
+
+	switch s.Type {
+	case huma.TypeBoolean:
+		values = append(values, "true")
+	case huma.TypeInteger, huma.TypeNumber:
+		values = append(values, "0")
+		step := 0.5
+		if s.Type == huma.TypeInteger {
+			step = 1
+			values = append(values, g.number(s)+0.5)
+		}
+		if s.Minimum != nil {
+			values = append(values, *s.Minimum-step)
+		}
+		if s.ExclusiveMinimum != nil {
+			values = append(values, *s.ExclusiveMinimum)
+		}
+		if s.Maximum != nil {
+			values = append(values, *s.Maximum+step)
+		}
+		if s.ExclusiveMaximum != nil {
+			values = append(values, *s.ExclusiveMaximum)
+		}
+		if s.MultipleOf != nil {
+			values = append(values, g.number(s)+*s.MultipleOf/2)
+		}
+	case huma.TypeString:
+		values = append(values, 0.0)
+		if s.MinLength != nil && *s.MinLength > 0 {
+			values = append(values, g.letters(*s.MinLength-1))
+		}
+		if s.MaxLength != nil {
+			values = append(values, g.letters(*s.MaxLength+1))
+		}
+		if s.Format != "" || s.Pattern != "" {
+			values = append(values, "!invalid "+s.Format)
+		}
+	case huma.TypeArray:
+		values = append(values, map[string]any{})
+		if depth >= maxGenerateDepth {
+			break
+		}
+		valid, _ := g.Valid(s).([]any)
+		if s.MinItems != nil && *s.MinItems > 0 && len(valid) >= *s.MinItems {
+			values = append(values, valid[:*s.MinItems-1])
+		}
+		if s.MaxItems != nil || s.UniqueItems {
+			// Repeating an item both exceeds the maximum and adds a duplicate.
+			item := g.Valid(s.Items)
+			if len(valid) > 0 {
+				item = valid[0]
+			}
+			values = append(values, append(append([]any{}, valid...), item))
+		}
+		for _, item := range g.invalid(s.Items, depth+1) {
+			values = append(values, append(append([]any{}, valid...), item))
+		}
+	case huma.TypeObject:
+		values = append(values, []any{})
+		if depth >= maxGenerateDepth {
+			break
+		}
+		valid, _ := g.Valid(s).(map[string]any)
+		with := func(name string, v any, remove bool) map[string]any {
+			obj := make(map[string]any, len(valid)+1)
+			for k, existing := range valid {
+				obj[k] = existing
+			}
+			if remove {
+				delete(obj, name)
+			} else {
+				obj[name] = v
+			}
+			return obj
+		}
+		for _, name := range s.Required {
+			values = append(values, with(name, nil, true))
+		}
+		for _, name := range propertyNames(s) {
+			prop := s.Properties[name]
+			if p := g.resolve(prop); p != nil && p.ReadOnly {
+				continue
+			}
+			for _, v := range g.invalid(prop, depth+1) {
+				values = append(values, with(name, v, false))
+			}
+		}
+		if s.AdditionalProperties == false {
+			values = append(values, with("unexpected-"+g.letters(4), true, false))
+		}
+	}
+	return values
+}
+
+// FuzzBody fuzzes an operation's request body, failing if any request results
+// in a server error (a `5xx` status code). The fuzz corpus is seeded with
+// valid and boundary-invalid bodies generated from the operation's request
+// body schema, and path parameters are filled with valid generated values.
+// Call it from a fuzz test instead of `f.Fuzz`:
+//
+//	func FuzzCreateThing(f *testing.F) {
+//		_, api := humatest.New(f)
+//		addRoutes(api)
+//		humatest.FuzzBody(f, api, "create-thing")
+//	}
+func FuzzBody(f *testing.F, api TestAPI, operationID string) {
+	f.Helper()
+
+	op := findOperation(api.OpenAPI(), operationID)
+	if op == nil {
+		panic("operation " + operationID + " not found")
+	}
+
+	registry := api.OpenAPI().Components.Schemas
+	gen := NewGenerator(registry, 1)
+
+	path := op.Path
+	for _, p := range op.Parameters {
+		if p.In == "path" {
+			path = strings.ReplaceAll(path, "{"+p.Name+"}", fmt.Sprint(gen.Valid(p.Schema)))
+		}
+	}
+
+	if op.RequestBody != nil {
+		if mt := op.RequestBody.Content["application/json"]; mt != nil && mt.Schema != nil {
+			for i := 0; i < 5; i++ {
+				addSeed(f, gen.Valid(mt.Schema))
+			}
+			for _, v := range gen.Invalid(mt.Schema) {
+				addSeed(f, v)
+			}
+		}
+	}
+
+	f.Fuzz(func(t *testing.T, body []byte) {
+		resp := Wrap(t, api).Do(op.Method, path, "Content-Type: application/json", bytes.NewReader(body))
+		if resp.Code >= http.StatusInternalServerError {
+			t.Errorf("unexpected server error %d for body %s: %s", resp.Code, body, resp.Body.String())
+		}
+	})
+}
+
+func addSeed(f *testing.F, v any) {
+	if b, err := json.Marshal(v); err == nil {
+		f.Add(b)
+	}
+}
@@ -112,8 +112,15 @@ type testAPI struct {
 	tb TB
 }
 
-func (a *testAPI) Do(method, path string, args ...any) *httptest.ResponseRecorder {
-	a.tb.Helper()
+func (a *testAPI) Config() *huma.Config {
+	if p, ok := a.API.(huma.ConfigProvider); ok {
+		return p.Config()
+	}
+	return nil
+}
+
+// newRequest creates a request from the arguments accepted by `TestAPI.Do`.
+func newRequest(method, path string, args ...any) (*http.Request, bool) {
 	var b io.Reader
 	isJSON := false
 	for _, arg := range args {
@@ -136,6 +143,10 @@ func (a *testAPI) Do(method, path string, args ...any) *httptest.ResponseRecorde
 	}
 
 	req, _ := http.NewRequest(method, path, b)
+	if req.Body == nil {
+		// Like a server, always provide a body for handlers to read.
+		req.Body = http.NoBody
+	}
 	if isJSON {
 		req.Header.Set("Content-Type", "application/json")
 	}
@@ -149,9 +160,15 @@ func (a *testAPI) Do(method, path string, args ...any) *httptest.ResponseRecorde
 			}
 		}
 	}
+	return req, b != nil
+}
+
+func (a *testAPI) Do(method, path string, args ...any) *httptest.ResponseRecorder {
+	a.tb.Helper()
+	req, hasBody := newRequest(method, path, args...)
 	resp := httptest.NewRecorder()
 
-	bytes, _ := httputil.DumpRequest(req, b != nil)
+	bytes, _ := httputil.DumpRequest(req, hasBody)
 	a.tb.Log("Making request:\n" + strings.TrimSpace(string(bytes)))
 
 	a.Adapter().ServeHTTP(resp, req)
@@ -2,14 +2,22 @@ package humatest
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/danielgtaylor/huma/v2"
 	"github.com/go-chi/chi/v5"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 type Response struct {
@@ -116,3 +124,323 @@ func TestNewAPI(t *testing.T) {
 		wrapped.Post("/", 1234)
 	})
 }
+
+type CallInput struct {
+	ID      string    `path:"id"`
+	Tags    []string  `query:"tags"`
+	Limit   int       `query:"limit"`
+	Trace   string    `header:"X-Trace"`
+	Session string    `cookie:"session"`
+	Since   time.Time `query:"since"`
+	Body    struct {
+		Value string `json:"value"`
+	}
+}
+
+type CallOutput struct {
+	Status  int
+	Count   int       `header:"X-Count"`
+	Links   []string  `header:"Link"`
+	Updated time.Time `header:"Last-Modified"`
+	Body    struct {
+		Echo string `json:"echo"`
+	}
+}
+
+func TestCall(t *testing.T) {
+	_, api := New(t)
+
+	updated := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	huma.Register(api, huma.Operation{
+		OperationID: "call",
+		Method:      http.MethodPut,
+		Path:        "/call/{id}",
+	}, func(ctx context.Context, input *CallInput) (*CallOutput, error) {
+		if input.ID == "missing" {
+			return nil, huma.Error404NotFound("not found")
+		}
+		assert.Equal(t, "a b", input.ID)
+		assert.Equal(t, []string{"x", "y"}, input.Tags)
+		assert.Equal(t, 0, input.Limit)
+		assert.Equal(t, "trace", input.Trace)
+		assert.Equal(t, "s", input.Session)
+		assert.True(t, updated.Equal(input.Since))
+		out := &CallOutput{Status: http.StatusCreated, Count: 2, Links: []string{"a", "b"}, Updated: updated}
+		out.Body.Echo = input.Body.Value
+		return out, nil
+	})
+
+	input := &CallInput{
+		ID:      "a b",
+		Tags:    []string{"x", "y"},
+		Trace:   "trace",
+		Session: "s",
+		Since:   updated,
+	}
+	input.Body.Value = "hello"
+
+	out, resp := Call[CallInput, CallOutput](t, api, "call", input)
+	assert.Equal(t, http.StatusCreated, resp.Code)
+	assert.Equal(t, http.StatusCreated, out.Status)
+	assert.Equal(t, 2, out.Count)
+	assert.Equal(t, []string{"a", "b"}, out.Links)
+	assert.True(t, updated.Equal(out.Updated))
+	assert.Equal(t, "hello", out.Body.Echo)
+
+	// Errors are left in the response recorder.
+	out, resp = Call[CallInput, CallOutput](t, api, "call", &CallInput{ID: "missing"})
+	assert.Nil(t, out)
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+
+	assert.Panics(t, func() {
+		Call[CallInput, CallOutput](t, api, "unknown", input)
+	})
+}
+
+type recordingTB struct {
+	testing.TB
+	errors []string
+}
+
+func (tb *recordingTB) Errorf(format string, args ...any) {
+	tb.errors = append(tb.errors, fmt.Sprintf(format, args...))
+}
+
+func TestValidateResponses(t *testing.T) {
+	tb := &recordingTB{TB: t}
+	_, api := New(t, ValidateResponses(tb, huma.DefaultConfig("Test API", "1.0.0")))
+
+	type Output struct {
+		Count int `header:"X-Count" minimum:"1"`
+		Body  struct {
+			Name string `json:"name" maxLength:"5"`
+		}
+	}
+
+	huma.Register(api, huma.Operation{
+		Method: http.MethodGet,
+		Path:   "/things/{name}",
+		Errors: []int{http.StatusNotFound},
+	}, func(ctx context.Context, input *struct {
+		Name string `path:"name"`
+	}) (*Output, error) {
+		switch input.Name {
+		case "missing":
+			return nil, huma.Error404NotFound("not found")
+		case "conflict":
+			return nil, huma.Error409Conflict("undeclared")
+		}
+		out := &Output{Count: len(input.Name)}
+		out.Body.Name = input.Name
+		return out, nil
+	})
+
+	api.Get("/things/abc")
+	api.Get("/things/missing")
+	assert.Empty(t, tb.errors)
+
+	// The body does not match the schema.
+	api.Get("/things/toolong")
+	require.Len(t, tb.errors, 1)
+	assert.Contains(t, tb.errors[0], "body.name")
+
+	// The status code is not documented.
+	tb.errors = nil
+	api.Get("/things/conflict")
+	require.Len(t, tb.errors, 1)
+	assert.Contains(t, tb.errors[0], "undeclared response status code")
+}
+
+type GeneratedThing struct {
+	ID      string   `json:"id" readOnly:"true"`
+	Name    string   `json:"name" minLength:"2" maxLength:"10"`
+	Email   string   `json:"email,omitempty" format:"email"`
+	Count   int      `json:"count" minimum:"1" maximum:"5"`
+	Ratio   float64  `json:"ratio,omitempty" exclusiveMinimum:"0" multipleOf:"0.5"`
+	Kind    string   `json:"kind" enum:"a,b"`
+	Tags    []string `json:"tags,omitempty" maxItems:"2" uniqueItems:"true"`
+	Code    string   `json:"code,omitempty" pattern:"^[A-Z]{3}$" example:"ABC"`
+	Enabled bool     `json:"enabled,omitempty"`
+}
+
+func TestGenerator(t *testing.T) {
+	registry := huma.NewMapRegistry("#/components/schemas/", huma.DefaultSchemaNamer)
+	s := registry.Schema(reflect.TypeOf(GeneratedThing{}), true, "")
+	g := NewGenerator(registry, 1)
+
+	validates := func(v any) bool {
+		res := &huma.ValidateResult{}
+		huma.Validate(registry, s, huma.NewPathBuffer([]byte{}, 0), huma.ModeWriteToServer, v, res)
+		return len(res.Errors) == 0
+	}
+
+	for i := 0; i < 50; i++ {
+		v := g.Valid(s)
+		assert.True(t, validates(v), v)
+		assert.NotContains(t, v, "id")
+	}
+
+	invalid := g.Invalid(s)
+	assert.NotEmpty(t, invalid)
+	for _, v := range invalid {
+		assert.False(t, validates(v), v)
+	}
+
+	// Boundary values are included.
+	assert.Contains(t, fmt.Sprint(invalid), "count:0")
+	assert.Contains(t, fmt.Sprint(invalid), "count:6")
+
+	// Values are repeatable for the same seed.
+	assert.Equal(t, NewGenerator(registry, 5).Valid(s), NewGenerator(registry, 5).Valid(s))
+}
+
+func FuzzBodyExample(f *testing.F) {
+	_, api := New(f)
+	huma.Register(api, huma.Operation{
+		OperationID: "create-thing",
+		Method:      http.MethodPost,
+		Path:        "/things/{id}",
+	}, func(ctx context.Context, input *struct {
+		ID   string `path:"id" maxLength:"8"`
+		Body GeneratedThing
+	}) (*struct{}, error) {
+		return nil, nil
+	})
+
+	FuzzBody(f, api, "create-thing")
+}
+
+func TestCheckExamples(t *testing.T) {
+	_, api := New(t)
+
+	type Thing struct {
+		Name  string `json:"name" maxLength:"10"`
+		Count int    `json:"count" minimum:"1"`
+	}
+
+	huma.Register(api, huma.Operation{
+		OperationID: "put-thing",
+		Method:      http.MethodPut,
+		Path:        "/things/{id}",
+		RequestBody: &huma.RequestBody{
+			Content: map[string]*huma.MediaType{
+				"application/json": {
+					Schema:  api.OpenAPI().Components.Schemas.Schema(reflect.TypeOf(Thing{}), true, ""),
+					Example: map[string]any{"name": "first", "count": 1},
+					Examples: map[string]*huma.Example{
+						"second": {Value: map[string]any{"name": "second", "count": 2}},
+					},
+				},
+			},
+		},
+		Responses: map[string]*huma.Response{
+			"200": {
+				Content: map[string]*huma.MediaType{
+					"application/json": {
+						Example: Thing{Name: "out", Count: 3},
+					},
+				},
+			},
+		},
+	}, func(ctx context.Context, input *struct {
+		ID   string `path:"id" example:"abc"`
+		Body Thing
+	}) (*struct{ Body Thing }, error) {
+		assert.Equal(t, "abc", input.ID)
+		return &struct{ Body Thing }{Body: input.Body}, nil
+	})
+
+	CheckExamples(t, api)
+
+	mt := api.OpenAPI().Paths["/things/{id}"].Put.RequestBody.Content["application/json"]
+	examples := mediaExamples(api.OpenAPI().Components.Schemas, mt)
+	require.Len(t, examples, 2)
+	assert.Equal(t, "example", examples[0].name)
+	assert.Equal(t, "second", examples[1].name)
+}
+
+func TestRecordReplay(t *testing.T) {
+	_, api := New(t)
+
+	type Output struct {
+		Version string `header:"X-Version"`
+		Date    string `header:"Date"`
+		Body    struct {
+			Greeting string `json:"greeting"`
+		}
+	}
+
+	huma.Register(api, huma.Operation{
+		OperationID: "greet",
+		Method:      http.MethodPost,
+		Path:        "/greet/{name}",
+	}, func(ctx context.Context, input *struct {
+		Name          string `path:"name"`
+		Authorization string `header:"Authorization"`
+		Body          struct {
+			Punctuation string `json:"punctuation"`
+		}
+	}) (*Output, error) {
+		if input.Authorization == "" {
+			return nil, huma.Error401Unauthorized("missing auth")
+		}
+		out := &Output{Version: "1", Date: time.Now().Format(time.RFC3339Nano)}
+		out.Body.Greeting = "Hello, " + input.Name + input.Body.Punctuation
+		return out, nil
+	})
+
+	dir := t.TempDir()
+	handler := Record(dir, api.Adapter())
+	req := httptest.NewRequest(http.MethodPost, "/greet/world", strings.NewReader(`{"punctuation": "!"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer secret")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+	require.Len(t, files, 1)
+	assert.Equal(t, "0001-post-greet-world.json", filepath.Base(files[0]))
+
+	b, _ := os.ReadFile(files[0])
+	assert.NotContains(t, string(b), "secret")
+	assert.NotContains(t, string(b), "Date")
+
+	var fixture Fixture
+	require.NoError(t, json.Unmarshal(b, &fixture))
+	assert.Equal(t, http.StatusOK, fixture.Response.Status)
+	assert.JSONEq(t, `{"greeting": "Hello, world!"}`, string(fixture.Response.Body))
+	assert.Equal(t, []string{"REDACTED"}, fixture.Request.Header["Authorization"])
+
+	Replay(t, api, filepath.Join(dir, "*.json"), "Authorization: Bearer test")
+}
+
+func TestServer(t *testing.T) {
+	_, api := New(t)
+
+	huma.Register(api, huma.Operation{
+		Method: http.MethodPost,
+		Path:   "/echo",
+	}, func(ctx context.Context, input *struct {
+		Body struct {
+			Value string `json:"value"`
+		}
+	}) (*Response, error) {
+		resp := &Response{MyHeader: "my-value"}
+		resp.Body.Echo = input.Body.Value
+		return resp, nil
+	})
+
+	for _, srv := range []*Server{NewServer(api), NewTLSServer(api)} {
+		resp, err := srv.Post("/echo", map[string]any{"value": "hello"})
+		require.NoError(t, err)
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		srv.Close()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "my-value", resp.Header.Get("My-Header"))
+		assert.JSONEq(t, `{"echo":"hello"}`, string(body))
+		if srv.TLS != nil {
+			assert.Equal(t, 2, resp.ProtoMajor)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package humatest
+
+import (
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// Server is an API served over a real network listener, for tests which need
+// real HTTP semantics like keep-alive connections, TLS, or server timeouts.
+// Use `Server.Client()` for a client configured to talk to it, including
+// trusting its TLS certificate, and call `Close()` when done.
+type Server struct {
+	*httptest.Server
+}
+
+// NewServer starts serving the API on a local HTTP listener. It works with
+// any adapter, as requests are handled by the API's router.
+//
+//	srv := humatest.NewServer(api)
+//	defer srv.Close()
+//
+//	resp, err := srv.Get("/greeting/world")
+func NewServer(api huma.API) *Server {
+	return &Server{httptest.NewServer(api.Adapter())}
+}
+
+// NewTLSServer starts serving the API on a local HTTPS listener with HTTP/2
+// enabled. See `NewServer`.
+func NewTLSServer(api huma.API) *Server {
+	s := httptest.NewUnstartedServer(api.Adapter())
+	s.EnableHTTP2 = true
+	s.StartTLS()
+	return &Server{s}
+}
+
+// Do makes a request to the server using its client. The path is relative to
+// the server's URL and args work the same way as `TestAPI.Do`. The caller
+// must close the response body.
+func (s *Server) Do(method, path string, args ...any) (*http.Response, error) {
+	req, _ := newRequest(method, s.URL+path, args...)
+	return s.Client().Do(req)
+}
+
+// Get performs a GET request against the server. See `Server.Do`.
+func (s *Server) Get(path string, args ...any) (*http.Response, error) {
+	return s.Do(http.MethodGet, path, args...)
+}
+
+// Post performs a POST request against the server. See `Server.Do`.
+func (s *Server) Post(path string, args ...any) (*http.Response, error) {
+	return s.Do(http.MethodPost, path, args...)
+}
+
+// Put performs a PUT request against the server. See `Server.Do`.
+func (s *Server) Put(path string, args ...any) (*http.Response, error) {
+	return s.Do(http.MethodPut, path, args...)
+}
+
+// Patch performs a PATCH request against the server. See `Server.Do`.
+func (s *Server) Patch(path string, args ...any) (*http.Response, error) {
+	return s.Do(http.MethodPatch, path, args...)
+}
+
+// Delete performs a DELETE request against the server. See `Server.Do`.
+func (s *Server) Delete(path string, args ...any) (*http.Response, error) {
+	return s.Do(http.MethodDelete, path, args...)
+}
@@ -0,0 +1,357 @@
+// Package idempotency implements the `Idempotency-Key` header for Huma APIs,
+// making it safe for clients to retry non-idempotent requests like `POST`
+// after a network failure. The first request with a key is processed and its
+// response saved. Repeated requests with the same key receive the saved
+// response instead of being processed again.
+//
+//	idempotency.Use(api, idempotency.Config{})
+//
+//	huma.Register(api, huma.Operation{
+//		OperationID: "create-payment",
+//		Method:      http.MethodPost,
+//		Path:        "/payments",
+//		Metadata: map[string]any{
+//			idempotency.MetadataKey: true,
+//		},
+//	}, handler)
+//
+// See https://datatracker.ietf.org/doc/draft-ietf-httpapi-idempotency-key-header/
+package idempotency
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// MetadataKey is the operation metadata key used to opt an operation in to
+// idempotency key support, e.g. `Metadata: map[string]any{"idempotency": true}`.
+const MetadataKey = "idempotency"
+
+// Header is the name of the request header containing the idempotency key.
+const Header = "Idempotency-Key"
+
+// ReplayedHeader is set on responses which were replayed from a previous
+// request with the same key.
+const ReplayedHeader = "Idempotent-Replayed"
+
+// maxKeyLength is the maximum length of an idempotency key.
+const maxKeyLength = 255
+
+// Config configures idempotency key support for an API.
+type Config struct {
+	// Store saves keys and their responses. Defaults to a new `MemoryStore`.
+	Store Store
+
+	// TTL is how long keys and their responses are kept. Defaults to 24 hours.
+	TTL time.Duration
+
+	// Required rejects requests to opted-in operations which do not include
+	// an idempotency key with a `400 Bad Request` error.
+	Required bool
+
+	// Scope returns a string identifying the client, e.g. an API key or user
+	// ID, so that different clients may use the same keys without seeing each
+	// other's responses. Defaults to the client's address as returned by
+	// `huma.ClientInfoFromContext`, which is shared by clients behind the same
+	// NAT or proxy, so authenticated operations should scope keys to the
+	// authenticated user instead.
+	Scope func(ctx huma.Context) string
+}
+
+// clientScope is the default scope, which identifies clients by their
+// address so that clients cannot replay each other's responses.
+func clientScope(ctx huma.Context) string {
+	info := huma.ClientInfoFromContext(ctx.Context())
+	if info.IP.IsValid() {
+		return info.IP.String()
+	}
+	return info.ID
+}
+
+// enabled returns whether an operation has opted in.
+func enabled(op *huma.Operation) bool {
+	on, _ := op.Metadata[MetadataKey].(bool)
+	return on
+}
+
+// recordingContext saves the response as it is written, and replays the
+// buffered request body.
+type recordingContext struct {
+	huma.ContextWrapper
+	body     io.Reader
+	status   int
+	header   http.Header
+	buf      bytes.Buffer
+	writer   io.Writer
+	streamed bool
+}
+
+func (c *recordingContext) BodyReader() io.Reader {
+	return c.body
+}
+
+func (c *recordingContext) SetStatus(code int) {
+	c.status = code
+	c.ContextWrapper.SetStatus(code)
+}
+
+func (c *recordingContext) SetHeader(name, value string) {
+	c.header.Set(name, value)
+	c.ContextWrapper.SetHeader(name, value)
+}
+
+func (c *recordingContext) AppendHeader(name, value string) {
+	c.header.Add(name, value)
+	c.ContextWrapper.AppendHeader(name, value)
+}
+
+func (c *recordingContext) BodyWriter() io.Writer {
+	if c.writer == nil {
+		c.writer = io.MultiWriter(c.ContextWrapper.BodyWriter(), &c.buf)
+	}
+	return c.writer
+}
+
+// StreamBody passes streamed responses through without saving them, as they
+// may be arbitrarily large or long-lived.
+func (c *recordingContext) StreamBody(cb func(w io.Writer, flush func() error)) {
+	c.streamed = true
+	if sc, ok := c.Unwrap().(huma.StreamingContext); ok {
+		sc.StreamBody(cb)
+		return
+	}
+	w := c.ContextWrapper.BodyWriter()
+	cb(w, func() error {
+		if f, ok := w.(http.Flusher); ok {
+			f.Flush()
+			return nil
+		}
+		return http.ErrNotSupported
+	})
+}
+
+// fingerprint identifies a request by its method, URL, and body.
+func fingerprint(ctx huma.Context, body []byte) string {
+	u := ctx.URL()
+	h := sha256.New()
+	h.Write([]byte(ctx.Method() + " " + u.RequestURI() + "\n"))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Use adds idempotency key middleware to the API and documents the header
+// on opted-in operations. Like other middleware, it must be called before
+// registering operations with `huma.Register` in order to apply to them.
+//
+// For opted-in operations, requests with an `Idempotency-Key` header are
+// handled as follows:
+//
+//   - The first request with a key is processed and its response saved.
+//   - Repeated requests receive the saved response, with the
+//     `Idempotent-Replayed: true` header.
+//   - Requests made while the first request is still being processed receive
+//     a `409 Conflict` error.
+//   - Requests reusing a key for a different method, URL, or body receive a
+//     `422 Unprocessable Entity` error.
+//
+// Responses with a `5xx` status and streamed responses are not saved, so the
+// key may be retried. If the store returns an error then a
+// `503 Service Unavailable` error is returned rather than risking processing
+// a request twice.
+func Use(api huma.API, config Config) {
+	if config.Store == nil {
+		config.Store = NewMemoryStore()
+	}
+	if config.TTL <= 0 {
+		config.TTL = 24 * time.Hour
+	}
+	if config.Scope == nil {
+		config.Scope = clientScope
+	}
+
+	oapi := api.OpenAPI()
+	oapi.OnAddOperation = append(oapi.OnAddOperation, config.document)
+
+	api.UseMiddleware(func(ctx huma.Context, next func(huma.Context)) {
+		op := ctx.Operation()
+		if !enabled(op) {
+			next(ctx)
+			return
+		}
+
+		key := ctx.Header(Header)
+		if key == "" {
+			if config.Required {
+				huma.WriteErr(api, ctx, http.StatusBadRequest, "the "+Header+" header is required", &huma.ErrorDetail{
+					Message:  "required header parameter is missing",
+					Location: "header." + Header,
+				})
+				return
+			}
+			next(ctx)
+			return
+		}
+		if len(key) > maxKeyLength {
+			huma.WriteErr(api, ctx, http.StatusBadRequest, "invalid "+Header+" header", &huma.ErrorDetail{
+				Message:  "expected length <= " + strconv.Itoa(maxKeyLength),
+				Location: "header." + Header,
+			})
+			return
+		}
+
+		// Buffer the body to fingerprint it, limited in the same way as the
+		// operation will limit it.
+		limit := op.MaxBodyBytes
+		if limit == 0 {
+			limit = 1024 * 1024
+		}
+		reader := ctx.BodyReader()
+		if limit > 0 {
+			reader = io.LimitReader(reader, limit+1)
+		}
+		body, err := io.ReadAll(reader)
+		if err != nil {
+			var ne net.Error
+			if errors.As(err, &ne) && ne.Timeout() {
+				huma.WriteErr(api, ctx, http.StatusRequestTimeout, "request body read timeout")
+				return
+			}
+			huma.WriteErr(api, ctx, http.StatusBadRequest, "cannot read request body", err)
+			return
+		}
+
+		scoped := config.Scope(ctx) + ":" + op.OperationID + ":" + key
+
+		fp := fingerprint(ctx, body)
+		record, err := config.Store.Start(ctx.Context(), scoped, fp, config.TTL)
+		if err != nil {
+			huma.WriteErr(api, ctx, http.StatusServiceUnavailable, "unable to check "+Header)
+			return
+		}
+		if record != nil {
+			switch {
+			case record.Fingerprint != fp:
+				huma.WriteErr(api, ctx, http.StatusUnprocessableEntity, Header+" was already used for a different request")
+			case record.Response == nil:
+				huma.WriteErr(api, ctx, http.StatusConflict, "a request with this "+Header+" is still being processed")
+			default:
+				replay(ctx, record.Response)
+			}
+			return
+		}
+
+		rc := &recordingContext{
+			ContextWrapper: huma.WrapContext(ctx),
+			body:           bytes.NewReader(body),
+			status:         http.StatusOK,
+			header:         http.Header{},
+		}
+		completed := false
+		defer func() {
+			// Release the key if the handler panicked so it can be retried.
+			if !completed {
+				config.Store.Delete(ctx.Context(), scoped)
+			}
+		}()
+		next(rc)
+		completed = true
+
+		if rc.streamed || rc.status >= http.StatusInternalServerError {
+			config.Store.Delete(ctx.Context(), scoped)
+			return
+		}
+		config.Store.Complete(ctx.Context(), scoped, &Response{
+			Status: rc.status,
+			Header: rc.header,
+			Body:   rc.buf.Bytes(),
+		}, config.TTL)
+	})
+}
+
+// replay writes a saved response.
+func replay(ctx huma.Context, resp *Response) {
+	names := make([]string, 0, len(resp.Header))
+	for name := range resp.Header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for i, value := range resp.Header[name] {
+			if i == 0 {
+				// Replace any value set by outer middleware for this request.
+				ctx.SetHeader(name, value)
+				continue
+			}
+			ctx.AppendHeader(name, value)
+		}
+	}
+	ctx.SetHeader(ReplayedHeader, "true")
+	ctx.SetStatus(resp.Status)
+	ctx.BodyWriter().Write(resp.Body)
+}
+
+// document adds the `Idempotency-Key` header and the `409` and `422`
+// responses to the OpenAPI for opted-in operations.
+func (c *Config) document(oapi *huma.OpenAPI, op *huma.Operation) {
+	if !enabled(op) {
+		return
+	}
+
+	documented := false
+	for _, p := range op.Parameters {
+		if p.In == "header" && strings.EqualFold(p.Name, Header) {
+			documented = true
+		}
+	}
+	if !documented {
+		maxLength := maxKeyLength
+		op.Parameters = append(op.Parameters, &huma.Param{
+			Name:        Header,
+			In:          "header",
+			Description: "Unique key which makes the request safe to retry. Repeated requests with the same key receive the original response.",
+			Required:    c.Required,
+			Schema:      &huma.Schema{Type: huma.TypeString, MaxLength: &maxLength},
+		})
+	}
+
+	if op.Responses == nil {
+		op.Responses = map[string]*huma.Response{}
+	}
+	codes := []int{http.StatusConflict, http.StatusUnprocessableEntity}
+	if c.Required {
+		codes = append(codes, http.StatusBadRequest)
+	}
+	for _, status := range codes {
+		code := strconv.Itoa(status)
+		if op.Responses[code] == nil {
+			op.Responses[code] = &huma.Response{
+				Description: http.StatusText(status),
+				Content:     huma.ErrorContent(op),
+			}
+		}
+	}
+
+	for code, resp := range op.Responses {
+		if resp.Ref != "" || strings.HasPrefix(code, "4") || strings.HasPrefix(code, "5") || code == "default" {
+			continue
+		}
+		if resp.Headers == nil {
+			resp.Headers = map[string]*huma.Param{}
+		}
+		resp.Headers[ReplayedHeader] = &huma.Param{
+			Description: "Set to `true` when the response was replayed from a previous request with the same idempotency key.",
+			Schema:      &huma.Schema{Type: huma.TypeBoolean},
+		}
+	}
+}
@@ -0,0 +1,273 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/humatest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type PaymentInput struct {
+	Body struct {
+		Amount int `json:"amount"`
+	}
+}
+
+type PaymentOutput struct {
+	Location string `header:"Location"`
+	Body     struct {
+		ID     int `json:"id"`
+		Amount int `json:"amount"`
+	}
+}
+
+func setup(t *testing.T, config Config) (humatest.TestAPI, *int32) {
+	_, api := humatest.New(t)
+	Use(api, config)
+
+	var calls int32
+	huma.Register(api, huma.Operation{
+		OperationID:   "create-payment",
+		Method:        http.MethodPost,
+		Path:          "/payments",
+		DefaultStatus: http.StatusCreated,
+		Metadata:      map[string]any{MetadataKey: true},
+	}, func(ctx context.Context, input *PaymentInput) (*PaymentOutput, error) {
+		id := atomic.AddInt32(&calls, 1)
+		if input.Body.Amount < 0 {
+			return nil, huma.Error500InternalServerError("payment failed")
+		}
+		resp := &PaymentOutput{Location: "/payments/1"}
+		resp.Body.ID = int(id)
+		resp.Body.Amount = input.Body.Amount
+		return resp, nil
+	})
+
+	huma.Register(api, huma.Operation{
+		OperationID: "list-payments",
+		Method:      http.MethodGet,
+		Path:        "/payments",
+	}, func(ctx context.Context, input *struct{}) (*struct{}, error) {
+		return nil, nil
+	})
+
+	return api, &calls
+}
+
+func TestReplay(t *testing.T) {
+	api, calls := setup(t, Config{})
+
+	resp := api.Post("/payments", "Idempotency-Key: abc", map[string]any{"amount": 5})
+	require.Equal(t, http.StatusCreated, resp.Code, resp.Body.String())
+	assert.JSONEq(t, `{"id": 1, "amount": 5}`, resp.Body.String())
+	assert.Empty(t, resp.Header().Get(ReplayedHeader))
+
+	resp = api.Post("/payments", "Idempotency-Key: abc", map[string]any{"amount": 5})
+	require.Equal(t, http.StatusCreated, resp.Code, resp.Body.String())
+	assert.JSONEq(t, `{"id": 1, "amount": 5}`, resp.Body.String())
+	assert.Equal(t, "true", resp.Header().Get(ReplayedHeader))
+	assert.Equal(t, "/payments/1", resp.Header().Get("Location"))
+	assert.Contains(t, resp.Header().Get("Content-Type"), "json")
+	assert.EqualValues(t, 1, atomic.LoadInt32(calls))
+
+	// A different key is processed again.
+	resp = api.Post("/payments", "Idempotency-Key: def", map[string]any{"amount": 5})
+	assert.JSONEq(t, `{"id": 2, "amount": 5}`, resp.Body.String())
+
+	// Reusing a key for a different request is an error.
+	resp = api.Post("/payments", "Idempotency-Key: abc", map[string]any{"amount": 6})
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+
+	// Requests without a key are processed normally.
+	resp = api.Post("/payments", map[string]any{"amount": 5})
+	assert.JSONEq(t, `{"id": 3, "amount": 5}`, resp.Body.String())
+
+	resp = api.Post("/payments", "Idempotency-Key: "+strings.Repeat("a", 256), map[string]any{"amount": 5})
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+}
+
+func TestServerErrorsRetry(t *testing.T) {
+	api, calls := setup(t, Config{})
+
+	resp := api.Post("/payments", "Idempotency-Key: abc", map[string]any{"amount": -1})
+	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+	resp = api.Post("/payments", "Idempotency-Key: abc", map[string]any{"amount": -1})
+	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+	assert.Empty(t, resp.Header().Get(ReplayedHeader))
+	assert.EqualValues(t, 2, atomic.LoadInt32(calls))
+}
+
+func TestConcurrent(t *testing.T) {
+	_, api := humatest.New(t)
+	Use(api, Config{})
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	huma.Register(api, huma.Operation{
+		OperationID: "slow",
+		Method:      http.MethodPost,
+		Path:        "/slow",
+		Metadata:    map[string]any{MetadataKey: true},
+	}, func(ctx context.Context, input *struct{}) (*struct{}, error) {
+		close(started)
+		<-release
+		return nil, nil
+	})
+
+	done := make(chan int)
+	go func() {
+		done <- api.Post("/slow", "Idempotency-Key: abc").Code
+	}()
+	<-started
+
+	resp := api.Post("/slow", "Idempotency-Key: abc")
+	assert.Equal(t, http.StatusConflict, resp.Code)
+
+	close(release)
+	assert.Equal(t, http.StatusNoContent, <-done)
+
+	resp = api.Post("/slow", "Idempotency-Key: abc")
+	assert.Equal(t, http.StatusNoContent, resp.Code)
+	assert.Equal(t, "true", resp.Header().Get(ReplayedHeader))
+}
+
+func TestRequired(t *testing.T) {
+	api, _ := setup(t, Config{
+		Required: true,
+		Scope: func(ctx huma.Context) string {
+			return ctx.Header("Authorization")
+		},
+	})
+
+	resp := api.Post("/payments", map[string]any{"amount": 5})
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+	assert.Contains(t, resp.Body.String(), "header.Idempotency-Key")
+
+	// Operations which have not opted in are not affected.
+	resp = api.Get("/payments")
+	assert.Equal(t, http.StatusNoContent, resp.Code)
+
+	// Keys are scoped per client.
+	resp = api.Post("/payments", "Authorization: a", "Idempotency-Key: abc", map[string]any{"amount": 5})
+	assert.JSONEq(t, `{"id": 1, "amount": 5}`, resp.Body.String())
+	resp = api.Post("/payments", "Authorization: b", "Idempotency-Key: abc", map[string]any{"amount": 5})
+	assert.JSONEq(t, `{"id": 2, "amount": 5}`, resp.Body.String())
+}
+
+func TestDefaultScope(t *testing.T) {
+	api, calls := setup(t, Config{})
+
+	post := func(remoteAddr string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/payments", strings.NewReader(`{"amount": 5}`))
+		req.RemoteAddr = remoteAddr
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(Header, "abc")
+		w := httptest.NewRecorder()
+		api.Adapter().ServeHTTP(w, req)
+		return w
+	}
+
+	// Clients are identified by their address by default, so one client's
+	// response is never replayed to another.
+	assert.JSONEq(t, `{"id": 1, "amount": 5}`, post("192.0.2.1:1234").Body.String())
+	assert.JSONEq(t, `{"id": 2, "amount": 5}`, post("192.0.2.2:1234").Body.String())
+
+	resp := post("192.0.2.1:5678")
+	assert.JSONEq(t, `{"id": 1, "amount": 5}`, resp.Body.String())
+	assert.Equal(t, "true", resp.Header().Get(ReplayedHeader))
+	assert.EqualValues(t, 2, *calls)
+}
+
+type failingStore struct{ MemoryStore }
+
+func (s *failingStore) Start(ctx context.Context, key, fingerprint string, ttl time.Duration) (*Record, error) {
+	return nil, errors.New("unavailable")
+}
+
+func TestStoreError(t *testing.T) {
+	api, calls := setup(t, Config{Store: &failingStore{}})
+
+	resp := api.Post("/payments", "Idempotency-Key: abc", map[string]any{"amount": 5})
+	assert.Equal(t, http.StatusServiceUnavailable, resp.Code)
+	assert.EqualValues(t, 0, atomic.LoadInt32(calls))
+}
+
+type errReader struct{ err error }
+
+func (r errReader) Read(p []byte) (int, error) {
+	return 0, r.err
+}
+
+func TestReadError(t *testing.T) {
+	api, calls := setup(t, Config{})
+
+	resp := api.Post("/payments", "Idempotency-Key: abc", errReader{errors.New("broken")})
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+
+	resp = api.Post("/payments", "Idempotency-Key: abc", errReader{os.ErrDeadlineExceeded})
+	assert.Equal(t, http.StatusRequestTimeout, resp.Code)
+
+	// The key was never used, so it can be retried.
+	resp = api.Post("/payments", "Idempotency-Key: abc", map[string]any{"amount": 5})
+	assert.Equal(t, http.StatusCreated, resp.Code)
+	assert.EqualValues(t, 1, atomic.LoadInt32(calls))
+}
+
+func TestOpenAPI(t *testing.T) {
+	api, _ := setup(t, Config{Required: true})
+
+	op := api.OpenAPI().Paths["/payments"].Post
+	var param *huma.Param
+	for _, p := range op.Parameters {
+		if p.Name == Header {
+			param = p
+		}
+	}
+	require.NotNil(t, param)
+	assert.Equal(t, "header", param.In)
+	assert.True(t, param.Required)
+	assert.NotNil(t, op.Responses["409"])
+	assert.NotNil(t, op.Responses["422"])
+	assert.NotNil(t, op.Responses["400"])
+	assert.NotNil(t, op.Responses["201"].Headers[ReplayedHeader])
+
+	for _, p := range api.OpenAPI().Paths["/payments"].Get.Parameters {
+		assert.NotEqual(t, Header, p.Name)
+	}
+}
+
+func TestMemoryStore(t *testing.T) {
+	now := time.Now()
+	s := NewMemoryStore()
+	s.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	record, err := s.Start(ctx, "k", "fp", time.Minute)
+	require.NoError(t, err)
+	assert.Nil(t, record)
+
+	record, _ = s.Start(ctx, "k", "other", time.Minute)
+	assert.Equal(t, &Record{Fingerprint: "fp"}, record)
+
+	require.NoError(t, s.Complete(ctx, "k", &Response{Status: 200}, time.Minute))
+	record, _ = s.Start(ctx, "k", "fp", time.Minute)
+	assert.Equal(t, 200, record.Response.Status)
+
+	// Expired keys can be reused.
+	now = now.Add(2 * time.Minute)
+	record, _ = s.Start(ctx, "k", "other", time.Minute)
+	assert.Nil(t, record)
+
+	require.NoError(t, s.Delete(ctx, "k"))
+	assert.Empty(t, s.entries)
+}
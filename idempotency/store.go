@@ -0,0 +1,118 @@
+package idempotency
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Response is a saved response which is replayed for repeated requests.
+type Response struct {
+	Status int
+	Header http.Header
+	Body   []byte
+}
+
+// Record is the state of an idempotency key.
+type Record struct {
+	// Fingerprint identifies the request which first used the key, so that
+	// reusing the key for a different request can be detected.
+	Fingerprint string
+
+	// Response is the saved response, or nil if the first request is still
+	// being processed.
+	Response *Response
+}
+
+// Store saves the state of idempotency keys. Implementations must be safe
+// for concurrent use, and `Start` must be atomic so that only one of several
+// concurrent requests with the same key is processed. A shared store such as
+// Redis can be used across multiple instances of a service.
+type Store interface {
+	// Start creates an in-progress record for the key with the request's
+	// fingerprint if the key is not in use, returning nil. Otherwise it
+	// returns the existing record without modifying it.
+	Start(ctx context.Context, key, fingerprint string, ttl time.Duration) (*Record, error)
+
+	// Complete saves the response for a key, to be replayed until the TTL
+	// expires.
+	Complete(ctx context.Context, key string, resp *Response, ttl time.Duration) error
+
+	// Delete removes a key, allowing it to be retried.
+	Delete(ctx context.Context, key string) error
+}
+
+type entry struct {
+	record  Record
+	expires time.Time
+}
+
+// MemoryStore is an in-process `Store`. Expired keys are periodically
+// removed.
+type MemoryStore struct {
+	mu        sync.Mutex
+	entries   map[string]*entry
+	lastSweep time.Time
+
+	// now returns the current time and can be replaced for testing.
+	now func() time.Time
+}
+
+// NewMemoryStore creates a new in-process store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		entries: map[string]*entry{},
+		now:     time.Now,
+	}
+}
+
+// sweepInterval is how often expired keys are removed from a `MemoryStore`.
+const sweepInterval = time.Minute
+
+// Start implements the `Store` interface.
+func (s *MemoryStore) Start(ctx context.Context, key, fingerprint string, ttl time.Duration) (*Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if now.Sub(s.lastSweep) > sweepInterval {
+		for k, e := range s.entries {
+			if !now.Before(e.expires) {
+				delete(s.entries, k)
+			}
+		}
+		s.lastSweep = now
+	}
+
+	if e := s.entries[key]; e != nil && now.Before(e.expires) {
+		record := e.record
+		return &record, nil
+	}
+	s.entries[key] = &entry{
+		record:  Record{Fingerprint: fingerprint},
+		expires: now.Add(ttl),
+	}
+	return nil, nil
+}
+
+// Complete implements the `Store` interface.
+func (s *MemoryStore) Complete(ctx context.Context, key string, resp *Response, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if e := s.entries[key]; e != nil {
+		e.record.Response = resp
+		e.expires = s.now().Add(ttl)
+	}
+	return nil
+}
+
+// Delete implements the `Store` interface.
+func (s *MemoryStore) Delete(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.entries, key)
+	return nil
+}
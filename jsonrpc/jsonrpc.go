@@ -0,0 +1,350 @@
+// Package jsonrpc exposes the operations registered with a Huma API as
+// JSON-RPC 2.0 methods, for clients which are standardized on JSON-RPC. Each
+// method is named after an operation ID and is handled by calling the
+// operation's `huma.OperationHandler` internally, so API middleware,
+// validation, and error handling all behave the same as for REST requests.
+//
+// Method params are an object of the operation's input parameters by name,
+// with the request body in `body`. The result is the response body:
+//
+//	jsonrpc.Expose(api, jsonrpc.Config{})
+//
+//	// --> {"jsonrpc": "2.0", "method": "get-thing", "params": {"thing-id": "a"}, "id": 1}
+//	// <-- {"jsonrpc": "2.0", "result": {"name": "Thing A"}, "id": 1}
+//
+// Batch requests are supported and their calls are handled concurrently.
+package jsonrpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// Standard JSON-RPC 2.0 error codes.
+const (
+	CodeParseError     = -32700
+	CodeInvalidRequest = -32600
+	CodeMethodNotFound = -32601
+	CodeInvalidParams  = -32602
+	CodeInternalError  = -32603
+
+	// CodeServerError is used for operation errors other than invalid params
+	// and internal errors, e.g. `404 Not Found`.
+	CodeServerError = -32000
+)
+
+// Config configures the JSON-RPC endpoint.
+type Config struct {
+	// Path is the URL path of the JSON-RPC endpoint. Defaults to `/rpc`.
+	Path string
+
+	// Filter decides which operations are exposed. If nil, all operations
+	// with an operation ID are exposed.
+	Filter func(op *huma.Operation) bool
+}
+
+// Request is a JSON-RPC request. Requests without an ID are notifications,
+// which get no response.
+type Request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	ID      json.RawMessage `json:"id,omitempty"`
+}
+
+// Response is a JSON-RPC response.
+type Response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	Result  any             `json:"result,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+	ID      json.RawMessage `json:"id"`
+}
+
+// Error is a JSON-RPC error. For operation errors, the data is the error
+// model returned by the operation, e.g. `huma.ErrorModel`.
+type Error struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    any    `json:"data,omitempty"`
+}
+
+type endpoint struct {
+	api    huma.API
+	config Config
+}
+
+// Expose adds a JSON-RPC endpoint to the API at `config.Path` for `POST`
+// requests. Operations registered after calling Expose are also available.
+func Expose(api huma.API, config Config) {
+	if config.Path == "" {
+		config.Path = "/rpc"
+	}
+	e := &endpoint{api: api, config: config}
+	api.Adapter().Handle(&huma.Operation{Method: http.MethodPost, Path: config.Path}, e.handle)
+}
+
+func (e *endpoint) handle(ctx huma.Context) {
+	body, err := io.ReadAll(huma.LimitBody(e.api, ctx))
+	if err != nil {
+		var mbe *http.MaxBytesError
+		if errors.As(err, &mbe) {
+			e.write(ctx, http.StatusRequestEntityTooLarge, errorResponse(nil, CodeInvalidRequest, fmt.Sprintf("request body is too large limit=%d bytes", mbe.Limit)))
+			return
+		}
+		e.write(ctx, http.StatusOK, errorResponse(nil, CodeParseError, err.Error()))
+		return
+	}
+	body = bytes.TrimSpace(body)
+
+	if len(body) > 0 && body[0] == '[' {
+		var batch []json.RawMessage
+		if err := json.Unmarshal(body, &batch); err != nil {
+			e.write(ctx, http.StatusOK, errorResponse(nil, CodeParseError, err.Error()))
+			return
+		}
+		if len(batch) == 0 {
+			e.write(ctx, http.StatusOK, errorResponse(nil, CodeInvalidRequest, "empty batch"))
+			return
+		}
+
+		responses := make([]*Response, len(batch))
+		var wg sync.WaitGroup
+		for i, raw := range batch {
+			wg.Add(1)
+			go func(i int, raw json.RawMessage) {
+				defer wg.Done()
+				responses[i] = e.call(ctx, raw)
+			}(i, raw)
+		}
+		wg.Wait()
+
+		// Notifications get no response, and if there is nothing to return
+		// then no body is sent at all.
+		results := []*Response{}
+		for _, resp := range responses {
+			if resp != nil {
+				results = append(results, resp)
+			}
+		}
+		if len(results) == 0 {
+			ctx.SetStatus(http.StatusNoContent)
+			return
+		}
+		e.write(ctx, http.StatusOK, results)
+		return
+	}
+
+	if !json.Valid(body) {
+		e.write(ctx, http.StatusOK, errorResponse(nil, CodeParseError, "invalid JSON"))
+		return
+	}
+	resp := e.call(ctx, body)
+	if resp == nil {
+		ctx.SetStatus(http.StatusNoContent)
+		return
+	}
+	e.write(ctx, http.StatusOK, resp)
+}
+
+func (e *endpoint) write(ctx huma.Context, status int, v any) {
+	ctx.SetHeader("Content-Type", "application/json")
+	ctx.SetStatus(status)
+	json.NewEncoder(ctx.BodyWriter()).Encode(v)
+}
+
+func errorResponse(id json.RawMessage, code int, message string) *Response {
+	if id == nil {
+		id = json.RawMessage("null")
+	}
+	return &Response{JSONRPC: "2.0", Error: &Error{Code: code, Message: message}, ID: id}
+}
+
+// findOperation returns the exposed operation with the given operation ID.
+func (e *endpoint) findOperation(id string) *huma.Operation {
+	for _, item := range e.api.OpenAPI().Paths {
+		for _, op := range []*huma.Operation{item.Get, item.Put, item.Post, item.Delete, item.Patch} {
+			if op != nil && op.OperationID == id && (e.config.Filter == nil || e.config.Filter(op)) {
+				return op
+			}
+		}
+	}
+	return nil
+}
+
+// call handles a single request, returning nil for notifications.
+func (e *endpoint) call(ctx huma.Context, raw json.RawMessage) *Response {
+	var req Request
+	if err := json.Unmarshal(raw, &req); err != nil {
+		return errorResponse(nil, CodeInvalidRequest, "invalid request: "+err.Error())
+	}
+	if req.JSONRPC != "2.0" || req.Method == "" {
+		return errorResponse(req.ID, CodeInvalidRequest, `invalid request: jsonrpc must be "2.0" and method is required`)
+	}
+
+	resp := e.invoke(ctx, &req)
+	if req.ID == nil {
+		return nil
+	}
+	resp.JSONRPC = "2.0"
+	resp.ID = req.ID
+	return resp
+}
+
+// invoke calls the operation for a request and converts its response.
+func (e *endpoint) invoke(ctx huma.Context, req *Request) *Response {
+	op := e.findOperation(req.Method)
+	if op == nil {
+		return errorResponse(nil, CodeMethodNotFound, "method not found: "+req.Method)
+	}
+
+	params := map[string]json.RawMessage{}
+	if len(req.Params) > 0 && string(req.Params) != "null" {
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return errorResponse(nil, CodeInvalidParams, "params must be an object")
+		}
+	}
+
+	r, err := newRequest(ctx, op, params)
+	if err != nil {
+		return errorResponse(nil, CodeInvalidParams, err.Error())
+	}
+	rec := httptest.NewRecorder()
+	huma.OperationHandler(e.api, op.OperationID).ServeHTTP(rec, r)
+
+	var result any
+	if rec.Body.Len() > 0 {
+		if err := json.Unmarshal(rec.Body.Bytes(), &result); err != nil {
+			// Non-JSON responses are returned as strings.
+			result = rec.Body.String()
+		}
+	}
+
+	if rec.Code >= http.StatusBadRequest {
+		code := CodeServerError
+		switch {
+		case rec.Code == http.StatusBadRequest || rec.Code == http.StatusUnprocessableEntity:
+			code = CodeInvalidParams
+		case rec.Code >= http.StatusInternalServerError:
+			code = CodeInternalError
+		}
+		message := http.StatusText(rec.Code)
+		if m, ok := result.(map[string]any); ok {
+			if detail, ok := m["detail"].(string); ok && detail != "" {
+				message = detail
+			}
+		}
+		return &Response{Error: &Error{Code: code, Message: message, Data: result}}
+	}
+
+	if result == nil {
+		// A successful response must have a result, so use `null` for
+		// operations without a response body.
+		result = json.RawMessage("null")
+	}
+	return &Response{Result: result}
+}
+
+// newRequest creates an internal request for an operation from the params,
+// forwarding the headers of the JSON-RPC request, e.g. for authentication.
+func newRequest(ctx huma.Context, op *huma.Operation, params map[string]json.RawMessage) (*http.Request, error) {
+	path := op.Path
+	query := url.Values{}
+	headers := http.Header{}
+	for _, p := range op.Parameters {
+		raw, ok := params[p.Name]
+		if !ok {
+			continue
+		}
+		delete(params, p.Name)
+		value, err := formatParam(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid param %s: %w", p.Name, err)
+		}
+		switch p.In {
+		case "path":
+			path = strings.ReplaceAll(path, "{"+p.Name+"}", url.PathEscape(value))
+		case "query":
+			query.Set(p.Name, value)
+		case "header":
+			headers.Set(p.Name, value)
+		case "cookie":
+			headers.Add("Cookie", (&http.Cookie{Name: p.Name, Value: value}).String())
+		}
+	}
+
+	var body io.Reader
+	if raw, ok := params["body"]; ok && op.RequestBody != nil {
+		delete(params, "body")
+		body = bytes.NewReader(raw)
+	}
+	for name := range params {
+		return nil, fmt.Errorf("unknown param %s", name)
+	}
+	if strings.Contains(path, "{") {
+		return nil, fmt.Errorf("missing required path params")
+	}
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx.Context(), op.Method, path, body)
+	if err != nil {
+		return nil, err
+	}
+	ctx.EachHeader(func(name, value string) {
+		switch http.CanonicalHeaderKey(name) {
+		case "Accept", "Accept-Encoding", "Content-Length", "Content-Type":
+			return
+		}
+		req.Header.Add(name, value)
+	})
+	for name, values := range headers {
+		req.Header[name] = values
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Host = ctx.Host()
+	req.RemoteAddr = huma.RemoteAddr(ctx)
+	return req, nil
+}
+
+// formatParam formats a JSON param value as a parameter string, joining
+// arrays with commas as Huma expects.
+func formatParam(raw json.RawMessage) (string, error) {
+	var v any
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	if err := dec.Decode(&v); err != nil {
+		return "", err
+	}
+	switch v := v.(type) {
+	case []any:
+		parts := make([]string, len(v))
+		for i, item := range v {
+			b, _ := json.Marshal(item)
+			s, err := formatParam(b)
+			if err != nil {
+				return "", err
+			}
+			parts[i] = s
+		}
+		return strings.Join(parts, ","), nil
+	case map[string]any:
+		return "", fmt.Errorf("objects are not supported")
+	case nil:
+		return "", nil
+	}
+	return fmt.Sprint(v), nil
+}
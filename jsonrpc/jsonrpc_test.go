@@ -0,0 +1,196 @@
+package jsonrpc
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/humatest"
+	"github.com/stretchr/testify/assert"
+)
+
+func setup(t *testing.T) (humatest.TestAPI, *atomic.Int64) {
+	_, api := humatest.New(t)
+
+	var pings atomic.Int64
+
+	huma.Register(api, huma.Operation{
+		OperationID: "get-thing",
+		Method:      http.MethodGet,
+		Path:        "/things/{thing-id}",
+	}, func(ctx context.Context, input *struct {
+		ThingID string   `path:"thing-id"`
+		Fields  []string `query:"fields"`
+		Auth    string   `header:"Authorization"`
+	}) (*struct {
+		Body struct {
+			ID     string   `json:"id"`
+			Fields []string `json:"fields,omitempty"`
+			Auth   string   `json:"auth,omitempty"`
+		}
+	}, error) {
+		if input.ThingID == "missing" {
+			return nil, huma.Error404NotFound("thing not found")
+		}
+		if input.ThingID == "broken" {
+			return nil, huma.Error500InternalServerError("oops")
+		}
+		resp := &struct {
+			Body struct {
+				ID     string   `json:"id"`
+				Fields []string `json:"fields,omitempty"`
+				Auth   string   `json:"auth,omitempty"`
+			}
+		}{}
+		resp.Body.ID = input.ThingID
+		resp.Body.Fields = input.Fields
+		resp.Body.Auth = input.Auth
+		return resp, nil
+	})
+
+	huma.Register(api, huma.Operation{
+		OperationID: "get-new-thing",
+		Method:      http.MethodGet,
+		Path:        "/things/new",
+	}, func(ctx context.Context, input *struct{}) (*struct{ Body map[string]string }, error) {
+		return &struct{ Body map[string]string }{map[string]string{"template": "new"}}, nil
+	})
+
+	huma.Register(api, huma.Operation{
+		OperationID: "create-thing",
+		Method:      http.MethodPost,
+		Path:        "/things",
+	}, func(ctx context.Context, input *struct {
+		Body struct {
+			Name string `json:"name" minLength:"2"`
+		}
+	}) (*struct{ Body map[string]string }, error) {
+		return &struct{ Body map[string]string }{map[string]string{"name": input.Body.Name}}, nil
+	})
+
+	huma.Register(api, huma.Operation{
+		OperationID: "ping",
+		Method:      http.MethodPost,
+		Path:        "/ping",
+	}, func(ctx context.Context, input *struct{}) (*struct{}, error) {
+		pings.Add(1)
+		return nil, nil
+	})
+
+	Expose(api, Config{})
+	return api, &pings
+}
+
+func TestCall(t *testing.T) {
+	api, _ := setup(t)
+
+	resp := api.Post("/rpc", "Authorization: abc", strings.NewReader(`{
+		"jsonrpc": "2.0",
+		"method": "get-thing",
+		"params": {"thing-id": "a", "fields": ["x", "y"]},
+		"id": 1
+	}`))
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, `{"jsonrpc": "2.0", "result": {"id": "a", "fields": ["x", "y"], "auth": "abc"}, "id": 1}`, resp.Body.String())
+
+	resp = api.Post("/rpc", strings.NewReader(`{"jsonrpc": "2.0", "method": "create-thing", "params": {"body": {"name": "Thing"}}, "id": "abc"}`))
+	assert.JSONEq(t, `{"jsonrpc": "2.0", "result": {"name": "Thing"}, "id": "abc"}`, resp.Body.String())
+
+	resp = api.Post("/rpc", strings.NewReader(`{"jsonrpc": "2.0", "method": "ping", "id": 2}`))
+	assert.JSONEq(t, `{"jsonrpc": "2.0", "result": null, "id": 2}`, resp.Body.String())
+}
+
+func TestNotification(t *testing.T) {
+	api, pings := setup(t)
+
+	resp := api.Post("/rpc", strings.NewReader(`{"jsonrpc": "2.0", "method": "ping"}`))
+	assert.Equal(t, http.StatusNoContent, resp.Code)
+	assert.Empty(t, resp.Body.String())
+	assert.Equal(t, int64(1), pings.Load())
+}
+
+func TestBatch(t *testing.T) {
+	api, pings := setup(t)
+
+	resp := api.Post("/rpc", strings.NewReader(`[
+		{"jsonrpc": "2.0", "method": "get-thing", "params": {"thing-id": "a"}, "id": 1},
+		{"jsonrpc": "2.0", "method": "ping"},
+		{"jsonrpc": "2.0", "method": "get-thing", "params": {"thing-id": "missing"}, "id": 2},
+		{"foo": "bar"}
+	]`))
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, `[
+		{"jsonrpc": "2.0", "result": {"id": "a"}, "id": 1},
+		{"jsonrpc": "2.0", "error": {"code": -32000, "message": "thing not found", "data": {
+			"title": "Not Found", "status": 404, "detail": "thing not found"
+		}}, "id": 2},
+		{"jsonrpc": "2.0", "error": {"code": -32600, "message": "invalid request: jsonrpc must be \"2.0\" and method is required"}, "id": null}
+	]`, resp.Body.String())
+	assert.Equal(t, int64(1), pings.Load())
+
+	// Batches of only notifications get no response.
+	resp = api.Post("/rpc", strings.NewReader(`[{"jsonrpc": "2.0", "method": "ping"}]`))
+	assert.Equal(t, http.StatusNoContent, resp.Code)
+}
+
+func TestErrors(t *testing.T) {
+	api, _ := setup(t)
+
+	for _, tc := range []struct {
+		name string
+		body string
+		code int
+	}{
+		{"parse", `{"jsonrpc": `, CodeParseError},
+		{"empty batch", `[]`, CodeInvalidRequest},
+		{"version", `{"jsonrpc": "1.0", "method": "ping", "id": 1}`, CodeInvalidRequest},
+		{"method", `{"jsonrpc": "2.0", "method": "unknown", "id": 1}`, CodeMethodNotFound},
+		{"positional", `{"jsonrpc": "2.0", "method": "get-thing", "params": ["a"], "id": 1}`, CodeInvalidParams},
+		{"unknown param", `{"jsonrpc": "2.0", "method": "get-thing", "params": {"thing-id": "a", "foo": 1}, "id": 1}`, CodeInvalidParams},
+		{"missing path", `{"jsonrpc": "2.0", "method": "get-thing", "params": {}, "id": 1}`, CodeInvalidParams},
+		{"validation", `{"jsonrpc": "2.0", "method": "create-thing", "params": {"body": {"name": "a"}}, "id": 1}`, CodeInvalidParams},
+		{"internal", `{"jsonrpc": "2.0", "method": "get-thing", "params": {"thing-id": "broken"}, "id": 1}`, CodeInternalError},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			resp := api.Post("/rpc", strings.NewReader(tc.body))
+			assert.Equal(t, http.StatusOK, resp.Code)
+			assert.Contains(t, resp.Body.String(), `"error":{"code":`+strconv.Itoa(tc.code))
+		})
+	}
+}
+
+func TestCallOperation(t *testing.T) {
+	api, _ := setup(t)
+
+	// Methods call their own operation, even when the router would match the
+	// request path to a different one.
+	resp := api.Post("/rpc", strings.NewReader(`{"jsonrpc": "2.0", "method": "get-thing", "params": {"thing-id": "new"}, "id": 1}`))
+	assert.JSONEq(t, `{"jsonrpc": "2.0", "result": {"id": "new"}, "id": 1}`, resp.Body.String())
+
+	resp = api.Post("/rpc", strings.NewReader(`{"jsonrpc": "2.0", "method": "get-new-thing", "id": 2}`))
+	assert.JSONEq(t, `{"jsonrpc": "2.0", "result": {"template": "new"}, "id": 2}`, resp.Body.String())
+}
+
+func TestParseError(t *testing.T) {
+	api, pings := setup(t)
+
+	resp := api.Post("/rpc", strings.NewReader(`{"jsonrpc": "2.0", "method": "ping", "id": 1`))
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, `{"jsonrpc": "2.0", "error": {"code": -32700, "message": "invalid JSON"}, "id": null}`, resp.Body.String())
+	assert.Equal(t, int64(0), pings.Load())
+}
+
+func TestTooLarge(t *testing.T) {
+	config := huma.DefaultConfig("Test API", "1.0.0")
+	config.MaxBodyBytes = 32
+	_, api := humatest.New(t, config)
+	Expose(api, Config{})
+
+	resp := api.Post("/rpc", strings.NewReader(`{"jsonrpc": "2.0", "method": "ping", "params": {"body": "`+strings.Repeat("a", 64)+`"}, "id": 1}`))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, resp.Code)
+	assert.JSONEq(t, `{"jsonrpc": "2.0", "error": {"code": -32600, "message": "request body is too large limit=32 bytes"}, "id": null}`, resp.Body.String())
+}
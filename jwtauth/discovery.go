@@ -0,0 +1,135 @@
+package jwtauth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// discoveryPath is appended to the issuer to get the OpenID Connect discovery
+// document, as described in OpenID Connect Discovery 1.0 section 4.
+const discoveryPath = "/.well-known/openid-configuration"
+
+// Discovery is the OpenID Connect provider metadata from an issuer's
+// discovery document. Use `Discover` to fetch it.
+type Discovery struct {
+	// URL is the URL the document was fetched from.
+	URL string `json:"-"`
+
+	Issuer                string   `json:"issuer"`
+	AuthorizationEndpoint string   `json:"authorization_endpoint"`
+	TokenEndpoint         string   `json:"token_endpoint"`
+	JWKSURI               string   `json:"jwks_uri"`
+	ScopesSupported       []string `json:"scopes_supported"`
+	GrantTypesSupported   []string `json:"grant_types_supported"`
+}
+
+// Discover fetches the OpenID Connect discovery document for the issuer,
+// e.g. `https://auth.example.com/`. The issuer in the document must match.
+// If `client` is nil, a client with a ten second timeout is used.
+//
+//	discovery, err := jwtauth.Discover(ctx, "https://auth.example.com/", nil)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	jwtauth.Use(api, jwtauth.Config{
+//		Discovery: discovery,
+//		Audience:  "my-api",
+//	})
+func Discover(ctx context.Context, issuer string, client *http.Client) (*Discovery, error) {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	u := strings.TrimSuffix(issuer, "/") + discoveryPath
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetching discovery document: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching discovery document: unexpected status %d", resp.StatusCode)
+	}
+
+	d := &Discovery{URL: u}
+	if err := json.NewDecoder(resp.Body).Decode(d); err != nil {
+		return nil, fmt.Errorf("decoding discovery document: %w", err)
+	}
+	if d.Issuer != issuer {
+		return nil, fmt.Errorf("discovery document issuer %q does not match %q", d.Issuer, issuer)
+	}
+	if d.JWKSURI == "" {
+		return nil, fmt.Errorf("discovery document is missing jwks_uri")
+	}
+	return d, nil
+}
+
+// supportsGrant returns whether the provider supports the grant type. Per
+// the spec, authorization code and implicit are the defaults when the
+// provider does not list its grant types.
+func (d *Discovery) supportsGrant(grant string) bool {
+	if d.GrantTypesSupported == nil {
+		return grant == "authorization_code" || grant == "implicit"
+	}
+	return contains(d.GrantTypesSupported, grant)
+}
+
+// securityScheme returns an OAuth2 security scheme with a flow for each
+// supported grant, or an OpenID Connect scheme if no flows can be described.
+func (d *Discovery) securityScheme() *huma.SecurityScheme {
+	scopes := map[string]string{}
+	for _, s := range d.ScopesSupported {
+		scopes[s] = ""
+	}
+
+	flows := &huma.OAuthFlows{}
+	if d.AuthorizationEndpoint != "" && d.TokenEndpoint != "" && d.supportsGrant("authorization_code") {
+		flows.AuthorizationCode = &huma.OAuthFlow{
+			AuthorizationURL: d.AuthorizationEndpoint,
+			TokenURL:         d.TokenEndpoint,
+			Scopes:           scopes,
+		}
+	}
+	if d.TokenEndpoint != "" && d.supportsGrant("client_credentials") {
+		flows.ClientCredentials = &huma.OAuthFlow{
+			TokenURL: d.TokenEndpoint,
+			Scopes:   scopes,
+		}
+	}
+	if flows.AuthorizationCode == nil && flows.ClientCredentials == nil {
+		return &huma.SecurityScheme{
+			Type:             "openIdConnect",
+			OpenIDConnectURL: d.URL,
+		}
+	}
+	return &huma.SecurityScheme{Type: "oauth2", Flows: flows}
+}
+
+// addScopes declares any scopes required by the operation which are missing
+// from the OAuth2 flows of the scheme, keeping the OpenAPI valid.
+func addScopes(scheme *huma.SecurityScheme, req requirement) {
+	if scheme.Flows == nil {
+		return
+	}
+	for _, flow := range []*huma.OAuthFlow{scheme.Flows.AuthorizationCode, scheme.Flows.ClientCredentials} {
+		if flow == nil {
+			continue
+		}
+		for _, scopes := range req.scopes {
+			for _, s := range scopes {
+				if _, ok := flow.Scopes[s]; !ok {
+					flow.Scopes[s] = ""
+				}
+			}
+		}
+	}
+}
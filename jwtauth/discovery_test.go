@@ -0,0 +1,130 @@
+package jwtauth
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/humatest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newDiscoveryServer(t *testing.T, keys testKeys, overrides map[string]any) *httptest.Server {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/.well-known/openid-configuration":
+			doc := map[string]any{
+				"issuer":                 server.URL + "/",
+				"authorization_endpoint": server.URL + "/authorize",
+				"token_endpoint":         server.URL + "/token",
+				"jwks_uri":               server.URL + "/jwks",
+				"scopes_supported":       []string{"openid", "items:read"},
+				"grant_types_supported":  []string{"authorization_code", "client_credentials"},
+			}
+			for k, v := range overrides {
+				doc[k] = v
+			}
+			json.NewEncoder(w).Encode(doc)
+		case "/jwks":
+			json.NewEncoder(w).Encode(keys.jwks())
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestDiscovery(t *testing.T) {
+	keys := newTestKeys(t)
+	server := newDiscoveryServer(t, keys, nil)
+	issuer := server.URL + "/"
+
+	d, err := Discover(context.Background(), issuer, nil)
+	require.NoError(t, err)
+	assert.Equal(t, server.URL+"/jwks", d.JWKSURI)
+
+	_, api := humatest.New(t, huma.DefaultConfig("Test API", "1.0.0"))
+	Use(api, Config{SchemeName: "oidc", Discovery: d})
+
+	huma.Register(api, huma.Operation{
+		OperationID: "write",
+		Method:      http.MethodGet,
+		Path:        "/write",
+		Security:    []map[string][]string{{"oidc": {"items:write"}}},
+	}, func(ctx context.Context, input *struct{}) (*struct{}, error) {
+		return nil, nil
+	})
+
+	// The security scheme is added to the OpenAPI.
+	scheme := api.OpenAPI().Components.SecuritySchemes["oidc"]
+	require.NotNil(t, scheme)
+	assert.Equal(t, "oauth2", scheme.Type)
+	require.NotNil(t, scheme.Flows.AuthorizationCode)
+	assert.Equal(t, server.URL+"/authorize", scheme.Flows.AuthorizationCode.AuthorizationURL)
+	require.NotNil(t, scheme.Flows.ClientCredentials)
+	assert.Equal(t, server.URL+"/token", scheme.Flows.ClientCredentials.TokenURL)
+	assert.Contains(t, scheme.Flows.ClientCredentials.Scopes, "items:read")
+	assert.Contains(t, scheme.Flows.ClientCredentials.Scopes, "items:write")
+
+	// Keys and issuer come from the discovery document.
+	claims := map[string]any{
+		"sub":   "alice",
+		"iss":   issuer,
+		"exp":   time.Now().Add(time.Hour).Unix(),
+		"scope": "items:write",
+	}
+	resp := api.Get("/write", "Authorization: Bearer "+sign(t, "RS256", "rsa", keys.rsa, claims))
+	assert.Equal(t, http.StatusNoContent, resp.Code)
+
+	claims["iss"] = "https://evil.com/"
+	resp = api.Get("/write", "Authorization: Bearer "+sign(t, "RS256", "rsa", keys.rsa, claims))
+	assert.Equal(t, http.StatusUnauthorized, resp.Code)
+}
+
+func TestDiscoveryExistingScheme(t *testing.T) {
+	server := newDiscoveryServer(t, newTestKeys(t), nil)
+	d, err := Discover(context.Background(), server.URL+"/", nil)
+	require.NoError(t, err)
+
+	config := huma.DefaultConfig("Test API", "1.0.0")
+	existing := &huma.SecurityScheme{Type: "http", Scheme: "bearer"}
+	config.Components.SecuritySchemes = map[string]*huma.SecurityScheme{"bearer": existing}
+	_, api := humatest.New(t, config)
+	Use(api, Config{Discovery: d})
+
+	assert.Same(t, existing, api.OpenAPI().Components.SecuritySchemes["bearer"])
+}
+
+func TestDiscoveryOpenIDConnectScheme(t *testing.T) {
+	server := newDiscoveryServer(t, newTestKeys(t), map[string]any{
+		"grant_types_supported": []string{"urn:ietf:params:oauth:grant-type:device_code"},
+	})
+	d, err := Discover(context.Background(), server.URL+"/", nil)
+	require.NoError(t, err)
+
+	scheme := d.securityScheme()
+	assert.Equal(t, "openIdConnect", scheme.Type)
+	assert.Equal(t, server.URL+"/.well-known/openid-configuration", scheme.OpenIDConnectURL)
+}
+
+func TestDiscoveryErrors(t *testing.T) {
+	keys := newTestKeys(t)
+
+	server := newDiscoveryServer(t, keys, map[string]any{"issuer": "https://evil.com/"})
+	_, err := Discover(context.Background(), server.URL+"/", nil)
+	assert.ErrorContains(t, err, "does not match")
+
+	server = newDiscoveryServer(t, keys, map[string]any{"jwks_uri": ""})
+	_, err = Discover(context.Background(), server.URL+"/", nil)
+	assert.ErrorContains(t, err, "jwks_uri")
+
+	_, err = Discover(context.Background(), server.URL+"/missing/", nil)
+	assert.ErrorContains(t, err, "unexpected status 404")
+}
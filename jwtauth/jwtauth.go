@@ -0,0 +1,470 @@
+// Package jwtauth provides JWT bearer token authentication for Huma APIs. It
+// validates tokens against the signing keys from a JWKS URL, which may be
+// found via OpenID Connect discovery (see `Discover`), checks the
+// issuer, audience, and expiry, and enforces the scopes declared in each
+// operation's `Security` requirements for the configured security scheme.
+// The validated claims are available to handlers via `jwtauth.GetClaims`.
+//
+//	config := huma.DefaultConfig("My API", "1.0.0")
+//	config.Components.SecuritySchemes = map[string]*huma.SecurityScheme{
+//		"bearer": {Type: "http", Scheme: "bearer", BearerFormat: "JWT"},
+//	}
+//	api := humachi.New(router, config)
+//	jwtauth.Use(api, jwtauth.Config{
+//		SchemeName: "bearer",
+//		JWKSURL:    "https://auth.example.com/.well-known/jwks.json",
+//		Issuer:     "https://auth.example.com/",
+//		Audience:   "my-api",
+//	})
+//
+//	huma.Register(api, huma.Operation{
+//		OperationID: "delete-item",
+//		Method:      http.MethodDelete,
+//		Path:        "/items/{id}",
+//		Security:    []map[string][]string{{"bearer": {"items:write"}}},
+//	}, handler)
+package jwtauth
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+var (
+	errMissingToken     = errors.New("missing bearer token")
+	errMalformedToken   = errors.New("malformed token")
+	errInvalidSignature = errors.New("invalid token signature")
+)
+
+// Config configures JWT bearer authentication.
+type Config struct {
+	// SchemeName is the name of the security scheme in
+	// `OpenAPI.Components.SecuritySchemes` which this validates. Operations
+	// whose security requirements include this scheme are authenticated.
+	// Defaults to `bearer`.
+	SchemeName string
+
+	// Discovery is OpenID Connect provider metadata from `Discover`. When set,
+	// `JWKSURL` and `Issuer` default to the provider's values, and if the
+	// scheme is not declared in the OpenAPI, an OAuth2 security scheme with
+	// the provider's endpoints and scopes is added.
+	Discovery *Discovery
+
+	// JWKSURL is the URL of the JSON Web Key Set containing the keys used to
+	// sign tokens. Keys are fetched on first use and cached.
+	JWKSURL string
+
+	// Keys are static public keys by key ID, as an alternative to `JWKSURL`.
+	// Use an empty key ID to match tokens without a `kid` header.
+	Keys map[string]crypto.PublicKey
+
+	// Issuer is the required `iss` claim, if set.
+	Issuer string
+
+	// Audience is the required `aud` claim, if set.
+	Audience string
+
+	// Leeway allows for clock skew when checking the `exp` and `nbf` claims.
+	Leeway time.Duration
+
+	// RefreshInterval is how often the JWKS is refreshed. Defaults to one hour.
+	// Tokens with an unknown key ID also trigger a refresh, at most once per
+	// minute.
+	RefreshInterval time.Duration
+
+	// HTTPClient is used to fetch the JWKS. Defaults to a client with a
+	// ten second timeout.
+	HTTPClient *http.Client
+}
+
+// Claims are the claims of a validated token.
+type Claims map[string]any
+
+// Subject returns the `sub` claim.
+func (c Claims) Subject() string {
+	s, _ := c["sub"].(string)
+	return s
+}
+
+// Scopes returns the token's scopes from either the space-separated `scope`
+// claim or the `scp` claim, which may be a string or a list.
+func (c Claims) Scopes() []string {
+	if s, ok := c["scope"].(string); ok {
+		return strings.Fields(s)
+	}
+	switch scp := c["scp"].(type) {
+	case string:
+		return strings.Fields(scp)
+	case []any:
+		scopes := make([]string, 0, len(scp))
+		for _, s := range scp {
+			if str, ok := s.(string); ok {
+				scopes = append(scopes, str)
+			}
+		}
+		return scopes
+	}
+	return nil
+}
+
+// hasAudience returns whether the `aud` claim, which may be a string or a
+// list, contains the audience.
+func (c Claims) hasAudience(aud string) bool {
+	switch v := c["aud"].(type) {
+	case string:
+		return v == aud
+	case []any:
+		for _, a := range v {
+			if a == aud {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// maxNumericDate bounds numeric date claims in seconds, so that converting
+// them to a `time.Time` cannot overflow.
+const maxNumericDate = 1 << 62
+
+// time returns a numeric date claim, if present. Dates which are out of range
+// return an error rather than overflowing.
+func (c Claims) time(name string) (time.Time, bool, error) {
+	n, ok := c[name].(json.Number)
+	if !ok {
+		return time.Time{}, false, nil
+	}
+	f, err := n.Float64()
+	if err != nil || f <= -maxNumericDate || f >= maxNumericDate {
+		return time.Time{}, false, fmt.Errorf("invalid %s claim", name)
+	}
+	sec, frac := math.Modf(f)
+	return time.Unix(int64(sec), int64(frac*float64(time.Second))), true, nil
+}
+
+type claimsKey struct{}
+
+// GetClaims returns the validated token claims for the current request, or
+// nil if the request was not authenticated.
+//
+//	func handler(ctx context.Context, input *MyInput) (*MyOutput, error) {
+//		user := jwtauth.GetClaims(ctx).Subject()
+//		// ...
+//	}
+func GetClaims(ctx context.Context) Claims {
+	c, _ := ctx.Value(claimsKey{}).(Claims)
+	return c
+}
+
+// claimsContext overrides the request context to include the claims.
+type claimsContext struct {
+	huma.ContextWrapper
+	ctx context.Context
+}
+
+func (c *claimsContext) Context() context.Context {
+	return c.ctx
+}
+
+// validator validates tokens using the configured keys and claims.
+type validator struct {
+	config Config
+	keys   *keySet
+}
+
+func (v *validator) key(ctx context.Context, kid string) (key, error) {
+	if v.config.Keys != nil {
+		if pub, ok := v.config.Keys[kid]; ok {
+			return key{pub: pub}, nil
+		}
+		if v.keys == nil {
+			return key{}, fmt.Errorf("unknown key %q", kid)
+		}
+	}
+	return v.keys.get(ctx, kid)
+}
+
+// validate parses and validates a compact serialized JWT.
+func (v *validator) validate(ctx context.Context, token string) (Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errMalformedToken
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, errMalformedToken
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, errMalformedToken
+	}
+
+	k, err := v.key(ctx, header.Kid)
+	if err != nil {
+		return nil, err
+	}
+	if k.alg != "" && k.alg != header.Alg {
+		return nil, fmt.Errorf("algorithm %q not allowed for key", header.Alg)
+	}
+	if err := verify(header.Alg, k.pub, []byte(parts[0]+"."+parts[1]), sig); err != nil {
+		return nil, err
+	}
+
+	var claims Claims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, errMalformedToken
+	}
+
+	now := time.Now()
+	exp, ok, err := claims.time("exp")
+	if err != nil {
+		return nil, err
+	}
+	if ok && now.After(exp.Add(v.config.Leeway)) {
+		return nil, errors.New("token is expired")
+	}
+	nbf, ok, err := claims.time("nbf")
+	if err != nil {
+		return nil, err
+	}
+	if ok && now.Before(nbf.Add(-v.config.Leeway)) {
+		return nil, errors.New("token is not valid yet")
+	}
+	if v.config.Issuer != "" && claims["iss"] != v.config.Issuer {
+		return nil, errors.New("invalid token issuer")
+	}
+	if v.config.Audience != "" && !claims.hasAudience(v.config.Audience) {
+		return nil, errors.New("invalid token audience")
+	}
+
+	return claims, nil
+}
+
+func decodeSegment(s string, v any) error {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return err
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	return dec.Decode(v)
+}
+
+// requirement describes how an operation uses the security scheme.
+type requirement struct {
+	// required is true when every security alternative includes the scheme,
+	// so requests without a token are rejected.
+	required bool
+
+	// scopes lists the scopes for each alternative which includes the scheme.
+	// A token must have all the scopes of at least one alternative.
+	scopes [][]string
+}
+
+// requirement returns how the operation uses the scheme, or false if it
+// does not use it at all.
+func (c *Config) requirement(oapi *huma.OpenAPI, op *huma.Operation) (requirement, bool) {
+	security := op.Security
+	if security == nil {
+		security = oapi.Security
+	}
+
+	r := requirement{required: len(security) > 0}
+	for _, alternative := range security {
+		scopes, ok := alternative[c.SchemeName]
+		if !ok {
+			r.required = false
+			continue
+		}
+		r.scopes = append(r.scopes, scopes)
+	}
+	return r, len(r.scopes) > 0
+}
+
+// allowed returns whether the granted scopes satisfy the requirement.
+func (r requirement) allowed(granted []string) bool {
+	for _, scopes := range r.scopes {
+		ok := true
+		for _, s := range scopes {
+			if !contains(granted, s) {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(values []string, v string) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
+
+// bearerToken returns the token from the `Authorization` header.
+func bearerToken(ctx huma.Context) string {
+	auth := ctx.Header("Authorization")
+	if len(auth) > 7 && strings.EqualFold(auth[:7], "bearer ") {
+		return strings.TrimSpace(auth[7:])
+	}
+	return ""
+}
+
+// Use adds JWT authentication middleware to the API and documents the `401`
+// and `403` responses for authenticated operations in the OpenAPI. Like other
+// middleware, it must be called before registering operations with
+// `huma.Register` in order to apply to them.
+//
+// Operations are authenticated when their `Security` requirements (or the
+// top-level `OpenAPI.Security` if unset) include the configured scheme. When
+// every alternative requires the scheme, requests without a valid token get
+// a `401 Unauthorized`. When another alternative could be used instead, e.g.
+// an API key or `{}` for anonymous access, tokens are validated if present
+// but not required. Tokens missing the required scopes get a
+// `403 Forbidden`.
+func Use(api huma.API, config Config) {
+	if config.SchemeName == "" {
+		config.SchemeName = "bearer"
+	}
+	if config.RefreshInterval == 0 {
+		config.RefreshInterval = time.Hour
+	}
+	if config.HTTPClient == nil {
+		config.HTTPClient = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	oapi := api.OpenAPI()
+	if d := config.Discovery; d != nil {
+		if config.JWKSURL == "" {
+			config.JWKSURL = d.JWKSURI
+		}
+		if config.Issuer == "" {
+			config.Issuer = d.Issuer
+		}
+		if oapi.Components == nil {
+			oapi.Components = &huma.Components{}
+		}
+		if oapi.Components.SecuritySchemes == nil {
+			oapi.Components.SecuritySchemes = map[string]*huma.SecurityScheme{}
+		}
+		if oapi.Components.SecuritySchemes[config.SchemeName] == nil {
+			scheme := d.securityScheme()
+			oapi.Components.SecuritySchemes[config.SchemeName] = scheme
+			oapi.OnAddOperation = append(oapi.OnAddOperation, func(oapi *huma.OpenAPI, op *huma.Operation) {
+				if req, ok := config.requirement(oapi, op); ok {
+					addScopes(scheme, req)
+				}
+			})
+		}
+	}
+
+	v := &validator{config: config}
+	if config.JWKSURL != "" {
+		v.keys = &keySet{
+			url:     config.JWKSURL,
+			client:  config.HTTPClient,
+			refresh: config.RefreshInterval,
+		}
+	} else if config.Keys == nil {
+		panic("jwtauth: either JWKSURL, Keys, or Discovery must be set")
+	}
+
+	oapi.OnAddOperation = append(oapi.OnAddOperation, config.document)
+
+	api.UseMiddleware(func(ctx huma.Context, next func(huma.Context)) {
+		req, ok := config.requirement(oapi, ctx.Operation())
+		if !ok {
+			next(ctx)
+			return
+		}
+
+		token := bearerToken(ctx)
+		if token == "" {
+			if req.required {
+				unauthorized(api, ctx, errMissingToken)
+				return
+			}
+			next(ctx)
+			return
+		}
+
+		claims, err := v.validate(ctx.Context(), token)
+		if err != nil {
+			unauthorized(api, ctx, err)
+			return
+		}
+
+		if !req.allowed(claims.Scopes()) {
+			scopes := req.scopes[0]
+			ctx.SetHeader("WWW-Authenticate", `Bearer error="insufficient_scope", scope="`+strings.Join(scopes, " ")+`"`)
+			huma.WriteErr(api, ctx, http.StatusForbidden, "insufficient scope", fmt.Errorf("requires scopes: %s", strings.Join(scopes, ", ")))
+			return
+		}
+
+		next(&claimsContext{
+			ContextWrapper: huma.WrapContext(ctx),
+			ctx:            context.WithValue(ctx.Context(), claimsKey{}, claims),
+		})
+	})
+}
+
+// unauthorized writes a `401` error response as described in RFC 6750.
+func unauthorized(api huma.API, ctx huma.Context, err error) {
+	if err == errMissingToken {
+		ctx.SetHeader("WWW-Authenticate", "Bearer")
+	} else {
+		ctx.SetHeader("WWW-Authenticate", `Bearer error="invalid_token", error_description=`+strconv.Quote(err.Error()))
+	}
+	huma.WriteErr(api, ctx, http.StatusUnauthorized, "unauthorized", err)
+}
+
+// document adds the `401` and `403` responses to the OpenAPI for operations
+// which use the security scheme.
+func (c *Config) document(oapi *huma.OpenAPI, op *huma.Operation) {
+	if _, ok := c.requirement(oapi, op); !ok {
+		return
+	}
+
+	if op.Responses == nil {
+		op.Responses = map[string]*huma.Response{}
+	}
+	content := huma.ErrorContent(op)
+	for _, status := range []int{http.StatusUnauthorized, http.StatusForbidden} {
+		code := strconv.Itoa(status)
+		if op.Responses[code] != nil {
+			continue
+		}
+		op.Responses[code] = &huma.Response{
+			Description: http.StatusText(status),
+			Content:     content,
+			Headers: map[string]*huma.Param{
+				"WWW-Authenticate": {
+					Description: "The authentication scheme and, on failure, the reason.",
+					Schema:      &huma.Schema{Type: huma.TypeString},
+				},
+			},
+		}
+	}
+}
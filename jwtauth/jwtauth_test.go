@@ -0,0 +1,295 @@
+package jwtauth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/humatest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func b64(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func segment(t *testing.T, v any) string {
+	b, err := json.Marshal(v)
+	require.NoError(t, err)
+	return b64(b)
+}
+
+// sign creates a compact JWT using the given algorithm and private key.
+func sign(t *testing.T, alg, kid string, priv crypto.Signer, claims map[string]any) string {
+	signed := segment(t, map[string]any{"alg": alg, "kid": kid, "typ": "JWT"}) + "." + segment(t, claims)
+	digest := sha256.Sum256([]byte(signed))
+
+	var sig []byte
+	var err error
+	switch k := priv.(type) {
+	case *rsa.PrivateKey:
+		sig, err = rsa.SignPKCS1v15(rand.Reader, k, crypto.SHA256, digest[:])
+	case *ecdsa.PrivateKey:
+		var r, s *big.Int
+		r, s, err = ecdsa.Sign(rand.Reader, k, digest[:])
+		sig = make([]byte, 64)
+		r.FillBytes(sig[:32])
+		s.FillBytes(sig[32:])
+	case ed25519.PrivateKey:
+		sig = ed25519.Sign(k, []byte(signed))
+	}
+	require.NoError(t, err)
+	return signed + "." + b64(sig)
+}
+
+type testKeys struct {
+	rsa *rsa.PrivateKey
+	ec  *ecdsa.PrivateKey
+	ed  ed25519.PrivateKey
+}
+
+func newTestKeys(t *testing.T) testKeys {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	return testKeys{rsaKey, ecKey, edKey}
+}
+
+func (k testKeys) jwks() map[string]any {
+	return map[string]any{"keys": []map[string]any{
+		{
+			"kty": "RSA", "kid": "rsa", "alg": "RS256", "use": "sig",
+			"n": b64(k.rsa.N.Bytes()),
+			"e": b64(big.NewInt(int64(k.rsa.E)).Bytes()),
+		},
+		{
+			"kty": "EC", "kid": "ec", "crv": "P-256",
+			"x": b64(k.ec.X.FillBytes(make([]byte, 32))),
+			"y": b64(k.ec.Y.FillBytes(make([]byte, 32))),
+		},
+		{
+			"kty": "OKP", "kid": "ed", "crv": "Ed25519",
+			"x": b64(k.ed.Public().(ed25519.PublicKey)),
+		},
+		{"kty": "RSA", "kid": "enc", "use": "enc", "n": "AQAB", "e": "AQAB"},
+	}}
+}
+
+func newTestAPI(t *testing.T, config Config) humatest.TestAPI {
+	hc := huma.DefaultConfig("Test API", "1.0.0")
+	hc.Components.SecuritySchemes = map[string]*huma.SecurityScheme{
+		"bearer": {Type: "http", Scheme: "bearer", BearerFormat: "JWT"},
+	}
+	_, api := humatest.New(t, hc)
+	Use(api, config)
+
+	for _, op := range []huma.Operation{
+		{OperationID: "public", Path: "/public"},
+		{OperationID: "read", Path: "/read", Security: []map[string][]string{{"bearer": {}}}},
+		{OperationID: "write", Path: "/write", Security: []map[string][]string{{"bearer": {"items:write"}}}},
+		{OperationID: "optional", Path: "/optional", Security: []map[string][]string{{"bearer": {}}, {}}},
+	} {
+		op.Method = http.MethodGet
+		huma.Register(api, op, func(ctx context.Context, input *struct{}) (*struct {
+			Body struct {
+				Subject string `json:"subject"`
+			}
+		}, error) {
+			resp := &struct {
+				Body struct {
+					Subject string `json:"subject"`
+				}
+			}{}
+			resp.Body.Subject = GetClaims(ctx).Subject()
+			return resp, nil
+		})
+	}
+	return api
+}
+
+func TestJWKS(t *testing.T) {
+	keys := newTestKeys(t)
+	var fetches atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+		json.NewEncoder(w).Encode(keys.jwks())
+	}))
+	defer server.Close()
+
+	api := newTestAPI(t, Config{
+		JWKSURL:  server.URL,
+		Issuer:   "https://auth.example.com/",
+		Audience: "test-api",
+	})
+
+	valid := map[string]any{
+		"sub":   "alice",
+		"iss":   "https://auth.example.com/",
+		"aud":   []string{"other", "test-api"},
+		"exp":   time.Now().Add(time.Hour).Unix(),
+		"scope": "items:read items:write",
+	}
+
+	for _, tc := range []struct {
+		name string
+		alg  string
+		kid  string
+		key  crypto.Signer
+	}{
+		{"rsa", "RS256", "rsa", keys.rsa},
+		{"ec", "ES256", "ec", keys.ec},
+		{"ed", "EdDSA", "ed", keys.ed},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			token := sign(t, tc.alg, tc.kid, tc.key, valid)
+			resp := api.Get("/write", "Authorization: Bearer "+token)
+			assert.Equal(t, http.StatusOK, resp.Code)
+			assert.Contains(t, resp.Body.String(), `"subject":"alice"`)
+		})
+	}
+
+	// Keys are cached.
+	assert.EqualValues(t, 1, fetches.Load())
+
+	// Algorithm confusion is rejected.
+	resp := api.Get("/read", "Authorization: Bearer "+sign(t, "ES256", "rsa", keys.ec, valid))
+	assert.Equal(t, http.StatusUnauthorized, resp.Code)
+}
+
+func TestJWKSSharedFetch(t *testing.T) {
+	keys := newTestKeys(t)
+	var fetches atomic.Int32
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+		<-release
+		json.NewEncoder(w).Encode(keys.jwks())
+	}))
+	defer server.Close()
+
+	s := &keySet{url: server.URL, client: server.Client(), refresh: time.Hour}
+
+	// A request which gives up waiting does not cancel the fetch for others.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := s.get(ctx, "ec")
+	assert.ErrorIs(t, err, context.Canceled)
+
+	done := make(chan error, 2)
+	for i := 0; i < 2; i++ {
+		go func() {
+			_, err := s.get(context.Background(), "ec")
+			done <- err
+		}()
+	}
+	close(release)
+	assert.NoError(t, <-done)
+	assert.NoError(t, <-done)
+	assert.EqualValues(t, 1, fetches.Load())
+}
+
+func TestValidation(t *testing.T) {
+	keys := newTestKeys(t)
+	other := newTestKeys(t)
+	api := newTestAPI(t, Config{
+		Keys:     map[string]crypto.PublicKey{"": keys.ec.Public()},
+		Issuer:   "https://auth.example.com/",
+		Audience: "test-api",
+	})
+
+	claims := func(overrides map[string]any) map[string]any {
+		c := map[string]any{
+			"sub": "alice",
+			"iss": "https://auth.example.com/",
+			"aud": "test-api",
+			"exp": time.Now().Add(time.Hour).Unix(),
+			"scp": []string{"items:read"},
+		}
+		for k, v := range overrides {
+			c[k] = v
+		}
+		return c
+	}
+
+	// Operations without the scheme are not authenticated.
+	assert.Equal(t, http.StatusOK, api.Get("/public").Code)
+
+	// A token is required.
+	resp := api.Get("/read")
+	assert.Equal(t, http.StatusUnauthorized, resp.Code)
+	assert.Equal(t, "Bearer", resp.Header().Get("WWW-Authenticate"))
+
+	// Unless anonymous access is allowed.
+	assert.Equal(t, http.StatusOK, api.Get("/optional").Code)
+
+	resp = api.Get("/read", "Authorization: Bearer "+sign(t, "ES256", "", keys.ec, claims(nil)))
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	for name, token := range map[string]string{
+		"malformed": "not-a-token",
+		"signature": sign(t, "ES256", "", other.ec, claims(nil)),
+		"expired":   sign(t, "ES256", "", keys.ec, claims(map[string]any{"exp": time.Now().Add(-time.Minute).Unix()})),
+		"nbf":       sign(t, "ES256", "", keys.ec, claims(map[string]any{"nbf": time.Now().Add(time.Minute).Unix()})),
+		"issuer":    sign(t, "ES256", "", keys.ec, claims(map[string]any{"iss": "https://evil.com/"})),
+		"audience":  sign(t, "ES256", "", keys.ec, claims(map[string]any{"aud": "other"})),
+		"kid":       sign(t, "ES256", "unknown", keys.ec, claims(nil)),
+		"exp range": sign(t, "ES256", "", keys.ec, claims(map[string]any{"exp": 1e20})),
+		"nbf range": sign(t, "ES256", "", keys.ec, claims(map[string]any{"nbf": -1e20})),
+	} {
+		t.Run(name, func(t *testing.T) {
+			resp := api.Get("/read", "Authorization: Bearer "+token)
+			assert.Equal(t, http.StatusUnauthorized, resp.Code)
+			assert.Contains(t, resp.Header().Get("WWW-Authenticate"), `error="invalid_token"`)
+
+			// Invalid tokens are rejected even when they are optional.
+			assert.Equal(t, http.StatusUnauthorized, api.Get("/optional", "Authorization: Bearer "+token).Code)
+		})
+	}
+
+	// Scopes are enforced.
+	resp = api.Get("/write", "Authorization: Bearer "+sign(t, "ES256", "", keys.ec, claims(nil)))
+	assert.Equal(t, http.StatusForbidden, resp.Code)
+	assert.Equal(t, `Bearer error="insufficient_scope", scope="items:write"`, resp.Header().Get("WWW-Authenticate"))
+	assert.Contains(t, resp.Body.String(), "items:write")
+}
+
+func TestDocumented(t *testing.T) {
+	keys := newTestKeys(t)
+	api := newTestAPI(t, Config{Keys: map[string]crypto.PublicKey{"": keys.ec.Public()}})
+
+	paths := api.OpenAPI().Paths
+	assert.Nil(t, paths["/public"].Get.Responses["401"])
+	for _, path := range []string{"/read", "/write", "/optional"} {
+		for _, code := range []string{"401", "403"} {
+			resp := paths[path].Get.Responses[code]
+			require.NotNil(t, resp, path+" "+code)
+			assert.NotEmpty(t, resp.Content)
+			assert.NotNil(t, resp.Headers["WWW-Authenticate"])
+		}
+	}
+}
+
+func TestConfigRequired(t *testing.T) {
+	_, api := humatest.New(t, huma.DefaultConfig("Test API", "1.0.0"))
+	assert.Panics(t, func() {
+		Use(api, Config{})
+	})
+}
@@ -0,0 +1,293 @@
+package jwtauth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	_ "crypto/sha256" // Register hashes for `crypto.Hash.New`.
+	_ "crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// jwk is a JSON Web Key as described in RFC 7517. Only public keys used for
+// signatures are supported.
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
+
+// publicKey converts the JWK into a Go public key.
+func (k *jwk) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		if !e.IsInt64() || e.Int64() > 1<<31-1 {
+			return nil, errors.New("invalid RSA exponent")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, errors.New("invalid EC point")
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, err
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid Ed25519 key")
+		}
+		return ed25519.PublicKey(x), nil
+	}
+	return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+}
+
+// keySet fetches and caches the signing keys from a JWKS URL. Keys are
+// refreshed periodically and whenever a token uses an unknown key ID, so key
+// rotation is picked up without a restart.
+type keySet struct {
+	url     string
+	client  *http.Client
+	refresh time.Duration
+
+	mu       sync.Mutex
+	keys     map[string]key
+	fetched  time.Time
+	inflight *keyFetch
+}
+
+// key is a parsed public key along with its optional algorithm restriction.
+type key struct {
+	pub crypto.PublicKey
+	alg string
+}
+
+// keyFetch is an in-progress fetch of the JWKS, shared by all requests which
+// need it. The error is set before `done` is closed.
+type keyFetch struct {
+	done chan struct{}
+	err  error
+}
+
+// minRefetch limits how often unknown key IDs can trigger a fetch, so that
+// clients cannot cause a flood of requests to the JWKS URL.
+const minRefetch = time.Minute
+
+// fetchTimeout limits how long a JWKS fetch may take. Fetches are shared
+// across requests so they don't use any one request's context.
+const fetchTimeout = 10 * time.Second
+
+func (s *keySet) get(ctx context.Context, kid string) (key, error) {
+	s.mu.Lock()
+	k, ok := s.keys[kid]
+	age := time.Since(s.fetched)
+	if (ok && age <= s.refresh) || (!ok && age <= minRefetch && s.inflight == nil) {
+		s.mu.Unlock()
+		if !ok {
+			return key{}, fmt.Errorf("unknown key %q", kid)
+		}
+		return k, nil
+	}
+	f := s.startFetch()
+	s.mu.Unlock()
+
+	if ok {
+		// Keep using the cached key while it is refreshed in the background.
+		return k, nil
+	}
+
+	select {
+	case <-f.done:
+	case <-ctx.Done():
+		return key{}, ctx.Err()
+	}
+	if f.err != nil {
+		return key{}, f.err
+	}
+
+	s.mu.Lock()
+	k, ok = s.keys[kid]
+	s.mu.Unlock()
+	if !ok {
+		return key{}, fmt.Errorf("unknown key %q", kid)
+	}
+	return k, nil
+}
+
+// startFetch starts fetching the keys in the background unless a fetch is
+// already in progress, and returns the fetch. Must be called with the lock
+// held.
+func (s *keySet) startFetch() *keyFetch {
+	if s.inflight != nil {
+		return s.inflight
+	}
+	s.fetched = time.Now()
+	f := &keyFetch{done: make(chan struct{})}
+	s.inflight = f
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), fetchTimeout)
+		defer cancel()
+		keys, err := s.fetch(ctx)
+
+		s.mu.Lock()
+		if err == nil {
+			s.keys = keys
+		}
+		s.inflight = nil
+		s.mu.Unlock()
+
+		f.err = err
+		close(f.done)
+	}()
+	return f
+}
+
+// fetch loads the keys from the JWKS URL.
+func (s *keySet) fetch(ctx context.Context) (map[string]key, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetching JWKS: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching JWKS: unexpected status %d", resp.StatusCode)
+	}
+
+	var doc struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("decoding JWKS: %w", err)
+	}
+
+	keys := make(map[string]key, len(doc.Keys))
+	for i := range doc.Keys {
+		k := &doc.Keys[i]
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		pub, err := k.publicKey()
+		if err != nil {
+			// Skip unsupported keys rather than failing the whole set.
+			continue
+		}
+		keys[k.Kid] = key{pub: pub, alg: k.Alg}
+	}
+	return keys, nil
+}
+
+// ecdsaAlgs maps each curve to the only algorithm allowed to use it.
+var ecdsaAlgs = map[elliptic.Curve]string{
+	elliptic.P256(): "ES256",
+	elliptic.P384(): "ES384",
+	elliptic.P521(): "ES512",
+}
+
+// verify checks the token signature for the given algorithm and key. The
+// algorithm must match the key type to prevent algorithm confusion attacks.
+func verify(alg string, pub crypto.PublicKey, signed, sig []byte) error {
+	var hash crypto.Hash
+	switch alg {
+	case "RS256", "PS256", "ES256":
+		hash = crypto.SHA256
+	case "RS384", "PS384", "ES384":
+		hash = crypto.SHA384
+	case "RS512", "PS512", "ES512":
+		hash = crypto.SHA512
+	case "EdDSA":
+		if k, ok := pub.(ed25519.PublicKey); ok && ed25519.Verify(k, signed, sig) {
+			return nil
+		}
+		return errInvalidSignature
+	default:
+		return fmt.Errorf("unsupported algorithm %q", alg)
+	}
+
+	h := hash.New()
+	h.Write(signed)
+	digest := h.Sum(nil)
+
+	switch k := pub.(type) {
+	case *rsa.PublicKey:
+		switch alg[:2] {
+		case "RS":
+			if rsa.VerifyPKCS1v15(k, hash, digest, sig) == nil {
+				return nil
+			}
+		case "PS":
+			if rsa.VerifyPSS(k, hash, digest, sig, nil) == nil {
+				return nil
+			}
+		}
+	case *ecdsa.PublicKey:
+		size := (k.Curve.Params().BitSize + 7) / 8
+		if ecdsaAlgs[k.Curve] == alg && len(sig) == 2*size {
+			r := new(big.Int).SetBytes(sig[:size])
+			s := new(big.Int).SetBytes(sig[size:])
+			if ecdsa.Verify(k, digest, r, s) {
+				return nil
+			}
+		}
+	}
+	return errInvalidSignature
+}
@@ -0,0 +1,60 @@
+package huma
+
+import (
+	"context"
+)
+
+type validationWarningsKey struct{}
+
+// ValidationWarningsFromContext returns the validation errors which were
+// treated as warnings for the current request because their schema keywords
+// are listed in `Operation.LenientValidation`. The `EnvelopeTransformer`
+// includes them in the response's `meta.warnings`.
+func ValidationWarningsFromContext(ctx context.Context) []error {
+	warnings, _ := ctx.Value(validationWarningsKey{}).([]error)
+	return warnings
+}
+
+// takeLenient moves the errors for lenient schema keywords out of the
+// validation result, appending copies of them to `warnings`.
+func takeLenient(res *ValidateResult, lenient map[string]bool, warnings []error) []error {
+	if len(lenient) == 0 {
+		return warnings
+	}
+	kept := res.Errors[:0]
+	for _, err := range res.Errors {
+		if d, ok := err.(*ErrorDetail); ok && d.keyword != "" && lenient[d.keyword] {
+			c := *d
+			warnings = append(warnings, &c)
+			continue
+		}
+		kept = append(kept, err)
+	}
+	res.Errors = kept
+	return warnings
+}
+
+// validationWarning returns the `Warning` header value sent for a lenient
+// validation failure. The value is left out as it may be large.
+func validationWarning(err error) string {
+	msg := err.Error()
+	if d, ok := err.(*ErrorDetail); ok {
+		msg = d.Location + ": " + d.Message
+	}
+	return `299 - "` + warningEscaper.Replace(msg) + `"`
+}
+
+// validationWarningsContext overrides the request context to include the
+// validation warnings.
+type validationWarningsContext struct {
+	humaContext
+	ctx context.Context
+}
+
+func (c *validationWarningsContext) Context() context.Context {
+	return c.ctx
+}
+
+func (c *validationWarningsContext) Unwrap() Context {
+	return c.humaContext
+}
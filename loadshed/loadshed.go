@@ -0,0 +1,230 @@
+// Package loadshed provides overload protection for Huma APIs by limiting
+// the number of requests handled concurrently, per operation or per tag.
+// Requests over the limit wait in a bounded queue, and once the queue is
+// full they receive a `503 Service Unavailable` error with a `Retry-After`
+// header. This keeps a slow downstream dependency from tying up every
+// request and taking out the whole service. The 503 response is documented
+// in the OpenAPI for each limited operation.
+//
+//	loadshed.Use(api, loadshed.Config{
+//		Default: &loadshed.Limit{MaxConcurrent: 100, MaxQueue: 50},
+//		Tags: map[string]loadshed.Limit{
+//			"Reports": {MaxConcurrent: 4, MaxQueue: 8, QueueTimeout: time.Second},
+//		},
+//	})
+//
+// Limits can also be set on individual operations via metadata:
+//
+//	huma.Register(api, huma.Operation{
+//		OperationID: "export",
+//		Method:      http.MethodPost,
+//		Path:        "/export",
+//		Metadata: map[string]any{
+//			"loadshed": loadshed.Limit{MaxConcurrent: 2},
+//		},
+//	}, handler)
+package loadshed
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// MetadataKey is the operation metadata key used to set a `Limit` for a
+// specific operation, overriding any tag or default limits.
+const MetadataKey = "loadshed"
+
+// Limit describes how many requests may be handled at once.
+type Limit struct {
+	// MaxConcurrent is the number of requests which may be handled at once. A
+	// value of zero or less disables the limit, which can be used to exempt
+	// individual operations from tag or default limits.
+	MaxConcurrent int
+
+	// MaxQueue is the number of requests which may wait for a free slot once
+	// `MaxConcurrent` requests are being handled. Further requests are
+	// rejected immediately. Defaults to zero, i.e. no queue.
+	MaxQueue int
+
+	// QueueTimeout is the longest a request waits in the queue before being
+	// rejected. If zero, requests wait until a slot is free or the client
+	// gives up.
+	QueueTimeout time.Duration
+}
+
+// Config configures load shedding for an API.
+type Config struct {
+	// Default is the limit for operations which have no operation or tag
+	// limit. If nil, those operations are not limited.
+	Default *Limit
+
+	// Tags sets limits for operations by tag. Operations with a tag limit
+	// share a single limit across all operations with that tag. If an
+	// operation has multiple limited tags then the first is used.
+	Tags map[string]Limit
+
+	// RetryAfter is sent in the `Retry-After` header of rejected requests.
+	// Defaults to one second.
+	RetryAfter time.Duration
+}
+
+// scope is a resolved limit along with the name of the limiter it uses.
+type scope struct {
+	name  string
+	limit Limit
+}
+
+// scope returns the limit for an operation, if any. Operation limits take
+// precedence over tag limits, which take precedence over the default.
+func (c *Config) scope(op *huma.Operation) (scope, bool) {
+	name := op.OperationID
+	if name == "" {
+		name = op.Method + " " + op.Path
+	}
+
+	var s scope
+	if l, ok := op.Metadata[MetadataKey].(Limit); ok {
+		s = scope{"op:" + name, l}
+	} else if t, l, ok := c.tagLimit(op); ok {
+		s = scope{"tag:" + t, l}
+	} else if c.Default != nil {
+		s = scope{"op:" + name, *c.Default}
+	} else {
+		return s, false
+	}
+	return s, s.limit.MaxConcurrent > 0
+}
+
+func (c *Config) tagLimit(op *huma.Operation) (string, Limit, bool) {
+	for _, t := range op.Tags {
+		if l, ok := c.Tags[t]; ok {
+			return t, l, true
+		}
+	}
+	return "", Limit{}, false
+}
+
+// limiter is a semaphore with a bounded number of waiters.
+type limiter struct {
+	limit   Limit
+	slots   chan struct{}
+	waiting atomic.Int32
+}
+
+func newLimiter(limit Limit) *limiter {
+	return &limiter{
+		limit: limit,
+		slots: make(chan struct{}, limit.MaxConcurrent),
+	}
+}
+
+// acquire takes a slot, waiting in the queue if there is room. It returns
+// false if the request should be rejected.
+func (l *limiter) acquire(ctx context.Context) bool {
+	select {
+	case l.slots <- struct{}{}:
+		return true
+	default:
+	}
+
+	if int(l.waiting.Add(1)) > l.limit.MaxQueue {
+		l.waiting.Add(-1)
+		return false
+	}
+	defer l.waiting.Add(-1)
+
+	var timeout <-chan time.Time
+	if l.limit.QueueTimeout > 0 {
+		t := time.NewTimer(l.limit.QueueTimeout)
+		defer t.Stop()
+		timeout = t.C
+	}
+
+	select {
+	case l.slots <- struct{}{}:
+		return true
+	case <-ctx.Done():
+		return false
+	case <-timeout:
+		return false
+	}
+}
+
+func (l *limiter) release() {
+	<-l.slots
+}
+
+// Use adds load shedding middleware to the API and documents the `503`
+// response in the OpenAPI. Like other middleware, it must be called before
+// registering operations with `huma.Register` in order to apply to them.
+func Use(api huma.API, config Config) {
+	if config.RetryAfter <= 0 {
+		config.RetryAfter = time.Second
+	}
+	retryAfter := strconv.Itoa(int(math.Ceil(config.RetryAfter.Seconds())))
+
+	var mu sync.Mutex
+	limiters := map[string]*limiter{}
+	get := func(s scope) *limiter {
+		mu.Lock()
+		defer mu.Unlock()
+		l := limiters[s.name]
+		if l == nil {
+			l = newLimiter(s.limit)
+			limiters[s.name] = l
+		}
+		return l
+	}
+
+	oapi := api.OpenAPI()
+	oapi.OnAddOperation = append(oapi.OnAddOperation, config.document)
+
+	api.UseMiddleware(func(ctx huma.Context, next func(huma.Context)) {
+		s, ok := config.scope(ctx.Operation())
+		if !ok {
+			next(ctx)
+			return
+		}
+
+		l := get(s)
+		if !l.acquire(ctx.Context()) {
+			ctx.SetHeader("Retry-After", retryAfter)
+			huma.WriteErr(api, ctx, http.StatusServiceUnavailable, "server is overloaded, retry after "+retryAfter+" seconds")
+			return
+		}
+		defer l.release()
+
+		next(ctx)
+	})
+}
+
+// document adds the `503` response to the OpenAPI for limited operations.
+func (c *Config) document(oapi *huma.OpenAPI, op *huma.Operation) {
+	if _, ok := c.scope(op); !ok {
+		return
+	}
+
+	code := strconv.Itoa(http.StatusServiceUnavailable)
+	if op.Responses == nil {
+		op.Responses = map[string]*huma.Response{}
+	}
+	if op.Responses[code] == nil {
+		op.Responses[code] = &huma.Response{
+			Description: http.StatusText(http.StatusServiceUnavailable),
+			Content:     huma.ErrorContent(op),
+			Headers: map[string]*huma.Param{
+				"Retry-After": {
+					Description: "Number of seconds to wait before making another request.",
+					Schema:      &huma.Schema{Type: huma.TypeInteger},
+				},
+			},
+		}
+	}
+}
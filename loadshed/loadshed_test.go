@@ -0,0 +1,136 @@
+package loadshed
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/humatest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// register adds an operation which blocks until `release` is closed. Each
+// call signals on `started` once the handler is running.
+func register(api huma.API, id string, tags []string, metadata map[string]any, started chan<- struct{}, release <-chan struct{}) {
+	huma.Register(api, huma.Operation{
+		OperationID: id,
+		Method:      http.MethodGet,
+		Path:        "/" + id,
+		Tags:        tags,
+		Metadata:    metadata,
+	}, func(ctx context.Context, input *struct{}) (*struct{}, error) {
+		if started != nil {
+			started <- struct{}{}
+		}
+		if release != nil {
+			<-release
+		}
+		return nil, nil
+	})
+}
+
+// get makes a request in the background, sending the status code on the
+// returned channel when complete.
+func get(api humatest.TestAPI, path string) <-chan int {
+	done := make(chan int, 1)
+	go func() {
+		done <- serve(context.Background(), api, path)
+	}()
+	return done
+}
+
+func serve(ctx context.Context, api humatest.TestAPI, path string) int {
+	w := httptest.NewRecorder()
+	api.Adapter().ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil).WithContext(ctx))
+	return w.Code
+}
+
+func TestLimiter(t *testing.T) {
+	l := newLimiter(Limit{MaxConcurrent: 1, MaxQueue: 1, QueueTimeout: 10 * time.Millisecond})
+	ctx := context.Background()
+
+	assert.True(t, l.acquire(ctx))
+
+	// The queued request times out.
+	assert.False(t, l.acquire(ctx))
+
+	// A full queue rejects immediately.
+	l.limit.QueueTimeout = 0
+	l.waiting.Add(1)
+	assert.False(t, l.acquire(ctx))
+	l.waiting.Add(-1)
+
+	// Cancelled requests leave the queue.
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	assert.False(t, l.acquire(cancelled))
+	assert.EqualValues(t, 0, l.waiting.Load())
+
+	// Queued requests get the slot once it is released.
+	acquired := make(chan bool)
+	go func() { acquired <- l.acquire(ctx) }()
+	l.release()
+	assert.True(t, <-acquired)
+	l.release()
+}
+
+func TestUse(t *testing.T) {
+	_, api := humatest.New(t, huma.DefaultConfig("Test API", "1.0.0"))
+	Use(api, Config{
+		Default: &Limit{MaxConcurrent: 1},
+		Tags: map[string]Limit{
+			"reports": {MaxConcurrent: 1, MaxQueue: 1},
+		},
+		RetryAfter: 1500 * time.Millisecond,
+	})
+
+	started := make(chan struct{}, 10)
+	release := make(chan struct{})
+	register(api, "default", nil, nil, started, release)
+	register(api, "report-a", []string{"reports"}, nil, started, release)
+	register(api, "report-b", []string{"reports"}, nil, started, release)
+	register(api, "health", nil, map[string]any{MetadataKey: Limit{}}, nil, nil)
+
+	first := get(api, "/default")
+	<-started
+
+	// Requests over the limit are rejected when there is no queue.
+	resp := api.Get("/default")
+	assert.Equal(t, http.StatusServiceUnavailable, resp.Code)
+	assert.Equal(t, "2", resp.Header().Get("Retry-After"))
+	assert.Contains(t, resp.Body.String(), "server is overloaded")
+
+	// Tag limits are shared across operations, and queued requests run once
+	// a slot is free.
+	a := get(api, "/report-a")
+	<-started
+	b := get(api, "/report-b")
+	require.Eventually(t, func() bool {
+		// Give up quickly in case this request is queued ahead of `b`.
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		return serve(ctx, api, "/report-a") == http.StatusServiceUnavailable
+	}, time.Second, time.Millisecond)
+
+	// Exempt operations are not limited.
+	assert.Equal(t, http.StatusNoContent, api.Get("/health").Code)
+
+	close(release)
+	assert.Equal(t, http.StatusNoContent, <-first)
+	assert.Equal(t, http.StatusNoContent, <-a)
+	assert.Equal(t, http.StatusNoContent, <-b)
+
+	// Slots are released once requests complete.
+	assert.Equal(t, http.StatusNoContent, api.Get("/default").Code)
+
+	// The 503 response is documented.
+	op := api.OpenAPI().Paths["/default"].Get
+	require.NotNil(t, op.Responses["503"])
+	assert.NotNil(t, op.Responses["503"].Headers["Retry-After"])
+	assert.NotEmpty(t, op.Responses["503"].Content)
+	assert.Nil(t, api.OpenAPI().Paths["/health"].Get.Responses["503"])
+}
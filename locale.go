@@ -0,0 +1,168 @@
+package huma
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var localeType = reflect.TypeOf(Locale{})
+
+type localeKey struct{}
+
+// Locale is an input parameter which negotiates the language of the response
+// from the client's preferred languages, usually sent in the
+// `Accept-Language` header, and the API's supported `Config.Locales`. The
+// chosen locale is also available via `huma.LocaleFromContext` and is set on
+// errors implementing `huma.LocaleError`.
+//
+//	type GreetingInput struct {
+//		Locale huma.Locale `header:"Accept-Language"`
+//	}
+//
+//	func handler(ctx context.Context, input *GreetingInput) (*GreetingOutput, error) {
+//		switch input.Locale.Tag {
+//		// ...
+//		}
+//	}
+type Locale struct {
+	// Tag is the chosen language tag. When `Config.Locales` is set it is
+	// always one of them, falling back to the first if none of the client's
+	// languages are supported. Otherwise, it is the client's most preferred
+	// language, or empty if none was sent.
+	Tag string
+
+	// Requested lists the client's language ranges, most preferred first.
+	Requested []string
+}
+
+func (l Locale) String() string {
+	return l.Tag
+}
+
+// UnmarshalText parses an `Accept-Language` style list of language ranges
+// with optional quality values, ordered by preference. Ranges with a quality
+// of zero are left out.
+func (l *Locale) UnmarshalText(text []byte) error {
+	type weighted struct {
+		tag string
+		q   float64
+	}
+	ranges := []weighted{}
+	for _, part := range strings.Split(string(text), ",") {
+		tag, params, _ := strings.Cut(part, ";")
+		tag = strings.TrimSpace(tag)
+		if tag == "" {
+			continue
+		}
+		q := 1.0
+		if k, v, ok := strings.Cut(strings.TrimSpace(params), "="); ok && strings.TrimSpace(k) == "q" {
+			if f, err := strconv.ParseFloat(strings.TrimSpace(v), 64); err == nil {
+				q = f
+			}
+		}
+		if q <= 0 {
+			continue
+		}
+		ranges = append(ranges, weighted{tag, q})
+	}
+	sort.SliceStable(ranges, func(i, j int) bool {
+		return ranges[i].q > ranges[j].q
+	})
+
+	l.Requested = make([]string, len(ranges))
+	for i, r := range ranges {
+		l.Requested[i] = r.tag
+	}
+	return nil
+}
+
+func (l *Locale) Schema(r Registry) *Schema {
+	return &Schema{
+		Type:        TypeString,
+		Description: "Preferred languages, e.g. `en-US, en;q=0.9`.",
+	}
+}
+
+// negotiate chooses the best supported locale for the requested ranges. Each
+// range is matched exactly first, then by truncating it (e.g. `en-GB` to
+// `en`), and then by supported locales it is a prefix of (e.g. `en` to
+// `en-US`).
+func (l *Locale) negotiate(supported []string) {
+	if len(supported) == 0 {
+		l.Tag = ""
+		for _, r := range l.Requested {
+			if r != "*" {
+				l.Tag = r
+				break
+			}
+		}
+		return
+	}
+
+	for _, r := range l.Requested {
+		if r == "*" {
+			break
+		}
+		for candidate := r; candidate != ""; {
+			for _, s := range supported {
+				if strings.EqualFold(s, candidate) {
+					l.Tag = s
+					return
+				}
+			}
+			i := strings.LastIndexByte(candidate, '-')
+			if i == -1 {
+				break
+			}
+			candidate = candidate[:i]
+		}
+		for _, s := range supported {
+			if len(s) > len(r) && s[len(r)] == '-' && strings.EqualFold(s[:len(r)], r) {
+				l.Tag = s
+				return
+			}
+		}
+	}
+	l.Tag = supported[0]
+}
+
+// LocaleFromContext returns the locale chosen for the current request by a
+// `huma.Locale` input, or an empty string if there is none.
+func LocaleFromContext(ctx context.Context) string {
+	tag, _ := ctx.Value(localeKey{}).(string)
+	return tag
+}
+
+// LocaleError is implemented by errors which are rendered differently
+// depending on the request's locale, e.g. to translate their message. It is
+// called on the error before it is written, for operations with a
+// `huma.Locale` input.
+type LocaleError interface {
+	SetLocale(tag string)
+}
+
+// setErrorLocale sets the locale on errors which support it.
+func setErrorLocale(ctx Context, err any) {
+	if le, ok := err.(LocaleError); ok {
+		if tag := LocaleFromContext(ctx.Context()); tag != "" {
+			le.SetLocale(tag)
+		}
+	}
+}
+
+// localeContext overrides the request context to include the chosen locale.
+type localeContext struct {
+	humaContext
+	ctx context.Context
+}
+
+func (c *localeContext) Context() context.Context {
+	return c.ctx
+}
+
+func (c *localeContext) Unwrap() Context {
+	return c.humaContext
+}
@@ -0,0 +1,116 @@
+package huma_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/humatest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocaleUnmarshal(t *testing.T) {
+	l := huma.Locale{}
+	require.NoError(t, l.UnmarshalText([]byte("fr-CH, fr;q=0.9, en;q=0.8, de;q=0, *;q=0.5")))
+	assert.Equal(t, []string{"fr-CH", "fr", "en", "*"}, l.Requested)
+}
+
+type localizedError struct {
+	huma.ErrorModel
+	Locale string `json:"locale"`
+}
+
+func (e *localizedError) SetLocale(tag string) {
+	e.Locale = tag
+}
+
+func TestLocale(t *testing.T) {
+	config := huma.DefaultConfig("Test API", "1.0.0")
+	config.Locales = []string{"en-US", "de", "fr-FR"}
+	_, api := humatest.New(t, config)
+
+	type GreetingOutput struct {
+		Body struct {
+			Greeting string `json:"greeting"`
+		}
+	}
+
+	huma.Register(api, huma.Operation{
+		OperationID: "greet",
+		Method:      http.MethodGet,
+		Path:        "/greet",
+	}, func(ctx context.Context, input *struct {
+		Locale huma.Locale `header:"Accept-Language"`
+		Fail   bool        `query:"fail"`
+	}) (*GreetingOutput, error) {
+		assert.Equal(t, input.Locale.Tag, huma.LocaleFromContext(ctx))
+		if input.Fail {
+			return nil, &localizedError{ErrorModel: huma.ErrorModel{Status: http.StatusBadRequest}}
+		}
+		out := &GreetingOutput{}
+		switch input.Locale.Tag {
+		case "de":
+			out.Body.Greeting = "Hallo"
+		case "fr-FR":
+			out.Body.Greeting = "Bonjour"
+		default:
+			out.Body.Greeting = "Hello"
+		}
+		return out, nil
+	})
+
+	for _, tc := range []struct {
+		header   string
+		expected string
+	}{
+		{"", "en-US"},
+		{"de-AT", "de"},
+		{"fr", "fr-FR"},
+		{"es, de;q=0.5, fr;q=0.8", "fr-FR"},
+		{"ja, *", "en-US"},
+		{"EN-us", "en-US"},
+	} {
+		t.Run(tc.header, func(t *testing.T) {
+			headers := []any{}
+			if tc.header != "" {
+				headers = append(headers, "Accept-Language: "+tc.header)
+			}
+			resp := api.Get("/greet", headers...)
+			assert.Equal(t, http.StatusOK, resp.Code)
+			assert.Equal(t, tc.expected, resp.Header().Get("Content-Language"))
+			assert.Contains(t, resp.Header().Values("Vary"), "Accept-Language")
+		})
+	}
+
+	resp := api.Get("/greet", "Accept-Language: de")
+	assert.Contains(t, resp.Body.String(), "Hallo")
+
+	resp = api.Get("/greet?fail=true", "Accept-Language: fr")
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+	assert.Contains(t, resp.Body.String(), `"locale":"fr-FR"`)
+
+	param := api.OpenAPI().Paths["/greet"].Get.Parameters[0]
+	assert.Equal(t, "Accept-Language", param.Name)
+	assert.Equal(t, huma.TypeString, param.Schema.Type)
+}
+
+func TestLocaleUnconfigured(t *testing.T) {
+	_, api := humatest.New(t, huma.DefaultConfig("Test API", "1.0.0"))
+
+	huma.Register(api, huma.Operation{
+		OperationID: "greet",
+		Method:      http.MethodGet,
+		Path:        "/greet",
+	}, func(ctx context.Context, input *struct {
+		Locale huma.Locale `header:"Accept-Language"`
+	}) (*struct{}, error) {
+		assert.Equal(t, input.Locale.Tag, huma.LocaleFromContext(ctx))
+		return nil, nil
+	})
+
+	resp := api.Get("/greet", "Accept-Language: pt-BR;q=0.5, es")
+	assert.Equal(t, http.StatusNoContent, resp.Code)
+	assert.Empty(t, resp.Header().Get("Content-Language"))
+}
@@ -0,0 +1,230 @@
+// Package longrunning provides the asynchronous request-reply pattern for
+// operations which take too long to complete within a single request. The
+// handler validates the request and returns a task which runs in the
+// background. The client immediately receives a `202 Accepted` response with
+// `Location` and `Operation-Location` headers pointing to a status resource,
+// which it polls until the job has succeeded or failed.
+//
+//	jobs := longrunning.New(api, longrunning.Config{})
+//
+//	longrunning.Register(jobs, huma.Operation{
+//		OperationID: "export-things",
+//		Method:      http.MethodPost,
+//		Path:        "/things/export",
+//	}, func(ctx context.Context, input *ExportInput) (longrunning.Task[*Export], error) {
+//		return func(ctx context.Context) (*Export, error) {
+//			return exportThings(ctx, input.Body.Format)
+//		}, nil
+//	})
+package longrunning
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// State is the state of a job.
+type State string
+
+// Job states. Jobs start out running and finish by either succeeding or
+// failing.
+const (
+	Running   State = "running"
+	Succeeded State = "succeeded"
+	Failed    State = "failed"
+)
+
+// Job is the status resource of a long-running operation.
+type Job struct {
+	ID        string    `json:"id" doc:"Job identifier"`
+	Operation string    `json:"operation" doc:"Operation ID which started the job"`
+	State     State     `json:"state" enum:"running,succeeded,failed" doc:"Current state of the job"`
+	CreatedAt time.Time `json:"createdAt" doc:"When the job was started"`
+	UpdatedAt time.Time `json:"updatedAt" doc:"When the job state last changed"`
+	Result    any       `json:"result,omitempty" doc:"Result of the operation, once it has succeeded"`
+	Error     any       `json:"error,omitempty" doc:"Error details, if the operation failed"`
+}
+
+// Task is the work of a long-running operation, which is run in the
+// background after the request has completed. Returning a `huma.StatusError`
+// sets the job's error details, and any other error is reported as a
+// `500 Internal Server Error`.
+type Task[R any] func(ctx context.Context) (R, error)
+
+// Config configures long-running operations.
+type Config struct {
+	// Store saves the state of jobs. Defaults to a `MemoryStore` which keeps
+	// finished jobs for 24 hours.
+	Store Store
+
+	// Path is the path prefix of the job status resources, which are served at
+	// `{Path}/{id}`. Defaults to `/operations`.
+	Path string
+
+	// Timeout limits how long tasks may run. Defaults to no timeout.
+	Timeout time.Duration
+
+	// RetryAfter is the suggested polling interval sent in the `Retry-After`
+	// header while a job is running. Defaults to one second.
+	RetryAfter time.Duration
+}
+
+// Manager runs tasks and serves the status of their jobs.
+type Manager struct {
+	api    huma.API
+	config Config
+}
+
+type statusOutput struct {
+	RetryAfter string `header:"Retry-After" doc:"Seconds to wait before polling again, while the job is running"`
+	Body       *Job
+}
+
+type acceptedOutput struct {
+	Location          string `header:"Location" doc:"URL of the job status resource"`
+	OperationLocation string `header:"Operation-Location" doc:"URL of the job status resource"`
+	RetryAfter        string `header:"Retry-After" doc:"Seconds to wait before polling the job status"`
+	Body              *Job
+}
+
+// New creates a manager for long-running operations and registers the
+// `get-operation-status` operation which serves the status of jobs.
+func New(api huma.API, config Config) *Manager {
+	if config.Store == nil {
+		config.Store = NewMemoryStore(24 * time.Hour)
+	}
+	if config.Path == "" {
+		config.Path = "/operations"
+	}
+	config.Path = strings.TrimSuffix(config.Path, "/")
+	if config.RetryAfter <= 0 {
+		config.RetryAfter = time.Second
+	}
+
+	m := &Manager{api: api, config: config}
+
+	huma.Register(api, huma.Operation{
+		OperationID: "get-operation-status",
+		Method:      http.MethodGet,
+		Path:        config.Path + "/{id}",
+		Summary:     "Get operation status",
+		Description: "Get the status of a long-running operation, including its result once it has succeeded.",
+		Errors:      []int{http.StatusNotFound},
+	}, func(ctx context.Context, input *struct {
+		ID string `path:"id" doc:"Job identifier"`
+	}) (*statusOutput, error) {
+		job, err := m.config.Store.Load(ctx, input.ID)
+		if err != nil {
+			return nil, err
+		}
+		if job == nil {
+			return nil, huma.Error404NotFound("operation not found")
+		}
+		resp := &statusOutput{Body: job}
+		if job.State == Running {
+			resp.RetryAfter = m.retryAfter()
+		}
+		return resp, nil
+	})
+
+	return m
+}
+
+// retryAfter returns the polling interval in whole seconds, rounded up.
+func (m *Manager) retryAfter() string {
+	return strconv.Itoa(int((m.config.RetryAfter + time.Second - 1) / time.Second))
+}
+
+// newID returns a random 32 character hex string.
+func newID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// Register an operation which starts a long-running job. The handler is
+// called with the validated input and returns the task to run, or an error
+// to reject the request. Accepted requests respond with `202 Accepted` and
+// the initial job status, unless `op.DefaultStatus` is set.
+func Register[I, R any](m *Manager, op huma.Operation, handler func(ctx context.Context, input *I) (Task[R], error)) {
+	if op.DefaultStatus == 0 {
+		op.DefaultStatus = http.StatusAccepted
+	}
+	operationID := op.OperationID
+
+	huma.Register(m.api, op, func(ctx context.Context, input *I) (*acceptedOutput, error) {
+		task, err := handler(ctx, input)
+		if err != nil {
+			return nil, err
+		}
+
+		now := time.Now()
+		job := &Job{
+			ID:        newID(),
+			Operation: operationID,
+			State:     Running,
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+		if err := m.config.Store.Save(ctx, job); err != nil {
+			return nil, err
+		}
+		go run(m, job, task)
+
+		location := m.config.Path + "/" + job.ID
+		return &acceptedOutput{
+			Location:          location,
+			OperationLocation: location,
+			RetryAfter:        m.retryAfter(),
+			Body:              job,
+		}, nil
+	})
+}
+
+// run runs a task and saves the final state of its job.
+func run[R any](m *Manager, job *Job, task Task[R]) {
+	ctx := context.Background()
+	if m.config.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, m.config.Timeout)
+		defer cancel()
+	}
+
+	result, err := runTask(ctx, task)
+	finished := *job
+	finished.UpdatedAt = time.Now()
+	if err != nil {
+		finished.State = Failed
+		var se huma.StatusError
+		if !errors.As(err, &se) {
+			se = huma.NewError(http.StatusInternalServerError, err.Error())
+		}
+		finished.Error = se
+	} else {
+		finished.State = Succeeded
+		finished.Result = result
+	}
+	// There is no client to report a failure to save to, in which case the
+	// job appears to still be running. The task's context may have timed out,
+	// so it is not used here.
+	_ = m.config.Store.Save(context.Background(), &finished)
+}
+
+// runTask runs a task, converting panics into errors so that a failed job
+// does not crash the server.
+func runTask[R any](ctx context.Context, task Task[R]) (result R, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.New("task panicked")
+		}
+	}()
+	return task(ctx)
+}
@@ -0,0 +1,192 @@
+package longrunning
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/humatest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type Export struct {
+	URL string `json:"url"`
+}
+
+type ExportInput struct {
+	Body struct {
+		Format string `json:"format" enum:"csv,json"`
+	}
+}
+
+// poll gets the job status until it is no longer running.
+func poll(t *testing.T, api humatest.TestAPI, location string) map[string]any {
+	for i := 0; i < 100; i++ {
+		resp := api.Get(location)
+		require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+		var job map[string]any
+		require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &job))
+		if job["state"] != string(Running) {
+			assert.Empty(t, resp.Header().Get("Retry-After"))
+			return job
+		}
+		assert.Equal(t, "1", resp.Header().Get("Retry-After"))
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("job did not finish")
+	return nil
+}
+
+func TestLongRunning(t *testing.T) {
+	_, api := humatest.New(t)
+	jobs := New(api, Config{})
+
+	release := make(chan struct{})
+	Register(jobs, huma.Operation{
+		OperationID: "export-things",
+		Method:      http.MethodPost,
+		Path:        "/things/export",
+	}, func(ctx context.Context, input *ExportInput) (Task[*Export], error) {
+		if input.Body.Format == "json" {
+			return nil, huma.Error409Conflict("export already running")
+		}
+		return func(ctx context.Context) (*Export, error) {
+			<-release
+			return &Export{URL: "https://example.com/export.csv"}, nil
+		}, nil
+	})
+
+	resp := api.Post("/things/export", map[string]any{"format": "csv"})
+	require.Equal(t, http.StatusAccepted, resp.Code, resp.Body.String())
+	location := resp.Header().Get("Location")
+	assert.True(t, strings.HasPrefix(location, "/operations/"))
+	assert.Equal(t, location, resp.Header().Get("Operation-Location"))
+	assert.Equal(t, "1", resp.Header().Get("Retry-After"))
+	assert.Contains(t, resp.Body.String(), `"state":"running"`)
+	assert.Contains(t, resp.Body.String(), `"operation":"export-things"`)
+
+	resp = api.Get(location)
+	require.Equal(t, http.StatusOK, resp.Code)
+	assert.Contains(t, resp.Body.String(), `"state":"running"`)
+
+	close(release)
+	job := poll(t, api, location)
+	assert.Equal(t, string(Succeeded), job["state"])
+	assert.Equal(t, map[string]any{"url": "https://example.com/export.csv"}, job["result"])
+
+	// Handler errors reject the request without starting a job.
+	resp = api.Post("/things/export", map[string]any{"format": "json"})
+	assert.Equal(t, http.StatusConflict, resp.Code)
+
+	// Input is validated before the handler is called.
+	resp = api.Post("/things/export", map[string]any{"format": "xml"})
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+
+	resp = api.Get("/operations/missing")
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+}
+
+func TestLongRunningFailure(t *testing.T) {
+	_, api := humatest.New(t)
+	jobs := New(api, Config{Path: "/jobs/", Timeout: 10 * time.Millisecond})
+
+	Register(jobs, huma.Operation{
+		OperationID: "fail",
+		Method:      http.MethodPost,
+		Path:        "/fail/{mode}",
+	}, func(ctx context.Context, input *struct {
+		Mode string `path:"mode"`
+	}) (Task[string], error) {
+		return func(ctx context.Context) (string, error) {
+			switch input.Mode {
+			case "status":
+				return "", huma.Error400BadRequest("bad export")
+			case "panic":
+				panic("boom")
+			case "timeout":
+				<-ctx.Done()
+				return "", ctx.Err()
+			}
+			return "", errors.New("internal")
+		}, nil
+	})
+
+	for _, tc := range []struct {
+		mode   string
+		status float64
+		detail string
+	}{
+		{"status", 400, "bad export"},
+		{"error", 500, "internal"},
+		{"panic", 500, "task panicked"},
+		{"timeout", 500, "context deadline exceeded"},
+	} {
+		t.Run(tc.mode, func(t *testing.T) {
+			resp := api.Post("/fail/" + tc.mode)
+			require.Equal(t, http.StatusAccepted, resp.Code, resp.Body.String())
+			location := resp.Header().Get("Location")
+			assert.True(t, strings.HasPrefix(location, "/jobs/"))
+
+			job := poll(t, api, location)
+			assert.Equal(t, string(Failed), job["state"])
+			jobErr := job["error"].(map[string]any)
+			assert.Equal(t, tc.status, jobErr["status"])
+			assert.Equal(t, tc.detail, jobErr["detail"])
+		})
+	}
+}
+
+func TestOpenAPI(t *testing.T) {
+	_, api := humatest.New(t)
+	jobs := New(api, Config{})
+
+	Register(jobs, huma.Operation{
+		OperationID: "export-things",
+		Method:      http.MethodPost,
+		Path:        "/things/export",
+	}, func(ctx context.Context, input *ExportInput) (Task[*Export], error) {
+		return nil, nil
+	})
+
+	op := api.OpenAPI().Paths["/things/export"].Post
+	accepted := op.Responses["202"]
+	require.NotNil(t, accepted)
+	assert.NotNil(t, accepted.Headers["Location"])
+	assert.NotNil(t, accepted.Headers["Operation-Location"])
+	assert.Equal(t, "#/components/schemas/Job", accepted.Content["application/json"].Schema.Ref)
+
+	status := api.OpenAPI().Paths["/operations/{id}"].Get
+	require.NotNil(t, status)
+	assert.Equal(t, "get-operation-status", status.OperationID)
+	assert.NotNil(t, status.Responses["404"])
+}
+
+func TestMemoryStore(t *testing.T) {
+	now := time.Now()
+	s := NewMemoryStore(time.Minute)
+	s.now = func() time.Time { return now }
+
+	ctx := context.Background()
+	require.NoError(t, s.Save(ctx, &Job{ID: "running", State: Running, UpdatedAt: now}))
+	require.NoError(t, s.Save(ctx, &Job{ID: "done", State: Succeeded, UpdatedAt: now}))
+
+	job, err := s.Load(ctx, "done")
+	require.NoError(t, err)
+	assert.Equal(t, Succeeded, job.State)
+
+	// Finished jobs expire, but running ones do not.
+	now = now.Add(2 * time.Minute)
+	job, _ = s.Load(ctx, "done")
+	assert.Nil(t, job)
+	job, _ = s.Load(ctx, "running")
+	assert.NotNil(t, job)
+
+	require.NoError(t, s.Save(ctx, &Job{ID: "other", State: Running, UpdatedAt: now}))
+	assert.Len(t, s.jobs, 2)
+}
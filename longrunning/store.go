@@ -0,0 +1,71 @@
+package longrunning
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Store saves the state of jobs. Implementations must be safe for concurrent
+// use. A shared store such as Redis or a database table can be used so that
+// any instance of a service can report the status of a job.
+type Store interface {
+	// Save creates or updates a job.
+	Save(ctx context.Context, job *Job) error
+
+	// Load returns the job with the given ID, or nil if it does not exist.
+	Load(ctx context.Context, id string) (*Job, error)
+}
+
+// MemoryStore is an in-process `Store`. Finished jobs are periodically
+// removed once they are older than the store's TTL.
+type MemoryStore struct {
+	mu        sync.Mutex
+	jobs      map[string]Job
+	ttl       time.Duration
+	lastSweep time.Time
+
+	// now returns the current time and can be replaced for testing.
+	now func() time.Time
+}
+
+// NewMemoryStore creates a new in-process store which keeps finished jobs
+// for the given duration after they were last updated.
+func NewMemoryStore(ttl time.Duration) *MemoryStore {
+	return &MemoryStore{
+		jobs: map[string]Job{},
+		ttl:  ttl,
+		now:  time.Now,
+	}
+}
+
+// Save implements the `Store` interface.
+func (s *MemoryStore) Save(ctx context.Context, job *Job) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if now.Sub(s.lastSweep) > s.ttl {
+		for id, j := range s.jobs {
+			if j.State != Running && now.Sub(j.UpdatedAt) > s.ttl {
+				delete(s.jobs, id)
+			}
+		}
+		s.lastSweep = now
+	}
+
+	s.jobs[job.ID] = *job
+	return nil
+}
+
+// Load implements the `Store` interface.
+func (s *MemoryStore) Load(ctx context.Context, id string) (*Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	j, ok := s.jobs[id]
+	if !ok || (j.State != Running && s.now().Sub(j.UpdatedAt) > s.ttl) {
+		return nil, nil
+	}
+	return &j, nil
+}
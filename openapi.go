@@ -824,13 +824,14 @@ type Operation struct {
 	Path string `yaml:"-"`
 
 	// DefaultStatus is the default HTTP status code for this operation. It will
-	// be set to 200 or 204 if not specified, depending on whether the handler
-	// returns a response body.
+	// be inferred using `Config.DefaultStatus` if not specified, falling back
+	// to 200 or 204 depending on whether the handler returns a response body.
 	DefaultStatus int `yaml:"-"`
 
 	// MaxBodyBytes is the maximum number of bytes to read from the request
-	// body. If not specified, the default is 1MB. Use -1 for unlimited. If
-	// the limit is reached, then an HTTP 413 error is returned.
+	// body. If not specified, the default is `Config.MaxBodyBytes` or 1MB.
+	// Use -1 for unlimited. If the limit is exceeded, then an HTTP 413 error
+	// is returned.
 	MaxBodyBytes int64 `yaml:"-"`
 
 	// BodyReadTimeout is the maximum amount of time to wait for the request
@@ -840,6 +841,68 @@ type Operation struct {
 	// of -1 can unset the server's timeout.
 	BodyReadTimeout time.Duration `yaml:"-"`
 
+	// FullDuplex lets the handler keep reading the request body after it has
+	// started writing the response, for streaming endpoints which interleave
+	// the two. The input must not have a `Body` or `RawBody` field, so that the
+	// body is left for the handler to read via `ctx.BodyReader()` from a
+	// streaming response. See `huma.FullDuplex`.
+	FullDuplex bool `yaml:"-"`
+
+	// SkipCompression disables `Config.Compression` for this operation, e.g.
+	// for responses which are already compressed or must be sent as-is.
+	SkipCompression bool `yaml:"-"`
+
+	// ValidationErrorStatus is the HTTP status code returned when a request
+	// fails validation. If not specified, the default is
+	// `Config.ValidationErrorStatus` or 422 Unprocessable Entity.
+	ValidationErrorStatus int `yaml:"-"`
+
+	// ValidationMode controls whether requests which fail validation are
+	// rejected or only reported via `Config.OnValidationAudit` before calling
+	// the handler. If not specified, the default is `Config.ValidationMode`
+	// or `ValidationEnforce`.
+	ValidationMode ValidationMode `yaml:"-"`
+
+	// LenientValidation lists schema keywords, like `maxLength` or `enum`,
+	// whose validation failures are sent to the client as `Warning` headers
+	// instead of rejecting the request. This lets clients migrate gradually
+	// to a stricter schema. Type errors are always rejected. See
+	// `ValidationWarningsFromContext`.
+	LenientValidation []string `yaml:"-"`
+
+	// Timeout is the maximum amount of time the handler may run for. The
+	// handler's context is canceled once it is reached, and an HTTP 504 error
+	// is returned without waiting for the handler to finish. If not
+	// specified, there is no timeout.
+	Timeout time.Duration `yaml:"-"`
+
+	// MaxConcurrent is the maximum number of requests to this operation which
+	// may be handled at once, which is useful for throttling expensive
+	// operations like report generation or exports. Once reached, further
+	// requests wait in a queue of up to `MaxQueue` requests and are otherwise
+	// rejected with an HTTP 429 error. Handlers which time out keep their slot
+	// until they actually return. If not specified, there is no limit.
+	MaxConcurrent int `yaml:"-"`
+
+	// MaxQueue is the number of requests which may wait for a free slot when
+	// `MaxConcurrent` requests are already being handled. If not specified,
+	// requests over the limit are rejected immediately.
+	MaxQueue int `yaml:"-"`
+
+	// QueueTimeout is the maximum amount of time a request waits in the queue
+	// before being rejected. If not specified, requests wait until a slot is
+	// free or the client gives up.
+	QueueTimeout time.Duration `yaml:"-"`
+
+	// ResponseStrategy controls whether the response body is buffered or
+	// streamed. If not specified, the default is `Config.ResponseStrategy`.
+	ResponseStrategy ResponseStrategy `yaml:"-"`
+
+	// Cache is the caching policy for successful responses, which is sent as
+	// the `Cache-Control`, `Expires`, and `Vary` headers and documented in the
+	// OpenAPI. See `CacheMaxAge` and `CacheNoStore`.
+	Cache *CachePolicy `yaml:"-"`
+
 	// Errors is a list of HTTP status codes that the handler may return. If
 	// not specified, then a default error response is added to the OpenAPI.
 	// This is a convenience for handlers that return a fixed set of errors
@@ -848,16 +911,39 @@ type Operation struct {
 	// schema generated from the type returned by `huma.NewError()`.
 	Errors []int `yaml:"-"`
 
+	// ErrorTypes maps HTTP status codes to example values of the error types
+	// the handler may return for them, e.g. `http.StatusConflict:
+	// &ConflictError{}`. Each gets its own response schema generated from the
+	// Go type instead of the type returned by `huma.NewError()`. The status
+	// codes are also added to `Errors`.
+	ErrorTypes map[int]any `yaml:"-"`
+
 	// SkipValidateParams disables validation of path, query, and header
 	// parameters. This can speed up request processing if you want to handle
 	// your own validation. Use with caution!
 	SkipValidateParams bool `yaml:"-"`
 
+	// StrictHeaders validates header parameters declared in `Parameters`
+	// using their full schema, even when they are not bound to an input field
+	// like `Config.RequestIDHeader` or headers documented for a proxy.
+	StrictHeaders bool `yaml:"-"`
+
+	// RejectUnknownHeaders rejects requests with `X-` headers which are not
+	// declared as header parameters, the request ID header, or an API key
+	// security scheme. `X-Forwarded-*` headers are allowed when
+	// `Config.TrustedProxies` is set. This is useful for locked-down internal
+	// APIs where unexpected headers may indicate a misconfigured client.
+	RejectUnknownHeaders bool `yaml:"-"`
+
 	// SkipValidateBody disables validation of the request body. This can speed
 	// up request processing if you want to handle your own validation. Use with
 	// caution!
 	SkipValidateBody bool `yaml:"-"`
 
+	// RequestTransformers modify the decoded request body before it is
+	// validated. They run after any API-wide `Config.RequestTransformers`.
+	RequestTransformers []RequestTransformer `yaml:"-"`
+
 	// Hidden will skip documenting this operation in the OpenAPI. This is
 	// useful for operations that are not intended to be used by clients but
 	// you'd still like the benefits of using Huma. Generally not recommended.
@@ -1318,8 +1404,13 @@ type Components struct {
 }
 
 func (c *Components) MarshalJSON() ([]byte, error) {
+	var schemas any = c.Schemas
+	if r, ok := c.Schemas.(*mapRegistry); ok && r.inline {
+		// Inline registries never have any schemas to reference.
+		schemas = map[string]*Schema{}
+	}
 	return marshalJSON([]jsonFieldInfo{
-		{"schemas", c.Schemas, omitEmpty},
+		{"schemas", schemas, omitEmpty},
 		{"responses", c.Responses, omitEmpty},
 		{"parameters", c.Parameters, omitEmpty},
 		{"examples", c.Examples, omitEmpty},
@@ -1375,6 +1466,23 @@ type Tag struct {
 	// Extensions (user-defined properties), if any. Values in this map will
 	// be marshalled as siblings of the other properties above.
 	Extensions map[string]any `yaml:",inline"`
+
+	// --- Huma-specific fields ---
+	// These are inherited by every operation with this tag when it is
+	// registered, so the tag must be added to the OpenAPI first.
+
+	// Middlewares run for operations with this tag, after the API's own
+	// middlewares. Operations with several tags run each tag's middlewares
+	// in the order the tags are listed on the operation.
+	Middlewares Middlewares `yaml:"-"`
+
+	// Security requirements used by operations with this tag which do not set
+	// their own. Set an operation's `Security` to an empty slice to make it
+	// public anyway.
+	Security []map[string][]string `yaml:"-"`
+
+	// Errors are added to the `Errors` of operations with this tag.
+	Errors []int `yaml:"-"`
 }
 
 func (t *Tag) MarshalJSON() ([]byte, error) {
@@ -1451,6 +1559,10 @@ type OpenAPI struct { //nolint: musttag
 	// `AddOperation`. You may bypass this by directly writing to the `Paths`
 	// map instead.
 	OnAddOperation []AddOpFunc `yaml:"-"`
+
+	// handlers are the fully-wired handlers of registered operations, by
+	// operation ID. See `OperationHandler`.
+	handlers map[string]http.Handler
 }
 
 // AddOperation adds an operation to the OpenAPI. This is the preferred way to
@@ -1462,10 +1574,11 @@ func (o *OpenAPI) AddOperation(op *Operation) {
 		o.Paths = map[string]*PathItem{}
 	}
 
-	item := o.Paths[op.Path]
+	path := openAPIPath(op.Path)
+	item := o.Paths[path]
 	if item == nil {
 		item = &PathItem{}
-		o.Paths[op.Path] = item
+		o.Paths[path] = item
 	}
 
 	switch op.Method {
@@ -0,0 +1,128 @@
+package huma
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// operationHandler serves a single operation without a router.
+type operationHandler struct {
+	op      *Operation
+	handler func(Context)
+
+	// segments of the operation's path, excluding any catch-all parameter.
+	segments []string
+	wildcard string
+}
+
+// addOperationHandler stores the fully-wired handler for an operation so it
+// can be retrieved via `OperationHandler`.
+func addOperationHandler(oapi *OpenAPI, op *Operation, handler func(Context)) {
+	h := &operationHandler{op: op, handler: handler}
+	prefix, wildcard, ok := PathWildcard(op.Path)
+	h.segments = splitPath(prefix)
+	if ok {
+		h.segments = h.segments[:len(h.segments)-1]
+		h.wildcard = wildcard
+	}
+	if oapi.handlers == nil {
+		oapi.handlers = map[string]http.Handler{}
+	}
+	oapi.handlers[op.OperationID] = h
+}
+
+// match returns the path parameter names and values if the path matches the
+// operation's path. Only parameters which are whole path segments are
+// supported.
+func (h *operationHandler) match(path string) (names, values []string, ok bool) {
+	segments := splitPath(path)
+	if len(segments) < len(h.segments) || (h.wildcard == "" && len(segments) != len(h.segments)) {
+		return nil, nil, false
+	}
+	for i, s := range h.segments {
+		if len(s) > 2 && s[0] == '{' && s[len(s)-1] == '}' && !strings.Contains(s[1:len(s)-1], "{") {
+			if segments[i] == "" {
+				return nil, nil, false
+			}
+			names = append(names, s[1:len(s)-1])
+			values = append(values, segments[i])
+			continue
+		}
+		if s != segments[i] {
+			return nil, nil, false
+		}
+	}
+	if h.wildcard != "" {
+		if len(segments) == len(h.segments) {
+			return nil, nil, false
+		}
+		names = append(names, h.wildcard)
+		values = append(values, strings.Join(segments[len(h.segments):], "/"))
+	}
+	return names, values, true
+}
+
+func (h *operationHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := &routerContext{op: h.op, r: r, w: w, maxMemory: defaultMultipartMaxMemory}
+	if names, values, ok := h.match(r.URL.Path); ok {
+		ctx.names, ctx.values = names, values
+	} else {
+		// The path was rewritten, e.g. by stripping a prefix, so fall back to
+		// any values set by the router which called this handler.
+		var v any = r
+		if pv, ok := v.(interface{ PathValue(string) string }); ok {
+			for _, name := range pathTemplateParams(h.op.Path) {
+				ctx.names = append(ctx.names, name)
+				ctx.values = append(ctx.values, pv.PathValue(name))
+			}
+		}
+	}
+	h.handler(ctx)
+}
+
+// pathTemplateParams returns the names of the path parameters in a path
+// template.
+func pathTemplateParams(path string) []string {
+	names := []string{}
+	for {
+		start := strings.IndexByte(path, '{')
+		if start < 0 {
+			return names
+		}
+		end := strings.IndexByte(path[start:], '}')
+		if end < 0 {
+			return names
+		}
+		names = append(names, strings.TrimSuffix(path[start+1:start+end], "..."))
+		path = path[start+end+1:]
+	}
+}
+
+// OperationHandler returns a standalone `http.Handler` for the registered
+// operation with the given ID, including all of the usual request parsing,
+// validation, middleware, and response serialization. This makes it possible
+// to mount operations individually, e.g. in serverless function routers or
+// alongside other handlers in a router Huma has no adapter for. Operations
+// are still registered with the API's adapter as normal.
+//
+// Path parameters are read by matching the request path against the
+// operation's path. If it doesn't match, e.g. because a prefix was stripped,
+// then values from `http.Request.PathValue` (Go 1.22+) are used instead. The
+// request method is not checked. It panics if no operation with the ID has
+// been registered.
+//
+//	huma.Register(api, huma.Operation{
+//		OperationID: "get-user",
+//		Method:      http.MethodGet,
+//		Path:        "/users/{id}",
+//	}, getUser)
+//
+//	mux.Handle("GET /users/{id}", huma.OperationHandler(api, "get-user"))
+func OperationHandler(api API, operationID string) http.Handler {
+	h := api.OpenAPI().handlers[operationID]
+	if h == nil {
+		panic(fmt.Sprintf("no operation registered with ID %q", operationID))
+	}
+	return h
+}
@@ -0,0 +1,76 @@
+package huma_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/humatest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOperationHandler(t *testing.T) {
+	_, api := humatest.New(t, huma.DefaultConfig("Test API", "1.0.0"))
+	api.UseMiddleware(func(ctx huma.Context, next func(huma.Context)) {
+		ctx.SetHeader("X-Middleware", "yes")
+		next(ctx)
+	})
+
+	huma.Register(api, huma.Operation{
+		OperationID: "get-file",
+		Method:      http.MethodGet,
+		Path:        "/users/{id}/files/{path...}",
+	}, func(ctx context.Context, input *struct {
+		ID   int    `path:"id" minimum:"1"`
+		Path string `path:"path"`
+	}) (*struct{ Body string }, error) {
+		return &struct{ Body string }{Body: input.Path}, nil
+	})
+
+	h := huma.OperationHandler(api, "get-file")
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/users/1/files/a/b.txt", nil))
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.JSONEq(t, `"a/b.txt"`, w.Body.String())
+	assert.Equal(t, "yes", w.Header().Get("X-Middleware"))
+
+	// Validation still takes place.
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/users/0/files/a.txt", nil))
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+
+	// The operation is still registered with the adapter.
+	resp := api.Get("/users/1/files/c.txt")
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	assert.Panics(t, func() {
+		huma.OperationHandler(api, "missing")
+	})
+}
+
+func TestOperationHandlerCORS(t *testing.T) {
+	config := huma.DefaultConfig("Test API", "1.0.0")
+	config.CORS = &huma.CORSConfig{AllowOrigins: []string{"https://example.com"}}
+	_, api := humatest.New(t, config)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "get-thing",
+		Method:      http.MethodGet,
+		Path:        "/things/{id}",
+	}, func(ctx context.Context, input *struct {
+		ID string `path:"id"`
+	}) (*struct{}, error) {
+		return nil, nil
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/things/1", nil)
+	req.Header.Set("Origin", "https://example.com")
+	w := httptest.NewRecorder()
+	huma.OperationHandler(api, "get-thing").ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, "https://example.com", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "Origin", w.Header().Get("Vary"))
+}
@@ -0,0 +1,140 @@
+// Package otelhuma provides OpenTelemetry tracing for Huma APIs. It is
+// implemented as router-agnostic Huma middleware, so every adapter produces
+// identical telemetry.
+//
+//	api := humachi.New(router, huma.DefaultConfig("My API", "1.0.0"))
+//	api.UseMiddleware(otelhuma.Middleware())
+package otelhuma
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/danielgtaylor/huma/v2"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ScopeName is the instrumentation scope name used for the tracer.
+const ScopeName = "github.com/danielgtaylor/huma/v2/otelhuma"
+
+type config struct {
+	tracerProvider trace.TracerProvider
+	propagators    propagation.TextMapPropagator
+}
+
+// Option configures the tracing middleware.
+type Option func(*config)
+
+// WithTracerProvider sets the tracer provider used to create spans. Defaults
+// to the global tracer provider.
+func WithTracerProvider(tp trace.TracerProvider) Option {
+	return func(c *config) {
+		c.tracerProvider = tp
+	}
+}
+
+// WithPropagators sets the propagators used to extract the parent span from
+// the incoming request headers. Defaults to the global propagators.
+func WithPropagators(p propagation.TextMapPropagator) Option {
+	return func(c *config) {
+		c.propagators = p
+	}
+}
+
+// tracedContext overrides the request context to include the span and
+// records the response status.
+type tracedContext struct {
+	huma.ContextWrapper
+	ctx    context.Context
+	status int
+}
+
+func (c *tracedContext) Context() context.Context {
+	return c.ctx
+}
+
+func (c *tracedContext) SetStatus(code int) {
+	c.status = code
+	c.ContextWrapper.SetStatus(code)
+}
+
+// headerCarrier adapts the request headers for use with propagators.
+type headerCarrier struct {
+	ctx huma.Context
+}
+
+func (h headerCarrier) Get(key string) string {
+	return h.ctx.Header(key)
+}
+
+func (h headerCarrier) Set(key, value string) {
+	// Request headers are read-only.
+}
+
+func (h headerCarrier) Keys() []string {
+	var keys []string
+	h.ctx.EachHeader(func(name, value string) {
+		keys = append(keys, name)
+	})
+	return keys
+}
+
+// Middleware creates a server span for each request, named by the operation
+// ID (or the method and path template if there is no ID). The span records
+// the route template and response status, is marked as an error for `5xx`
+// responses and panics, and is available to handlers via the request
+// context, e.g. for use with `trace.SpanFromContext(ctx)`.
+func Middleware(opts ...Option) func(ctx huma.Context, next func(huma.Context)) {
+	c := config{
+		tracerProvider: otel.GetTracerProvider(),
+		propagators:    otel.GetTextMapPropagator(),
+	}
+	for _, opt := range opts {
+		opt(&c)
+	}
+	tracer := c.tracerProvider.Tracer(ScopeName)
+
+	return func(ctx huma.Context, next func(huma.Context)) {
+		op := ctx.Operation()
+		name := op.OperationID
+		if name == "" {
+			name = op.Method + " " + op.Path
+		}
+
+		parent := c.propagators.Extract(ctx.Context(), headerCarrier{ctx})
+		spanCtx, span := tracer.Start(parent, name,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				attribute.String("http.request.method", ctx.Method()),
+				attribute.String("http.route", op.Path),
+				attribute.String("url.path", huma.RedactPath(ctx)),
+			),
+		)
+		defer span.End()
+
+		tctx := &tracedContext{ContextWrapper: huma.WrapContext(ctx), ctx: spanCtx}
+		defer func() {
+			if r := recover(); r != nil {
+				span.RecordError(fmt.Errorf("panic: %v", r))
+				span.SetStatus(codes.Error, "panic")
+				panic(r)
+			}
+		}()
+
+		next(tctx)
+
+		status := tctx.status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		span.SetAttributes(attribute.Int("http.response.status_code", status))
+		if status >= 500 {
+			span.SetStatus(codes.Error, http.StatusText(status))
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package otelhuma
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/humatest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func attr(attrs []attribute.KeyValue, key string) attribute.Value {
+	for _, a := range attrs {
+		if string(a.Key) == key {
+			return a.Value
+		}
+	}
+	return attribute.Value{}
+}
+
+func TestMiddleware(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	_, api := humatest.New(t, huma.DefaultConfig("Test API", "1.0.0"))
+	api.UseMiddleware(Middleware(
+		WithTracerProvider(tp),
+		WithPropagators(propagation.TraceContext{}),
+	))
+
+	var handlerSpan trace.SpanContext
+	huma.Register(api, huma.Operation{
+		OperationID: "get-thing",
+		Method:      http.MethodGet,
+		Path:        "/things/{id}",
+	}, func(ctx context.Context, input *struct {
+		ID string `path:"id"`
+	}) (*struct{}, error) {
+		handlerSpan = trace.SpanContextFromContext(ctx)
+		if input.ID == "bad" {
+			return nil, huma.Error500InternalServerError("whoops")
+		}
+		return nil, nil
+	})
+
+	resp := api.Get("/things/123", "traceparent: 00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01")
+	assert.Equal(t, http.StatusNoContent, resp.Code)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	span := spans[0]
+	assert.Equal(t, "get-thing", span.Name())
+	assert.Equal(t, trace.SpanKindServer, span.SpanKind())
+	assert.Equal(t, "/things/{id}", attr(span.Attributes(), "http.route").AsString())
+	assert.Equal(t, "/things/123", attr(span.Attributes(), "url.path").AsString())
+	assert.EqualValues(t, http.StatusNoContent, attr(span.Attributes(), "http.response.status_code").AsInt64())
+	assert.Equal(t, codes.Unset, span.Status().Code)
+
+	// The parent is propagated and the span is available to the handler.
+	assert.Equal(t, "0af7651916cd43dd8448eb211c80319c", span.Parent().TraceID().String())
+	assert.Equal(t, span.SpanContext().SpanID(), handlerSpan.SpanID())
+
+	resp = api.Get("/things/bad")
+	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+
+	spans = recorder.Ended()
+	require.Len(t, spans, 2)
+	assert.Equal(t, codes.Error, spans[1].Status().Code)
+}
@@ -0,0 +1,126 @@
+// Package paging provides standard cursor & offset pagination for list
+// operations. Embed `Params` in your input struct to add the `cursor`,
+// `limit`, and `offset` query parameters, and return a `Page[T]` to send the
+// items along with next/previous cursors and an RFC 8288 `Link` header.
+//
+//	huma.Register(api, huma.Operation{
+//		OperationID: "list-things",
+//		Method:      http.MethodGet,
+//		Path:        "/things",
+//	}, func(ctx context.Context, input *struct {
+//		paging.Params
+//	}) (*paging.Page[Thing], error) {
+//		things, next := db.ListThings(input.Cursor, input.Limit)
+//		return paging.NewPage(&input.Params, things, next, ""), nil
+//	})
+package paging
+
+import (
+	"net/url"
+	"strconv"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// Params are pagination query parameters. Clients start with the first page
+// and follow the `next` cursor or link. Operations which support random
+// access can use the offset instead of cursors.
+type Params struct {
+	Cursor string `query:"cursor" doc:"Opaque cursor from a previous page to continue from. Takes precedence over offset."`
+	Limit  int    `query:"limit" minimum:"1" maximum:"1000" default:"20" doc:"Maximum number of items to return."`
+	Offset int    `query:"offset" minimum:"0" doc:"Number of items to skip, for operations which support random access."`
+
+	// url is the request URL, used to generate links to other pages.
+	url url.URL
+}
+
+func (p *Params) Resolve(ctx huma.Context) []error {
+	p.url = ctx.URL()
+	return nil
+}
+
+// link returns a link to the current URL with the given query params set,
+// removing the cursor and offset params unless set.
+func (p *Params) link(rel string, params map[string]string) string {
+	u := p.url
+	query := u.Query()
+	query.Del("cursor")
+	query.Del("offset")
+	for k, v := range params {
+		query.Set(k, v)
+	}
+	u.RawQuery = query.Encode()
+	return "<" + u.String() + `>; rel="` + rel + `"`
+}
+
+// PageBody is the response body of a page of items.
+type PageBody[T any] struct {
+	Items []T    `json:"items" doc:"Items in this page."`
+	Next  string `json:"next,omitempty" doc:"Cursor for the next page, if there is one."`
+	Prev  string `json:"prev,omitempty" doc:"Cursor for the previous page, if there is one."`
+	Total *int   `json:"total,omitempty" doc:"Total number of items, if known."`
+}
+
+// Page is an operation output with a page of items. The `Link` header
+// contains RFC 8288 links to the `next`, `prev`, `first`, and `last` pages
+// where available.
+type Page[T any] struct {
+	Link []string `header:"Link" doc:"Links to other pages, with relations like next, prev, first, and last."`
+	Body PageBody[T]
+}
+
+// NewPage creates a cursor-based page. The next and prev cursors should be
+// empty if there is no next or previous page.
+func NewPage[T any](p *Params, items []T, next, prev string) *Page[T] {
+	if items == nil {
+		items = []T{}
+	}
+	page := &Page[T]{Body: PageBody[T]{Items: items, Next: next, Prev: prev}}
+
+	limit := strconv.Itoa(p.Limit)
+	var links []string
+	if next != "" {
+		links = append(links, p.link("next", map[string]string{"cursor": next, "limit": limit}))
+	}
+	if prev != "" {
+		links = append(links, p.link("prev", map[string]string{"cursor": prev, "limit": limit}))
+	}
+	page.Link = links
+	return page
+}
+
+// NewOffsetPage creates an offset-based page given the total number of items,
+// linking to the first, last, next, and previous pages.
+func NewOffsetPage[T any](p *Params, items []T, total int) *Page[T] {
+	if items == nil {
+		items = []T{}
+	}
+	page := &Page[T]{Body: PageBody[T]{Items: items, Total: &total}}
+
+	limit := p.Limit
+	if limit <= 0 {
+		limit = 1
+	}
+	offsetLink := func(rel string, offset int) string {
+		return p.link(rel, map[string]string{"offset": strconv.Itoa(offset), "limit": strconv.Itoa(limit)})
+	}
+
+	last := 0
+	if total > 0 {
+		last = (total - 1) / limit * limit
+	}
+	links := []string{offsetLink("first", 0)}
+	if p.Offset > 0 {
+		prev := p.Offset - limit
+		if prev < 0 {
+			prev = 0
+		}
+		links = append(links, offsetLink("prev", prev))
+	}
+	if p.Offset+limit < total {
+		links = append(links, offsetLink("next", p.Offset+limit))
+	}
+	links = append(links, offsetLink("last", last))
+	page.Link = links
+	return page
+}
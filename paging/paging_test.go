@@ -0,0 +1,124 @@
+package paging
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/humatest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type Thing struct {
+	ID string `json:"id"`
+}
+
+func things(n int) []Thing {
+	items := make([]Thing, n)
+	for i := range items {
+		items[i] = Thing{ID: strconv.Itoa(i)}
+	}
+	return items
+}
+
+func TestCursorPage(t *testing.T) {
+	_, api := humatest.New(t)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "list-things",
+		Method:      http.MethodGet,
+		Path:        "/things",
+	}, func(ctx context.Context, input *struct {
+		Params
+		Sort string `query:"sort"`
+	}) (*Page[Thing], error) {
+		assert.Equal(t, "name", input.Sort)
+		switch input.Cursor {
+		case "":
+			return NewPage(&input.Params, things(input.Limit), "c2", ""), nil
+		default:
+			return NewPage[Thing](&input.Params, nil, "", "c1"), nil
+		}
+	})
+
+	resp := api.Get("/things?sort=name&limit=2")
+	require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+	assert.Equal(t, []string{`</things?cursor=c2&limit=2&sort=name>; rel="next"`}, resp.Header().Values("Link"))
+	assert.JSONEq(t, `{"items": [{"id": "0"}, {"id": "1"}], "next": "c2"}`, resp.Body.String())
+
+	resp = api.Get("/things?sort=name&cursor=c2&limit=2")
+	require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+	assert.Equal(t, []string{`</things?cursor=c1&limit=2&sort=name>; rel="prev"`}, resp.Header().Values("Link"))
+	assert.JSONEq(t, `{"items": [], "prev": "c1"}`, resp.Body.String())
+
+	// Default limit.
+	resp = api.Get("/things?sort=name")
+	require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+	assert.Contains(t, resp.Header().Get("Link"), "limit=20")
+
+	resp = api.Get("/things?sort=name&limit=0")
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+}
+
+func TestOffsetPage(t *testing.T) {
+	_, api := humatest.New(t)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "list-things",
+		Method:      http.MethodGet,
+		Path:        "/things",
+	}, func(ctx context.Context, input *struct {
+		Params
+	}) (*Page[Thing], error) {
+		return NewOffsetPage(&input.Params, things(input.Limit), 25), nil
+	})
+
+	resp := api.Get("/things?offset=10&limit=10")
+	require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+	assert.Equal(t, []string{
+		`</things?limit=10&offset=0>; rel="first"`,
+		`</things?limit=10&offset=0>; rel="prev"`,
+		`</things?limit=10&offset=20>; rel="next"`,
+		`</things?limit=10&offset=20>; rel="last"`,
+	}, resp.Header().Values("Link"))
+	assert.Contains(t, resp.Body.String(), `"total":25`)
+
+	resp = api.Get("/things?offset=20&limit=10")
+	require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+	assert.Equal(t, []string{
+		`</things?limit=10&offset=0>; rel="first"`,
+		`</things?limit=10&offset=10>; rel="prev"`,
+		`</things?limit=10&offset=20>; rel="last"`,
+	}, resp.Header().Values("Link"))
+}
+
+func TestOpenAPI(t *testing.T) {
+	_, api := humatest.New(t)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "list-things",
+		Method:      http.MethodGet,
+		Path:        "/things",
+	}, func(ctx context.Context, input *struct {
+		Params
+	}) (*Page[Thing], error) {
+		return nil, nil
+	})
+
+	op := api.OpenAPI().Paths["/things"].Get
+	names := []string{}
+	for _, p := range op.Parameters {
+		names = append(names, p.Name)
+	}
+	assert.ElementsMatch(t, []string{"cursor", "limit", "offset"}, names)
+	assert.NotNil(t, op.Responses["200"].Headers["Link"])
+
+	body := api.OpenAPI().Components.Schemas.SchemaFromRef(op.Responses["200"].Content["application/json"].Schema.Ref)
+	require.NotNil(t, body)
+	assert.Contains(t, body.Properties, "items")
+	assert.Contains(t, body.Properties, "next")
+	assert.Equal(t, []string{"items"}, body.Required)
+}
@@ -0,0 +1,94 @@
+package huma
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// ProblemType describes a kind of error with a stable `type` URI, as
+// recommended by RFC 9457 Problem Details for HTTP APIs. Problem types are
+// registered via `Config.ProblemTypes`, which includes them in the OpenAPI
+// as the `x-problem-types` extension and, if `Config.ErrorsPath` is set,
+// serves documentation for each at `{ErrorsPath}/{name}`.
+//
+//	var OutOfStock = &huma.ProblemType{
+//		Name:        "out-of-stock",
+//		Title:       "Out of stock",
+//		Status:      http.StatusConflict,
+//		Description: "The requested item is no longer available.",
+//	}
+//
+//	config.ErrorsPath = "/errors"
+//	config.ProblemTypes = []*huma.ProblemType{OutOfStock}
+//
+//	// Later, in a handler...
+//	return nil, OutOfStock.New("item 123 is out of stock")
+type ProblemType struct {
+	// Name is a unique, URL-safe identifier like `out-of-stock`.
+	Name string `json:"name" yaml:"name"`
+
+	// Type is the URI identifying the problem type. If empty, it is set to
+	// `{ErrorsPath}/{Name}` when the API is created.
+	Type string `json:"type" yaml:"type"`
+
+	// Title is a short, human-readable summary which does not change between
+	// occurrences of the problem.
+	Title string `json:"title" yaml:"title"`
+
+	// Status is the HTTP status code used for this problem type.
+	Status int `json:"status" yaml:"status"`
+
+	// Description explains the problem and how clients can resolve it.
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+}
+
+// New creates a new error of this problem type with the given detail message
+// and optional error details. The error is created using `huma.NewError`, and
+// if it is a `*huma.ErrorModel` then its `type` and `title` are set.
+func (p *ProblemType) New(detail string, errs ...error) StatusError {
+	err := NewError(p.Status, detail, errs...)
+	if model, ok := err.(*ErrorModel); ok {
+		model.Type = p.Type
+		if p.Title != "" {
+			model.Title = p.Title
+		}
+	}
+	return err
+}
+
+// setupProblemTypes fills in problem type URIs, adds the catalog to the
+// OpenAPI, and serves the documentation endpoint if enabled.
+func setupProblemTypes(config Config, a Adapter) {
+	if len(config.ProblemTypes) == 0 {
+		return
+	}
+
+	byName := make(map[string]*ProblemType, len(config.ProblemTypes))
+	for _, p := range config.ProblemTypes {
+		if p.Type == "" && config.ErrorsPath != "" {
+			p.Type = config.ErrorsPath + "/" + p.Name
+		}
+		byName[p.Name] = p
+	}
+
+	if config.OpenAPI.Extensions == nil {
+		config.OpenAPI.Extensions = map[string]any{}
+	}
+	config.OpenAPI.Extensions["x-problem-types"] = config.ProblemTypes
+
+	if config.ErrorsPath != "" {
+		a.Handle(&Operation{
+			Method: http.MethodGet,
+			Path:   config.ErrorsPath + "/{type}",
+		}, func(ctx Context) {
+			p := byName[ctx.Param("type")]
+			if p == nil {
+				ctx.SetStatus(http.StatusNotFound)
+				return
+			}
+			ctx.SetHeader("Content-Type", "application/json")
+			b, _ := json.Marshal(p)
+			ctx.BodyWriter().Write(b)
+		})
+	}
+}
@@ -0,0 +1,276 @@
+// Package promhuma provides Prometheus metrics for Huma APIs. It is
+// implemented as router-agnostic Huma middleware, so every adapter produces
+// identical metrics.
+//
+//	api := humachi.New(router, huma.DefaultConfig("My API", "1.0.0"))
+//	api.UseMiddleware(promhuma.Middleware(prometheus.DefaultRegisterer))
+//	router.Handle("/metrics", promhttp.Handler())
+package promhuma
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type config struct {
+	namespace   string
+	buckets     []float64
+	sizeBuckets []float64
+}
+
+// Option configures the metrics middleware.
+type Option func(*config)
+
+// WithNamespace sets a namespace prefix for the metric names.
+func WithNamespace(namespace string) Option {
+	return func(c *config) {
+		c.namespace = namespace
+	}
+}
+
+// WithBuckets sets the request duration histogram buckets in seconds.
+// Defaults to `prometheus.DefBuckets`.
+func WithBuckets(buckets []float64) Option {
+	return func(c *config) {
+		c.buckets = buckets
+	}
+}
+
+// WithSizeBuckets sets the request & response size histogram buckets in
+// bytes. Defaults to exponential buckets from 100 bytes to 100 MB.
+func WithSizeBuckets(buckets []float64) Option {
+	return func(c *config) {
+		c.sizeBuckets = buckets
+	}
+}
+
+// metricsContext records the response status and size.
+type metricsContext struct {
+	huma.ContextWrapper
+	status int
+	writer *countingWriter
+}
+
+func (c *metricsContext) SetStatus(code int) {
+	c.status = code
+	c.ContextWrapper.SetStatus(code)
+}
+
+func (c *metricsContext) BodyWriter() io.Writer {
+	if c.writer == nil {
+		c.writer = &countingWriter{w: c.ContextWrapper.BodyWriter()}
+	}
+	return c.writer
+}
+
+func (c *metricsContext) StreamBody(cb func(w io.Writer, flush func() error)) {
+	if sc, ok := c.Unwrap().(huma.StreamingContext); ok {
+		sc.StreamBody(func(w io.Writer, flush func() error) {
+			if c.writer == nil {
+				c.writer = &countingWriter{}
+			}
+			c.writer.w = w
+			cb(c.writer, flush)
+		})
+		return
+	}
+	w := c.BodyWriter()
+	cb(w, c.writer.FlushError)
+}
+
+// countingWriter counts the bytes written to the response while still
+// allowing it to be flushed.
+type countingWriter struct {
+	w     io.Writer
+	count int64
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	n, err := w.w.Write(p)
+	w.count += int64(n)
+	return n, err
+}
+
+func (w *countingWriter) Flush() {
+	w.FlushError()
+}
+
+func (w *countingWriter) FlushError() error {
+	switch t := w.w.(type) {
+	case interface{ FlushError() error }:
+		return t.FlushError()
+	case interface{ Flush() error }:
+		return t.Flush()
+	case http.Flusher:
+		t.Flush()
+		return nil
+	case http.ResponseWriter:
+		return http.NewResponseController(t).Flush()
+	}
+	return http.ErrNotSupported
+}
+
+// statusClass returns the class of a status code, e.g. `2xx`.
+func statusClass(status int) string {
+	return strconv.Itoa(status/100) + "xx"
+}
+
+// Middleware creates and registers request metrics with the given registerer
+// and returns middleware which records them. Metrics are labeled by the
+// operation ID (or the method and path template if there is no ID) and the
+// response status class such as `2xx`:
+//
+//   - `http_requests_total` counts completed requests.
+//   - `http_request_duration_seconds` is a histogram of request durations.
+//   - `http_requests_in_flight` is a gauge of requests currently being
+//     handled, labeled only by operation.
+//   - `http_request_size_bytes` is a histogram of request body sizes, taken
+//     from the `Content-Length` header.
+//   - `http_response_size_bytes` is a histogram of response body sizes.
+//     Adapters which stream bodies after the handler returns (e.g. Fiber) may
+//     report partial sizes for streamed responses.
+func Middleware(reg prometheus.Registerer, opts ...Option) func(ctx huma.Context, next func(huma.Context)) {
+	c := config{
+		buckets:     prometheus.DefBuckets,
+		sizeBuckets: prometheus.ExponentialBuckets(100, 10, 7),
+	}
+	for _, opt := range opts {
+		opt(&c)
+	}
+
+	labels := []string{"operation", "status"}
+	requests := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: c.namespace,
+		Name:      "http_requests_total",
+		Help:      "Total number of HTTP requests.",
+	}, labels)
+	duration := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: c.namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request duration in seconds.",
+		Buckets:   c.buckets,
+	}, labels)
+	inFlight := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: c.namespace,
+		Name:      "http_requests_in_flight",
+		Help:      "Number of HTTP requests currently being handled.",
+	}, []string{"operation"})
+	requestSize := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: c.namespace,
+		Name:      "http_request_size_bytes",
+		Help:      "HTTP request body size in bytes.",
+		Buckets:   c.sizeBuckets,
+	}, labels)
+	responseSize := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: c.namespace,
+		Name:      "http_response_size_bytes",
+		Help:      "HTTP response body size in bytes.",
+		Buckets:   c.sizeBuckets,
+	}, labels)
+	reg.MustRegister(requests, duration, inFlight, requestSize, responseSize)
+
+	return func(ctx huma.Context, next func(huma.Context)) {
+		op := ctx.Operation()
+		name := op.OperationID
+		if name == "" {
+			name = op.Method + " " + op.Path
+		}
+
+		gauge := inFlight.WithLabelValues(name)
+		gauge.Inc()
+		start := time.Now()
+
+		mctx := &metricsContext{ContextWrapper: huma.WrapContext(ctx)}
+		defer func() {
+			gauge.Dec()
+
+			status := mctx.status
+			if r := recover(); r != nil {
+				// Count panics as server errors, then let them continue.
+				status = http.StatusInternalServerError
+				defer panic(r)
+			}
+			if status == 0 {
+				status = http.StatusOK
+			}
+
+			class := statusClass(status)
+			requests.WithLabelValues(name, class).Inc()
+			duration.WithLabelValues(name, class).Observe(time.Since(start).Seconds())
+
+			var reqSize int64
+			if l, err := strconv.ParseInt(ctx.Header("Content-Length"), 10, 64); err == nil {
+				reqSize = l
+			}
+			requestSize.WithLabelValues(name, class).Observe(float64(reqSize))
+
+			var respSize int64
+			if mctx.writer != nil {
+				respSize = mctx.writer.count
+			}
+			responseSize.WithLabelValues(name, class).Observe(float64(respSize))
+		}()
+
+		next(mctx)
+	}
+}
+
+// DeprecatedUsage creates and registers a counter of requests using
+// deprecated parameters and body fields, and returns a function to set as
+// `huma.Config.OnDeprecatedUsage`. The `http_deprecated_usage_total` counter
+// is labeled by the operation and the location of the deprecated value,
+// with array indexes removed, e.g. `body.items[].legacy_id`.
+//
+//	config := huma.DefaultConfig("My API", "1.0.0")
+//	config.OnDeprecatedUsage = promhuma.DeprecatedUsage(prometheus.DefaultRegisterer)
+func DeprecatedUsage(reg prometheus.Registerer, opts ...Option) func(ctx huma.Context, location string) {
+	c := config{}
+	for _, opt := range opts {
+		opt(&c)
+	}
+
+	usage := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: c.namespace,
+		Name:      "http_deprecated_usage_total",
+		Help:      "Total number of requests using deprecated parameters or fields.",
+	}, []string{"operation", "location"})
+	reg.MustRegister(usage)
+
+	return func(ctx huma.Context, location string) {
+		op := ctx.Operation()
+		name := op.OperationID
+		if name == "" {
+			name = op.Method + " " + op.Path
+		}
+		usage.WithLabelValues(name, stripIndexes(location)).Inc()
+	}
+}
+
+// stripIndexes removes array indexes from a location to keep the number of
+// label values bounded, e.g. `body.items[3].id` becomes `body.items[].id`.
+func stripIndexes(location string) string {
+	if !strings.Contains(location, "[") {
+		return location
+	}
+	var sb strings.Builder
+	skip := false
+	for _, r := range location {
+		switch {
+		case r == '[':
+			skip = true
+			sb.WriteRune(r)
+		case r == ']':
+			skip = false
+			sb.WriteRune(r)
+		case !skip:
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,97 @@
+package promhuma
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/humatest"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMiddleware(t *testing.T) {
+	reg := prometheus.NewRegistry()
+
+	_, api := humatest.New(t, huma.DefaultConfig("Test API", "1.0.0"))
+	api.UseMiddleware(Middleware(reg, WithNamespace("test")))
+
+	huma.Register(api, huma.Operation{
+		OperationID: "create-thing",
+		Method:      http.MethodPost,
+		Path:        "/things",
+	}, func(ctx context.Context, input *struct {
+		Body struct {
+			Name string `json:"name"`
+		}
+	}) (*struct {
+		Body struct {
+			Name string `json:"name"`
+		}
+	}, error) {
+		if input.Body.Name == "bad" {
+			return nil, huma.Error500InternalServerError("whoops")
+		}
+		out := &struct {
+			Body struct {
+				Name string `json:"name"`
+			}
+		}{}
+		out.Body.Name = input.Body.Name
+		return out, nil
+	})
+
+	api.Post("/things", map[string]any{"name": "one"})
+	api.Post("/things", map[string]any{"name": "two"})
+	api.Post("/things", map[string]any{"name": "bad"})
+
+	assert.NoError(t, testutil.GatherAndCompare(reg, strings.NewReader(`
+# HELP test_http_requests_total Total number of HTTP requests.
+# TYPE test_http_requests_total counter
+test_http_requests_total{operation="create-thing",status="2xx"} 2
+test_http_requests_total{operation="create-thing",status="5xx"} 1
+# HELP test_http_requests_in_flight Number of HTTP requests currently being handled.
+# TYPE test_http_requests_in_flight gauge
+test_http_requests_in_flight{operation="create-thing"} 0
+`), "test_http_requests_total", "test_http_requests_in_flight"))
+
+	count, err := testutil.GatherAndCount(reg, "test_http_request_duration_seconds", "test_http_request_size_bytes", "test_http_response_size_bytes")
+	assert.NoError(t, err)
+	assert.Equal(t, 6, count)
+}
+
+func TestDeprecatedUsage(t *testing.T) {
+	reg := prometheus.NewRegistry()
+
+	config := huma.DefaultConfig("Test API", "1.0.0")
+	config.OnDeprecatedUsage = DeprecatedUsage(reg)
+	_, api := humatest.New(t, config)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "list-things",
+		Method:      http.MethodGet,
+		Path:        "/things",
+	}, func(ctx context.Context, input *struct {
+		Sort string `query:"sort" deprecated:"true"`
+	}) (*struct{}, error) {
+		return nil, nil
+	})
+
+	api.Get("/things?sort=name")
+	api.Get("/things?sort=date")
+	api.Get("/things")
+
+	assert.NoError(t, testutil.GatherAndCompare(reg, strings.NewReader(`
+# HELP http_deprecated_usage_total Total number of requests using deprecated parameters or fields.
+# TYPE http_deprecated_usage_total counter
+http_deprecated_usage_total{location="query.sort",operation="list-things"} 2
+`), "http_deprecated_usage_total"))
+}
+
+func TestStripIndexes(t *testing.T) {
+	assert.Equal(t, "query.sort", stripIndexes("query.sort"))
+	assert.Equal(t, "body.items[].tags[].id", stripIndexes("body.items[12].tags[0].id"))
+}
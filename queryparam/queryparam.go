@@ -7,8 +7,8 @@
 //		value := queryparam.Get(r.URL.RawQuery, "key")
 //	}
 //
-// Note that this method does not support multiple values for the same key,
-// so using `val=1,2,3` is preferable to `val=1&val=2&val=3`.
+// Use `GetAll` or an `Iterator` when a key may be repeated, as in
+// `val=1&val=2&val=3`.
 package queryparam
 
 import (
@@ -59,3 +59,74 @@ func Get(query, name string) string {
 	}
 	return ""
 }
+
+// GetAll returns all values for a repeated query param like
+// `val=1&val=2&val=3`, in the order they appear. Only the returned slice is
+// allocated, and it is nil if the param is not present.
+func GetAll(query, name string) []string {
+	var values []string
+	it := NewIterator(query)
+	for {
+		k, v, ok := it.Next()
+		if !ok {
+			break
+		}
+		if k == name {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// Iterator walks over the query params in a raw query string without any
+// dynamic allocations (unless a name or value needs to be unescaped).
+//
+//	it := queryparam.NewIterator(r.URL.RawQuery)
+//	for {
+//		name, value, ok := it.Next()
+//		if !ok {
+//			break
+//		}
+//		// ...
+//	}
+type Iterator struct {
+	query string
+	pos   int
+}
+
+// NewIterator creates an iterator over the given raw query string.
+func NewIterator(query string) Iterator {
+	return Iterator{query: query}
+}
+
+// Next returns the next query param name & value, or `ok` set to false once
+// there are no more params. Like `Get`, a param without a value such as
+// `?flag` has a value of `true`.
+func (it *Iterator) Next() (name, value string, ok bool) {
+	for it.pos < len(it.query) {
+		start := it.pos
+		end := strings.IndexByte(it.query[start:], '&')
+		if end == -1 {
+			end = len(it.query)
+		} else {
+			end += start
+		}
+		it.pos = end + 1
+
+		if start == end {
+			// Empty param like `a=1&&b=2`.
+			continue
+		}
+
+		pair := it.query[start:end]
+		if eq := strings.IndexByte(pair, '='); eq != -1 {
+			name, _ = url.QueryUnescape(pair[:eq])
+			value, _ = url.QueryUnescape(pair[eq+1:])
+		} else {
+			name, _ = url.QueryUnescape(pair)
+			value = "true"
+		}
+		return name, value, true
+	}
+	return "", "", false
+}
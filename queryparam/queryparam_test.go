@@ -133,6 +133,44 @@ func TestQuery(t *testing.T) {
 	}
 }
 
+func TestQueryAll(t *testing.T) {
+	for _, item := range []struct {
+		query    string
+		name     string
+		expected []string
+	}{
+		{"foo=bar", "foo", []string{"bar"}},
+		{"foo=1&baz=123&foo=2&foo=3", "foo", []string{"1", "2", "3"}},
+		{"foo=a%20b&foo=c+d", "foo", []string{"a b", "c d"}},
+		{"foo=bar&&bool&foo", "foo", []string{"bar", "true"}},
+		{"foo=bar&baz=123", "missing", nil},
+	} {
+		t.Run(item.query+"/"+item.name, func(t *testing.T) {
+			assert.Equal(t, item.expected, GetAll(item.query, item.name))
+		})
+	}
+}
+
+func TestParseQueryIterator(t *testing.T) {
+	for _, test := range parseTests {
+		if !test.ok {
+			continue
+		}
+		t.Run(test.query, func(t *testing.T) {
+			result := url.Values{}
+			it := NewIterator(test.query)
+			for {
+				k, v, ok := it.Next()
+				if !ok {
+					break
+				}
+				result.Add(k, v)
+			}
+			assert.Equal(t, test.out, result)
+		})
+	}
+}
+
 var Result string
 
 func BenchmarkNewQuery(b *testing.B) {
@@ -175,3 +213,26 @@ func BenchmarkStdQueryMulti(b *testing.B) {
 		Boolean = Values.Get("bool")
 	}
 }
+
+var Results []string
+
+func BenchmarkNewQueryAll(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		Results = GetAll("foo=bar&baz=123&baz=456&bool", "baz")
+	}
+}
+
+func BenchmarkNewQueryIterator(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		it := NewIterator("foo=bar&baz=123&baz=456&bool")
+		for {
+			_, v, ok := it.Next()
+			if !ok {
+				break
+			}
+			Result = v
+		}
+	}
+}
@@ -0,0 +1,239 @@
+// Package ratelimit provides token bucket rate limiting for Huma APIs,
+// configured per operation or per tag and keyed by client. Requests which
+// exceed the limit receive a `429 Too Many Requests` error with a
+// `Retry-After` header, and all limited responses include `X-RateLimit-*`
+// headers describing the current state of the limit. The 429 response and
+// headers are documented in the OpenAPI for each limited operation.
+//
+//	ratelimit.Use(api, ratelimit.Config{
+//		Default: &ratelimit.Limit{Requests: 100, Period: time.Minute},
+//		Tags: map[string]ratelimit.Limit{
+//			"Search": {Requests: 10, Period: time.Minute},
+//		},
+//	})
+//
+// Limits can also be set on individual operations via metadata:
+//
+//	huma.Register(api, huma.Operation{
+//		OperationID: "login",
+//		Method:      http.MethodPost,
+//		Path:        "/login",
+//		Metadata: map[string]any{
+//			"ratelimit": ratelimit.Limit{Requests: 5, Period: time.Minute},
+//		},
+//	}, handler)
+package ratelimit
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// MetadataKey is the operation metadata key used to set a `Limit` for a
+// specific operation, overriding any tag or default limits.
+const MetadataKey = "ratelimit"
+
+// Limit describes how many requests are allowed over a period of time.
+type Limit struct {
+	// Requests is the number of requests allowed per `Period`. A value of zero
+	// or less disables rate limiting, which can be used to exempt individual
+	// operations from tag or default limits.
+	Requests int
+
+	// Period over which `Requests` are allowed, e.g. `time.Minute`.
+	Period time.Duration
+
+	// Burst is the maximum number of requests allowed at once, i.e. the size
+	// of the token bucket. Defaults to `Requests`.
+	Burst int
+}
+
+// rate returns the number of tokens added to the bucket per second.
+func (l Limit) rate() float64 {
+	return float64(l.Requests) / l.Period.Seconds()
+}
+
+func (l Limit) burst() int {
+	if l.Burst > 0 {
+		return l.Burst
+	}
+	return l.Requests
+}
+
+// Config configures rate limiting for an API.
+type Config struct {
+	// Store tracks the token buckets. Defaults to a new `MemoryStore`.
+	Store Store
+
+	// Default is the limit for operations which have no operation or tag
+	// limit. If nil, those operations are not rate limited.
+	Default *Limit
+
+	// Tags sets limits for operations by tag. Operations with a tag limit
+	// share a single bucket per client across all operations with that tag.
+	// If an operation has multiple limited tags then the first is used.
+	Tags map[string]Limit
+
+	// Key returns the client key used to identify whose bucket a request
+	// should use, e.g. an API key or user ID. Defaults to the client IP
+	// address, see `huma.ClientInfoFromContext`.
+	Key func(ctx huma.Context) string
+}
+
+// scope is a resolved limit along with the bucket it applies to.
+type scope struct {
+	name  string
+	limit Limit
+}
+
+// scope returns the limit for an operation, if any. Operation limits take
+// precedence over tag limits, which take precedence over the default.
+func (c *Config) scope(op *huma.Operation) (scope, bool) {
+	name := op.OperationID
+	if name == "" {
+		name = op.Method + " " + op.Path
+	}
+
+	var s scope
+	if l, ok := op.Metadata[MetadataKey].(Limit); ok {
+		s = scope{"op:" + name, l}
+	} else if t, l, ok := c.tagLimit(op); ok {
+		s = scope{"tag:" + t, l}
+	} else if c.Default != nil {
+		s = scope{"op:" + name, *c.Default}
+	} else {
+		return s, false
+	}
+	return s, s.limit.Requests > 0 && s.limit.Period > 0
+}
+
+func (c *Config) tagLimit(op *huma.Operation) (string, Limit, bool) {
+	for _, t := range op.Tags {
+		if l, ok := c.Tags[t]; ok {
+			return t, l, true
+		}
+	}
+	return "", Limit{}, false
+}
+
+// clientIP returns the client's IP address without a port, taking the API's
+// trusted proxies into account.
+func clientIP(ctx huma.Context) string {
+	if info := huma.ClientInfoFromContext(ctx.Context()); info.IP.IsValid() {
+		return info.IP.String()
+	} else if info.ID != "" {
+		// A trusted proxy hid the client's address behind an identifier.
+		return info.ID
+	}
+	addr := huma.RemoteAddr(ctx)
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		return host
+	}
+	return addr
+}
+
+// ceilSeconds formats a duration as a whole number of seconds, rounding up.
+func ceilSeconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
+
+// Use adds rate limiting middleware to the API and documents the limits in
+// the OpenAPI. Like other middleware, it must be called before registering
+// operations with `huma.Register` in order to apply to them.
+//
+// Responses for limited operations include these headers:
+//
+//   - `X-RateLimit-Limit` is the maximum number of requests allowed at once.
+//   - `X-RateLimit-Remaining` is the number of requests which can be made now.
+//   - `X-RateLimit-Reset` is the number of seconds until the limit fully resets.
+//
+// If the store returns an error then the request is allowed, so that an
+// unavailable store does not take down the API.
+func Use(api huma.API, config Config) {
+	if config.Store == nil {
+		config.Store = NewMemoryStore()
+	}
+	if config.Key == nil {
+		config.Key = clientIP
+	}
+
+	oapi := api.OpenAPI()
+	oapi.OnAddOperation = append(oapi.OnAddOperation, config.document)
+
+	api.UseMiddleware(func(ctx huma.Context, next func(huma.Context)) {
+		s, ok := config.scope(ctx.Operation())
+		if !ok {
+			next(ctx)
+			return
+		}
+
+		result, err := config.Store.Take(ctx.Context(), s.name+":"+config.Key(ctx), s.limit)
+		if err != nil {
+			next(ctx)
+			return
+		}
+
+		ctx.SetHeader("X-RateLimit-Limit", strconv.Itoa(s.limit.burst()))
+		ctx.SetHeader("X-RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		ctx.SetHeader("X-RateLimit-Reset", ceilSeconds(result.Reset))
+
+		if !result.Allowed {
+			ctx.SetHeader("Retry-After", ceilSeconds(result.RetryAfter))
+			huma.WriteErr(api, ctx, http.StatusTooManyRequests, "rate limit exceeded, retry after "+ceilSeconds(result.RetryAfter)+" seconds")
+			return
+		}
+
+		next(ctx)
+	})
+}
+
+// document adds the `429` response and rate limit headers to the OpenAPI for
+// limited operations.
+func (c *Config) document(oapi *huma.OpenAPI, op *huma.Operation) {
+	if _, ok := c.scope(op); !ok {
+		return
+	}
+
+	code := strconv.Itoa(http.StatusTooManyRequests)
+	if op.Responses == nil {
+		op.Responses = map[string]*huma.Response{}
+	}
+	if op.Responses[code] == nil {
+		op.Responses[code] = &huma.Response{
+			Description: http.StatusText(http.StatusTooManyRequests),
+			Content:     huma.ErrorContent(op),
+			Headers: map[string]*huma.Param{
+				"Retry-After": {
+					Description: "Number of seconds to wait before making another request.",
+					Schema:      &huma.Schema{Type: huma.TypeInteger},
+				},
+			},
+		}
+	}
+
+	for _, resp := range op.Responses {
+		if resp.Ref != "" {
+			continue
+		}
+		if resp.Headers == nil {
+			resp.Headers = map[string]*huma.Param{}
+		}
+		resp.Headers["X-RateLimit-Limit"] = &huma.Param{
+			Description: "Maximum number of requests allowed at once.",
+			Schema:      &huma.Schema{Type: huma.TypeInteger},
+		}
+		resp.Headers["X-RateLimit-Remaining"] = &huma.Param{
+			Description: "Number of requests which can be made right now.",
+			Schema:      &huma.Schema{Type: huma.TypeInteger},
+		}
+		resp.Headers["X-RateLimit-Reset"] = &huma.Param{
+			Description: "Number of seconds until the limit fully resets.",
+			Schema:      &huma.Schema{Type: huma.TypeInteger},
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/humatest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryStore(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	s := NewMemoryStore()
+	s.now = func() time.Time { return now }
+
+	limit := Limit{Requests: 2, Period: time.Second}
+	ctx := context.Background()
+
+	r, err := s.Take(ctx, "a", limit)
+	require.NoError(t, err)
+	assert.True(t, r.Allowed)
+	assert.Equal(t, 1, r.Remaining)
+	assert.Equal(t, 500*time.Millisecond, r.Reset)
+
+	r, _ = s.Take(ctx, "a", limit)
+	assert.True(t, r.Allowed)
+	assert.Equal(t, 0, r.Remaining)
+
+	r, _ = s.Take(ctx, "a", limit)
+	assert.False(t, r.Allowed)
+	assert.Equal(t, 500*time.Millisecond, r.RetryAfter)
+	assert.Equal(t, time.Second, r.Reset)
+
+	// Other keys have their own buckets.
+	r, _ = s.Take(ctx, "b", limit)
+	assert.True(t, r.Allowed)
+
+	// Tokens refill over time.
+	now = now.Add(500 * time.Millisecond)
+	r, _ = s.Take(ctx, "a", limit)
+	assert.True(t, r.Allowed)
+	assert.Equal(t, 0, r.Remaining)
+
+	// Full buckets are eventually removed.
+	now = now.Add(2 * sweepInterval)
+	s.Take(ctx, "c", limit)
+	assert.Len(t, s.buckets, 1)
+}
+
+func TestBurst(t *testing.T) {
+	s := NewMemoryStore()
+	limit := Limit{Requests: 1, Period: time.Hour, Burst: 3}
+
+	for i := 0; i < 3; i++ {
+		r, _ := s.Take(context.Background(), "a", limit)
+		assert.True(t, r.Allowed)
+	}
+	r, _ := s.Take(context.Background(), "a", limit)
+	assert.False(t, r.Allowed)
+}
+
+type errorStore struct{}
+
+func (errorStore) Take(ctx context.Context, key string, limit Limit) (Result, error) {
+	return Result{}, errors.New("unavailable")
+}
+
+func register(api huma.API, id string, tags []string, metadata map[string]any) {
+	huma.Register(api, huma.Operation{
+		OperationID: id,
+		Method:      http.MethodGet,
+		Path:        "/" + id,
+		Tags:        tags,
+		Metadata:    metadata,
+	}, func(ctx context.Context, input *struct{}) (*struct{}, error) {
+		return nil, nil
+	})
+}
+
+func TestUse(t *testing.T) {
+	_, api := humatest.New(t, huma.DefaultConfig("Test API", "1.0.0"))
+	Use(api, Config{
+		Default: &Limit{Requests: 1, Period: time.Minute},
+		Tags: map[string]Limit{
+			"search": {Requests: 2, Period: time.Minute},
+		},
+		Key: func(ctx huma.Context) string {
+			return ctx.Header("X-API-Key")
+		},
+	})
+
+	register(api, "default", nil, nil)
+	register(api, "search-a", []string{"search"}, nil)
+	register(api, "search-b", []string{"search"}, nil)
+	register(api, "login", nil, map[string]any{MetadataKey: Limit{Requests: 3, Period: time.Minute}})
+	register(api, "health", nil, map[string]any{MetadataKey: Limit{}})
+
+	resp := api.Get("/default", "X-API-Key: a")
+	assert.Equal(t, http.StatusNoContent, resp.Code)
+	assert.Equal(t, "1", resp.Header().Get("X-RateLimit-Limit"))
+	assert.Equal(t, "0", resp.Header().Get("X-RateLimit-Remaining"))
+	assert.Equal(t, "60", resp.Header().Get("X-RateLimit-Reset"))
+
+	resp = api.Get("/default", "X-API-Key: a")
+	assert.Equal(t, http.StatusTooManyRequests, resp.Code)
+	assert.Equal(t, "60", resp.Header().Get("Retry-After"))
+	assert.Contains(t, resp.Body.String(), "rate limit exceeded")
+
+	// Clients are limited separately.
+	resp = api.Get("/default", "X-API-Key: b")
+	assert.Equal(t, http.StatusNoContent, resp.Code)
+
+	// Tag limits are shared across operations.
+	assert.Equal(t, http.StatusNoContent, api.Get("/search-a", "X-API-Key: a").Code)
+	assert.Equal(t, http.StatusNoContent, api.Get("/search-b", "X-API-Key: a").Code)
+	assert.Equal(t, http.StatusTooManyRequests, api.Get("/search-a", "X-API-Key: a").Code)
+
+	// Operation limits override the default.
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, http.StatusNoContent, api.Get("/login", "X-API-Key: a").Code)
+	}
+	assert.Equal(t, http.StatusTooManyRequests, api.Get("/login", "X-API-Key: a").Code)
+
+	// Operations can be exempted.
+	for i := 0; i < 3; i++ {
+		resp = api.Get("/health", "X-API-Key: a")
+		assert.Equal(t, http.StatusNoContent, resp.Code)
+		assert.Empty(t, resp.Header().Get("X-RateLimit-Limit"))
+	}
+
+	// The limits are documented.
+	op := api.OpenAPI().Paths["/default"].Get
+	require.NotNil(t, op.Responses["429"])
+	assert.NotNil(t, op.Responses["429"].Headers["Retry-After"])
+	assert.NotEmpty(t, op.Responses["429"].Content)
+	for _, code := range []string{"204", "429"} {
+		for _, name := range []string{"X-RateLimit-Limit", "X-RateLimit-Remaining", "X-RateLimit-Reset"} {
+			assert.NotNil(t, op.Responses[code].Headers[name], code+" "+name)
+		}
+	}
+	assert.Nil(t, api.OpenAPI().Paths["/health"].Get.Responses["429"])
+}
+
+func TestClientIP(t *testing.T) {
+	_, api := humatest.New(t, huma.DefaultConfig("Test API", "1.0.0"))
+	Use(api, Config{Default: &Limit{Requests: 1, Period: time.Minute}})
+	register(api, "test", nil, nil)
+
+	// `httptest.NewRequest` always uses the same remote address.
+	for _, expected := range []int{http.StatusNoContent, http.StatusTooManyRequests} {
+		w := httptest.NewRecorder()
+		api.Adapter().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/test", nil))
+		assert.Equal(t, expected, w.Code)
+	}
+}
+
+func TestStoreError(t *testing.T) {
+	_, api := humatest.New(t, huma.DefaultConfig("Test API", "1.0.0"))
+	Use(api, Config{
+		Store:   errorStore{},
+		Default: &Limit{Requests: 1, Period: time.Minute},
+	})
+	register(api, "test", nil, nil)
+
+	// Requests are allowed when the store is unavailable.
+	assert.Equal(t, http.StatusNoContent, api.Get("/test").Code)
+	assert.Equal(t, http.StatusNoContent, api.Get("/test").Code)
+}
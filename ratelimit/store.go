@@ -0,0 +1,109 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// Result describes the state of a bucket after attempting to take a token.
+type Result struct {
+	// Allowed is true if a token was taken and the request may proceed.
+	Allowed bool
+
+	// Remaining is the number of requests which can be made right now.
+	Remaining int
+
+	// Reset is how long until the bucket is completely refilled.
+	Reset time.Duration
+
+	// RetryAfter is how long until the next request will be allowed. It is
+	// zero when `Allowed` is true.
+	RetryAfter time.Duration
+}
+
+// Store tracks token buckets by key. Implementations must be safe for
+// concurrent use. A shared store such as Redis can be used to apply limits
+// across multiple instances of a service.
+type Store interface {
+	// Take attempts to take a token from the bucket identified by `key`,
+	// creating a full bucket for the limit if it does not yet exist.
+	Take(ctx context.Context, key string, limit Limit) (Result, error)
+}
+
+// bucket is a token bucket which refills continuously.
+type bucket struct {
+	tokens  float64
+	updated time.Time
+	full    time.Time
+}
+
+// MemoryStore is an in-process `Store`. Buckets which have completely
+// refilled are periodically removed, so memory use is proportional to the
+// number of recently active clients.
+type MemoryStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+
+	// now returns the current time and can be replaced for testing.
+	now func() time.Time
+}
+
+// NewMemoryStore creates a new in-process store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		buckets: map[string]*bucket{},
+		now:     time.Now,
+	}
+}
+
+// sweepInterval is how often full buckets are removed from a `MemoryStore`.
+const sweepInterval = time.Minute
+
+// Take implements the `Store` interface.
+func (s *MemoryStore) Take(ctx context.Context, key string, limit Limit) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if now.Sub(s.lastSweep) > sweepInterval {
+		for k, b := range s.buckets {
+			if !now.Before(b.full) {
+				delete(s.buckets, k)
+			}
+		}
+		s.lastSweep = now
+	}
+
+	rate := limit.rate()
+	burst := float64(limit.burst())
+
+	b := s.buckets[key]
+	if b == nil {
+		b = &bucket{tokens: burst, updated: now}
+		s.buckets[key] = b
+	} else {
+		b.tokens = math.Min(burst, b.tokens+now.Sub(b.updated).Seconds()*rate)
+		b.updated = now
+	}
+
+	result := Result{}
+	if b.tokens >= 1 {
+		b.tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = seconds((1 - b.tokens) / rate)
+	}
+	result.Remaining = int(b.tokens)
+	result.Reset = seconds((burst - b.tokens) / rate)
+	b.full = now.Add(result.Reset)
+
+	return result, nil
+}
+
+// seconds converts fractional seconds to a duration.
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
@@ -0,0 +1,79 @@
+package huma
+
+import (
+	"net/http"
+	"reflect"
+	"strconv"
+)
+
+// RegisterRaw registers a raw handler for the given operation. Unlike
+// `Register`, no input parsing, validation, or output serialization takes
+// place and the handler has full control of the request and response. This
+// is useful for proxies, file servers, and protocol-upgrade endpoints like
+// websockets. The operation is still documented in the OpenAPI unless it is
+// hidden, so any parameters, request body, and responses should be described
+// on the operation itself.
+//
+//	huma.RegisterRaw(api, huma.Operation{
+//		OperationID: "get-file",
+//		Method:      http.MethodGet,
+//		Path:        "/files/{path}",
+//		Summary:     "Download a file",
+//		Responses: map[string]*huma.Response{
+//			"200": {Description: "File contents"},
+//		},
+//	}, func(ctx huma.Context) {
+//		ctx.SetHeader("Content-Type", "application/octet-stream")
+//		ctx.BodyWriter().Write(data)
+//	})
+func RegisterRaw(api API, op Operation, handler func(ctx Context)) {
+	oapi := api.OpenAPI()
+	registry := oapi.Components.Schemas
+
+	if op.Method == "" || op.Path == "" {
+		panic("method and path must be specified in operation")
+	}
+	applyTagDefaults(oapi, &op)
+
+	if op.Responses == nil {
+		op.Responses = map[string]*Response{}
+	}
+	if op.DefaultStatus != 0 {
+		statusStr := strconv.Itoa(op.DefaultStatus)
+		if op.Responses[statusStr] == nil {
+			op.Responses[statusStr] = &Response{
+				Description: http.StatusText(op.DefaultStatus),
+			}
+		}
+	}
+
+	addErrorTypeCodes(&op)
+	if len(op.Errors) > 0 || len(op.Responses) == 0 {
+		exampleErr := NewError(0, "")
+		errContentType := "application/json"
+		if ctf, ok := exampleErr.(ContentTypeFilter); ok {
+			errContentType = ctf.ContentType(errContentType)
+		}
+		errType := reflect.TypeOf(exampleErr)
+		errSchema := registry.Schema(errType, true, getHint(errType, "", "Error"))
+		for _, code := range op.Errors {
+			if v, ok := op.ErrorTypes[code]; ok {
+				op.Responses[strconv.Itoa(code)] = errorTypeResponse(registry, &op, code, v)
+				continue
+			}
+			op.Responses[strconv.Itoa(code)] = errorResponse(code, errContentType, errSchema)
+		}
+		if len(op.Responses) == 0 {
+			// Nothing is known about the response, so set a default.
+			op.Responses["default"] = &Response{
+				Description: "Response",
+			}
+		}
+	}
+
+	if !op.Hidden {
+		oapi.AddOperation(&op)
+	}
+
+	handle(api, &op, handler)
+}
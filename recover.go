@@ -0,0 +1,69 @@
+package huma
+
+import (
+	"io"
+	"net/http"
+	"runtime/debug"
+)
+
+// recoverContext tracks whether a response has been started so that a
+// recovered panic does not write a second status or a corrupted body.
+type recoverContext struct {
+	humaContext
+	written bool
+}
+
+func (c *recoverContext) SetStatus(code int) {
+	c.written = true
+	c.humaContext.SetStatus(code)
+}
+
+func (c *recoverContext) BodyWriter() io.Writer {
+	c.written = true
+	return c.humaContext.BodyWriter()
+}
+
+func (c *recoverContext) StreamBody(cb func(w io.Writer, flush func() error)) {
+	c.written = true
+	streamBody(c.humaContext, cb)
+}
+
+func (c *recoverContext) Unwrap() Context {
+	return c.humaContext
+}
+
+// recoverPanics wraps a handler so that panics are converted into a
+// `500 Internal Server Error` problem response when enabled in the API's
+// config. Panics with `http.ErrAbortHandler` are re-raised so the server can
+// abort the response as usual. If the response has already been started then
+// it is left as-is.
+func recoverPanics(api API, handler func(ctx Context)) func(ctx Context) {
+	config := configOf(api)
+	if !config.RecoverPanics {
+		return handler
+	}
+
+	onPanic := config.OnPanic
+	return func(ctx Context) {
+		rctx := &recoverContext{humaContext: ctx}
+		defer func() {
+			if r := recover(); r != nil {
+				if r == http.ErrAbortHandler {
+					panic(r)
+				}
+
+				if onPanic != nil {
+					onPanic(ctx, r, debug.Stack())
+				}
+
+				if !rctx.written {
+					// The panic value is not sent to the client as it may contain
+					// sensitive information.
+					WriteErr(api, ctx, http.StatusInternalServerError, "internal server error")
+				}
+			}
+		}()
+
+		handler(rctx)
+	}
+}
@@ -0,0 +1,165 @@
+package huma
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// Redacted replaces the values of sensitive fields when redacting.
+const Redacted = "[REDACTED]"
+
+// IsSensitive returns true if values of this schema should never be logged or
+// otherwise exposed. This is the case for fields tagged `sensitive:"true"`
+// (which sets the `x-sensitive` extension), `writeOnly` fields, and fields
+// with the `password` format.
+func (s *Schema) IsSensitive() bool {
+	if s == nil {
+		return false
+	}
+	if s.WriteOnly || s.Format == "password" {
+		return true
+	}
+	sensitive, _ := s.Extensions["x-sensitive"].(bool)
+	return sensitive
+}
+
+// Redact replaces sensitive values within `v`, which must be the result of
+// decoding JSON into an `any`, with `huma.Redacted` using the given schema.
+// Maps and slices are modified in place. References are resolved using the
+// registry. Sensitive values are redacted wherever they are found, including
+// within `allOf`, `anyOf`, and `oneOf` subschemas.
+func Redact(r Registry, s *Schema, v any) any {
+	if s == nil || v == nil {
+		return v
+	}
+	if s.IsSensitive() {
+		return Redacted
+	}
+	if s.Ref != "" {
+		// Fields may mark a referenced schema as sensitive, so the check above
+		// must happen before resolving the reference.
+		if s = r.SchemaFromRef(s.Ref); s == nil {
+			return v
+		}
+		if s.IsSensitive() {
+			return Redacted
+		}
+	}
+
+	switch tv := v.(type) {
+	case map[string]any:
+		for k, item := range tv {
+			if prop := s.Properties[k]; prop != nil {
+				tv[k] = Redact(r, prop, item)
+			} else if additional, ok := s.AdditionalProperties.(*Schema); ok {
+				tv[k] = Redact(r, additional, item)
+			}
+		}
+	case []any:
+		if s.Items != nil {
+			for i, item := range tv {
+				tv[i] = Redact(r, s.Items, item)
+			}
+		}
+	}
+
+	for _, subs := range [][]*Schema{s.AllOf, s.AnyOf, s.OneOf} {
+		for _, sub := range subs {
+			v = Redact(r, sub, v)
+		}
+	}
+
+	return v
+}
+
+// RedactJSON returns a copy of the JSON document `data` with sensitive values
+// replaced using `huma.Redact`. An error is returned if the document cannot
+// be parsed, in which case the original data should not be exposed.
+func RedactJSON(r Registry, s *Schema, data []byte) ([]byte, error) {
+	var v any
+	if err := json.Unmarshal(data, &v); err != nil {
+		return nil, err
+	}
+	return json.Marshal(Redact(r, s, v))
+}
+
+// containsSensitive returns whether the schema or any schema within it is
+// sensitive, in which case documents using it must not be exposed as-is.
+func containsSensitive(r Registry, s *Schema, visited map[*Schema]bool) bool {
+	if s == nil || visited[s] {
+		return false
+	}
+	visited[s] = true
+	if s.IsSensitive() {
+		return true
+	}
+	if s.Ref != "" {
+		return containsSensitive(r, r.SchemaFromRef(s.Ref), visited)
+	}
+	for _, prop := range s.Properties {
+		if containsSensitive(r, prop, visited) {
+			return true
+		}
+	}
+	if additional, ok := s.AdditionalProperties.(*Schema); ok && containsSensitive(r, additional, visited) {
+		return true
+	}
+	if containsSensitive(r, s.Items, visited) {
+		return true
+	}
+	for _, subs := range [][]*Schema{s.AllOf, s.AnyOf, s.OneOf} {
+		for _, sub := range subs {
+			if containsSensitive(r, sub, visited) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// RedactPath returns the request's URL path with the values of sensitive
+// path params replaced by `huma.Redacted`, for use in logs and traces.
+//
+//	// For `/reset/{token}` with `token` tagged `sensitive:"true"`:
+//	huma.RedactPath(ctx) // "/reset/[REDACTED]"
+func RedactPath(ctx Context) string {
+	path := ctx.URL().Path
+	op := ctx.Operation()
+	if op == nil {
+		return path
+	}
+	sensitive := map[string]bool{}
+	for _, p := range op.Parameters {
+		if p.In == "path" && p.Schema.IsSensitive() {
+			sensitive[p.Name] = true
+		}
+	}
+	if len(sensitive) == 0 {
+		return path
+	}
+
+	// Rebuild the path from the template, as values may contain slashes.
+	tmpl := op.Path
+	redacted := make([]byte, 0, len(path))
+	for {
+		start := strings.IndexByte(tmpl, '{')
+		if start < 0 {
+			break
+		}
+		end := strings.IndexByte(tmpl[start:], '}')
+		if end < 0 {
+			break
+		}
+		end += start
+		redacted = append(redacted, tmpl[:start]...)
+		name := strings.TrimSuffix(tmpl[start+1:end], "...")
+		if sensitive[name] {
+			redacted = append(redacted, Redacted...)
+		} else {
+			redacted = append(redacted, ctx.Param(name)...)
+		}
+		tmpl = tmpl[end+1:]
+	}
+	redacted = append(redacted, tmpl...)
+	return string(redacted)
+}
@@ -0,0 +1,168 @@
+package huma_test
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/humatest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type RedactCredentials struct {
+	Username string `json:"username"`
+	Password string `json:"password" format:"password"`
+	APIKey   string `json:"apiKey" writeOnly:"true"`
+}
+
+type RedactAccount struct {
+	Name        string              `json:"name"`
+	SSN         string              `json:"ssn" sensitive:"true"`
+	Credentials RedactCredentials   `json:"credentials"`
+	History     []RedactCredentials `json:"history"`
+	Secrets     map[string]string   `json:"secrets" sensitive:"true"`
+	Nested      *RedactAccount      `json:"nested,omitempty" sensitive:"true"`
+}
+
+func TestRedactJSON(t *testing.T) {
+	r := huma.NewMapRegistry("#/components/schemas/", huma.DefaultSchemaNamer)
+	s := r.Schema(reflect.TypeOf(RedactAccount{}), true, "")
+
+	redacted, err := huma.RedactJSON(r, s, []byte(`{
+		"name": "Alice",
+		"ssn": "123-45-6789",
+		"credentials": {"username": "alice", "password": "hunter2", "apiKey": "abc"},
+		"history": [{"username": "old", "password": "letmein"}],
+		"secrets": {"a": "b"},
+		"nested": {"name": "Bob"},
+		"unknown": "kept"
+	}`))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"name": "Alice",
+		"ssn": "[REDACTED]",
+		"credentials": {"username": "alice", "password": "[REDACTED]", "apiKey": "[REDACTED]"},
+		"history": [{"username": "old", "password": "[REDACTED]"}],
+		"secrets": "[REDACTED]",
+		"nested": "[REDACTED]",
+		"unknown": "kept"
+	}`, string(redacted))
+
+	_, err = huma.RedactJSON(r, s, []byte(`{"ssn": "123-`))
+	assert.Error(t, err)
+}
+
+func TestRedactCombinators(t *testing.T) {
+	r := huma.NewMapRegistry("#/components/schemas/", huma.DefaultSchemaNamer)
+	s := &huma.Schema{
+		OneOf: []*huma.Schema{
+			{Type: huma.TypeObject, Properties: map[string]*huma.Schema{
+				"token": {Type: huma.TypeString, WriteOnly: true},
+			}},
+			{Type: huma.TypeObject, Properties: map[string]*huma.Schema{
+				"name": {Type: huma.TypeString},
+			}},
+		},
+	}
+
+	v := huma.Redact(r, s, map[string]any{"token": "secret", "name": "kept"})
+	assert.Equal(t, map[string]any{"token": huma.Redacted, "name": "kept"}, v)
+}
+
+func TestSensitiveSchema(t *testing.T) {
+	r := huma.NewMapRegistry("#/components/schemas/", huma.DefaultSchemaNamer)
+	s := r.Schema(reflect.TypeOf(RedactAccount{}), false, "")
+
+	assert.True(t, s.Properties["ssn"].IsSensitive())
+	assert.Equal(t, true, s.Properties["ssn"].Extensions["x-sensitive"])
+	assert.False(t, s.Properties["name"].IsSensitive())
+}
+
+func TestSensitiveValidationErrors(t *testing.T) {
+	_, api := humatest.New(t, huma.DefaultConfig("Test API", "1.0.0"))
+
+	huma.Register(api, huma.Operation{
+		OperationID: "create-account",
+		Method:      http.MethodPut,
+		Path:        "/account",
+	}, func(ctx context.Context, input *struct {
+		Body struct {
+			Name string `json:"name" minLength:"10"`
+			PIN  string `json:"pin" minLength:"6" sensitive:"true"`
+		}
+	}) (*struct{}, error) {
+		return nil, nil
+	})
+
+	resp := api.Put("/account", map[string]any{"name": "short", "pin": "1234"})
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+	body := resp.Body.String()
+	assert.Contains(t, body, `"value":"short"`)
+	assert.Contains(t, body, `"location":"body.pin","value":"[REDACTED]"`)
+	assert.False(t, strings.Contains(body, "1234"))
+}
+
+func TestSensitiveParseErrors(t *testing.T) {
+	_, api := humatest.New(t, huma.DefaultConfig("Test API", "1.0.0"))
+
+	huma.Register(api, huma.Operation{
+		OperationID: "login",
+		Method:      http.MethodPost,
+		Path:        "/login/{code}",
+	}, func(ctx context.Context, input *struct {
+		Code string `path:"code" style:"matrix" sensitive:"true"`
+		Body RedactCredentials
+	}) (*struct{}, error) {
+		return nil, nil
+	})
+
+	// Malformed bodies cannot be redacted, so are not echoed back at all.
+	resp := api.Post("/login/;code=1", strings.NewReader(`{"username": "u", "password": "hunter2"`))
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+	assert.Contains(t, resp.Body.String(), huma.Redacted)
+	assert.NotContains(t, resp.Body.String(), "hunter2")
+
+	resp = api.Post("/login/secret-code", map[string]any{"username": "u"})
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+	assert.Contains(t, resp.Body.String(), "path.code")
+	assert.NotContains(t, resp.Body.String(), "secret-code")
+}
+
+func TestRedactPath(t *testing.T) {
+	_, api := humatest.New(t, huma.DefaultConfig("Test API", "1.0.0"))
+
+	var paths []string
+	api.UseMiddleware(func(ctx huma.Context, next func(huma.Context)) {
+		paths = append(paths, huma.RedactPath(ctx))
+		next(ctx)
+	})
+
+	huma.Register(api, huma.Operation{
+		OperationID: "reset",
+		Method:      http.MethodGet,
+		Path:        "/users/{user}/reset/{token}",
+	}, func(ctx context.Context, input *struct {
+		User  string `path:"user"`
+		Token string `path:"token" sensitive:"true"`
+	}) (*struct{}, error) {
+		return nil, nil
+	})
+
+	huma.Register(api, huma.Operation{
+		OperationID: "get-user",
+		Method:      http.MethodGet,
+		Path:        "/users/{user}",
+	}, func(ctx context.Context, input *struct {
+		User string `path:"user"`
+	}) (*struct{}, error) {
+		return nil, nil
+	})
+
+	api.Get("/users/alice/reset/abc123")
+	api.Get("/users/bob")
+	assert.Equal(t, []string{"/users/alice/reset/[REDACTED]", "/users/bob"}, paths)
+}
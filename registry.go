@@ -20,6 +20,16 @@ type Registry interface {
 	Map() map[string]*Schema
 }
 
+// SchemaHookRegistry is a registry which supports customizing schemas after
+// they are generated. It is used by `Config.OnSchema`.
+type SchemaHookRegistry interface {
+	Registry
+
+	// OnSchema adds a function which is called with each newly generated
+	// schema and the type it was generated from.
+	OnSchema(f func(t reflect.Type, s *Schema))
+}
+
 // DefaultSchemaNamer provides schema names for types. It uses the type name
 // when possible, ignoring the package name. If the type is generic, e.g.
 // `MyType[SubType]`, then the brackets are removed like `MyTypeSubType`.
@@ -62,13 +72,19 @@ type mapRegistry struct {
 	types   map[string]reflect.Type
 	seen    map[reflect.Type]bool
 	namer   func(reflect.Type, string) string
+	hooks   []func(reflect.Type, *Schema)
+
+	// inline disables refs so every schema is generated where it is used.
+	// Types currently being inlined are tracked to detect recursion.
+	inline   bool
+	inlining map[reflect.Type]bool
 }
 
 func (r *mapRegistry) Schema(t reflect.Type, allowRef bool, hint string) *Schema {
 	t = deref(t)
 	getsRef := t.Kind() == reflect.Struct
-	if t == timeType {
-		// Special case: time.Time is always a string.
+	if t == timeType || t == addrType || t == prefixType || isDecimalType(t) {
+		// Special case: time.Time, netip, and decimal types are always strings.
 		getsRef = false
 	}
 
@@ -78,6 +94,17 @@ func (r *mapRegistry) Schema(t reflect.Type, allowRef bool, hint string) *Schema
 		getsRef = false
 	}
 
+	if r.inline {
+		if t.Kind() == reflect.Struct && getsRef {
+			if r.inlining[t] {
+				panic(fmt.Errorf("recursive type %s cannot be inlined", t))
+			}
+			r.inlining[t] = true
+			defer delete(r.inlining, t)
+		}
+		getsRef = false
+	}
+
 	name := r.namer(t, hint)
 
 	if getsRef {
@@ -101,6 +128,12 @@ func (r *mapRegistry) Schema(t reflect.Type, allowRef bool, hint string) *Schema
 		r.seen[t] = true
 	}
 	s := SchemaFromType(r, t)
+	if s != nil && len(r.hooks) > 0 {
+		for _, hook := range r.hooks {
+			hook(t, s)
+		}
+		s.syncProperties()
+	}
 	if getsRef {
 		r.schemas[name] = s
 	}
@@ -111,6 +144,10 @@ func (r *mapRegistry) Schema(t reflect.Type, allowRef bool, hint string) *Schema
 	return s
 }
 
+func (r *mapRegistry) OnSchema(f func(t reflect.Type, s *Schema)) {
+	r.hooks = append(r.hooks, f)
+}
+
 func (r *mapRegistry) SchemaFromRef(ref string) *Schema {
 	return r.schemas[ref[len(r.prefix):]]
 }
@@ -142,3 +179,35 @@ func NewMapRegistry(prefix string, namer func(t reflect.Type, hint string) strin
 		namer:   namer,
 	}
 }
+
+// NewInlineRegistry creates a new registry which never returns references,
+// so every schema is generated inline where it is used and the OpenAPI has
+// no `#/components/schemas`. This is useful for tools which do not support
+// `$ref`, but makes the OpenAPI larger. Recursive types cannot be inlined
+// and cause a panic.
+func NewInlineRegistry() Registry {
+	r := NewMapRegistry("", DefaultSchemaNamer).(*mapRegistry)
+	r.inline = true
+	r.inlining = map[reflect.Type]bool{}
+	return r
+}
+
+// namedSchema returns a schema referencing the existing schema with the given
+// name, e.g. for fields using the `schemaName` tag. It panics if there is no
+// such schema.
+func namedSchema(r Registry, name string) *Schema {
+	s := r.Map()[name]
+	if s == nil {
+		panic(fmt.Errorf("schema %q not found, register its type with the registry first", name))
+	}
+	return &Schema{Ref: registryPrefix(r) + name}
+}
+
+// registryPrefix returns the prefix used for references to schemas in the
+// registry.
+func registryPrefix(r Registry) string {
+	if m, ok := r.(*mapRegistry); ok && !m.inline {
+		return m.prefix
+	}
+	return "#/components/schemas/"
+}
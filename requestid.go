@@ -0,0 +1,99 @@
+package huma
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+)
+
+type requestIDKey struct{}
+
+// RequestID returns the request ID for the current request, or an empty
+// string if request IDs are not enabled. See `Config.RequestIDHeader`.
+//
+//	func handler(ctx context.Context, input *MyInput) (*MyOutput, error) {
+//		log.Printf("request %s: doing something", huma.RequestID(ctx))
+//		// ...
+//	}
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// RequestIDError is implemented by errors which include the request ID in
+// the response body. It is called on the error before it is written.
+type RequestIDError interface {
+	SetRequestID(id string)
+}
+
+// requestIDContext overrides the request context to include the request ID.
+type requestIDContext struct {
+	humaContext
+	ctx context.Context
+}
+
+func (c *requestIDContext) Context() context.Context {
+	return c.ctx
+}
+
+func (c *requestIDContext) Unwrap() Context {
+	return c.humaContext
+}
+
+// newRequestID returns a random 32 character hex string.
+func newRequestID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// validRequestID returns whether a client-provided request ID is safe to use
+// in headers and logs.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > 128 {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// requestIDs wraps a handler to read or generate a request ID when enabled in
+// the API's config. The ID is echoed in the response and added to the
+// request context.
+func requestIDs(api API, handler func(ctx Context)) func(ctx Context) {
+	config := configOf(api)
+	header := config.RequestIDHeader
+	if header == "" {
+		return handler
+	}
+
+	generate := config.NewRequestID
+	if generate == nil {
+		generate = newRequestID
+	}
+
+	return func(ctx Context) {
+		id := ctx.Header(header)
+		if !validRequestID(id) {
+			id = generate()
+		}
+		ctx.SetHeader(header, id)
+		handler(&requestIDContext{
+			humaContext: ctx,
+			ctx:         context.WithValue(ctx.Context(), requestIDKey{}, id),
+		})
+	}
+}
+
+// setErrorRequestID sets the request ID on errors which support it.
+func setErrorRequestID(ctx Context, err any) {
+	if re, ok := err.(RequestIDError); ok {
+		if id := RequestID(ctx.Context()); id != "" {
+			re.SetRequestID(id)
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package huma
+
+import (
+	"reflect"
+	"sync"
+)
+
+// ResolverOrder can be implemented by a resolver to run before or after the
+// other resolvers of the input. Resolvers run in ascending order, and the
+// default order is zero. Resolvers with the same order always run in the
+// same sequence: a struct before its fields, fields in declaration order,
+// slice items by index, and map values sorted by key.
+//
+//	// Authenticate before any body resolvers run.
+//	func (i *MyInput) ResolverOrder() int {
+//		return -1
+//	}
+type ResolverOrder interface {
+	ResolverOrder() int
+}
+
+var resolverOrderType = reflect.TypeOf((*ResolverOrder)(nil)).Elem()
+
+// stopResolvingError wraps a resolver error to skip the remaining resolvers.
+type stopResolvingError struct {
+	err error
+}
+
+func (e *stopResolvingError) Error() string {
+	return e.err.Error()
+}
+
+func (e *stopResolvingError) Unwrap() error {
+	return e.err
+}
+
+// StopResolving wraps an error returned from a resolver so that none of the
+// remaining resolvers run for the request, e.g. when authentication fails
+// and resolving the rest of the input would be wasted work. The wrapped error
+// is returned to the client like any other resolver error.
+//
+//	func (i *MyInput) Resolve(ctx huma.Context) []error {
+//		if ctx.Header("Authorization") == "" {
+//			return []error{huma.StopResolving(huma.Error401Unauthorized("login required"))}
+//		}
+//		return nil
+//	}
+func StopResolving(err error) error {
+	return &stopResolvingError{err: err}
+}
+
+// resolverInfo describes a resolver found within an input type.
+type resolverInfo struct {
+	// order is the value returned by `ResolverOrder`, if implemented.
+	order int
+}
+
+var dynamicResolversCache sync.Map
+
+// resolverRun tracks the resolvers run for a single request.
+type resolverRun struct {
+	ctx Context
+	pb  *PathBuffer
+	res *ValidateResult
+
+	// failed is set once any resolver returns errors.
+	failed bool
+
+	// stopped is set once a resolver returns an error from `StopResolving`.
+	stopped bool
+}
+
+// all runs the resolvers found within `v`. The path buffer is reset for
+// each resolver path unless `v` is nested within an already pushed path.
+func (r *resolverRun) all(resolvers *findResult[*resolverInfo], v reflect.Value, reset bool) {
+	for i := range resolvers.Paths {
+		if r.stopped {
+			return
+		}
+		if reset {
+			r.pb.Reset()
+		}
+		resolvers.everyPB(v, resolvers.Paths[i].Path, r.pb, resolvers.Paths[i].Value, r.run)
+	}
+}
+
+// run runs the resolver implemented by `item`. For interface values, the
+// resolvers found within the dynamic value are run instead.
+func (r *resolverRun) run(item reflect.Value, _ *resolverInfo) {
+	if r.stopped {
+		return
+	}
+
+	if item.Kind() == reflect.Interface {
+		if item.IsNil() {
+			return
+		}
+		dynamic := item.Elem()
+		if dynamic.Kind() == reflect.Pointer {
+			if dynamic.IsNil() {
+				return
+			}
+			r.dynamic(dynamic.Elem())
+			return
+		}
+		// The dynamic value is not addressable, so resolve a copy and store it
+		// back in case it was modified.
+		tmp := reflect.New(dynamic.Type()).Elem()
+		tmp.Set(dynamic)
+		r.dynamic(tmp)
+		if item.CanSet() {
+			item.Set(tmp)
+		}
+		return
+	}
+
+	var errs []error
+	if resolver, ok := item.Addr().Interface().(Resolver); ok {
+		errs = resolver.Resolve(r.ctx)
+	} else if resolver, ok := item.Addr().Interface().(ResolverWithPath); ok {
+		errs = resolver.Resolve(r.ctx, r.pb)
+	} else {
+		panic("matched resolver cannot be run, please file a bug")
+	}
+
+	for _, err := range errs {
+		if stop, ok := err.(*stopResolvingError); ok {
+			r.stopped = true
+			err = stop.err
+		}
+		r.res.Errors = append(r.res.Errors, err)
+		r.failed = true
+	}
+}
+
+// dynamic runs the resolvers within a value found behind an interface, whose
+// type is only known at runtime.
+func (r *resolverRun) dynamic(v reflect.Value) {
+	var resolvers *findResult[*resolverInfo]
+	if cached, ok := dynamicResolversCache.Load(v.Type()); ok {
+		resolvers = cached.(*findResult[*resolverInfo])
+	} else {
+		resolvers = findResolvers(resolverType, v.Type())
+		dynamicResolversCache.Store(v.Type(), resolvers)
+	}
+	r.all(resolvers, v, false)
+}
@@ -0,0 +1,372 @@
+// Package responsecache caches responses of Huma operations on the server,
+// so that repeated requests for the same resource skip the handler. Each
+// operation opts in and chooses which request headers and query params
+// select between cached responses.
+//
+//	responsecache.Use(api, responsecache.Config{})
+//
+//	huma.Register(api, huma.Operation{
+//		OperationID: "get-product",
+//		Method:      http.MethodGet,
+//		Path:        "/products/{id}",
+//		Metadata: map[string]any{
+//			responsecache.MetadataKey: &responsecache.Rule{
+//				TTL:     time.Minute,
+//				Headers: []string{"Accept-Language"},
+//			},
+//		},
+//	}, handler)
+package responsecache
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// MetadataKey is the operation metadata key used to opt an operation in to
+// response caching. The value is either a `*Rule`, or `true` to use the
+// defaults from the `Config`.
+const MetadataKey = "responseCache"
+
+// Header is set on cached operations' responses to `HIT` if the response came
+// from the cache, or `MISS` otherwise.
+const Header = "X-Cache"
+
+// Config configures response caching for an API.
+type Config struct {
+	// Store saves cached responses. Defaults to a new `MemoryStore`.
+	Store Store
+
+	// TTL is how long responses are cached for operations whose rule does not
+	// set one. Defaults to one minute.
+	TTL time.Duration
+}
+
+// Rule configures caching for a single operation.
+type Rule struct {
+	// TTL is how long responses are cached. Defaults to `Config.TTL`. A
+	// `s-maxage` or `max-age` directive in the response's `Cache-Control`
+	// header takes precedence, so it works well with `Operation.Cache`.
+	TTL time.Duration
+
+	// Headers are the request headers which select between cached responses,
+	// e.g. `Accept-Language` or `Authorization`. The `Accept` header is
+	// always included, as it selects the response format.
+	Headers []string
+
+	// Query are the query params which select between cached responses. Other
+	// query params are ignored. If nil, the whole query string is used.
+	Query []string
+}
+
+// now returns the current time and can be replaced for testing.
+var now = time.Now
+
+// rule returns the operation's rule, or nil if it has not opted in.
+func rule(op *huma.Operation) *Rule {
+	switch v := op.Metadata[MetadataKey].(type) {
+	case *Rule:
+		return v
+	case Rule:
+		return &v
+	case bool:
+		if v {
+			return &Rule{}
+		}
+	}
+	return nil
+}
+
+// headers returns the request headers which select between responses.
+func (r *Rule) headers() []string {
+	return append([]string{"Accept"}, r.Headers...)
+}
+
+// key returns the cache key for a request, derived from its method, path,
+// and the selected query params and headers.
+func (r *Rule) key(ctx huma.Context) string {
+	u := ctx.URL()
+	h := sha256.New()
+	h.Write([]byte(ctx.Method() + " " + u.EscapedPath() + "\n"))
+
+	query := u.Query()
+	if r.Query != nil {
+		selected := make(map[string][]string, len(r.Query))
+		for _, name := range r.Query {
+			if values, ok := query[name]; ok {
+				selected[name] = values
+			}
+		}
+		query = selected
+	}
+	// Encoding sorts the params, so the order in the request doesn't matter.
+	h.Write([]byte(query.Encode() + "\n"))
+
+	for _, name := range r.headers() {
+		h.Write([]byte(strings.ToLower(name) + ": " + ctx.Header(name) + "\n"))
+	}
+
+	return ctx.Operation().OperationID + ":" + hex.EncodeToString(h.Sum(nil))
+}
+
+// cacheControl parses a `Cache-Control` header into its directives and their
+// values, if any.
+func cacheControl(value string) map[string]string {
+	directives := map[string]string{}
+	for _, part := range strings.Split(value, ",") {
+		name, v, _ := strings.Cut(strings.TrimSpace(part), "=")
+		if name != "" {
+			directives[strings.ToLower(name)] = strings.Trim(v, `"`)
+		}
+	}
+	return directives
+}
+
+// recordingContext saves the response as it is written.
+type recordingContext struct {
+	huma.ContextWrapper
+	status   int
+	header   http.Header
+	buf      bytes.Buffer
+	writer   io.Writer
+	streamed bool
+}
+
+func (c *recordingContext) SetStatus(code int) {
+	c.status = code
+	c.ContextWrapper.SetStatus(code)
+}
+
+func (c *recordingContext) SetHeader(name, value string) {
+	c.header.Set(name, value)
+	c.ContextWrapper.SetHeader(name, value)
+}
+
+func (c *recordingContext) AppendHeader(name, value string) {
+	c.header.Add(name, value)
+	c.ContextWrapper.AppendHeader(name, value)
+}
+
+func (c *recordingContext) BodyWriter() io.Writer {
+	if c.writer == nil {
+		c.writer = io.MultiWriter(c.ContextWrapper.BodyWriter(), &c.buf)
+	}
+	return c.writer
+}
+
+// StreamBody passes streamed responses through without caching them, as they
+// may be arbitrarily large or long-lived.
+func (c *recordingContext) StreamBody(cb func(w io.Writer, flush func() error)) {
+	c.streamed = true
+	if sc, ok := c.Unwrap().(huma.StreamingContext); ok {
+		sc.StreamBody(cb)
+		return
+	}
+	w := c.ContextWrapper.BodyWriter()
+	cb(w, func() error {
+		if f, ok := w.(http.Flusher); ok {
+			f.Flush()
+			return nil
+		}
+		return http.ErrNotSupported
+	})
+}
+
+// ttl returns how long the recorded response may be cached for, or zero if
+// it must not be cached.
+func (c *recordingContext) ttl(ctx huma.Context, r *Rule, def time.Duration) time.Duration {
+	if c.streamed || c.status < 200 || c.status > 299 || c.status == http.StatusPartialContent {
+		return 0
+	}
+	if c.header.Get("Set-Cookie") != "" {
+		return 0
+	}
+
+	cc := cacheControl(c.header.Get("Cache-Control"))
+	for _, directive := range []string{"no-store", "no-cache", "private"} {
+		if _, ok := cc[directive]; ok {
+			return 0
+		}
+	}
+
+	// Responses for authenticated requests may only be shared between users
+	// if they are public, or the credentials are part of the cache key.
+	if ctx.Header("Authorization") != "" {
+		_, public := cc["public"]
+		if !public && !containsFold(r.Headers, "Authorization") {
+			return 0
+		}
+	}
+
+	// Responses which vary on headers outside of the cache key would be
+	// returned for the wrong requests.
+	for _, vary := range c.header.Values("Vary") {
+		for _, name := range strings.Split(vary, ",") {
+			if name = strings.TrimSpace(name); name != "" && !containsFold(r.headers(), name) {
+				return 0
+			}
+		}
+	}
+
+	for _, directive := range []string{"s-maxage", "max-age"} {
+		if v, ok := cc[directive]; ok {
+			seconds, err := strconv.Atoi(v)
+			if err != nil {
+				return 0
+			}
+			return time.Duration(seconds) * time.Second
+		}
+	}
+	if r.TTL > 0 {
+		return r.TTL
+	}
+	return def
+}
+
+func containsFold(values []string, v string) bool {
+	for _, value := range values {
+		if strings.EqualFold(value, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// Use adds response caching middleware to the API and documents the cache
+// headers on opted-in operations. Like other middleware, it must be called
+// before registering operations with `huma.Register` in order to apply to
+// them.
+//
+// Only `GET` and `HEAD` requests are cached. Responses are cached if they
+// have a `2xx` status other than `206 Partial Content`, unless:
+//
+//   - Their `Cache-Control` header includes `no-store`, `no-cache`, or
+//     `private`.
+//   - They set a cookie.
+//   - The request has an `Authorization` header which isn't part of the
+//     cache key, and the response isn't marked `public`.
+//   - They vary on request headers which aren't part of the cache key.
+//   - They are streamed.
+//
+// Requests with `Cache-Control: no-cache` skip the cache lookup but may
+// refresh the cached response, while `no-store` bypasses the cache entirely.
+// Cached responses are sent with an `Age` header, and all responses of
+// opted-in operations have an `X-Cache` header of `HIT` or `MISS`. Errors
+// from the store are treated as cache misses.
+func Use(api huma.API, config Config) {
+	if config.Store == nil {
+		config.Store = NewMemoryStore()
+	}
+	if config.TTL <= 0 {
+		config.TTL = time.Minute
+	}
+
+	oapi := api.OpenAPI()
+	oapi.OnAddOperation = append(oapi.OnAddOperation, document)
+
+	api.UseMiddleware(func(ctx huma.Context, next func(huma.Context)) {
+		r := rule(ctx.Operation())
+		if r == nil || (ctx.Method() != http.MethodGet && ctx.Method() != http.MethodHead) {
+			next(ctx)
+			return
+		}
+
+		cc := cacheControl(ctx.Header("Cache-Control"))
+		_, noStore := cc["no-store"]
+		_, noCache := cc["no-cache"]
+
+		key := r.key(ctx)
+		if !noStore && !noCache {
+			if entry, err := config.Store.Get(ctx.Context(), key); err == nil && entry != nil {
+				replay(ctx, entry)
+				return
+			}
+		}
+
+		ctx.SetHeader(Header, "MISS")
+		if noStore {
+			next(ctx)
+			return
+		}
+
+		rc := &recordingContext{
+			ContextWrapper: huma.WrapContext(ctx),
+			status:         http.StatusOK,
+			header:         http.Header{},
+		}
+		stored := now()
+		next(rc)
+
+		if ttl := rc.ttl(ctx, r, config.TTL); ttl > 0 {
+			config.Store.Set(ctx.Context(), key, &Entry{
+				Status: rc.status,
+				Header: rc.header,
+				Body:   rc.buf.Bytes(),
+				Stored: stored,
+			}, ttl)
+		}
+	})
+}
+
+// replay writes a cached response.
+func replay(ctx huma.Context, entry *Entry) {
+	names := make([]string, 0, len(entry.Header))
+	for name := range entry.Header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for i, value := range entry.Header[name] {
+			if i == 0 {
+				// Replace any value set by outer middleware for this request.
+				ctx.SetHeader(name, value)
+				continue
+			}
+			ctx.AppendHeader(name, value)
+		}
+	}
+	age := now().Sub(entry.Stored)
+	if age < 0 {
+		age = 0
+	}
+	ctx.SetHeader("Age", strconv.FormatInt(int64(age/time.Second), 10))
+	ctx.SetHeader(Header, "HIT")
+	ctx.SetStatus(entry.Status)
+	ctx.BodyWriter().Write(entry.Body)
+}
+
+// document adds the `Age` and `X-Cache` headers to the successful responses
+// of opted-in operations.
+func document(oapi *huma.OpenAPI, op *huma.Operation) {
+	if rule(op) == nil {
+		return
+	}
+
+	for code, resp := range op.Responses {
+		if resp.Ref != "" || !strings.HasPrefix(code, "2") {
+			continue
+		}
+		if resp.Headers == nil {
+			resp.Headers = map[string]*huma.Param{}
+		}
+		if resp.Headers["Age"] == nil {
+			resp.Headers["Age"] = &huma.Param{
+				Description: "Seconds since a cached response was generated.",
+				Schema:      &huma.Schema{Type: huma.TypeInteger},
+			}
+		}
+		resp.Headers[Header] = &huma.Param{
+			Description: "Whether the response came from the server's cache.",
+			Schema:      &huma.Schema{Type: huma.TypeString, Enum: []any{"HIT", "MISS"}},
+		}
+	}
+}
@@ -0,0 +1,205 @@
+package responsecache
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/humatest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type ProductOutput struct {
+	CacheControl string `header:"Cache-Control"`
+	Vary         string `header:"Vary"`
+	Body         struct {
+		Calls int32  `json:"calls"`
+		Query string `json:"query"`
+	}
+}
+
+func setup(t *testing.T, config Config, r any) (humatest.TestAPI, *int32) {
+	_, api := humatest.New(t)
+	Use(api, config)
+
+	var calls int32
+	huma.Register(api, huma.Operation{
+		OperationID: "get-product",
+		Method:      http.MethodGet,
+		Path:        "/products/{id}",
+		Metadata:    map[string]any{MetadataKey: r},
+	}, func(ctx context.Context, input *struct {
+		ID           string `path:"id"`
+		Page         int    `query:"page"`
+		Sort         string `query:"sort"`
+		CacheControl string `query:"cc"`
+		Vary         string `query:"vary"`
+		Fail         bool   `query:"fail"`
+	}) (*ProductOutput, error) {
+		n := atomic.AddInt32(&calls, 1)
+		if input.Fail {
+			return nil, huma.Error404NotFound("not found")
+		}
+		resp := &ProductOutput{CacheControl: input.CacheControl, Vary: input.Vary}
+		resp.Body.Calls = n
+		resp.Body.Query = input.Sort
+		return resp, nil
+	})
+
+	return api, &calls
+}
+
+func TestCache(t *testing.T) {
+	api, calls := setup(t, Config{}, true)
+
+	resp := api.Get("/products/1")
+	require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+	assert.Equal(t, "MISS", resp.Header().Get(Header))
+	assert.Empty(t, resp.Header().Get("Age"))
+
+	resp = api.Get("/products/1")
+	require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+	assert.Equal(t, "HIT", resp.Header().Get(Header))
+	assert.Equal(t, "0", resp.Header().Get("Age"))
+	assert.Contains(t, resp.Header().Get("Content-Type"), "json")
+	assert.JSONEq(t, `{"calls": 1, "query": ""}`, resp.Body.String())
+
+	// Different paths and query strings are cached separately, but the order
+	// of query params doesn't matter.
+	api.Get("/products/2")
+	api.Get("/products/1?page=1&sort=asc")
+	resp = api.Get("/products/1?sort=asc&page=1")
+	assert.Equal(t, "HIT", resp.Header().Get(Header))
+	assert.EqualValues(t, 3, atomic.LoadInt32(calls))
+
+	// Errors are not cached.
+	api.Get("/products/1?fail=true")
+	api.Get("/products/1?fail=true")
+	assert.EqualValues(t, 5, atomic.LoadInt32(calls))
+
+	// Requests can skip the cache.
+	resp = api.Get("/products/1", "Cache-Control: no-cache")
+	assert.Equal(t, "MISS", resp.Header().Get(Header))
+	assert.JSONEq(t, `{"calls": 6, "query": ""}`, resp.Body.String())
+	resp = api.Get("/products/1")
+	assert.JSONEq(t, `{"calls": 6, "query": ""}`, resp.Body.String())
+
+	// The response format is part of the key.
+	resp = api.Get("/products/1", "Accept: application/cbor")
+	assert.Equal(t, "MISS", resp.Header().Get(Header))
+
+	// The headers are documented.
+	headers := api.OpenAPI().Paths["/products/{id}"].Get.Responses["200"].Headers
+	assert.Contains(t, headers, "Age")
+	assert.Contains(t, headers, Header)
+}
+
+func TestCacheAge(t *testing.T) {
+	api, _ := setup(t, Config{}, true)
+
+	start := time.Now()
+	defer func() { now = time.Now }()
+	now = func() time.Time { return start }
+
+	api.Get("/products/1")
+	now = func() time.Time { return start.Add(5 * time.Second) }
+	resp := api.Get("/products/1")
+	assert.Equal(t, "HIT", resp.Header().Get(Header))
+	assert.Equal(t, "5", resp.Header().Get("Age"))
+}
+
+func TestCacheRule(t *testing.T) {
+	store := NewMemoryStore()
+	api, calls := setup(t, Config{Store: store}, &Rule{
+		TTL:     time.Hour,
+		Headers: []string{"Accept-Language"},
+		Query:   []string{"sort"},
+	})
+
+	api.Get("/products/1?sort=asc&page=1")
+	resp := api.Get("/products/1?page=2&sort=asc")
+	assert.Equal(t, "HIT", resp.Header().Get(Header))
+
+	resp = api.Get("/products/1?sort=asc", "Accept-Language: de")
+	assert.Equal(t, "MISS", resp.Header().Get(Header))
+	assert.EqualValues(t, 2, atomic.LoadInt32(calls))
+
+	// Entries expire after the rule's TTL.
+	store.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+	resp = api.Get("/products/1?sort=asc")
+	assert.Equal(t, "MISS", resp.Header().Get(Header))
+}
+
+func TestCacheControl(t *testing.T) {
+	store := NewMemoryStore()
+	api, calls := setup(t, Config{Store: store}, true)
+
+	for _, item := range []struct {
+		name    string
+		url     string
+		headers []any
+		cached  bool
+	}{
+		{"no-store", "/products/1?cc=no-store", nil, false},
+		{"private", "/products/1?cc=private,max-age=60", nil, false},
+		{"vary-unknown", "/products/1?vary=Accept-Language", nil, false},
+		{"vary-accept", "/products/1?vary=Accept", nil, true},
+		{"authorized", "/products/1?cc=max-age=60", []any{"Authorization: Bearer abc"}, false},
+		{"authorized-public", "/products/1?cc=public,max-age=60", []any{"Authorization: Bearer abc"}, true},
+		{"request-no-store", "/products/2", []any{"Cache-Control: no-store"}, false},
+	} {
+		t.Run(item.name, func(t *testing.T) {
+			before := atomic.LoadInt32(calls)
+			api.Get(item.url, item.headers...)
+			resp := api.Get(item.url, item.headers...)
+			if item.cached {
+				assert.Equal(t, "HIT", resp.Header().Get(Header))
+				assert.Equal(t, before+1, atomic.LoadInt32(calls))
+			} else {
+				assert.Equal(t, "MISS", resp.Header().Get(Header))
+				assert.Equal(t, before+2, atomic.LoadInt32(calls))
+			}
+		})
+	}
+
+	// The response's max-age overrides the default TTL.
+	api.Get("/products/3?cc=max-age=10")
+	store.now = func() time.Time { return time.Now().Add(30 * time.Second) }
+	resp := api.Get("/products/3?cc=max-age=10")
+	assert.Equal(t, "MISS", resp.Header().Get(Header))
+}
+
+type failingStore struct{}
+
+func (failingStore) Get(ctx context.Context, key string) (*Entry, error) {
+	return nil, errors.New("unavailable")
+}
+
+func (failingStore) Set(ctx context.Context, key string, entry *Entry, ttl time.Duration) error {
+	return errors.New("unavailable")
+}
+
+func TestCacheStoreError(t *testing.T) {
+	api, calls := setup(t, Config{Store: failingStore{}}, true)
+
+	api.Get("/products/1")
+	resp := api.Get("/products/1")
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, "MISS", resp.Header().Get(Header))
+	assert.EqualValues(t, 2, atomic.LoadInt32(calls))
+}
+
+func TestCacheNotOptedIn(t *testing.T) {
+	api, calls := setup(t, Config{}, false)
+
+	api.Get("/products/1")
+	resp := api.Get("/products/1")
+	assert.Empty(t, resp.Header().Get(Header))
+	assert.EqualValues(t, 2, atomic.LoadInt32(calls))
+	assert.NotContains(t, api.OpenAPI().Paths["/products/{id}"].Get.Responses["200"].Headers, Header)
+}
@@ -0,0 +1,88 @@
+package responsecache
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Entry is a cached response.
+type Entry struct {
+	Status int
+	Header http.Header
+	Body   []byte
+
+	// Stored is when the response was generated, used for the `Age` header.
+	Stored time.Time
+}
+
+// Store saves cached responses. Implementations must be safe for concurrent
+// use. A shared store such as Redis can be used to share cached responses
+// across multiple instances of a service.
+type Store interface {
+	// Get returns the entry for the key, or nil if there is none or it has
+	// expired.
+	Get(ctx context.Context, key string) (*Entry, error)
+
+	// Set saves the entry for the key until the TTL expires.
+	Set(ctx context.Context, key string, entry *Entry, ttl time.Duration) error
+}
+
+type memoryEntry struct {
+	entry   *Entry
+	expires time.Time
+}
+
+// MemoryStore is an in-process `Store`. Expired entries are periodically
+// removed.
+type MemoryStore struct {
+	mu        sync.Mutex
+	entries   map[string]*memoryEntry
+	lastSweep time.Time
+
+	// now returns the current time and can be replaced for testing.
+	now func() time.Time
+}
+
+// NewMemoryStore creates a new in-process store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		entries: map[string]*memoryEntry{},
+		now:     time.Now,
+	}
+}
+
+// sweepInterval is how often expired entries are removed from a
+// `MemoryStore`.
+const sweepInterval = time.Minute
+
+// Get implements the `Store` interface.
+func (s *MemoryStore) Get(ctx context.Context, key string) (*Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if e := s.entries[key]; e != nil && s.now().Before(e.expires) {
+		return e.entry, nil
+	}
+	return nil, nil
+}
+
+// Set implements the `Store` interface.
+func (s *MemoryStore) Set(ctx context.Context, key string, entry *Entry, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if now.Sub(s.lastSweep) > sweepInterval {
+		for k, e := range s.entries {
+			if !now.Before(e.expires) {
+				delete(s.entries, k)
+			}
+		}
+		s.lastSweep = now
+	}
+
+	s.entries[key] = &memoryEntry{entry: entry, expires: now.Add(ttl)}
+	return nil
+}
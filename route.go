@@ -0,0 +1,96 @@
+package huma
+
+import (
+	"context"
+	"sync"
+)
+
+type routeKey struct{}
+
+// Route describes the operation route matched for a request. Unlike the
+// request path, its template makes a low-cardinality label for metrics and
+// tracing.
+type Route struct {
+	// Method is the operation's HTTP method, e.g. `GET`.
+	Method string
+
+	// Template is the operation's path template, e.g. `/items/{id}`.
+	Template string
+
+	// OperationID is the operation's ID, if it has one.
+	OperationID string
+
+	// Params are the values of the path parameters by name.
+	Params map[string]string
+}
+
+// RouteFromContext returns the route matched for the current request, and
+// whether there is one. It is available to handlers, resolvers, and API
+// middleware via `ctx.Context()`, and is computed once per request on first
+// use.
+//
+//	api.UseMiddleware(func(ctx huma.Context, next func(huma.Context)) {
+//		start := time.Now()
+//		next(ctx)
+//		route, _ := huma.RouteFromContext(ctx.Context())
+//		requestDuration.WithLabelValues(route.Method, route.Template).Observe(time.Since(start).Seconds())
+//	})
+func RouteFromContext(ctx context.Context) (Route, bool) {
+	if r, ok := ctx.Value(routeKey{}).(*routeResolver); ok {
+		return r.get(), true
+	}
+	return Route{}, false
+}
+
+// routeResolver lazily resolves the route for a request.
+type routeResolver struct {
+	ctx   Context
+	names []string
+	once  sync.Once
+	route Route
+}
+
+func (r *routeResolver) get() Route {
+	r.once.Do(func() {
+		op := r.ctx.Operation()
+		r.route = Route{
+			Method:      op.Method,
+			Template:    op.Path,
+			OperationID: op.OperationID,
+		}
+		if len(r.names) > 0 {
+			r.route.Params = make(map[string]string, len(r.names))
+			for _, name := range r.names {
+				r.route.Params[name] = r.ctx.Param(name)
+			}
+		}
+	})
+	return r.route
+}
+
+// routeContext overrides the request context to include the route.
+type routeContext struct {
+	humaContext
+	ctx context.Context
+}
+
+func (c *routeContext) Context() context.Context {
+	return c.ctx
+}
+
+func (c *routeContext) Unwrap() Context {
+	return c.humaContext
+}
+
+// routes wraps a handler to make the operation's route available via
+// `RouteFromContext`.
+func routes(op *Operation, handler func(ctx Context)) func(ctx Context) {
+	names := pathTemplateParams(op.Path)
+	return func(ctx Context) {
+		r := &routeResolver{ctx: ctx, names: names}
+		handler(&routeContext{
+			humaContext: ctx,
+			ctx:         context.WithValue(ctx.Context(), routeKey{}, r),
+		})
+	}
+}
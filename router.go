@@ -0,0 +1,291 @@
+package huma
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2/queryparam"
+)
+
+// routeNode is a node in the router's tree of path segments. Static segments
+// take priority over parameters, which take priority over catch-alls.
+type routeNode struct {
+	static   map[string]*routeNode
+	param    *routeNode
+	wildcard map[string]*route
+	routes   map[string]*route
+}
+
+// route is a handler registered for a method, along with the names of its
+// path parameters in the order they appear in the path.
+type route struct {
+	op      *Operation
+	handler func(Context)
+	params  []string
+}
+
+// Router is a minimal, dependency-free router which can be used as the
+// API's adapter when you don't need the features of a third-party router.
+// Paths may contain `{param}` segments and end with a `{param...}` catch-all
+// segment. Unmatched paths get a `404 Not Found` response, and unmatched
+// methods get a `405 Method Not Allowed` response listing the allowed
+// methods.
+//
+// Parameters must be whole path segments, so `/files/{name}.{ext}` is not
+// supported. Use `New` to create an API using a new router.
+type Router struct {
+	// MultipartMaxMemory is the maximum memory to use when parsing multipart
+	// form data.
+	MultipartMaxMemory int64
+
+	root routeNode
+}
+
+// defaultMultipartMaxMemory is the default maximum memory to use when parsing
+// multipart form data.
+const defaultMultipartMaxMemory = 8 * 1024
+
+// NewRouter creates a new, empty router.
+func NewRouter() *Router {
+	return &Router{MultipartMaxMemory: defaultMultipartMaxMemory}
+}
+
+// Handle registers the handler for the operation's method and path. It
+// panics if the path is invalid or already registered for the method.
+func (r *Router) Handle(op *Operation, handler func(Context)) {
+	prefix, wildcard, isWildcard := PathWildcard(op.Path)
+	if !strings.HasPrefix(prefix, "/") {
+		panic(fmt.Sprintf("path must begin with a slash: %s", op.Path))
+	}
+	method := strings.ToUpper(op.Method)
+
+	rt := &route{op: op, handler: handler}
+	n := &r.root
+	segments := strings.Split(prefix[1:], "/")
+	if isWildcard {
+		// The prefix ends in a slash, leaving an empty last segment.
+		segments = segments[:len(segments)-1]
+	}
+	for _, segment := range segments {
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") && !strings.ContainsAny(segment[1:len(segment)-1], "{}") {
+			rt.params = append(rt.params, segment[1:len(segment)-1])
+			if n.param == nil {
+				n.param = &routeNode{}
+			}
+			n = n.param
+			continue
+		}
+		if strings.ContainsAny(segment, "{}") {
+			panic(fmt.Sprintf("path parameters must be whole path segments: %s", op.Path))
+		}
+		if n.static == nil {
+			n.static = map[string]*routeNode{}
+		}
+		if n.static[segment] == nil {
+			n.static[segment] = &routeNode{}
+		}
+		n = n.static[segment]
+	}
+
+	routes := &n.routes
+	if isWildcard {
+		rt.params = append(rt.params, wildcard)
+		routes = &n.wildcard
+	}
+	if *routes == nil {
+		*routes = map[string]*route{}
+	}
+	if (*routes)[method] != nil {
+		panic(fmt.Sprintf("route already registered: %s %s", method, op.Path))
+	}
+	(*routes)[method] = rt
+}
+
+// match finds the routes for the given path segments, appending the values
+// of any path parameters to `values`.
+func (n *routeNode) match(segments []string, values []string) (map[string]*route, []string) {
+	if len(segments) == 0 {
+		if n.routes != nil {
+			return n.routes, values
+		}
+	} else {
+		if child := n.static[segments[0]]; child != nil {
+			if routes, v := child.match(segments[1:], values); routes != nil {
+				return routes, v
+			}
+		}
+		if n.param != nil && segments[0] != "" {
+			if routes, v := n.param.match(segments[1:], append(values, segments[0])); routes != nil {
+				return routes, v
+			}
+		}
+	}
+	if n.wildcard != nil && len(segments) > 0 {
+		return n.wildcard, append(values, strings.Join(segments, "/"))
+	}
+	return nil, values
+}
+
+// splitPath splits a request path into its segments.
+func splitPath(path string) []string {
+	return strings.Split(strings.TrimPrefix(path, "/"), "/")
+}
+
+// ServeHTTP dispatches the request to the handler registered for its method
+// and path.
+func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	ctx := routerContextPool.Get().(*routerContext)
+	defer func() {
+		// Reset the context even if the handler panics, so it never keeps a
+		// request alive or leaks it to the next one.
+		*ctx = routerContext{values: ctx.values[:0]}
+		routerContextPool.Put(ctx)
+	}()
+
+	routes, values := r.root.match(splitPath(req.URL.Path), ctx.values[:0])
+	if routes == nil {
+		http.NotFound(w, req)
+		return
+	}
+
+	rt := routes[req.Method]
+	if rt == nil {
+		allowed := make([]string, 0, len(routes))
+		for method := range routes {
+			allowed = append(allowed, method)
+		}
+		sort.Strings(allowed)
+		w.Header().Set("Allow", strings.Join(allowed, ", "))
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	ctx.op, ctx.r, ctx.w, ctx.maxMemory, ctx.names, ctx.values = rt.op, req, w, r.MultipartMaxMemory, rt.params, values
+	rt.handler(ctx)
+}
+
+// New creates a new API using the built-in router. The router is available
+// via `api.Adapter()`, which implements `http.Handler`.
+//
+//	api := huma.New(huma.DefaultConfig("My API", "1.0.0"))
+//	// ... register operations ...
+//	http.ListenAndServe(":8888", api.Adapter())
+func New(config Config) API {
+	return NewAPI(config, NewRouter())
+}
+
+type routerContext struct {
+	op     *Operation
+	r      *http.Request
+	w      http.ResponseWriter
+	names  []string
+	values []string
+
+	// maxMemory is the maximum memory to use when parsing multipart forms.
+	maxMemory int64
+}
+
+// routerContextPool reuses contexts across requests to reduce allocations.
+var routerContextPool = sync.Pool{
+	New: func() any {
+		return &routerContext{}
+	},
+}
+
+func (c *routerContext) Operation() *Operation {
+	return c.op
+}
+
+func (c *routerContext) Context() context.Context {
+	return c.r.Context()
+}
+
+func (c *routerContext) Method() string {
+	return c.r.Method
+}
+
+func (c *routerContext) Host() string {
+	return c.r.Host
+}
+
+func (c *routerContext) RemoteAddr() string {
+	return c.r.RemoteAddr
+}
+
+func (c *routerContext) URL() url.URL {
+	return *c.r.URL
+}
+
+func (c *routerContext) Param(name string) string {
+	for i, n := range c.names {
+		if n == name {
+			return c.values[i]
+		}
+	}
+	return ""
+}
+
+func (c *routerContext) Query(name string) string {
+	return queryparam.Get(c.r.URL.RawQuery, name)
+}
+
+func (c *routerContext) GetMultiQuery(name string) []string {
+	return queryparam.GetAll(c.r.URL.RawQuery, name)
+}
+
+func (c *routerContext) Header(name string) string {
+	return c.r.Header.Get(name)
+}
+
+func (c *routerContext) EachHeader(cb func(name, value string)) {
+	for name, values := range c.r.Header {
+		for _, value := range values {
+			cb(name, value)
+		}
+	}
+}
+
+func (c *routerContext) BodyReader() io.Reader {
+	return c.r.Body
+}
+
+func (c *routerContext) GetMultipartForm() (*multipart.Form, error) {
+	err := c.r.ParseMultipartForm(c.maxMemory)
+	return c.r.MultipartForm, err
+}
+
+func (c *routerContext) SetReadDeadline(deadline time.Time) error {
+	return SetReadDeadline(c.w, deadline)
+}
+
+func (c *routerContext) EnableFullDuplex() error {
+	return EnableFullDuplex(c.w)
+}
+
+func (c *routerContext) WriteEarlyHints(links []string) error {
+	return WriteEarlyHints(c.w, links)
+}
+
+func (c *routerContext) SetStatus(code int) {
+	c.w.WriteHeader(code)
+}
+
+func (c *routerContext) AppendHeader(name string, value string) {
+	c.w.Header().Add(name, value)
+}
+
+func (c *routerContext) SetHeader(name string, value string) {
+	c.w.Header().Set(name, value)
+}
+
+func (c *routerContext) BodyWriter() io.Writer {
+	return c.w
+}
@@ -0,0 +1,106 @@
+package huma_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRouter(t *testing.T) {
+	router := huma.NewRouter()
+
+	handle := func(method, path string, params ...string) {
+		router.Handle(&huma.Operation{Method: method, Path: path}, func(ctx huma.Context) {
+			ctx.BodyWriter().Write([]byte(method + " " + path))
+			for _, name := range params {
+				ctx.BodyWriter().Write([]byte(" " + name + "=" + ctx.Param(name)))
+			}
+		})
+	}
+
+	handle(http.MethodGet, "/")
+	handle(http.MethodGet, "/users")
+	handle(http.MethodGet, "/users/me")
+	handle(http.MethodGet, "/users/{id}", "id")
+	handle(http.MethodDelete, "/users/{userID}", "userID")
+	handle(http.MethodGet, "/users/{id}/posts/{post}", "id", "post")
+	handle(http.MethodGet, "/static/{file...}", "file")
+	handle(http.MethodGet, "/static/robots.txt")
+
+	for _, item := range []struct {
+		method string
+		url    string
+		status int
+		body   string
+	}{
+		{http.MethodGet, "/", http.StatusOK, "GET /"},
+		{http.MethodGet, "/users", http.StatusOK, "GET /users"},
+		{http.MethodGet, "/users/me", http.StatusOK, "GET /users/me"},
+		{http.MethodGet, "/users/123", http.StatusOK, "GET /users/{id} id=123"},
+		{http.MethodDelete, "/users/123", http.StatusOK, "DELETE /users/{userID} userID=123"},
+		{http.MethodGet, "/users/123/posts/456", http.StatusOK, "GET /users/{id}/posts/{post} id=123 post=456"},
+		{http.MethodGet, "/static/css/site.css", http.StatusOK, "GET /static/{file...} file=css/site.css"},
+		{http.MethodGet, "/static/", http.StatusOK, "GET /static/{file...} file="},
+		{http.MethodGet, "/static/robots.txt", http.StatusOK, "GET /static/robots.txt"},
+		{http.MethodGet, "/users/123/posts", http.StatusNotFound, ""},
+		{http.MethodGet, "/users/", http.StatusNotFound, ""},
+		{http.MethodGet, "/static", http.StatusNotFound, ""},
+		{http.MethodPost, "/users/123", http.StatusMethodNotAllowed, ""},
+	} {
+		t.Run(item.method+" "+item.url, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(item.method, item.url, nil))
+			assert.Equal(t, item.status, w.Code, w.Body.String())
+			if item.status == http.StatusOK {
+				assert.Equal(t, item.body, w.Body.String())
+			}
+			if item.status == http.StatusMethodNotAllowed {
+				assert.Equal(t, "DELETE, GET", w.Header().Get("Allow"))
+			}
+		})
+	}
+
+	assert.Panics(t, func() {
+		handle(http.MethodGet, "/users/{other}")
+	})
+
+	// Multiple parameters in one segment are rejected rather than being
+	// treated as a single parameter named `name}.{ext`.
+	for _, path := range []string{"/files/{name}.{ext}", "/files/{name}{ext}"} {
+		assert.PanicsWithValue(t, "path parameters must be whole path segments: "+path, func() {
+			handle(http.MethodGet, path)
+		})
+	}
+}
+
+func TestNewWithRouter(t *testing.T) {
+	api := huma.New(huma.DefaultConfig("Test API", "1.0.0"))
+
+	huma.Register(api, huma.Operation{
+		OperationID: "greet",
+		Method:      http.MethodGet,
+		Path:        "/greeting/{name}",
+	}, func(ctx context.Context, input *struct {
+		Name string `path:"name" maxLength:"10"`
+	}) (*struct{ Body string }, error) {
+		return &struct{ Body string }{Body: "Hello, " + input.Name}, nil
+	})
+
+	w := httptest.NewRecorder()
+	api.Adapter().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/greeting/world", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `"Hello, world"`, w.Body.String())
+
+	w = httptest.NewRecorder()
+	api.Adapter().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/greeting/much-too-long", nil))
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+
+	w = httptest.NewRecorder()
+	api.Adapter().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "/greeting/{name}")
+}
@@ -6,9 +6,11 @@ import (
 	"fmt"
 	"math/bits"
 	"net"
+	"net/netip"
 	"net/url"
 	"reflect"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -28,9 +30,13 @@ const (
 )
 
 var (
-	timeType = reflect.TypeOf(time.Time{})
-	ipType   = reflect.TypeOf(net.IP{})
-	urlType  = reflect.TypeOf(url.URL{})
+	timeType   = reflect.TypeOf(time.Time{})
+	ipType     = reflect.TypeOf(net.IP{})
+	urlType    = reflect.TypeOf(url.URL{})
+	addrType   = reflect.TypeOf(netip.Addr{})
+	prefixType = reflect.TypeOf(netip.Prefix{})
+
+	rawMessageType = reflect.TypeOf(json.RawMessage{})
 )
 
 func deref(t reflect.Type) reflect.Type {
@@ -153,6 +159,33 @@ func (s *Schema) MarshalJSON() ([]byte, error) {
 	}, s.Extensions)
 }
 
+// syncProperties updates the cached property names and required lookup after
+// the schema's `Properties` or `Required` may have been changed, e.g. by a
+// schema hook. Existing properties keep their order and new ones are added
+// sorted by name.
+func (s *Schema) syncProperties() {
+	if s.propertyNames != nil {
+		names := make([]string, 0, len(s.Properties))
+		known := map[string]bool{}
+		for _, name := range s.propertyNames {
+			if _, ok := s.Properties[name]; ok {
+				names = append(names, name)
+				known[name] = true
+			}
+		}
+		added := []string{}
+		for name := range s.Properties {
+			if !known[name] {
+				added = append(added, name)
+			}
+		}
+		sort.Strings(added)
+		s.propertyNames = append(names, added...)
+	}
+	s.requiredMap = nil
+	s.PrecomputeMessages()
+}
+
 // PrecomputeMessages tries to precompute as many validation error messages
 // as possible so that new strings aren't allocated during request validation.
 func (s *Schema) PrecomputeMessages() {
@@ -351,11 +384,19 @@ func jsonTag(f reflect.StructField, name string) any {
 // This is used by `huma.SchemaFromType` when it encounters a struct, and
 // is used to generate schemas for path/query/header parameters.
 func SchemaFromField(registry Registry, f reflect.StructField, hint string) *Schema {
-	fs := registry.Schema(f.Type, true, hint)
+	var fs *Schema
+	if name := f.Tag.Get("schemaName"); name != "" {
+		fs = namedSchema(registry, name)
+	} else {
+		fs = registry.Schema(f.Type, true, hint)
+	}
 	if fs == nil {
 		return fs
 	}
-	fs.Description = f.Tag.Get("doc")
+	if doc := f.Tag.Get("doc"); doc != "" {
+		// Keep any description from the type, e.g. via `SchemaProvider`.
+		fs.Description = doc
+	}
 	if fs.Format == "date-time" && f.Tag.Get("header") != "" {
 		// Special case: this is a header and uses a different date/time format.
 		// Note that it can still be overridden by the `format` or `timeFormat`
@@ -399,6 +440,14 @@ func SchemaFromField(registry Registry, f reflect.StructField, hint string) *Sch
 			fs.Enum = enumValues
 		}
 	}
+	if f.Tag.Get("enumNames") != "" || f.Tag.Get("enumDescriptions") != "" {
+		enumSchema := fs
+		if fs.Type == TypeArray && fs.Items != nil {
+			enumSchema = fs.Items
+		}
+		setEnumExtension(enumSchema, "x-enum-varnames", enumTag(f, "enumNames", len(enumSchema.Enum)))
+		setEnumExtension(enumSchema, "x-enum-descriptions", enumTag(f, "enumDescriptions", len(enumSchema.Enum)))
+	}
 	fs.Minimum = floatTag(f, "minimum")
 	fs.ExclusiveMinimum = floatTag(f, "exclusiveMinimum")
 	fs.Maximum = floatTag(f, "maximum")
@@ -406,7 +455,9 @@ func SchemaFromField(registry Registry, f reflect.StructField, hint string) *Sch
 	fs.MultipleOf = floatTag(f, "multipleOf")
 	fs.MinLength = intTag(f, "minLength")
 	fs.MaxLength = intTag(f, "maxLength")
-	fs.Pattern = f.Tag.Get("pattern")
+	if pattern := f.Tag.Get("pattern"); pattern != "" {
+		fs.Pattern = pattern
+	}
 	fs.MinItems = intTag(f, "minItems")
 	fs.MaxItems = intTag(f, "maxItems")
 	fs.UniqueItems = boolTag(f, "uniqueItems")
@@ -415,6 +466,12 @@ func SchemaFromField(registry Registry, f reflect.StructField, hint string) *Sch
 	fs.ReadOnly = boolTag(f, "readOnly")
 	fs.WriteOnly = boolTag(f, "writeOnly")
 	fs.Deprecated = boolTag(f, "deprecated")
+	if boolTag(f, "sensitive") {
+		if fs.Extensions == nil {
+			fs.Extensions = map[string]any{}
+		}
+		fs.Extensions["x-sensitive"] = true
+	}
 	fs.PrecomputeMessages()
 
 	return fs
@@ -468,6 +525,13 @@ type SchemaProvider interface {
 	Schema(r Registry) *Schema
 }
 
+// SchemaTransformer is an interface that can be implemented by types to
+// modify their generated schema, e.g. to allow additional properties which
+// are serialized by a custom marshaler.
+type SchemaTransformer interface {
+	TransformSchema(r Registry, s *Schema) *Schema
+}
+
 // SchemaFromType returns a schema for a given type, using the registry to
 // possibly create references for nested structs. The schema that is returned
 // can then be passed to `huma.Validate` to efficiently validate incoming
@@ -491,6 +555,17 @@ func SchemaFromType(r Registry, t reflect.Type) *Schema {
 		return &Schema{Type: TypeString, Format: "ipv4"}
 	}
 
+	if t == rawMessageType {
+		// Special case: raw JSON may be any value. Use the `schemaName` field
+		// tag to document its structure.
+		return &Schema{}
+	}
+
+	if isDecimalType(t) {
+		// Special case: arbitrary-precision number sent as a string.
+		return &Schema{Type: TypeString, Format: DecimalFormat}
+	}
+
 	minZero := 0.0
 	switch t.Kind() {
 	case reflect.Bool:
@@ -559,6 +634,12 @@ func SchemaFromType(r Registry, t reflect.Type) *Schema {
 			return &Schema{Type: TypeString, Format: "date-time"}
 		case urlType:
 			return &Schema{Type: TypeString, Format: "uri"}
+		case addrType:
+			// Either address family. Use `format:"ipv4"` or `format:"ipv6"` on
+			// the field to restrict it.
+			return &Schema{Type: TypeString, Format: "ip"}
+		case prefixType:
+			return &Schema{Type: TypeString, Format: "cidr"}
 		}
 
 		required := []string{}
@@ -609,5 +690,14 @@ func SchemaFromType(r Registry, t reflect.Type) *Schema {
 		return nil
 	}
 
+	if ev, ok := v.(EnumValuer); ok {
+		setEnumValues(&s, ev.EnumValues())
+		s.PrecomputeMessages()
+	}
+
+	if st, ok := v.(SchemaTransformer); ok {
+		return st.TransformSchema(r, &s)
+	}
+
 	return &s
 }
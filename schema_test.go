@@ -2,9 +2,12 @@ package huma_test
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"math/bits"
 	"net"
+	"net/http"
+	"net/netip"
 	"net/url"
 	"reflect"
 	"strconv"
@@ -12,10 +15,20 @@ import (
 	"time"
 
 	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/humatest"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
+type EnumStatus string
+
+func (EnumStatus) EnumValues() []huma.EnumValue {
+	return []huma.EnumValue{
+		{Value: "active", Name: "StatusActive", Description: "In use"},
+		{Value: "archived", Name: "StatusArchived"},
+	}
+}
+
 type EmbeddedChild struct {
 	// This one should be ignored as it is overridden by `Embedded`.
 	Value string `json:"value" doc:"old doc"`
@@ -100,6 +113,16 @@ func TestSchema(t *testing.T) {
 			input:    net.IPv4(127, 0, 0, 1),
 			expected: `{"type": "string", "format": "ipv4"}`,
 		},
+		{
+			name:     "netip-addr",
+			input:    netip.MustParseAddr("127.0.0.1"),
+			expected: `{"type": "string", "format": "ip"}`,
+		},
+		{
+			name:     "netip-prefix",
+			input:    netip.MustParsePrefix("10.0.0.0/8"),
+			expected: `{"type": "string", "format": "cidr"}`,
+		},
 		{
 			name:     "bytes",
 			input:    []byte("test"),
@@ -242,6 +265,64 @@ func TestSchema(t *testing.T) {
 				"additionalProperties": false
 			}`,
 		},
+		{
+			name: "field-enum-names",
+			input: struct {
+				Value string `json:"value" enum:"one,two" enumNames:"One,Two" enumDescriptions:"[\"First, and best\", \"Second\"]"`
+			}{},
+			expected: `{
+				"type": "object",
+				"properties": {
+					"value": {
+						"type": "string",
+						"enum": ["one", "two"],
+						"x-enum-varnames": ["One", "Two"],
+						"x-enum-descriptions": ["First, and best", "Second"]
+					}
+				},
+				"required": ["value"],
+				"additionalProperties": false
+			}`,
+		},
+		{
+			name: "field-array-enum-descriptions",
+			input: struct {
+				Value []int `json:"value" enum:"1,2" enumDescriptions:"Low,High"`
+			}{},
+			expected: `{
+				"type": "object",
+				"properties": {
+					"value": {
+						"type": "array",
+						"items": {
+							"type": "integer",
+							"format": "int64",
+							"enum": [1, 2],
+							"x-enum-descriptions": ["Low", "High"]
+						}
+					}
+				},
+				"required": ["value"],
+				"additionalProperties": false
+			}`,
+		},
+		{
+			name: "field-enum-names-mismatch",
+			input: struct {
+				Value string `json:"value" enum:"one,two" enumNames:"One"`
+			}{},
+			panics: "enumNames tag for field 'Value' has 1 values but the enum has 2: schema is invalid",
+		},
+		{
+			name:  "enum-valuer",
+			input: EnumStatus(""),
+			expected: `{
+				"type": "string",
+				"enum": ["active", "archived"],
+				"x-enum-varnames": ["StatusActive", "StatusArchived"],
+				"x-enum-descriptions": ["In use", ""]
+			}`,
+		},
 		{
 			name: "field-readonly",
 			input: struct {
@@ -662,3 +743,140 @@ func BenchmarkSchemaErrors(b *testing.B) {
 		}
 	}
 }
+
+type HookedSchemaItem struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+func TestSchemaHooks(t *testing.T) {
+	config := huma.DefaultConfig("Test API", "1.0.0")
+	config.OnSchema = func(t reflect.Type, s *huma.Schema) {
+		if t != reflect.TypeOf(HookedSchemaItem{}) {
+			return
+		}
+		s.Description = "An item."
+		s.Extensions = map[string]any{"x-owner": "catalog"}
+
+		// Rename `name` to `title`.
+		s.Properties["title"] = s.Properties["name"]
+		delete(s.Properties, "name")
+		s.Required = []string{"id", "title"}
+	}
+	_, api := humatest.New(t, config)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "put-item",
+		Method:      http.MethodPut,
+		Path:        "/item",
+	}, func(ctx context.Context, input *struct {
+		Body HookedSchemaItem
+	}) (*struct{}, error) {
+		return nil, nil
+	})
+
+	s := api.OpenAPI().Components.Schemas.Map()["HookedSchemaItem"]
+	require.NotNil(t, s)
+	assert.Equal(t, "An item.", s.Description)
+	assert.Equal(t, "catalog", s.Extensions["x-owner"])
+	assert.Contains(t, s.Properties, "title")
+	assert.NotContains(t, s.Properties, "name")
+
+	// Validation uses the customized schema.
+	resp := api.Put("/item", map[string]any{"id": "1", "name": "x"})
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+	assert.Contains(t, resp.Body.String(), "title")
+}
+
+type hooklessRegistry struct {
+	huma.Registry
+}
+
+func TestSchemaHooksUnsupported(t *testing.T) {
+	config := huma.DefaultConfig("Test API", "1.0.0")
+	config.Components.Schemas = hooklessRegistry{config.Components.Schemas}
+	config.OnSchema = func(t reflect.Type, s *huma.Schema) {}
+	assert.Panics(t, func() {
+		humatest.New(t, config)
+	})
+}
+
+type InlineChild struct {
+	Name string `json:"name" minLength:"1"`
+}
+
+type InlineParent struct {
+	Child    InlineChild   `json:"child"`
+	Children []InlineChild `json:"children,omitempty"`
+}
+
+func TestInlineRegistry(t *testing.T) {
+	config := huma.DefaultConfig("Test API", "1.0.0")
+	config.Components.Schemas = huma.NewInlineRegistry()
+	_, api := humatest.New(t, config)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "put-parent",
+		Method:      http.MethodPut,
+		Path:        "/parent",
+	}, func(ctx context.Context, input *struct {
+		Body InlineParent
+	}) (*struct{ Body InlineParent }, error) {
+		return &struct{ Body InlineParent }{Body: input.Body}, nil
+	})
+
+	b, err := json.Marshal(api.OpenAPI())
+	require.NoError(t, err)
+	assert.NotContains(t, string(b), "$ref")
+	assert.NotContains(t, string(b), `"schemas"`)
+
+	body := api.OpenAPI().Paths["/parent"].Put.RequestBody.Content["application/json"].Schema
+	assert.Equal(t, huma.TypeObject, body.Properties["child"].Type)
+	assert.Equal(t, huma.TypeObject, body.Properties["children"].Items.Type)
+
+	resp := api.Put("/parent", map[string]any{"child": map[string]any{"name": "a"}})
+	assert.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+
+	resp = api.Put("/parent", map[string]any{"child": map[string]any{"name": ""}})
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+	assert.Contains(t, resp.Body.String(), "body.child.name")
+}
+
+type InlineRecursive struct {
+	Next *InlineRecursive `json:"next,omitempty"`
+}
+
+func TestInlineRegistryRecursive(t *testing.T) {
+	r := huma.NewInlineRegistry()
+	assert.Panics(t, func() {
+		r.Schema(reflect.TypeOf(InlineRecursive{}), true, "")
+	})
+}
+
+func TestRegistryCustomPrefix(t *testing.T) {
+	config := huma.DefaultConfig("Test API", "1.0.0")
+	config.Components.Schemas = huma.NewMapRegistry("#/definitions/", huma.DefaultSchemaNamer)
+	_, api := humatest.New(t, config)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "put-parent",
+		Method:      http.MethodPut,
+		Path:        "/parent",
+	}, func(ctx context.Context, input *struct {
+		Body InlineParent
+	}) (*struct{}, error) {
+		return nil, nil
+	})
+
+	body := api.OpenAPI().Paths["/parent"].Put.RequestBody.Content["application/json"].Schema
+	assert.Equal(t, "#/definitions/InlineParent", body.Ref)
+
+	resp := api.Put("/parent", map[string]any{"child": map[string]any{"name": ""}})
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+
+	// Refs served from the schemas path are rewritten to schema URLs.
+	resp = api.Get("/schemas/InlineParent.json")
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Contains(t, resp.Body.String(), `"/schemas/InlineChild.json"`)
+	assert.NotContains(t, resp.Body.String(), "#/definitions/")
+}
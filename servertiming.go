@@ -0,0 +1,193 @@
+package huma
+
+import (
+	"context"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Names of the built-in server timings recorded for each request when
+// `Config.ServerTiming` is enabled.
+const (
+	// ServerTimingValidation covers reading, parsing, and validating the
+	// request parameters and body, including resolvers.
+	ServerTimingValidation = "validation"
+
+	// ServerTimingHandler covers the operation handler.
+	ServerTimingHandler = "handler"
+
+	// ServerTimingSerialization covers transforming and marshaling the
+	// response body. For streamed responses only the transformation is
+	// included, as the body is marshaled after the headers are sent.
+	ServerTimingSerialization = "serialization"
+)
+
+type serverTimingKey struct{}
+
+// serverTimingEntry is a single named timing metric.
+type serverTimingEntry struct {
+	name string
+	desc string
+	dur  time.Duration
+}
+
+// serverTimings collects the timings for a request until the response
+// headers are written.
+type serverTimings struct {
+	mu      sync.Mutex
+	entries []serverTimingEntry
+	written bool
+}
+
+func (t *serverTimings) add(name, desc string, dur time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !t.written {
+		t.entries = append(t.entries, serverTimingEntry{name, desc, dur})
+	}
+}
+
+// header returns the `Server-Timing` header value and prevents further
+// timings from being recorded.
+func (t *serverTimings) header() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.written {
+		return ""
+	}
+	t.written = true
+
+	var b strings.Builder
+	for i, e := range t.entries {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(e.name)
+		if e.desc != "" {
+			b.WriteString(`;desc=`)
+			b.WriteString(strconv.Quote(e.desc))
+		}
+		b.WriteString(";dur=")
+		ms := float64(e.dur.Round(time.Microsecond)) / float64(time.Millisecond)
+		b.WriteString(strconv.FormatFloat(ms, 'f', -1, 64))
+	}
+	return b.String()
+}
+
+// getServerTimings returns the timings being collected for the request, or
+// nil if server timing is not enabled.
+func getServerTimings(ctx context.Context) *serverTimings {
+	t, _ := ctx.Value(serverTimingKey{}).(*serverTimings)
+	return t
+}
+
+// since records a timing from the start time until now. It does nothing if
+// the timings are nil.
+func (t *serverTimings) since(name string, start time.Time) {
+	if t != nil {
+		t.add(name, "", time.Since(start))
+	}
+}
+
+// AddServerTiming records a named timing to send to the client in the
+// `Server-Timing` response header. The name must be a valid HTTP token, e.g.
+// `db`, and the description is optional. It does nothing if
+// `Config.ServerTiming` is not enabled or the response headers have already
+// been sent.
+//
+//	func handler(ctx context.Context, input *MyInput) (*MyOutput, error) {
+//		start := time.Now()
+//		results := db.Query(ctx, input.Query)
+//		huma.AddServerTiming(ctx, "db", "Database query", time.Since(start))
+//		// ...
+//	}
+func AddServerTiming(ctx context.Context, name, desc string, dur time.Duration) {
+	if t := getServerTimings(ctx); t != nil && validServerTimingName(name) {
+		t.add(name, desc, dur)
+	}
+}
+
+// StartServerTiming starts a named timing and returns a function which
+// records it when called. See `AddServerTiming`.
+//
+//	stop := huma.StartServerTiming(ctx, "db", "")
+//	results := db.Query(ctx, input.Query)
+//	stop()
+func StartServerTiming(ctx context.Context, name, desc string) func() {
+	start := time.Now()
+	return func() {
+		AddServerTiming(ctx, name, desc, time.Since(start))
+	}
+}
+
+// validServerTimingName returns whether the name is a valid HTTP token so
+// it cannot break the header.
+func validServerTimingName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		if c <= ' ' || c > '~' || strings.IndexByte(`"(),/:;<=>?@[\]{}`, c) != -1 {
+			return false
+		}
+	}
+	return true
+}
+
+// serverTimingContext adds the collected timings to the response headers
+// just before they are sent.
+type serverTimingContext struct {
+	humaContext
+	ctx     context.Context
+	timings *serverTimings
+}
+
+func (c *serverTimingContext) Context() context.Context {
+	return c.ctx
+}
+
+func (c *serverTimingContext) writeHeader() {
+	if h := c.timings.header(); h != "" {
+		c.humaContext.SetHeader("Server-Timing", h)
+	}
+}
+
+func (c *serverTimingContext) SetStatus(code int) {
+	c.writeHeader()
+	c.humaContext.SetStatus(code)
+}
+
+func (c *serverTimingContext) BodyWriter() io.Writer {
+	c.writeHeader()
+	return c.humaContext.BodyWriter()
+}
+
+func (c *serverTimingContext) StreamBody(cb func(w io.Writer, flush func() error)) {
+	c.writeHeader()
+	streamBody(c.humaContext, cb)
+}
+
+func (c *serverTimingContext) Unwrap() Context {
+	return c.humaContext
+}
+
+// serverTiming wraps a handler to collect timings and send them in the
+// `Server-Timing` response header when enabled in the API's config.
+func serverTiming(api API, handler func(ctx Context)) func(ctx Context) {
+	if !configOf(api).ServerTiming {
+		return handler
+	}
+
+	return func(ctx Context) {
+		t := &serverTimings{}
+		handler(&serverTimingContext{
+			humaContext: ctx,
+			ctx:         context.WithValue(ctx.Context(), serverTimingKey{}, t),
+			timings:     t,
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package huma_test
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/humatest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServerTiming(t *testing.T) {
+	config := huma.DefaultConfig("Test API", "1.0.0")
+	config.ServerTiming = true
+	_, api := humatest.New(t, config)
+
+	api.UseMiddleware(func(ctx huma.Context, next func(huma.Context)) {
+		huma.AddServerTiming(ctx.Context(), "auth", "", 2*time.Millisecond)
+		next(ctx)
+	})
+
+	type Output struct {
+		Body struct {
+			Message string `json:"message"`
+		}
+	}
+
+	huma.Register(api, huma.Operation{
+		OperationID: "get-greeting",
+		Method:      http.MethodGet,
+		Path:        "/greeting",
+	}, func(ctx context.Context, input *struct {
+		Count int `query:"count" minimum:"1"`
+	}) (*Output, error) {
+		huma.AddServerTiming(ctx, "db", `Query "greetings"`, 1500*time.Microsecond)
+		huma.AddServerTiming(ctx, "bad name", "", time.Millisecond)
+		stop := huma.StartServerTiming(ctx, "cache", "")
+		stop()
+		out := &Output{}
+		out.Body.Message = "hello"
+		return out, nil
+	})
+
+	huma.Register(api, huma.Operation{
+		OperationID:      "get-buffered",
+		Method:           http.MethodGet,
+		Path:             "/buffered",
+		ResponseStrategy: huma.ResponseBuffered,
+	}, func(ctx context.Context, input *struct{}) (*Output, error) {
+		return &Output{}, nil
+	})
+
+	resp := api.Get("/greeting")
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Regexp(t, regexp.MustCompile(`^auth;dur=2, validation;dur=[\d.]+, db;desc="Query \\"greetings\\"";dur=1.5, cache;dur=[\d.]+, handler;dur=[\d.]+, serialization;dur=[\d.]+$`), resp.Header().Get("Server-Timing"))
+
+	// Failed validation still reports the time spent.
+	resp = api.Get("/greeting?count=0")
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+	assert.Regexp(t, `^auth;dur=2, validation;dur=[\d.]+$`, resp.Header().Get("Server-Timing"))
+
+	resp = api.Get("/buffered")
+	assert.Contains(t, resp.Header().Get("Server-Timing"), "serialization;dur=")
+}
+
+func TestServerTimingDisabled(t *testing.T) {
+	_, api := humatest.New(t, huma.DefaultConfig("Test API", "1.0.0"))
+
+	huma.Register(api, huma.Operation{
+		OperationID: "get-test",
+		Method:      http.MethodGet,
+		Path:        "/test",
+	}, func(ctx context.Context, input *struct{}) (*struct{}, error) {
+		huma.AddServerTiming(ctx, "db", "", time.Millisecond)
+		return nil, nil
+	})
+
+	resp := api.Get("/test")
+	assert.Equal(t, http.StatusNoContent, resp.Code)
+	assert.Empty(t, resp.Header().Get("Server-Timing"))
+}
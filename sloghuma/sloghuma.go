@@ -0,0 +1,315 @@
+//go:build go1.21
+
+// Package sloghuma provides structured request logging for Huma APIs using
+// `log/slog`. It is implemented as router-agnostic Huma middleware, so every
+// adapter produces identical log records.
+//
+//	api := humachi.New(router, huma.DefaultConfig("My API", "1.0.0"))
+//	api.UseMiddleware(sloghuma.Middleware(slog.Default()))
+package sloghuma
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+type config struct {
+	clientIPHeader string
+	registry       huma.Registry
+	bodyLimit      int
+}
+
+// Option configures the logging middleware.
+type Option func(*config)
+
+// WithClientIPHeader reads the client IP from the given header, such as
+// `X-Forwarded-For`, rather than the connection's remote address. Only use
+// this behind a proxy which sets the header, as clients can send any value.
+// If the header contains a list of addresses then the first is used. Prefer
+// `huma.Config.TrustedProxies`, which is used by default and cannot be
+// spoofed by clients.
+func WithClientIPHeader(name string) Option {
+	return func(c *config) {
+		c.clientIPHeader = name
+	}
+}
+
+// WithBodies logs the request and response bodies for debugging, using the
+// operation's schemas and the given registry (typically
+// `api.OpenAPI().Components.Schemas`) to replace sensitive values with
+// `huma.Redacted`. See `huma.Schema.IsSensitive` for which fields are
+// considered sensitive. Only JSON bodies with a schema are logged, and bodies
+// larger than `limit` bytes are omitted rather than truncated so that nothing
+// is ever logged without being redacted.
+func WithBodies(registry huma.Registry, limit int) Option {
+	return func(c *config) {
+		c.registry = registry
+		c.bodyLimit = limit
+	}
+}
+
+type attrsKey struct{}
+
+// AddAttrs adds custom attributes to the request's log record. It is a no-op
+// if the logging middleware is not in use.
+//
+//	func handler(ctx context.Context, input *MyInput) (*MyOutput, error) {
+//		sloghuma.AddAttrs(ctx, slog.String("user", input.User))
+//		// ...
+//	}
+func AddAttrs(ctx context.Context, attrs ...slog.Attr) {
+	if holder, ok := ctx.Value(attrsKey{}).(*[]slog.Attr); ok {
+		*holder = append(*holder, attrs...)
+	}
+}
+
+// loggingContext overrides the request context to hold custom attributes and
+// records the response status. When bodies are logged it also captures the
+// request and response bodies.
+type loggingContext struct {
+	huma.ContextWrapper
+	ctx    context.Context
+	status int
+
+	reqBody  *capture
+	respBody *capture
+	writer   io.Writer
+}
+
+func (c *loggingContext) Context() context.Context {
+	return c.ctx
+}
+
+func (c *loggingContext) SetStatus(code int) {
+	c.status = code
+	c.ContextWrapper.SetStatus(code)
+}
+
+func (c *loggingContext) BodyReader() io.Reader {
+	if c.reqBody == nil {
+		return c.ContextWrapper.BodyReader()
+	}
+	return io.TeeReader(c.ContextWrapper.BodyReader(), c.reqBody)
+}
+
+func (c *loggingContext) BodyWriter() io.Writer {
+	if c.respBody == nil {
+		return c.ContextWrapper.BodyWriter()
+	}
+	if c.writer == nil {
+		c.writer = &captureWriter{w: c.ContextWrapper.BodyWriter(), capture: c.respBody}
+	}
+	return c.writer
+}
+
+func (c *loggingContext) StreamBody(cb func(w io.Writer, flush func() error)) {
+	if sc, ok := c.Unwrap().(huma.StreamingContext); ok {
+		sc.StreamBody(cb)
+		return
+	}
+	w := c.ContextWrapper.BodyWriter()
+	sw := huma.NewStreamWriter(c.ctx, w, nil)
+	defer sw.Close()
+	cb(w, sw.Flush)
+}
+
+// capture buffers up to `limit` bytes of a body. Writes never fail so that
+// capturing does not affect the request.
+type capture struct {
+	buf      bytes.Buffer
+	limit    int
+	overflow bool
+}
+
+func (c *capture) Write(p []byte) (int, error) {
+	if !c.overflow {
+		if c.buf.Len()+len(p) > c.limit {
+			c.overflow = true
+			c.buf = bytes.Buffer{}
+		} else {
+			c.buf.Write(p)
+		}
+	}
+	return len(p), nil
+}
+
+// captureWriter copies the response body into a capture while still allowing
+// the response to be flushed.
+type captureWriter struct {
+	w       io.Writer
+	capture *capture
+}
+
+func (w *captureWriter) Write(p []byte) (int, error) {
+	n, err := w.w.Write(p)
+	w.capture.Write(p[:n])
+	return n, err
+}
+
+func (w *captureWriter) Flush() {
+	w.FlushError()
+}
+
+func (w *captureWriter) FlushError() error {
+	switch t := w.w.(type) {
+	case interface{ FlushError() error }:
+		return t.FlushError()
+	case interface{ Flush() error }:
+		return t.Flush()
+	case http.Flusher:
+		t.Flush()
+		return nil
+	case http.ResponseWriter:
+		return http.NewResponseController(t).Flush()
+	}
+	return http.ErrNotSupported
+}
+
+// jsonSchema returns the schema of the JSON media type in the given content,
+// if any.
+func jsonSchema(content map[string]*huma.MediaType) *huma.Schema {
+	if mt := content["application/json"]; mt != nil && mt.Schema != nil {
+		return mt.Schema
+	}
+	for ct, mt := range content {
+		if mt != nil && mt.Schema != nil && strings.HasSuffix(ct, "+json") {
+			return mt.Schema
+		}
+	}
+	return nil
+}
+
+// bodyAttr returns a redacted body attribute, or false if the body could not
+// be safely logged.
+func (c *config) bodyAttr(key string, s *huma.Schema, body *capture) (slog.Attr, bool) {
+	if s == nil || body.overflow || body.buf.Len() == 0 {
+		return slog.Attr{}, false
+	}
+	redacted, err := huma.RedactJSON(c.registry, s, body.buf.Bytes())
+	if err != nil {
+		return slog.Attr{}, false
+	}
+	return slog.String(key, string(redacted)), true
+}
+
+// bodyAttrs returns the redacted request and response body attributes.
+func (c *config) bodyAttrs(op *huma.Operation, status int, lctx *loggingContext) []slog.Attr {
+	var attrs []slog.Attr
+	if op.RequestBody != nil {
+		if a, ok := c.bodyAttr("request_body", jsonSchema(op.RequestBody.Content), lctx.reqBody); ok {
+			attrs = append(attrs, a)
+		}
+	}
+	resp := op.Responses[strconv.Itoa(status)]
+	if resp == nil {
+		resp = op.Responses["default"]
+	}
+	if resp != nil {
+		if a, ok := c.bodyAttr("response_body", jsonSchema(resp.Content), lctx.respBody); ok {
+			attrs = append(attrs, a)
+		}
+	}
+	return attrs
+}
+
+// clientIP returns the client's IP address without a port.
+func (c *config) clientIP(ctx huma.Context) string {
+	if c.clientIPHeader != "" {
+		if v := ctx.Header(c.clientIPHeader); v != "" {
+			ip, _, _ := strings.Cut(v, ",")
+			return strings.TrimSpace(ip)
+		}
+	}
+	if info := huma.ClientInfoFromContext(ctx.Context()); info.IP.IsValid() {
+		return info.IP.String()
+	} else if info.ID != "" {
+		// A trusted proxy hid the client's address behind an identifier.
+		return info.ID
+	}
+	addr := huma.RemoteAddr(ctx)
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		return host
+	}
+	return addr
+}
+
+// Middleware logs one record per request once it completes, including the
+// operation ID (or the method and path template if there is no ID), method,
+// path, status, duration, client IP, and request ID (if enabled via
+// `huma.Config.RequestIDHeader`). Handlers can add custom attributes with
+// `AddAttrs`. Records are logged at the `INFO` level, or `WARN` for `4xx` and
+// `ERROR` for `5xx` responses and panics.
+func Middleware(logger *slog.Logger, opts ...Option) func(ctx huma.Context, next func(huma.Context)) {
+	c := &config{}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return func(ctx huma.Context, next func(huma.Context)) {
+		op := ctx.Operation()
+		name := op.OperationID
+		if name == "" {
+			name = op.Method + " " + op.Path
+		}
+
+		start := time.Now()
+		var custom []slog.Attr
+		lctx := &loggingContext{
+			ContextWrapper: huma.WrapContext(ctx),
+			ctx:            context.WithValue(ctx.Context(), attrsKey{}, &custom),
+		}
+		if c.registry != nil {
+			lctx.reqBody = &capture{limit: c.bodyLimit}
+			lctx.respBody = &capture{limit: c.bodyLimit}
+		}
+
+		defer func() {
+			status := lctx.status
+			if r := recover(); r != nil {
+				// Log panics as server errors, then let them continue.
+				status = http.StatusInternalServerError
+				defer panic(r)
+			}
+			if status == 0 {
+				status = http.StatusOK
+			}
+
+			level := slog.LevelInfo
+			if status >= 500 {
+				level = slog.LevelError
+			} else if status >= 400 {
+				level = slog.LevelWarn
+			}
+
+			attrs := make([]slog.Attr, 0, 7+len(custom))
+			attrs = append(attrs,
+				slog.String("operation", name),
+				slog.String("method", ctx.Method()),
+				slog.String("path", huma.RedactPath(ctx)),
+				slog.Int("status", status),
+				slog.Duration("duration", time.Since(start)),
+				slog.String("client_ip", c.clientIP(ctx)),
+			)
+			if id := huma.RequestID(ctx.Context()); id != "" {
+				attrs = append(attrs, slog.String("request_id", id))
+			}
+			if c.registry != nil {
+				attrs = append(attrs, c.bodyAttrs(op, status, lctx)...)
+			}
+			attrs = append(attrs, custom...)
+
+			logger.LogAttrs(ctx.Context(), level, "request", attrs...)
+		}()
+
+		next(lctx)
+	}
+}
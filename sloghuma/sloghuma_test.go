@@ -0,0 +1,145 @@
+//go:build go1.21
+
+package sloghuma
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/humatest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMiddleware(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := slog.New(slog.NewJSONHandler(buf, nil))
+
+	config := huma.DefaultConfig("Test API", "1.0.0")
+	config.RequestIDHeader = "X-Request-ID"
+	_, api := humatest.New(t, config)
+	api.UseMiddleware(Middleware(logger, WithClientIPHeader("X-Forwarded-For")))
+
+	huma.Register(api, huma.Operation{
+		OperationID: "get-thing",
+		Method:      http.MethodGet,
+		Path:        "/things/{id}",
+	}, func(ctx context.Context, input *struct {
+		ID string `path:"id"`
+	}) (*struct{}, error) {
+		AddAttrs(ctx, slog.String("thing", input.ID))
+		if input.ID == "missing" {
+			return nil, huma.Error404NotFound("not found")
+		}
+		return nil, nil
+	})
+
+	api.Get("/things/123", "X-Request-ID: abc123", "X-Forwarded-For: 203.0.113.1, 10.0.0.1")
+
+	var record map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+	assert.Equal(t, "INFO", record["level"])
+	assert.Equal(t, "request", record["msg"])
+	assert.Equal(t, "get-thing", record["operation"])
+	assert.Equal(t, "GET", record["method"])
+	assert.Equal(t, "/things/123", record["path"])
+	assert.EqualValues(t, http.StatusNoContent, record["status"])
+	assert.Contains(t, record, "duration")
+	assert.Equal(t, "203.0.113.1", record["client_ip"])
+	assert.Equal(t, "abc123", record["request_id"])
+	assert.Equal(t, "123", record["thing"])
+
+	buf.Reset()
+	record = nil
+	api.Get("/things/missing")
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+	assert.Equal(t, "WARN", record["level"])
+	assert.EqualValues(t, http.StatusNotFound, record["status"])
+}
+
+func TestClientIP(t *testing.T) {
+	_, api := humatest.New(t, huma.DefaultConfig("Test API", "1.0.0"))
+
+	var ip string
+	api.UseMiddleware(func(ctx huma.Context, next func(huma.Context)) {
+		ip = (&config{}).clientIP(ctx)
+		next(ctx)
+	})
+	huma.Register(api, huma.Operation{
+		OperationID: "test",
+		Method:      http.MethodGet,
+		Path:        "/test",
+	}, func(ctx context.Context, input *struct{}) (*struct{}, error) {
+		return nil, nil
+	})
+
+	// `httptest.NewRequest` sets a remote address of `192.0.2.1:1234`.
+	req := httptest.NewRequest(http.MethodGet, "/test", nil)
+	api.Adapter().ServeHTTP(httptest.NewRecorder(), req)
+	assert.Equal(t, "192.0.2.1", ip)
+}
+
+func TestBodies(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := slog.New(slog.NewJSONHandler(buf, nil))
+
+	_, api := humatest.New(t, huma.DefaultConfig("Test API", "1.0.0"))
+	api.UseMiddleware(Middleware(logger, WithBodies(api.OpenAPI().Components.Schemas, 1024)))
+
+	type Login struct {
+		Username string `json:"username"`
+		Password string `json:"password" format:"password"`
+	}
+
+	huma.Register(api, huma.Operation{
+		OperationID: "login",
+		Method:      http.MethodPost,
+		Path:        "/login",
+	}, func(ctx context.Context, input *struct {
+		Body Login
+	}) (*struct {
+		Body struct {
+			User  string `json:"user"`
+			Token string `json:"token" sensitive:"true"`
+		}
+	}, error) {
+		resp := &struct {
+			Body struct {
+				User  string `json:"user"`
+				Token string `json:"token" sensitive:"true"`
+			}
+		}{}
+		resp.Body.User = input.Body.Username
+		resp.Body.Token = "secret-token"
+		return resp, nil
+	})
+
+	resp := api.Post("/login", map[string]any{"username": "alice", "password": "hunter2"})
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Contains(t, resp.Body.String(), "secret-token")
+
+	var record map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+	assert.JSONEq(t, `{"username": "alice", "password": "[REDACTED]"}`, record["request_body"].(string))
+	var respBody map[string]any
+	require.NoError(t, json.Unmarshal([]byte(record["response_body"].(string)), &respBody))
+	assert.Equal(t, "alice", respBody["user"])
+	assert.Equal(t, huma.Redacted, respBody["token"])
+	assert.NotContains(t, buf.String(), "hunter2")
+	assert.NotContains(t, buf.String(), "secret-token")
+
+	// Bodies over the limit are omitted rather than logged unredacted.
+	buf.Reset()
+	record = nil
+	api.Post("/login", map[string]any{"username": strings.Repeat("a", 2048), "password": "hunter2"})
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+	assert.NotContains(t, record, "request_body")
+	assert.NotContains(t, buf.String(), "hunter2")
+}
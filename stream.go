@@ -0,0 +1,144 @@
+package huma
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"reflect"
+)
+
+var streamBodyType = reflect.TypeOf(StreamBody(nil))
+
+// StreamBody is an output body type which streams data to the client using
+// a `huma.StreamWriter`. The response status and headers are written before
+// the function is called.
+//
+//	type ProgressOutput struct {
+//		ContentType string `header:"Content-Type"`
+//		Body        huma.StreamBody
+//	}
+//
+//	func handler(ctx context.Context, input *struct{}) (*ProgressOutput, error) {
+//		return &ProgressOutput{
+//			ContentType: "text/plain",
+//			Body: func(w *huma.StreamWriter) {
+//				for i := 0; i <= 100; i += 10 {
+//					select {
+//					case <-w.Context().Done():
+//						// The client went away.
+//						return
+//					case <-time.After(time.Second):
+//					}
+//					fmt.Fprintf(w, "%d%%\n", i)
+//					w.Flush()
+//				}
+//			},
+//		}, nil
+//	}
+type StreamBody func(w *StreamWriter)
+
+// StreamingContext may be implemented by adapter contexts whose
+// `BodyWriter` cannot be flushed directly, such as routers based on
+// `fasthttp`. The adapter calls `cb` with a writer and a function to flush
+// buffered data to the client, and may do so after the handler has returned.
+// It is found through contexts wrapped by middleware via `ContextUnwrapper`,
+// so middleware which changes the `BodyWriter` should implement it too.
+type StreamingContext interface {
+	StreamBody(cb func(w io.Writer, flush func() error))
+}
+
+// StreamWriter is a writer for streaming response bodies. It provides an
+// explicit `Flush` to send buffered data to the client and a context which
+// is canceled when the client disconnects or a write fails.
+type StreamWriter struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	w      io.Writer
+	flush  func() error
+}
+
+// NewStreamWriter creates a stream writer using the given writer, flush
+// function and parent context. If `flush` is nil, the writer is flushed via
+// `http.Flusher` or `http.ResponseController` when possible.
+func NewStreamWriter(ctx context.Context, w io.Writer, flush func() error) *StreamWriter {
+	ctx, cancel := context.WithCancel(ctx)
+	if flush == nil {
+		flush = flushFunc(w)
+	}
+	return &StreamWriter{ctx: ctx, cancel: cancel, w: w, flush: flush}
+}
+
+// flushFunc returns a function to flush the given writer.
+func flushFunc(w io.Writer) func() error {
+	switch t := w.(type) {
+	case interface{ FlushError() error }:
+		return t.FlushError
+	case interface{ Flush() error }:
+		return t.Flush
+	case http.Flusher:
+		return func() error {
+			t.Flush()
+			return nil
+		}
+	case http.ResponseWriter:
+		return http.NewResponseController(t).Flush
+	}
+	return func() error {
+		return http.ErrNotSupported
+	}
+}
+
+// Context returns a context which is canceled when the client disconnects
+// or a write to the client fails.
+func (s *StreamWriter) Context() context.Context {
+	return s.ctx
+}
+
+// Write data to the client. Data may be buffered until `Flush` is called.
+func (s *StreamWriter) Write(p []byte) (int, error) {
+	n, err := s.w.Write(p)
+	if err != nil {
+		s.cancel()
+	}
+	return n, err
+}
+
+// Flush sends any buffered data to the client. If the underlying writer
+// cannot be flushed then `http.ErrNotSupported` is returned.
+func (s *StreamWriter) Flush() error {
+	if err := s.flush(); err != nil {
+		if err != http.ErrNotSupported {
+			s.cancel()
+		}
+		return err
+	}
+	return nil
+}
+
+// Close releases the resources associated with the stream writer's context.
+func (s *StreamWriter) Close() error {
+	s.cancel()
+	return nil
+}
+
+// writeStream calls the stream body function with a stream writer for the
+// given context, using the adapter's streaming support if available.
+func writeStream(ctx Context, body StreamBody) {
+	parent := ctx.Context()
+	streamBody(ctx, func(w io.Writer, flush func() error) {
+		sw := NewStreamWriter(parent, w, flush)
+		defer sw.Close()
+		body(sw)
+	})
+}
+
+// streamBody calls `cb` with the response body writer and a function to
+// flush it, using the adapter's streaming support if available.
+func streamBody(ctx Context, cb func(w io.Writer, flush func() error)) {
+	if sc, ok := findContext[StreamingContext](ctx); ok {
+		sc.StreamBody(cb)
+		return
+	}
+	w := ctx.BodyWriter()
+	cb(w, flushFunc(w))
+}
@@ -0,0 +1,51 @@
+package huma_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+type failingWriter struct{}
+
+func (w failingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("connection reset")
+}
+
+func TestStreamWriterFlush(t *testing.T) {
+	rec := httptest.NewRecorder()
+	w := huma.NewStreamWriter(context.Background(), rec, nil)
+	defer w.Close()
+
+	w.Write([]byte("hello"))
+	assert.NoError(t, w.Flush())
+	assert.True(t, rec.Flushed)
+	assert.NoError(t, w.Context().Err())
+}
+
+func TestStreamWriterDisconnect(t *testing.T) {
+	// Parent context cancellation (e.g. client disconnect) propagates.
+	parent, cancel := context.WithCancel(context.Background())
+	w := huma.NewStreamWriter(parent, httptest.NewRecorder(), nil)
+	cancel()
+	<-w.Context().Done()
+
+	// Write failures cancel the stream context.
+	w = huma.NewStreamWriter(context.Background(), failingWriter{}, nil)
+	_, err := w.Write([]byte("hello"))
+	assert.Error(t, err)
+	assert.ErrorIs(t, w.Context().Err(), context.Canceled)
+}
+
+func TestStreamWriterFlushUnsupported(t *testing.T) {
+	w := huma.NewStreamWriter(context.Background(), failingWriter{}, nil)
+	assert.ErrorIs(t, w.Flush(), http.ErrNotSupported)
+
+	// Unsupported flushing does not cancel the stream.
+	assert.NoError(t, w.Context().Err())
+}
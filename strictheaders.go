@@ -0,0 +1,145 @@
+package huma
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// strictHeaders validates the request headers of operations using
+// `Operation.StrictHeaders` or `Operation.RejectUnknownHeaders`.
+type strictHeaders struct {
+	// params are the header params declared in the operation's `Parameters`
+	// which are not bound to an input field, so are not otherwise validated.
+	params []*Param
+
+	// allowed are the canonical names of headers which may be sent when
+	// rejecting unknown headers, or nil if unknown headers are allowed.
+	allowed map[string]bool
+
+	// allowForwarded allows `X-Forwarded-*` headers set by trusted proxies.
+	allowForwarded bool
+}
+
+// newStrictHeaders returns the strict header validation for the operation,
+// or nil if it is not enabled. It must be called once the operation's
+// `Parameters` are complete.
+func newStrictHeaders(api API, op *Operation, bound *findResult[*paramFieldInfo]) *strictHeaders {
+	if !op.StrictHeaders && !op.RejectUnknownHeaders {
+		return nil
+	}
+
+	isBound := map[string]bool{}
+	for _, p := range bound.Paths {
+		if p.Value.Loc == "header" {
+			isBound[http.CanonicalHeaderKey(p.Value.Name)] = true
+		}
+	}
+
+	s := &strictHeaders{}
+	if op.RejectUnknownHeaders {
+		s.allowed = map[string]bool{}
+	}
+	for _, p := range op.Parameters {
+		if p.In != "header" {
+			continue
+		}
+		name := http.CanonicalHeaderKey(p.Name)
+		if op.StrictHeaders && !isBound[name] && p.Schema != nil {
+			p.Schema.PrecomputeMessages()
+			s.params = append(s.params, p)
+		}
+		if s.allowed != nil {
+			s.allowed[name] = true
+		}
+	}
+
+	if s.allowed != nil {
+		for name := range isBound {
+			// Includes hidden params, which are not in the OpenAPI.
+			s.allowed[name] = true
+		}
+		// Headers used by the API itself or its security schemes are declared
+		// elsewhere in the OpenAPI, so are always allowed.
+		config := configOf(api)
+		if config.RequestIDHeader != "" {
+			s.allowed[http.CanonicalHeaderKey(config.RequestIDHeader)] = true
+		}
+		if oapi := api.OpenAPI(); oapi.Components != nil {
+			for _, scheme := range oapi.Components.SecuritySchemes {
+				if scheme.Type == "apiKey" && scheme.In == "header" {
+					s.allowed[http.CanonicalHeaderKey(scheme.Name)] = true
+				}
+			}
+		}
+		s.allowForwarded = len(config.TrustedProxies) > 0
+	}
+	return s
+}
+
+// validate adds an error for each invalid declared header and, if enabled,
+// each undeclared `X-` header.
+func (s *strictHeaders) validate(r Registry, ctx Context, pb *PathBuffer, res *ValidateResult) {
+	for _, p := range s.params {
+		pb.Reset()
+		pb.Push("header")
+		pb.Push(p.Name)
+		value := ctx.Header(p.Name)
+		if value == "" {
+			if p.Required {
+				res.Add(pb, "", "required header parameter is missing")
+			}
+			continue
+		}
+		Validate(r, p.Schema, pb, ModeWriteToServer, headerParamValue(r, p.Schema, value), res)
+	}
+
+	if s.allowed == nil {
+		return
+	}
+	ctx.EachHeader(func(name, value string) {
+		if len(name) < 2 || !strings.EqualFold(name[:2], "x-") {
+			return
+		}
+		name = http.CanonicalHeaderKey(name)
+		if s.allowed[name] || (s.allowForwarded && strings.HasPrefix(name, "X-Forwarded-")) {
+			return
+		}
+		pb.Reset()
+		pb.Push("header")
+		pb.Push(name)
+		res.Add(pb, nil, "unexpected header")
+	})
+}
+
+// headerParamValue converts a header's string value to the type expected by
+// the schema so it can be validated. Values which cannot be converted are
+// returned as-is and so fail validation.
+func headerParamValue(r Registry, s *Schema, value string) any {
+	for s.Ref != "" {
+		s = r.SchemaFromRef(s.Ref)
+	}
+	switch s.Type {
+	case TypeBoolean:
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+	case TypeInteger, TypeNumber:
+		if f, err := strconv.ParseFloat(value, 64); err == nil {
+			return f
+		}
+	case TypeArray:
+		items := strings.Split(value, ",")
+		values := make([]any, len(items))
+		for i, item := range items {
+			item = strings.TrimSpace(item)
+			if s.Items != nil {
+				values[i] = headerParamValue(r, s.Items, item)
+			} else {
+				values[i] = item
+			}
+		}
+		return values
+	}
+	return value
+}
@@ -0,0 +1,89 @@
+package huma_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/humatest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStrictHeaders(t *testing.T) {
+	config := huma.DefaultConfig("Test API", "1.0.0")
+	config.RequestIDHeader = "X-Request-ID"
+	config.Components.SecuritySchemes = map[string]*huma.SecurityScheme{
+		"key": {Type: "apiKey", In: "header", Name: "X-API-Key"},
+	}
+	_, api := humatest.New(t, config)
+
+	minimum := 1.0
+	huma.Register(api, huma.Operation{
+		OperationID: "strict",
+		Method:      http.MethodGet,
+		Path:        "/strict",
+		Parameters: []*huma.Param{
+			{Name: "X-Tenant", In: "header", Required: true, Schema: &huma.Schema{Type: huma.TypeString, Pattern: "^[a-z]+$"}},
+			{Name: "X-Retry", In: "header", Schema: &huma.Schema{Type: huma.TypeInteger, Minimum: &minimum}},
+			{Name: "X-Flags", In: "header", Schema: &huma.Schema{Type: huma.TypeArray, Items: &huma.Schema{Type: huma.TypeBoolean}}},
+		},
+		StrictHeaders:        true,
+		RejectUnknownHeaders: true,
+	}, func(ctx context.Context, input *struct {
+		Trace  string `header:"X-Trace" maxLength:"8"`
+		Secret string `header:"X-Secret" hidden:"true"`
+	}) (*struct{}, error) {
+		return nil, nil
+	})
+
+	resp := api.Get("/strict",
+		"X-Tenant: acme",
+		"X-Retry: 2",
+		"X-Flags: true, false",
+		"X-Trace: abc",
+		"X-Secret: shh",
+		"X-Request-ID: 123",
+		"X-API-Key: key",
+		"Accept: application/json",
+	)
+	assert.Equal(t, http.StatusNoContent, resp.Code, resp.Body.String())
+
+	resp = api.Get("/strict")
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+	assert.Contains(t, resp.Body.String(), "header.X-Tenant")
+
+	resp = api.Get("/strict",
+		"X-Tenant: ACME",
+		"X-Retry: 0",
+		"X-Flags: yes",
+		"X-Other: value",
+	)
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+	body := resp.Body.String()
+	assert.Contains(t, body, "header.X-Tenant")
+	assert.Contains(t, body, "header.X-Retry")
+	assert.Contains(t, body, "header.X-Flags")
+	assert.Contains(t, body, "header.X-Other")
+	assert.Contains(t, body, "unexpected header")
+}
+
+func TestStrictHeadersForwarded(t *testing.T) {
+	config := huma.DefaultConfig("Test API", "1.0.0")
+	config.TrustedProxies = []string{"10.0.0.0/8"}
+	_, api := humatest.New(t, config)
+
+	huma.Register(api, huma.Operation{
+		OperationID:          "strict",
+		Method:               http.MethodGet,
+		Path:                 "/strict",
+		RejectUnknownHeaders: true,
+	}, func(ctx context.Context, input *struct{}) (*struct{}, error) {
+		return nil, nil
+	})
+
+	resp := api.Get("/strict", "X-Forwarded-For: 192.0.2.1", "X-Unknown: 1")
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+	assert.Contains(t, resp.Body.String(), "header.X-Unknown")
+	assert.NotContains(t, resp.Body.String(), "header.X-Forwarded-For")
+}
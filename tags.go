@@ -0,0 +1,52 @@
+package huma
+
+// findTag returns the OpenAPI tag with the given name, if any.
+func findTag(oapi *OpenAPI, name string) *Tag {
+	for _, tag := range oapi.Tags {
+		if tag != nil && tag.Name == name {
+			return tag
+		}
+	}
+	return nil
+}
+
+// applyTagDefaults adds the errors and security requirements configured on
+// the operation's tags. Security is only inherited if the operation has none
+// set, using the first of its tags which has any.
+func applyTagDefaults(oapi *OpenAPI, op *Operation) {
+	for _, name := range op.Tags {
+		tag := findTag(oapi, name)
+		if tag == nil {
+			continue
+		}
+		for _, code := range tag.Errors {
+			if !containsInt(op.Errors, code) {
+				op.Errors = append(op.Errors, code)
+			}
+		}
+		if op.Security == nil && tag.Security != nil {
+			op.Security = append([]map[string][]string{}, tag.Security...)
+		}
+	}
+}
+
+// tagMiddlewares returns the middlewares configured on the operation's tags,
+// in the order the tags are listed on the operation.
+func tagMiddlewares(oapi *OpenAPI, op *Operation) Middlewares {
+	var m Middlewares
+	for _, name := range op.Tags {
+		if tag := findTag(oapi, name); tag != nil {
+			m = append(m, tag.Middlewares...)
+		}
+	}
+	return m
+}
+
+func containsInt(values []int, v int) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
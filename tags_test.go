@@ -0,0 +1,106 @@
+package huma_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/humatest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTagDefaults(t *testing.T) {
+	_, api := humatest.New(t, huma.DefaultConfig("Test API", "1.0.0"))
+
+	calls := []string{}
+	api.UseMiddleware(func(ctx huma.Context, next func(huma.Context)) {
+		calls = append(calls, "api")
+		next(ctx)
+	})
+
+	oapi := api.OpenAPI()
+	oapi.Tags = append(oapi.Tags, &huma.Tag{
+		Name: "Admin",
+		Middlewares: huma.Middlewares{func(ctx huma.Context, next func(huma.Context)) {
+			calls = append(calls, "admin")
+			if ctx.Header("Authorization") == "" {
+				huma.WriteErr(api, ctx, http.StatusUnauthorized, "login required")
+				return
+			}
+			next(ctx)
+		}},
+		Security: []map[string][]string{{"bearer": {"admin"}}},
+		Errors:   []int{http.StatusUnauthorized, http.StatusForbidden},
+	}, &huma.Tag{
+		Name: "Audited",
+		Middlewares: huma.Middlewares{func(ctx huma.Context, next func(huma.Context)) {
+			calls = append(calls, "audited")
+			next(ctx)
+		}},
+		Errors: []int{http.StatusForbidden, http.StatusConflict},
+	})
+
+	handler := func(ctx context.Context, input *struct{}) (*struct{}, error) {
+		calls = append(calls, "handler")
+		return nil, nil
+	}
+
+	huma.Register(api, huma.Operation{
+		OperationID: "delete-user",
+		Method:      http.MethodDelete,
+		Path:        "/users",
+		Tags:        []string{"Admin", "Audited"},
+		Errors:      []int{http.StatusNotFound},
+	}, handler)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "admin-health",
+		Method:      http.MethodGet,
+		Path:        "/health",
+		Tags:        []string{"Admin"},
+		Security:    []map[string][]string{},
+	}, handler)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "list-users",
+		Method:      http.MethodGet,
+		Path:        "/users",
+		Tags:        []string{"Users"},
+	}, handler)
+
+	// Errors are merged without duplicates and security is inherited.
+	op := oapi.Paths["/users"].Delete
+	assert.Equal(t, []int{http.StatusNotFound, http.StatusUnauthorized, http.StatusForbidden, http.StatusConflict}, op.Errors[:4])
+	assert.Equal(t, []map[string][]string{{"bearer": {"admin"}}}, op.Security)
+	assert.Contains(t, op.Responses, "409")
+
+	// An explicit empty security requirement is kept.
+	assert.Equal(t, []map[string][]string{}, oapi.Paths["/health"].Get.Security)
+	assert.Contains(t, oapi.Paths["/health"].Get.Responses, "401")
+
+	// Untagged or unknown tags are unaffected.
+	assert.Nil(t, oapi.Paths["/users"].Get.Security)
+	assert.Empty(t, oapi.Paths["/users"].Get.Errors)
+
+	// Tag middlewares run after the API's, in tag order.
+	resp := api.Delete("/users", "Authorization: Bearer abc")
+	require.Equal(t, http.StatusNoContent, resp.Code, resp.Body.String())
+	assert.Equal(t, []string{"api", "admin", "audited", "handler"}, calls)
+
+	calls = nil
+	resp = api.Delete("/users")
+	assert.Equal(t, http.StatusUnauthorized, resp.Code)
+	assert.Equal(t, []string{"api", "admin"}, calls)
+
+	calls = nil
+	resp = api.Get("/users")
+	assert.Equal(t, http.StatusNoContent, resp.Code)
+	assert.Equal(t, []string{"api", "handler"}, calls)
+
+	// Tag metadata is not part of the OpenAPI document.
+	b, err := api.OpenAPI().MarshalJSON()
+	require.NoError(t, err)
+	assert.NotContains(t, string(b), "Middlewares")
+}
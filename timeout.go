@@ -0,0 +1,83 @@
+package huma
+
+import (
+	"bytes"
+	"context"
+	"time"
+)
+
+// handlerResult holds the values returned by a handler run in the background
+// or the value it panicked with.
+type handlerResult struct {
+	output    any
+	err       error
+	recovered any
+}
+
+// callWithTimeout calls the handler with a context which is canceled after
+// the given timeout. If the handler has not returned by then, a 504 Gateway
+// Timeout error is returned and any late result from the handler is discarded,
+// so it can never write to the response. Panics in the handler are re-raised
+// in the calling goroutine so they can be recovered as usual. The `done`
+// function is called once the handler has actually returned, which may be
+// after this function has returned.
+func callWithTimeout(ctx context.Context, timeout time.Duration, input any, handler func(context.Context, any) (any, error), done func()) (any, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	// Buffered so the goroutine can always exit, even after a timeout.
+	results := make(chan handlerResult, 1)
+	go func() {
+		var result handlerResult
+		defer func() {
+			if r := recover(); r != nil {
+				result.recovered = r
+			}
+			done()
+			results <- result
+		}()
+		result.output, result.err = handler(ctx, input)
+	}()
+
+	select {
+	case result := <-results:
+		if result.recovered != nil {
+			panic(result.recovered)
+		}
+		if ctx.Err() == context.DeadlineExceeded {
+			// The handler gave up because of the deadline, e.g. by returning
+			// `ctx.Err()`, so report the timeout rather than its error.
+			return nil, Error504GatewayTimeout("operation timed out")
+		}
+		return result.output, result.err
+	case <-ctx.Done():
+		if ctx.Err() == context.DeadlineExceeded {
+			return nil, Error504GatewayTimeout("operation timed out")
+		}
+		// The client went away, so wait for the handler to stop.
+		result := <-results
+		if result.recovered != nil {
+			panic(result.recovered)
+		}
+		return result.output, result.err
+	}
+}
+
+// handlerResources are held for as long as the handler may be running, which
+// can be after the request has finished if the handler timed out.
+type handlerResources struct {
+	limiter *concurrencyLimiter
+	buf     *bytes.Buffer
+}
+
+// release releases any held resources.
+func (r *handlerResources) release() {
+	if r.limiter != nil {
+		r.limiter.release()
+		r.limiter = nil
+	}
+	if r.buf != nil {
+		releaseBuf(r.buf)
+		r.buf = nil
+	}
+}
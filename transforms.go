@@ -4,6 +4,9 @@ import (
 	"bytes"
 	"path"
 	"reflect"
+	"sort"
+	"strconv"
+	"strings"
 )
 
 type schemaField struct {
@@ -138,9 +141,7 @@ func (t *SchemaLinkTransformer) Transform(ctx Context, status string, v any) (an
 	host := ctx.Host()
 	ctx.AppendHeader("Link", info.header)
 
-	tmp := reflect.New(info.t).Elem()
-
-	// Set the `$schema` field.
+	// Build the `$schema` field value.
 	buf := bufPool.Get().(*bytes.Buffer)
 	if len(host) >= 9 && host[:9] == "localhost" {
 		buf.WriteString("http://")
@@ -149,10 +150,19 @@ func (t *SchemaLinkTransformer) Transform(ctx Context, status string, v any) (an
 	}
 	buf.WriteString(host)
 	buf.WriteString(info.ref)
-	tmp.Field(0).SetString(buf.String())
+	schemaURL := buf.String()
 	buf.Reset()
 	bufPool.Put(buf)
 
+	if e, ok := v.(*ErrorModel); ok && len(e.Extensions) > 0 {
+		// Copying the fields below would lose the custom serialization of the
+		// extension members, so add the `$schema` as one instead.
+		return e.withExtension("$schema", schemaURL), nil
+	}
+
+	tmp := reflect.New(info.t).Elem()
+	tmp.Field(0).SetString(schemaURL)
+
 	// Copy over all the exported fields.
 	vv := reflect.Indirect(reflect.ValueOf(v))
 	for _, i := range info.fields {
@@ -161,3 +171,219 @@ func (t *SchemaLinkTransformer) Transform(ctx Context, status string, v any) (an
 
 	return tmp.Addr().Interface(), nil
 }
+
+// ConditionalTransformer is a transformer which runs in priority order and
+// only when its conditions match. Unlike plain transformers, conditional
+// transformers do not run on error responses unless `Errors` is set.
+//
+//	config.ConditionalTransformers = append(config.ConditionalTransformers,
+//		huma.ConditionalTransformer{
+//			Transformer:  EnvelopeTransformer,
+//			Priority:     100,
+//			ContentTypes: []string{"application/json"},
+//		},
+//	)
+type ConditionalTransformer struct {
+	// Transformer is the function which modifies the response body.
+	Transformer Transformer
+
+	// Priority controls the order in which transformers run, with lower
+	// values running first. Transformers with the same priority run in the
+	// order they were added. Plain transformers from `Config.Transformers`
+	// have a priority of zero.
+	Priority int
+
+	// Operations limits the transformer to the operations with the given IDs.
+	// If empty, the transformer runs for all operations.
+	Operations []string
+
+	// ContentTypes limits the transformer to responses with the given
+	// negotiated content types, e.g. `application/json`. If empty, the
+	// transformer runs for all content types.
+	ContentTypes []string
+
+	// Errors enables running the transformer on error responses, i.e. those
+	// with a status code of 400 or above.
+	Errors bool
+}
+
+// matches returns whether the transformer should run for the given context
+// and response status.
+func (t *ConditionalTransformer) matches(api API, ctx Context, status string) bool {
+	if !t.Errors {
+		if code, err := strconv.Atoi(status); err == nil && code >= 400 {
+			return false
+		}
+	}
+
+	if len(t.Operations) > 0 {
+		op := ctx.Operation()
+		if op == nil || !slicesContains(t.Operations, op.OperationID) {
+			return false
+		}
+	}
+
+	if len(t.ContentTypes) > 0 {
+		ct, err := api.Negotiate(ctx.Header("Accept"))
+		if err != nil || !slicesContains(t.ContentTypes, ct) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// sortTransformers combines plain and conditional transformers into a single
+// list sorted by priority. Plain transformers always run, including on error
+// responses, to preserve their existing behavior.
+func sortTransformers(plain []Transformer, conditional []ConditionalTransformer) []ConditionalTransformer {
+	all := make([]ConditionalTransformer, 0, len(plain)+len(conditional))
+	for _, t := range plain {
+		all = append(all, ConditionalTransformer{Transformer: t, Errors: true})
+	}
+	all = append(all, conditional...)
+	sort.SliceStable(all, func(i, j int) bool {
+		return all[i].Priority < all[j].Priority
+	})
+	return all
+}
+
+// transformRequestBody runs the request transformers on the parsed body and
+// re-encodes the result using the request's content type, returning both the
+// transformed value and its encoded bytes.
+func transformRequestBody(api API, ctx Context, transformers []RequestTransformer, parsed any) (any, []byte, error) {
+	var err error
+	for _, t := range transformers {
+		if parsed, err = t(ctx, parsed); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	ct := ctx.Header("Content-Type")
+	if end := strings.IndexRune(ct, ';'); end != -1 {
+		ct = ct[:end]
+	}
+	if ct == "" {
+		ct = "application/json"
+	}
+
+	buf := &bytes.Buffer{}
+	if err := api.Marshal(buf, ct, parsed); err != nil {
+		return nil, nil, err
+	}
+	return parsed, buf.Bytes(), nil
+}
+
+// envelope is the wrapper for successful response bodies.
+type envelope struct {
+	Data any            `json:"data"`
+	Meta map[string]any `json:"meta"`
+}
+
+// errorEnvelope is the wrapper for error response bodies.
+type errorEnvelope struct {
+	Errors []any `json:"errors"`
+}
+
+// EnvelopeTransformer is an opt-in transform that wraps successful response
+// bodies in `{"data": ..., "meta": {...}}` and error response bodies in
+// `{"errors": [...]}`, for APIs with a mandated envelope format. Use
+// `OnAddOperation` to update the generated response schemas to match.
+//
+//	envelope := huma.NewEnvelopeTransformer(nil)
+//	config.OnAddOperation = append(config.OnAddOperation, envelope.OnAddOperation)
+//	config.Transformers = append(config.Transformers, envelope.Transform)
+type EnvelopeTransformer struct {
+	meta    func(ctx Context) map[string]any
+	wrapped map[*Schema]bool
+}
+
+// NewEnvelopeTransformer creates a new transformer that wraps response bodies
+// in an envelope. The optional `meta` function returns the value of the
+// `meta` field for successful responses, e.g. the request ID or paging info.
+func NewEnvelopeTransformer(meta func(ctx Context) map[string]any) *EnvelopeTransformer {
+	return &EnvelopeTransformer{
+		meta:    meta,
+		wrapped: map[*Schema]bool{},
+	}
+}
+
+// OnAddOperation is triggered whenever a new operation is added to the API,
+// enabling this transformer to wrap the documented response schemas.
+func (t *EnvelopeTransformer) OnAddOperation(oapi *OpenAPI, op *Operation) {
+	for status, resp := range op.Responses {
+		isError := status == "default"
+		if code, err := strconv.Atoi(status); err == nil && code >= 400 {
+			isError = true
+		}
+
+		for _, content := range resp.Content {
+			if content == nil || content.Schema == nil || t.wrapped[content.Schema] {
+				continue
+			}
+
+			if isError {
+				content.Schema = &Schema{
+					Type:     TypeObject,
+					Required: []string{"errors"},
+					Properties: map[string]*Schema{
+						"errors": {
+							Type:  TypeArray,
+							Items: content.Schema,
+						},
+					},
+				}
+			} else {
+				content.Schema = &Schema{
+					Type:     TypeObject,
+					Required: []string{"data", "meta"},
+					Properties: map[string]*Schema{
+						"data": content.Schema,
+						"meta": {
+							Type:                 TypeObject,
+							Description:          "Additional information about the response.",
+							AdditionalProperties: true,
+						},
+					},
+				}
+			}
+			t.wrapped[content.Schema] = true
+		}
+	}
+}
+
+// Transform is called for every response to wrap the body in an envelope.
+// Raw `[]byte` bodies are not modified.
+func (t *EnvelopeTransformer) Transform(ctx Context, status string, v any) (any, error) {
+	if v == nil {
+		return v, nil
+	}
+
+	if _, ok := v.([]byte); ok {
+		return v, nil
+	}
+
+	if code, err := strconv.Atoi(status); err == nil && code >= 400 {
+		return &errorEnvelope{Errors: []any{v}}, nil
+	}
+
+	var meta map[string]any
+	if t.meta != nil {
+		meta = t.meta(ctx)
+	}
+	if meta == nil {
+		meta = map[string]any{}
+	}
+
+	if warnings := ValidationWarningsFromContext(ctx.Context()); len(warnings) > 0 {
+		// Copy so the map returned by the meta function isn't modified.
+		withWarnings := make(map[string]any, len(meta)+1)
+		for k, v := range meta {
+			withWarnings[k] = v
+		}
+		withWarnings["warnings"] = warnings
+		meta = withWarnings
+	}
+
+	return &envelope{Data: v, Meta: meta}, nil
+}
@@ -0,0 +1,150 @@
+package huma_test
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/humatest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnvelopeTransformer(t *testing.T) {
+	envelope := huma.NewEnvelopeTransformer(func(ctx huma.Context) map[string]any {
+		return map[string]any{"operation": ctx.Operation().OperationID}
+	})
+
+	config := huma.DefaultConfig("Test API", "1.0.0")
+	config.OnAddOperation = append(config.OnAddOperation, envelope.OnAddOperation)
+	config.Transformers = append(config.Transformers, envelope.Transform)
+	_, api := humatest.New(t, config)
+
+	type Output struct {
+		Body struct {
+			Name string `json:"name"`
+		}
+	}
+
+	huma.Register(api, huma.Operation{
+		OperationID: "get-thing",
+		Method:      http.MethodGet,
+		Path:        "/thing",
+	}, func(ctx context.Context, input *struct{}) (*Output, error) {
+		resp := &Output{}
+		resp.Body.Name = "foo"
+		return resp, nil
+	})
+
+	huma.Register(api, huma.Operation{
+		OperationID: "get-error",
+		Method:      http.MethodGet,
+		Path:        "/error",
+	}, func(ctx context.Context, input *struct{}) (*Output, error) {
+		return nil, huma.Error404NotFound("missing")
+	})
+
+	resp := api.Get("/thing")
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Contains(t, resp.Body.String(), `{"data":{`)
+	assert.Contains(t, resp.Body.String(), `"name":"foo"`)
+	assert.Contains(t, resp.Body.String(), `"meta":{"operation":"get-thing"}`)
+
+	resp = api.Get("/error")
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+	assert.Contains(t, resp.Body.String(), `{"errors":[{`)
+	assert.Contains(t, resp.Body.String(), `"detail":"missing"`)
+
+	// Schemas are wrapped to match the envelope.
+	op := api.OpenAPI().Paths["/thing"].Get
+	schema := op.Responses["200"].Content["application/json"].Schema
+	assert.Contains(t, schema.Required, "data")
+	assert.Equal(t, "#/components/schemas/OutputBody", schema.Properties["data"].Ref)
+
+	schema = op.Responses["default"].Content["application/problem+json"].Schema
+	assert.Equal(t, huma.TypeArray, schema.Properties["errors"].Type)
+	assert.Equal(t, "#/components/schemas/ErrorModel", schema.Properties["errors"].Items.Ref)
+}
+
+type fieldSelectItem struct {
+	ID       string            `json:"id"`
+	Name     string            `json:"name"`
+	Secret   string            `json:"-"`
+	Tags     []string          `json:"tags,omitempty"`
+	Labels   map[string]string `json:"labels,omitempty"`
+	Created  time.Time         `json:"created"`
+	Internal *fieldSelectItem  `json:"internal,omitempty"`
+}
+
+type FieldSelectMeta struct {
+	Total int `json:"total"`
+}
+
+type fieldSelectBody struct {
+	FieldSelectMeta
+	Items []fieldSelectItem `json:"items"`
+	Meta  map[string]any    `json:"meta"`
+}
+
+func TestFieldSelectTransformer(t *testing.T) {
+	fields := huma.NewFieldSelectTransformer("")
+
+	config := huma.DefaultConfig("Test API", "1.0.0")
+	config.OnAddOperation = append(config.OnAddOperation, fields.OnAddOperation)
+	config.Transformers = append(config.Transformers, fields.Transform)
+	_, api := humatest.New(t, config)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "list-things",
+		Method:      http.MethodGet,
+		Path:        "/things",
+	}, func(ctx context.Context, input *struct {
+		Fail bool `query:"fail"`
+	}) (*struct{ Body fieldSelectBody }, error) {
+		if input.Fail {
+			return nil, huma.Error400BadRequest("failed")
+		}
+		resp := &struct{ Body fieldSelectBody }{}
+		resp.Body.Total = 2
+		resp.Body.Items = []fieldSelectItem{
+			{ID: "a", Name: "A", Secret: "s", Tags: []string{"x"}, Labels: map[string]string{"env": "prod"}},
+			{ID: "b", Name: "B", Created: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), Internal: &fieldSelectItem{ID: "c"}},
+		}
+		resp.Body.Meta = map[string]any{"page": 1, "cursor": map[string]any{"next": "n", "prev": "p"}}
+		return resp, nil
+	})
+
+	for _, tc := range []struct {
+		name     string
+		fields   string
+		expected string
+	}{
+		{"dotted", "items.id,items.name,meta", `{"items": [{"id": "a", "name": "A"}, {"id": "b", "name": "B"}], "meta": {"page": 1, "cursor": {"next": "n", "prev": "p"}}}`},
+		{"jmespath", "items[*].id,meta.cursor.next", `{"items": [{"id": "a"}, {"id": "b"}], "meta": {"cursor": {"next": "n"}}}`},
+		{"pointer", "/items/labels/env,/total", `{"items": [{"labels": {"env": "prod"}}, {}], "total": 2}`},
+		{"nested", "items.internal.id,items.created", `{"items": [{"created": "0001-01-01T00:00:00Z"}, {"created": "2024-01-02T00:00:00Z", "internal": {"id": "c"}}]}`},
+		{"overlap", "items.id,items", `{"items": [{"id": "a", "name": "A", "tags": ["x"], "labels": {"env": "prod"}, "created": "0001-01-01T00:00:00Z"}, {"id": "b", "name": "B", "created": "2024-01-02T00:00:00Z", "internal": {"id": "c", "name": "", "created": "0001-01-01T00:00:00Z"}}]}`},
+		{"unknown", "items.Secret,missing", `{"items": [{}, {}]}`},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			resp := api.Get("/things?fields=" + url.QueryEscape(tc.fields))
+			assert.Equal(t, http.StatusOK, resp.Code)
+			assert.JSONEq(t, tc.expected, resp.Body.String())
+		})
+	}
+
+	// Without the param the full body is sent.
+	resp := api.Get("/things")
+	assert.Contains(t, resp.Body.String(), `"total":2`)
+	assert.Contains(t, resp.Body.String(), `"tags":["x"]`)
+
+	// Errors are not modified.
+	resp = api.Get("/things?fail=true&fields=items")
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+	assert.Contains(t, resp.Body.String(), `"detail":"failed"`)
+
+	params := api.OpenAPI().Paths["/things"].Get.Parameters
+	assert.Equal(t, "fields", params[len(params)-1].Name)
+}
@@ -4,8 +4,8 @@ import (
 	"errors"
 	"fmt"
 	"math"
-	"net"
 	"net/mail"
+	"net/netip"
 	"net/url"
 	"reflect"
 	"regexp"
@@ -63,6 +63,10 @@ func mapTo[A, B any](s []A, f func(A) B) []B {
 type PathBuffer struct {
 	buf []byte
 	off int
+
+	// starts are the offsets of pushed entries, so that entries like map keys
+	// containing separators can be popped.
+	starts []int
 }
 
 // Push an entry onto the path, adding a `.` separator as needed.
@@ -70,6 +74,7 @@ type PathBuffer struct {
 //	pb.Push("foo") // foo
 //	pb.Push("bar") // foo.bar
 func (b *PathBuffer) Push(s string) {
+	b.starts = append(b.starts, b.off)
 	if b.off > 0 {
 		b.buf = append(b.buf, '.')
 		b.off++
@@ -83,6 +88,7 @@ func (b *PathBuffer) Push(s string) {
 //	pb.Push("foo")  // foo
 //	pb.PushIndex(1) // foo[1]
 func (b *PathBuffer) PushIndex(i int) {
+	b.starts = append(b.starts, b.off)
 	l := len(b.buf)
 	b.buf = append(b.buf, '[')
 	b.buf = append(b.buf, strconv.Itoa(i)...)
@@ -90,6 +96,20 @@ func (b *PathBuffer) PushIndex(i int) {
 	b.off += len(b.buf) - l
 }
 
+// PushKey pushes a map key onto the path as a quoted string surrounded by
+// `[` and `]`, so keys containing separators are unambiguous.
+//
+//	pb.Push("foo")     // foo
+//	pb.PushKey("a.b") // foo["a.b"]
+func (b *PathBuffer) PushKey(key string) {
+	b.starts = append(b.starts, b.off)
+	l := len(b.buf)
+	b.buf = append(b.buf, '[')
+	b.buf = strconv.AppendQuote(b.buf, key)
+	b.buf = append(b.buf, ']')
+	b.off += len(b.buf) - l
+}
+
 // Pop the latest entry off the path.
 //
 //	pb.Push("foo")  // foo
@@ -98,6 +118,13 @@ func (b *PathBuffer) PushIndex(i int) {
 //	pb.Pop()        // foo[1]
 //	pb.Pop()        // foo
 func (b *PathBuffer) Pop() {
+	if n := len(b.starts); n > 0 {
+		b.off = b.starts[n-1]
+		b.starts = b.starts[:n-1]
+		b.buf = b.buf[:b.off]
+		return
+	}
+	// Entries in a buffer created with an offset are found by their separator.
 	for b.off > 0 {
 		b.off--
 		if b.buf[b.off] == '.' || b.buf[b.off] == '[' {
@@ -138,6 +165,7 @@ func (b *PathBuffer) String() string {
 func (b *PathBuffer) Reset() {
 	b.buf = b.buf[:0]
 	b.off = 0
+	b.starts = b.starts[:0]
 }
 
 // NewPathBuffer creates a new path buffer given an existing byte slice.
@@ -149,35 +177,89 @@ func NewPathBuffer(buf []byte, offset int) *PathBuffer {
 	return &PathBuffer{buf: buf, off: offset}
 }
 
+// maxPooledDetails limits how many error details a pooled validation result
+// keeps around between requests.
+const maxPooledDetails = 256
+
 // ValidateResult tracks validation errors. It is safe to use for multiple
 // validations as long as `Reset()` is called between uses.
 type ValidateResult struct {
 	Errors []error
+
+	// details is a reusable slab of error details used by the request handlers
+	// so that bad requests don't allocate a new detail per error. Details are
+	// only valid until `Reset()` is called.
+	details []ErrorDetail
+	pooled  bool
+}
+
+// detail returns a new error detail, reusing one from the slab if pooled.
+func (r *ValidateResult) detail() *ErrorDetail {
+	if !r.pooled {
+		return &ErrorDetail{}
+	}
+	if len(r.details) == cap(r.details) {
+		// Start a new slab. Details from the old one are still referenced by
+		// `Errors` so they must not be moved.
+		size := 2 * cap(r.details)
+		if size < 8 {
+			size = 8
+		}
+		r.details = make([]ErrorDetail, 0, size)
+	}
+	r.details = r.details[:len(r.details)+1]
+	return &r.details[len(r.details)-1]
 }
 
 // Add an error to the validation result at the given path and with the
 // given value.
 func (r *ValidateResult) Add(path *PathBuffer, v any, msg string) {
-	r.Errors = append(r.Errors, &ErrorDetail{
+	d := r.detail()
+	*d = ErrorDetail{
 		Message:  msg,
 		Location: path.String(),
 		Value:    v,
-	})
+	}
+	r.Errors = append(r.Errors, d)
+}
+
+// addKeyword adds an error for a failed schema keyword like `maxLength`, so
+// operations can choose to only warn about some keywords via
+// `Operation.LenientValidation`.
+func (r *ValidateResult) addKeyword(path *PathBuffer, v any, keyword, msg string) {
+	r.Add(path, v, msg)
+	r.Errors[len(r.Errors)-1].(*ErrorDetail).keyword = keyword
 }
 
 // Addf adds an error to the validation result at the given path and with
 // the given value, allowing for fmt.Printf-style formatting.
 func (r *ValidateResult) Addf(path *PathBuffer, v any, format string, args ...any) {
-	r.Errors = append(r.Errors, &ErrorDetail{
-		Message:  fmt.Sprintf(format, args...),
-		Location: path.String(),
-		Value:    v,
-	})
+	r.Add(path, v, fmt.Sprintf(format, args...))
+}
+
+// redactFrom replaces the values of errors added since `start` with
+// `huma.Redacted` so that sensitive input is not echoed back to clients or
+// written to logs.
+func (r *ValidateResult) redactFrom(start int) {
+	for _, err := range r.Errors[start:] {
+		if d, ok := err.(*ErrorDetail); ok {
+			d.Value = Redacted
+		}
+	}
 }
 
 // Reset the validation error so it can be used again.
 func (r *ValidateResult) Reset() {
 	r.Errors = r.Errors[:0]
+	if cap(r.details) > maxPooledDetails {
+		// Don't hold on to memory from a single abusive request.
+		r.details = nil
+	}
+	for i := range r.details {
+		// Clear references so values can be garbage collected.
+		r.details[i] = ErrorDetail{}
+	}
+	r.details = r.details[:0]
 }
 
 func validateFormat(path *PathBuffer, str string, s *Schema, res *ValidateResult) {
@@ -191,69 +273,81 @@ func validateFormat(path *PathBuffer, str string, s *Schema, res *ValidateResult
 			}
 		}
 		if !found {
-			res.Add(path, str, "expected string to be RFC 3339 date-time")
+			res.addKeyword(path, str, "format", "expected string to be RFC 3339 date-time")
 		}
 	case "date-time-http":
 		if _, err := time.Parse(time.RFC1123, str); err != nil {
-			res.Add(path, str, "expected string to be RFC 1123 date-time")
+			res.addKeyword(path, str, "format", "expected string to be RFC 1123 date-time")
 		}
 	case "date":
 		if _, err := time.Parse("2006-01-02", str); err != nil {
-			res.Add(path, str, "expected string to be RFC 3339 date")
+			res.addKeyword(path, str, "format", "expected string to be RFC 3339 date")
 		}
 	case "time":
 		if _, err := time.Parse("15:04:05", str); err != nil {
 			if _, err := time.Parse("15:04:05Z07:00", str); err != nil {
-				res.Add(path, str, "expected string to be RFC 3339 time")
+				res.addKeyword(path, str, "format", "expected string to be RFC 3339 time")
 			}
 		}
 		// TODO: duration
 	case "email", "idn-email":
 		if _, err := mail.ParseAddress(str); err != nil {
-			res.Addf(path, str, "expected string to be RFC 5322 email: %v", err)
+			res.addKeyword(path, str, "format", fmt.Sprintf("expected string to be RFC 5322 email: %v", err))
 		}
 	case "hostname":
 		if !(rxHostname.MatchString(str) && len(str) < 256) {
-			res.Add(path, str, "expected string to be RFC 5890 hostname")
+			res.addKeyword(path, str, "format", "expected string to be RFC 5890 hostname")
 		}
 	// TODO: proper idn-hostname support... need to figure out how.
 	case "ipv4":
-		if ip := net.ParseIP(str); ip == nil || ip.To4() == nil {
-			res.Add(path, str, "expected string to be RFC 2673 ipv4")
+		if ip, err := netip.ParseAddr(str); err != nil || !ip.Is4() {
+			res.addKeyword(path, str, "format", "expected string to be RFC 2673 ipv4")
 		}
 	case "ipv6":
-		if ip := net.ParseIP(str); ip == nil || ip.To16() == nil {
-			res.Add(path, str, "expected string to be RFC 2373 ipv6")
+		if ip, err := netip.ParseAddr(str); err != nil || !ip.Is6() {
+			res.addKeyword(path, str, "format", "expected string to be RFC 2373 ipv6")
+		}
+	case "ip":
+		if _, err := netip.ParseAddr(str); err != nil {
+			res.addKeyword(path, str, "format", "expected string to be ipv4 or ipv6")
+		}
+	case "cidr":
+		if _, err := netip.ParsePrefix(str); err != nil {
+			res.addKeyword(path, str, "format", "expected string to be RFC 4632 cidr")
 		}
 	case "uri", "uri-reference", "iri", "iri-reference":
 		if _, err := url.Parse(str); err != nil {
-			res.Addf(path, str, "expected string to be RFC 3986 uri: %v", err)
+			res.addKeyword(path, str, "format", fmt.Sprintf("expected string to be RFC 3986 uri: %v", err))
 		}
 		// TODO: check if it's actually a reference?
 	case "uuid":
 		if err := validateUUID(str); err != nil {
-			res.Addf(path, str, "expected string to be RFC 4122 uuid: %v", err)
+			res.addKeyword(path, str, "format", fmt.Sprintf("expected string to be RFC 4122 uuid: %v", err))
 		}
 	case "uri-template":
 		u, err := url.Parse(str)
 		if err != nil {
-			res.Addf(path, str, "expected string to be RFC 3986 uri: %v", err)
+			res.addKeyword(path, str, "format", fmt.Sprintf("expected string to be RFC 3986 uri: %v", err))
 			return
 		}
 		if !rxURITemplate.MatchString(u.Path) {
-			res.Add(path, str, "expected string to be RFC 6570 uri-template")
+			res.addKeyword(path, str, "format", "expected string to be RFC 6570 uri-template")
 		}
 	case "json-pointer":
 		if !rxJSONPointer.MatchString(str) {
-			res.Add(path, str, "expected string to be RFC 6901 json-pointer")
+			res.addKeyword(path, str, "format", "expected string to be RFC 6901 json-pointer")
 		}
 	case "relative-json-pointer":
 		if !rxRelJSONPointer.MatchString(str) {
-			res.Add(path, str, "expected string to be RFC 6901 relative-json-pointer")
+			res.addKeyword(path, str, "format", "expected string to be RFC 6901 relative-json-pointer")
 		}
 	case "regex":
 		if _, err := regexp.Compile(str); err != nil {
-			res.Addf(path, str, "expected string to be regex: %v", err)
+			res.addKeyword(path, str, "format", fmt.Sprintf("expected string to be regex: %v", err))
+		}
+	case DecimalFormat:
+		if !isDecimal(str) {
+			res.addKeyword(path, str, "format", "expected string to be decimal number")
 		}
 	}
 }
@@ -265,14 +359,14 @@ func validateOneOf(r Registry, s *Schema, path *PathBuffer, mode ValidateMode, v
 		Validate(r, sub, path, mode, v, subRes)
 		if len(subRes.Errors) == 0 {
 			if found {
-				res.Add(path, v, "expected value to match exactly one schema but matched multiple")
+				res.addKeyword(path, v, "oneOf", "expected value to match exactly one schema but matched multiple")
 			}
 			found = true
 		}
 		subRes.Reset()
 	}
 	if !found {
-		res.Add(path, v, "expected value to match exactly one schema but matched none")
+		res.addKeyword(path, v, "oneOf", "expected value to match exactly one schema but matched none")
 	}
 }
 
@@ -288,7 +382,7 @@ func validateAnyOf(r Registry, s *Schema, path *PathBuffer, mode ValidateMode, v
 	}
 
 	if matches == 0 {
-		res.Add(path, v, "expected value to match at least one schema but matched none")
+		res.addKeyword(path, v, "anyOf", "expected value to match at least one schema but matched none")
 	}
 }
 
@@ -309,6 +403,10 @@ func validateAnyOf(r Registry, s *Schema, path *PathBuffer, mode ValidateMode, v
 //		fmt.Println(err.Error())
 //	}
 func Validate(r Registry, s *Schema, path *PathBuffer, mode ValidateMode, v any, res *ValidateResult) {
+	if s.IsSensitive() {
+		defer res.redactFrom(len(res.Errors))
+	}
+
 	// Get the actual schema if this is a reference.
 	for s.Ref != "" {
 		s = r.SchemaFromRef(s.Ref)
@@ -332,7 +430,7 @@ func Validate(r Registry, s *Schema, path *PathBuffer, mode ValidateMode, v any,
 		subRes := &ValidateResult{}
 		Validate(r, s.Not, path, mode, v, subRes)
 		if len(subRes.Errors) == 0 {
-			res.Add(path, v, "expected value to not match schema")
+			res.addKeyword(path, v, "not", "expected value to not match schema")
 		}
 	}
 
@@ -377,27 +475,27 @@ func Validate(r Registry, s *Schema, path *PathBuffer, mode ValidateMode, v any,
 
 		if s.Minimum != nil {
 			if num < *s.Minimum {
-				res.Addf(path, v, s.msgMinimum)
+				res.addKeyword(path, v, "minimum", s.msgMinimum)
 			}
 		}
 		if s.ExclusiveMinimum != nil {
 			if num <= *s.ExclusiveMinimum {
-				res.Addf(path, v, s.msgExclusiveMinimum)
+				res.addKeyword(path, v, "exclusiveMinimum", s.msgExclusiveMinimum)
 			}
 		}
 		if s.Maximum != nil {
 			if num > *s.Maximum {
-				res.Add(path, v, s.msgMaximum)
+				res.addKeyword(path, v, "maximum", s.msgMaximum)
 			}
 		}
 		if s.ExclusiveMaximum != nil {
 			if num >= *s.ExclusiveMaximum {
-				res.Addf(path, v, s.msgExclusiveMaximum)
+				res.addKeyword(path, v, "exclusiveMaximum", s.msgExclusiveMaximum)
 			}
 		}
 		if s.MultipleOf != nil {
 			if math.Mod(num, *s.MultipleOf) != 0 {
-				res.Addf(path, v, s.msgMultipleOf)
+				res.addKeyword(path, v, "multipleOf", s.msgMultipleOf)
 			}
 		}
 	case TypeString:
@@ -413,17 +511,17 @@ func Validate(r Registry, s *Schema, path *PathBuffer, mode ValidateMode, v any,
 
 		if s.MinLength != nil {
 			if len(str) < *s.MinLength {
-				res.Addf(path, str, s.msgMinLength)
+				res.addKeyword(path, str, "minLength", s.msgMinLength)
 			}
 		}
 		if s.MaxLength != nil {
 			if len(str) > *s.MaxLength {
-				res.Add(path, str, s.msgMaxLength)
+				res.addKeyword(path, str, "maxLength", s.msgMaxLength)
 			}
 		}
 		if s.patternRe != nil {
 			if !s.patternRe.MatchString(str) {
-				res.Add(path, v, s.msgPattern)
+				res.addKeyword(path, v, "pattern", s.msgPattern)
 			}
 		}
 
@@ -433,7 +531,7 @@ func Validate(r Registry, s *Schema, path *PathBuffer, mode ValidateMode, v any,
 
 		if s.ContentEncoding == "base64" {
 			if !rxBase64.MatchString(str) {
-				res.Add(path, str, "expected string to be base64 encoded")
+				res.addKeyword(path, str, "contentEncoding", "expected string to be base64 encoded")
 			}
 		}
 	case TypeArray:
@@ -489,7 +587,7 @@ func Validate(r Registry, s *Schema, path *PathBuffer, mode ValidateMode, v any,
 			}
 		}
 		if !found {
-			res.Add(path, v, s.msgEnum)
+			res.addKeyword(path, v, "enum", s.msgEnum)
 		}
 	}
 }
@@ -497,12 +595,12 @@ func Validate(r Registry, s *Schema, path *PathBuffer, mode ValidateMode, v any,
 func handleArray[T any](r Registry, s *Schema, path *PathBuffer, mode ValidateMode, res *ValidateResult, arr []T) {
 	if s.MinItems != nil {
 		if len(arr) < *s.MinItems {
-			res.Addf(path, arr, s.msgMinItems)
+			res.addKeyword(path, arr, "minItems", s.msgMinItems)
 		}
 	}
 	if s.MaxItems != nil {
 		if len(arr) > *s.MaxItems {
-			res.Addf(path, arr, s.msgMaxItems)
+			res.addKeyword(path, arr, "maxItems", s.msgMaxItems)
 		}
 	}
 
@@ -510,7 +608,7 @@ func handleArray[T any](r Registry, s *Schema, path *PathBuffer, mode ValidateMo
 		seen := make(map[any]struct{}, len(arr))
 		for _, item := range arr {
 			if _, ok := seen[item]; ok {
-				res.Add(path, arr, "expected array items to be unique")
+				res.addKeyword(path, arr, "uniqueItems", "expected array items to be unique")
 			}
 			seen[item] = struct{}{}
 		}
@@ -526,12 +624,12 @@ func handleArray[T any](r Registry, s *Schema, path *PathBuffer, mode ValidateMo
 func handleMapString(r Registry, s *Schema, path *PathBuffer, mode ValidateMode, m map[string]any, res *ValidateResult) {
 	if s.MinProperties != nil {
 		if len(m) < *s.MinProperties {
-			res.Add(path, m, s.msgMinProperties)
+			res.addKeyword(path, m, "minProperties", s.msgMinProperties)
 		}
 	}
 	if s.MaxProperties != nil {
 		if len(m) > *s.MaxProperties {
-			res.Add(path, m, s.msgMaxProperties)
+			res.addKeyword(path, m, "maxProperties", s.msgMaxProperties)
 		}
 	}
 
@@ -560,7 +658,7 @@ func handleMapString(r Registry, s *Schema, path *PathBuffer, mode ValidateMode,
 				// These are not required for the current mode.
 				continue
 			}
-			res.Add(path, m, s.msgRequired[k])
+			res.addKeyword(path, m, "required", s.msgRequired[k])
 			continue
 		}
 
@@ -574,7 +672,7 @@ func handleMapString(r Registry, s *Schema, path *PathBuffer, mode ValidateMode,
 			// No additional properties allowed.
 			if _, ok := s.Properties[k]; !ok {
 				path.Push(k)
-				res.Add(path, m, "unexpected property")
+				res.addKeyword(path, m, "additionalProperties", "unexpected property")
 				path.Pop()
 			}
 		}
@@ -597,12 +695,12 @@ func handleMapString(r Registry, s *Schema, path *PathBuffer, mode ValidateMode,
 func handleMapAny(r Registry, s *Schema, path *PathBuffer, mode ValidateMode, m map[any]any, res *ValidateResult) {
 	if s.MinProperties != nil {
 		if len(m) < *s.MinProperties {
-			res.Add(path, m, s.msgMinProperties)
+			res.addKeyword(path, m, "minProperties", s.msgMinProperties)
 		}
 	}
 	if s.MaxProperties != nil {
 		if len(m) > *s.MaxProperties {
-			res.Add(path, m, s.msgMaxProperties)
+			res.addKeyword(path, m, "maxProperties", s.msgMaxProperties)
 		}
 	}
 
@@ -631,7 +729,7 @@ func handleMapAny(r Registry, s *Schema, path *PathBuffer, mode ValidateMode, m
 				// These are not required for the current mode.
 				continue
 			}
-			res.Add(path, m, s.msgRequired[k])
+			res.addKeyword(path, m, "required", s.msgRequired[k])
 			continue
 		}
 
@@ -651,7 +749,7 @@ func handleMapAny(r Registry, s *Schema, path *PathBuffer, mode ValidateMode, m
 			}
 			if _, ok := s.Properties[kStr]; !ok {
 				path.Push(kStr)
-				res.Add(path, m, "unexpected property")
+				res.addKeyword(path, m, "additionalProperties", "unexpected property")
 				path.Pop()
 			}
 		}
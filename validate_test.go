@@ -3,6 +3,7 @@ package huma_test
 import (
 	"encoding/json"
 	"fmt"
+	"net/netip"
 	"reflect"
 	"strings"
 	"testing"
@@ -407,6 +408,44 @@ var validateTests = []struct {
 		input: map[string]any{"value": "1234"},
 		errs:  []string{"expected string to be RFC 2373 ipv6"},
 	},
+	{
+		name: "expected ipv6 not ipv4",
+		typ: reflect.TypeOf(struct {
+			Value string `json:"value" format:"ipv6"`
+		}{}),
+		input: map[string]any{"value": "127.0.0.1"},
+		errs:  []string{"expected string to be RFC 2373 ipv6"},
+	},
+	{
+		name: "ip success",
+		typ: reflect.TypeOf(struct {
+			Value netip.Addr `json:"value"`
+		}{}),
+		input: map[string]any{"value": "::1"},
+	},
+	{
+		name: "expected ip",
+		typ: reflect.TypeOf(struct {
+			Value netip.Addr `json:"value"`
+		}{}),
+		input: map[string]any{"value": "1234"},
+		errs:  []string{"expected string to be ipv4 or ipv6"},
+	},
+	{
+		name: "cidr success",
+		typ: reflect.TypeOf(struct {
+			Value netip.Prefix `json:"value"`
+		}{}),
+		input: map[string]any{"value": "2001:db8::/32"},
+	},
+	{
+		name: "expected cidr",
+		typ: reflect.TypeOf(struct {
+			Value netip.Prefix `json:"value"`
+		}{}),
+		input: map[string]any{"value": "10.0.0.1"},
+		errs:  []string{"expected string to be RFC 4632 cidr"},
+	},
 	{
 		name: "uri success",
 		typ: reflect.TypeOf(struct {
@@ -1200,3 +1239,21 @@ func BenchmarkValidate(b *testing.B) {
 		})
 	}
 }
+
+func TestPathBufferKeys(t *testing.T) {
+	pb := huma.NewPathBuffer([]byte(""), 0)
+	pb.Push("body")
+	pb.Push("items")
+	pb.PushKey(`a.b["c`)
+	pb.PushIndex(0)
+	assert.Equal(t, `body.items["a.b[\"c"][0]`, pb.String())
+	pb.Pop()
+	pb.Pop()
+	assert.Equal(t, "body.items", pb.String())
+	assert.Equal(t, `body.items.name`, pb.With("name"))
+
+	// Buffers created with an existing path can pop its entries.
+	pb = huma.NewPathBuffer([]byte("body.items"), len("body.items"))
+	pb.Pop()
+	assert.Equal(t, "body", pb.String())
+}
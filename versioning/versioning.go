@@ -0,0 +1,391 @@
+// Package versioning routes requests for the same method and path to
+// different operations based on the requested API version, which clients
+// send via a header like `Api-Version: 2` or a versioned media type like
+// `Accept: application/vnd.myapi.v2+json`. Each version is documented in its
+// own OpenAPI document.
+//
+//	versions := versioning.New(api, versioning.Config{
+//		MediaType: "application/vnd.myapi",
+//		Default:   "1",
+//	})
+//
+//	huma.Register(versions.Version("1"), huma.Operation{
+//		OperationID: "get-thing",
+//		Method:      http.MethodGet,
+//		Path:        "/things/{id}",
+//	}, getThingV1)
+//
+//	huma.Register(versions.Version("2"), huma.Operation{
+//		OperationID: "get-thing",
+//		Method:      http.MethodGet,
+//		Path:        "/things/{id}",
+//	}, getThingV2)
+package versioning
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// Config configures API versioning.
+type Config struct {
+	// Header is the request header containing the requested version. It is
+	// also set on responses to the version which handled the request.
+	// Defaults to `Api-Version`.
+	Header string
+
+	// MediaType is the vendor media type prefix used for versioned media
+	// types, e.g. `application/vnd.myapi` to accept
+	// `application/vnd.myapi.v2+json`. Versioned media types are matched in
+	// the `Accept` and `Content-Type` headers. If empty, only the header is
+	// used.
+	MediaType string
+
+	// Default is the version used when a request does not specify one. If
+	// empty, such requests receive a `400 Bad Request` error.
+	Default string
+}
+
+// Versions registers operations for multiple versions of an API.
+type Versions struct {
+	api    huma.API
+	config Config
+
+	mu       sync.Mutex
+	versions map[string]*versionAPI
+	routes   map[string]*route
+}
+
+// New creates a set of API versions on top of an existing API, whose
+// middleware and configuration are shared by all versions.
+func New(api huma.API, config Config) *Versions {
+	if config.Header == "" {
+		config.Header = "Api-Version"
+	}
+	return &Versions{
+		api:      api,
+		config:   config,
+		versions: map[string]*versionAPI{},
+		routes:   map[string]*route{},
+	}
+}
+
+// Version returns the API to register a version's operations with. Each
+// version has its own OpenAPI document, which is served at
+// `{OpenAPIPath}/{version}.json` and `.yaml` when the API's `OpenAPIPath` is
+// set. Operations registered directly with the underlying API are shared
+// by all versions, but are only documented in its OpenAPI.
+func (v *Versions) Version(version string) huma.API {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if a := v.versions[version]; a != nil {
+		return a
+	}
+	a := &versionAPI{
+		API:      v.api,
+		versions: v,
+		version:  version,
+		oapi:     v.newOpenAPI(version),
+	}
+	v.versions[version] = a
+
+	if path := openAPIPath(v.api); path != "" {
+		v.api.Adapter().Handle(&huma.Operation{
+			Method: http.MethodGet,
+			Path:   path + "/" + version + ".json",
+		}, func(ctx huma.Context) {
+			b, _ := a.oapi.MarshalJSON()
+			ctx.SetHeader("Content-Type", "application/vnd.oai.openapi+json")
+			ctx.BodyWriter().Write(b)
+		})
+		v.api.Adapter().Handle(&huma.Operation{
+			Method: http.MethodGet,
+			Path:   path + "/" + version + ".yaml",
+		}, func(ctx huma.Context) {
+			b, _ := a.oapi.YAML()
+			ctx.SetHeader("Content-Type", "application/vnd.oai.openapi+yaml")
+			ctx.BodyWriter().Write(b)
+		})
+	}
+	return a
+}
+
+// newOpenAPI creates the OpenAPI document for a version. It shares the
+// API's info, servers, and security schemes, but has its own schemas so
+// that versions can use different types with the same name.
+func (v *Versions) newOpenAPI(version string) *huma.OpenAPI {
+	parent := v.api.OpenAPI()
+	oapi := &huma.OpenAPI{
+		OpenAPI:           parent.OpenAPI,
+		JSONSchemaDialect: parent.JSONSchemaDialect,
+		Servers:           parent.Servers,
+		Security:          parent.Security,
+		Tags:              parent.Tags,
+		ExternalDocs:      parent.ExternalDocs,
+		OnAddOperation:    append([]huma.AddOpFunc{}, parent.OnAddOperation...),
+	}
+	info := huma.Info{}
+	if parent.Info != nil {
+		info = *parent.Info
+	}
+	info.Version = version
+	oapi.Info = &info
+
+	components := huma.Components{}
+	if parent.Components != nil {
+		components = *parent.Components
+	}
+	components.Schemas = huma.NewMapRegistry("#/components/schemas/", huma.DefaultSchemaNamer)
+	oapi.Components = &components
+
+	oapi.OnAddOperation = append(oapi.OnAddOperation, v.document(version))
+	return oapi
+}
+
+// document adds the version header and media types to a version's
+// operations.
+func (v *Versions) document(version string) huma.AddOpFunc {
+	return func(oapi *huma.OpenAPI, op *huma.Operation) {
+		documented := false
+		for _, p := range op.Parameters {
+			if p.In == "header" && strings.EqualFold(p.Name, v.config.Header) {
+				documented = true
+			}
+		}
+		if !documented {
+			desc := "API version."
+			if v.config.MediaType != "" {
+				desc = "API version. May instead be selected via the `" + v.mediaType(version, "json") + "` media type."
+			}
+			op.Parameters = append(op.Parameters, &huma.Param{
+				Name:        v.config.Header,
+				In:          "header",
+				Description: desc,
+				Required:    version != v.config.Default,
+				Schema:      &huma.Schema{Type: huma.TypeString, Enum: []any{version}},
+			})
+		}
+
+		if v.config.MediaType == "" {
+			return
+		}
+		for code, resp := range op.Responses {
+			if resp.Ref != "" || strings.HasPrefix(code, "4") || strings.HasPrefix(code, "5") || code == "default" {
+				continue
+			}
+			if mt := resp.Content["application/json"]; mt != nil {
+				resp.Content[v.mediaType(version, "json")] = mt
+			}
+		}
+	}
+}
+
+// mediaType returns the versioned media type with the given format suffix.
+func (v *Versions) mediaType(version, suffix string) string {
+	return v.config.MediaType + ".v" + version + "+" + suffix
+}
+
+// requested returns the version requested by a request, or an empty string.
+// The header takes precedence over media types.
+func (v *Versions) requested(ctx huma.Context) string {
+	if version := strings.TrimSpace(ctx.Header(v.config.Header)); version != "" {
+		return version
+	}
+	if v.config.MediaType == "" {
+		return ""
+	}
+	for _, header := range []string{ctx.Header("Accept"), ctx.Header("Content-Type")} {
+		if version := v.fromMediaType(header); version != "" {
+			return version
+		}
+	}
+	return ""
+}
+
+// fromMediaType returns the version from the first versioned media type in a
+// header value, e.g. `2` from `application/vnd.myapi.v2+json`.
+func (v *Versions) fromMediaType(header string) string {
+	prefix := strings.ToLower(v.config.MediaType) + ".v"
+	for _, value := range strings.Split(header, ",") {
+		value = strings.ToLower(strings.TrimSpace(value))
+		if i := strings.IndexByte(value, ';'); i != -1 {
+			value = strings.TrimSpace(value[:i])
+		}
+		if !strings.HasPrefix(value, prefix) {
+			continue
+		}
+		version := value[len(prefix):]
+		if i := strings.IndexByte(version, '+'); i != -1 {
+			version = version[:i]
+		}
+		if version != "" {
+			return version
+		}
+	}
+	return ""
+}
+
+// route dispatches requests for one method and path to the handler of the
+// requested version.
+type route struct {
+	versions *Versions
+	mu       sync.RWMutex
+	handlers map[string]versionHandler
+}
+
+type versionHandler struct {
+	op      *huma.Operation
+	handler func(ctx huma.Context)
+}
+
+func (r *route) serve(ctx huma.Context) {
+	v := r.versions
+	version := v.requested(ctx)
+	if version == "" {
+		version = v.config.Default
+	}
+
+	r.mu.RLock()
+	h, ok := r.handlers[version]
+	r.mu.RUnlock()
+
+	vary := v.config.Header
+	if v.config.MediaType != "" {
+		vary += ", Accept, Content-Type"
+	}
+	ctx.AppendHeader("Vary", vary)
+
+	if !ok {
+		location := "header." + v.config.Header
+		if version == "" {
+			huma.WriteErr(v.api, ctx, http.StatusBadRequest, "an API version is required", &huma.ErrorDetail{
+				Message:  "required header parameter is missing",
+				Location: location,
+			})
+			return
+		}
+		huma.WriteErr(v.api, ctx, http.StatusBadRequest, "unsupported API version", &huma.ErrorDetail{
+			Message:  "expected one of: " + strings.Join(r.available(), ", "),
+			Location: location,
+			Value:    version,
+		})
+		return
+	}
+
+	ctx.SetHeader(v.config.Header, version)
+	h.handler(&versionContext{ContextWrapper: huma.WrapContext(ctx), op: h.op})
+}
+
+// available returns the versions registered for the route.
+func (r *route) available() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	versions := make([]string, 0, len(r.handlers))
+	for version := range r.handlers {
+		versions = append(versions, version)
+	}
+	sort.Strings(versions)
+	return versions
+}
+
+// versionContext returns the operation of the version handling the request
+// rather than the first version registered with the router.
+type versionContext struct {
+	huma.ContextWrapper
+	op *huma.Operation
+}
+
+func (c *versionContext) Operation() *huma.Operation {
+	return c.op
+}
+
+// openAPIPath returns the API's configured OpenAPI path, if any.
+func openAPIPath(api huma.API) string {
+	if p, ok := api.(huma.ConfigProvider); ok {
+		if c := p.Config(); c != nil {
+			return c.OpenAPIPath
+		}
+	}
+	return ""
+}
+
+// versionAPI is the API for a single version. Operations registered with it
+// are added to the version's OpenAPI and routed via the version dispatcher.
+type versionAPI struct {
+	huma.API
+	versions *Versions
+	version  string
+	oapi     *huma.OpenAPI
+}
+
+func (a *versionAPI) OpenAPI() *huma.OpenAPI {
+	return a.oapi
+}
+
+func (a *versionAPI) Config() *huma.Config {
+	if p, ok := a.API.(huma.ConfigProvider); ok {
+		return p.Config()
+	}
+	return nil
+}
+
+func (a *versionAPI) Adapter() huma.Adapter {
+	return &versionAdapter{Adapter: a.API.Adapter(), api: a}
+}
+
+// Negotiate selects the versioned media type when the client asked for it,
+// so it is used as the response's `Content-Type`. The format suffix, e.g.
+// `+json`, selects the format used to marshal the response.
+func (a *versionAPI) Negotiate(accept string) (string, error) {
+	v := a.versions
+	if v.config.MediaType != "" {
+		prefix := strings.ToLower(v.config.MediaType) + ".v" + a.version + "+"
+		for _, value := range strings.Split(accept, ",") {
+			value = strings.ToLower(strings.TrimSpace(value))
+			if i := strings.IndexByte(value, ';'); i != -1 {
+				value = strings.TrimSpace(value[:i])
+			}
+			if !strings.HasPrefix(value, prefix) {
+				continue
+			}
+			suffix := value[len(prefix):]
+			if _, err := a.API.Negotiate("application/" + suffix); err == nil {
+				return v.mediaType(a.version, suffix), nil
+			}
+		}
+	}
+	return a.API.Negotiate(accept)
+}
+
+// versionAdapter registers each method and path with the underlying adapter
+// once, dispatching to the handler for the requested version.
+type versionAdapter struct {
+	huma.Adapter
+	api *versionAPI
+}
+
+func (a *versionAdapter) Handle(op *huma.Operation, handler func(ctx huma.Context)) {
+	v := a.api.versions
+	key := op.Method + " " + op.Path
+
+	v.mu.Lock()
+	r := v.routes[key]
+	if r == nil {
+		r = &route{versions: v, handlers: map[string]versionHandler{}}
+		v.routes[key] = r
+		a.Adapter.Handle(op, r.serve)
+	}
+	v.mu.Unlock()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.handlers[a.api.version]; ok {
+		panic("versioning: " + key + " is already registered for version " + a.api.version)
+	}
+	r.handlers[a.api.version] = versionHandler{op: op, handler: handler}
+}
@@ -0,0 +1,140 @@
+package versioning
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/humatest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type ThingV1 struct {
+	Body struct {
+		Name string `json:"name"`
+	}
+}
+
+type ThingV2 struct {
+	Body struct {
+		Title string `json:"title"`
+	}
+}
+
+func setup(t *testing.T, config Config) (humatest.TestAPI, *Versions) {
+	_, api := humatest.New(t, huma.DefaultConfig("Test API", "1.0.0"))
+	versions := New(api, config)
+
+	huma.Register(versions.Version("1"), huma.Operation{
+		OperationID: "get-thing",
+		Method:      http.MethodGet,
+		Path:        "/things",
+	}, func(ctx context.Context, input *struct{}) (*ThingV1, error) {
+		resp := &ThingV1{}
+		resp.Body.Name = "one"
+		return resp, nil
+	})
+
+	huma.Register(versions.Version("2"), huma.Operation{
+		OperationID: "get-thing",
+		Method:      http.MethodGet,
+		Path:        "/things",
+	}, func(ctx context.Context, input *struct{}) (*ThingV2, error) {
+		resp := &ThingV2{}
+		resp.Body.Title = "two"
+		return resp, nil
+	})
+
+	return api, versions
+}
+
+func TestHeader(t *testing.T) {
+	api, _ := setup(t, Config{Default: "1"})
+
+	resp := api.Get("/things")
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, `{"$schema": "https:///schemas/ThingV1Body.json", "name": "one"}`, resp.Body.String())
+	assert.Equal(t, "1", resp.Header().Get("Api-Version"))
+	assert.Contains(t, resp.Header().Values("Vary"), "Api-Version")
+
+	resp = api.Get("/things", "Api-Version: 2")
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, `{"$schema": "https:///schemas/ThingV2Body.json", "title": "two"}`, resp.Body.String())
+	assert.Equal(t, "2", resp.Header().Get("Api-Version"))
+
+	resp = api.Get("/things", "Api-Version: 3")
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+	assert.Contains(t, resp.Body.String(), "expected one of: 1, 2")
+}
+
+func TestVersionRequired(t *testing.T) {
+	api, _ := setup(t, Config{Header: "X-Version"})
+
+	resp := api.Get("/things")
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+	assert.Contains(t, resp.Body.String(), "header.X-Version")
+
+	resp = api.Get("/things", "X-Version: 1")
+	assert.Equal(t, http.StatusOK, resp.Code)
+}
+
+func TestMediaType(t *testing.T) {
+	api, _ := setup(t, Config{MediaType: "application/vnd.test"})
+
+	resp := api.Get("/things", "Accept: application/vnd.test.v2+json")
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, `{"$schema": "https:///schemas/ThingV2Body.json", "title": "two"}`, resp.Body.String())
+	assert.Equal(t, "application/vnd.test.v2+json", resp.Header().Get("Content-Type"))
+	assert.Equal(t, "2", resp.Header().Get("Api-Version"))
+
+	// The header takes precedence over the media type.
+	resp = api.Get("/things", "Accept: application/vnd.test.v2+json", "Api-Version: 1")
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, `{"$schema": "https:///schemas/ThingV1Body.json", "name": "one"}`, resp.Body.String())
+	assert.Equal(t, "application/json", resp.Header().Get("Content-Type"))
+}
+
+func TestOpenAPI(t *testing.T) {
+	api, versions := setup(t, Config{MediaType: "application/vnd.test", Default: "1"})
+
+	v1 := versions.Version("1").OpenAPI()
+	v2 := versions.Version("2").OpenAPI()
+	assert.Equal(t, "1", v1.Info.Version)
+	assert.Equal(t, "2", v2.Info.Version)
+	assert.Empty(t, api.OpenAPI().Paths)
+
+	op := v2.Paths["/things"].Get
+	require.NotNil(t, op)
+	require.Len(t, op.Parameters, 1)
+	assert.Equal(t, "Api-Version", op.Parameters[0].Name)
+	assert.True(t, op.Parameters[0].Required)
+	assert.False(t, v1.Paths["/things"].Get.Parameters[0].Required)
+	assert.Contains(t, op.Responses["200"].Content, "application/vnd.test.v2+json")
+
+	resp := api.Get("/openapi/2.json")
+	assert.Equal(t, http.StatusOK, resp.Code)
+	var spec map[string]any
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &spec))
+	assert.Equal(t, "2", spec["info"].(map[string]any)["version"])
+
+	resp = api.Get("/openapi/1.yaml")
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, "application/vnd.oai.openapi+yaml", resp.Header().Get("Content-Type"))
+}
+
+func TestDuplicateVersion(t *testing.T) {
+	_, versions := setup(t, Config{})
+
+	assert.Panics(t, func() {
+		huma.Register(versions.Version("1"), huma.Operation{
+			OperationID: "get-thing-again",
+			Method:      http.MethodGet,
+			Path:        "/things",
+		}, func(ctx context.Context, input *struct{}) (*ThingV1, error) {
+			return nil, nil
+		})
+	})
+}
@@ -0,0 +1,51 @@
+package huma
+
+import (
+	"fmt"
+	"strings"
+)
+
+// wildcardDescription documents catch-all path parameters in the OpenAPI.
+const wildcardDescription = "Matches the rest of the path, including any slashes."
+
+// PathWildcard returns the name of the catch-all parameter ending an
+// operation path, like `file` in `/static/{file...}`, along with the path
+// before it. Router adapters use it to translate catch-all parameters into
+// the router's own syntax, e.g. `/static/*` for chi or `/static/*file` for
+// gin. The parameter's value is the rest of the path without a leading slash,
+// like `css/site.css` for `/static/css/site.css`.
+//
+//	prefix, name, ok := huma.PathWildcard("/static/{file...}")
+//	// prefix == "/static/", name == "file", ok == true
+func PathWildcard(path string) (prefix, name string, ok bool) {
+	if !strings.HasSuffix(path, "...}") {
+		return path, "", false
+	}
+	start := strings.LastIndexByte(path, '{')
+	if start < 0 {
+		return path, "", false
+	}
+	return path[:start], path[start+1 : len(path)-len("...}")], true
+}
+
+// openAPIPath returns the path as documented in the OpenAPI, which has no
+// syntax for catch-all parameters, so `/static/{file...}` becomes
+// `/static/{file}`.
+func openAPIPath(path string) string {
+	if prefix, name, ok := PathWildcard(path); ok {
+		return prefix + "{" + name + "}"
+	}
+	return path
+}
+
+// checkPathWildcard panics if a catch-all parameter is used anywhere other
+// than as the entire last segment of the path.
+func checkPathWildcard(path string) {
+	prefix, name, ok := PathWildcard(path)
+	if strings.Contains(prefix, "...}") || (!ok && strings.Contains(path, "...}")) {
+		panic(fmt.Sprintf("catch-all path parameter must be at the end of path %s", path))
+	}
+	if ok && (name == "" || !strings.HasSuffix(prefix, "/")) {
+		panic(fmt.Sprintf("catch-all path parameter must be a whole path segment in %s", path))
+	}
+}
@@ -0,0 +1,72 @@
+package huma_test
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/humatest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPathWildcard(t *testing.T) {
+	prefix, name, ok := huma.PathWildcard("/static/{bucket}/{file...}")
+	assert.True(t, ok)
+	assert.Equal(t, "/static/{bucket}/", prefix)
+	assert.Equal(t, "file", name)
+
+	prefix, _, ok = huma.PathWildcard("/static/{file}")
+	assert.False(t, ok)
+	assert.Equal(t, "/static/{file}", prefix)
+}
+
+func TestWildcardParam(t *testing.T) {
+	_, api := humatest.New(t, huma.DefaultConfig("Test API", "1.0.0"))
+
+	huma.Register(api, huma.Operation{
+		OperationID: "get-file",
+		Method:      http.MethodGet,
+		Path:        "/files/{path...}",
+	}, func(ctx context.Context, input *struct {
+		Path string `path:"path"`
+	}) (*struct{ Body string }, error) {
+		return &struct{ Body string }{Body: input.Path}, nil
+	})
+
+	resp := api.Get("/files/a/b/c.txt")
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, `"a/b/c.txt"`, strings.TrimSpace(resp.Body.String()))
+
+	// The OpenAPI has no catch-all syntax, so it is documented as a normal
+	// path parameter.
+	assert.Nil(t, api.OpenAPI().Paths["/files/{path...}"])
+	item := api.OpenAPI().Paths["/files/{path}"]
+	if assert.NotNil(t, item) {
+		param := item.Get.Parameters[0]
+		assert.Equal(t, "path", param.Name)
+		assert.Contains(t, param.Description, "rest of the path")
+	}
+}
+
+func TestWildcardInvalid(t *testing.T) {
+	for _, path := range []string{
+		"/files/{path...}/info",
+		"/files/prefix{path...}",
+		"/files/{...}",
+	} {
+		t.Run(path, func(t *testing.T) {
+			_, api := humatest.New(t, huma.DefaultConfig("Test API", "1.0.0"))
+			assert.Panics(t, func() {
+				huma.Register(api, huma.Operation{
+					OperationID: "get-file",
+					Method:      http.MethodGet,
+					Path:        path,
+				}, func(ctx context.Context, input *struct{}) (*struct{}, error) {
+					return nil, nil
+				})
+			})
+		})
+	}
+}